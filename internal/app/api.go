@@ -0,0 +1,540 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/auth"
+	"github.com/Xafloc/NoteFlow-Go/internal/git"
+	"github.com/Xafloc/NoteFlow-Go/internal/handlers"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/openapi"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/etag"
+)
+
+// The REST API is served under apiRoot, and under apiAlias for the bundled
+// pages and clients written before it was versioned. Only apiRoot is a
+// stable contract; openapi.json describes it.
+const (
+	apiRoot    = "/api/v1"
+	apiAlias   = "/api"
+	apiVersion = "1.0.0"
+)
+
+// eventStream is the content type of the API's server-sent event streams.
+const eventStream = "text/event-stream"
+
+// apiRoute is one route of the REST API and its OpenAPI description
+type apiRoute struct {
+	op      openapi.Operation
+	handler fiber.Handler
+	// readOnly marks a POST that changes nothing, so isn't audited.
+	readOnly bool
+}
+
+// mountAPI serves the workspace's API routes and their OpenAPI document.
+// GETs other than event streams are conditional, and every other route
+// that changes something is recorded in the audit log.
+func (ws *workspace) mountAPI(root fiber.Router) {
+	routes := ws.apiRoutes()
+	for i := range routes {
+		op := &routes[i].op
+		op.Conditional = op.Method == fiber.MethodGet && op.Produces != eventStream
+	}
+	cond := conditional()
+	bodyLimit := limitBody(ws.app.settings.Limits.BodyBytes())
+	audit := handlers.NewAuditHandler(ws.taskRegistry.Audit(ws.folder), ws.noteManager).Record
+	for _, prefix := range []string{apiRoot, apiAlias} {
+		api := root.Group(prefix)
+		for _, r := range routes {
+			var chain []fiber.Handler
+			if r.op.Conditional {
+				chain = append(chain, cond)
+			}
+			if !isUpload(r.op) {
+				chain = append(chain, bodyLimit)
+			}
+			if r.op.Method != fiber.MethodGet && !r.readOnly {
+				chain = append(chain, audit)
+			}
+			api.Add(r.op.Method, r.op.Path, append(chain, r.handler)...)
+		}
+	}
+
+	ops := make([]openapi.Operation, len(routes))
+	for i, r := range routes {
+		ops[i] = r.op
+	}
+	info := openapi.Info{
+		Title:       "NoteFlow API",
+		Version:     apiVersion,
+		Description: "Notes, tasks and archives of a NoteFlow notes folder.",
+		ServerURL:   ws.prefix + apiRoot,
+	}
+	if ws.app.auth != nil {
+		info.SessionCookie = auth.CookieName
+	}
+	doc, err := json.Marshal(openapi.Document(info, ops))
+	if err != nil {
+		log.Printf("Warning: failed to build the OpenAPI document: %v", err)
+		return
+	}
+	root.Get(apiRoot+"/openapi.json", cond, func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return c.Send(doc)
+	})
+}
+
+// conditional gives a GET response an ETag hashed from its body, unless
+// the handler set one, and answers 304 Not Modified when If-None-Match
+// already has it. no-cache has browsers revalidate every time instead of
+// reusing a copy they consider fresh, so polling stays live.
+func conditional() fiber.Handler {
+	tag := etag.New()
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		return tag(c)
+	}
+}
+
+// limitBody rejects request bodies over n bytes. Fiber itself reads them
+// up to the larger upload limit.
+func limitBody(n int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(c.Body()) > n {
+			return fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("request body too large (max %dMB)", n>>20))
+		}
+		return c.Next()
+	}
+}
+
+// isUpload reports whether op takes a file, and so the upload limit.
+func isUpload(op openapi.Operation) bool {
+	for _, f := range op.Form {
+		if f.Binary {
+			return true
+		}
+	}
+	return false
+}
+
+// apiRoutes lists the workspace's API routes, relative to the API root.
+// Fiber matches routes in order, so fixed paths come before parameterised
+// ones that would also match them.
+func (ws *workspace) apiRoutes() []apiRoute {
+	a := ws.app
+	notesHandler := handlers.NewNotesHandler(ws.noteManager, ws.noteTemplates)
+	notesHandler.SetConfig(a.config)
+	notesHandler.SetTaskRegistry(ws.taskRegistry)
+	tasksHandler := handlers.NewTasksHandler(ws.noteManager)
+	tasksHandler.SetTaskRegistry(ws.taskRegistry)
+	filesHandler := handlers.NewFilesHandler(ws.noteManager)
+	filesHandler.SetTranscriber(a.transcriber)
+	filesHandler.SetDescriber(a.describer)
+	filesHandler.SetMaxUpload(int64(a.settings.Limits.UploadBytes()))
+	themesHandler := handlers.NewThemesHandler(a.config, a.configPath)
+	configHandler := handlers.NewConfigHandler(a.settings, a.configPath, ws.folder)
+	globalTasksHandler := handlers.NewGlobalTasksHandler(ws.taskRegistry)
+	searchHandler := handlers.NewSearchHandler(ws.taskRegistry, services.NewSearchService(ws.noteManager))
+	agendaHandler := handlers.NewAgendaHandler(ws.noteManager, ws.taskRegistry)
+	statsHandler := handlers.NewStatsHandler(ws.noteManager, ws.taskRegistry)
+	tagsHandler := handlers.NewTagsHandler(ws.noteManager)
+	spellcheckHandler := handlers.NewSpellcheckHandler(ws.spellcheck)
+	noteTemplatesHandler := handlers.NewNoteTemplatesHandler(ws.noteTemplates)
+	eventsHandler := handlers.NewEventsHandler(ws.events)
+	auditHandler := handlers.NewAuditHandler(ws.taskRegistry.Audit(ws.folder), ws.noteManager)
+
+	const (
+		get, post, put, del = fiber.MethodGet, fiber.MethodPost, fiber.MethodPut, fiber.MethodDelete
+		markdown            = "text/markdown"
+	)
+	route := func(method, path, tag, summary string, handler fiber.Handler, op openapi.Operation) apiRoute {
+		op.Method, op.Path, op.Tag, op.Summary = method, path, tag, summary
+		return apiRoute{op: op, handler: handler}
+	}
+	q := func(name, description string) openapi.Param {
+		return openapi.Param{Name: name, Description: description}
+	}
+	// taskExportQuery filters the task exports, as it does GET /global-tasks.
+	taskExportQuery := []openapi.Param{
+		q("completed", "true or false"), q("q", "text search"), q("due", "today, week, overdue, none or YYYY-MM-DD"),
+		q("due_from", "YYYY-MM-DD, inclusive"), q("due_to", "YYYY-MM-DD, inclusive"),
+		q("sort", `due, updated, text or folder; "-" prefixed for descending`),
+	}
+	readOnly := func(r apiRoute) apiRoute {
+		r.readOnly = true
+		return r
+	}
+
+	routes := []apiRoute{
+		// Notes
+		route(get, "/notes", "notes", "Render all notes as HTML", notesHandler.GetNotes, openapi.Operation{
+			Query:    []openapi.Param{q("tag", "only notes tagged with this tag or one nested under it"), q("mention", "only notes mentioning @name")},
+			Produces: "text/html",
+		}),
+		route(post, "/notes", "notes", "Add a note, optionally from a template", handlers.Idempotent(ws.taskRegistry.Idempotency(ws.folder), notesHandler.AddNote), openapi.Operation{
+			Headers: []openapi.Param{{Name: handlers.IdempotencyKeyHeader, Description: "retries with the same key get the first response instead of adding the note again"}},
+			Body:    models.NoteRequest{}, Data: models.NoteCursor{},
+		}),
+		route(get, "/notes/metadata", "notes", "List notes by frontmatter; each query parameter is a filter", notesHandler.QueryNoteMetadata, openapi.Operation{
+			Data: []services.NoteMetadata{},
+		}),
+		route(get, "/notes/raw", "notes", "Get notes.md as markdown", notesHandler.GetNotesRaw, openapi.Operation{Produces: markdown}),
+		route(get, "/notes/export.json", "backups", "Export the notes, with their metadata and tasks, as a notes JSON document", notesHandler.ExportNotesJSON, openapi.Operation{
+			Data: services.NotesJSON{}, Bare: true,
+		}),
+		route(post, "/notes/import.json", "backups", "Add the notes of a notes JSON document, or restore from it", notesHandler.ImportNotesJSON, openapi.Operation{
+			Query: []openapi.Param{q("mode", "merge (default): add the notes not already here; restore: replace the notes")},
+			Body:  services.NotesJSON{},
+			Data:  services.ImportResult{},
+		}),
+		route(get, "/notes/:index", "notes", "Get a note for editing", notesHandler.GetNote, openapi.Operation{
+			Data: models.NoteView{}, Bare: true,
+		}),
+		route(put, "/notes/:index", "notes", "Replace a note; a stale version is a 409 with both versions", notesHandler.UpdateNote, openapi.Operation{Body: models.NoteRequest{}}),
+		readOnly(route(post, "/notes/:index/merge", "notes", "Merge an edit of an older version into the current note, without saving", notesHandler.MergeNote, openapi.Operation{
+			Body: models.NoteRequest{}, Data: models.NoteMerge{},
+		})),
+		route(del, "/notes/:index", "notes", "Move a note to the trash", notesHandler.DeleteNote, openapi.Operation{}),
+		route(get, "/notes/:index/raw", "notes", "Get a note's markdown source", notesHandler.GetNoteRaw, openapi.Operation{Produces: markdown}),
+		route(get, "/notes/:index/diff", "notes", "Diff two versions of a note", notesHandler.GetNoteDiff, openapi.Operation{
+			Query: []openapi.Param{q("from", "revision to diff from"), q("to", "revision to diff to; the current text by default")},
+			Data:  services.NoteDiff{},
+		}),
+		route(get, "/notes/:index/backlinks", "notes", "List the notes linking to a note", notesHandler.GetNoteBacklinks, openapi.Operation{
+			Data: []services.Backlink{},
+		}),
+		route(get, "/notes/:index/toc", "notes", "List a note's headings", notesHandler.GetNoteTOC, openapi.Operation{Data: services.NoteTOC{}}),
+		route(get, "/notes/:index/history", "notes", "List a note's saved versions", notesHandler.GetNoteHistory, openapi.Operation{
+			Data: []services.RevisionSummary{},
+		}),
+		route(get, "/notes/:index/history/:rev", "notes", "Get a saved version of a note", notesHandler.GetNoteRevision, openapi.Operation{
+			Query: []openapi.Param{q("format", `"diff" returns just the diff to the current text, as text/x-diff`)},
+			Data:  services.RevisionDetail{},
+		}),
+		route(post, "/notes/:index/history/:rev/restore", "notes", "Restore a saved version of a note", notesHandler.RestoreNoteRevision, openapi.Operation{}),
+		route(get, "/toc", "notes", "List the headings of every note", notesHandler.GetTOC, openapi.Operation{Data: []services.NoteTOC{}}),
+		route(get, "/debug/render-cache", "notes", "Report the rendered-note cache's size and hits", notesHandler.GetRenderCacheStats, openapi.Operation{
+			Data: services.RenderCacheStats{},
+		}),
+
+		// Trash
+		route(get, "/trash", "trash", "List deleted notes", notesHandler.ListTrash, openapi.Operation{Data: []services.TrashEntry{}}),
+		route(del, "/trash", "trash", "Permanently delete every note in the trash", notesHandler.EmptyTrash, openapi.Operation{}),
+		route(post, "/trash/:entry/restore", "trash", "Restore a deleted note", notesHandler.RestoreTrashedNote, openapi.Operation{Data: models.NoteIndex{}}),
+		route(del, "/trash/:entry", "trash", "Permanently delete a note from the trash", notesHandler.PurgeTrashedNote, openapi.Operation{}),
+
+		// Backups of notes.md
+		route(get, "/backups", "backups", "List the backups of notes.md, newest first", notesHandler.ListBackups, openapi.Operation{
+			Data: []models.Backup{},
+		}),
+		route(get, "/backups/:name", "backups", "Get a backup of notes.md", notesHandler.GetBackup, openapi.Operation{Produces: markdown}),
+		route(post, "/backups/:name/restore", "backups", "Replace notes.md with a backup", notesHandler.RestoreBackup, openapi.Operation{}),
+
+		// Export and import of the whole folder
+		route(get, "/export.zip", "backups", "Download the notes and assets tree as a zip archive", notesHandler.ExportZip, openapi.Operation{
+			Produces: "application/zip",
+		}),
+		route(get, "/export/site.zip", "backups", "Download the notes as a static HTML site, zipped, for publishing", notesHandler.ExportSite, openapi.Operation{
+			Query:    []openapi.Param{q("theme", "color theme; default the configured one")},
+			Produces: "application/zip",
+		}),
+		route(get, "/export.pdf", "backups", "Print the notes, or some of them, to one PDF with a table of contents", notesHandler.ExportPDF, openapi.Operation{
+			Query: []openapi.Param{
+				q("tag", "only notes tagged with this tag or one nested under it"), q("mention", "only notes mentioning @name"),
+				q("from", "only notes written from this day, YYYY-MM-DD"), q("to", "only notes written up to this day, YYYY-MM-DD"),
+				q("title", "cover title; default the folder's name"),
+			},
+			Produces: "application/pdf",
+		}),
+		route(get, "/export.epub", "backups", "Package the notes, or some of them, as an EPUB book, one chapter per note", notesHandler.ExportEPUB, openapi.Operation{
+			Query: []openapi.Param{
+				q("tag", "only notes tagged with this tag or one nested under it"), q("mention", "only notes mentioning @name"),
+				q("from", "only notes written from this day, YYYY-MM-DD"), q("to", "only notes written up to this day, YYYY-MM-DD"),
+				q("title", "book title; default the folder's name"),
+			},
+			Produces: "application/epub+zip",
+		}),
+		route(post, "/import", "backups", "Merge a zip archive from /export.zip into the folder, or restore from it", notesHandler.Import, openapi.Operation{
+			Form: []openapi.Param{{Name: "file", Binary: true}, {Name: "mode", Description: "merge (default): add the notes not already here; restore: replace the notes"}},
+			Data: services.ImportResult{},
+		}),
+		route(post, "/import/obsidian", "backups", "Import a zipped Obsidian vault, translating its links and storing its attachments", notesHandler.ImportObsidian, openapi.Operation{
+			Form: []openapi.Param{{Name: "file", Binary: true}, {Name: "mode", Description: "merge (default): add the notes not already here; restore: replace the notes"}},
+			Data: services.ImportResult{},
+		}),
+		route(post, "/import/keep", "backups", "Import the Google Keep notes of a Takeout zip, lists as tasks and labels as tags", notesHandler.ImportKeep, openapi.Operation{
+			Form: []openapi.Param{{Name: "file", Binary: true}, {Name: "mode", Description: "merge (default): add the notes not already here; restore: replace the notes"}},
+			Data: services.ImportResult{},
+		}),
+		route(post, "/import/joplin", "backups", "Import a Joplin export (.jex), its notebooks as folders and its to-dos as tasks", notesHandler.ImportJoplin, openapi.Operation{
+			Form: []openapi.Param{{Name: "file", Binary: true}, {Name: "mode", Description: "merge (default): add the notes not already in each folder; restore: replace them"}},
+			Data: services.ImportResult{},
+		}),
+
+		// Tasks
+		route(get, "/tasks", "tasks", "List this folder's open tasks", tasksHandler.GetTasks, openapi.Operation{
+			Data: []*models.TaskInfo{}, Bare: true,
+		}),
+		route(post, "/tasks/archive-completed", "tasks", "Move checked tasks out of older notes", tasksHandler.ArchiveCompleted, openapi.Operation{
+			Body: models.ArchiveCompletedRequest{}, Data: services.ArchiveCompletedResult{},
+		}),
+		route(get, "/tasks/export.csv", "tasks", "Export this folder's tasks as CSV", tasksHandler.ExportTasks, openapi.Operation{
+			Query:    taskExportQuery,
+			Produces: "text/csv",
+		}),
+		route(get, "/tasks/export.tsv", "tasks", "Export this folder's tasks as TSV", tasksHandler.ExportTasks, openapi.Operation{
+			Query:    taskExportQuery,
+			Produces: "text/tab-separated-values",
+		}),
+		route(post, "/tasks/:index", "tasks", "Check or uncheck a task", tasksHandler.UpdateTask, openapi.Operation{Body: models.TaskUpdate{}}),
+		route(post, "/capture", "tasks", "Quick-add a task from compact syntax", tasksHandler.CaptureTask, openapi.Operation{
+			Body: models.CaptureRequest{}, Data: services.QuickAdd{},
+		}),
+		route(get, "/board", "tasks", "Get the kanban board", tasksHandler.GetBoard, openapi.Operation{Data: services.Board{}}),
+		route(put, "/board/:index", "tasks", "Move a task to another board column", tasksHandler.SetTaskState, openapi.Operation{
+			Body: models.TaskStateRequest{},
+		}),
+		route(get, "/agenda", "tasks", "Get open tasks by due date", agendaHandler.GetAgenda, openapi.Operation{
+			Query: []openapi.Param{q("scope", `"folder" (default) or "all" registered folders`), q("format", `"markdown" returns bullet lists as text/markdown`)},
+			Data:  services.Agenda{},
+		}),
+
+		// Tags
+		route(get, "/tags", "tags", "Get the tag tree", tagsHandler.GetTags, openapi.Operation{Data: []*models.TagNode{}}),
+		route(get, "/tags/stats", "tags", "Get tag usage statistics", tagsHandler.GetTagStats, openapi.Operation{Data: services.TagStats{}}),
+		route(post, "/tags/rename", "tags", "Rename a tag in every note", tagsHandler.RenameTag, openapi.Operation{
+			Body: models.TagRenameRequest{}, Data: services.TagRewrite{},
+		}),
+		route(post, "/tags/merge", "tags", "Merge tags into one", tagsHandler.MergeTags, openapi.Operation{
+			Body: models.TagMergeRequest{}, Data: services.TagRewrite{},
+		}),
+		route(get, "/mentions", "tags", "Count @mentions", tagsHandler.GetMentions, openapi.Operation{Data: []services.MentionCount{}}),
+
+		// Spell check
+		readOnly(route(post, "/spellcheck", "spellcheck", "Find misspelled words", spellcheckHandler.Check, openapi.Operation{
+			Body: models.SpellcheckRequest{}, Data: services.SpellcheckResult{},
+		})),
+		route(get, "/spellcheck/words", "spellcheck", "Get the custom word list", spellcheckHandler.GetWords, openapi.Operation{Data: []string{}}),
+		route(post, "/spellcheck/words", "spellcheck", "Add a word to the custom list", spellcheckHandler.AddWord, openapi.Operation{
+			Body: models.WordRequest{}, Data: []string{},
+		}),
+
+		// Note templates; POST /notes takes "template"
+		route(get, "/templates", "templates", "List note templates", noteTemplatesHandler.List, openapi.Operation{Data: []services.NoteTemplate{}}),
+		route(post, "/templates", "templates", "Create a note template", noteTemplatesHandler.Create, openapi.Operation{Body: services.NoteTemplate{}}),
+		route(get, "/templates/:name", "templates", "Get a note template", noteTemplatesHandler.Get, openapi.Operation{Data: services.NoteTemplate{}}),
+		route(put, "/templates/:name", "templates", "Create or replace a note template", noteTemplatesHandler.Update, openapi.Operation{
+			Body: models.TemplateContentRequest{},
+		}),
+		route(del, "/templates/:name", "templates", "Delete a note template", noteTemplatesHandler.Delete, openapi.Operation{}),
+
+		// Files and archived sites
+		route(post, "/upload-file", "files", "Upload a file to embed in a note", filesHandler.UploadFile, openapi.Operation{
+			Form: []openapi.Param{{Name: "file", Binary: true}},
+			Data: models.UploadResult{}, Bare: true,
+		}),
+		route(get, "/links", "files", "Render the archived links panel", filesHandler.GetLinks, openapi.Operation{
+			Query: []openapi.Param{q("tag", "only archives with this tag")},
+			Data:  models.LinksView{}, Bare: true,
+		}),
+		route(post, "/archive-delete", "files", "Delete an archived site", filesHandler.DeleteArchive, openapi.Operation{
+			Body: models.ArchiveDeleteRequest{},
+		}),
+		route(get, "/archives/status", "files", "Report pending and recent archive jobs", filesHandler.ArchiveStatus, openapi.Operation{
+			Data: services.ArchiveStatus{},
+		}),
+		route(get, "/archives/links", "files", "List the external links in notes", filesHandler.ExternalLinks, openapi.Operation{
+			Data: []services.ExternalLink{},
+		}),
+		route(post, "/archives/bulk", "files", "Archive every external link, streaming progress as server-sent events", filesHandler.BulkArchive, openapi.Operation{
+			Body: services.BulkArchiveOptions{}, Produces: eventStream,
+		}),
+		route(post, "/archives/:filename/refresh", "files", "Capture a new snapshot of an archived page", filesHandler.RefreshArchive, openapi.Operation{
+			Data: models.ArchiveSnapshot{},
+		}),
+		route(get, "/archives/:filename/meta", "files", "Get an archive's metadata", filesHandler.GetArchiveMeta, openapi.Operation{
+			Data: models.ArchiveMeta{},
+		}),
+		route(put, "/archives/:filename/meta", "files", "Edit an archive's title, notes and tags", filesHandler.UpdateArchiveMeta, openapi.Operation{
+			Body: services.ArchiveMetaUpdate{}, Data: models.ArchiveMeta{},
+		}),
+
+		// Themes and font sizes
+		route(get, "/themes", "themes", "List theme names", themesHandler.GetThemes, openapi.Operation{Data: []string{}, Bare: true}),
+		route(get, "/current-theme", "themes", "Get the configured theme", themesHandler.GetCurrentTheme, openapi.Operation{
+			Data: models.CurrentTheme{}, Bare: true,
+		}),
+		readOnly(route(post, "/theme", "themes", "Get a theme's colors", themesHandler.SetTheme, openapi.Operation{
+			Body: models.ThemeRequest{}, Data: map[string]string{},
+		})),
+		route(post, "/save-theme", "themes", "Save the theme preference", themesHandler.SaveTheme, openapi.Operation{Body: models.ThemeRequest{}}),
+		route(get, "/font-scales", "themes", "Get the font-size multipliers", themesHandler.GetFontScales, openapi.Operation{Data: models.FontScales{}}),
+		route(post, "/font-scales", "themes", "Save a section's font-size multiplier", themesHandler.SaveFontScale, openapi.Operation{
+			Body: models.FontScaleRequest{}, Data: map[string]float64{},
+		}),
+
+		// Configuration
+		route(get, "/config", "config", "Get the settings in effect: config file, environment and flags, credentials redacted", configHandler.GetConfig, openapi.Operation{
+			Data: models.ConfigView{},
+		}),
+
+		// Tasks across registered folders
+		route(get, "/global-tasks", "global-tasks", "Query tasks across every registered folder", globalTasksHandler.GetGlobalTasks, openapi.Operation{
+			Query: []openapi.Param{
+				q("folder", "folder ID or path"), q("group", "folder group"), q("completed", "true or false"), q("q", "text search"),
+				q("due", "today, week, overdue, none or YYYY-MM-DD"), q("due_from", "YYYY-MM-DD, inclusive"), q("due_to", "YYYY-MM-DD, inclusive"),
+				q("sort", `due, updated, text or folder; "-" prefixed for descending`), q("limit", "page size"), q("offset", "page start"),
+			},
+			Data: models.GlobalTasksResponse{},
+		}),
+		route(get, "/global-tasks/export.csv", "global-tasks", "Export the tasks across every registered folder as CSV", globalTasksHandler.ExportTasks, openapi.Operation{
+			Query:    append([]openapi.Param{q("folder", "folder ID or path"), q("group", "folder group")}, taskExportQuery...),
+			Produces: "text/csv",
+		}),
+		route(get, "/global-tasks/export.tsv", "global-tasks", "Export the tasks across every registered folder as TSV", globalTasksHandler.ExportTasks, openapi.Operation{
+			Query:    append([]openapi.Param{q("folder", "folder ID or path"), q("group", "folder group")}, taskExportQuery...),
+			Produces: "text/tab-separated-values",
+		}),
+		route(get, "/global-tasks/history", "global-tasks", "Count tasks added, completed, reopened and removed per day or week, with open tasks and streaks", globalTasksHandler.GetTaskHistory, openapi.Operation{
+			Query: []openapi.Param{
+				q("by", "day or week"), q("from", "first day, YYYY-MM-DD"), q("to", "last day, YYYY-MM-DD; default today"),
+				q("folder", "folder ID or path"), q("group", "folder group"),
+			},
+			Data: services.TaskHistory{},
+		}),
+		route(get, "/global-tasks/duplicates", "global-tasks", "Find open tasks repeated across folders", globalTasksHandler.FindDuplicates, openapi.Operation{
+			Query: []openapi.Param{q("threshold", "share of words near-duplicates have in common, 0-1; default 0.8")},
+			Data:  []services.DuplicateGroup{},
+		}),
+		route(post, "/global-tasks/duplicates/dedupe", "global-tasks", "Keep one of a set of duplicate tasks and delete the others from their notes", globalTasksHandler.DedupeTasks, openapi.Operation{
+			Body: models.TaskDedupeRequest{}, Data: services.DedupeResult{},
+		}),
+		route(post, "/global-tasks/links", "global-tasks", "Link tasks so that completing or reopening one does the same to the others", globalTasksHandler.LinkTasks, openapi.Operation{
+			Body: models.TaskLinkRequest{}, Data: models.TaskLinkResponse{},
+		}),
+		route(del, "/global-tasks/links/:id", "global-tasks", "Unlink tasks", globalTasksHandler.UnlinkTasks, openapi.Operation{}),
+		route(get, "/global-tasks/events", "global-tasks", "Stream task registry changes as server-sent tasks.changed events", eventsHandler.TaskStream, openapi.Operation{
+			Produces: eventStream,
+		}),
+		route(post, "/global-tasks/:id/toggle", "global-tasks", "Complete or reopen a task", globalTasksHandler.UpdateGlobalTask, openapi.Operation{
+			Body: models.GlobalTaskToggle{},
+		}),
+		route(get, "/global-tasks/:id/source", "global-tasks", "Locate a task's folder, note and line", globalTasksHandler.GetTaskSource, openapi.Operation{
+			Data: services.TaskSource{},
+		}),
+		route(get, "/global-folders", "global-tasks", "List registered folders", globalTasksHandler.GetActiveFolders, openapi.Operation{
+			Query: []openapi.Param{q("inactive", "true to list forgotten folders too")},
+			Data:  []models.FolderRegistry{},
+		}),
+		route(post, "/global-folders/add", "global-tasks", "Register a folder", globalTasksHandler.AddFolder, openapi.Operation{
+			Body: models.FolderAddRequest{}, Data: models.FolderRegistry{},
+		}),
+		route(post, "/global-folders/discover", "global-tasks", "Register every notes folder under a directory", globalTasksHandler.DiscoverFolders, openapi.Operation{
+			Body: models.FolderDiscoverRequest{}, Data: services.DiscoverResult{},
+		}),
+		route(post, "/global-folders/:id/forget", "global-tasks", "Stop tracking a folder", globalTasksHandler.ForgetFolder, openapi.Operation{}),
+		route(post, "/global-folders/:id/deactivate", "global-tasks", "Stop tracking a folder; same as forget", globalTasksHandler.ForgetFolder, openapi.Operation{}),
+		route(post, "/global-folders/:id/reactivate", "global-tasks", "Track a forgotten folder again", globalTasksHandler.ReactivateFolder, openapi.Operation{
+			Data: models.FolderRegistry{},
+		}),
+		route(del, "/global-folders/:id", "global-tasks", "Remove a folder and its tasks from the registry, keeping its files", globalTasksHandler.DeleteFolder, openapi.Operation{}),
+		route(put, "/global-folders/:id/labels", "global-tasks", "Set a folder's display alias and group", globalTasksHandler.SetFolderLabels, openapi.Operation{
+			Body: models.FolderLabelRequest{}, Data: models.FolderRegistry{},
+		}),
+		route(post, "/global-folders/:id/sync", "global-tasks", "Re-read a folder's tasks", globalTasksHandler.SyncFolder, openapi.Operation{}),
+		route(post, "/global-sync", "global-tasks", "Re-read every folder's tasks", globalTasksHandler.ForceSync, openapi.Operation{}),
+		route(get, "/global-registry/export", "global-tasks", "Download the registry's folders, tasks and saved views as JSON", globalTasksHandler.ExportRegistry, openapi.Operation{
+			Bare: true, Data: services.RegistryExport{},
+		}),
+		route(post, "/global-registry/import", "global-tasks", "Merge an exported registry into this one", globalTasksHandler.ImportRegistry, openapi.Operation{
+			Query: []openapi.Param{q("rewrite", "OLD=NEW: move folders under OLD to NEW; repeatable")},
+			Body:  services.RegistryExport{}, Data: services.RegistryImportResult{},
+		}),
+		route(get, "/projects", "projects", "List the registered folders and the URLs they are served under", ws.listProjects, openapi.Operation{
+			Data: []models.Project{},
+		}),
+
+		// Search: this folder (indexed), and every registered folder
+		route(get, "/search", "search", "Search this folder's notes", searchHandler.Search, openapi.Operation{
+			Query: []openapi.Param{q("q", "query"), q("limit", "most results to return; 50 by default")},
+			Data:  services.SearchResults{},
+		}),
+		route(get, "/search/global", "search", "Search every registered folder", searchHandler.GlobalSearch, openapi.Operation{
+			Query: []openapi.Param{q("q", "query")},
+			Data:  handlers.SearchResponse{},
+		}),
+
+		// Statistics
+		route(get, "/stats", "stats", "Get note, task, archive and storage figures per registered folder and in total", statsHandler.Dashboard, openapi.Operation{
+			Query: []openapi.Param{q("weeks", "weeks of task activity, 12 by default")},
+			Data:  services.Dashboard{},
+		}),
+		route(get, "/stats/export.csv", "stats", "Export per-day note and task metrics", statsHandler.ExportCSV, openapi.Operation{
+			Produces: "text/csv",
+		}),
+
+		// Audit log
+		route(get, "/audit", "audit", "List changes made through the API, newest first", auditHandler.List, openapi.Operation{
+			Query: []openapi.Param{
+				q("since", "only changes at or after this RFC 3339 time or date"),
+				q("until", "only changes before this RFC 3339 time, or up to the end of this date"),
+				q("action", "create, update, delete, toggle or request"),
+				q("note", "only changes to the note with this ID"),
+				q("limit", "most entries to return; 100 by default, at most 1000"),
+			},
+			Data: []models.AuditEntry{},
+		}),
+	}
+
+	// The integrations sign in with the owner's credentials, and shutdown
+	// stops everyone's server, so users' workspaces don't get them. The
+	// integrations work on the folder NoteFlow was started in, so the
+	// owner's other projects only get shutdown.
+	if ws.user != auth.Owner {
+		return routes
+	}
+	shutdown := route(post, "/shutdown", "server", "Stop the server", ws.shutdown, openapi.Operation{})
+	if ws.github == nil {
+		return append(routes, shutdown)
+	}
+	githubHandler := handlers.NewGitHubHandler(ws.github)
+	gitHandler := handlers.NewGitHandler(ws.git)
+	return append(routes,
+		route(post, "/github/export", "integrations", "Export tasks as GitHub issues", githubHandler.ExportTasks, openapi.Operation{
+			Body: models.GitHubExportRequest{}, Data: []services.ExportedIssue{},
+		}),
+		route(post, "/github/import", "integrations", "Import assigned GitHub issues now", githubHandler.ImportIssues, openapi.Operation{}),
+		route(post, "/todoist/sync", "integrations", "Sync with Todoist now", handlers.NewTodoistHandler(ws.todoist).Sync, openapi.Operation{
+			Data: services.TaskSyncResult{},
+		}),
+		route(post, "/google-tasks/sync", "integrations", "Sync with Google Tasks now", handlers.NewGoogleTasksHandler(ws.googleTasks).Sync, openapi.Operation{
+			Data: services.TaskSyncResult{},
+		}),
+		route(post, "/jira/sync", "integrations", "Sync with Jira now", handlers.NewJiraHandler(ws.jira).Sync, openapi.Operation{
+			Data: services.TaskSyncResult{},
+		}),
+		route(post, "/digest/send", "integrations", "Email the task digest now", handlers.NewDigestHandler(a.digest).Send, openapi.Operation{
+			Data: services.Digest{},
+		}),
+		route(get, "/git/log", "integrations", "List recent commits of the notes", gitHandler.Log, openapi.Operation{
+			Query: []openapi.Param{q("limit", "most commits to return; 20 by default")},
+			Data:  []git.Commit{},
+		}),
+		route(post, "/git/pull", "integrations", "Pull the notes from the git remote and reload them", gitHandler.Pull, openapi.Operation{}),
+		route(post, "/git/push", "integrations", "Commit the notes and push them to the git remote", gitHandler.Push, openapi.Operation{}),
+		shutdown,
+	)
+}
+
+// shutdown stops the server once the response is sent.
+func (ws *workspace) shutdown(c *fiber.Ctx) error {
+	go ws.app.Shutdown()
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: "shutting down",
+	})
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/auth"
+	"github.com/gofiber/fiber/v2"
+)
+
+// loginFailureDelay slows down password guessing.
+const loginFailureDelay = time.Second
+
+// userKey is the fiber.Ctx local holding the logged-in user's ID.
+const userKey = "user"
+
+// requireAuth lets a request through with a valid session cookie or an
+// "Authorization: Bearer" token or password, recording whose it is under
+// userKey. Without one, pages redirect to the login page and everything
+// else gets 401. The login page, static files and favicon are always
+// reachable, as are Slack's slash commands, which carry a signature
+// instead.
+func (a *App) requireAuth(c *fiber.Ctx) error {
+	c.Locals(userKey, auth.Owner)
+	if a.auth == nil {
+		return c.Next()
+	}
+	path := strings.TrimPrefix(c.Path(), a.server.BasePath)
+	if path == "/slack/command" {
+		return c.Next()
+	}
+	if _, rest, ok := projectPath(path); ok {
+		path = rest
+	}
+	if path == "/login" || path == "/favicon.ico" || strings.HasPrefix(path, "/static/") {
+		return c.Next()
+	}
+	if session := c.Cookies(auth.CookieName); session != "" {
+		if user, ok := a.auth.ValidSession(session, time.Now()); ok {
+			c.Locals(userKey, user)
+			return c.Next()
+		}
+	}
+	if token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok && a.auth.Check(token) {
+		return c.Next()
+	}
+	if c.Method() == fiber.MethodGet && !strings.HasPrefix(path, "/api/") && !strings.HasPrefix(path, "/assets/") {
+		return c.Redirect(a.server.BasePath + "/login?next=" + url.QueryEscape(c.OriginalURL()))
+	}
+	return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+}
+
+// serveLogin serves the login page.
+func (a *App) serveLogin(c *fiber.Ctx) error {
+	if a.auth == nil {
+		return c.Redirect(a.server.BasePath + "/")
+	}
+	return a.renderLogin(c, a.loginNext(c.Query("next")), "")
+}
+
+// login checks the submitted user name and password and starts a
+// session.
+func (a *App) login(c *fiber.Ctx) error {
+	if a.auth == nil {
+		return c.Redirect(a.server.BasePath+"/", fiber.StatusSeeOther)
+	}
+	next := a.loginNext(c.FormValue("next"))
+	user, ok := a.auth.Login(c.FormValue("username"), c.FormValue("password"))
+	if !ok {
+		time.Sleep(loginFailureDelay)
+		c.Status(fiber.StatusUnauthorized)
+		return a.renderLogin(c, next, "Wrong user name or password.")
+	}
+	value, expires := a.auth.NewSession(user, time.Now())
+	c.Cookie(&fiber.Cookie{
+		Name:     auth.CookieName,
+		Value:    value,
+		Path:     a.cookiePath(),
+		Expires:  expires,
+		Secure:   a.server.TLS(),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return c.Redirect(next, fiber.StatusSeeOther)
+}
+
+// logout ends the session.
+func (a *App) logout(c *fiber.Ctx) error {
+	c.Cookie(&fiber.Cookie{
+		Name:     auth.CookieName,
+		Path:     a.cookiePath(),
+		Expires:  time.Unix(0, 0),
+		Secure:   a.server.TLS(),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return c.Redirect(a.server.BasePath+"/login", fiber.StatusSeeOther)
+}
+
+func (a *App) renderLogin(c *fiber.Ctx, next, errMsg string) error {
+	html, err := a.templateService.RenderLogin(a.config, next, errMsg, a.auth.MultiUser())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to render login page: "+err.Error())
+	}
+	c.Set("Content-Type", "text/html")
+	return c.SendString(html)
+}
+
+// loginNext returns next if it is a path on this server, else the index,
+// so the login form can't be used to redirect elsewhere.
+func (a *App) loginNext(next string) string {
+	if next == "" || !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.Contains(next, "\\") {
+		return a.server.BasePath + "/"
+	}
+	return next
+}
+
+func (a *App) cookiePath() string {
+	if a.server.BasePath == "" {
+		return "/"
+	}
+	return a.server.BasePath
+}
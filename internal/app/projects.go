@@ -0,0 +1,108 @@
+package app
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// projectsPath is where a workspace serves its other registered folders:
+// each under projectsPath/<alias>, with the same pages and API as the
+// workspace itself.
+const projectsPath = "/p"
+
+// projectPath splits a path relative to the base path into the alias of
+// the project it is in and the rest of the path.
+func projectPath(path string) (alias, rest string, ok bool) {
+	path, ok = strings.CutPrefix(path, projectsPath+"/")
+	if !ok {
+		return "", "", false
+	}
+	alias, rest, _ = strings.Cut(path, "/")
+	return alias, "/" + rest, alias != ""
+}
+
+// serveProject hands the request to ws's project alias. The workspace's
+// own folder is redirected to its root.
+func (ws *workspace) serveProject(c *fiber.Ctx, alias, rest string) error {
+	p, err := ws.project(alias)
+	if errors.Is(err, services.ErrProjectNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "no registered folder is called "+alias)
+	}
+	if err != nil {
+		return err
+	}
+	if p == ws {
+		target := ws.prefix + rest
+		if q := c.Context().QueryArgs().String(); q != "" {
+			target += "?" + q
+		}
+		return c.Redirect(target)
+	}
+	p.handler(c.Context())
+	return nil
+}
+
+// project returns the workspace of the registered folder served under
+// alias, opening it on first use. Projects share ws's task registry and
+// the NoteManager it keeps for the folder.
+func (ws *workspace) project(alias string) (*workspace, error) {
+	project, err := ws.taskRegistry.Project(alias)
+	if err != nil {
+		return nil, err
+	}
+	if project.Path == ws.folder {
+		return ws, nil
+	}
+	noteManager, err := ws.taskRegistry.FolderNoteManager(project.FolderID)
+	if err != nil {
+		return nil, err
+	}
+	prefix := ws.prefix + projectsPath + "/" + alias
+
+	ws.projectsMu.Lock()
+	defer ws.projectsMu.Unlock()
+	if p, ok := ws.projects[project.FolderID]; ok {
+		if p.noteManager == noteManager && p.prefix == prefix {
+			return p, nil
+		}
+		// The folder was forgotten and added again, or its alias changed
+		p.events.Close()
+	}
+	p := ws.app.newWorkspace(ws.user, project.Path, prefix, noteManager, ws.taskRegistry)
+	p.parent = ws
+	ws.projects[project.FolderID] = p
+	log.Printf("Opened project %s: %s", alias, project.Path)
+	return p, nil
+}
+
+// listProjects lists the registered folders and where each is served, for
+// switching between them.
+// GET /api/projects
+func (ws *workspace) listProjects(c *fiber.Ctx) error {
+	home := ws
+	if ws.parent != nil {
+		home = ws.parent
+	}
+	projects, err := ws.taskRegistry.Projects()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list projects: "+err.Error())
+	}
+	for i := range projects {
+		p := &projects[i]
+		if p.Path == home.folder {
+			p.URL = home.prefix + "/"
+		} else {
+			p.URL = home.prefix + projectsPath + "/" + p.Alias + "/"
+		}
+		p.Current = p.Path == ws.folder
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   projects,
+	})
+}
@@ -1,51 +1,115 @@
 package app
 
 import (
+	"crypto/tls"
 	"embed"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/Xafloc/NoteFlow-Go/internal/auth"
 	"github.com/Xafloc/NoteFlow-Go/internal/handlers"
 	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/notify"
+	"github.com/Xafloc/NoteFlow-Go/internal/selfsigned"
 	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/Xafloc/NoteFlow-Go/internal/transcribe"
+	"github.com/Xafloc/NoteFlow-Go/internal/vision"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/gofiber/fiber/v2/middleware/logger"
 )
 
 // App represents the main application
 type App struct {
 	fiber           *fiber.App
-	noteManager     *services.NoteManager
+	owner           *workspace // the folder NoteFlow was started in
 	templateService *services.TemplateService
-	taskRegistry    *services.TaskRegistryService
-	config          *models.Config
+	auth            *auth.Authenticator // nil when no password, token or users are set
+	digest          *services.DigestService
+	telegram        *services.TelegramService
+	slack           *services.SlackService
+	transcriber     transcribe.Transcriber
+	describer       vision.Describer
+	config          *models.Config // as in the config file, which handlers save
+	settings        models.Config  // config with the environment and command line applied
+	location        *time.Location // where due-date phrases resolve; from settings.Timezone
 	configPath      string
 	basePath        string
+	server          models.ServerConfig // Host/Port as configured; BasePath cleaned
 	port            int
-	noBrowser       bool // when true, do not auto-open a browser on startup
+	onListen        func(url string)
+
+	usersMu sync.Mutex
+	users   map[int]*workspace // multi-user accounts' workspaces, by users.id
+
+	shutdownOnce sync.Once
 }
 
-// SetNoBrowser disables the default behavior of opening the user's browser
-// to the server URL once the server is listening. Useful for headless / SSH
-// sessions or when the user prefers to manage browser tabs themselves.
-func (a *App) SetNoBrowser(b bool) {
-	a.noBrowser = b
+// SetOnListen registers fn to be called with the server URL once the
+// server is listening, before the browser is opened.
+func (a *App) SetOnListen(fn func(url string)) {
+	a.onListen = fn
 }
 
-// NewApp creates a new application instance
-func NewApp(basePath string, webAssets *embed.FS) (*App, error) {
+// NewApp creates a new application instance serving basePath, unless the
+// settings name another data directory. flags holds the settings given on
+// the command line (see Config.Override); they override the environment,
+// which overrides the config file, which overrides the defaults.
+func NewApp(basePath string, webAssets *embed.FS, flags models.Config) (*App, error) {
 	// Initialize configuration
 	configPath := getConfigPath()
-	config, err := models.LoadConfig(configPath)
+	fileConfig, err := models.LoadConfig(configPath)
 	if err != nil {
 		log.Printf("Warning: Failed to load config: %v", err)
-		config = models.DefaultConfig()
+		fileConfig = models.DefaultConfig()
+	}
+
+	config, err := fileConfig.WithEnv()
+	if err != nil {
+		return nil, err
+	}
+	config = config.Override(flags)
+	config.Server.BasePath = models.CleanBasePath(config.Server.BasePath)
+	if err := config.Server.ValidateTLS(); err != nil {
+		return nil, err
+	}
+	server := config.Server
+	if basePath, err = config.ResolveDataDir(basePath); err != nil {
+		return nil, fmt.Errorf("failed to resolve data_dir: %w", err)
+	}
+
+	// The log goes to stderr and, when configured, a file.
+	if config.Log.File != "" {
+		f, err := os.OpenFile(config.Log.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		log.SetOutput(io.MultiWriter(os.Stderr, f))
+	}
+
+	// Due-date phrases ("tomorrow 5pm") resolve in the configured zone;
+	// everything else keeps the system's.
+	location, err := config.Location()
+	if err != nil {
+		log.Printf("Warning: unknown timezone %q, using system zone: %v", config.Timezone, err)
+		location = time.Local
+	}
+
+	// Encrypted notes are unlocked before anything reads them
+	if err := unlockNotes(basePath); err != nil {
+		return nil, err
 	}
 
 	// Initialize note manager
@@ -59,6 +123,7 @@ func NewApp(basePath string, webAssets *embed.FS) (*App, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize template service: %w", err)
 	}
+	templateService.SetURLPrefix(server.BasePath)
 
 	// Initialize task registry service
 	taskRegistry, err := services.NewTaskRegistryService()
@@ -66,198 +131,389 @@ func NewApp(basePath string, webAssets *embed.FS) (*App, error) {
 		return nil, fmt.Errorf("failed to initialize task registry: %w", err)
 	}
 
+	// Accounts in the task DB turn on multi-user mode
+	var users auth.Users
+	if accounts, err := taskRegistry.Users().ListUsers(); err != nil {
+		log.Printf("Warning: failed to list users: %v", err)
+	} else if len(accounts) > 0 {
+		users = taskRegistry.Users()
+		log.Printf("Multi-user mode: %d user(s)", len(accounts))
+	}
+	authenticator, err := auth.New(config.Auth, filepath.Join(filepath.Dir(configPath), "session.key"), users)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize auth: %w", err)
+	}
+	templateService.SetAuthEnabled(authenticator != nil)
+	if authenticator == nil && server.Host != "" && !loopbackHost(server.Host) {
+		log.Printf("Warning: listening on %s without a password; set auth.password in %s", server.Host, configPath)
+	}
+
+	// Optional push notifications. A bad channel config is logged and
+	// ignored rather than blocking startup.
+	notifier, err := notify.New(config.Notifications)
+	if err != nil {
+		log.Printf("Warning: notifications disabled: %v", err)
+	} else if notifier != nil {
+		noteManager.SetNotifier(notifier)
+		if !config.Notifications.DisableOverdue {
+			taskRegistry.SetNotifier(notifier)
+		}
+	}
+
+	noteManager.SetLocation(location)
+
+	// Archive +URLs in the background so a slow site doesn't hold up saving
+	noteManager.SetArchiveConfig(config.Archive)
+	noteManager.StartArchiveQueue()
+	noteManager.SetLinkPreviewConfig(config.LinkPreviews)
+
+	// Changes are pushed to open pages over /ws
+	events := services.NewEventHub()
+	noteManager.SetEvents(events)
+	taskRegistry.SetEvents(events)
+
+	// Webhooks get the owner's note and task events, so they are watched
+	// before the folder's first sync. A bad entry disables them.
+	if webhooks, err := services.NewWebhookService(config.Webhooks); err != nil {
+		log.Printf("Warning: webhooks disabled: %v", err)
+	} else if webhooks != nil {
+		if notifier != nil {
+			webhooks.SetNotifier(notifier)
+		}
+		webhooks.Watch(events)
+	}
+
 	// Register this folder with the task registry
 	if err := taskRegistry.RegisterFolder(basePath, noteManager); err != nil {
 		log.Printf("Warning: failed to register folder for global tasks: %v", err)
 	}
 
+	// Per-folder settings (.noteflow.json). A broken file is logged and
+	// treated as empty so the notes themselves stay reachable.
+	folderConfig, err := models.LoadFolderConfig(basePath)
+	if err != nil {
+		log.Printf("Warning: Failed to load %s: %v", models.FolderConfigFile, err)
+		folderConfig = &models.FolderConfig{}
+	}
+
+	noteManager.SetTrashRetention(folderConfig.TrashRetentionDays())
+	noteManager.SetBackupPolicy(folderConfig.BackupKeep(), folderConfig.BackupInterval())
+	noteManager.SetSaveDelay(folderConfig.SaveDelay())
+	noteManager.StartRemotePolling(folderConfig.RemotePollInterval())
+	if n, err := noteManager.PurgeExpiredTrash(); err != nil {
+		log.Printf("Warning: failed to purge expired trash: %v", err)
+	} else if n > 0 {
+		log.Printf("Purged %d expired note(s) from %s", n, models.TrashFile)
+	}
+
+	githubService := services.NewGitHubService(noteManager, config.GitHub, folderConfig)
+	if githubService.CloseOnComplete() {
+		noteManager.OnTaskToggle(githubService.HandleTaskToggle)
+	}
+	githubService.StartImport()
+
+	todoistService := services.NewTodoistService(noteManager, config.Todoist, folderConfig,
+		filepath.Join(filepath.Dir(configPath), "todoist"))
+	todoistService.Start()
+
+	googleTasksService := services.NewGoogleTasksService(noteManager, config.Google, folderConfig,
+		filepath.Join(filepath.Dir(configPath), "google-tasks"))
+	googleTasksService.Start()
+
+	jiraService := services.NewJiraService(noteManager, config.Jira, folderConfig,
+		filepath.Join(filepath.Dir(configPath), "jira"))
+	jiraService.Start()
+
+	gitService := services.NewGitSyncService(noteManager, folderConfig)
+	gitService.Watch(events)
+	gitService.Start()
+
+	digestService := services.NewDigestService(taskRegistry, config.Digest, filepath.Dir(configPath))
+	digestService.Start()
+
+	// The Telegram bot runs in the server of the folder it files into.
+	telegramService := services.NewTelegramService(noteManager, taskRegistry, config.Telegram)
+	telegramService.Start()
+
+	// Slack's slash commands reach the owner's folder.
+	slackService := services.NewSlackService(noteManager, taskRegistry, config.Slack)
+
+	spellcheckService := services.NewSpellcheckService(basePath, folderConfig)
+
+	// Optional voice-note transcription; misconfiguration only disables it.
+	transcriber, err := transcribe.New(config.Transcription)
+	if err != nil {
+		log.Printf("Warning: transcription disabled: %v", err)
+	}
+
+	// Optional image alt text, same rules.
+	describer, err := vision.New(config.AltText)
+	if err != nil {
+		log.Printf("Warning: alt text disabled: %v", err)
+	}
+
 	app := &App{
-		noteManager:     noteManager,
 		templateService: templateService,
-		taskRegistry:    taskRegistry,
-		config:          config,
+		auth:            authenticator,
+		digest:          digestService,
+		telegram:        telegramService,
+		slack:           slackService,
+		transcriber:     transcriber,
+		describer:       describer,
+		config:          fileConfig,
+		settings:        config,
+		location:        location,
 		configPath:      configPath,
 		basePath:        basePath,
-		port:            8000, // Start with default, will be updated in Start()
+		server:          server,
+		port:            server.Port, // updated in Start() when scanning for a free port
+		users:           make(map[int]*workspace),
+	}
+	app.owner = &workspace{
+		app:           app,
+		user:          auth.Owner,
+		folder:        basePath,
+		noteManager:   noteManager,
+		taskRegistry:  taskRegistry,
+		events:        events,
+		spellcheck:    spellcheckService,
+		noteTemplates: services.NewNoteTemplateService(basePath),
+		github:        githubService,
+		todoist:       todoistService,
+		googleTasks:   googleTasksService,
+		jira:          jiraService,
+		git:           gitService,
+		prefix:        server.BasePath,
+		templates:     templateService,
+		projects:      make(map[int]*workspace),
 	}
 
 	app.setupFiber()
-	app.setupRoutes()
+	app.owner.serve()
 
 	return app, nil
 }
 
-// setupFiber initializes the Fiber app with middleware
+// setupFiber initializes the Fiber app that listens: middleware and the
+// login routes, then every other request goes to the workspace of the
+// logged-in user.
 func (a *App) setupFiber() {
-	a.fiber = fiber.New(fiber.Config{
-		AppName:      "NoteFlow",
-		ServerHeader: "NoteFlow/1.0",
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			code := fiber.StatusInternalServerError
-			if e, ok := err.(*fiber.Error); ok {
-				code = e.Code
-			}
-			return c.Status(code).JSON(models.APIResponse{
-				Status:  "error",
-				Message: err.Error(),
-			})
-		},
-	})
+	a.fiber = newFiber(a.settings.Limits)
+	if a.settings.Log.Requests {
+		a.fiber.Use(logger.New(logger.Config{Output: log.Writer()}))
+	}
 
 	// Middleware
-	a.fiber.Use(recover.New())
 	a.fiber.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
 		AllowMethods: "GET,POST,PUT,DELETE",
-		AllowHeaders: "Origin, Content-Type, Accept",
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
 	}))
+	if n := a.settings.Limits.RequestLimit(); n > 0 {
+		a.fiber.Use(limiter.New(limiter.Config{
+			Max:        n,
+			Expiration: time.Minute,
+			// Pages load their static files in bursts
+			Next: func(c *fiber.Ctx) bool {
+				path := strings.TrimPrefix(c.Path(), a.server.BasePath)
+				if _, rest, ok := projectPath(path); ok {
+					path = rest
+				}
+				return strings.HasPrefix(path, "/static/") || path == "/favicon.ico"
+			},
+			LimitReached: func(c *fiber.Ctx) error {
+				return fiber.NewError(fiber.StatusTooManyRequests, "too many requests; try again in a minute")
+			},
+		}))
+	}
+	a.fiber.Use(a.requireAuth)
 
-	// Serve static assets from basePath
-	assetsPath := filepath.Join(a.basePath, "assets")
-	a.fiber.Static("/assets", assetsPath)
+	// Pages under a project prefix log in and out there too
+	for _, prefix := range []string{a.server.BasePath, a.server.BasePath + projectsPath + "/:project"} {
+		a.fiber.Get(prefix+"/login", a.serveLogin)
+		a.fiber.Post(prefix+"/login", a.login)
+		a.fiber.Post(prefix+"/logout", a.logout)
+	}
+	a.fiber.Post(a.server.BasePath+"/slack/command", handlers.NewSlackHandler(a.slack).Command)
 
-	// Serve embedded static files (favicon, etc.)
-	a.fiber.Static("/static", "./web/static")
+	a.fiber.Use(a.dispatch)
 }
 
-// setupRoutes configures all application routes
-func (a *App) setupRoutes() {
-	// Initialize handlers
-	notesHandler := handlers.NewNotesHandler(a.noteManager)
-	tasksHandler := handlers.NewTasksHandler(a.noteManager)
-	filesHandler := handlers.NewFilesHandler(a.noteManager)
-	themesHandler := handlers.NewThemesHandler(a.config, a.configPath)
-	globalTasksHandler := handlers.NewGlobalTasksHandler(a.taskRegistry)
-	searchHandler := handlers.NewSearchHandler(a.taskRegistry)
-
-	// Root route - serve main HTML page
-	a.fiber.Get("/", a.serveIndex)
-	a.fiber.Get("/global-tasks", a.serveGlobalTasks)
-	a.fiber.Get("/favicon.ico", func(c *fiber.Ctx) error {
-		return c.Redirect("/static/favicon.ico")
-	})
-
-	// API routes
-	api := a.fiber.Group("/api")
-
-	// Note routes
-	api.Get("/notes", notesHandler.GetNotes)
-	api.Post("/notes", notesHandler.AddNote)
-	api.Get("/notes/:index", notesHandler.GetNote)
-	api.Put("/notes/:index", notesHandler.UpdateNote)
-	api.Delete("/notes/:index", notesHandler.DeleteNote)
-
-	// Task routes
-	api.Get("/tasks", tasksHandler.GetTasks)
-	api.Post("/tasks/:index", tasksHandler.UpdateTask)
-
-	// File routes
-	api.Post("/upload-file", filesHandler.UploadFile)
-	api.Get("/links", filesHandler.GetLinks)
-	api.Post("/archive-delete", filesHandler.DeleteArchive)
-
-	// Theme routes
-	api.Get("/themes", themesHandler.GetThemes)
-	api.Get("/current-theme", themesHandler.GetCurrentTheme)
-	api.Post("/theme", themesHandler.SetTheme)
-	api.Post("/save-theme", themesHandler.SaveTheme)
-
-	// Per-section font-size multipliers (v1.4)
-	api.Get("/font-scales", themesHandler.GetFontScales)
-	api.Post("/font-scales", themesHandler.SaveFontScale)
-
-	// Global task routes
-	api.Get("/global-tasks", globalTasksHandler.GetGlobalTasks)
-	api.Post("/global-tasks/:id/toggle", globalTasksHandler.UpdateGlobalTask)
-	api.Get("/global-folders", globalTasksHandler.GetActiveFolders)
-	api.Post("/global-folders/add", globalTasksHandler.AddFolder)
-	api.Post("/global-folders/:id/forget", globalTasksHandler.ForgetFolder)
-	api.Post("/global-folders/:id/sync", globalTasksHandler.SyncFolder)
-	api.Post("/global-sync", globalTasksHandler.ForceSync)
-
-	// v1.5: cross-folder search
-	api.Get("/search/global", searchHandler.GlobalSearch)
-
-	// Shutdown route
-	api.Post("/shutdown", func(c *fiber.Ctx) error {
-		go func() {
-			log.Println("Shutting down server...")
-			if err := a.fiber.Shutdown(); err != nil {
-				log.Printf("Error during shutdown: %v", err)
+// dispatch hands the request to the logged-in user's workspace, or to
+// the project of theirs its path is in.
+func (a *App) dispatch(c *fiber.Ctx) error {
+	ws := a.owner
+	if user, _ := c.Locals(userKey).(int); user != auth.Owner {
+		var err error
+		if ws, err = a.userWorkspace(user); err != nil {
+			if errors.Is(err, services.ErrUserNotFound) {
+				return fiber.NewError(fiber.StatusUnauthorized, "user no longer exists")
 			}
-		}()
-		return c.JSON(models.APIResponse{
-			Status:  "success",
-			Message: "shutting down",
-		})
-	})
-}
-
-// serveIndex serves the main HTML page with theme styling
-func (a *App) serveIndex(c *fiber.Ctx) error {
-	html, err := a.templateService.RenderIndex(a.config, a.basePath)
-	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to render page: "+err.Error())
+			return err
+		}
 	}
-	
-	c.Set("Content-Type", "text/html")
-	return c.SendString(html)
-}
-
-// serveGlobalTasks serves the global tasks page with theme styling
-func (a *App) serveGlobalTasks(c *fiber.Ctx) error {
-	html, err := a.templateService.RenderGlobalTasks(a.config, a.basePath)
-	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to render global tasks page: "+err.Error())
+	if path, ok := strings.CutPrefix(c.Path(), a.server.BasePath); ok {
+		if alias, rest, ok := projectPath(path); ok {
+			return ws.serveProject(c, alias, rest)
+		}
 	}
-	
-	c.Set("Content-Type", "text/html")
-	return c.SendString(html)
+	ws.handler(c.Context())
+	return nil
 }
 
-// Start starts the web server on the first available port starting from 8000.
+// Start starts the web server on the configured host and port, or when no
+// port is configured on the first free one from models.DefaultPort up.
 // Once the server is actually listening, opens the URL in the user's default
-// browser (unless SetNoBrowser(true) was called). The browser launch is
+// browser (unless the server config's NoBrowser is set). The browser launch is
 // non-fatal — if no launcher is available or it errors, the server keeps
 // running and the user can navigate to the printed URL manually.
 func (a *App) Start() error {
-	for port := 8000; port < 65535; port++ {
-		addr := fmt.Sprintf(":%d", port)
-		a.port = port // Update the port for this instance
-
-		log.Printf("NoteFlow server starting on http://localhost:%d", port)
-		log.Printf("Using folder: %s", a.basePath)
-
-		// Register a one-shot listener-ready hook for this port. We re-register
-		// each iteration so the URL captured in the closure matches whichever
-		// port we end up actually binding to.
-		port := port
-		a.fiber.Hooks().OnListen(func(fiber.ListenData) error {
-			if a.noBrowser {
-				return nil
+	ln, err := a.listen()
+	if err != nil {
+		return err
+	}
+	if a.server.TLS() {
+		cfg, err := a.tlsConfig()
+		if err != nil {
+			ln.Close()
+			return err
+		}
+		ln = tls.NewListener(ln, cfg)
+	}
+	url := a.URL()
+	log.Printf("NoteFlow server starting on %s", url)
+	log.Printf("Using folder: %s", a.basePath)
+
+	a.fiber.Hooks().OnListen(func(fiber.ListenData) error {
+		// On stdout by itself, without the log prefix, so it can be copied.
+		fmt.Printf("\nNoteFlow is running at %s\n\n", url)
+		if a.onListen != nil {
+			a.onListen(url)
+		}
+		if a.server.NoBrowser {
+			return nil
+		}
+		if err := openBrowser(url); err != nil {
+			log.Printf("Couldn't open browser automatically (%v); open %s manually", err, url)
+		}
+		return nil
+	})
+	return a.fiber.Listener(ln)
+}
+
+// shutdownTimeout bounds how long Shutdown waits for open requests.
+const shutdownTimeout = 10 * time.Second
+
+// Shutdown stops the server gracefully: open requests get shutdownTimeout
+// to finish, then the background services stop, every folder's unsaved
+// notes are written and the task database is closed. Start returns once
+// the listener is closed. Calls after the first wait for it to finish.
+func (a *App) Shutdown() {
+	a.shutdownOnce.Do(func() {
+		log.Println("Shutting down server...")
+		// End the event streams, which would otherwise keep the server
+		// waiting for their connections.
+		a.closeEvents()
+		if err := a.fiber.ShutdownWithTimeout(shutdownTimeout); err != nil {
+			log.Printf("Error during shutdown: %v", err)
+		}
+
+		owner := a.owner
+		owner.github.StopImport()
+		owner.todoist.Stop()
+		owner.googleTasks.Stop()
+		owner.jira.Stop()
+		a.digest.Stop()
+		a.telegram.Stop()
+
+		// The user registries share the owner's database, which closes last.
+		a.usersMu.Lock()
+		var workspaces []*workspace
+		for _, ws := range a.users {
+			workspaces = append(workspaces, ws)
+		}
+		a.usersMu.Unlock()
+		workspaces = append(workspaces, owner)
+		for _, ws := range workspaces {
+			if err := ws.noteManager.Close(); err != nil {
+				log.Printf("Error saving notes in %s: %v", ws.folder, err)
 			}
-			url := fmt.Sprintf("http://localhost:%d", port)
-			if err := openBrowser(url); err != nil {
-				log.Printf("Couldn't open browser automatically (%v); open %s manually", err, url)
+			if err := ws.taskRegistry.Close(); err != nil {
+				log.Printf("Error closing task registry: %v", err)
 			}
-			return nil
-		})
+		}
+		// Last, so the final save of notes.md is in the last commit.
+		owner.git.Stop()
+	})
+}
 
-		err := a.fiber.Listen(addr)
+// listen binds the server's socket. An explicitly configured port must be
+// free; otherwise ports are tried upward from models.DefaultPort.
+func (a *App) listen() (net.Listener, error) {
+	if a.server.Port != 0 {
+		ln, err := net.Listen("tcp", net.JoinHostPort(a.server.Host, strconv.Itoa(a.server.Port)))
+		if err != nil {
+			return nil, fmt.Errorf("cannot listen on port %d: %w", a.server.Port, err)
+		}
+		a.port = a.server.Port
+		return ln, nil
+	}
+	for port := models.DefaultPort; port < 65535; port++ {
+		ln, err := net.Listen("tcp", net.JoinHostPort(a.server.Host, strconv.Itoa(port)))
 		if err != nil {
 			// If error contains "address already in use", try next port
 			if strings.Contains(err.Error(), "address already in use") {
 				continue
 			}
-			// For other errors, return them
-			return err
+			return nil, err
 		}
+		a.port = port
+		return ln, nil
+	}
+	return nil, fmt.Errorf("no available port found in range %d-65534", models.DefaultPort)
+}
 
-		// If we get here, server started successfully (this won't actually be reached because Listen is blocking)
-		return nil
+// tlsConfig loads the configured certificate, or generates a self-signed
+// one beside the config file when none is given.
+func (a *App) tlsConfig() (*tls.Config, error) {
+	certFile, keyFile := a.server.TLSCert, a.server.TLSKey
+	if certFile == "" {
+		var err error
+		dir := filepath.Join(filepath.Dir(a.configPath), "tls")
+		certFile, keyFile, err = selfsigned.Ensure(dir, selfsigned.Hosts(a.server.Host))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create self-signed certificate: %w", err)
+		}
+		log.Printf("Using self-signed certificate %s; your browser will ask you to trust it", certFile)
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
 	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
 
-	return fmt.Errorf("no available port found in range 8000-65534")
+// loopbackHost reports whether host only accepts local connections.
+func loopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// URL is the address to open the UI at. A wildcard or empty host is shown
+// as localhost.
+func (a *App) URL() string {
+	host := a.server.Host
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	scheme := "http://"
+	if a.server.TLS() {
+		scheme = "https://"
+	}
+	return scheme + net.JoinHostPort(host, strconv.Itoa(a.port)) + a.server.BasePath + "/"
 }
 
 // openBrowser launches the user's default browser pointed at url. Returns
@@ -270,7 +526,9 @@ func (a *App) Start() error {
 //     `cmd /c start` because it bypasses cmd.exe quoting issues entirely.
 //   - macOS: open
 //   - Linux: xdg-open (the conventional opener; falls through to whatever
-//     the desktop session has registered for http:// URLs).
+//     the desktop session has registered for http:// URLs). Without a
+//     display, e.g. over SSH, there is nothing to open it on, so the URL is
+//     left for the user.
 func openBrowser(url string) error {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
@@ -279,6 +537,9 @@ func openBrowser(url string) error {
 	case "darwin":
 		cmd = exec.Command("open", url)
 	case "linux":
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return fmt.Errorf("no display")
+		}
 		cmd = exec.Command("xdg-open", url)
 	default:
 		return fmt.Errorf("auto-open not supported on %s", runtime.GOOS)
@@ -286,6 +547,10 @@ func openBrowser(url string) error {
 	return cmd.Start()
 }
 
+// Folder returns the folder the server was started for.
+func (a *App) Folder() string {
+	return a.basePath
+}
 
 // GetPort returns the port the server is running on
 func (a *App) GetPort() int {
@@ -294,11 +559,9 @@ func (a *App) GetPort() int {
 
 // getConfigPath returns the path to the configuration file
 func getConfigPath() string {
-	homeDir, err := os.UserHomeDir()
+	configPath, err := models.DefaultConfigPath()
 	if err != nil {
 		return "noteflow.json"
 	}
-	return filepath.Join(homeDir, ".config", "noteflow", "noteflow.json")
+	return configPath
 }
-
-
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/auth"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+)
+
+// unlockNotes unlocks the folder at basePath when its notes are encrypted,
+// with the passphrase from storage.PassphraseEnv or, failing that, asked
+// for on the terminal.
+func unlockNotes(basePath string) error {
+	if storage.NewFileStorage(basePath).Mode() != models.NotesStorageEncrypted || storage.IsUnlocked(basePath) {
+		return nil
+	}
+	passphrase := os.Getenv(storage.PassphraseEnv)
+	if passphrase == "" {
+		var err error
+		passphrase, err = auth.ReadPassphrase(os.Stdin, os.Stderr, "Passphrase for the notes in "+basePath+": ")
+		if err != nil {
+			return fmt.Errorf("%w (%v)", storage.ErrLocked, err)
+		}
+	}
+	if err := storage.Unlock(basePath, passphrase); err != nil {
+		return fmt.Errorf("failed to unlock notes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/handlers"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/valyala/fasthttp"
+)
+
+// workspace is one notes folder, the services working on it, and the
+// routes serving it. The folder NoteFlow was started in is the owner's
+// workspace; in multi-user mode each user gets one for their notes root,
+// built on their first request.
+type workspace struct {
+	app           *App
+	user          int    // auth.Owner or a users.id
+	folder        string // the notes folder
+	noteManager   *services.NoteManager
+	taskRegistry  *services.TaskRegistryService
+	events        *services.EventHub // changes in this workspace, served on /ws
+	spellcheck    *services.SpellcheckService
+	noteTemplates *services.NoteTemplateService
+	// The integrations, set only in the owner's workspace.
+	github      *services.GitHubService
+	todoist     *services.TodoistService
+	googleTasks *services.GoogleTasksService
+	jira        *services.JiraService
+	git         *services.GitSyncService
+
+	// prefix is the URL path the workspace is served under: the base path,
+	// or for a project the base path and /p/<alias>. templates render the
+	// pages for it.
+	prefix    string
+	templates *services.TemplateService
+
+	// The workspace's other registered folders, opened on first use, by
+	// folder ID. A project's parent is the owner or user workspace it
+	// belongs to; it has no projects of its own.
+	parent     *workspace
+	projectsMu sync.Mutex
+	projects   map[int]*workspace
+
+	fiber   *fiber.App
+	handler fasthttp.RequestHandler // fiber's, built once the routes are set
+}
+
+// newFiber creates a Fiber app with NoteFlow's error handling. Bodies are
+// read up to the upload limit; API routes other than uploads check the
+// smaller body limit themselves (see mountAPI).
+func newFiber(limits models.LimitsConfig) *fiber.App {
+	f := fiber.New(fiber.Config{
+		AppName:      "NoteFlow",
+		ServerHeader: "NoteFlow/1.0",
+		BodyLimit:    max(limits.UploadBytes(), limits.BodyBytes()),
+		// Start prints the URL to open instead of Fiber's banner, whose
+		// listen address ("http://[::]:8000") can't be opened as it is.
+		DisableStartupMessage: true,
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			code := fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				code = e.Code
+			}
+			return c.Status(code).JSON(models.APIResponse{
+				Status:  "error",
+				Message: err.Error(),
+			})
+		},
+	})
+	f.Use(recover.New())
+	return f
+}
+
+// serve sets up the workspace's routes and static files.
+func (ws *workspace) serve() {
+	ws.fiber = newFiber(ws.app.settings.Limits)
+
+	// Serve static assets from the notes folder, and those offloaded to
+	// its S3 bucket by redirecting to a signed URL
+	assetsPath := filepath.Join(ws.folder, "assets")
+	ws.fiber.Get(ws.prefix+"/assets/*", ws.offloadedAsset)
+	ws.fiber.Static(ws.prefix+"/assets", assetsPath)
+
+	// Serve embedded static files (favicon, etc.)
+	ws.fiber.Static(ws.prefix+"/static", "./web/static")
+
+	ws.setupRoutes()
+	ws.handler = ws.fiber.Handler()
+}
+
+// offloadedAsset redirects a request for an asset kept in the folder's S3
+// bucket to a signed URL of it, and passes the rest on to the static files.
+func (ws *workspace) offloadedAsset(c *fiber.Ctx) error {
+	rel, err := url.PathUnescape(c.Params("*"))
+	if err != nil {
+		return c.Next()
+	}
+	if target, ok := ws.noteManager.AssetURL("assets/" + rel); ok {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.Redirect(target, fiber.StatusFound)
+	}
+	return c.Next()
+}
+
+// userWorkspace returns the workspace of a multi-user account, opening
+// their notes root on first use.
+func (a *App) userWorkspace(id int) (*workspace, error) {
+	a.usersMu.Lock()
+	defer a.usersMu.Unlock()
+	if ws, ok := a.users[id]; ok {
+		return ws, nil
+	}
+	user, err := a.owner.taskRegistry.Users().GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Join(user.NotesRoot, "assets"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create assets directory: %w", err)
+	}
+	noteManager, err := services.NewNoteManager(user.NotesRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open notes of %s: %w", user.Name, err)
+	}
+	registry := a.owner.taskRegistry.ForUser(user)
+	if err := registry.RegisterFolder(user.NotesRoot, noteManager); err != nil {
+		log.Printf("Warning: failed to register %s's folder for global tasks: %v", user.Name, err)
+	}
+
+	ws := a.newWorkspace(user.ID, user.NotesRoot, a.server.BasePath, noteManager, registry)
+	registry.SetEvents(ws.events)
+	a.users[id] = ws
+	log.Printf("Opened notes of user %s: %s", user.Name, user.NotesRoot)
+	return ws, nil
+}
+
+// newWorkspace sets up and serves the workspace of a folder other than
+// the one NoteFlow was started in: its NoteManager gets the app's archive
+// and link preview settings and an event hub of its own, and the folder's
+// .noteflow.json is read.
+func (a *App) newWorkspace(user int, folder, prefix string, noteManager *services.NoteManager, registry *services.TaskRegistryService) *workspace {
+	noteManager.SetLocation(a.location)
+	noteManager.SetArchiveConfig(a.settings.Archive)
+	noteManager.StartArchiveQueue()
+	noteManager.SetLinkPreviewConfig(a.settings.LinkPreviews)
+	events := services.NewEventHub()
+	noteManager.SetEvents(events)
+
+	folderConfig, err := models.LoadFolderConfig(folder)
+	if err != nil {
+		log.Printf("Warning: Failed to load %s in %s: %v", models.FolderConfigFile, folder, err)
+		folderConfig = &models.FolderConfig{}
+	}
+	noteManager.SetTrashRetention(folderConfig.TrashRetentionDays())
+	noteManager.SetBackupPolicy(folderConfig.BackupKeep(), folderConfig.BackupInterval())
+	noteManager.SetSaveDelay(folderConfig.SaveDelay())
+	noteManager.StartRemotePolling(folderConfig.RemotePollInterval())
+
+	ws := &workspace{
+		app:           a,
+		user:          user,
+		folder:        folder,
+		noteManager:   noteManager,
+		taskRegistry:  registry,
+		events:        events,
+		spellcheck:    services.NewSpellcheckService(folder, folderConfig),
+		noteTemplates: services.NewNoteTemplateService(folder),
+		prefix:        prefix,
+		templates:     a.templateService.WithURLPrefix(prefix),
+		projects:      make(map[int]*workspace),
+	}
+	ws.serve()
+	return ws
+}
+
+// closeEvents closes the event hub of every workspace and project.
+func (a *App) closeEvents() {
+	a.owner.closeEvents()
+	a.usersMu.Lock()
+	defer a.usersMu.Unlock()
+	for _, ws := range a.users {
+		ws.closeEvents()
+	}
+}
+
+// closeEvents closes the event hubs of ws and its projects.
+func (ws *workspace) closeEvents() {
+	ws.events.Close()
+	ws.projectsMu.Lock()
+	defer ws.projectsMu.Unlock()
+	for _, p := range ws.projects {
+		p.events.Close()
+	}
+}
+
+// setupRoutes configures the workspace's routes
+func (ws *workspace) setupRoutes() {
+	eventsHandler := handlers.NewEventsHandler(ws.events)
+
+	// Everything is mounted under the workspace's prefix
+	var root fiber.Router = ws.fiber
+	if ws.prefix != "" {
+		root = ws.fiber.Group(ws.prefix)
+	}
+
+	// Root route - serve main HTML page
+	root.Get("/", ws.serveIndex)
+	root.Get("/global-tasks", ws.serveGlobalTasks)
+	root.Get("/board", ws.serveBoard)
+	root.Get("/stats", ws.serveStats)
+	root.Get("/ws", eventsHandler.Stream)
+	root.Get("/favicon.ico", func(c *fiber.Ctx) error {
+		return c.Redirect(ws.prefix + "/static/favicon.ico")
+	})
+
+	// API routes (see api.go)
+	ws.mountAPI(root)
+}
+
+// serveIndex serves the main HTML page with theme styling
+func (ws *workspace) serveIndex(c *fiber.Ctx) error {
+	html, err := ws.templates.RenderIndex(ws.app.config, ws.folder)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to render page: "+err.Error())
+	}
+
+	c.Set("Content-Type", "text/html")
+	return c.SendString(html)
+}
+
+// serveGlobalTasks serves the global tasks page with theme styling
+func (ws *workspace) serveGlobalTasks(c *fiber.Ctx) error {
+	html, err := ws.templates.RenderGlobalTasks(ws.app.config, ws.folder)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to render global tasks page: "+err.Error())
+	}
+
+	c.Set("Content-Type", "text/html")
+	return c.SendString(html)
+}
+
+// serveStats serves the statistics dashboard page
+func (ws *workspace) serveStats(c *fiber.Ctx) error {
+	html, err := ws.templates.RenderStats(ws.app.config, ws.folder)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to render stats page: "+err.Error())
+	}
+
+	c.Set("Content-Type", "text/html")
+	return c.SendString(html)
+}
+
+// serveBoard serves the kanban board page
+func (ws *workspace) serveBoard(c *fiber.Ctx) error {
+	html, err := ws.templates.RenderBoard(ws.app.config, ws.folder)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to render board page: "+err.Error())
+	}
+
+	c.Set("Content-Type", "text/html")
+	return c.SendString(html)
+}
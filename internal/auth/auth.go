@@ -0,0 +1,185 @@
+// Package auth checks NoteFlow's password, API token and multi-user
+// accounts and issues the session cookies a login gets. Sessions are
+// stateless: the cookie holds the user and expiry and an HMAC of them
+// keyed by a per-install secret and the owner's credentials, so logins
+// survive a restart and changing the password or token logs everyone out.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// CookieName is the session cookie set on login.
+const CookieName = "noteflow_session"
+
+// DefaultSessionTTL is how long a login lasts unless configured.
+const DefaultSessionTTL = 7 * 24 * time.Hour
+
+// keySize is the length of the secret sessions are signed with.
+const keySize = 32
+
+// Owner is the user ID of the server's owner: whoever logs in with the
+// configured password or token rather than as a multi-user account.
+const Owner = 0
+
+// Users looks up multi-user accounts.
+type Users interface {
+	// UserPasswordHash returns the named user's ID and the hash
+	// HashPassword made of their password, or an error if there's no such
+	// user.
+	UserPasswordHash(name string) (id int, hash string, err error)
+}
+
+// Authenticator checks credentials and sessions. A nil *Authenticator
+// means auth is off.
+type Authenticator struct {
+	password string
+	token    string
+	users    Users // nil outside multi-user mode
+	ttl      time.Duration
+	key      []byte // HMAC key: the install secret plus the credentials
+}
+
+// dummyHash is checked against when a login names an unknown user, so the
+// response takes as long as for a known one.
+var dummyHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword("")
+	return hash
+})
+
+// New returns an Authenticator for cfg, or nil when cfg sets neither a
+// password nor a token and there are no users. users, if non-nil, enables
+// multi-user logins. keyPath holds the signing secret and is created on
+// first use.
+func New(cfg models.AuthConfig, keyPath string, users Users) (*Authenticator, error) {
+	password, token := cfg.ResolvedPassword(), cfg.ResolvedToken()
+	if password == "" && token == "" && users == nil {
+		return nil, nil
+	}
+	secret, err := loadKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	ttl := DefaultSessionTTL
+	if cfg.SessionHours > 0 {
+		ttl = time.Duration(cfg.SessionHours) * time.Hour
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(password + "\x00" + token))
+	return &Authenticator{password: password, token: token, users: users, ttl: ttl, key: mac.Sum(nil)}, nil
+}
+
+// MultiUser reports whether logins name a user.
+func (a *Authenticator) MultiUser() bool {
+	return a.users != nil
+}
+
+// Login checks a login form: a user name and their password, or no name
+// and the owner's password or token. It returns the user ID to start a
+// session for.
+func (a *Authenticator) Login(name, password string) (int, bool) {
+	if name == "" || a.users == nil {
+		return Owner, a.Check(password)
+	}
+	id, hash, err := a.users.UserPasswordHash(name)
+	if err != nil {
+		VerifyPassword(dummyHash(), password)
+		return 0, false
+	}
+	ok, err := VerifyPassword(hash, password)
+	return id, ok && err == nil
+}
+
+// loadKey reads the signing secret at path, generating it if missing.
+func loadKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err == nil && len(key) >= keySize {
+		return key, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	key = make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, key, 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Check reports whether secret is the password or the token. The
+// comparison takes the same time wherever the inputs differ.
+func (a *Authenticator) Check(secret string) bool {
+	if secret == "" {
+		return false
+	}
+	// Evaluate both so the timing doesn't reveal which is configured.
+	p := a.password != "" && equal(secret, a.password)
+	t := a.token != "" && equal(secret, a.token)
+	return p || t
+}
+
+// equal compares two strings in constant time. Hashing first keeps the
+// time independent of their lengths too.
+func equal(a, b string) bool {
+	ha, hb := sha256.Sum256([]byte(a)), sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(ha[:], hb[:]) == 1
+}
+
+// NewSession returns a session cookie value for user valid from now, and
+// when it expires.
+func (a *Authenticator) NewSession(user int, now time.Time) (string, time.Time) {
+	expires := now.Add(a.ttl)
+	payload := strconv.Itoa(user) + "." + strconv.FormatInt(expires.Unix(), 10)
+	return payload + "." + a.sign(payload), expires
+}
+
+// ValidSession returns the user of value if it is an unexpired session
+// this Authenticator issued.
+func (a *Authenticator) ValidSession(value string, now time.Time) (int, bool) {
+	i := strings.LastIndexByte(value, '.')
+	if i < 0 {
+		return 0, false
+	}
+	payload, sig := value[:i], value[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(a.sign(payload))) {
+		return 0, false
+	}
+	userPart, expiresPart, ok := strings.Cut(payload, ".")
+	if !ok {
+		return 0, false
+	}
+	user, err := strconv.Atoi(userPart)
+	if err != nil {
+		return 0, false
+	}
+	expires, err := strconv.ParseInt(expiresPart, 10, 64)
+	if err != nil || now.Unix() >= expires {
+		return 0, false
+	}
+	return user, true
+}
+
+func (a *Authenticator) sign(payload string) string {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
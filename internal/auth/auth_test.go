@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestNew_DisabledWithoutCredentials(t *testing.T) {
+	t.Setenv("NOTEFLOW_PASSWORD", "")
+	t.Setenv("NOTEFLOW_API_TOKEN", "")
+	a, err := New(models.AuthConfig{}, filepath.Join(t.TempDir(), "session.key"), nil)
+	if err != nil || a != nil {
+		t.Fatalf("New(empty) = %v, %v; want nil, nil", a, err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	a, err := New(models.AuthConfig{Password: "hunter2", Token: "tok-123"}, filepath.Join(t.TempDir(), "session.key"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for secret, want := range map[string]bool{
+		"hunter2": true,
+		"tok-123": true,
+		"hunter":  false,
+		"":        false,
+	} {
+		if got := a.Check(secret); got != want {
+			t.Errorf("Check(%q) = %v, want %v", secret, got, want)
+		}
+	}
+}
+
+func TestCheck_EmptyTokenNeverMatches(t *testing.T) {
+	t.Setenv("NOTEFLOW_API_TOKEN", "")
+	a, err := New(models.AuthConfig{Password: "pw"}, filepath.Join(t.TempDir(), "session.key"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Check("") {
+		t.Error("empty secret accepted")
+	}
+}
+
+func TestSessions(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "session.key")
+	cfg := models.AuthConfig{Password: "pw", SessionHours: 1}
+	a, err := New(cfg, keyPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	session, expires := a.NewSession(Owner, now)
+	if !expires.Equal(now.Add(time.Hour)) {
+		t.Errorf("expires = %v, want an hour from now", expires)
+	}
+	if user, ok := a.ValidSession(session, now); !ok || user != Owner {
+		t.Errorf("fresh session: user %d, ok %v", user, ok)
+	}
+	if _, ok := a.ValidSession(session, now.Add(2*time.Hour)); ok {
+		t.Error("expired session accepted")
+	}
+	if _, ok := a.ValidSession("7"+session[1:], now); ok {
+		t.Error("session with an altered user accepted")
+	}
+
+	// The key on disk is reused, so sessions survive a restart...
+	restarted, _ := New(cfg, keyPath, nil)
+	if _, ok := restarted.ValidSession(session, now); !ok {
+		t.Error("session lost after reloading the key")
+	}
+	// ...but a new password invalidates them.
+	changed, _ := New(models.AuthConfig{Password: "new"}, keyPath, nil)
+	if _, ok := changed.ValidSession(session, now); ok {
+		t.Error("session still valid after the password changed")
+	}
+}
+
+type fakeUsers map[string]string // name -> password hash
+
+func (f fakeUsers) UserPasswordHash(name string) (int, string, error) {
+	hash, ok := f[name]
+	if !ok {
+		return 0, "", errors.New("no such user")
+	}
+	return len(name), hash, nil
+}
+
+func TestLogin_MultiUser(t *testing.T) {
+	t.Setenv("NOTEFLOW_PASSWORD", "")
+	t.Setenv("NOTEFLOW_API_TOKEN", "")
+	hash, err := HashPassword("alice-pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Users alone enable auth, even without an owner password.
+	a, err := New(models.AuthConfig{}, filepath.Join(t.TempDir(), "session.key"), fakeUsers{"alice": hash})
+	if err != nil || a == nil || !a.MultiUser() {
+		t.Fatalf("New with users = %v, %v", a, err)
+	}
+	if id, ok := a.Login("alice", "alice-pw"); !ok || id != 5 {
+		t.Errorf("Login(alice) = %d, %v", id, ok)
+	}
+	if _, ok := a.Login("alice", "wrong"); ok {
+		t.Error("wrong password accepted")
+	}
+	if _, ok := a.Login("bob", "alice-pw"); ok {
+		t.Error("unknown user accepted")
+	}
+	if _, ok := a.Login("", ""); ok {
+		t.Error("owner login accepted without an owner password")
+	}
+
+	session, _ := a.NewSession(5, time.Now())
+	if id, ok := a.ValidSession(session, time.Now()); !ok || id != 5 {
+		t.Errorf("ValidSession = %d, %v; want alice's id", id, ok)
+	}
+}
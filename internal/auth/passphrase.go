@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ReadPassphrase writes prompt to out and reads a passphrase from the
+// first line of in. When in is a terminal, echo is turned off while it is
+// typed, where stty can do that. Nothing past the line is consumed, so
+// the next call reads the next line.
+func ReadPassphrase(in io.Reader, out io.Writer, prompt string) (string, error) {
+	fmt.Fprint(out, prompt)
+	if f, ok := in.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 && stty(f, "-echo") == nil {
+			defer func() {
+				stty(f, "echo")
+				fmt.Fprintln(out)
+			}()
+		}
+	}
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := in.Read(b)
+		if n == 1 {
+			if b[0] == '\n' {
+				break
+			}
+			line = append(line, b[0])
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("read passphrase: %w", err)
+		}
+	}
+	passphrase := strings.TrimRight(string(line), "\r")
+	if passphrase == "" {
+		return "", errors.New("no passphrase given")
+	}
+	return passphrase, nil
+}
+
+func stty(tty *os.File, arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = tty
+	return cmd.Run()
+}
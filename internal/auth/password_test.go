@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashPassword_RoundTrip(t *testing.T) {
+	hash, err := HashPassword("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "pbkdf2-sha256$600000$") {
+		t.Errorf("hash = %q, want the pbkdf2-sha256 format", hash)
+	}
+	if ok, err := VerifyPassword(hash, "s3cret"); err != nil || !ok {
+		t.Errorf("VerifyPassword(right) = %v, %v", ok, err)
+	}
+	if ok, _ := VerifyPassword(hash, "s3cret "); ok {
+		t.Error("VerifyPassword accepted a wrong password")
+	}
+	if again, _ := HashPassword("s3cret"); again == hash {
+		t.Error("two hashes of the same password share a salt")
+	}
+}
+
+func TestVerifyPassword_Malformed(t *testing.T) {
+	for _, hash := range []string{"", "plain", "pbkdf2-sha256$x$AA$AA", "bcrypt$10$AA$AA"} {
+		if _, err := VerifyPassword(hash, "pw"); err != ErrMalformedHash {
+			t.Errorf("VerifyPassword(%q) error = %v, want ErrMalformedHash", hash, err)
+		}
+	}
+}
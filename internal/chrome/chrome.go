@@ -0,0 +1,120 @@
+// Package chrome drives a locally installed Chrome or Chromium in headless
+// mode. It shells out to the browser's own --print-to-pdf flag rather than
+// speaking the DevTools protocol, so there is nothing to install beyond
+// the browser itself.
+package chrome
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ErrNotFound is returned by Find when no Chrome-family browser is
+// installed.
+var ErrNotFound = errors.New("chrome: no Chrome or Chromium browser found")
+
+// candidates are the browser binaries looked up on $PATH, in order.
+var candidates = []string{
+	"google-chrome", "google-chrome-stable", "chromium", "chromium-browser",
+	"chrome", "microsoft-edge", "msedge",
+}
+
+// installPaths are well-known install locations that are usually not on
+// $PATH.
+func installPaths() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{
+			"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+			"/Applications/Chromium.app/Contents/MacOS/Chromium",
+			"/Applications/Microsoft Edge.app/Contents/MacOS/Microsoft Edge",
+		}
+	case "windows":
+		var paths []string
+		for _, env := range []string{"ProgramFiles", "ProgramFiles(x86)", "LocalAppData"} {
+			if dir := os.Getenv(env); dir != "" {
+				paths = append(paths,
+					filepath.Join(dir, `Google\Chrome\Application\chrome.exe`),
+					filepath.Join(dir, `Microsoft\Edge\Application\msedge.exe`))
+			}
+		}
+		return paths
+	}
+	return nil
+}
+
+// Find returns the browser to use: path itself when set, otherwise the
+// first Chrome-family browser on $PATH or in a standard install location.
+func Find(path string) (string, error) {
+	if path != "" {
+		if _, err := exec.LookPath(path); err != nil {
+			return "", fmt.Errorf("chrome: %w", err)
+		}
+		return path, nil
+	}
+	for _, name := range candidates {
+		if p, err := exec.LookPath(name); err == nil {
+			return p, nil
+		}
+	}
+	for _, p := range installPaths() {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// PrintPDF renders the page at url (http(s):// or file://) to a PDF at
+// out using the browser at binary.
+func PrintPDF(ctx context.Context, binary, url, out string) error {
+	// A throwaway profile keeps the run from touching, or waiting on, the
+	// user's open browser session.
+	profile, err := os.MkdirTemp("", "noteflow-chrome-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(profile)
+
+	args := []string{
+		"--headless=new",
+		"--disable-gpu",
+		"--no-first-run",
+		"--no-default-browser-check",
+		"--hide-scrollbars",
+		"--no-pdf-header-footer",
+		"--user-data-dir=" + profile,
+		"--print-to-pdf=" + out,
+	}
+	// Chrome refuses to start sandboxed as root, which is how it runs in
+	// most containers.
+	if os.Geteuid() == 0 {
+		args = append(args, "--no-sandbox")
+	}
+	args = append(args, url)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("chrome: %w: %s", err, lastLine(stderr.String()))
+	}
+	if info, err := os.Stat(out); err != nil || info.Size() == 0 {
+		return fmt.Errorf("chrome: no PDF written: %s", lastLine(stderr.String()))
+	}
+	return nil
+}
+
+// lastLine returns the last non-empty line of Chrome's chatty stderr,
+// which is where the actual error usually is.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
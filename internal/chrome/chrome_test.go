@@ -0,0 +1,61 @@
+package chrome
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeChrome writes a shell script that stands in for the browser: it
+// writes its arguments to the --print-to-pdf target.
+func fakeChrome(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the fake binary")
+	}
+	bin := filepath.Join(t.TempDir(), "chrome")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return bin
+}
+
+func TestPrintPDF(t *testing.T) {
+	bin := fakeChrome(t, `for a in "$@"; do case "$a" in --print-to-pdf=*) echo "$@" > "${a#--print-to-pdf=}";; esac; done`)
+	out := filepath.Join(t.TempDir(), "page.pdf")
+	if err := PrintPDF(context.Background(), bin, "file:///tmp/page.html", out); err != nil {
+		t.Fatal(err)
+	}
+	args, _ := os.ReadFile(out)
+	for _, want := range []string{"--headless=new", "--no-pdf-header-footer", "file:///tmp/page.html"} {
+		if !strings.Contains(string(args), want) {
+			t.Errorf("args %q lack %q", args, want)
+		}
+	}
+}
+
+func TestPrintPDFFailure(t *testing.T) {
+	bin := fakeChrome(t, "echo 'noise' >&2\necho 'ERROR: cannot open display' >&2\nexit 1\n")
+	err := PrintPDF(context.Background(), bin, "https://example.com", filepath.Join(t.TempDir(), "page.pdf"))
+	if err == nil || !strings.HasSuffix(err.Error(), "ERROR: cannot open display") {
+		t.Errorf("err = %v", err)
+	}
+
+	silent := fakeChrome(t, "exit 0\n")
+	if err := PrintPDF(context.Background(), silent, "https://example.com", filepath.Join(t.TempDir(), "page.pdf")); err == nil {
+		t.Error("expected an error when no PDF is written")
+	}
+}
+
+func TestFind(t *testing.T) {
+	bin := fakeChrome(t, "exit 0\n")
+	if got, err := Find(bin); err != nil || got != bin {
+		t.Errorf("Find(%q) = %q, %v", bin, got, err)
+	}
+	if _, err := Find(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing binary")
+	}
+}
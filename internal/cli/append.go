@@ -18,27 +18,37 @@ import (
 
 const appendHelp = `USAGE:
     noteflow-go append [--title TITLE] [BODY...]
+    noteflow-go add [-t TITLE] [BODY...]
 
 Appends a single note to notes.md in the current directory, via the same
 write path as the web UI — same schema, same task parsing, same sigil
 expansion. Designed for AI coding agents (Claude Code, Cursor, Aider)
 and shell scripts that want to drop a note without spinning up the
-server.
+server. 'add' is the same command under a shorter name.
 
 BODY:
     Argument(s) after the flags = note body (joined with spaces).
     No arguments     = body is read from stdin.
+    An argument starting with "- " (a list item or task) ends the flags.
     Empty input is rejected with a non-zero exit.
 
 FLAGS:
-    --title TITLE    Optional title (rendered as "## TIMESTAMP - TITLE")
+    --title, -t TITLE
+                     Optional title (rendered as "## TIMESTAMP - TITLE")
     --help, -h       Show this help and exit
 
 MARKDOWN FEATURES (parsed at write time, same as the web UI):
     - [ ] task                  Task; appears in 'noteflow-go tasks'
     !p1 @2026-05-20 #tag        Inline task metadata (priority / due / tag)
+    @due(tomorrow 5pm), ^fri    Natural-language due dates, rewritten to
+                                 @YYYY-MM-DD[THH:MM] on save
     +http://example.com         Archived locally on save; rewrites to a
                                  link to the archived copy
+    ++http://example.com        Same, plus a reader-mode copy of the
+                                 article text
+    +pdf:http://example.com     Archived as a PDF via headless Chrome
+                                 (HTML when no browser is installed)
+    +mhtml:, +warc:             Archived as .mhtml or .warc.gz
     +file:src/foo.go#10-25      Inlines those lines as a fenced code
                                  block with language detected from .go
 
@@ -48,6 +58,7 @@ OUTPUT:
 EXAMPLES:
     # Quick shell capture
     noteflow-go append "revisit the cache eviction logic"
+    noteflow-go add -t "Groceries" "- [ ] milk"
 
     # Pipe a command's output as a note body
     git log --oneline -5 | noteflow-go append --title "last week's commits"
@@ -69,6 +80,7 @@ EXAMPLES:
 // Usage:
 //
 //	noteflow append [--title TITLE] [BODY...]
+//	noteflow add [-t TITLE] [BODY...]
 //
 // If no BODY arguments are given, the note body is read from stdin. If both
 // are provided, BODY args win (stdin is ignored). An empty resulting body is
@@ -91,6 +103,16 @@ func RunAppend(basePath string, args []string, stdin io.Reader, stdout io.Writer
 	fs.SetOutput(io.Discard) // we surface errors ourselves; suppress flag's auto-printing
 
 	title := fs.String("title", "", "optional note title")
+	fs.StringVar(title, "t", "", "shorthand for --title")
+
+	// A body that starts with a list item or task ("- [ ] milk") isn't a
+	// flag: end the flags there, as if "--" had been given.
+	for i, a := range args {
+		if strings.HasPrefix(a, "- ") {
+			args = append(append(args[:i:i], "--"), args[i:]...)
+			break
+		}
+	}
 
 	if err := fs.Parse(args); err != nil {
 		return fmt.Errorf("parse flags: %w", err)
@@ -113,6 +135,13 @@ func RunAppend(basePath string, args []string, stdin io.Reader, stdout io.Writer
 	if err != nil {
 		return fmt.Errorf("open notes.md: %w", err)
 	}
+	// Due-date phrases resolve in the configured zone, as in the server.
+	config := userConfig()
+	location, err := config.Location()
+	if err != nil {
+		return fmt.Errorf("unknown timezone %q: %w", config.Timezone, err)
+	}
+	manager.SetLocation(location)
 	if err := manager.AddNote(*title, body); err != nil {
 		return fmt.Errorf("append note: %w", err)
 	}
@@ -33,6 +33,16 @@ func TestAppend_FromArgs(t *testing.T) {
 	}
 }
 
+func TestAppend_ShortTitleFlag(t *testing.T) {
+	dir := t.TempDir()
+	if err := RunAppend(dir, []string{"-t", "Groceries", "- [ ] milk"}, nil, &bytes.Buffer{}); err != nil {
+		t.Fatalf("RunAppend: %v", err)
+	}
+	if got := readNotes(t, dir); !strings.Contains(got, "- Groceries\n\n- [ ] milk") {
+		t.Errorf("notes.md missing expected content; got:\n%s", got)
+	}
+}
+
 func TestAppend_FromStdin(t *testing.T) {
 	dir := t.TempDir()
 	stdin := strings.NewReader("piped body\nsecond line")
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+)
+
+const archiveLinksHelp = `USAGE:
+    noteflow-go archive-links [--list] [--concurrency N] [--refetch]
+
+Finds every plain http(s) link in notes.md in the current directory that
+has no archive yet, archives the pages into assets/sites/, and adds a
+reference after each link:
+
+    https://example.com ([archived 2026-10-16 09:00](assets/sites/...))
+
+Links inside code, +URL sigils and links that already have a reference
+are left alone. A URL that was archived before is linked to its newest
+snapshot rather than fetched again. Each note is saved as soon as one of
+its links is archived, so stopping midway (Ctrl-C) keeps the work done;
+run it again to pick up the rest, including pages that failed.
+
+FLAGS:
+    --list             Only list the links and exit
+    --concurrency N    Pages fetched at once (default 2, max 8)
+    --refetch          Archive pages again even when a snapshot exists
+    --help, -h         Show this help and exit
+
+OUTPUT:
+    [done/total] ok|reused|FAILED url ...
+`
+
+// RunArchiveLinks archives the unarchived links in notes.md in basePath,
+// printing progress to stdout.
+func RunArchiveLinks(basePath string, args []string, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, archiveLinksHelp)
+			return nil
+		}
+	}
+
+	fs := flag.NewFlagSet("archive-links", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	list := fs.Bool("list", false, "only list the links")
+	concurrency := fs.Int("concurrency", services.DefaultBulkArchiveConcurrency, "pages fetched at once")
+	refetch := fs.Bool("refetch", false, "archive again even when a snapshot exists")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+	if *concurrency < 1 || *concurrency > services.MaxBulkArchiveConcurrency {
+		return fmt.Errorf("--concurrency must be between 1 and %d", services.MaxBulkArchiveConcurrency)
+	}
+
+	manager, err := services.NewNoteManager(basePath)
+	if err != nil {
+		return fmt.Errorf("open notes.md: %w", err)
+	}
+
+	if *list {
+		links, err := manager.ExternalLinks()
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			if link.Snapshot != "" {
+				fmt.Fprintf(stdout, "%s (archived as %s)\n", link.URL, link.Snapshot)
+			} else {
+				fmt.Fprintln(stdout, link.URL)
+			}
+		}
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	final, err := manager.BulkArchive(ctx, services.BulkArchiveOptions{Concurrency: *concurrency, Refetch: *refetch}, func(e services.BulkArchiveEvent) {
+		if e.Type != services.BulkArchiveLink {
+			return
+		}
+		switch {
+		case e.Error != "":
+			fmt.Fprintf(stdout, "[%d/%d] FAILED %s: %s\n", e.Done, e.Total, e.URL, e.Error)
+		case e.Reused:
+			fmt.Fprintf(stdout, "[%d/%d] reused %s -> %s\n", e.Done, e.Total, e.URL, e.FilePath)
+		default:
+			fmt.Fprintf(stdout, "[%d/%d] ok %s -> %s\n", e.Done, e.Total, e.URL, e.FilePath)
+		}
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	fmt.Fprintf(stdout, "archived %d of %d links, %d failed\n", final.Done-final.Failed, final.Total, final.Failed)
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("interrupted; run again to archive the rest")
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArchiveLinks_ListAndReuse(t *testing.T) {
+	dir := t.TempDir()
+	if err := RunAppend(dir, []string{"see https://kept.example/ and `https://code.example`"}, nil, &bytes.Buffer{}); err != nil {
+		t.Fatalf("RunAppend: %v", err)
+	}
+	sites := filepath.Join(dir, "assets", "sites")
+	os.WriteFile(filepath.Join(sites, "2026_10_15_080000_Kept-kept.example.html"),
+		[]byte("<!-- ARCHIVED PAGE - Original URL: https://kept.example/ - Archived: 2026-10-15 08:00:00 -->"), 0644)
+
+	out := &bytes.Buffer{}
+	if err := RunArchiveLinks(dir, []string{"--list"}, out); err != nil {
+		t.Fatalf("--list: %v", err)
+	}
+	if got := out.String(); got != "https://kept.example/ (archived as 2026_10_15_080000_Kept-kept.example.html)\n" {
+		t.Errorf("--list = %q", got)
+	}
+
+	out.Reset()
+	if err := RunArchiveLinks(dir, nil, out); err != nil {
+		t.Fatalf("RunArchiveLinks: %v", err)
+	}
+	if !strings.Contains(out.String(), "[1/1] reused https://kept.example/") || !strings.Contains(out.String(), "archived 1 of 1 links, 0 failed") {
+		t.Errorf("output = %q", out.String())
+	}
+	if got := readNotes(t, dir); !strings.Contains(got, "https://kept.example/ ([archived 2026-10-15 08:00](assets/sites/2026_10_15_080000_Kept-kept.example.html))") {
+		t.Errorf("notes.md not annotated:\n%s", got)
+	}
+
+	if err := RunArchiveLinks(dir, []string{"--concurrency", "0"}, out); err == nil {
+		t.Error("expected an error for --concurrency 0")
+	}
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+)
+
+const assetsHelp = `USAGE:
+    noteflow-go assets [status|offload]
+
+Shows where the uploads and archived sites of the project in the current
+directory are kept, or moves them to its S3 bucket:
+
+    status      count the files on disk and in the bucket (default)
+    offload     upload every file under assets/images, assets/files and
+                assets/sites to the bucket and delete it here
+
+The bucket is set in .noteflow.json as "s3": {"bucket": "notes-assets",
+"region": "eu-west-1"}, with "endpoint" and "path_style": true for
+MinIO, R2, B2 and the like, "prefix" to share a bucket and "url_minutes"
+for how long the links handed out stay valid (60). The credentials are
+taken from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+AWS_SESSION_TOKEN.
+
+Once a bucket is set, new uploads and archives go straight to it; notes
+keep linking to /assets/..., which the server answers with a redirect to
+a signed URL. notes.md, archive metadata and note history stay here.
+Offloaded files are not in exports or git sync.
+
+FLAGS:
+    --help, -h       Show this help and exit
+`
+
+// RunAssets shows or changes where the assets of basePath are kept.
+//
+// Usage:
+//
+//	noteflow assets [status|offload]
+func RunAssets(basePath string, args []string, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, assetsHelp)
+			return nil
+		}
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected argument %q", args[1])
+	}
+	if !storage.HasNotes(basePath) {
+		return fmt.Errorf("no notes.md in %s", basePath)
+	}
+	fs := storage.NewFileStorage(basePath)
+	defer fs.Close()
+
+	action := "status"
+	if len(args) == 1 {
+		action = args[0]
+	}
+	switch action {
+	case "status":
+		usage, err := fs.AssetUsage()
+		if err != nil {
+			return err
+		}
+		bucket := "no bucket set"
+		if ok, err := fs.AssetsOffloaded(); err != nil {
+			bucket = err.Error()
+		} else if ok {
+			bucket = "new uploads go to the bucket"
+		}
+		fmt.Fprintf(stdout, "on disk:   %d file(s), %s\n", usage.Local, formatSize(usage.LocalBytes))
+		fmt.Fprintf(stdout, "in bucket: %d file(s), %s (%s)\n", usage.Offloaded, formatSize(usage.OffloadedBytes), bucket)
+		return nil
+	case "offload":
+		n, size, err := fs.OffloadAssets()
+		if n > 0 {
+			fmt.Fprintf(stdout, "offloaded %d file(s), %s\n", n, formatSize(size))
+		}
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			fmt.Fprintln(stdout, "nothing to offload")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown action %q (want status or offload)", action)
+}
+
+// formatSize renders n bytes for people.
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,381 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+const startHelp = `USAGE:
+    noteflow-go start [--daemon] [SERVER FLAGS]
+
+Starts the web server for the current directory. Without --daemon this is
+the same as running noteflow-go with no subcommand.
+
+With --daemon the server runs in the background, detached from the
+terminal, and start returns once it is listening, printing its URL. The
+browser is not opened. Its PID file and log live in
+~/.config/noteflow/run/, one pair per folder; use 'noteflow-go status' and
+'noteflow-go stop' from the same folder to manage it. Only one server per
+folder can run this way.
+
+FLAGS:
+    --daemon         Run in the background
+    --help, -h       Show this help and exit
+
+Every server flag (--port, --host, --base-path, --tls-cert, --tls-key,
+--self-signed) is passed on; see 'noteflow-go --help'.
+
+EXAMPLES:
+    noteflow-go start --daemon
+    noteflow-go start --daemon --port 9000 --host 127.0.0.1
+`
+
+const stopHelp = `USAGE:
+    noteflow-go stop [--all]
+
+Stops the background server started with 'noteflow-go start --daemon' in
+the current directory. It shuts down gracefully, saving any unsaved notes.
+
+FLAGS:
+    --all            Stop the background servers of every folder
+    --help, -h       Show this help and exit
+`
+
+const statusHelp = `USAGE:
+    noteflow-go status [--all] [--json]
+
+Reports whether a background server is running for the current
+directory, with its PID, URL, log file and start time. Exits 1 when it
+isn't running. PID files left behind by a server that died are removed.
+
+FLAGS:
+    --all            List the background servers of every folder
+    --json           Emit JSON instead
+    --help, -h       Show this help and exit
+`
+
+// DaemonStateEnv names the environment variable that tells a server
+// started by 'start --daemon' where to write its PID file.
+const DaemonStateEnv = "NOTEFLOW_DAEMON_STATE"
+
+// ErrNotRunning is returned by RunStatus when no background server is
+// running, so main can exit 1 after printing the status.
+var ErrNotRunning = errors.New("not running")
+
+// daemonStartTimeout bounds how long start --daemon waits for the server
+// to listen, and stop for it to exit.
+const daemonStartTimeout = 15 * time.Second
+
+// daemonState is the content of a PID file. The server writes it once it
+// is listening, so a PID file with a URL means the server is up.
+type daemonState struct {
+	PID     int       `json:"pid"`
+	Dir     string    `json:"dir"`
+	URL     string    `json:"url"`
+	Log     string    `json:"log"`
+	Started time.Time `json:"started"`
+}
+
+// DefaultRunDir returns the directory of the PID and log files:
+// ~/.config/noteflow/run.
+func DefaultRunDir() (string, error) {
+	configPath, err := models.DefaultConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "run"), nil
+}
+
+// daemonFiles returns the PID and log file of dir's background server. The
+// name is the folder's base name, for people listing runDir, plus a hash of
+// its absolute path, so folders with the same name don't collide.
+func daemonFiles(runDir, dir string) (pidFile, logFile string) {
+	sum := sha256.Sum256([]byte(dir))
+	name := filepath.Base(dir) + "-" + hex.EncodeToString(sum[:4])
+	return filepath.Join(runDir, name+".pid"), filepath.Join(runDir, name+".log")
+}
+
+// WriteDaemonState writes the PID file at path for the running process,
+// serving dir at url. The log file sits beside it.
+func WriteDaemonState(path, dir, url string) error {
+	state := daemonState{
+		PID:     os.Getpid(),
+		Dir:     dir,
+		URL:     url,
+		Log:     strings.TrimSuffix(path, ".pid") + ".log",
+		Started: time.Now(),
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readDaemonState reads the PID file at path. A missing file, or one whose
+// process is gone, reads as nil; the stale file is removed.
+func readDaemonState(path string) (*daemonState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var state daemonState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	if state.PID <= 0 || !processAlive(state.PID) {
+		os.Remove(path)
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// RunStart starts the server for basePath in the background when args
+// hold --daemon. main runs the server itself otherwise.
+//
+// Usage:
+//
+//	noteflow start --daemon [SERVER FLAGS]
+func RunStart(basePath, runDir string, args []string, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, startHelp)
+			return nil
+		}
+	}
+
+	dir, err := filepath.Abs(basePath)
+	if err != nil {
+		return err
+	}
+	pidFile, logFile := daemonFiles(runDir, dir)
+	state, err := readDaemonState(pidFile)
+	if err != nil {
+		return err
+	}
+	if state != nil {
+		return fmt.Errorf("already running (pid %d) at %s", state.PID, state.URL)
+	}
+
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		return fmt.Errorf("create %s: %w", runDir, err)
+	}
+	logOut, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open log: %w", err)
+	}
+	defer logOut.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("find executable: %w", err)
+	}
+	childArgs := []string{"--no-browser"}
+	for _, a := range args {
+		if a != "--daemon" {
+			childArgs = append(childArgs, a)
+		}
+	}
+	cmd := exec.Command(exe, childArgs...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), DaemonStateEnv+"="+pidFile)
+	cmd.Stdout = logOut
+	cmd.Stderr = logOut
+	detach(cmd)
+	fmt.Fprintf(logOut, "--- %s: starting in %s\n", time.Now().Format(time.RFC3339), dir)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start server: %w", err)
+	}
+
+	// Wait for the server to write its PID file, or to die trying.
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	deadline := time.After(daemonStartTimeout)
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		select {
+		case err := <-exited:
+			return fmt.Errorf("server exited (%v); see %s", err, logFile)
+		case <-deadline:
+			return fmt.Errorf("server (pid %d) isn't listening after %s; see %s", cmd.Process.Pid, daemonStartTimeout, logFile)
+		case <-tick.C:
+		}
+		state, err := readDaemonState(pidFile)
+		if err != nil || state == nil || state.PID != cmd.Process.Pid {
+			continue
+		}
+		fmt.Fprintf(stdout, "NoteFlow running in the background (pid %d)\n", state.PID)
+		fmt.Fprintf(stdout, "url: %s\n", state.URL)
+		fmt.Fprintf(stdout, "log: %s\n", logFile)
+		return cmd.Process.Release()
+	}
+}
+
+// RunStop stops the background server of basePath, or of every folder with
+// --all.
+//
+// Usage:
+//
+//	noteflow stop [--all]
+func RunStop(basePath, runDir string, args []string, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, stopHelp)
+			return nil
+		}
+	}
+
+	fs := flag.NewFlagSet("stop", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	all := fs.Bool("all", false, "stop every folder's server")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	pidFiles, err := selectPIDFiles(basePath, runDir, *all)
+	if err != nil {
+		return err
+	}
+	stopped := 0
+	for _, pidFile := range pidFiles {
+		state, err := readDaemonState(pidFile)
+		if err != nil {
+			return err
+		}
+		if state == nil {
+			continue
+		}
+		if err := stopProcess(state.PID); err != nil {
+			return fmt.Errorf("stop pid %d: %w", state.PID, err)
+		}
+		if !waitExit(state.PID, daemonStartTimeout) {
+			return fmt.Errorf("pid %d still running after %s; see %s", state.PID, daemonStartTimeout, state.Log)
+		}
+		os.Remove(pidFile)
+		fmt.Fprintf(stdout, "stopped: %s (pid %d)\n", state.Dir, state.PID)
+		stopped++
+	}
+	if stopped == 0 {
+		fmt.Fprintln(stdout, "not running")
+	}
+	return nil
+}
+
+// RunStatus reports the background server of basePath, or of every folder
+// with --all. It returns ErrNotRunning when there is none.
+//
+// Usage:
+//
+//	noteflow status [--all] [--json]
+func RunStatus(basePath, runDir string, args []string, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, statusHelp)
+			return nil
+		}
+	}
+
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	all := fs.Bool("all", false, "list every folder's server")
+	jsonOut := fs.Bool("json", false, "emit JSON instead of human format")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	pidFiles, err := selectPIDFiles(basePath, runDir, *all)
+	if err != nil {
+		return err
+	}
+	running := []daemonState{}
+	for _, pidFile := range pidFiles {
+		state, err := readDaemonState(pidFile)
+		if err != nil {
+			return err
+		}
+		if state != nil {
+			running = append(running, *state)
+		}
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(running); err != nil {
+			return err
+		}
+	} else if len(running) == 0 {
+		fmt.Fprintln(stdout, "not running")
+	} else if *all {
+		for _, s := range running {
+			fmt.Fprintf(stdout, "%d  %s  %s\n", s.PID, s.URL, s.Dir)
+		}
+	} else {
+		s := running[0]
+		fmt.Fprintf(stdout, "running (pid %d) since %s\n", s.PID, s.Started.Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(stdout, "url: %s\n", s.URL)
+		fmt.Fprintf(stdout, "log: %s\n", s.Log)
+	}
+	if len(running) == 0 {
+		return ErrNotRunning
+	}
+	return nil
+}
+
+// selectPIDFiles returns the PID file of basePath, or every PID file in
+// runDir when all is set.
+func selectPIDFiles(basePath, runDir string, all bool) ([]string, error) {
+	if !all {
+		dir, err := filepath.Abs(basePath)
+		if err != nil {
+			return nil, err
+		}
+		pidFile, _ := daemonFiles(runDir, dir)
+		return []string{pidFile}, nil
+	}
+	files, err := filepath.Glob(filepath.Join(runDir, "*.pid"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// waitExit polls until pid has exited, for at most timeout.
+func waitExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !processAlive(pid) {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestStatus_NotRunningRemovesStalePIDFile(t *testing.T) {
+	dir, runDir := t.TempDir(), t.TempDir()
+	out := &bytes.Buffer{}
+	if err := RunStatus(dir, runDir, nil, out); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("RunStatus: err = %v, want ErrNotRunning", err)
+	}
+
+	// A PID file whose process is gone.
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	pidFile, _ := daemonFiles(runDir, dir)
+	writeState(t, pidFile, cmd.Process.Pid, dir)
+
+	out.Reset()
+	if err := RunStatus(dir, runDir, nil, out); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("RunStatus: err = %v, want ErrNotRunning", err)
+	}
+	if _, err := os.Stat(pidFile); !os.IsNotExist(err) {
+		t.Errorf("stale PID file still there: %v", err)
+	}
+}
+
+func TestStatusAndStart_Running(t *testing.T) {
+	dir, runDir := t.TempDir(), t.TempDir()
+	pidFile, _ := daemonFiles(runDir, dir)
+	if err := WriteDaemonState(pidFile, dir, "http://localhost:8123/"); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	if err := RunStatus(dir, runDir, nil, out); err != nil {
+		t.Fatalf("RunStatus: %v", err)
+	}
+	if !strings.Contains(out.String(), "url: http://localhost:8123/") {
+		t.Errorf("output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := RunStatus(t.TempDir(), runDir, []string{"--all"}, out); err != nil {
+		t.Fatalf("RunStatus --all: %v", err)
+	}
+	if !strings.Contains(out.String(), dir) {
+		t.Errorf("--all output:\n%s", out.String())
+	}
+
+	err := RunStart(dir, runDir, []string{"--daemon"}, out)
+	if err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Errorf("RunStart: err = %v, want already running", err)
+	}
+}
+
+func TestStop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sleep")
+	}
+	dir, runDir := t.TempDir(), t.TempDir()
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skip("sleep:", err)
+	}
+	go cmd.Wait() // reap it, so it doesn't linger as a zombie
+	pidFile, _ := daemonFiles(runDir, dir)
+	writeState(t, pidFile, cmd.Process.Pid, dir)
+
+	out := &bytes.Buffer{}
+	if err := RunStop(dir, runDir, nil, out); err != nil {
+		t.Fatalf("RunStop: %v", err)
+	}
+	if !strings.Contains(out.String(), "stopped: "+dir) {
+		t.Errorf("output:\n%s", out.String())
+	}
+	if _, err := os.Stat(pidFile); !os.IsNotExist(err) {
+		t.Errorf("PID file still there: %v", err)
+	}
+
+	out.Reset()
+	if err := RunStop(dir, runDir, nil, out); err != nil || strings.TrimSpace(out.String()) != "not running" {
+		t.Errorf("second stop: err %v, output %q", err, out.String())
+	}
+}
+
+func TestDaemonFiles(t *testing.T) {
+	a, _ := daemonFiles("/run", "/home/me/one/notes")
+	b, _ := daemonFiles("/run", "/home/me/two/notes")
+	if a == b {
+		t.Errorf("folders with the same name share %s", a)
+	}
+	if !strings.HasPrefix(filepath.Base(a), "notes-") {
+		t.Errorf("PID file %s doesn't start with the folder name", a)
+	}
+}
+
+// writeState writes a PID file for pid, which needn't be this process.
+func writeState(t *testing.T, path string, pid int, dir string) {
+	t.Helper()
+	data, err := json.Marshal(daemonState{PID: pid, Dir: dir, URL: "http://localhost:8000/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,42 @@
+//go:build windows
+
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+const (
+	createNewProcessGroup = 0x00000200
+	detachedProcess       = 0x00000008
+)
+
+// detach starts cmd without a console, in a process group of its own, so
+// it survives the terminal closing.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: createNewProcessGroup | detachedProcess}
+}
+
+// processAlive reports whether a process with this pid exists. On Windows
+// FindProcess opens the process, which fails once it is gone.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// stopProcess ends pid. Windows has no SIGTERM to deliver to a detached
+// process, so this is a hard stop. Edits are saved as they are made, but
+// an archive job in progress is lost.
+func stopProcess(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
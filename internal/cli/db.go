@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+)
+
+const dbHelp = `USAGE:
+    noteflow-go db [status|migrate <version>]
+
+Shows or changes the schema version of the task DB
+(~/.config/noteflow/tasks.db, or $NOTEFLOW_TASK_DB):
+
+    status               list the schema steps applied (default)
+    migrate <version>    run the steps up to version, or undo those after
+                         it; undoing a step deletes what it stored
+
+NoteFlow migrates the DB to the latest version itself when it opens it,
+and refuses one a newer NoteFlow has migrated further. To go back to an
+older NoteFlow, first run 'noteflow-go db migrate <its version>' with
+the newer one.
+
+FLAGS:
+    --help, -h       Show this help and exit
+`
+
+// RunDB shows or migrates the schema of the task DB at dbPath.
+//
+// Usage:
+//
+//	noteflow db [status|migrate <version>]
+func RunDB(dbPath string, args []string, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, dbHelp)
+			return nil
+		}
+	}
+	action := "status"
+	if len(args) > 0 {
+		action = args[0]
+	}
+	switch {
+	case action == "status" && len(args) <= 1:
+	case action == "migrate" && len(args) == 2:
+	case action == "migrate":
+		return fmt.Errorf("usage: noteflow db migrate <version>")
+	case action != "status" && action != "migrate":
+		return fmt.Errorf("unknown action %q (want status or migrate)", action)
+	default:
+		return fmt.Errorf("unexpected argument %q", args[1])
+	}
+
+	db, err := services.NewDatabaseServiceAt(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if action == "migrate" {
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := db.MigrateTo(version); err != nil {
+			return err
+		}
+	}
+	steps, err := db.SchemaSteps()
+	if err != nil {
+		return err
+	}
+	for _, step := range steps {
+		fmt.Fprintf(stdout, "%3d  %-20s  %s\n", step.Version, step.Name, step.Applied.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Fprintf(stdout, "schema version %d of %d\n", len(steps), services.LatestSchemaVersion())
+	return nil
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+)
+
+func TestDB_StatusAndMigrate(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tasks.db")
+	out := &bytes.Buffer{}
+	if err := RunDB(dbPath, nil, out); err != nil {
+		t.Fatalf("RunDB: %v", err)
+	}
+	latest := services.LatestSchemaVersion()
+	if want := fmt.Sprintf("schema version %d of %d\n", latest, latest); !strings.HasSuffix(out.String(), want) {
+		t.Errorf("status = %q, want it to end %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := RunDB(dbPath, []string{"migrate", "5"}, out); err != nil {
+		t.Fatalf("RunDB migrate: %v", err)
+	}
+	if want := fmt.Sprintf("schema version 5 of %d\n", latest); !strings.HasSuffix(out.String(), want) || strings.Contains(out.String(), "users") {
+		t.Errorf("after migrate 5 = %q", out.String())
+	}
+	if err := RunDB(dbPath, []string{"migrate", "x"}, out); err == nil {
+		t.Error("migrate x succeeded")
+	}
+}
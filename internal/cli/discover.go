@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+)
+
+const discoverHelp = `USAGE:
+    noteflow-go discover [--ignore PATTERN]... [--dry-run] [--json] [ROOT]
+
+Scans ROOT (default: the current directory) and every directory below it
+for notes.md files, and registers each folder that has one in the task
+DB (~/.config/noteflow/tasks.db), syncing its tasks, so 'noteflow-go
+tasks' and the global tasks page see them without opening each one.
+Folders already registered are left as they are.
+
+Hidden directories are skipped, as are node_modules, vendor, target,
+dist, build, __pycache__, venv and Library, and the assets/ of each
+folder found.
+
+FLAGS:
+    --ignore P       Skip directories matching P (repeatable): a path
+                     relative to ROOT with * and ? wildcards, or a name
+                     when it has no slash, e.g. --ignore archive
+                     --ignore 'clients/*/old'
+    --dry-run        List what would be registered, register nothing
+    --json           Emit the result as JSON
+    --help, -h       Show this help and exit
+`
+
+// RunDiscover registers the notes folders under args' ROOT, or basePath
+// when none is given, in the task DB at dbPath.
+//
+// Usage:
+//
+//	noteflow discover [--ignore PATTERN]... [--dry-run] [--json] [ROOT]
+func RunDiscover(basePath, dbPath string, args []string, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, discoverHelp)
+			return nil
+		}
+	}
+
+	fs := flag.NewFlagSet("discover", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var ignore []string
+	fs.Var((*patternList)(&ignore), "ignore", "skip directories matching this pattern")
+	dryRun := fs.Bool("dry-run", false, "register nothing")
+	asJSON := fs.Bool("json", false, "emit JSON")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("expected at most one ROOT, got %d", fs.NArg())
+	}
+	root := basePath
+	if fs.NArg() == 1 {
+		root = fs.Arg(0)
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("resolve ROOT: %w", err)
+	}
+	found, err := services.FindNotesFolders(root, ignore)
+	if err != nil {
+		return err
+	}
+
+	db, err := services.NewDatabaseServiceAt(dbPath)
+	if err != nil {
+		return fmt.Errorf("open task db: %w", err)
+	}
+	defer db.Close()
+	folders, err := db.GetActiveFolders()
+	if err != nil {
+		return err
+	}
+	registered := map[string]bool{}
+	for _, folder := range folders {
+		registered[folder.Path] = true
+	}
+
+	result := &services.DiscoverResult{Root: root, DryRun: *dryRun, Found: found, Added: []string{}, Existing: []string{}}
+	for _, dir := range found {
+		if registered[dir] {
+			result.Existing = append(result.Existing, dir)
+			continue
+		}
+		if !*dryRun {
+			if err := registerFolder(db, dir); err != nil {
+				if result.Failed == nil {
+					result.Failed = map[string]string{}
+				}
+				result.Failed[dir] = err.Error()
+				continue
+			}
+		}
+		result.Added = append(result.Added, dir)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	verb := "registered"
+	if *dryRun {
+		verb = "would register"
+	}
+	for _, dir := range result.Added {
+		fmt.Fprintf(stdout, "%s: %s\n", verb, dir)
+	}
+	for _, dir := range result.Existing {
+		fmt.Fprintf(stdout, "exists:  %s\n", dir)
+	}
+	for _, dir := range found {
+		if why, ok := result.Failed[dir]; ok {
+			fmt.Fprintf(stdout, "failed:  %s: %s\n", dir, why)
+		}
+	}
+	fmt.Fprintf(stdout, "%d notes folder(s) under %s, %d %s\n", len(found), root, len(result.Added), verb)
+	return nil
+}
+
+// registerFolder registers dir in db and syncs its tasks, as init does.
+func registerFolder(db *services.DatabaseService, dir string) error {
+	manager, err := services.NewNoteManager(dir)
+	if err != nil {
+		return fmt.Errorf("open notes: %w", err)
+	}
+	defer manager.Close()
+	folder, err := db.RegisterFolder(dir)
+	if err != nil {
+		return err
+	}
+	return db.SyncFolderTasks(folder.ID, manager.GetAllTasks())
+}
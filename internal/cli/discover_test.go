@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+)
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	dbPath := filepath.Join(t.TempDir(), "tasks.db")
+	for _, dir := range []string{"a", "b/c", "skip/d"} {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+		os.WriteFile(filepath.Join(root, dir, "notes.md"), []byte("## 2026-05-12 09:00:00\n\n- [ ] task in "+dir+"\n"), 0644)
+	}
+
+	out := &bytes.Buffer{}
+	if err := RunDiscover("", dbPath, []string{"--dry-run", "--ignore", "skip", root}, out); err != nil {
+		t.Fatalf("RunDiscover --dry-run: %v", err)
+	}
+	if !strings.Contains(out.String(), "would register: "+filepath.Join(root, "b", "c")) || strings.Contains(out.String(), "skip") {
+		t.Errorf("dry run output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := RunDiscover(root, dbPath, []string{"--ignore", "skip"}, out); err != nil {
+		t.Fatalf("RunDiscover: %v", err)
+	}
+	db, err := services.NewDatabaseServiceAt(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	folders, _ := db.GetActiveFolders()
+	tasks, _ := db.GetGlobalTasks()
+	db.Close()
+	if len(folders) != 2 || tasks == nil || tasks.Total != 2 {
+		t.Errorf("after discover: %d folder(s), tasks %+v", len(folders), tasks)
+	}
+
+	// A second scan finds them registered.
+	out.Reset()
+	if err := RunDiscover(root, dbPath, []string{"--ignore", "skip"}, out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out.String(), "2 notes folder(s) under "+root+", 0 registered\n") {
+		t.Errorf("second scan output = %q", out.String())
+	}
+}
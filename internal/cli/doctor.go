@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+)
+
+const doctorHelp = `USAGE:
+    noteflow-go doctor [--fix] [--json]
+
+Checks the NoteFlow project in the current directory and lists what it
+finds, one problem per line:
+
+    structure    text the parser ignores (no "## TIMESTAMP" header) or
+                 headers without a timestamp
+    separators   duplicate, missing or mangled <!-- note --> lines, and
+                 Windows line endings, which make notes run together
+    assets       files under assets/ no note links to, and links to
+                 files that aren't there
+    archives     archive links whose file is gone, and reader copies
+                 whose archive is gone
+    tasks        the task DB (~/.config/noteflow/tasks.db) disagreeing
+                 with notes.md, or the folder not registered at all
+
+Exits 1 while problems remain.
+
+FLAGS:
+    --fix            Repair what can be repaired safely: separators and
+                     line endings are rewritten in notes.md (no text is
+                     dropped) and the folder's tasks are re-synced.
+                     Orphaned and missing files are only reported.
+    --json           Emit the report as JSON
+    --help, -h       Show this help and exit
+`
+
+// ErrDoctorIssues is returned by RunDoctor when problems remain after any
+// repairs, so main can exit non-zero after printing the report.
+var ErrDoctorIssues = errors.New("problems found")
+
+// RunDoctor checks the project in basePath against the task DB at dbPath
+// (skipped when there is no DB yet) and prints what it finds.
+//
+// Usage:
+//
+//	noteflow doctor [--fix] [--json]
+func RunDoctor(basePath, dbPath string, args []string, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, doctorHelp)
+			return nil
+		}
+	}
+
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fix := fs.Bool("fix", false, "repair what can be repaired")
+	jsonOut := fs.Bool("json", false, "emit JSON instead of human format")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	db, err := openTaskDB(dbPath)
+	if err != nil {
+		return err
+	}
+	if db != nil {
+		defer db.Close()
+	}
+	report, err := services.Doctor(basePath, db, *fix)
+	if err != nil {
+		return err
+	}
+
+	remaining, fixable := 0, 0
+	for _, issue := range report.Issues {
+		if !issue.Fixed {
+			remaining++
+			if issue.Fixable {
+				fixable++
+			}
+		}
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(stdout, "%s: %d notes\n", report.Path, report.Notes)
+		for _, issue := range report.Issues {
+			line := fmt.Sprintf("%-11s ", issue.Check)
+			if issue.Line > 0 {
+				line += fmt.Sprintf("line %d: ", issue.Line)
+			}
+			line += issue.Message
+			switch {
+			case issue.Fixed:
+				line += "  [fixed]"
+			case issue.Fixable:
+				line += "  [fixable]"
+			}
+			fmt.Fprintln(stdout, line)
+		}
+		switch {
+		case remaining == 0 && len(report.Issues) == 0:
+			fmt.Fprintln(stdout, "no problems found")
+		case remaining == 0:
+			fmt.Fprintf(stdout, "fixed %d problem(s)\n", len(report.Issues))
+		case fixable > 0:
+			fmt.Fprintf(stdout, "%d problem(s), %d fixable with --fix\n", remaining, fixable)
+		default:
+			fmt.Fprintf(stdout, "%d problem(s) to look at\n", remaining)
+		}
+	}
+	if remaining > 0 {
+		return ErrDoctorIssues
+	}
+	return nil
+}
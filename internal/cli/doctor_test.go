@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(t.TempDir(), "tasks.db")
+	notes := "## 2026-05-12 09:00:00 - a\n\nfirst\n\n<!-- note -->\n\n<!-- note -->\n## 2026-05-11 09:00:00\n\nsecond\n"
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte(notes), 0644)
+
+	out := &bytes.Buffer{}
+	err := RunDoctor(dir, dbPath, nil, out)
+	if !errors.Is(err, ErrDoctorIssues) {
+		t.Fatalf("RunDoctor: err = %v, want ErrDoctorIssues", err)
+	}
+	if !strings.Contains(out.String(), "separators  line 5: duplicate separator (empty note)  [fixable]") {
+		t.Errorf("output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := RunDoctor(dir, dbPath, []string{"--fix"}, out); err != nil {
+		t.Fatalf("RunDoctor --fix: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "fixed 1 problem(s)") {
+		t.Errorf("output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := RunDoctor(dir, dbPath, nil, out); err != nil || !strings.Contains(out.String(), "no problems found") {
+		t.Errorf("after fix: err %v, output:\n%s", err, out.String())
+	}
+}
@@ -0,0 +1,285 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+	"github.com/Xafloc/NoteFlow-Go/internal/themes"
+)
+
+const exportHelp = `USAGE:
+    noteflow-go export [--format zip|html|json] [-o PATH] [--theme NAME]
+                       [--include PATTERN]... [--exclude PATTERN]...
+    noteflow-go export --format pdf|epub [-o PATH] [--title T] [--tag TAG]
+                       [--mention NAME] [--from DAY] [--to DAY]
+
+Exports the NoteFlow project in the current directory: notes.md,
+trash.md, archive.md, .noteflow.json, templates/ and the assets tree
+(uploads, archived sites, note history). Nothing else in the folder is
+included.
+
+FORMATS:
+    zip     The files as they are, for backups (default)
+    html    A static site in a directory: index.html with every note
+            rendered in your theme, and the assets it links; open it in
+            any browser or publish the directory as is (GitHub Pages, any
+            web server). Links to archived sites that aren't exported, or
+            that browsers can't show (MHTML, WARC), go to their reader
+            copy or the original page instead
+    json    The parsed notes with their tasks, and every file with its
+            content base64-encoded
+    pdf     The notes, oldest first, in one paginated PDF for sharing,
+            e.g. as a status report: a cover with a linked table of
+            contents, then each note from a new page. --tag, --mention,
+            --from and --to pick the notes. Printed by Chrome or
+            Chromium, found as for PDF archives (archive.chrome_path,
+            NOTEFLOW_CHROME_PATH)
+    epub    The notes, oldest first, as an e-book for e-readers: one
+            chapter per note with the images it shows embedded, and a
+            table of contents. Picks notes as pdf does
+
+FLAGS:
+    --format F       zip, html, json, pdf or epub; by default taken from
+                     -o's extension (.zip, .json, .pdf, .epub), else zip
+    -o PATH          Where to write. Default: noteflow-FOLDER-TIMESTAMP.zip,
+                     .json, or a directory for html. "-" writes zip or
+                     json to stdout
+    --theme NAME     Color theme of an html export: dark-orange, dark-blue
+                     or light-blue. Default: the theme in
+                     ~/.config/noteflow/noteflow.json
+    --include P      Only export files matching P (repeatable)
+    --exclude P      Leave out files matching P (repeatable)
+    --title T        Title of a pdf or epub export; default the folder's
+                     name
+    --tag TAG        pdf, epub: only notes tagged TAG or a tag nested
+                     under it
+    --mention NAME   pdf, epub: only notes mentioning @NAME
+    --from, --to D   pdf, epub: only notes written from / up to day D
+                     (YYYY-MM-DD, inclusive)
+    --help, -h       Show this help and exit
+
+Patterns are paths relative to the folder, with * and ? wildcards. A
+pattern matches a file or any directory above it, and one without a slash
+also matches file names: "assets/sites", "*.pdf", "assets/.history".
+
+EXAMPLES:
+    noteflow-go export
+    noteflow-go export --exclude assets/.history --exclude '*.mp4'
+    noteflow-go export --format html -o ~/share/notes-site
+    noteflow-go export --format html --theme light-blue --exclude assets/sites -o docs
+    noteflow-go export -o - --format json | jq '.notes[].title'
+    noteflow-go export -o status.pdf --tag status --from 2026-10-01
+    noteflow-go export -o essays.epub --tag essay --title "Essays"
+`
+
+// patternList is a flag that can be given several times.
+type patternList []string
+
+func (p *patternList) String() string { return strings.Join(*p, ",") }
+
+func (p *patternList) Set(v string) error {
+	*p = append(*p, v)
+	return nil
+}
+
+// RunExport exports the project in basePath.
+//
+// Usage:
+//
+//	noteflow export [--format zip|html|json] [-o PATH] [--theme NAME] [--include P]... [--exclude P]...
+//	noteflow export --format pdf|epub [-o PATH] [--title T] [--tag TAG] [--mention NAME] [--from DAY] [--to DAY]
+//
+// A summary line goes to stdout, unless the export itself does.
+func RunExport(basePath string, args []string, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, exportHelp)
+			return nil
+		}
+	}
+
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := fs.String("format", "", "zip, html, json, pdf or epub")
+	output := fs.String("o", "", "output path, or - for stdout")
+	var opts services.ExportOptions
+	config := userConfig()
+	theme := config.Theme
+	if themes.AvailableThemes[theme] == nil {
+		theme = "" // the UI falls back to the default too
+	}
+	fs.StringVar(&opts.Theme, "theme", theme, "color theme of an html export")
+	var doc services.DocumentOptions
+	fs.StringVar(&doc.Title, "title", "", "title of a pdf or epub export")
+	fs.StringVar(&doc.Tag, "tag", "", "pdf, epub: only notes with this tag")
+	fs.StringVar(&doc.Mention, "mention", "", "pdf, epub: only notes mentioning @name")
+	fs.StringVar(&doc.From, "from", "", "pdf, epub: only notes from this day")
+	fs.StringVar(&doc.To, "to", "", "pdf, epub: only notes up to this day")
+	fs.Var((*patternList)(&opts.Include), "include", "only export files matching this pattern")
+	fs.Var((*patternList)(&opts.Exclude), "exclude", "leave out files matching this pattern")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	opts.Format = *format
+	if opts.Format == "" {
+		opts.Format = services.ExportFormatZip
+		switch strings.ToLower(filepath.Ext(*output)) {
+		case ".json":
+			opts.Format = services.ExportFormatJSON
+		case ".pdf":
+			opts.Format = services.ExportFormatPDF
+		case ".epub":
+			opts.Format = services.ExportFormatEPUB
+		}
+	}
+	if err := services.ValidateExportOptions(opts); err != nil {
+		return err
+	}
+	if *output == "-" && opts.Format == services.ExportFormatHTML {
+		return fmt.Errorf("an html export is a directory; give -o DIR")
+	}
+
+	if !storage.HasNotes(basePath) {
+		return fmt.Errorf("no notes.md in %s", basePath)
+	}
+	manager, err := services.NewNoteManager(basePath)
+	if err != nil {
+		return fmt.Errorf("open notes.md: %w", err)
+	}
+
+	path := *output
+	if path == "" {
+		path = defaultExportPath(basePath, opts.Format, time.Now())
+	}
+	if opts.Format == services.ExportFormatPDF || opts.Format == services.ExportFormatEPUB {
+		return exportDocument(manager, opts.Format, config.Archive, doc, path, stdout)
+	}
+	if opts.Format == services.ExportFormatHTML {
+		n, err := manager.ExportHTML(path, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "exported %d note(s) and %d file(s) to %s\n", len(manager.GetAllNotes()), n, filepath.Join(path, "index.html"))
+		return nil
+	}
+
+	write := manager.ExportZip
+	if opts.Format == services.ExportFormatJSON {
+		write = manager.ExportJSON
+	}
+	if path == "-" {
+		_, err := write(stdout, opts)
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	n, err := write(f, opts)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	fmt.Fprintf(stdout, "exported %d file(s) to %s\n", n, path)
+	return nil
+}
+
+// exportDocument writes the notes doc selects to path as one PDF, printed
+// with the browser of archive, or as an EPUB book.
+func exportDocument(manager *services.NoteManager, format string, archive models.ArchiveConfig, doc services.DocumentOptions, path string, stdout io.Writer) error {
+	if doc.Tag != "" {
+		tag, ok := models.NormalizeTagName(doc.Tag)
+		if !ok {
+			return fmt.Errorf("invalid tag %q", doc.Tag)
+		}
+		doc.Tag = tag
+	}
+	if doc.Mention != "" {
+		mention, ok := models.NormalizeMentionName(doc.Mention)
+		if !ok {
+			return fmt.Errorf("invalid mention %q", doc.Mention)
+		}
+		doc.Mention = mention
+	}
+	if path != "-" {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+	}
+	var data []byte
+	var n int
+	var err error
+	if format == services.ExportFormatEPUB {
+		var buf bytes.Buffer
+		n, err = manager.ExportEPUB(&buf, doc, time.Now())
+		data = buf.Bytes()
+	} else {
+		manager.SetArchiveConfig(archive)
+		data, n, err = manager.ExportPDF(doc, time.Now())
+	}
+	if err != nil {
+		return err
+	}
+	if path == "-" {
+		_, err := stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "exported %d note(s) to %s\n", n, path)
+	return nil
+}
+
+// userConfig returns the user config with its NOTEFLOW_* overrides, or
+// the defaults when there is none. Unlike models.LoadConfig it never
+// creates the file.
+func userConfig() models.Config {
+	config := *models.DefaultConfig()
+	if configPath, err := models.DefaultConfigPath(); err == nil {
+		if data, err := os.ReadFile(configPath); err == nil {
+			json.Unmarshal(data, &config)
+		}
+	}
+	if withEnv, err := config.WithEnv(); err == nil {
+		config = withEnv
+	}
+	return config
+}
+
+// defaultExportPath names an export after the folder and the time, in the
+// folder itself.
+func defaultExportPath(basePath, format string, now time.Time) string {
+	name := filepath.Join(basePath, "noteflow-"+filepath.Base(basePath)+"-"+now.Format("20060102-150405"))
+	switch format {
+	case services.ExportFormatZip:
+		return name + ".zip"
+	case services.ExportFormatJSON:
+		return name + ".json"
+	case services.ExportFormatPDF:
+		return name + ".pdf"
+	case services.ExportFormatEPUB:
+		return name + ".epub"
+	}
+	return name
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExport_JSON(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte("## 2026-05-12 09:00:00 - Plan\n\n- [ ] ship\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "assets", "files"), 0755)
+	os.WriteFile(filepath.Join(dir, "assets", "files", "a.txt"), []byte("hello"), 0644)
+
+	out := &bytes.Buffer{}
+	if err := RunExport(dir, []string{"-o", "-", "--format", "json", "--exclude", "notes.md"}, out); err != nil {
+		t.Fatalf("RunExport: %v", err)
+	}
+	var dump struct {
+		Notes []struct{ Title string }
+		Files []struct {
+			Path string
+			Data []byte
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &dump); err != nil {
+		t.Fatalf("output isn't JSON: %v", err)
+	}
+	if len(dump.Notes) != 1 || dump.Notes[0].Title != "Plan" {
+		t.Errorf("notes = %+v", dump.Notes)
+	}
+	if len(dump.Files) != 1 || dump.Files[0].Path != "assets/files/a.txt" || string(dump.Files[0].Data) != "hello" {
+		t.Errorf("files = %+v", dump.Files)
+	}
+}
+
+func TestExport_DefaultZip(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte("## 2026-05-12 09:00:00\n\nhi\n"), 0644)
+
+	out := &bytes.Buffer{}
+	if err := RunExport(dir, nil, out); err != nil {
+		t.Fatalf("RunExport: %v", err)
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "noteflow-*.zip"))
+	if len(matches) != 1 || !strings.Contains(out.String(), "exported 1 file(s) to "+matches[0]) {
+		t.Errorf("zips %v, output %q", matches, out.String())
+	}
+
+	if err := RunExport(dir, []string{"--format", "html", "-o", "-"}, out); err == nil {
+		t.Error("html to stdout accepted")
+	}
+	if err := RunExport(dir, []string{"--format", "html", "--theme", "neon", "-o", filepath.Join(t.TempDir(), "site")}, out); err == nil {
+		t.Error("unknown theme accepted")
+	}
+	if err := RunExport(t.TempDir(), nil, out); err == nil {
+		t.Error("export without notes.md succeeded")
+	}
+}
+
+func TestExport_PDF(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the fake browser")
+	}
+	t.Setenv("HOME", t.TempDir())
+	// The fake browser "prints" by copying the page it was given.
+	bin := filepath.Join(t.TempDir(), "chrome")
+	script := "#!/bin/sh\nfor a in \"$@\"; do case \"$a\" in --print-to-pdf=*) out=\"${a#--print-to-pdf=}\";; file://*) src=\"${a#file://}\";; esac; done\ncp \"$src\" \"$out\"\n"
+	if err := os.WriteFile(bin, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NOTEFLOW_CHROME_PATH", bin)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte("## 2026-10-14 09:00:00 - Week 42\n\n#status done\n\n<!-- note -->\n## 2026-10-13 09:00:00 - Other\n\nhi\n"), 0644)
+
+	out := &bytes.Buffer{}
+	path := filepath.Join(t.TempDir(), "status.pdf")
+	if err := RunExport(dir, []string{"-o", path, "--tag", "#status"}, out); err != nil {
+		t.Fatalf("RunExport: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "Week 42") || strings.Contains(string(data), "Other") {
+		t.Errorf("pdf = %.200s, %v", data, err)
+	}
+	if !strings.Contains(out.String(), "exported 1 note(s) to "+path) {
+		t.Errorf("output %q", out.String())
+	}
+	if err := RunExport(dir, []string{"-o", path}, out); err == nil {
+		t.Error("overwrote an existing pdf")
+	}
+}
+
+func TestExport_EPUB(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte("## 2026-10-14 09:00:00 - Week 42\n\n#essay done\n\n<!-- note -->\n## 2026-10-13 09:00:00 - Other\n\nhi\n"), 0644)
+
+	out := &bytes.Buffer{}
+	path := filepath.Join(t.TempDir(), "essays.epub")
+	if err := RunExport(dir, []string{"-o", path, "--tag", "essay"}, out); err != nil {
+		t.Fatalf("RunExport: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.HasPrefix(string(data), "PK") || !strings.Contains(string(data), "application/epub+zip") {
+		t.Errorf("epub = %.100q, %v", data, err)
+	}
+	if !strings.Contains(out.String(), "exported 1 note(s) to "+path) {
+		t.Errorf("output %q", out.String())
+	}
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/gtasks"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+const googleAuthHelp = `USAGE:
+    noteflow-go google-auth [--client-id ID --client-secret SECRET]
+
+Authorizes NoteFlow to mirror tasks into Google Tasks. Create an OAuth
+client of type "Desktop app" in Google Cloud Console (APIs & Services →
+Credentials, with the Google Tasks API enabled), then run this command
+with its ID and secret. It prints a consent URL; after you approve,
+Google redirects back to a one-shot listener on 127.0.0.1 and the
+refresh token is saved to ~/.config/noteflow/noteflow.json.
+
+The client ID and secret are remembered, so re-authorizing later needs
+no flags.
+
+Then pick which folders mirror, in each folder's .noteflow.json:
+    {"google_tasks": {"list_id": "", "tag": ""}}
+An empty list_id means your default list; tag limits the mirror to
+tasks carrying #tag.
+
+FLAGS:
+    --client-id ID          OAuth client ID
+    --client-secret SECRET  OAuth client secret
+    --help, -h              Show this help and exit
+`
+
+// googleAuthTimeout bounds how long we wait for the browser round trip.
+const googleAuthTimeout = 5 * time.Minute
+
+// RunGoogleAuth runs the installed-app OAuth flow and stores the resulting
+// refresh token in the user config at configPath.
+func RunGoogleAuth(configPath string, args []string, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, googleAuthHelp)
+			return nil
+		}
+	}
+
+	fs := flag.NewFlagSet("google-auth", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	clientID := fs.String("client-id", "", "OAuth client ID")
+	clientSecret := fs.String("client-secret", "", "OAuth client secret")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	config, err := models.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if *clientID != "" {
+		config.Google.ClientID = *clientID
+	}
+	if *clientSecret != "" {
+		config.Google.ClientSecret = *clientSecret
+	}
+	if config.Google.ClientID == "" || config.Google.ClientSecret == "" {
+		return fmt.Errorf("no OAuth client configured (pass --client-id and --client-secret; see --help)")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), googleAuthTimeout)
+	defer cancel()
+	refresh, err := googleAuth(ctx, config.Google.ClientID, config.Google.ClientSecret, func(authURL string) {
+		fmt.Fprintf(stdout, "Open this URL in your browser to authorize NoteFlow:\n\n    %s\n\nWaiting for Google to redirect back...\n", authURL)
+	})
+	if err != nil {
+		return err
+	}
+
+	config.Google.RefreshToken = refresh
+	if err := models.SaveConfig(config, configPath); err != nil {
+		return fmt.Errorf("save config: %w", err)
+	}
+	fmt.Fprintln(stdout, "authorized: Google Tasks refresh token saved")
+	return nil
+}
+
+// googleAuth listens on a loopback port, hands the consent URL to open, and
+// exchanges the code Google redirects back with for a refresh token.
+func googleAuth(ctx context.Context, clientID, clientSecret string, open func(authURL string)) (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("start callback listener: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://%s/callback", ln.Addr())
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	state := hex.EncodeToString(buf)
+
+	type result struct {
+		code string
+		err  error
+	}
+	done := make(chan result, 1)
+	finish := func(res result) {
+		select {
+		case done <- res:
+		default: // a duplicate redirect; the first one wins
+		}
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		switch {
+		case q.Get("state") != state:
+			http.Error(w, "state mismatch", http.StatusBadRequest)
+			return
+		case q.Get("error") != "":
+			fmt.Fprintln(w, "Authorization was denied. You can close this tab.")
+			finish(result{err: fmt.Errorf("authorization denied: %s", q.Get("error"))})
+		default:
+			fmt.Fprintln(w, "NoteFlow is authorized. You can close this tab.")
+			finish(result{code: q.Get("code")})
+		}
+	})}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	open(gtasks.AuthURL(clientID, redirectURI, state))
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return "", res.err
+		}
+		return gtasks.ExchangeCode(ctx, clientID, clientSecret, res.code, redirectURI)
+	case <-ctx.Done():
+		return "", fmt.Errorf("timed out waiting for authorization")
+	}
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/gtasks"
+)
+
+func TestGoogleAuth_LoopbackFlow(t *testing.T) {
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != "the-code" || r.Form.Get("grant_type") != "authorization_code" {
+			t.Errorf("token form = %v", r.Form)
+		}
+		w.Write([]byte(`{"access_token":"at","refresh_token":"rt","expires_in":3600}`))
+	}))
+	defer tokenSrv.Close()
+	orig := gtasks.TokenEndpoint
+	gtasks.TokenEndpoint = tokenSrv.URL
+	defer func() { gtasks.TokenEndpoint = orig }()
+
+	// Stand in for the browser: follow the consent URL's redirect_uri as
+	// Google would after the user approves.
+	browser := func(authURL string) {
+		u, _ := url.Parse(authURL)
+		q := u.Query()
+		cb := q.Get("redirect_uri") + "?code=the-code&state=" + q.Get("state")
+		go func() {
+			resp, err := http.Get(cb)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+
+	refresh, err := googleAuth(context.Background(), "id", "secret", browser)
+	if err != nil {
+		t.Fatalf("googleAuth: %v", err)
+	}
+	if refresh != "rt" {
+		t.Errorf("refresh token = %q, want rt", refresh)
+	}
+}
+
+func TestRunGoogleAuth_RequiresClient(t *testing.T) {
+	configPath := t.TempDir() + "/noteflow.json"
+	if err := RunGoogleAuth(configPath, nil, io.Discard); err == nil {
+		t.Error("expected error without an OAuth client")
+	}
+}
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+)
+
+const importHelp = `USAGE:
+    noteflow-go import [--mode merge|restore] [--json] FORMAT PATH
+
+Imports notes into the NoteFlow project in the current directory, creating
+notes.md if there is none. By default the notes are merged in: each is
+placed by its date, and one that reads the same as a note already here is
+skipped, so importing twice is harmless. --mode restore replaces the
+notes instead; the replaced notes.md goes to the backups like any save.
+
+FORMATS:
+    zip        An archive made by 'noteflow-go export' or GET /api/export.zip:
+               its notes and its assets tree
+    json       A notes JSON document (GET /api/notes/export.json, see
+               docs/20261017_notes_json_schema.md); "-" reads stdin
+    obsidian   An Obsidian vault: its directory, or a zip of it. Every
+               markdown file becomes a note titled with its name, dated by
+               its "created" or "date" frontmatter, else when it was last
+               modified. Frontmatter is kept as the note's metadata (nested
+               values and multi-line text are dropped). [[Note#Heading|label]]
+               links become [[Note|label]] wiki links, aliases included;
+               embedded and linked attachments are copied to assets/ and
+               images shown. .obsidian/, .trash/ and other hidden folders
+               are skipped
+    keep       A Google Takeout export of Keep: its directory, or the
+               Takeout zip. Each note, read from its JSON file (or its HTML
+               in older exports), keeps its title and creation date; list
+               items become tasks, ticked as they were, labels #tags and
+               links a list after the text. Attachments are copied to
+               assets/ and images shown. Notes in Keep's trash are skipped
+    joplin     A Joplin export (.jex, "Export > JEX" in Joplin). Notebooks
+               become folders under this one, nested as they are, and are
+               registered in the task DB; notes without one come here.
+               To-dos become notes opening with their task, ticked when
+               completed and with their due date. Attachments are stored
+               with the notes using them, links between notes become wiki
+               links, and tags #tags. Encrypted items can't be imported
+
+FLAGS:
+    --mode M         merge (default) or restore
+    --json           Print the import result as JSON
+    --help, -h       Show this help and exit
+
+OUTPUT:
+    imported N note(s) from PATH: A added, S skipped, F file(s) written
+    missing: FILE    An attachment a note links to that wasn't found;
+                     the link is left as it was
+    folder NAME: N note(s) in DIR, A added, S skipped
+                     For joplin, each notebook's folder
+
+EXAMPLES:
+    noteflow-go import obsidian ~/Documents/Vault
+    noteflow-go import joplin ~/Downloads/notes.jex
+    noteflow-go import keep ~/Downloads/takeout-20261017T120000Z-001.zip
+    noteflow-go import --mode restore zip noteflow-api-20261017-120000.zip
+    curl -s localhost:8000/api/notes/export.json | noteflow-go import json -
+`
+
+// RunImport imports notes into the project in basePath. The folders a
+// Joplin import makes for notebooks are registered in the task DB at
+// dbPath.
+//
+// Usage:
+//
+//	noteflow import [--mode merge|restore] [--json] zip|json|joplin|keep|obsidian PATH
+//
+// Flags may also follow the format.
+func RunImport(basePath, dbPath string, args []string, stdin io.Reader, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, importHelp)
+			return nil
+		}
+	}
+
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	mode := fs.String("mode", services.ImportMerge, "merge or restore")
+	asJSON := fs.Bool("json", false, "print the import result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("usage: noteflow import [--mode merge|restore] FORMAT PATH (see --help)")
+	}
+	format := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: noteflow import [--mode merge|restore] %s PATH", format)
+	}
+	path := fs.Arg(0)
+	if *mode != services.ImportMerge && *mode != services.ImportRestore {
+		return fmt.Errorf("--mode must be merge or restore")
+	}
+
+	// Read the input before opening the project, so that a bad path or
+	// document leaves it alone.
+	var run func(*services.NoteManager) (*services.ImportResult, error)
+	switch format {
+	case "zip", "obsidian", "keep":
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if format != "zip" && info.IsDir() {
+			dir := os.DirFS(path)
+			run = func(manager *services.NoteManager) (*services.ImportResult, error) {
+				if format == "keep" {
+					return manager.ImportKeep(dir, *mode, time.Now())
+				}
+				return manager.ImportObsidian(dir, *mode, time.Now())
+			}
+			break
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := zip.NewReader(f, info.Size()); err != nil {
+			return fmt.Errorf("%s is not a zip archive: %v", path, strings.TrimPrefix(err.Error(), "zip: "))
+		}
+		run = func(manager *services.NoteManager) (*services.ImportResult, error) {
+			switch format {
+			case "zip":
+				return manager.ImportZip(f, info.Size(), *mode)
+			case "keep":
+				return manager.ImportKeepZip(f, info.Size(), *mode, time.Now())
+			}
+			return manager.ImportObsidianZip(f, info.Size(), *mode, time.Now())
+		}
+	case "json":
+		r := stdin
+		if path != "-" {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		var doc services.NotesJSON
+		if err := json.NewDecoder(r).Decode(&doc); err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
+		}
+		run = func(manager *services.NoteManager) (*services.ImportResult, error) {
+			return manager.ImportNotesJSON(&doc, *mode, time.Now())
+		}
+	case "joplin":
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		run = func(manager *services.NoteManager) (*services.ImportResult, error) {
+			return manager.ImportJEX(f, *mode, time.Now())
+		}
+	default:
+		return fmt.Errorf("unknown format %q (want zip, json, joplin, keep or obsidian)", format)
+	}
+
+	manager, err := services.NewNoteManager(basePath)
+	if err != nil {
+		return fmt.Errorf("open notes.md: %w", err)
+	}
+	defer manager.Close()
+	result, err := run(manager)
+	if err != nil {
+		return err
+	}
+	if err := registerImportedFolders(dbPath, manager.GetBasePath(), result); err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	fmt.Fprintf(stdout, "imported %d note(s) from %s: %d added, %d skipped, %d file(s) written\n",
+		result.Notes, path, result.Added, result.Skipped, result.Files)
+	for _, name := range result.Missing {
+		fmt.Fprintf(stdout, "missing: %s\n", name)
+	}
+	for _, folder := range result.Folders {
+		name := folder.Name
+		if name == "" {
+			name = "(no notebook)"
+		}
+		fmt.Fprintf(stdout, "folder %s: %d note(s) in %s, %d added, %d skipped\n",
+			name, folder.Notes, folder.Path, folder.Added, folder.Skipped)
+	}
+	return nil
+}
+
+// registerImportedFolders registers the folders besides basePath that an
+// import wrote to in the task DB at dbPath, so that their tasks are listed.
+func registerImportedFolders(dbPath, basePath string, result *services.ImportResult) error {
+	var dirs []string
+	for _, folder := range result.Folders {
+		if folder.Path != basePath {
+			dirs = append(dirs, folder.Path)
+		}
+	}
+	if len(dirs) == 0 {
+		return nil
+	}
+	db, err := services.NewDatabaseServiceAt(dbPath)
+	if err != nil {
+		return fmt.Errorf("open task db: %w", err)
+	}
+	defer db.Close()
+	for _, dir := range dirs {
+		if err := registerFolder(db, dir); err != nil {
+			return fmt.Errorf("register %s: %w", dir, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+)
+
+func TestImport_ObsidianVault(t *testing.T) {
+	vault := t.TempDir()
+	os.MkdirAll(filepath.Join(vault, ".obsidian"), 0755)
+	os.MkdirAll(filepath.Join(vault, "img"), 0755)
+	os.WriteFile(filepath.Join(vault, ".obsidian", "app.json"), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(vault, "Plan.md"), []byte("---\ntags: [work]\n---\nSee [[Ideas]] ![[logo.png]] ![[lost.png]]"), 0644)
+	os.WriteFile(filepath.Join(vault, "Ideas.md"), []byte("- [ ] try it"), 0644)
+	os.WriteFile(filepath.Join(vault, "img", "logo.png"), []byte("png"), 0644)
+
+	dir := t.TempDir()
+	out := &bytes.Buffer{}
+	if err := RunImport(dir, filepath.Join(t.TempDir(), "tasks.db"), []string{"obsidian", vault}, nil, out); err != nil {
+		t.Fatalf("RunImport: %v", err)
+	}
+	if !strings.Contains(out.String(), "imported 2 note(s) from "+vault+": 2 added, 0 skipped, 1 file(s) written") ||
+		!strings.Contains(out.String(), "missing: lost.png") {
+		t.Errorf("output = %q", out.String())
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "notes.md"))
+	for _, want := range []string{"- Plan\n\n---\ntags: [work]\n---\nSee [[Ideas]] ![logo](/assets/images/logo.png)", "- Ideas\n\n- [ ] try it"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("notes.md lacks %q:\n%s", want, data)
+		}
+	}
+
+	out.Reset()
+	if err := RunImport(dir, filepath.Join(t.TempDir(), "tasks.db"), []string{"obsidian", "--mode", "merge", vault}, nil, out); err != nil {
+		t.Fatalf("RunImport again: %v", err)
+	}
+	if !strings.Contains(out.String(), "0 added, 2 skipped") {
+		t.Errorf("importing again: %q", out.String())
+	}
+	if err := RunImport(dir, filepath.Join(t.TempDir(), "tasks.db"), []string{"evernote", vault}, nil, out); err == nil {
+		t.Error("an unknown format was accepted")
+	}
+	if err := RunImport(dir, filepath.Join(t.TempDir(), "tasks.db"), []string{"zip", filepath.Join(vault, "Plan.md")}, nil, out); err == nil || !strings.Contains(err.Error(), "not a zip") {
+		t.Errorf("a markdown file as a zip: %v", err)
+	}
+}
+
+func TestImport_Joplin(t *testing.T) {
+	notebook, todo := strings.Repeat("1", 32), strings.Repeat("2", 32)
+	jex := filepath.Join(t.TempDir(), "notes.jex")
+	f, _ := os.Create(jex)
+	tw := tar.NewWriter(f)
+	for name, content := range map[string]string{
+		notebook + ".md": "Errands\n\nid: " + notebook + "\ntype_: 2",
+		todo + ".md":     "Buy milk\n\nid: " + todo + "\nparent_id: " + notebook + "\nis_todo: 1\ntodo_completed: 0\ntype_: 1",
+	} {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	f.Close()
+
+	dir, dbPath := t.TempDir(), filepath.Join(t.TempDir(), "tasks.db")
+	out := &bytes.Buffer{}
+	if err := RunImport(dir, dbPath, []string{"joplin", jex}, nil, out); err != nil {
+		t.Fatalf("RunImport: %v", err)
+	}
+	if !strings.Contains(out.String(), "folder Errands: 1 note(s) in "+filepath.Join(dir, "Errands")+", 1 added, 0 skipped") {
+		t.Errorf("output = %q", out.String())
+	}
+	db, err := services.NewDatabaseServiceAt(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	tasks, _ := db.GetGlobalTasks()
+	if tasks == nil || tasks.Total != 1 || !strings.Contains(tasks.Tasks[0].Content, "Buy milk") {
+		t.Errorf("registered tasks = %+v", tasks)
+	}
+}
+
+func TestImport_Keep(t *testing.T) {
+	takeout := t.TempDir()
+	os.MkdirAll(filepath.Join(takeout, "Keep"), 0755)
+	os.WriteFile(filepath.Join(takeout, "Keep", "Todo.json"), []byte(`{"title": "Todo", "listContent": [{"text": "call mum", "isChecked": false}], "labels": [{"name": "home"}]}`), 0644)
+
+	dir := t.TempDir()
+	out := &bytes.Buffer{}
+	if err := RunImport(dir, filepath.Join(t.TempDir(), "tasks.db"), []string{"keep", takeout}, nil, out); err != nil {
+		t.Fatalf("RunImport: %v", err)
+	}
+	if !strings.Contains(out.String(), "imported 1 note(s) from "+takeout+": 1 added") {
+		t.Errorf("output = %q", out.String())
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "notes.md"))
+	if !strings.Contains(string(data), "- Todo\n\n- [ ] call mum\n\n#home") {
+		t.Errorf("notes.md = %s", data)
+	}
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+)
+
+const initHelp = `USAGE:
+    noteflow-go init [--gitignore] [--no-register] [DIR]
+
+Sets up DIR (default: the current directory, created if missing) as a
+NoteFlow project, the way the server would on first start, without
+starting it:
+
+    notes.md         empty notes file
+    assets/          images/, files/ and sites/ for uploads and archives
+    .noteflow.json   per-folder settings (see the README), empty
+    tasks DB         the folder is registered in ~/.config/noteflow/tasks.db
+                     and its tasks synced, so 'noteflow-go tasks' and the
+                     global tasks page see it straight away
+
+Anything that already exists is left as it is, so init is safe to run in
+an existing project.
+
+FLAGS:
+    --gitignore      Add assets/, trash.md and .notes.md.bak to
+                     DIR/.gitignore, keeping uploads, archived sites, note
+                     history and the save backup out of git
+    --no-register    Don't register the folder in the task DB
+    --help, -h       Show this help and exit
+`
+
+// gitignoreEntries are the lines init --gitignore makes sure .gitignore
+// has: the assets tree (uploads, archives, note history), the trash and
+// the backup of notes.md.
+var gitignoreEntries = []string{"assets/", "trash.md", ".notes.md.bak"}
+
+// RunInit scaffolds a NoteFlow project in args' DIR, or basePath when none
+// is given, and registers it in the task DB at dbPath.
+//
+// Usage:
+//
+//	noteflow init [--gitignore] [--no-register] [DIR]
+//
+// Output is one line per step, saying what was created and what already
+// existed.
+func RunInit(basePath, dbPath string, args []string, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, initHelp)
+			return nil
+		}
+	}
+
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	gitignore := fs.Bool("gitignore", false, "add assets/, trash.md and .notes.md.bak to .gitignore")
+	noRegister := fs.Bool("no-register", false, "don't register the folder in the task DB")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("expected at most one DIR, got %d", fs.NArg())
+	}
+	dir := basePath
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("resolve DIR: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create %s: %w", dir, err)
+	}
+
+	report := func(name string, existed bool) {
+		if existed {
+			fmt.Fprintf(stdout, "exists:  %s\n", name)
+		} else {
+			fmt.Fprintf(stdout, "created: %s\n", name)
+		}
+	}
+
+	// NoteManager creates notes.md and the assets tree exactly as the
+	// server does.
+	notesExisted := exists(filepath.Join(dir, "notes.md"))
+	assetsExisted := exists(filepath.Join(dir, "assets"))
+	manager, err := services.NewNoteManager(dir)
+	if err != nil {
+		return fmt.Errorf("create notes.md: %w", err)
+	}
+	report("notes.md", notesExisted)
+	report("assets/", assetsExisted)
+
+	configExisted := exists(filepath.Join(dir, models.FolderConfigFile))
+	if !configExisted {
+		if err := models.SaveFolderConfig(dir, &models.FolderConfig{}); err != nil {
+			return fmt.Errorf("write %s: %w", models.FolderConfigFile, err)
+		}
+	}
+	report(models.FolderConfigFile, configExisted)
+
+	if *gitignore {
+		added, err := addGitignoreEntries(filepath.Join(dir, ".gitignore"), gitignoreEntries)
+		if err != nil {
+			return fmt.Errorf("update .gitignore: %w", err)
+		}
+		if len(added) == 0 {
+			fmt.Fprintln(stdout, "exists:  .gitignore entries")
+		} else {
+			fmt.Fprintf(stdout, "updated: .gitignore (+%s)\n", strings.Join(added, ", +"))
+		}
+	}
+
+	if *noRegister {
+		return nil
+	}
+	db, err := services.NewDatabaseServiceAt(dbPath)
+	if err != nil {
+		return fmt.Errorf("open task db: %w", err)
+	}
+	defer db.Close()
+	folder, err := db.RegisterFolder(dir)
+	if err != nil {
+		return fmt.Errorf("register folder: %w", err)
+	}
+	if err := db.SyncFolderTasks(folder.ID, manager.GetAllTasks()); err != nil {
+		return fmt.Errorf("sync tasks: %w", err)
+	}
+	fmt.Fprintf(stdout, "registered: folder %d in %s\n", folder.ID, dbPath)
+	return nil
+}
+
+// addGitignoreEntries appends the entries path doesn't list yet, creating
+// the file if needed, and returns those it added.
+func addGitignoreEntries(path string, entries []string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	have := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		have[strings.TrimSpace(line)] = true
+	}
+	var added []string
+	for _, e := range entries {
+		if !have[e] && !have["/"+e] {
+			added = append(added, e)
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	var b strings.Builder
+	b.Write(data)
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("# NoteFlow\n")
+	for _, e := range added {
+		b.WriteString(e + "\n")
+	}
+	return added, os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+)
+
+func TestInit(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "project")
+	dbPath := filepath.Join(t.TempDir(), "tasks.db")
+	out := &bytes.Buffer{}
+	if err := RunInit("", dbPath, []string{"--gitignore", dir}, out); err != nil {
+		t.Fatalf("RunInit: %v", err)
+	}
+	for _, name := range []string{"notes.md", "assets/images", "assets/files", "assets/sites", ".noteflow.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s not created: %v", name, err)
+		}
+	}
+	if got := readFile(t, filepath.Join(dir, ".gitignore")); got != "# NoteFlow\nassets/\ntrash.md\n.notes.md.bak\n" {
+		t.Errorf(".gitignore = %q", got)
+	}
+	if !strings.Contains(out.String(), "registered: folder ") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	db, err := services.NewDatabaseServiceAt(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	folders, _ := db.GetActiveFolders()
+	if len(folders) != 1 || folders[0].Path != dir {
+		t.Errorf("registered folders = %+v, want %s", folders, dir)
+	}
+}
+
+func TestInit_KeepsExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	notes := "## 2026-05-12 09:00:00\n\n- [ ] keep me\n"
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte(notes), 0644)
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("bin/\nassets/"), 0644)
+
+	out := &bytes.Buffer{}
+	if err := RunInit(dir, "", []string{"--gitignore", "--no-register"}, out); err != nil {
+		t.Fatalf("RunInit: %v", err)
+	}
+	if got := readFile(t, filepath.Join(dir, "notes.md")); got != notes {
+		t.Errorf("notes.md changed: %q", got)
+	}
+	if got := readFile(t, filepath.Join(dir, ".gitignore")); got != "bin/\nassets/\n# NoteFlow\ntrash.md\n.notes.md.bak\n" {
+		t.Errorf(".gitignore = %q", got)
+	}
+	if !strings.Contains(out.String(), "exists:  notes.md") || strings.Contains(out.String(), "registered") {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
@@ -0,0 +1,273 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+)
+
+const listHelp = `USAGE:
+    noteflow-go list [--tasks] [--json]
+
+Lists the notes in notes.md in the current directory, newest first, one
+per line:
+
+    INDEX  TIMESTAMP  TITLE  (DONE/TOTAL tasks)
+
+INDEX is the note's index in the API (/api/notes/INDEX). Nothing is
+written and no server is needed.
+
+FLAGS:
+    --tasks          Also print each note's tasks, indented under it
+    --json           Emit JSON instead (notes with their tasks)
+    --help, -h       Show this help and exit
+`
+
+const grepHelp = `USAGE:
+    noteflow-go grep [-i] [--tasks] [--json] PATTERN
+
+Prints the lines of notes.md in the current directory that match
+PATTERN, a Go regular expression (https://pkg.go.dev/regexp/syntax),
+grouped by note:
+
+    [INDEX] TIMESTAMP - TITLE
+      LINE: TEXT
+
+LINE counts from 1 at the first line of the note's body; a match in the
+title is line 0. Exits with status 1 when nothing matches, as grep does.
+
+FLAGS:
+    -i               Match case-insensitively
+    --tasks          Only match task lines ("- [ ] ...")
+    --json           Emit JSON instead
+    --help, -h       Show this help and exit
+
+EXAMPLES:
+    noteflow-go grep -i 'deploy(ment)?'
+    noteflow-go grep --tasks '#release'
+    noteflow-go grep --json TODO | jq '.[].matches[].text'
+`
+
+// checkboxRE finds a task checkbox on a line, as the note parser does.
+var checkboxRE = regexp.MustCompile(`\[[xX /]\]`)
+
+// ErrNoMatch is returned by RunGrep when no line matched, so main can exit
+// with grep's status 1 without printing an error.
+var ErrNoMatch = errors.New("no match")
+
+// listTask is a task as printed by list --json.
+type listTask struct {
+	Index    int        `json:"index"` // for /api/tasks/:index
+	Text     string     `json:"text"`  // tokens stripped, for display
+	Done     bool       `json:"done"`
+	Priority int        `json:"priority,omitempty"`
+	Due      *time.Time `json:"due,omitempty"`
+}
+
+// listNote is a note as printed by list --json.
+type listNote struct {
+	Index     int        `json:"index"` // for /api/notes/:index
+	Timestamp time.Time  `json:"timestamp"`
+	Title     string     `json:"title"`
+	Tasks     []listTask `json:"tasks"`
+}
+
+// grepMatch is one matching line of a note.
+type grepMatch struct {
+	Line int    `json:"line"` // 1-based in the body; 0 is the title
+	Text string `json:"text"`
+	Task bool   `json:"task"`
+}
+
+// grepNote is a note with matching lines, as printed by grep --json.
+type grepNote struct {
+	Index     int         `json:"index"`
+	Timestamp time.Time   `json:"timestamp"`
+	Title     string      `json:"title"`
+	Matches   []grepMatch `json:"matches"`
+}
+
+// RunList prints the notes of notes.md in basePath.
+//
+// Usage:
+//
+//	noteflow list [--tasks] [--json]
+func RunList(basePath string, args []string, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, listHelp)
+			return nil
+		}
+	}
+
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	withTasks := fs.Bool("tasks", false, "print each note's tasks")
+	jsonOut := fs.Bool("json", false, "emit JSON instead of human format")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	notes, err := loadNotes(basePath)
+	if err != nil {
+		return err
+	}
+
+	out := make([]listNote, len(notes))
+	for i, note := range notes {
+		out[i] = listNote{Index: i, Timestamp: note.Timestamp, Title: note.Title, Tasks: []listTask{}}
+		for _, task := range note.Tasks {
+			t := listTask{
+				Index:    task.Index,
+				Text:     stripCheckbox(models.CleanTaskText(task.Text)),
+				Done:     task.Checked,
+				Priority: task.Priority,
+			}
+			if !task.DueDate.IsZero() {
+				due := task.DueDate
+				t.Due = &due
+			}
+			out[i].Tasks = append(out[i].Tasks, t)
+		}
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	for _, n := range out {
+		line := fmt.Sprintf("%d  %s", n.Index, n.Timestamp.Format("2006-01-02 15:04"))
+		if n.Title != "" {
+			line += "  " + n.Title
+		}
+		if len(n.Tasks) > 0 {
+			done := 0
+			for _, t := range n.Tasks {
+				if t.Done {
+					done++
+				}
+			}
+			line += fmt.Sprintf("  (%d/%d tasks)", done, len(n.Tasks))
+		}
+		fmt.Fprintln(stdout, line)
+		if !*withTasks {
+			continue
+		}
+		for _, t := range n.Tasks {
+			state := "[ ]"
+			if t.Done {
+				state = "[x]"
+			}
+			fmt.Fprintf(stdout, "    %s %s\n", state, t.Text)
+		}
+	}
+	return nil
+}
+
+// RunGrep prints the lines of the notes in basePath that match a regular
+// expression. It returns ErrNoMatch when there are none.
+//
+// Usage:
+//
+//	noteflow grep [-i] [--tasks] [--json] PATTERN
+func RunGrep(basePath string, args []string, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, grepHelp)
+			return nil
+		}
+	}
+
+	fs := flag.NewFlagSet("grep", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	ignoreCase := fs.Bool("i", false, "match case-insensitively")
+	tasksOnly := fs.Bool("tasks", false, "only match task lines")
+	jsonOut := fs.Bool("json", false, "emit JSON instead of human format")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected one PATTERN, got %d arguments (see --help)", fs.NArg())
+	}
+	pattern := fs.Arg(0)
+	if *ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	notes, err := loadNotes(basePath)
+	if err != nil {
+		return err
+	}
+
+	out := []grepNote{}
+	for i, note := range notes {
+		var matches []grepMatch
+		if !*tasksOnly && note.Title != "" && re.MatchString(note.Title) {
+			matches = append(matches, grepMatch{Line: 0, Text: note.Title})
+		}
+		for n, line := range strings.Split(note.Content, "\n") {
+			isTask := checkboxRE.MatchString(line)
+			if (*tasksOnly && !isTask) || !re.MatchString(line) {
+				continue
+			}
+			matches = append(matches, grepMatch{Line: n + 1, Text: line, Task: isTask})
+		}
+		if len(matches) > 0 {
+			out = append(out, grepNote{Index: i, Timestamp: note.Timestamp, Title: note.Title, Matches: matches})
+		}
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+	} else {
+		for _, n := range out {
+			header := fmt.Sprintf("[%d] %s", n.Index, n.Timestamp.Format("2006-01-02 15:04:05"))
+			if n.Title != "" {
+				header += " - " + n.Title
+			}
+			fmt.Fprintln(stdout, header)
+			for _, m := range n.Matches {
+				fmt.Fprintf(stdout, "  %d: %s\n", m.Line, m.Text)
+			}
+		}
+	}
+	if len(out) == 0 {
+		return ErrNoMatch
+	}
+	return nil
+}
+
+// loadNotes loads the notes of basePath without creating anything: a
+// folder without notes.md is an error rather than a new, empty project.
+func loadNotes(basePath string) ([]*models.Note, error) {
+	if !storage.HasNotes(basePath) {
+		return nil, fmt.Errorf("no notes.md in %s", basePath)
+	}
+	manager, err := services.NewNoteManager(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("open notes.md: %w", err)
+	}
+	return manager.GetAllNotes(), nil
+}
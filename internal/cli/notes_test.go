@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	RunAppend(dir, []string{"--title", "Groceries", "- [ ] milk\n- [x] eggs"}, nil, &bytes.Buffer{})
+
+	out := &bytes.Buffer{}
+	if err := RunList(dir, []string{"--tasks"}, out); err != nil {
+		t.Fatalf("RunList: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "0  ") || !strings.HasSuffix(lines[0], "  Groceries  (1/2 tasks)") {
+		t.Fatalf("output = %q", out.String())
+	}
+	if lines[1] != "    [ ] milk" || lines[2] != "    [x] eggs" {
+		t.Errorf("task lines = %q", lines[1:])
+	}
+
+	out.Reset()
+	if err := RunList(dir, []string{"--json"}, out); err != nil {
+		t.Fatalf("RunList --json: %v", err)
+	}
+	var notes []listNote
+	if err := json.Unmarshal(out.Bytes(), &notes); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(notes) != 1 || notes[0].Title != "Groceries" || len(notes[0].Tasks) != 2 || !notes[0].Tasks[1].Done {
+		t.Errorf("json = %+v", notes)
+	}
+}
+
+func TestList_NoNotesFile(t *testing.T) {
+	if err := RunList(t.TempDir(), nil, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "no notes.md") {
+		t.Errorf("err = %v, want no notes.md", err)
+	}
+}
+
+func TestGrep(t *testing.T) {
+	dir := t.TempDir()
+	RunAppend(dir, []string{"--title", "Deploy", "checked the logs\n- [ ] deploy to staging"}, nil, &bytes.Buffer{})
+	RunAppend(dir, []string{"unrelated"}, nil, &bytes.Buffer{})
+
+	out := &bytes.Buffer{}
+	if err := RunGrep(dir, []string{"-i", "deploy"}, out); err != nil {
+		t.Fatalf("RunGrep: %v", err)
+	}
+	want := "  0: Deploy\n  2: - [ ] deploy to staging\n"
+	if !strings.HasPrefix(out.String(), "[1] ") || !strings.HasSuffix(out.String(), want) {
+		t.Errorf("output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := RunGrep(dir, []string{"--tasks", "--json", "deploy"}, out); err != nil {
+		t.Fatalf("RunGrep --tasks: %v", err)
+	}
+	var notes []grepNote
+	json.Unmarshal(out.Bytes(), &notes)
+	if len(notes) != 1 || len(notes[0].Matches) != 1 || !notes[0].Matches[0].Task || notes[0].Matches[0].Line != 2 {
+		t.Errorf("json = %+v", notes)
+	}
+
+	if err := RunGrep(dir, []string{"nothing-like-this"}, &bytes.Buffer{}); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("no match: err = %v, want ErrNoMatch", err)
+	}
+	if err := RunGrep(dir, []string{"("}, &bytes.Buffer{}); err == nil || errors.Is(err, ErrNoMatch) {
+		t.Errorf("bad pattern: err = %v", err)
+	}
+}
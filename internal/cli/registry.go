@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+)
+
+const registryHelp = `USAGE:
+    noteflow-go registry export [-o FILE]
+    noteflow-go registry import [--rewrite OLD=NEW]... [--json] FILE|-
+
+Moves the task registry (~/.config/noteflow/tasks.db) to another machine.
+'export' writes every registered folder, forgotten ones included, with
+its alias, group, tasks and task history (added, completed, reopened,
+removed), plus the saved views, as JSON. 'import' merges such a file into this
+machine's registry:
+
+    - folders are matched by path; new ones are registered, known ones
+      keep their ID and take the file's alias and group
+    - a task replaces the one with its hash only if it changed later, and
+      tasks missing from the file are kept, so importing twice is harmless
+    - folders with no notes here yet are imported forgotten, tasks and
+      all; reactivate them on the global tasks page once the notes are
+      in place
+
+Active folders found here are re-read right away, so their tasks follow
+their notes. GET /api/global-registry/export and POST
+/api/global-registry/import do the same over the API.
+
+FLAGS:
+    -o FILE          Write the export to FILE instead of stdout
+    --rewrite O=N    Import folders under O as under N (repeatable), e.g.
+                     --rewrite /Users/ana=/home/ana
+    --json           Print the import result as JSON
+    --help, -h       Show this help and exit
+`
+
+// RunRegistry exports the task registry at dbPath as JSON, or imports
+// such an export into it.
+//
+// Usage:
+//
+//	noteflow registry export [-o FILE]
+//	noteflow registry import [--rewrite OLD=NEW]... [--json] FILE|-
+func RunRegistry(dbPath string, args []string, stdin io.Reader, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, registryHelp)
+			return nil
+		}
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("expected export or import")
+	}
+
+	fs := flag.NewFlagSet("registry", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	output := fs.String("o", "", "write the export to this file")
+	var rewrites []string
+	fs.Var((*patternList)(&rewrites), "rewrite", "move folders under OLD to NEW")
+	asJSON := fs.Bool("json", false, "emit JSON")
+	if err := fs.Parse(args[1:]); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	switch args[0] {
+	case "export":
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+		}
+		return exportRegistry(dbPath, *output, stdout)
+	case "import":
+		if fs.NArg() != 1 {
+			return fmt.Errorf("expected one FILE (- for stdin)")
+		}
+		var parsed []services.PathRewrite
+		for _, r := range rewrites {
+			rewrite, err := services.ParsePathRewrite(r)
+			if err != nil {
+				return err
+			}
+			parsed = append(parsed, rewrite)
+		}
+		return importRegistry(dbPath, fs.Arg(0), parsed, *asJSON, stdin, stdout)
+	}
+	return fmt.Errorf("unknown action %q (want export or import)", args[0])
+}
+
+func exportRegistry(dbPath, output string, stdout io.Writer) error {
+	db, err := openTaskDB(dbPath)
+	if err != nil {
+		return err
+	}
+	if db == nil {
+		return fmt.Errorf("no task DB at %s", dbPath)
+	}
+	defer db.Close()
+	export, err := db.ExportRegistry(time.Now())
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if output == "" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, 0600); err != nil {
+		return err
+	}
+	tasks := 0
+	for _, folder := range export.Folders {
+		tasks += len(folder.Tasks)
+	}
+	fmt.Fprintf(stdout, "exported %d folder(s), %d task(s) to %s\n", len(export.Folders), tasks, output)
+	return nil
+}
+
+func importRegistry(dbPath, file string, rewrites []services.PathRewrite, asJSON bool, stdin io.Reader, stdout io.Writer) error {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+	var export services.RegistryExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return fmt.Errorf("read registry export: %w", err)
+	}
+	export.RewritePaths(rewrites)
+
+	db, err := services.NewDatabaseServiceAt(dbPath)
+	if err != nil {
+		return fmt.Errorf("open task db: %w", err)
+	}
+	defer db.Close()
+	result, err := db.ImportRegistry(&export)
+	if err != nil {
+		return err
+	}
+
+	// Bring the active folders' tasks in line with their notes, as the
+	// server does when it starts tracking a folder.
+	folders, err := db.GetActiveFolders()
+	if err != nil {
+		return err
+	}
+	imported := map[string]bool{}
+	for _, dir := range append(result.Added, result.Updated...) {
+		imported[dir] = true
+	}
+	for _, folder := range folders {
+		if imported[folder.Path] {
+			if err := registerFolder(db, folder.Path); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: sync %s: %v\n", folder.Path, err)
+			}
+		}
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	for _, dir := range result.Added {
+		fmt.Fprintf(stdout, "added:   %s\n", dir)
+	}
+	for _, dir := range result.Updated {
+		fmt.Fprintf(stdout, "merged:  %s\n", dir)
+	}
+	for _, dir := range result.Missing {
+		fmt.Fprintf(stdout, "missing: %s (no notes here, imported as forgotten)\n", dir)
+	}
+	for dir, why := range result.Skipped {
+		fmt.Fprintf(stdout, "skipped: %s: %s\n", dir, why)
+	}
+	fmt.Fprintf(stdout, "%d folder(s) added, %d merged, %d task(s) and %d view(s) imported\n",
+		len(result.Added), len(result.Updated), result.Tasks, result.Views)
+	return nil
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+)
+
+func TestRegistryExportImport(t *testing.T) {
+	root := t.TempDir()
+	oldDir, newDir := filepath.Join(root, "old", "app"), filepath.Join(root, "new", "app")
+	notes := []byte("## 2026-05-12 09:00:00\n\n- [x] done\n- [ ] open\n")
+	for _, dir := range []string{oldDir, newDir} {
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "notes.md"), notes, 0644)
+	}
+
+	oldDB := filepath.Join(t.TempDir(), "tasks.db")
+	db, err := services.NewDatabaseServiceAt(oldDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registerFolder(db, oldDir); err != nil {
+		t.Fatal(err)
+	}
+	gone, _ := db.RegisterFolder(filepath.Join(root, "old", "gone"))
+	db.SetFolderLabels(gone.ID, "", "Archive")
+	before, _ := db.GetGlobalTasks()
+	db.Close()
+
+	file := filepath.Join(t.TempDir(), "registry.json")
+	out := &bytes.Buffer{}
+	if err := RunRegistry(oldDB, []string{"export", "-o", file}, nil, out); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "exported 2 folder(s), 2 task(s)") {
+		t.Errorf("export output = %q", out.String())
+	}
+
+	newDB := filepath.Join(t.TempDir(), "tasks.db")
+	out.Reset()
+	if err := RunRegistry(newDB, []string{"import", "--rewrite", filepath.Join(root, "old") + "=" + filepath.Join(root, "new"), file}, nil, out); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if !strings.Contains(out.String(), "missing: "+filepath.Join(root, "new", "gone")) {
+		t.Errorf("import output = %q", out.String())
+	}
+
+	db, err = services.NewDatabaseServiceAt(newDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	after, _ := db.GetGlobalTasks()
+	if len(after.Tasks) != 2 || after.Tasks[0].FolderPath != newDir {
+		t.Fatalf("tasks after import = %+v", after.Tasks)
+	}
+	for i, task := range after.Tasks {
+		if !task.LastUpdated.Equal(before.Tasks[i].LastUpdated) || task.Completed != before.Tasks[i].Completed {
+			t.Errorf("task %q = %v %v, was %v %v", task.Content, task.Completed, task.LastUpdated, before.Tasks[i].Completed, before.Tasks[i].LastUpdated)
+		}
+	}
+	folders, _ := db.GetFolders(true)
+	if len(folders) != 2 || folders[1].Active || folders[1].Group != "Archive" {
+		t.Errorf("folders after import = %+v", folders)
+	}
+
+	if err := RunRegistry(newDB, []string{"import", "--rewrite", "nope", file}, nil, out); err == nil {
+		t.Error("a rewrite without = was accepted")
+	}
+}
@@ -0,0 +1,285 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/auth"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+)
+
+const storageHelp = `USAGE:
+    noteflow-go storage [markdown|sqlite|files|encrypted|webdav]
+
+Shows or changes where the notes of the project in the current directory
+are kept:
+
+    markdown    notes.md, one file with every note (default)
+    sqlite      notes.db, a SQLite database with a row per note, indexed
+                by time and title; saves are transactions that write only
+                the notes that changed
+    files       notes/, a markdown file per note named after its time and
+                title, so the folder opens as an Obsidian or Logseq vault;
+                .md files added there by other apps are read as notes
+    encrypted   notes.md.enc, notes.md sealed with AES-256-GCM under a
+                key derived from a passphrase; trash.md and note history
+                are encrypted too, uploaded files are not
+    webdav      notes.md on a WebDAV server (Nextcloud, ownCloud, a NAS),
+                set in .noteflow.json as "webdav": {"url": "https://...
+                /notes.md", "username": "ana"} with the password in
+                NOTEFLOW_WEBDAV_PASSWORD. A copy is kept in
+                .notes.webdav.md for when the server is unreachable;
+                saves only replace the version last read, so edits from
+                another machine are never overwritten
+
+Converting copies every note into the new store, checks that they read
+back the same, records the mode in .noteflow.json and keeps the old file
+as .notes.md.bak, .notes.db.bak or .notes.bak. Stop the server first.
+
+The passphrase is taken from NOTEFLOW_PASSPHRASE, else read from the
+first line of stdin. Converting to encrypted asks for it twice, so a
+typo can't lock the notes away: on the first two lines of stdin, or once
+on stdin to repeat NOTEFLOW_PASSPHRASE. The new file is decrypted with
+it before the plain notes are deleted, instead of kept; backups made
+before, such as .notes.md.bak, assets/.backups or a git history, still
+hold them in the clear. There is no way back in without the passphrase.
+The server asks for it at start.
+
+Converting to webdav uploads the notes and fails if the server already
+has a notes.md; to use that one, set "storage": "webdav" in
+.noteflow.json instead.
+
+In the other modes notes.md is still there when you want it: convert
+back with 'noteflow-go storage markdown', or GET /api/notes/raw.
+
+FLAGS:
+    --help, -h       Show this help and exit
+`
+
+// storageFiles names the file, or directory, of each notes storage mode.
+var storageFiles = map[string]string{
+	models.NotesStorageMarkdown:  "notes.md",
+	models.NotesStorageSQLite:    storage.NotesDBFile,
+	models.NotesStorageFiles:     storage.NotesDir,
+	models.NotesStorageEncrypted: storage.EncryptedNotesFile,
+	models.NotesStorageWebDAV:    storage.WebDAVCacheFile,
+}
+
+// RunStorage shows or converts the notes storage mode of basePath.
+//
+// Usage:
+//
+//	noteflow storage [markdown|sqlite|files|encrypted|webdav]
+//
+// The passphrase of an encrypted folder comes from storage.PassphraseEnv,
+// else the first line of stdin.
+func RunStorage(basePath string, args []string, stdin io.Reader, stdout io.Writer) error {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, storageHelp)
+			return nil
+		}
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected argument %q", args[1])
+	}
+	if !storage.HasNotes(basePath) {
+		return fmt.Errorf("no notes.md in %s", basePath)
+	}
+	cfg, err := models.LoadFolderConfig(basePath)
+	if err != nil {
+		return err
+	}
+	current := cfg.NotesStorage()
+	if _, ok := storageFiles[current]; !ok {
+		return fmt.Errorf("unknown storage %q in %s", current, models.FolderConfigFile)
+	}
+	if current == models.NotesStorageEncrypted && !storage.IsUnlocked(basePath) {
+		passphrase, err := storagePassphrase(stdin)
+		if err != nil {
+			return err
+		}
+		if err := storage.Unlock(basePath, passphrase); err != nil {
+			return err
+		}
+	}
+
+	from := storage.NewFileStorageMode(basePath, current)
+	defer from.Close()
+	notes, err := from.LoadNotes()
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		fmt.Fprintf(stdout, "%s: %d note(s) in %s\n", current, len(notes), storageFiles[current])
+		return nil
+	}
+
+	target := args[0]
+	targetFile, ok := storageFiles[target]
+	if !ok {
+		return fmt.Errorf("unknown storage %q (want markdown, sqlite, files, encrypted or webdav)", target)
+	}
+	if target == current {
+		fmt.Fprintf(stdout, "already %s: %d note(s) in %s\n", current, len(notes), targetFile)
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(basePath, targetFile)); err == nil {
+		return fmt.Errorf("%s already exists; move it away first", targetFile)
+	}
+
+	var encryption *models.EncryptionFolderConfig
+	var passphrase string
+	if target == models.NotesStorageEncrypted {
+		if passphrase, err = newStoragePassphrase(stdin); err != nil {
+			return err
+		}
+		salt, err := storage.NewEncryptionSalt()
+		if err != nil {
+			return err
+		}
+		key, err := storage.DeriveKey(passphrase, salt)
+		if err != nil {
+			return err
+		}
+		storage.SetFolderKey(basePath, key)
+		encryption = &models.EncryptionFolderConfig{Salt: salt}
+	}
+
+	to := storage.NewFileStorageMode(basePath, target)
+	defer to.Close()
+	if err := to.SaveNotes(notes); err != nil {
+		if errors.Is(err, storage.ErrRemoteChanged) {
+			return fmt.Errorf("the WebDAV server already has a notes.md; to use it, set \"storage\": %q in %s", models.NotesStorageWebDAV, models.FolderConfigFile)
+		}
+		return err
+	}
+	if err := sameNotes(notes, to); err != nil {
+		to.Close()
+		os.RemoveAll(filepath.Join(basePath, targetFile))
+		return err
+	}
+
+	previous := *cfg
+	cfg.Storage = target
+	if target == models.NotesStorageMarkdown {
+		cfg.Storage = ""
+	}
+	if encryption != nil {
+		cfg.Encryption = encryption
+	}
+	if err := models.SaveFolderConfig(basePath, cfg); err != nil {
+		return err
+	}
+	if target == models.NotesStorageEncrypted {
+		// Open the new file as the server will, from the passphrase and
+		// the salt just saved, before the plain notes go.
+		if err := checkEncrypted(basePath, passphrase, notes); err != nil {
+			to.Close()
+			os.RemoveAll(filepath.Join(basePath, targetFile))
+			if err := models.SaveFolderConfig(basePath, &previous); err != nil {
+				return err
+			}
+			return fmt.Errorf("%s doesn't decrypt with the passphrase, so it was removed and %s kept: %w", targetFile, storageFiles[current], err)
+		}
+	}
+	resealed, err := to.ResealPrivateFiles()
+	if err != nil {
+		return fmt.Errorf("notes converted, but trash and history were not: %w", err)
+	}
+	from.Close()
+	oldFile := storageFiles[current]
+	backup := "." + strings.TrimPrefix(oldFile, ".") + ".bak"
+	if target == models.NotesStorageEncrypted {
+		// A plain copy beside the encrypted one would defeat it.
+		if err := os.RemoveAll(filepath.Join(basePath, oldFile)); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "converted %d note(s) to %s: %s, and encrypted %d trash and history file(s); %s removed\n", len(notes), target, targetFile, resealed, oldFile)
+		fmt.Fprintln(stdout, "older copies (.notes.md.bak, assets/.backups, git history) may still hold the notes in the clear")
+		return nil
+	}
+	if err := os.Rename(filepath.Join(basePath, oldFile), filepath.Join(basePath, backup)); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "converted %d note(s) to %s: %s (%s kept as %s)\n", len(notes), target, targetFile, oldFile, backup)
+	return nil
+}
+
+// storagePassphrase returns the passphrase in storage.PassphraseEnv, else
+// the first line of stdin.
+func storagePassphrase(stdin io.Reader) (string, error) {
+	if passphrase := os.Getenv(storage.PassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	return auth.ReadPassphrase(stdin, os.Stderr, "Passphrase: ")
+}
+
+// newStoragePassphrase returns the passphrase for a folder about to be
+// encrypted, asked for twice so that a typo can't lock the notes away:
+// storage.PassphraseEnv repeated on stdin, else the first two lines of
+// stdin.
+func newStoragePassphrase(stdin io.Reader) (string, error) {
+	passphrase := os.Getenv(storage.PassphraseEnv)
+	if passphrase == "" {
+		var err error
+		if passphrase, err = auth.ReadPassphrase(stdin, os.Stderr, "New passphrase: "); err != nil {
+			return "", err
+		}
+	}
+	repeated, err := auth.ReadPassphrase(stdin, os.Stderr, "Repeat the passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if repeated != passphrase {
+		return "", errors.New("the passphrases don't match")
+	}
+	return passphrase, nil
+}
+
+// checkEncrypted checks that the encrypted notes of basePath open with
+// passphrase and read back as notes.
+func checkEncrypted(basePath, passphrase string, notes []*models.Note) error {
+	if err := storage.Unlock(basePath, passphrase); err != nil {
+		return err
+	}
+	check := storage.NewFileStorageMode(basePath, models.NotesStorageEncrypted)
+	defer check.Close()
+	return sameNotes(notes, check)
+}
+
+// sameNotes checks that s reads back notes as they were saved. The files
+// mode orders notes by time alone, so notes saved in the same second may
+// come back in another order; the check ignores order.
+func sameNotes(notes []*models.Note, s *storage.FileStorage) error {
+	saved, err := s.LoadNotes()
+	if err != nil {
+		return err
+	}
+	if len(saved) != len(notes) {
+		return fmt.Errorf("conversion check: %d note(s) read back, want %d", len(saved), len(notes))
+	}
+	want, got := renderedNotes(notes), renderedNotes(saved)
+	for i := range want {
+		if got[i] != want[i] {
+			return fmt.Errorf("conversion check: a note reads back differently: %.60q", want[i])
+		}
+	}
+	return nil
+}
+
+// renderedNotes returns notes rendered, sorted.
+func renderedNotes(notes []*models.Note) []string {
+	rendered := make([]string, len(notes))
+	for i, note := range notes {
+		rendered[i] = note.Render()
+	}
+	sort.Strings(rendered)
+	return rendered
+}
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+)
+
+func TestStorage_ConvertBothWays(t *testing.T) {
+	dir := t.TempDir()
+	notes := "## 2026-05-12 09:00:00 - Plan\n\n- [ ] ship\n\n<!-- note -->\n## 2026-05-11 09:00:00\n\nolder\n"
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte(notes), 0644)
+
+	out := &bytes.Buffer{}
+	if err := RunStorage(dir, []string{"sqlite"}, nil, out); err != nil {
+		t.Fatalf("RunStorage sqlite: %v", err)
+	}
+	if !strings.Contains(out.String(), "converted 2 note(s) to sqlite") {
+		t.Errorf("output = %q", out.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.md")); !os.IsNotExist(err) {
+		t.Errorf("notes.md still there: %v", err)
+	}
+	if got := readFile(t, filepath.Join(dir, ".notes.md.bak")); got != notes {
+		t.Errorf(".notes.md.bak = %q", got)
+	}
+	cfg, _ := models.LoadFolderConfig(dir)
+	if cfg.NotesStorage() != models.NotesStorageSQLite {
+		t.Errorf("storage = %q", cfg.NotesStorage())
+	}
+
+	// The rest of NoteFlow now reads and writes notes.db.
+	manager, err := services.NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.AddNote("Added", "in sqlite"); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	if err := RunStorage(dir, nil, nil, out); err != nil || strings.TrimSpace(out.String()) != "sqlite: 3 note(s) in notes.db" {
+		t.Errorf("RunStorage: %v, output %q", err, out.String())
+	}
+
+	out.Reset()
+	if err := RunStorage(dir, []string{"markdown"}, nil, out); err != nil {
+		t.Fatalf("RunStorage markdown: %v", err)
+	}
+	got := readFile(t, filepath.Join(dir, "notes.md"))
+	if !strings.Contains(got, " - Added\n\nin sqlite\n") || !strings.HasSuffix(got, "older\n") {
+		t.Errorf("notes.md = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".notes.db.bak")); err != nil {
+		t.Errorf(".notes.db.bak: %v", err)
+	}
+	if cfg, _ := models.LoadFolderConfig(dir); cfg.Storage != "" {
+		t.Errorf("storage = %q after converting back", cfg.Storage)
+	}
+}
+
+func TestStorage_RefusesUnknownMode(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte("## 2026-05-12 09:00:00\n\nx\n"), 0644)
+	if err := RunStorage(dir, []string{"postgres"}, nil, &bytes.Buffer{}); err == nil {
+		t.Error("RunStorage postgres: no error")
+	}
+}
+
+func TestStorage_ConvertToFiles(t *testing.T) {
+	dir := t.TempDir()
+	notes := "## 2026-05-12 09:00:00 - Plan\n\n- [ ] ship\n\n<!-- note -->\n## 2026-05-11 09:00:00\n\nolder\n"
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte(notes), 0644)
+
+	out := &bytes.Buffer{}
+	if err := RunStorage(dir, []string{"files"}, nil, out); err != nil {
+		t.Fatalf("RunStorage files: %v", err)
+	}
+	if got := readFile(t, filepath.Join(dir, "notes", "2026-05-12 090000 Plan.md")); got != "## 2026-05-12 09:00:00 - Plan\n\n- [ ] ship\n" {
+		t.Errorf("note file = %q", got)
+	}
+
+	// A note dropped into the vault by another app joins the rest.
+	os.WriteFile(filepath.Join(dir, "notes", "Idea.md"), []byte("from obsidian\n"), 0644)
+	out.Reset()
+	if err := RunStorage(dir, []string{"markdown"}, nil, out); err != nil {
+		t.Fatalf("RunStorage markdown: %v", err)
+	}
+	got := readFile(t, filepath.Join(dir, "notes.md"))
+	if !strings.Contains(got, " - Idea\n\nfrom obsidian\n") || !strings.Contains(got, notes) {
+		t.Errorf("notes.md = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".notes.bak", "Idea.md")); err != nil {
+		t.Errorf(".notes.bak: %v", err)
+	}
+
+	// An unrelated notes/ directory is never overwritten.
+	os.Mkdir(filepath.Join(dir, "notes"), 0755)
+	if err := RunStorage(dir, []string{"files"}, nil, out); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("RunStorage files over notes/: err = %v", err)
+	}
+}
+
+func TestStorage_ConvertToEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	notes := "## 2026-05-12 09:00:00 - Plan\n\n- [ ] ship\n"
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte(notes), 0644)
+	parsed, _ := storage.NewFileStorage(dir).LoadNotes()
+	trashed := []models.TrashedNote{{Note: parsed[0], Deleted: parsed[0].Timestamp}}
+	if err := storage.NewFileStorage(dir).SaveTrash(trashed); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	if err := RunStorage(dir, []string{"encrypted"}, strings.NewReader("correct horse\ncorrect horse\n"), out); err != nil {
+		t.Fatalf("RunStorage encrypted: %v", err)
+	}
+	if !strings.Contains(out.String(), "encrypted 1 trash and history file(s)") {
+		t.Errorf("output = %q", out.String())
+	}
+	for _, name := range []string{"notes.md", ".notes.md.bak"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("plain %s left behind: %v", name, err)
+		}
+	}
+	for _, name := range []string{storage.EncryptedNotesFile, models.TrashFile} {
+		if got := readFile(t, filepath.Join(dir, name)); strings.Contains(got, "ship") {
+			t.Errorf("%s holds the notes in the clear", name)
+		}
+	}
+
+	out.Reset()
+	if err := RunStorage(dir, []string{"markdown"}, nil, out); err != nil {
+		t.Fatalf("RunStorage markdown: %v", err)
+	}
+	if got := readFile(t, filepath.Join(dir, "notes.md")); got != notes {
+		t.Errorf("notes.md = %q", got)
+	}
+	if got := readFile(t, filepath.Join(dir, models.TrashFile)); !strings.Contains(got, "ship") {
+		t.Errorf("trash.md still sealed: %q", got)
+	}
+}
+
+func TestStorage_EncryptedNeedsMatchingPassphrases(t *testing.T) {
+	dir := t.TempDir()
+	notes := "## 2026-05-12 09:00:00 - Plan\n\n- [ ] ship\n"
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte(notes), 0644)
+
+	err := RunStorage(dir, []string{"encrypted"}, strings.NewReader("correct horse\ncorect horse\n"), &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "don't match") {
+		t.Fatalf("RunStorage = %v, want a mismatch", err)
+	}
+	if got := readFile(t, filepath.Join(dir, "notes.md")); got != notes {
+		t.Errorf("notes.md = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, storage.EncryptedNotesFile)); !os.IsNotExist(err) {
+		t.Errorf("%s written: %v", storage.EncryptedNotesFile, err)
+	}
+
+	t.Setenv(storage.PassphraseEnv, "correct horse")
+	if err := RunStorage(dir, []string{"encrypted"}, strings.NewReader("wrong\n"), &bytes.Buffer{}); err == nil {
+		t.Fatal("RunStorage accepted an unconfirmed NOTEFLOW_PASSPHRASE")
+	}
+	if err := RunStorage(dir, []string{"encrypted"}, strings.NewReader("correct horse\n"), &bytes.Buffer{}); err != nil {
+		t.Fatalf("RunStorage with a confirmed NOTEFLOW_PASSPHRASE: %v", err)
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,7 +22,7 @@ import (
 const tasksHelp = `USAGE:
     noteflow-go tasks [FLAGS]                List tasks, filtered as requested
     noteflow-go tasks --status [--project P] One-line summary (for status bars)
-    noteflow-go tasks --toggle HASH          Mark a task done/undone
+    noteflow-go tasks --toggle ID|HASH       Mark a task done/undone
     noteflow-go tasks --save-view NAME …     Save current filters as a view
     noteflow-go tasks --view NAME            Apply a saved view's filters
     noteflow-go tasks --list-views           List all saved view names
@@ -40,22 +42,28 @@ You filter on those tokens via the flags below; no separate setup needed.
 
 FILTERING (combine freely):
     --done             Include completed tasks (default: open only)
-    --due VALUE        today | week | overdue | YYYY-MM-DD
+    --due VALUE        today | week | overdue | none | YYYY-MM-DD
     --priority N       1..3 — match tasks tagged !p1..!p3 in markdown
-    --tag NAME         Match tasks tagged #NAME (no leading #)
+    --tag NAME         Match tasks tagged #NAME or #NAME/... (no leading #)
     --project SUBSTR   Match folders whose path contains SUBSTR
                        (case-insensitive)
+    --folder ID|PATH   Only the registered folder with this ID (see
+                       /api/global-folders) or path ('.' for this one)
 
 OUTPUT:
     --json             Emit JSON instead of the human-readable table
+    --csv, --tsv       Emit CSV or TSV for spreadsheets: folder, note,
+                       text, state, priority, due, tags and when each
+                       task was created, updated and completed
     --status           Print "today=N overdue=N open=N" and exit
                        (combines with --project and --json)
 
 ACTIONS:
-    --toggle HASH      Flip completion state of the task with stable hash
-                       HASH. Updates both notes.md and the task DB.
-                       Get hashes via --json (each task has an .id and the
-                       hash is derivable from .content).
+    --toggle ID|HASH   Flip completion state of the task with global ID
+                       ID (the #N in the listing) or stable hash HASH
+                       (.hash in --json, which survives re-syncs).
+                       Updates both notes.md and the task DB, the same
+                       way the global tasks page does.
 
 SAVED VIEWS:
     --save-view NAME   Persist the current filter set as NAME. Composes
@@ -84,13 +92,21 @@ EXAMPLES:
     noteflow-go tasks --save-view blockers --due overdue --priority 1
     noteflow-go tasks --view blockers
 
+    # Open tasks of the folder you're in
+    noteflow-go tasks --folder .
+
+    # Every task, finished ones included, for a spreadsheet
+    noteflow-go tasks --done --csv > tasks.csv
+
     # Mark a task done from the terminal (the file gets updated too)
+    noteflow-go tasks --toggle 42
     noteflow-go tasks --toggle a3eb73cb5f2e
 `
 
 
 // RunTasks lists tasks from the cross-project task DB, applying optional
-// filters. Read-only: never writes to the DB. Filtering on priority/due/tag
+// filters. Listing reads the DB through DatabaseService.QueryTasks, as
+// GET /api/global-tasks does, and never writes to it. Filtering on priority/due/tag
 // is done in-process against the parsed metadata tokens in each task's text
 // (see models.ParseTaskMetadata) because the DB does not yet have dedicated
 // columns for these — see docs/20260512_task_db_schema.md §7.
@@ -98,15 +114,17 @@ EXAMPLES:
 // Usage:
 //
 //	noteflow tasks [--done] [--due today|week|overdue|YYYY-MM-DD]
-//	              [--priority N] [--tag T] [--project SUBSTR] [--json]
+//	              [--priority N] [--tag T] [--project SUBSTR]
+//	              [--folder ID|PATH] [--json|--csv|--tsv]
 //
 // Output is one line per task in the form:
 //
-//	[STATE] PRIORITY DUE  TEXT                  (PROJECT)
+//	#ID [STATE] PRIORITY DUE  TEXT                  (PROJECT)
 //
-// where STATE is "[ ]" or "[x]", PRIORITY is "p1"/"p2"/"p3"/"-",
+// where ID is the task's global ID (for --toggle), STATE is "[ ]" or "[x]", PRIORITY is "p1"/"p2"/"p3"/"-",
 // DUE is YYYY-MM-DD or "-", TEXT is the task text with metadata stripped,
-// and PROJECT is the basename of the project folder.
+// and PROJECT is the basename of the project folder. --csv and --tsv write
+// the tasks in the columns of GET /api/global-tasks/export.csv instead.
 func RunTasks(dbPath string, args []string, stdout, stderr io.Writer) error {
 	for _, a := range args {
 		if a == "--help" || a == "-h" {
@@ -119,12 +137,15 @@ func RunTasks(dbPath string, args []string, stdout, stderr io.Writer) error {
 	fs.SetOutput(io.Discard)
 
 	includeDone := fs.Bool("done", false, "include completed tasks (default: open only)")
-	dueFilter := fs.String("due", "", "filter by due date: today, week, overdue, or YYYY-MM-DD")
+	dueFilter := fs.String("due", "", "filter by due date: today, week, overdue, none, or YYYY-MM-DD")
 	priorityFilter := fs.Int("priority", 0, "filter by priority 1..3 (0 = no filter)")
 	tagFilter := fs.String("tag", "", "filter by tag (without leading #)")
 	projectFilter := fs.String("project", "", "filter by project path substring (case-insensitive)")
+	folderFilter := fs.String("folder", "", "only the folder with this ID or path")
 	jsonOut := fs.Bool("json", false, "emit JSON instead of human format")
-	toggleHash := fs.String("toggle", "", "toggle the completion state of the task with the given hash; updates both notes.md and the DB")
+	csvOut := fs.Bool("csv", false, "emit CSV for spreadsheets instead of human format")
+	tsvOut := fs.Bool("tsv", false, "emit TSV for spreadsheets instead of human format")
+	toggle := fs.String("toggle", "", "toggle the completion state of the task with the given ID or hash; updates both notes.md and the DB")
 	statusLine := fs.Bool("status", false, "print a single-line summary suitable for shell prompts / status bars and exit")
 	viewName := fs.String("view", "", "apply a saved view's filters (command-line flags override the view's stored values)")
 	saveView := fs.String("save-view", "", "save the current filter combination under NAME and exit")
@@ -158,52 +179,55 @@ func RunTasks(dbPath string, args []string, stdout, stderr io.Writer) error {
 		return runSaveView(dbPath, *saveView, *includeDone, *dueFilter, *priorityFilter, *tagFilter, *projectFilter, stdout)
 	}
 
-	if *toggleHash != "" {
-		return toggleTask(dbPath, *toggleHash, stdout)
+	if *toggle != "" {
+		return toggleTask(dbPath, *toggle, stdout)
 	}
 
 	if *statusLine {
 		return printStatusLine(dbPath, *jsonOut, *projectFilter, stdout)
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
-	if err != nil {
-		return fmt.Errorf("open task db: %w", err)
+	ds, err := openTaskDB(dbPath)
+	if err != nil || ds == nil {
+		return err // no DB yet is "no tasks", not an error
 	}
-	defer db.Close()
+	defer ds.Close()
 
-	rows, err := db.Query(`
-		SELECT t.id, t.content, t.completed, f.path
-		FROM tasks t
-		JOIN folders f ON t.folder_id = f.id
-		WHERE f.active = 1
-		ORDER BY f.path, t.completed, t.id`)
-	if err != nil {
-		// Likely cause: DB doesn't exist yet because NoteFlow hasn't been run.
-		// That's "no tasks" rather than an error from the user's perspective.
-		if strings.Contains(err.Error(), "no such table") {
-			return nil
+	var filter services.TaskFilter
+	if *folderFilter != "" {
+		if id, err := strconv.Atoi(*folderFilter); err == nil {
+			filter.FolderID = id
+		} else if filter.FolderPath, err = filepath.Abs(*folderFilter); err != nil {
+			return fmt.Errorf("resolve --folder: %w", err)
 		}
+	}
+	res, err := ds.QueryTasks(filter)
+	if err != nil {
 		return fmt.Errorf("query tasks: %w", err)
 	}
-	defer rows.Close()
 
 	type viewTask struct {
-		ID       int        `json:"id"`
+		ID       int        `json:"id"`                 // for --toggle ID
+		Hash     string     `json:"hash"`               // for --toggle HASH
 		Content  string     `json:"content"`            // raw, includes tokens
 		Clean    string     `json:"clean"`              // tokens stripped, for display
 		Done     bool       `json:"done"`
 		Priority int        `json:"priority,omitempty"`
 		Due      *time.Time `json:"due,omitempty"`
 		Tags     []string   `json:"tags,omitempty"`
+		FolderID int        `json:"folder_id"`
 		Project  string     `json:"project"`            // folder path
 	}
 
-	var out []viewTask
-	for rows.Next() {
-		var t viewTask
-		if err := rows.Scan(&t.ID, &t.Content, &t.Done, &t.Project); err != nil {
-			return fmt.Errorf("scan: %w", err)
+	out := make([]viewTask, 0, len(res.Tasks))
+	for _, gt := range res.Tasks {
+		t := viewTask{
+			ID:       gt.ID,
+			Hash:     gt.Hash,
+			Content:  gt.Content,
+			Done:     gt.Completed,
+			FolderID: gt.FolderID,
+			Project:  gt.FolderPath,
 		}
 		prio, due, tags := models.ParseTaskMetadata(t.Content)
 		t.Priority = prio
@@ -215,9 +239,6 @@ func RunTasks(dbPath string, args []string, stdout, stderr io.Writer) error {
 		t.Clean = models.CleanTaskText(t.Content)
 		out = append(out, t)
 	}
-	if err := rows.Err(); err != nil {
-		return fmt.Errorf("iterate rows: %w", err)
-	}
 
 	now := time.Now()
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
@@ -269,6 +290,26 @@ func RunTasks(dbPath string, args []string, stdout, stderr io.Writer) error {
 		return a.Content < b.Content
 	})
 
+	if *csvOut || *tsvOut {
+		comma := ','
+		if *tsvOut {
+			comma = '\t'
+		}
+		byID := make(map[int]models.GlobalTask, len(res.Tasks))
+		for _, gt := range res.Tasks {
+			byID[gt.ID] = gt
+		}
+		tasks := make([]models.GlobalTask, 0, len(filtered))
+		for _, t := range filtered {
+			tasks = append(tasks, byID[t.ID])
+		}
+		rows, err := ds.TaskExportRows(tasks, noteTitles)
+		if err != nil {
+			return fmt.Errorf("export tasks: %w", err)
+		}
+		return services.WriteTaskRows(stdout, rows, comma)
+	}
+
 	if *jsonOut {
 		enc := json.NewEncoder(stdout)
 		enc.SetIndent("", "  ")
@@ -288,11 +329,24 @@ func RunTasks(dbPath string, args []string, stdout, stderr io.Writer) error {
 		if t.Due != nil {
 			due = t.Due.Format("2006-01-02")
 		}
-		fmt.Fprintf(stdout, "%s %s %s  %s  (%s)\n", state, prio, due, stripCheckbox(t.Clean), filepath.Base(t.Project))
+		fmt.Fprintf(stdout, "#%d %s %s %s  %s  (%s)\n", t.ID, state, prio, due, stripCheckbox(t.Clean), filepath.Base(t.Project))
 	}
 	return nil
 }
 
+// noteTitles returns the note titles of the folder at path for the task
+// export, or nil when it has no notes.md to read them from.
+func noteTitles(_ int, path string) map[string]string {
+	if _, err := os.Stat(filepath.Join(path, "notes.md")); err != nil {
+		return nil
+	}
+	nm, err := services.NewNoteManager(path)
+	if err != nil {
+		return nil
+	}
+	return nm.NoteTitles()
+}
+
 // savedViewFilters is the on-disk shape of a saved view: a JSON blob of the
 // filter values. New fields can be added safely — JSON unmarshal ignores
 // keys it doesn't recognize, and zero values are treated as "unset" by the
@@ -407,7 +461,7 @@ func runDeleteView(dbPath, name string, stdout io.Writer) error {
 //
 // Designed to be cheap — runs a single query and counts in-process.
 func printStatusLine(dbPath string, asJSON bool, projectSubstr string, stdout io.Writer) error {
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := services.OpenTaskDB(dbPath)
 	if err != nil {
 		return fmt.Errorf("open task db: %w", err)
 	}
@@ -470,31 +524,35 @@ func writeStatusCounts(w io.Writer, asJSON bool, today, overdue, open int) error
 	return err
 }
 
-// toggleTask flips the completion state of a single task identified by its
-// stable content hash. It is the bidirectional half of Goal 2: the source
-// notes.md file and the task DB row are both updated, in that order, so that
-// the file remains the source of truth even if the DB write fails.
+// toggleTask flips the completion state of a single task, identified by
+// its global ID or its stable content hash. It is the bidirectional half of
+// Goal 2, and takes the same path as the global tasks page
+// (DatabaseService.SetTaskCompletion): the source notes.md file and the
+// task DB row are both updated, in that order, so that the file remains the
+// source of truth even if the DB write fails.
 //
 // Concurrency caveat: if the NoteFlow web server is running against the same
 // folder, it holds an in-memory NoteManager that won't see the file change
-// until its next sync. The next sync reconciles correctly (file wins), but
-// there is a brief window where the server's view is stale. For now this is
-// documented; a file lock would tighten it — see TODO.md technical debt.
-func toggleTask(dbPath, hash string, stdout io.Writer) error {
-	db, err := sql.Open("sqlite", dbPath)
+// until its watcher reloads it. The next sync reconciles correctly (file
+// wins), but there is a brief window where the server's view is stale.
+func toggleTask(dbPath, ref string, stdout io.Writer) error {
+	ds, err := openTaskDB(dbPath)
 	if err != nil {
-		return fmt.Errorf("open task db: %w", err)
+		return err
 	}
-	defer db.Close()
+	if ds == nil {
+		return fmt.Errorf("no task found with ID or hash %q (no task DB yet)", ref)
+	}
+	defer ds.Close()
 
-	var content, folderPath string
-	var currentlyDone bool
-	err = db.QueryRow(`
-		SELECT t.content, t.completed, f.path
-		FROM tasks t JOIN folders f ON t.folder_id = f.id
-		WHERE t.task_hash = ?`, hash).Scan(&content, &currentlyDone, &folderPath)
-	if errors.Is(err, sql.ErrNoRows) {
-		return fmt.Errorf("no task found with hash %q (run `noteflow tasks` to see available hashes)", hash)
+	var task *models.GlobalTask
+	if id, convErr := strconv.Atoi(ref); convErr == nil {
+		task, err = ds.GetTask(id)
+	} else {
+		task, err = ds.GetTaskByHash(ref)
+	}
+	if errors.Is(err, services.ErrGlobalTaskNotFound) {
+		return fmt.Errorf("no task found with ID or hash %q (run `noteflow tasks` to see IDs)", ref)
 	}
 	if err != nil {
 		return fmt.Errorf("look up task: %w", err)
@@ -502,64 +560,46 @@ func toggleTask(dbPath, hash string, stdout io.Writer) error {
 
 	// Open the folder's NoteManager — this is the only authoritative way to
 	// edit notes.md, because it knows the parsing rules.
-	mgr, err := services.NewNoteManager(folderPath)
+	mgr, err := services.NewNoteManager(task.FolderPath)
 	if err != nil {
-		return fmt.Errorf("open notes.md at %s: %w", folderPath, err)
-	}
-
-	// Find the task whose hash matches. We walk every task in the file and
-	// compute hashes the same way SyncFolderTasks does, then match.
-	allTasks := mgr.GetAllTasks()
-	hashes := services.ComputeTaskHashes(allTasks)
-	matchIdx := -1
-	for i, h := range hashes {
-		if h == hash {
-			matchIdx = i
-			break
-		}
-	}
-	if matchIdx == -1 {
-		return fmt.Errorf("task hash %q is in the DB but no longer in %s — the file has changed since the last sync. Open the project in NoteFlow to re-sync, then try again",
-			hash, filepath.Join(folderPath, "notes.md"))
+		return fmt.Errorf("open notes.md at %s: %w", task.FolderPath, err)
 	}
-
-	newDone := !currentlyDone
-	if err := mgr.UpdateTask(allTasks[matchIdx].Index, newDone); err != nil {
-		return fmt.Errorf("update notes.md: %w", err)
-	}
-
-	// File is updated. Now reflect the change in the DB so subsequent queries
-	// (and the next sync) see consistent state without waiting. We also update
-	// the `content` column so the DB's view of the row's checkbox marker
-	// matches the file — otherwise the next full sync would see a content
-	// "change" and pointlessly bump last_updated.
-	newContent := content
-	if newDone {
-		newContent = strings.Replace(content, "[ ]", "[x]", 1)
-	} else {
-		newContent = strings.Replace(content, "[x]", "[ ]", 1)
-		newContent = strings.Replace(newContent, "[X]", "[ ]", 1)
-	}
-	if _, err := db.Exec(`UPDATE tasks SET content = ?, completed = ?, last_updated = ? WHERE task_hash = ?`,
-		newContent, newDone, time.Now(), hash); err != nil {
-		return fmt.Errorf("update task db: %w", err)
+	if err := ds.SetTaskCompletion(task, !task.Completed, mgr); err != nil {
+		if errors.Is(err, services.ErrTaskNotInNotes) {
+			return fmt.Errorf("%w. Open the project in NoteFlow to re-sync, then try again", err)
+		}
+		return err
 	}
 
 	state := "[ ]"
-	if newDone {
+	if task.Completed {
 		state = "[x]"
 	}
-	fmt.Fprintf(stdout, "toggled: %s %s  (%s)\n", state, stripCheckbox(models.CleanTaskText(content)), filepath.Base(folderPath))
+	fmt.Fprintf(stdout, "toggled: %s %s  (%s)\n", state, stripCheckbox(models.CleanTaskText(task.Content)), filepath.Base(task.FolderPath))
 	return nil
 }
 
+// openTaskDB opens the task DB at dbPath through the same DatabaseService
+// the server uses. A missing DB file is (nil, nil): NoteFlow hasn't run
+// yet, so there are no tasks.
+func openTaskDB(dbPath string) (*services.DatabaseService, error) {
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) && !services.RemoteTaskDB(dbPath) {
+		return nil, nil
+	}
+	ds, err := services.NewDatabaseServiceAt(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open task db: %w", err)
+	}
+	return ds, nil
+}
+
 // stripCheckbox removes a leading "- [ ] " / "- [x] " prefix from a cleaned
 // task line, since the display already shows the checked state in its own
 // column. Returns the input unchanged when no checkbox prefix is found.
 func stripCheckbox(line string) string {
 	t := strings.TrimLeft(line, " ")
 	t = strings.TrimPrefix(t, "- ")
-	if len(t) >= 3 && t[0] == '[' && (t[1] == ' ' || t[1] == 'x' || t[1] == 'X') && t[2] == ']' {
+	if len(t) >= 3 && t[0] == '[' && (t[1] == ' ' || t[1] == '/' || t[1] == 'x' || t[1] == 'X') && t[2] == ']' {
 		t = strings.TrimLeft(t[3:], " ")
 	}
 	return t
@@ -568,41 +608,19 @@ func stripCheckbox(line string) string {
 func containsTag(have []string, want string) bool {
 	want = strings.TrimPrefix(want, "#")
 	for _, t := range have {
-		if t == want {
+		if models.TagMatches(t, want) {
 			return true
 		}
 	}
 	return false
 }
 
-// dueMatches returns whether a task should be included given its due date,
-// completion state, and the --due filter token. Filter tokens:
-//
-//	today     — due is today
-//	week      — due within the next 7 days (today inclusive)
-//	overdue   — due strictly before today AND not done
-//	YYYY-MM-DD — due is exactly that date
-//
-// Tasks with no due date are excluded by any --due filter.
+// dueMatches applies a --due filter token; see models.MatchesDueFilter
+// for the tokens (today, week, overdue, none, YYYY-MM-DD).
 func dueMatches(filter string, due *time.Time, done bool, today time.Time) (bool, error) {
-	if due == nil {
-		return false, nil
-	}
-	d := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, today.Location())
-	switch filter {
-	case "today":
-		return d.Equal(today), nil
-	case "week":
-		end := today.AddDate(0, 0, 7)
-		return !d.Before(today) && d.Before(end), nil
-	case "overdue":
-		return d.Before(today) && !done, nil
-	default:
-		exact, err := time.Parse("2006-01-02", filter)
-		if err != nil {
-			return false, fmt.Errorf("invalid --due value %q (want today|week|overdue|YYYY-MM-DD)", filter)
-		}
-		exactDay := time.Date(exact.Year(), exact.Month(), exact.Day(), 0, 0, 0, 0, today.Location())
-		return d.Equal(exactDay), nil
+	keep, err := models.MatchesDueFilter(filter, due, done, today)
+	if err != nil {
+		return false, fmt.Errorf("invalid --due value %q (want today|week|overdue|none|YYYY-MM-DD)", filter)
 	}
+	return keep, nil
 }
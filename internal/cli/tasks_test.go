@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -267,6 +269,56 @@ func TestRunTasks_ToggleFlipsBothFileAndDB(t *testing.T) {
 	}
 }
 
+func TestRunTasks_ToggleByID(t *testing.T) {
+	dbPath, folderPath, _ := setupToggleWorld(t)
+
+	var listed []struct {
+		ID      int    `json:"id"`
+		Hash    string `json:"hash"`
+		Content string `json:"content"`
+	}
+	out := &bytes.Buffer{}
+	if err := RunTasks(dbPath, []string{"--json"}, out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("RunTasks --json: %v", err)
+	}
+	json.Unmarshal(out.Bytes(), &listed)
+	id := 0
+	for _, task := range listed {
+		if strings.Contains(task.Content, "task beta") {
+			id = task.ID
+		}
+	}
+	if id == 0 {
+		t.Fatalf("task beta not listed: %s", out.String())
+	}
+
+	if err := RunTasks(dbPath, []string{"--toggle", strconv.Itoa(id)}, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("toggle by ID: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(folderPath, "notes.md"))
+	if !strings.Contains(string(data), "- [x] task beta") || !strings.Contains(string(data), "- [ ] task alpha") {
+		t.Errorf("notes.md after toggle:\n%s", data)
+	}
+
+	// The DB row follows the file, so the listing shows it done.
+	out.Reset()
+	RunTasks(dbPath, []string{"--done"}, out, &bytes.Buffer{})
+	if !strings.Contains(out.String(), fmt.Sprintf("#%d [x]", id)) {
+		t.Errorf("listing after toggle:\n%s", out.String())
+	}
+}
+
+func TestRunTasks_FilterFolder(t *testing.T) {
+	dbPath := setupTaskDB(t)
+	out := &bytes.Buffer{}
+	if err := RunTasks(dbPath, []string{"--folder", "/tmp/project-b"}, out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("RunTasks: %v", err)
+	}
+	if !strings.Contains(out.String(), "demo prep") || strings.Contains(out.String(), "ship the changelog") {
+		t.Errorf("--folder by path:\n%s", out.String())
+	}
+}
+
 func TestRunTasks_ToggleUnknownHash(t *testing.T) {
 	dbPath, _, _ := setupToggleWorld(t)
 	err := RunTasks(dbPath, []string{"--toggle", "deadbeef0000"}, &bytes.Buffer{}, &bytes.Buffer{})
@@ -454,3 +506,26 @@ func TestRunTasks_ToggleStaleFile(t *testing.T) {
 		t.Errorf("error message should mention stale file; got: %v", err)
 	}
 }
+
+func TestRunTasks_CSV(t *testing.T) {
+	dbPath, _, _ := setupToggleWorld(t)
+	out := &bytes.Buffer{}
+	if err := RunTasks(dbPath, []string{"--done", "--csv"}, out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("RunTasks --csv: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "folder,folder_path,note,text,state,") {
+		t.Fatalf("CSV:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), ",sprint,task alpha,todo,") || !strings.Contains(out.String(), ",sprint,task gamma already done,done,") {
+		t.Errorf("CSV lacks the note titles or states:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := RunTasks(dbPath, []string{"--tsv"}, out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("RunTasks --tsv: %v", err)
+	}
+	if got := strings.Count(out.String(), "\n"); got != 3 || !strings.Contains(out.String(), "\ttask beta\ttodo\t") {
+		t.Errorf("TSV of the open tasks:\n%s", out.String())
+	}
+}
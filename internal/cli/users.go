@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/auth"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+)
+
+const usersHelp = `USAGE:
+    noteflow-go users add NAME --root DIR   Create an account (password on stdin)
+    noteflow-go users passwd NAME           Change a password (password on stdin)
+    noteflow-go users remove NAME           Delete an account
+    noteflow-go users list                  List accounts and their notes folders
+
+Manages the accounts of multi-user mode. Once at least one account exists,
+'noteflow-go' asks for a user name and password at the login page and
+each user gets their own notes folder (DIR) and their own global task
+list. Registering folders is limited to DIR and the folders below it.
+The user who started the server keeps signing in with the --password /
+NOTEFLOW_PASSWORD login, if one is set.
+
+Accounts live in ~/.config/noteflow/tasks.db. Restart a running server
+after adding the first account. Removing an account forgets its folders
+and tasks; the notes on disk are left alone.
+
+The password is read from the first line of standard input:
+    echo "$PASS" | noteflow-go users add alice --root ~/alice-notes
+
+FLAGS:
+    --root DIR         Notes folder for 'add' (created if missing)
+    --help, -h         Show this help and exit
+`
+
+// RunUsers runs the users subcommand against the task DB at dbPath.
+func RunUsers(dbPath string, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stdout, usersHelp)
+		return nil
+	}
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			fmt.Fprint(stdout, usersHelp)
+			return nil
+		}
+	}
+
+	fs := flag.NewFlagSet("users", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	root := fs.String("root", "", "notes folder for add")
+	if err := fs.Parse(args[1:]); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+	// Allow "add NAME --root DIR" as well as "add --root DIR NAME".
+	rest := fs.Args()
+	if len(rest) > 1 {
+		if err := fs.Parse(rest[1:]); err != nil {
+			return fmt.Errorf("parse flags: %w", err)
+		}
+		rest = append(rest[:1], fs.Args()...)
+	}
+
+	db, err := services.NewDatabaseServiceAt(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	cmd := args[0]
+	if cmd == "list" {
+		users, err := db.ListUsers()
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			fmt.Fprintln(stdout, "No users.")
+			return nil
+		}
+		for _, u := range users {
+			fmt.Fprintf(stdout, "%-16s %s\n", u.Name, u.NotesRoot)
+		}
+		return nil
+	}
+
+	if len(rest) != 1 {
+		return fmt.Errorf("%s needs exactly one user name", cmd)
+	}
+	name := rest[0]
+	switch cmd {
+	case "add":
+		if *root == "" {
+			return errors.New("add needs --root DIR")
+		}
+		dir, err := filepath.Abs(*root)
+		if err != nil {
+			return err
+		}
+		hash, err := readPasswordHash(stdin)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("create notes folder: %w", err)
+		}
+		u, err := db.CreateUser(name, hash, dir)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Added %s with notes in %s\n", u.Name, u.NotesRoot)
+	case "passwd":
+		hash, err := readPasswordHash(stdin)
+		if err != nil {
+			return err
+		}
+		if err := db.SetUserPassword(name, hash); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		fmt.Fprintf(stdout, "Changed the password of %s\n", name)
+	case "remove":
+		if err := db.DeleteUser(name); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		fmt.Fprintf(stdout, "Removed %s\n", name)
+	default:
+		return fmt.Errorf("unknown command %q (want add, passwd, remove or list)", cmd)
+	}
+	return nil
+}
+
+// readPasswordHash reads a password from the first line of r and hashes
+// it.
+func readPasswordHash(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read password: %w", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("no password on standard input")
+	}
+	return auth.HashPassword(password)
+}
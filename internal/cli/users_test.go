@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/auth"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+)
+
+func TestRunUsers_AddPasswdRemove(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tasks.db")
+	root := filepath.Join(t.TempDir(), "alice")
+	run := func(stdin string, args ...string) (string, error) {
+		var out bytes.Buffer
+		err := RunUsers(dbPath, args, strings.NewReader(stdin), &out)
+		return out.String(), err
+	}
+
+	if _, err := run("", "add", "alice", "--root", root); err == nil {
+		t.Error("add without a password succeeded")
+	}
+	if _, err := run("s3cret\n", "add", "alice"); err == nil {
+		t.Error("add without --root succeeded")
+	}
+	if _, err := run("s3cret\n", "add", "alice", "--root", root); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	out, err := run("", "list")
+	if err != nil || !strings.Contains(out, "alice") || !strings.Contains(out, root) {
+		t.Errorf("list = %q, %v", out, err)
+	}
+	checkPassword(t, dbPath, "alice", "s3cret")
+
+	if _, err := run("n3w\n", "passwd", "alice"); err != nil {
+		t.Fatalf("passwd: %v", err)
+	}
+	checkPassword(t, dbPath, "alice", "n3w")
+	if _, err := run("x\n", "passwd", "bob"); err == nil {
+		t.Error("passwd for a missing user succeeded")
+	}
+
+	if _, err := run("", "remove", "alice"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if out, _ := run("", "list"); !strings.Contains(out, "No users") {
+		t.Errorf("list after remove = %q", out)
+	}
+}
+
+func checkPassword(t *testing.T, dbPath, name, password string) {
+	t.Helper()
+	db, err := services.NewDatabaseServiceAt(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	_, hash, err := db.UserPasswordHash(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := auth.VerifyPassword(hash, password); !ok || err != nil {
+		t.Errorf("password of %s is not %q (%v)", name, password, err)
+	}
+}
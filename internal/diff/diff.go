@@ -0,0 +1,333 @@
+// Package diff computes differences between two versions of a note: word
+// level for the side-by-side view in the browser, line level for unified
+// diffs. Both are a plain Myers diff over tokens (words, whitespace and
+// punctuation, or whole lines), so moved paragraphs show up as a delete
+// plus an insert.
+package diff
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kind says what an Op does to the old text.
+type Kind string
+
+const (
+	Equal  Kind = "equal"
+	Insert Kind = "insert"
+	Delete Kind = "delete"
+)
+
+// Op is a run of text that is unchanged, added or removed.
+type Op struct {
+	Kind Kind   `json:"op"`
+	Text string `json:"text"`
+}
+
+// maxEdits bounds the Myers search. Beyond it the two texts are treated as
+// unrelated (delete everything, insert everything) rather than spending
+// quadratic memory on a diff nobody can read anyway.
+const maxEdits = 2000
+
+// tokenRE splits text into words, whitespace runs and single symbols, so a
+// changed comma doesn't mark the whole word next to it as changed.
+var tokenRE = regexp.MustCompile(`[\p{L}\p{N}_]+|\s+|[^\p{L}\p{N}_\s]`)
+
+// Words returns the word-level diff turning a into b. Adjacent ops of the
+// same kind are merged, so the result alternates between kinds.
+func Words(a, b string) []Op {
+	return compact(tokens(tokenRE.FindAllString(a, -1), tokenRE.FindAllString(b, -1)))
+}
+
+func tokens(a, b []string) []Op {
+	// Common prefix and suffix are cheap to peel off and usually cover
+	// almost all of a note that has had a small edit.
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	var ops []Op
+	for _, t := range a[:pre] {
+		ops = append(ops, Op{Equal, t})
+	}
+	ops = append(ops, myers(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, t := range a[len(a)-suf:] {
+		ops = append(ops, Op{Equal, t})
+	}
+	return ops
+}
+
+// myers is the O(ND) greedy diff from Myers (1986). v[k+off] holds the
+// furthest-reaching x on diagonal k; trace[d] is a copy of v's diagonals
+// -d-1..d+1 before round d, which is all the backtrack needs.
+func myers(a, b []string) []Op {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return replaceAll(a, b)
+	}
+
+	off := maxEdits + 1
+	v := make([]int, 2*off+1)
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		if d > maxEdits {
+			return replaceAll(a, b)
+		}
+		trace = append(trace, append([]int(nil), v[off-d-1:off+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return replaceAll(a, b) // unreachable: round n+m always reaches the end
+}
+
+func backtrack(a, b []string, trace [][]int) []Op {
+	var rev []Op
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d] // v[k+d+1] is diagonal k
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[k-1+d+1] < v[k+1+d+1]) {
+			prevK = k + 1
+		}
+		prevX := v[prevK+d+1]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			rev = append(rev, Op{Equal, a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			rev = append(rev, Op{Insert, b[y-1]})
+			y--
+		} else {
+			rev = append(rev, Op{Delete, a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		rev = append(rev, Op{Equal, a[x-1]})
+		x--
+		y--
+	}
+
+	ops := make([]Op, len(rev))
+	for i, op := range rev {
+		ops[len(rev)-1-i] = op
+	}
+	return ops
+}
+
+func replaceAll(a, b []string) []Op {
+	ops := make([]Op, 0, 2)
+	if len(a) > 0 {
+		ops = append(ops, Op{Delete, strings.Join(a, "")})
+	}
+	if len(b) > 0 {
+		ops = append(ops, Op{Insert, strings.Join(b, "")})
+	}
+	return ops
+}
+
+// compact merges neighbouring ops of the same kind. A whitespace-only
+// equal run sandwiched between changes is folded into them, which reads
+// far better ("-old words+ +new words+" instead of word-by-word flicker).
+func compact(ops []Op) []Op {
+	var out []Op
+	for i := 0; i < len(ops); i++ {
+		op := ops[i]
+		if op.Kind == Equal && strings.TrimSpace(op.Text) == "" && !strings.Contains(op.Text, "\n") &&
+			len(out) > 0 && out[len(out)-1].Kind != Equal && i+1 < len(ops) && ops[i+1].Kind != Equal {
+			out = append(out, Op{Delete, op.Text}, Op{Insert, op.Text})
+			continue
+		}
+		out = append(out, op)
+	}
+
+	// Group each run of changes as all deletes then all inserts, then join.
+	var merged []Op
+	for i := 0; i < len(out); {
+		if out[i].Kind == Equal {
+			merged = appendOp(merged, out[i])
+			i++
+			continue
+		}
+		var del, ins strings.Builder
+		for ; i < len(out) && out[i].Kind != Equal; i++ {
+			if out[i].Kind == Delete {
+				del.WriteString(out[i].Text)
+			} else {
+				ins.WriteString(out[i].Text)
+			}
+		}
+		if del.Len() > 0 {
+			merged = appendOp(merged, Op{Delete, del.String()})
+		}
+		if ins.Len() > 0 {
+			merged = appendOp(merged, Op{Insert, ins.String()})
+		}
+	}
+	return merged
+}
+
+func appendOp(ops []Op, op Op) []Op {
+	if n := len(ops); n > 0 && ops[n-1].Kind == op.Kind {
+		ops[n-1].Text += op.Text
+		return ops
+	}
+	return append(ops, op)
+}
+
+// SideBySideHTML renders ops as a two-column table: the old text with
+// removals in <del> on the left, the new text with additions in <ins> on
+// the right. Text is escaped; line breaks are kept by the diff-side-by-side
+// CSS (white-space: pre-wrap).
+func SideBySideHTML(ops []Op) string {
+	var left, right strings.Builder
+	for _, op := range ops {
+		text := html.EscapeString(op.Text)
+		switch op.Kind {
+		case Equal:
+			left.WriteString(text)
+			right.WriteString(text)
+		case Delete:
+			left.WriteString("<del>" + text + "</del>")
+		case Insert:
+			right.WriteString("<ins>" + text + "</ins>")
+		}
+	}
+	return `<table class="diff-side-by-side"><tr><td class="diff-old">` + left.String() +
+		`</td><td class="diff-new">` + right.String() + `</td></tr></table>`
+}
+
+// splitLines splits text into lines that keep their "\n", so joining the
+// pieces gives back text exactly.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Lines returns the line-level diff turning a into b. Each op's Text is one
+// or more whole lines, newlines included; adjacent ops of the same kind are
+// merged.
+func Lines(a, b string) []Op {
+	var ops []Op
+	for _, op := range tokens(splitLines(a), splitLines(b)) {
+		ops = appendOp(ops, op)
+	}
+	return ops
+}
+
+// unifiedContext is the number of unchanged lines shown around each change,
+// the same default as diff -u and git diff.
+const unifiedContext = 3
+
+// Unified renders the line-level diff turning a into b in unified diff
+// format, labelled with fromName and toName. It returns "" when the texts
+// are equal.
+func Unified(a, b, fromName, toName string) string {
+	ops := tokens(splitLines(a), splitLines(b)) // one line per op
+
+	// Pick the ops each hunk covers: every change plus up to
+	// unifiedContext equal lines either side, merging hunks whose context
+	// would touch.
+	type span struct{ start, end int }
+	var hunks []span
+	for i, op := range ops {
+		if op.Kind == Equal {
+			continue
+		}
+		start, end := max(i-unifiedContext, 0), min(i+unifiedContext+1, len(ops))
+		if n := len(hunks); n > 0 && start <= hunks[n-1].end {
+			hunks[n-1].end = end
+		} else {
+			hunks = append(hunks, span{start, end})
+		}
+	}
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	// Line numbers in a and b at the start of each op.
+	aLine, bLine := make([]int, len(ops)), make([]int, len(ops))
+	for i, x, y := 0, 0, 0; i < len(ops); i++ {
+		aLine[i], bLine[i] = x, y
+		if ops[i].Kind != Insert {
+			x++
+		}
+		if ops[i].Kind != Delete {
+			y++
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString("--- " + fromName + "\n+++ " + toName + "\n")
+	for _, h := range hunks {
+		aCount, bCount := 0, 0
+		for _, op := range ops[h.start:h.end] {
+			if op.Kind != Insert {
+				aCount++
+			}
+			if op.Kind != Delete {
+				bCount++
+			}
+		}
+		out.WriteString("@@ -" + hunkRange(aLine[h.start], aCount) + " +" + hunkRange(bLine[h.start], bCount) + " @@\n")
+		for _, op := range ops[h.start:h.end] {
+			prefix := " "
+			switch op.Kind {
+			case Insert:
+				prefix = "+"
+			case Delete:
+				prefix = "-"
+			}
+			out.WriteString(prefix + op.Text)
+			if !strings.HasSuffix(op.Text, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return out.String()
+}
+
+// hunkRange formats one side of a hunk header. start is the 0-based index
+// of the hunk's first line; an empty side names the line before it.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return strconv.Itoa(start) + ",0"
+	}
+	if count == 1 {
+		return strconv.Itoa(start + 1)
+	}
+	return strconv.Itoa(start+1) + "," + strconv.Itoa(count)
+}
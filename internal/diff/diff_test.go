@@ -0,0 +1,114 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []Op
+	}{
+		{"same text", "same text", []Op{{Equal, "same text"}}},
+		{"ship on friday", "ship on monday", []Op{{Equal, "ship on "}, {Delete, "friday"}, {Insert, "monday"}}},
+		{"a b c", "a c", []Op{{Equal, "a "}, {Delete, "b "}, {Equal, "c"}}},
+		{"", "new", []Op{{Insert, "new"}}},
+		{"call bob, then alice", "call bob then the whole team",
+			[]Op{{Equal, "call bob"}, {Delete, ","}, {Equal, " then "}, {Delete, "alice"}, {Insert, "the whole team"}}},
+	}
+	for _, tt := range tests {
+		if got := Words(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Words(%q, %q) = %+v, want %+v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// Whatever the diff looks like, it must reconstruct both sides.
+func TestWords_Reconstructs(t *testing.T) {
+	vocab := strings.Fields("the a cat dog sat ran on mat . , \n")
+	r := rand.New(rand.NewSource(1))
+	text := func() string {
+		var b strings.Builder
+		for i := r.Intn(60); i > 0; i-- {
+			b.WriteString(vocab[r.Intn(len(vocab))] + " ")
+		}
+		return b.String()
+	}
+	for i := 0; i < 200; i++ {
+		a, b := text(), text()
+		var gotA, gotB strings.Builder
+		for _, op := range Words(a, b) {
+			if op.Kind != Insert {
+				gotA.WriteString(op.Text)
+			}
+			if op.Kind != Delete {
+				gotB.WriteString(op.Text)
+			}
+		}
+		if gotA.String() != a || gotB.String() != b {
+			t.Fatalf("round trip failed for %q -> %q", a, b)
+		}
+	}
+}
+
+func TestSideBySideHTML(t *testing.T) {
+	got := SideBySideHTML([]Op{{Equal, "x < "}, {Delete, "1"}, {Insert, "2"}})
+	want := `<table class="diff-side-by-side"><tr><td class="diff-old">x &lt; <del>1</del></td><td class="diff-new">x &lt; <ins>2</ins></td></tr></table>`
+	if got != want {
+		t.Errorf("SideBySideHTML = %s", got)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	b := "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven"
+	want := "--- a\n+++ b\n" +
+		"@@ -1,6 +1,6 @@\n one\n two\n-three\n+THREE\n four\n five\n six\n" +
+		"@@ -8,3 +8,4 @@\n eight\n nine\n ten\n+eleven\n\\ No newline at end of file\n"
+	if got := Unified(a, b, "a", "b"); got != want {
+		t.Errorf("Unified =\n%s\nwant\n%s", got, want)
+	}
+	if got := Unified(a, a, "a", "b"); got != "" {
+		t.Errorf("Unified of equal texts = %q, want empty", got)
+	}
+	if got, want := Unified("", "new\n", "a", "b"), "--- a\n+++ b\n@@ -0,0 +1 @@\n+new\n"; got != want {
+		t.Errorf("Unified from empty = %q, want %q", got, want)
+	}
+}
+
+func TestLines(t *testing.T) {
+	got := Lines("keep\nold\nkeep too\n", "keep\nnew\nkeep too\n")
+	want := []Op{{Equal, "keep\n"}, {Delete, "old\n"}, {Insert, "new\n"}, {Equal, "keep too\n"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines = %+v, want %+v", got, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := "# Plan\n- [ ] milk\n- [ ] eggs\n\nCall bob\n"
+	tests := []struct {
+		name, a, b, want string
+		conflicts        int
+	}{
+		{"only a changed", base + "more\n", base, base + "more\n", 0},
+		{"only b changed", base, "# Plan\n- [x] milk\n- [ ] eggs\n\nCall bob\n", "# Plan\n- [x] milk\n- [ ] eggs\n\nCall bob\n", 0},
+		{"separate changes",
+			"# Plan\n- [x] milk\n- [ ] eggs\n\nCall bob\n",
+			"# Plan\n- [ ] milk\n- [ ] eggs\n\nCall alice\n",
+			"# Plan\n- [x] milk\n- [ ] eggs\n\nCall alice\n", 0},
+		{"same change", base + "x\n", base + "x\n", base + "x\n", 0},
+		{"conflict",
+			"# Plan\n- [ ] milk\n- [ ] eggs\n\nCall bob today\n",
+			"# Plan\n- [ ] milk\n- [ ] eggs\n\nCall bob tomorrow",
+			"# Plan\n- [ ] milk\n- [ ] eggs\n\n<<<<<<< yours\nCall bob today\n=======\nCall bob tomorrow\n>>>>>>> current\n", 1},
+	}
+	for _, tt := range tests {
+		got, n := Merge(base, tt.a, tt.b, "yours", "current")
+		if got != tt.want || n != tt.conflicts {
+			t.Errorf("%s: Merge = %q, %d conflicts; want %q, %d", tt.name, got, n, tt.want, tt.conflicts)
+		}
+	}
+}
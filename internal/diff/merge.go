@@ -0,0 +1,124 @@
+package diff
+
+import "strings"
+
+// hunk replaces base lines [start, end) with lines.
+type hunk struct {
+	start, end int
+	lines      []string
+}
+
+// lineHunks returns the changes turning base into other, in order.
+func lineHunks(base, other []string) []hunk {
+	var out []hunk
+	var cur *hunk
+	i := 0
+	for _, op := range tokens(base, other) {
+		if op.Kind == Equal {
+			if cur != nil {
+				out = append(out, *cur)
+				cur = nil
+			}
+			i++
+			continue
+		}
+		if cur == nil {
+			cur = &hunk{start: i, end: i}
+		}
+		if op.Kind == Delete {
+			cur.end++
+			i++
+		} else {
+			cur.lines = append(cur.lines, op.Text)
+		}
+	}
+	if cur != nil {
+		out = append(out, *cur)
+	}
+	return out
+}
+
+// apply returns base[start:end] with hunks, which all lie inside it,
+// applied.
+func apply(base []string, start, end int, hunks []hunk) []string {
+	var out []string
+	for _, h := range hunks {
+		out = append(out, base[start:h.start]...)
+		out = append(out, h.lines...)
+		start = h.end
+	}
+	return append(out, base[start:end]...)
+}
+
+// Merge is a line-level three-way merge: it applies the changes a and b
+// each made to base. Where both changed the same or neighbouring lines
+// differently, the merged text holds both versions between conflict
+// markers labelled aName and bName, as git does, and the count of such
+// conflicts is returned.
+func Merge(base, a, b, aName, bName string) (string, int) {
+	baseLines := splitLines(base)
+	aHunks := lineHunks(baseLines, splitLines(a))
+	bHunks := lineHunks(baseLines, splitLines(b))
+
+	var out strings.Builder
+	write := func(lines []string) {
+		for _, l := range lines {
+			out.WriteString(l)
+		}
+	}
+	// section writes lines followed by a marker line, which must start a
+	// line of its own.
+	section := func(lines []string, marker string) {
+		write(lines)
+		if n := len(lines); n > 0 && !strings.HasSuffix(lines[n-1], "\n") {
+			out.WriteString("\n")
+		}
+		out.WriteString(marker + "\n")
+	}
+
+	conflicts, pos := 0, 0
+	for len(aHunks) > 0 || len(bHunks) > 0 {
+		// A region starts at the first remaining change and grows while a
+		// change on either side starts inside or right after it.
+		var inA, inB []hunk
+		var start int
+		if len(aHunks) > 0 && (len(bHunks) == 0 || aHunks[0].start <= bHunks[0].start) {
+			start = aHunks[0].start
+		} else {
+			start = bHunks[0].start
+		}
+		end := start
+		for {
+			if len(aHunks) > 0 && aHunks[0].start <= end {
+				inA, end = append(inA, aHunks[0]), max(end, aHunks[0].end)
+				aHunks = aHunks[1:]
+				continue
+			}
+			if len(bHunks) > 0 && bHunks[0].start <= end {
+				inB, end = append(inB, bHunks[0]), max(end, bHunks[0].end)
+				bHunks = bHunks[1:]
+				continue
+			}
+			break
+		}
+
+		write(baseLines[pos:start])
+		pos = end
+		aText, bText := apply(baseLines, start, end, inA), apply(baseLines, start, end, inB)
+		switch {
+		case len(inB) == 0:
+			write(aText)
+		case len(inA) == 0:
+			write(bText)
+		case strings.Join(aText, "") == strings.Join(bText, ""):
+			write(aText)
+		default:
+			conflicts++
+			out.WriteString("<<<<<<< " + aName + "\n")
+			section(aText, "=======")
+			section(bText, ">>>>>>> "+bName)
+		}
+	}
+	write(baseLines[pos:])
+	return out.String(), conflicts
+}
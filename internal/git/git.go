@@ -3,6 +3,8 @@
 // It deliberately reads .git/HEAD directly rather than shelling out to `git`,
 // so NoteFlow has no runtime dependency on a git binary. This supports the
 // "boring stack" goal — see docs/TODO.md → "Long-term Direction" goal 3.
+// The one exception is Repo, which runs git for the optional auto-commit
+// and sync mode; nothing else needs the binary.
 package git
 
 import (
@@ -0,0 +1,169 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNoGit is returned by Open when the git binary isn't on the PATH.
+var ErrNoGit = errors.New("git is not installed")
+
+// ErrNotRepo is returned by Open for a folder outside any git work tree.
+var ErrNotRepo = errors.New("not in a git repository")
+
+// Repo runs git commands in a folder of a work tree. Unlike the rest of
+// the package it needs the git binary: committing, pushing and pulling are
+// too much to reimplement.
+type Repo struct {
+	dir string
+}
+
+// Commit is one entry of Log.
+type Commit struct {
+	Hash     string    `json:"hash"`
+	ShortSHA string    `json:"short_sha"`
+	Author   string    `json:"author"`
+	Time     time.Time `json:"time"`
+	Subject  string    `json:"subject"`
+}
+
+// Open returns a Repo running git in dir, which must be inside a work
+// tree.
+func Open(dir string) (*Repo, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, ErrNoGit
+	}
+	r := &Repo{dir: dir}
+	out, err := r.run(context.Background(), "rev-parse", "--is-inside-work-tree")
+	if err != nil || out != "true" {
+		return nil, fmt.Errorf("%s: %w", dir, ErrNotRepo)
+	}
+	return r, nil
+}
+
+// run runs git with args in the repo's folder and returns its trimmed
+// output. git never prompts: a remote that needs a password fails instead
+// of hanging the server. Errors carry git's own message.
+func (r *Repo) run(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "LC_ALL=C")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// CommitPaths commits the changes under paths, relative to the repo's
+// folder, and reports whether there were any. Other changes in the work
+// tree, staged or not, are left alone. Paths that neither exist nor are
+// tracked, and paths .gitignore excludes, are skipped; exclude lists
+// paths under them to leave out. Without a configured identity, commits
+// are authored as "NoteFlow".
+func (r *Repo) CommitPaths(ctx context.Context, message string, paths, exclude []string) (bool, error) {
+	var spec []string
+	for _, p := range paths {
+		if _, err := os.Stat(filepath.Join(r.dir, p)); err != nil {
+			if tracked, _ := r.run(ctx, "ls-files", "--", p); tracked == "" {
+				continue
+			}
+		}
+		if _, err := r.run(ctx, "check-ignore", "-q", "--", p); err == nil {
+			continue // ignored
+		}
+		spec = append(spec, p)
+	}
+	if len(spec) == 0 {
+		return false, nil
+	}
+	for _, p := range exclude {
+		spec = append(spec, ":(exclude)"+p)
+	}
+
+	if _, err := r.run(ctx, append([]string{"add", "-A", "--"}, spec...)...); err != nil {
+		return false, err
+	}
+	staged, err := r.run(ctx, append([]string{"diff", "--cached", "--name-only", "-z", "--"}, spec...)...)
+	if err != nil || staged == "" {
+		return false, err
+	}
+	// Commit exactly the staged files: a directory in spec with nothing
+	// tracked under it would fail the commit.
+	args := []string{"commit", "-q", "-m", message, "--"}
+	if email, _ := r.run(ctx, "config", "user.email"); email == "" {
+		args = append([]string{"-c", "user.name=NoteFlow", "-c", "user.email=noteflow@localhost"}, args...)
+	}
+	for _, name := range strings.Split(strings.TrimRight(staged, "\x00"), "\x00") {
+		args = append(args, ":(literal)"+name)
+	}
+	if _, err := r.run(ctx, args...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// logFieldSep separates the fields of Log's format; it can't occur in a
+// commit subject.
+const logFieldSep = "\x1f"
+
+// Log returns up to limit commits touching paths, newest first; all
+// commits when paths is empty. A repo without commits has none.
+func (r *Repo) Log(ctx context.Context, limit int, paths []string) ([]Commit, error) {
+	if _, err := r.run(ctx, "rev-parse", "--verify", "-q", "HEAD"); err != nil {
+		return nil, nil
+	}
+	args := []string{"log", fmt.Sprintf("-n%d", limit), "--format=%H%x1f%h%x1f%an%x1f%aI%x1f%s", "--"}
+	out, err := r.run(ctx, append(args, paths...)...)
+	if err != nil || out == "" {
+		return nil, err
+	}
+	var commits []Commit
+	for _, line := range strings.Split(out, "\n") {
+		f := strings.Split(line, logFieldSep)
+		if len(f) != 5 {
+			continue
+		}
+		t, _ := time.Parse(time.RFC3339, f[3])
+		commits = append(commits, Commit{Hash: f[0], ShortSHA: f[1], Author: f[2], Time: t, Subject: f[4]})
+	}
+	return commits, nil
+}
+
+// CurrentBranch returns the checked-out branch; empty on a detached HEAD.
+func (r *Repo) CurrentBranch(ctx context.Context) string {
+	out, _ := r.run(ctx, "symbolic-ref", "-q", "--short", "HEAD")
+	return out
+}
+
+// Pull rebases the current branch onto branch of remote. Local changes
+// that aren't committed are stashed around it. A rebase that stops on a
+// conflict is aborted, leaving the branch as it was, and returns the
+// error.
+func (r *Repo) Pull(ctx context.Context, remote, branch string) error {
+	_, err := r.run(ctx, "pull", "-q", "--rebase", "--autostash", remote, branch)
+	if err != nil {
+		if _, statErr := r.run(ctx, "rev-parse", "-q", "--verify", "REBASE_HEAD"); statErr == nil {
+			r.run(context.Background(), "rebase", "--abort")
+		}
+	}
+	return err
+}
+
+// Push pushes the current branch to branch of remote.
+func (r *Repo) Push(ctx context.Context, remote, branch string) error {
+	_, err := r.run(ctx, "push", "-q", remote, "HEAD:refs/heads/"+branch)
+	return err
+}
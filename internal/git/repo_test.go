@@ -0,0 +1,110 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newRepo creates a work tree with a committed README.md and returns it
+// opened, skipping the test when git isn't installed.
+func newRepo(t *testing.T) (*Repo, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q", "-b", "main")
+	gitCmd(t, dir, "config", "user.email", "test@example.com")
+	gitCmd(t, dir, "config", "user.name", "Test")
+	writeFile(t, filepath.Join(dir, "README.md"), "readme\n")
+	gitCmd(t, dir, "add", "README.md")
+	gitCmd(t, dir, "commit", "-q", "-m", "init")
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return repo, dir
+}
+
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestOpen_NotARepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if _, err := Open(t.TempDir()); !errors.Is(err, ErrNotRepo) {
+		t.Errorf("Open: err = %v, want ErrNotRepo", err)
+	}
+}
+
+func TestCommitPaths(t *testing.T) {
+	repo, dir := newRepo(t)
+	ctx := context.Background()
+	writeFile(t, filepath.Join(dir, "notes.md"), "## note\n")
+	writeFile(t, filepath.Join(dir, "assets/images/a.png"), "png")
+	writeFile(t, filepath.Join(dir, "assets/.backups/notes-1.md"), "old")
+	writeFile(t, filepath.Join(dir, "main.go"), "package main\n")
+	paths, exclude := []string{"notes.md", "trash.md", "assets"}, []string{"assets/.backups"}
+
+	committed, err := repo.CommitPaths(ctx, "Add note", paths, exclude)
+	if err != nil || !committed {
+		t.Fatalf("CommitPaths = %v, %v", committed, err)
+	}
+	files := gitCmd(t, dir, "show", "--name-only", "--format=", "HEAD")
+	if files != "assets/images/a.png\nnotes.md" {
+		t.Errorf("committed files:\n%s", files)
+	}
+	if status := gitCmd(t, dir, "status", "--porcelain"); !strings.Contains(status, "?? main.go") {
+		t.Errorf("main.go was touched; status:\n%s", status)
+	}
+
+	committed, err = repo.CommitPaths(ctx, "Nothing", paths, exclude)
+	if err != nil || committed {
+		t.Errorf("CommitPaths without changes = %v, %v", committed, err)
+	}
+
+	commits, err := repo.Log(ctx, 5, []string{"notes.md"})
+	if err != nil || len(commits) != 1 || commits[0].Subject != "Add note" || commits[0].Author != "Test" {
+		t.Errorf("Log = %+v, %v", commits, err)
+	}
+}
+
+func TestPushPull(t *testing.T) {
+	repo, dir := newRepo(t)
+	ctx := context.Background()
+	remote := t.TempDir()
+	gitCmd(t, remote, "init", "-q", "--bare", "-b", "main")
+	gitCmd(t, dir, "remote", "add", "origin", remote)
+	if err := repo.Push(ctx, "origin", "main"); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	// Another clone adds a note and pushes it.
+	other := t.TempDir()
+	gitCmd(t, other, "clone", "-q", remote, ".")
+	writeFile(t, filepath.Join(other, "notes.md"), "## from elsewhere\n")
+	gitCmd(t, other, "add", "notes.md")
+	gitCmd(t, other, "-c", "user.name=Other", "-c", "user.email=o@example.com", "commit", "-q", "-m", "elsewhere")
+	gitCmd(t, other, "push", "-q", "origin", "HEAD:main")
+
+	if err := repo.Pull(ctx, "origin", "main"); err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if got := gitCmd(t, dir, "log", "-1", "--format=%s"); got != "elsewhere" {
+		t.Errorf("HEAD after pull is %q", got)
+	}
+	if branch := repo.CurrentBranch(ctx); branch != "main" {
+		t.Errorf("CurrentBranch = %q", branch)
+	}
+}
@@ -0,0 +1,168 @@
+// Package github is a minimal client for the slice of the GitHub REST API
+// NoteFlow uses to move tasks between notes.md and issue trackers. It talks
+// to the API directly over net/http (no go-github dependency) and only
+// models the fields NoteFlow reads.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAPI is the public GitHub REST endpoint. GitHub Enterprise users set
+// a different base URL in config.
+const DefaultAPI = "https://api.github.com"
+
+// Client performs authenticated GitHub REST calls.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a client for baseURL (DefaultAPI when empty) using a
+// personal access token.
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPI
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 20 * time.Second},
+	}
+}
+
+// Issue is the subset of a GitHub issue NoteFlow cares about.
+type Issue struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	State     string    `json:"state"`
+	HTMLURL   string    `json:"html_url"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// PullRequest is non-nil when the "issue" is actually a PR; the issues
+	// API returns both.
+	PullRequest *struct{} `json:"pull_request,omitempty"`
+}
+
+// CreateIssue opens a new issue in repo ("owner/name").
+func (c *Client) CreateIssue(ctx context.Context, repo, title, body string, labels []string) (*Issue, error) {
+	payload := map[string]any{"title": title, "body": body}
+	if len(labels) > 0 {
+		payload["labels"] = labels
+	}
+	var issue Issue
+	if err := c.do(ctx, http.MethodPost, "/repos/"+repo+"/issues", payload, &issue); err != nil {
+		return nil, fmt.Errorf("create issue in %s: %w", repo, err)
+	}
+	return &issue, nil
+}
+
+// SetIssueState closes or reopens an issue. state is "open" or "closed".
+func (c *Client) SetIssueState(ctx context.Context, repo string, number int, state string) error {
+	path := fmt.Sprintf("/repos/%s/issues/%d", repo, number)
+	if err := c.do(ctx, http.MethodPatch, path, map[string]string{"state": state}, nil); err != nil {
+		return fmt.Errorf("set %s#%d %s: %w", repo, number, state, err)
+	}
+	return nil
+}
+
+// CurrentUser returns the login of the token's owner.
+func (c *Client) CurrentUser(ctx context.Context) (string, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/user", nil, &user); err != nil {
+		return "", fmt.Errorf("get current user: %w", err)
+	}
+	return user.Login, nil
+}
+
+// maxIssuePages caps pagination so a repo with thousands of assigned issues
+// can't turn one sync into hundreds of requests.
+const maxIssuePages = 5
+
+// ListAssignedIssues returns open issues in repo assigned to login, skipping
+// pull requests.
+func (c *Client) ListAssignedIssues(ctx context.Context, repo, login string) ([]Issue, error) {
+	var out []Issue
+	for page := 1; page <= maxIssuePages; page++ {
+		path := fmt.Sprintf("/repos/%s/issues?state=open&assignee=%s&per_page=100&page=%d", repo, login, page)
+		var batch []Issue
+		if err := c.do(ctx, http.MethodGet, path, nil, &batch); err != nil {
+			return nil, fmt.Errorf("list issues in %s: %w", repo, err)
+		}
+		for _, is := range batch {
+			if is.PullRequest == nil {
+				out = append(out, is)
+			}
+		}
+		if len(batch) < 100 {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(buf)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// issueURLRE matches a GitHub issue URL as written back into task text.
+var issueURLRE = regexp.MustCompile(`https://[^/\s]+/([\w.-]+/[\w.-]+)/issues/(\d+)`)
+
+// ParseIssueURL finds the first issue URL in text and returns its repo
+// ("owner/name") and number. ok is false when text has no issue URL.
+func ParseIssueURL(text string) (repo string, number int, ok bool) {
+	m := issueURLRE.FindStringSubmatch(text)
+	if m == nil {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return m[1], n, true
+}
@@ -0,0 +1,75 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateIssue_SendsPayloadAndAuth(t *testing.T) {
+	var got map[string]any
+	var auth, path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number":12,"title":"ship it","state":"open","html_url":"https://github.com/o/r/issues/12"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "secret")
+	issue, err := c.CreateIssue(context.Background(), "o/r", "ship it", "body", []string{"noteflow"})
+	if err != nil {
+		t.Fatalf("CreateIssue: %v", err)
+	}
+	if path != "/repos/o/r/issues" {
+		t.Errorf("path = %q", path)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("auth = %q", auth)
+	}
+	if got["title"] != "ship it" || got["body"] != "body" {
+		t.Errorf("payload = %v", got)
+	}
+	if issue.Number != 12 || issue.HTMLURL != "https://github.com/o/r/issues/12" {
+		t.Errorf("issue = %+v", issue)
+	}
+}
+
+func TestSetIssueState_ErrorSurfacesBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("method = %s, want PATCH", r.Method)
+		}
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	err := NewClient(srv.URL, "").SetIssueState(context.Background(), "o/r", 3, "closed")
+	if err == nil {
+		t.Fatal("expected error for 404")
+	}
+}
+
+func TestParseIssueURL(t *testing.T) {
+	tests := []struct {
+		in     string
+		repo   string
+		number int
+		ok     bool
+	}{
+		{"- [ ] fix it https://github.com/acme/web-app/issues/42", "acme/web-app", 42, true},
+		{"- [ ] see https://ghe.corp.example/team/svc.go/issues/7 today", "team/svc.go", 7, true},
+		{"- [ ] PR https://github.com/acme/web/pull/9", "", 0, false},
+		{"- [ ] no link", "", 0, false},
+	}
+	for _, tt := range tests {
+		repo, n, ok := ParseIssueURL(tt.in)
+		if repo != tt.repo || n != tt.number || ok != tt.ok {
+			t.Errorf("ParseIssueURL(%q) = (%q, %d, %v), want (%q, %d, %v)", tt.in, repo, n, ok, tt.repo, tt.number, tt.ok)
+		}
+	}
+}
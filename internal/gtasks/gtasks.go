@@ -0,0 +1,202 @@
+// Package gtasks is a minimal Google Tasks API client for NoteFlow's task
+// mirror, plus the OAuth plumbing to authorize it. Like the other
+// integration packages it uses net/http directly rather than Google's SDK.
+package gtasks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultAPI is the Google Tasks REST endpoint.
+const DefaultAPI = "https://tasks.googleapis.com/tasks/v1"
+
+// DefaultList is Google's alias for the user's default task list.
+const DefaultList = "@default"
+
+// Task statuses.
+const (
+	StatusNeedsAction = "needsAction"
+	StatusCompleted   = "completed"
+)
+
+// ErrNotFound is returned when a task no longer exists.
+var ErrNotFound = errors.New("google tasks: task not found")
+
+// maxTaskPages bounds ListTasks pagination (100 tasks per page).
+const maxTaskPages = 20
+
+// Client performs authenticated Google Tasks calls.
+type Client struct {
+	baseURL string
+	tokens  *TokenSource
+	http    *http.Client
+}
+
+// NewClient creates a client for baseURL (DefaultAPI when empty).
+func NewClient(baseURL string, tokens *TokenSource) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPI
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		tokens:  tokens,
+		http:    &http.Client{Timeout: 20 * time.Second},
+	}
+}
+
+// Task is the subset of a Google task NoteFlow cares about.
+type Task struct {
+	ID          string    `json:"id,omitempty"`
+	Title       string    `json:"title"`
+	Status      string    `json:"status,omitempty"`
+	Due         string    `json:"due,omitempty"` // RFC 3339; Google keeps only the date
+	Deleted     bool      `json:"deleted,omitempty"`
+	Updated     time.Time `json:"updated,omitempty"`
+	WebViewLink string    `json:"webViewLink,omitempty"`
+}
+
+// DueDate returns the due date as YYYY-MM-DD, or "" when unset.
+func (t Task) DueDate() string {
+	if len(t.Due) < 10 {
+		return ""
+	}
+	return t.Due[:10]
+}
+
+// Completed reports whether the task is checked off.
+func (t Task) Completed() bool {
+	return t.Status == StatusCompleted
+}
+
+// DueValue converts YYYY-MM-DD to the RFC 3339 form the API expects.
+func DueValue(date string) string {
+	if date == "" {
+		return ""
+	}
+	return date + "T00:00:00.000Z"
+}
+
+// ListTasks returns every task in list, completed and hidden ones included,
+// so completions made on a phone are visible to the sync.
+func (c *Client) ListTasks(ctx context.Context, list string) ([]Task, error) {
+	var all []Task
+	pageToken := ""
+	for page := 0; page < maxTaskPages; page++ {
+		q := url.Values{
+			"showCompleted": {"true"},
+			"showHidden":    {"true"},
+			"maxResults":    {"100"},
+		}
+		if pageToken != "" {
+			q.Set("pageToken", pageToken)
+		}
+		var resp struct {
+			Items         []Task `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := c.do(ctx, http.MethodGet, listPath(list)+"?"+q.Encode(), nil, &resp); err != nil {
+			return nil, fmt.Errorf("list tasks in %s: %w", list, err)
+		}
+		all = append(all, resp.Items...)
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+	return all, nil
+}
+
+// InsertTask creates a task in list.
+func (c *Client) InsertTask(ctx context.Context, list string, task Task) (*Task, error) {
+	var out Task
+	if err := c.do(ctx, http.MethodPost, listPath(list), task, &out); err != nil {
+		return nil, fmt.Errorf("insert task: %w", err)
+	}
+	return &out, nil
+}
+
+// PatchTask updates the given fields of a task. Keys follow the API's
+// JSON names ("title", "due", "status"); a nil value clears the field.
+func (c *Client) PatchTask(ctx context.Context, list, id string, fields map[string]any) error {
+	if err := c.do(ctx, http.MethodPatch, listPath(list)+"/"+url.PathEscape(id), fields, nil); err != nil {
+		return fmt.Errorf("patch task %s: %w", id, err)
+	}
+	return nil
+}
+
+func listPath(list string) string {
+	return "/lists/" + url.PathEscape(list) + "/tasks"
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(buf)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// taskLinkRE matches a Google Tasks web link as written into task text.
+var taskLinkRE = regexp.MustCompile(`https://tasks\.google\.com/\S+`)
+
+// Link returns the web link recorded for t, falling back to one built from
+// the ID when the API didn't include webViewLink.
+func (t Task) Link() string {
+	if t.WebViewLink != "" {
+		return t.WebViewLink
+	}
+	return "https://tasks.google.com/task/" + t.ID
+}
+
+// ParseTaskLink returns the first Google Tasks link in text.
+func ParseTaskLink(text string) (link string, ok bool) {
+	link = taskLinkRE.FindString(text)
+	return link, link != ""
+}
+
+// StripTaskLink removes any Google Tasks link from text.
+func StripTaskLink(text string) string {
+	return strings.Join(strings.Fields(taskLinkRE.ReplaceAllString(text, "")), " ")
+}
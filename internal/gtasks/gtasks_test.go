@@ -0,0 +1,78 @@
+package gtasks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTokenSource_RefreshesOnceAndCaches(t *testing.T) {
+	var refreshes int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "rt" {
+			t.Errorf("form = %v", r.Form)
+		}
+		refreshes++
+		w.Write([]byte(`{"access_token":"at","expires_in":3600}`))
+	}))
+	defer srv.Close()
+	orig := TokenEndpoint
+	TokenEndpoint = srv.URL
+	defer func() { TokenEndpoint = orig }()
+
+	ts := NewTokenSource("id", "secret", "rt")
+	for i := 0; i < 3; i++ {
+		tok, err := ts.Token(context.Background())
+		if err != nil || tok != "at" {
+			t.Fatalf("Token = %q, %v", tok, err)
+		}
+	}
+	if refreshes != 1 {
+		t.Errorf("refreshes = %d, want 1", refreshes)
+	}
+}
+
+func TestListTasks_IncludesCompletedAndPages(t *testing.T) {
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"at","expires_in":3600}`))
+	}))
+	defer tokens.Close()
+	orig := TokenEndpoint
+	TokenEndpoint = tokens.URL
+	defer func() { TokenEndpoint = orig }()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer at" {
+			t.Errorf("auth = %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Path != "/lists/@default/tasks" || r.URL.Query().Get("showCompleted") != "true" {
+			t.Errorf("request = %s", r.URL)
+		}
+		if r.URL.Query().Get("pageToken") == "" {
+			w.Write([]byte(`{"items":[{"id":"a","title":"one","due":"2026-10-20T00:00:00.000Z"}],"nextPageToken":"p2"}`))
+			return
+		}
+		w.Write([]byte(`{"items":[{"id":"b","title":"two","status":"completed"}]}`))
+	}))
+	defer api.Close()
+
+	tasks, err := NewClient(api.URL, NewTokenSource("id", "s", "rt")).ListTasks(context.Background(), DefaultList)
+	if err != nil {
+		t.Fatalf("ListTasks: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].DueDate() != "2026-10-20" || !tasks[1].Completed() {
+		t.Errorf("tasks = %+v", tasks)
+	}
+}
+
+func TestParseTaskLink(t *testing.T) {
+	text := "[ ] call mom https://tasks.google.com/task/abc123?sa=6 #home"
+	if link, ok := ParseTaskLink(text); !ok || link != "https://tasks.google.com/task/abc123?sa=6" {
+		t.Errorf("ParseTaskLink = %q, %v", link, ok)
+	}
+	if got := StripTaskLink(text); got != "[ ] call mom #home" {
+		t.Errorf("StripTaskLink = %q", got)
+	}
+}
@@ -0,0 +1,122 @@
+package gtasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Google OAuth endpoints. Variables so tests can point them at a fake.
+var (
+	AuthEndpoint  = "https://accounts.google.com/o/oauth2/v2/auth"
+	TokenEndpoint = "https://oauth2.googleapis.com/token"
+)
+
+// Scope grants read/write access to the user's task lists and nothing else.
+const Scope = "https://www.googleapis.com/auth/tasks"
+
+// AuthURL returns the consent-screen URL for the installed-app flow. The
+// user is redirected to redirectURI (a loopback address) with ?code=.
+func AuthURL(clientID, redirectURI, state string) string {
+	q := url.Values{
+		"client_id":     {clientID},
+		"redirect_uri":  {redirectURI},
+		"response_type": {"code"},
+		"scope":         {Scope},
+		"access_type":   {"offline"},
+		"prompt":        {"consent"}, // always return a refresh token
+		"state":         {state},
+	}
+	return AuthEndpoint + "?" + q.Encode()
+}
+
+// tokenResponse is the token endpoint's reply for both grant types.
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// ExchangeCode trades an authorization code for a refresh token.
+func ExchangeCode(ctx context.Context, clientID, clientSecret, code, redirectURI string) (string, error) {
+	tok, err := postToken(ctx, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+		"redirect_uri":  {redirectURI},
+	})
+	if err != nil {
+		return "", err
+	}
+	if tok.RefreshToken == "" {
+		return "", fmt.Errorf("google returned no refresh token")
+	}
+	return tok.RefreshToken, nil
+}
+
+// TokenSource mints access tokens from a long-lived refresh token, caching
+// each one until shortly before it expires.
+type TokenSource struct {
+	clientID     string
+	clientSecret string
+	refreshToken string
+
+	mu      sync.Mutex
+	access  string
+	expires time.Time
+}
+
+// NewTokenSource creates a TokenSource for an authorized OAuth client.
+func NewTokenSource(clientID, clientSecret, refreshToken string) *TokenSource {
+	return &TokenSource{clientID: clientID, clientSecret: clientSecret, refreshToken: refreshToken}
+}
+
+// Token returns a valid access token, refreshing it when needed.
+func (ts *TokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.access != "" && time.Now().Before(ts.expires) {
+		return ts.access, nil
+	}
+	tok, err := postToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {ts.refreshToken},
+		"client_id":     {ts.clientID},
+		"client_secret": {ts.clientSecret},
+	})
+	if err != nil {
+		return "", err
+	}
+	ts.access = tok.AccessToken
+	ts.expires = time.Now().Add(time.Duration(tok.ExpiresIn)*time.Second - time.Minute)
+	return ts.access, nil
+}
+
+func postToken(ctx context.Context, form url.Values) (*tokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := (&http.Client{Timeout: 20 * time.Second}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("google token endpoint: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var tok tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return nil, err
+	}
+	return &tok, nil
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AgendaHandler serves tasks bucketed by due date.
+type AgendaHandler struct {
+	noteManager  *services.NoteManager
+	taskRegistry *services.TaskRegistryService
+}
+
+// NewAgendaHandler creates a new agenda handler
+func NewAgendaHandler(noteManager *services.NoteManager, taskRegistry *services.TaskRegistryService) *AgendaHandler {
+	return &AgendaHandler{noteManager: noteManager, taskRegistry: taskRegistry}
+}
+
+// GetAgenda returns open tasks bucketed into overdue / today / tomorrow /
+// this week. ?scope=all covers every registered folder instead of just
+// this one; ?format=markdown returns bullet lists for a daily note.
+// GET /api/agenda
+func (h *AgendaHandler) GetAgenda(c *fiber.Ctx) error {
+	var items []services.AgendaItem
+	switch c.Query("scope") {
+	case "", "folder":
+		items = h.noteManager.AgendaItems()
+	case "all":
+		global, err := h.taskRegistry.GetGlobalTasks()
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to get global tasks: "+err.Error())
+		}
+		items = services.GlobalAgendaItems(global.Tasks)
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "scope must be folder or all")
+	}
+
+	agenda := services.BuildAgenda(items, time.Now())
+	if c.Query("format") == "markdown" {
+		c.Set("Content-Type", "text/markdown; charset=utf-8")
+		return c.SendString(agenda.Markdown())
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   agenda,
+	})
+}
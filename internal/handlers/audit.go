@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuditHandler serves the audit log of a notes folder.
+type AuditHandler struct {
+	log         *services.AuditLog
+	noteManager *services.NoteManager
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(log *services.AuditLog, noteManager *services.NoteManager) *AuditHandler {
+	return &AuditHandler{log: log, noteManager: noteManager}
+}
+
+// Record is middleware for routes that change things: once the handler
+// succeeds it records an entry for each note the request created,
+// changed or deleted, or a single request entry when it touched none.
+// Changes made at the same moment by another request or a background job
+// may be attributed to this one.
+func (h *AuditHandler) Record(c *fiber.Ctx) error {
+	before := h.noteManager.Snapshot()
+	if err := c.Next(); err != nil {
+		return err
+	}
+	if c.Response().StatusCode() >= fiber.StatusBadRequest {
+		return nil
+	}
+	entries := h.noteManager.AuditChanges(before)
+	if len(entries) == 0 {
+		entries = []models.AuditEntry{{Action: models.AuditRequest}}
+	}
+	now := time.Now()
+	request := c.Method() + " " + c.Path()
+	for i := range entries {
+		entries[i].Time, entries[i].IP, entries[i].Request = now, c.IP(), request
+	}
+	h.log.Record(entries)
+	return nil
+}
+
+// List returns audit entries, newest first. ?since= and ?until= take an
+// RFC 3339 time or a date (until then includes the whole day), ?action=
+// and ?note= a note ID filter, and ?limit= caps the count.
+// GET /api/audit
+func (h *AuditHandler) List(c *fiber.Ctx) error {
+	var q services.AuditQuery
+	var err error
+	if q.Since, err = parseAuditTime(c.Query("since"), false); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid since: "+err.Error())
+	}
+	if q.Until, err = parseAuditTime(c.Query("until"), true); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid until: "+err.Error())
+	}
+	switch q.Action = c.Query("action"); q.Action {
+	case "", models.AuditCreate, models.AuditUpdate, models.AuditDelete, models.AuditToggle, models.AuditRequest:
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid action")
+	}
+	q.NoteID = c.Query("note")
+	if s := c.Query("limit"); s != "" {
+		if q.Limit, err = strconv.Atoi(s); err != nil || q.Limit < 1 {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid limit")
+		}
+	}
+
+	entries, err := h.log.Query(q)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(models.APIResponse{Status: "success", Data: entries})
+}
+
+// parseAuditTime reads an RFC 3339 time or a local date, which as an
+// upper bound means the end of that day. Empty is the zero time.
+func parseAuditTime(s string, end bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
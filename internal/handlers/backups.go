@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// backupError maps an unknown backup name to a 404.
+func backupError(err error) error {
+	if errors.Is(err, services.ErrBackupNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
+
+// ListBackups returns the backups of notes.md, newest first.
+// GET /api/backups
+func (h *NotesHandler) ListBackups(c *fiber.Ctx) error {
+	backups, err := h.noteManager.ListBackups()
+	if err != nil {
+		return backupError(err)
+	}
+	if backups == nil {
+		backups = []models.Backup{}
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   backups,
+	})
+}
+
+// GetBackup returns a backup of notes.md as markdown.
+// GET /api/backups/:name
+func (h *NotesHandler) GetBackup(c *fiber.Ctx) error {
+	data, err := h.noteManager.GetBackup(c.Params("name"))
+	if err != nil {
+		return backupError(err)
+	}
+	c.Set("Content-Type", "text/markdown; charset=utf-8")
+	return c.Send(data)
+}
+
+// RestoreBackup replaces notes.md with a backup.
+// POST /api/backups/:name/restore
+func (h *NotesHandler) RestoreBackup(c *fiber.Ctx) error {
+	if err := h.noteManager.RestoreBackup(c.Params("name")); err != nil {
+		return backupError(err)
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: "notes.md restored from " + c.Params("name"),
+	})
+}
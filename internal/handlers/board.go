@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetBoard returns this folder's tasks grouped into todo, doing and done
+// columns.
+// GET /api/board
+func (h *TasksHandler) GetBoard(c *fiber.Ctx) error {
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   h.noteManager.Board(),
+	})
+}
+
+// SetTaskState moves a task to another column, e.g. after a drag on the
+// board.
+// PUT /api/board/:index  {"state": "doing"}
+func (h *TasksHandler) SetTaskState(c *fiber.Ctx) error {
+	index, err := strconv.Atoi(c.Params("index"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid task index")
+	}
+	var req models.TaskStateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
+	}
+	state, ok := models.ParseTaskState(req.State)
+	if !ok {
+		return fiber.NewError(fiber.StatusBadRequest, "state must be todo, doing or done")
+	}
+
+	if err := h.noteManager.SetTaskState(index, state); err != nil {
+		return saveError(err, fiber.StatusNotFound, "Task not found: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{Status: "success"})
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ConfigHandler shows the configuration in effect.
+type ConfigHandler struct {
+	settings   models.Config
+	configPath string
+	folder     string
+}
+
+// NewConfigHandler creates a config handler for the settings in effect,
+// read from configPath, in the workspace serving folder.
+func NewConfigHandler(settings models.Config, configPath, folder string) *ConfigHandler {
+	return &ConfigHandler{settings: settings, configPath: configPath, folder: folder}
+}
+
+// GetConfig returns the settings in effect, credentials redacted.
+// GET /api/config
+func (h *ConfigHandler) GetConfig(c *fiber.Ctx) error {
+	redacted, err := h.settings.Redacted()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to read config: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   models.ConfigView{Path: h.configPath, Folder: h.folder, Config: redacted},
+	})
+}
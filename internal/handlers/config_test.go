@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestConfigHandler_GetConfig(t *testing.T) {
+	settings := models.Config{Theme: "light-blue", Auth: models.AuthConfig{Token: "secret"}, DataDir: "/notes"}
+	app := fiber.New()
+	app.Get("/api/config", NewConfigHandler(settings, "/home/me/.config/noteflow/noteflow.json", "/notes").GetConfig)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/config", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body struct {
+		Data models.ConfigView `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	view := body.Data
+	if view.Folder != "/notes" || view.Config["theme"] != "light-blue" || view.Config["data_dir"] != "/notes" {
+		t.Errorf("view = %+v", view)
+	}
+	if token := view.Config["auth"].(map[string]any)["token"]; token != "***" {
+		t.Errorf("auth token = %v, want redacted", token)
+	}
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// DigestHandler exposes the email task digest.
+type DigestHandler struct {
+	digest *services.DigestService
+}
+
+// NewDigestHandler creates a new digest handler
+func NewDigestHandler(digest *services.DigestService) *DigestHandler {
+	return &DigestHandler{digest: digest}
+}
+
+// Send emails the digest immediately, e.g. to check the SMTP settings.
+// POST /api/digest/send
+func (h *DigestHandler) Send(c *fiber.Ctx) error {
+	if !h.digest.Enabled() {
+		return fiber.NewError(fiber.StatusBadRequest, "No digest configured (set digest.to and digest.smtp.host in noteflow.json)")
+	}
+	digest, err := h.digest.Send(c.UserContext())
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadGateway, "Digest failed: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   digest,
+	})
+}
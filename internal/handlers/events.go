@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// eventsWriteWait bounds every write to an events socket.
+	eventsWriteWait = 10 * time.Second
+	// eventsPingPeriod is how often idle sockets are pinged, which keeps
+	// proxies from closing them and notices clients that vanished.
+	eventsPingPeriod = 30 * time.Second
+)
+
+// The zero upgrader only accepts same-origin requests, so other sites
+// can't follow along through a visitor's browser.
+var eventsUpgrader = websocket.FastHTTPUpgrader{}
+
+// EventsHandler streams note and task changes to open pages
+type EventsHandler struct {
+	hub *services.EventHub
+}
+
+// NewEventsHandler creates a new events handler
+func NewEventsHandler(hub *services.EventHub) *EventsHandler {
+	return &EventsHandler{hub: hub}
+}
+
+// Stream upgrades to a WebSocket and sends each services.Event as a JSON
+// text message until the client goes away. Messages from the client are
+// ignored.
+// GET /ws
+func (h *EventsHandler) Stream(c *fiber.Ctx) error {
+	if !websocket.FastHTTPIsWebSocketUpgrade(c.Context()) {
+		return fiber.NewError(fiber.StatusUpgradeRequired, "WebSocket upgrade required")
+	}
+	// On failure Upgrade has already written the error response.
+	_ = eventsUpgrader.Upgrade(c.Context(), h.stream)
+	return nil
+}
+
+func (h *EventsHandler) stream(conn *websocket.Conn) {
+	defer conn.Close()
+	events, unsubscribe := h.hub.Subscribe()
+	defer unsubscribe()
+
+	// Reading handles pings and close frames and notices a dropped client.
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(eventsPingPeriod)
+	defer ping.Stop()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(eventsWriteWait))
+			if err := conn.WriteJSON(e); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventsWriteWait)); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
+
+// TaskStream sends the task registry's changes (tasks discovered,
+// completed, reopened or removed in any registered folder) as Server-Sent
+// Events, for clients that can't use the WebSocket. Each is an
+// "event: tasks.changed" whose data is the services.Event as JSON.
+// GET /api/global-tasks/events
+func (h *EventsHandler) TaskStream(c *fiber.Ctx) error {
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("X-Accel-Buffering", "no") // stop nginx from buffering the stream
+
+	events, unsubscribe := h.hub.Subscribe()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+		// Sends the headers at once, and asks clients to reconnect after 5s.
+		fmt.Fprint(w, "retry: 5000\n\n")
+		if w.Flush() != nil {
+			return
+		}
+
+		ping := time.NewTicker(eventsPingPeriod)
+		defer ping.Stop()
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				if e.Type != services.EventTasksChanged {
+					continue
+				}
+				data, err := json.Marshal(e)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+			case <-ping.C:
+				// A comment line; writing it finds clients that went away.
+				fmt.Fprint(w, ": ping\n\n")
+			}
+			if w.Flush() != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
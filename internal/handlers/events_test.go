@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+func serveEvents(t *testing.T, hub *services.EventHub) string {
+	t.Helper()
+	app := fiber.New()
+	app.Get("/ws", NewEventsHandler(hub).Stream)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Listener(ln)
+	t.Cleanup(func() { app.Shutdown() })
+	return "ws://" + ln.Addr().String() + "/ws"
+}
+
+func TestEventsHandler_StreamsNoteChanges(t *testing.T) {
+	hub := services.NewEventHub()
+	mgr, err := services.NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr.SetEvents(hub)
+
+	conn, _, err := websocket.DefaultDialer.Dial(serveEvents(t, hub), nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	// The socket subscribes just after the handshake; keep adding notes
+	// until one gets through.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(20 * time.Millisecond):
+				mgr.AddNote("live", "hello")
+			}
+		}
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var e services.Event
+	if err := conn.ReadJSON(&e); err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+	if e.Type != services.EventNoteCreated || e.NoteID == "" || e.Folder != mgr.GetBasePath() {
+		t.Errorf("got event %+v", e)
+	}
+}
+
+func TestEventsHandler_RejectsOtherOrigins(t *testing.T) {
+	url := serveEvents(t, services.NewEventHub())
+	header := http.Header{"Origin": {"http://evil.example"}}
+	if conn, _, err := websocket.DefaultDialer.Dial(url, header); err == nil {
+		conn.Close()
+		t.Fatal("cross-origin WebSocket accepted")
+	}
+}
+
+func TestEventsHandler_TaskStreamSendsTaskChanges(t *testing.T) {
+	hub := services.NewEventHub()
+	app := fiber.New()
+	app.Get("/events", NewEventsHandler(hub).TaskStream)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Listener(ln)
+	defer app.Shutdown()
+	defer hub.Close() // ends the stream so Shutdown can finish
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	lines := bufio.NewScanner(resp.Body)
+	if !lines.Scan() || !strings.HasPrefix(lines.Text(), "retry:") {
+		t.Fatalf("first line %q", lines.Text())
+	}
+
+	// Only task registry changes are streamed.
+	hub.Publish(services.Event{Type: services.EventNoteCreated})
+	hub.Publish(services.Event{
+		Type:    services.EventTasksChanged,
+		Folder:  "/notes",
+		Changes: &services.TaskDiff{Added: []services.TaskChange{{ID: 7, Content: "- [ ] new"}}},
+	})
+	var got []string
+	for len(got) < 2 && lines.Scan() {
+		if lines.Text() != "" {
+			got = append(got, lines.Text())
+		}
+	}
+	if len(got) != 2 || got[0] != "event: tasks.changed" || !strings.Contains(got[1], `"added":[{"id":7,"content":"- [ ] new"}]`) {
+		t.Errorf("stream sent %q", got)
+	}
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/chrome"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/Xafloc/NoteFlow-Go/internal/themes"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExportZip streams the folder's notes and assets tree as a zip archive,
+// as 'noteflow export' writes it.
+// GET /api/export.zip
+func (h *NotesHandler) ExportZip(c *fiber.Ctx) error {
+	name := fmt.Sprintf("noteflow-%s-%s.zip", filepath.Base(h.noteManager.GetBasePath()), time.Now().Format("20060102-150405"))
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name))
+	nm := h.noteManager
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// Once streaming, the status is sent: a failure can only cut the
+		// archive short, which the client sees as a corrupt zip.
+		if _, err := nm.ExportZip(w, services.ExportOptions{Format: services.ExportFormatZip}); err != nil {
+			log.Printf("Warning: export.zip failed: %v", err)
+		}
+		w.Flush()
+	})
+	return nil
+}
+
+// ExportSite streams the notes as a static HTML site, zipped: index.html
+// in the ?theme= (default: the configured one) and the assets it links,
+// as 'noteflow export --format html' writes it. Unzipped, it can be
+// published as is, e.g. to GitHub Pages.
+// GET /api/export/site.zip?theme=light-blue
+func (h *NotesHandler) ExportSite(c *fiber.Ctx) error {
+	opts := services.ExportOptions{Format: services.ExportFormatHTML, Theme: c.Query("theme")}
+	if opts.Theme == "" && h.config != nil && themes.AvailableThemes[h.config.Theme] != nil {
+		opts.Theme = h.config.Theme
+	}
+	if err := services.ValidateExportOptions(opts); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	name := fmt.Sprintf("noteflow-%s-site-%s.zip", filepath.Base(h.noteManager.GetBasePath()), time.Now().Format("20060102-150405"))
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name))
+	nm := h.noteManager
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if _, err := nm.ExportSiteZip(w, opts); err != nil {
+			log.Printf("Warning: export/site.zip failed: %v", err)
+		}
+		w.Flush()
+	})
+	return nil
+}
+
+// ExportPDF prints the notes, or those with ?tag=, ?mention= and written
+// ?from= ?to= (YYYY-MM-DD), to one PDF with a table of contents, titled
+// ?title=. It needs Chrome or Chromium on the server: 503 without.
+// GET /api/export.pdf?tag=status&from=2026-10-01
+func (h *NotesHandler) ExportPDF(c *fiber.Ctx) error {
+	opts, err := documentOptions(c)
+	if err != nil {
+		return err
+	}
+	pdf, _, err := h.noteManager.ExportPDF(opts, time.Now())
+	if errors.Is(err, chrome.ErrNotFound) {
+		return fiber.NewError(fiber.StatusServiceUnavailable, err.Error())
+	}
+	if err != nil {
+		return documentError(err, "Failed to export PDF: ")
+	}
+	name := fmt.Sprintf("noteflow-%s-%s.pdf", filepath.Base(h.noteManager.GetBasePath()), time.Now().Format("20060102-150405"))
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name))
+	return c.Send(pdf)
+}
+
+// ExportEPUB packages the notes, or those ?tag=, ?mention=, ?from= and
+// ?to= select as for ExportPDF, into an EPUB book titled ?title=, one
+// chapter per note with its images embedded.
+// GET /api/export.epub?tag=essay
+func (h *NotesHandler) ExportEPUB(c *fiber.Ctx) error {
+	opts, err := documentOptions(c)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if _, err := h.noteManager.ExportEPUB(&buf, opts, time.Now()); err != nil {
+		return documentError(err, "Failed to export EPUB: ")
+	}
+	name := fmt.Sprintf("noteflow-%s-%s.epub", filepath.Base(h.noteManager.GetBasePath()), time.Now().Format("20060102-150405"))
+	c.Set(fiber.HeaderContentType, "application/epub+zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name))
+	return c.Send(buf.Bytes())
+}
+
+// documentOptions reads the note filters of ExportPDF and ExportEPUB.
+func documentOptions(c *fiber.Ctx) (services.DocumentOptions, error) {
+	opts := services.DocumentOptions{Title: c.Query("title"), From: c.Query("from"), To: c.Query("to")}
+	if tag := c.Query("tag"); tag != "" {
+		var ok bool
+		if opts.Tag, ok = models.NormalizeTagName(tag); !ok {
+			return opts, fiber.NewError(fiber.StatusBadRequest, "Invalid tag name")
+		}
+	}
+	if mention := c.Query("mention"); mention != "" {
+		var ok bool
+		if opts.Mention, ok = models.NormalizeMentionName(mention); !ok {
+			return opts, fiber.NewError(fiber.StatusBadRequest, "Invalid mention name")
+		}
+	}
+	return opts, nil
+}
+
+// documentError maps an error of ExportPDF or ExportEPUB to its status.
+func documentError(err error, prefix string) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidDocument):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrNoNotesToExport):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, prefix+err.Error())
+}
+
+// ExportNotesJSON returns the notes as a notes JSON document: each note's
+// title, timestamp, markdown, metadata and tasks, for scripts that would
+// rather not parse notes.md (docs/20261017_notes_json_schema.md).
+// GET /api/notes/export.json
+func (h *NotesHandler) ExportNotesJSON(c *fiber.Ctx) error {
+	name := fmt.Sprintf("noteflow-%s-notes-%s.json", filepath.Base(h.noteManager.GetBasePath()), time.Now().Format("20060102-150405"))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", name))
+	return c.JSON(h.noteManager.ExportNotesJSON(time.Now()))
+}
+
+// ImportNotesJSON adds the notes of a notes JSON document to the folder,
+// or with ?mode=restore replaces the notes with them.
+// POST /api/notes/import.json
+func (h *NotesHandler) ImportNotesJSON(c *fiber.Ctx) error {
+	mode := c.Query("mode")
+	if mode != "" && mode != services.ImportMerge && mode != services.ImportRestore {
+		return fiber.NewError(fiber.StatusBadRequest, "mode must be merge or restore")
+	}
+	var doc services.NotesJSON
+	if err := c.BodyParser(&doc); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid notes JSON: "+err.Error())
+	}
+	result, err := h.noteManager.ImportNotesJSON(&doc, mode, time.Now())
+	if errors.Is(err, services.ErrInvalidImport) {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return saveError(err, fiber.StatusInternalServerError, "Failed to import: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("%d note(s) added, %d skipped", result.Added, result.Skipped),
+		Data:    result,
+	})
+}
+
+// Import merges a zip archive made by GET /api/export.zip into the folder,
+// or with mode=restore replaces the notes with the archive's.
+// POST /api/import
+func (h *NotesHandler) Import(c *fiber.Ctx) error {
+	return h.importUpload(c, h.noteManager.ImportZip)
+}
+
+// ImportObsidian merges a zip of an Obsidian vault into the folder, or
+// with mode=restore replaces the notes with the vault's.
+// POST /api/import/obsidian
+func (h *NotesHandler) ImportObsidian(c *fiber.Ctx) error {
+	return h.importUpload(c, func(r io.ReaderAt, size int64, mode string) (*services.ImportResult, error) {
+		return h.noteManager.ImportObsidianZip(r, size, mode, time.Now())
+	})
+}
+
+// ImportKeep merges the Google Keep notes of a Takeout zip into the
+// folder, or with mode=restore replaces the notes with them.
+// POST /api/import/keep
+func (h *NotesHandler) ImportKeep(c *fiber.Ctx) error {
+	return h.importUpload(c, func(r io.ReaderAt, size int64, mode string) (*services.ImportResult, error) {
+		return h.noteManager.ImportKeepZip(r, size, mode, time.Now())
+	})
+}
+
+// ImportJoplin imports a Joplin export (.jex) into the folder, its
+// notebooks as folders under it, which are added to the task registry so
+// that their to-dos show among the tasks.
+// POST /api/import/joplin
+func (h *NotesHandler) ImportJoplin(c *fiber.Ctx) error {
+	return h.importUpload(c, func(r io.ReaderAt, size int64, mode string) (*services.ImportResult, error) {
+		result, err := h.noteManager.ImportJEX(io.NewSectionReader(r, 0, size), mode, time.Now())
+		if err != nil || h.taskRegistry == nil {
+			return result, err
+		}
+		for _, folder := range result.Folders {
+			if folder.Path == h.noteManager.GetBasePath() {
+				continue
+			}
+			if _, err := h.taskRegistry.AddFolderByPath(folder.Path); err != nil {
+				log.Printf("Warning: register imported folder %s: %v", folder.Path, err)
+			}
+		}
+		return result, nil
+	})
+}
+
+// importUpload imports the file uploaded as "file" with importFn, in the
+// mode given as a form field or query parameter.
+func (h *NotesHandler) importUpload(c *fiber.Ctx, importFn func(r io.ReaderAt, size int64, mode string) (*services.ImportResult, error)) error {
+	mode := c.FormValue("mode", c.Query("mode"))
+	if mode != "" && mode != services.ImportMerge && mode != services.ImportRestore {
+		return fiber.NewError(fiber.StatusBadRequest, "mode must be merge or restore")
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "No file provided")
+	}
+	f, err := file.Open()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to open file")
+	}
+	defer f.Close()
+
+	result, err := importFn(f, file.Size, mode)
+	if errors.Is(err, services.ErrInvalidImport) {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return saveError(err, fiber.StatusInternalServerError, "Failed to import: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("%d note(s) added, %d file(s) written", result.Added, result.Files),
+		Data:    result,
+	})
+}
@@ -1,27 +1,58 @@
 package handlers
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"html"
+	"io"
+	"net/url"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/Xafloc/NoteFlow-Go/internal/models"
 	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/Xafloc/NoteFlow-Go/internal/transcribe"
+	"github.com/Xafloc/NoteFlow-Go/internal/vision"
 	"github.com/gofiber/fiber/v2"
 )
 
 // FilesHandler handles file upload and management
 type FilesHandler struct {
 	noteManager *services.NoteManager
+	transcriber transcribe.Transcriber // optional; transcribes audio uploads
+	describer   vision.Describer       // optional; writes alt text for images
+	maxUpload   int64                  // bytes; see SetMaxUpload
 }
 
 // NewFilesHandler creates a new files handler
 func NewFilesHandler(noteManager *services.NoteManager) *FilesHandler {
 	return &FilesHandler{
 		noteManager: noteManager,
+		maxUpload:   models.DefaultUploadMB << 20,
 	}
 }
 
+// SetMaxUpload sets the largest file UploadFile accepts, in bytes.
+func (h *FilesHandler) SetMaxUpload(n int64) {
+	h.maxUpload = n
+}
+
+// SetTranscriber enables speech-to-text for audio uploads. Passing nil
+// disables it.
+func (h *FilesHandler) SetTranscriber(t transcribe.Transcriber) {
+	h.transcriber = t
+}
+
+// SetDescriber enables generated alt text for image uploads. Passing nil
+// disables it.
+func (h *FilesHandler) SetDescriber(d vision.Describer) {
+	h.describer = d
+}
+
 // UploadFile handles file uploads via drag-and-drop or form submission
 func (h *FilesHandler) UploadFile(c *fiber.Ctx) error {
 	file, err := c.FormFile("file")
@@ -29,6 +60,10 @@ func (h *FilesHandler) UploadFile(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "No file provided")
 	}
 
+	if file.Size > h.maxUpload {
+		return fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("File too large (max %dMB)", h.maxUpload>>20))
+	}
+
 	// Read file data
 	fileHeader, err := file.Open()
 	if err != nil {
@@ -38,16 +73,10 @@ func (h *FilesHandler) UploadFile(c *fiber.Ctx) error {
 
 	// Read file content
 	fileData := make([]byte, file.Size)
-	if _, err := fileHeader.Read(fileData); err != nil {
+	if _, err := io.ReadFull(fileHeader, fileData); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to read file")
 	}
 
-	// Validate file size (max 50MB)
-	maxSize := int64(50 * 1024 * 1024)
-	if file.Size > maxSize {
-		return fiber.NewError(fiber.StatusBadRequest, "File too large (max 50MB)")
-	}
-
 	// Validate file extension
 	ext := strings.ToLower(filepath.Ext(file.Filename))
 	allowedExts := map[string]bool{
@@ -57,7 +86,7 @@ func (h *FilesHandler) UploadFile(c *fiber.Ctx) error {
 		".json": true, ".xml": true, ".csv": true,
 	}
 
-	if !allowedExts[ext] {
+	if !allowedExts[ext] && !transcribe.IsAudio(file.Filename) {
 		return fiber.NewError(fiber.StatusBadRequest, "File type not allowed")
 	}
 
@@ -85,74 +114,245 @@ func (h *FilesHandler) UploadFile(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to save file: "+err.Error())
 	}
 
-	return c.JSON(map[string]interface{}{
-		"filePath":    filePath,
-		"isImage":     isImage,
-		"contentType": contentType,
-	})
+	isAudio := transcribe.IsAudio(file.Filename)
+	resp := models.UploadResult{
+		FilePath:    filePath,
+		IsImage:     isImage,
+		IsAudio:     isAudio,
+		ContentType: contentType,
+	}
+
+	// Voice notes: transcribe so the text lands in the note body (and so
+	// in search). A failed transcription never fails the upload itself.
+	transcript := ""
+	if h.transcriber != nil && isAudio {
+		diskPath := filepath.Join(h.noteManager.GetBasePath(), filepath.FromSlash(strings.TrimPrefix(filePath, "/")))
+		if text, err := h.transcriber.Transcribe(c.UserContext(), diskPath); err != nil {
+			resp.TranscriptError = err.Error()
+		} else {
+			transcript = text
+			resp.Transcript = text
+		}
+	}
+
+	// Images: generate alt text, same best-effort rules.
+	alt := file.Filename
+	if h.describer != nil && isImage {
+		if text, err := h.describer.Describe(c.UserContext(), fileData, contentType); err != nil {
+			resp.AltTextError = err.Error()
+		} else if text != "" {
+			alt = text
+			resp.AltText = text
+		}
+	}
+
+	resp.Markdown = uploadSnippet(filePath, alt, isAudio, transcript)
+	return c.JSON(resp)
+}
+
+// uploadSnippet is the markdown the editor inserts for an upload: an inline
+// player (plus transcript) for audio, an image/link reference otherwise.
+func uploadSnippet(filePath, alt string, isAudio bool, transcript string) string {
+	if isAudio {
+		block := `<audio controls src="` + html.EscapeString(filePath) + `"></audio>`
+		if transcript != "" {
+			block += "\n\n> **Transcript:** " + transcript
+		}
+		return block
+	}
+	return "![" + vision.CleanAltText(alt) + "](<" + filePath + ">)"
+}
+
+// archiveLabel names an archive in the links panel by its format.
+func archiveLabel(format string) string {
+	if format == "" || format == "html" {
+		return "site archive"
+	}
+	return format + " archive"
+}
+
+// readerLink links an archive's reader-mode copy, if it has one.
+func readerLink(path string) string {
+	if path == "" {
+		return ""
+	}
+	return ` <a href="/assets/sites/` + html.EscapeString(path) + `" target="_blank">reader</a>`
+}
+
+// archiveTagLinks renders an archive's comma-separated tags; clicking one
+// filters the links panel on it.
+func archiveTagLinks(tags string) string {
+	if tags == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, tag := range strings.Split(tags, ",") {
+		safeTag := html.EscapeString(tag)
+		b.WriteString(` <span class="archive-tag" data-tag="` + safeTag + `" onclick="updateLinks(this.dataset.tag)">#` + safeTag + `</span>`)
+	}
+	return b.String()
+}
+
+// hasArchiveTag reports whether an archive's comma-separated tags include
+// filter or a tag nested under it.
+func hasArchiveTag(tags, filter string) bool {
+	if tags == "" {
+		return false
+	}
+	for _, tag := range strings.Split(tags, ",") {
+		if models.TagMatches(tag, filter) {
+			return true
+		}
+	}
+	return false
 }
 
-// GetLinks returns information about archived links/sites
+// GetLinks returns information about archived links/sites, grouped by
+// domain and then by original URL, each URL's snapshots oldest first.
+// ?tag= limits the list to archives with that tag.
+// GET /api/links
 func (h *FilesHandler) GetLinks(c *fiber.Ctx) error {
 	linkGroups, err := h.noteManager.GetArchivedLinks()
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get links: "+err.Error())
 	}
 
+	tagFilter := c.Query("tag")
+	if tagFilter != "" {
+		var ok bool
+		if tagFilter, ok = models.NormalizeTagName(tagFilter); !ok {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid tag name")
+		}
+	}
+
 	// Generate HTML output (similar to Python version)
 	var htmlParts []string
 	var markdownParts []string
 
-	// Sort domains and build response
-	for domain, data := range linkGroups {
-		domainData := data.(map[string]interface{})
+	domains := make([]string, 0, len(linkGroups))
+	for domain := range linkGroups {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	if tagFilter == "" {
+		htmlParts = append(htmlParts,
+			`<div class="archive-filter"><span style="cursor:pointer;" onclick="archiveAllLinks()">archive links in notes</span></div>`)
+	} else {
+		htmlParts = append(htmlParts,
+			`<div class="archive-filter">#`+html.EscapeString(tagFilter)+
+				`<span style="cursor:pointer;font-size:0.5rem; margin-left:5px;" onclick="updateLinks()">clear</span></div>`)
+	}
+
+	for _, domain := range domains {
+		domainData := linkGroups[domain].(map[string]interface{})
 		archives := domainData["archives"].([]map[string]string)
+		if tagFilter != "" {
+			kept := archives[:0]
+			for _, archive := range archives {
+				if hasArchiveTag(archive["tags"], tagFilter) {
+					kept = append(kept, archive)
+				}
+			}
+			if archives = kept; len(archives) == 0 {
+				continue
+			}
+		}
+		// Filenames start with the capture time, so they sort
+		// chronologically.
+		sort.Slice(archives, func(i, j int) bool { return archives[i]["filename"] < archives[j]["filename"] })
+
+		// Snapshots of one URL go together; an archive that doesn't know
+		// its URL stands alone.
+		var urls []string
+		snapshots := make(map[string][]map[string]string)
+		for _, archive := range archives {
+			key := archive["url"]
+			if key == "" {
+				key = archive["filename"]
+			}
+			if _, ok := snapshots[key]; !ok {
+				urls = append(urls, key)
+			}
+			snapshots[key] = append(snapshots[key], archive)
+		}
 
 		htmlParts = append(htmlParts, `<div class="archived-link">`)
 		htmlParts = append(htmlParts, `<a href="#">`+domain+`</a>`)
 
-		for _, archive := range archives {
-			filename := archive["filename"]
-			timestamp := archive["timestamp"]
-
-			// Escape the filename for the HTML attribute. Critical for
-			// archives whose titles contained HTML entities — e.g. "It's
-			// FOSS" archived with a literal `&#x27;` in its filename.
-			// Without escaping, the browser decodes `&#x27;` back to `'`
-			// mid-attribute and the delete onclick JS string breaks.
-			// Using a data-filename attribute + this.dataset.filename in
-			// JS means the value is treated as a string, not as JS code.
-			safeFilename := html.EscapeString(filename)
-
-			htmlParts = append(htmlParts,
-				`<span class="archive-reference">`+
-					`<a href="/assets/sites/`+safeFilename+`" target="_blank">`+
-					`site archive [`+timestamp+`]</a>`+
-					`<span style="color:red;cursor:pointer;font-size:0.5rem; margin-left:5px;" `+
-					`data-filename="`+safeFilename+`" `+
-					`onclick="deleteArchive(this.dataset.filename)">delete</span>`+
-					`</span>`)
-
-			markdownParts = append(markdownParts,
-				`[`+domain+` - [`+timestamp+`]](/assets/sites/`+filename+`)`)
+		for _, key := range urls {
+			group := snapshots[key]
+			if pageURL := group[0]["url"]; pageURL != "" {
+				safeURL := html.EscapeString(pageURL)
+				newest := html.EscapeString(group[len(group)-1]["filename"])
+				htmlParts = append(htmlParts,
+					`<div class="archive-url">`+
+						`<a href="`+safeURL+`" target="_blank" title="`+safeURL+`">`+html.EscapeString(shortURL(pageURL))+`</a>`+
+						`<span style="cursor:pointer;font-size:0.5rem; margin-left:5px;" `+
+						`data-filename="`+newest+`" `+
+						`onclick="refreshArchive(this.dataset.filename)">refresh</span>`+
+						`</div>`)
+			}
+
+			for _, archive := range group {
+				filename := archive["filename"]
+				timestamp := archive["timestamp"]
+
+				// Escape the filename for the HTML attribute. Critical for
+				// archives whose titles contained HTML entities — e.g. "It's
+				// FOSS" archived with a literal `&#x27;` in its filename.
+				// Without escaping, the browser decodes `&#x27;` back to `'`
+				// mid-attribute and the delete onclick JS string breaks.
+				// Using a data-filename attribute + this.dataset.filename in
+				// JS means the value is treated as a string, not as JS code.
+				safeFilename := html.EscapeString(filename)
+
+				// The title and notes show as a tooltip
+				tooltip := archive["title"]
+				if notes := archive["notes"]; notes != "" {
+					tooltip = strings.TrimSpace(tooltip + "\n\n" + notes)
+				}
+
+				htmlParts = append(htmlParts,
+					`<span class="archive-reference">`+
+						`<a href="/assets/sites/`+safeFilename+`" target="_blank" title="`+html.EscapeString(tooltip)+`">`+
+						archiveLabel(archive["format"])+` [`+timestamp+`]</a>`+
+						readerLink(archive["reader"])+
+						archiveTagLinks(archive["tags"])+
+						`<span style="cursor:pointer;font-size:0.5rem; margin-left:5px;" `+
+						`data-filename="`+safeFilename+`" `+
+						`onclick="editArchive(this.dataset.filename)">edit</span>`+
+						`<span style="color:red;cursor:pointer;font-size:0.5rem; margin-left:5px;" `+
+						`data-filename="`+safeFilename+`" `+
+						`onclick="deleteArchive(this.dataset.filename)">delete</span>`+
+						`</span>`)
+
+				markdownParts = append(markdownParts,
+					`[`+domain+` - [`+timestamp+`]](/assets/sites/`+filename+`)`)
+			}
 		}
 
 		htmlParts = append(htmlParts, `</div>`)
 	}
 
-	result := map[string]interface{}{
-		"html":     strings.Join(htmlParts, "\n"),
-		"markdown": strings.Join(markdownParts, "\n"),
-	}
+	return c.JSON(models.LinksView{
+		HTML:     strings.Join(htmlParts, "\n"),
+		Markdown: strings.Join(markdownParts, "\n"),
+	})
+}
 
-	return c.JSON(result)
+// shortURL drops the scheme from a URL for display.
+func shortURL(u string) string {
+	u = strings.TrimPrefix(strings.TrimPrefix(u, "https://"), "http://")
+	if len(u) > 60 {
+		u = u[:57] + "..."
+	}
+	return u
 }
 
 // DeleteArchive deletes an archived website file
 func (h *FilesHandler) DeleteArchive(c *fiber.Ctx) error {
-	var req struct {
-		Filename string `json:"filename"`
-	}
+	var req models.ArchiveDeleteRequest
 
 	if err := c.BodyParser(&req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
@@ -169,4 +369,163 @@ func (h *FilesHandler) DeleteArchive(c *fiber.Ctx) error {
 	return c.JSON(models.APIResponse{
 		Status: "success",
 	})
-}
\ No newline at end of file
+}
+
+// ArchiveStatus reports queued, running and recently finished +URL
+// archives. The page polls it while any are pending and reloads the notes
+// once they are done.
+// GET /api/archives/status
+func (h *FilesHandler) ArchiveStatus(c *fiber.Ctx) error {
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   h.noteManager.ArchiveStatus(),
+	})
+}
+
+// RefreshArchive captures a new snapshot of an archived page's original
+// URL, keeping the existing snapshots.
+// POST /api/archives/:filename/refresh
+func (h *FilesHandler) RefreshArchive(c *fiber.Ctx) error {
+	filename, err := archiveFilename(c)
+	if err != nil {
+		return err
+	}
+	info, err := h.noteManager.RefreshArchive(filename)
+	switch {
+	case errors.Is(err, services.ErrArchiveNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrArchiveURLUnknown):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	case err != nil:
+		return fiber.NewError(fiber.StatusBadGateway, "Failed to archive: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data: models.ArchiveSnapshot{
+			Title:      info.Title,
+			FilePath:   info.FilePath,
+			ReaderPath: info.ReaderPath,
+			Archived:   info.Timestamp,
+		},
+	})
+}
+
+// archiveFilename reads the :filename route parameter.
+func archiveFilename(c *fiber.Ctx) (string, error) {
+	filename, err := url.PathUnescape(c.Params("filename"))
+	if err != nil || filename == "" {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Invalid archive name")
+	}
+	return filename, nil
+}
+
+// GetArchiveMeta returns an archive's original URL, capture time, format,
+// title, notes and tags.
+// GET /api/archives/:filename/meta
+func (h *FilesHandler) GetArchiveMeta(c *fiber.Ctx) error {
+	filename, err := archiveFilename(c)
+	if err != nil {
+		return err
+	}
+	meta, err := h.noteManager.ArchiveMeta(filename)
+	if errors.Is(err, services.ErrArchiveNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to read archive metadata: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   meta,
+	})
+}
+
+// UpdateArchiveMeta sets an archive's title, notes or tags; fields left
+// out of the body are unchanged.
+// PUT /api/archives/:filename/meta  {"title": "...", "notes": "...", "tags": ["go", "#reading"]}
+func (h *FilesHandler) UpdateArchiveMeta(c *fiber.Ctx) error {
+	filename, err := archiveFilename(c)
+	if err != nil {
+		return err
+	}
+	var req services.ArchiveMetaUpdate
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
+	}
+	if req.Tags != nil {
+		tags := make([]string, 0, len(*req.Tags))
+		for _, t := range *req.Tags {
+			tag, ok := models.NormalizeTagName(t)
+			if !ok {
+				return fiber.NewError(fiber.StatusBadRequest, "Invalid tag name: "+t)
+			}
+			tags = append(tags, tag)
+		}
+		req.Tags = &tags
+	}
+
+	meta, err := h.noteManager.UpdateArchiveMeta(filename, req)
+	if errors.Is(err, services.ErrArchiveNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to save archive metadata: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   meta,
+	})
+}
+
+// ExternalLinks lists the http(s) links in the notes that have no archive
+// yet, i.e. what BulkArchive would archive.
+// GET /api/archives/links
+func (h *FilesHandler) ExternalLinks(c *fiber.Ctx) error {
+	links, err := h.noteManager.ExternalLinks()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to scan links: "+err.Error())
+	}
+	if links == nil {
+		links = []services.ExternalLink{}
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   links,
+	})
+}
+
+// BulkArchive archives every link ExternalLinks lists and annotates the
+// notes, streaming progress as server-sent events: a "start" event with
+// the total, a "link" event per URL and a final "done" (or "error")
+// event, each carrying a services.BulkArchiveEvent as JSON. Closing the
+// connection stops the run after the pages in flight.
+// POST /api/archives/bulk  {"concurrency": 2, "refetch": false}
+func (h *FilesHandler) BulkArchive(c *fiber.Ctx) error {
+	var opts services.BulkArchiveOptions
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&opts); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	nm := h.noteManager
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		send := func(event services.BulkArchiveEvent) {
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			// A failed flush means the client went away
+			if err := w.Flush(); err != nil {
+				cancel()
+			}
+		}
+		if _, err := nm.BulkArchive(ctx, opts, send); err != nil && !errors.Is(err, context.Canceled) {
+			send(services.BulkArchiveEvent{Type: "error", Error: err.Error()})
+		}
+	})
+	return nil
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeTranscriber returns a fixed transcript and records what it was given.
+type fakeTranscriber struct{ path string }
+
+func (f *fakeTranscriber) Transcribe(_ context.Context, path string) (string, error) {
+	f.path = path
+	return "pick up the dry cleaning", nil
+}
+
+func TestFilesHandler_UploadAudioIsTranscribed(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := services.NewNoteManager(dir)
+	if err != nil {
+		t.Fatalf("NewNoteManager: %v", err)
+	}
+	h := NewFilesHandler(mgr)
+	fake := &fakeTranscriber{}
+	h.SetTranscriber(fake)
+	app := fiber.New()
+	app.Post("/upload-file", h.UploadFile)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile("file", "memo.m4a")
+	part.Write([]byte("fake audio"))
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/upload-file", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (audio must be an allowed upload)", resp.StatusCode)
+	}
+	var out map[string]any
+	json.NewDecoder(resp.Body).Decode(&out)
+	wantMD := "<audio controls src=\"/assets/files/memo.m4a\"></audio>\n\n> **Transcript:** pick up the dry cleaning"
+	if out["isAudio"] != true || out["transcript"] != "pick up the dry cleaning" || out["markdown"] != wantMD {
+		t.Errorf("response = %v", out)
+	}
+	if want := filepath.Join(dir, "assets", "files", "memo.m4a"); fake.path != want {
+		t.Errorf("transcribed %q, want %q", fake.path, want)
+	}
+	if _, err := os.Stat(fake.path); err != nil {
+		t.Errorf("uploaded file missing: %v", err)
+	}
+}
+
+// fakeDescriber returns fixed alt text.
+type fakeDescriber struct{ mime string }
+
+func (f *fakeDescriber) Describe(_ context.Context, _ []byte, mimeType string) (string, error) {
+	f.mime = mimeType
+	return "A whiteboard sketch of the [sync] flow", nil
+}
+
+func TestFilesHandler_UploadImageGetsAltText(t *testing.T) {
+	mgr, err := services.NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewNoteManager: %v", err)
+	}
+	h := NewFilesHandler(mgr)
+	fake := &fakeDescriber{}
+	h.SetDescriber(fake)
+	app := fiber.New()
+	app.Post("/upload-file", h.UploadFile)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	hdr := make(textproto.MIMEHeader)
+	hdr.Set("Content-Disposition", `form-data; name="file"; filename="board.png"`)
+	hdr.Set("Content-Type", "image/png")
+	part, _ := mw.CreatePart(hdr)
+	part.Write([]byte("\x89PNG"))
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/upload-file", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	var out map[string]any
+	json.NewDecoder(resp.Body).Decode(&out)
+	// Brackets in model output must not break the markdown image syntax.
+	if want := "![A whiteboard sketch of the (sync) flow](</assets/images/board.png>)"; out["markdown"] != want {
+		t.Errorf("markdown = %v, want %q", out["markdown"], want)
+	}
+	if fake.mime != "image/png" {
+		t.Errorf("describer got mime %q", fake.mime)
+	}
+}
+
+func TestFilesHandler_GetLinksGroupsSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := services.NewNoteManager(dir)
+	if err != nil {
+		t.Fatalf("NewNoteManager: %v", err)
+	}
+	sites := filepath.Join(dir, "assets", "sites")
+	banner := func(u string) []byte {
+		return []byte("<!-- ARCHIVED PAGE - Original URL: " + u + " - Archived: 2026-10-16 09:00:00 -->")
+	}
+	os.WriteFile(filepath.Join(sites, "2026_10_16_090000_A-example.com.html"), banner("https://example.com/a"), 0644)
+	os.WriteFile(filepath.Join(sites, "2026_10_14_090000_B-example.com.html"), banner("https://example.com/b"), 0644)
+	os.WriteFile(filepath.Join(sites, "2026_10_12_090000_A-example.com.html"), banner("https://example.com/a"), 0644)
+
+	app := fiber.New()
+	app.Get("/links", NewFilesHandler(mgr).GetLinks)
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/links", nil))
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	var out struct {
+		HTML string `json:"html"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	// URL a's two snapshots sit together, oldest first, before URL b
+	// (whose only snapshot falls between them in time); refresh re-captures
+	// from the newest.
+	order := []string{">example.com/a<", "site archive [2026_10_12]", "site archive [2026_10_16]", ">example.com/b<", "site archive [2026_10_14]"}
+	last := -1
+	for _, s := range order {
+		i := bytes.Index([]byte(out.HTML), []byte(s))
+		if i <= last {
+			t.Fatalf("%q out of order in:\n%s", s, out.HTML)
+		}
+		last = i
+	}
+	if !bytes.Contains([]byte(out.HTML), []byte(`data-filename="2026_10_16_090000_A-example.com.html" onclick="refreshArchive`)) {
+		t.Errorf("refresh should use the newest snapshot:\n%s", out.HTML)
+	}
+}
+
+func TestFilesHandler_ArchiveMetaAndTagFilter(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := services.NewNoteManager(dir)
+	if err != nil {
+		t.Fatalf("NewNoteManager: %v", err)
+	}
+	sites := filepath.Join(dir, "assets", "sites")
+	os.WriteFile(filepath.Join(sites, "2026_10_16_090000_Go-go.dev.html"), []byte("<html></html>"), 0644)
+	os.WriteFile(filepath.Join(sites, "2026_10_16_090000_News-example.com.html"), []byte("<html></html>"), 0644)
+
+	h := NewFilesHandler(mgr)
+	app := fiber.New()
+	app.Get("/links", h.GetLinks)
+	app.Get("/archives/:filename/meta", h.GetArchiveMeta)
+	app.Put("/archives/:filename/meta", h.UpdateArchiveMeta)
+
+	put := func(filename, body string) *http.Response {
+		req := httptest.NewRequest(http.MethodPut, "/archives/"+filename+"/meta", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Test: %v", err)
+		}
+		return resp
+	}
+	if resp := put("2026_10_16_090000_Go-go.dev.html", `{"title":"Go home","tags":["#lang/go","reading","reading"]}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("PUT status = %d", resp.StatusCode)
+	}
+	if resp := put("2026_10_16_090000_Go-go.dev.html", `{"notes":"start here"}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("PUT status = %d", resp.StatusCode)
+	}
+	if resp := put("2026_10_16_090000_Go-go.dev.html", `{"tags":["not a tag"]}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid tag status = %d, want 400", resp.StatusCode)
+	}
+	if resp := put("missing.html", `{"notes":"x"}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("missing archive status = %d, want 404", resp.StatusCode)
+	}
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/archives/2026_10_16_090000_Go-go.dev.html/meta", nil))
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	var got struct {
+		Data struct {
+			Title string   `json:"title"`
+			Notes string   `json:"notes"`
+			Tags  []string `json:"tags"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&got)
+	// A later update leaves the fields it doesn't name alone.
+	if got.Data.Title != "Go home" || got.Data.Notes != "start here" || len(got.Data.Tags) != 2 || got.Data.Tags[0] != "lang/go" {
+		t.Errorf("meta = %+v", got.Data)
+	}
+
+	// Filtering on a parent tag finds nested tags.
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/links?tag=lang", nil))
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	var links struct {
+		HTML string `json:"html"`
+	}
+	json.NewDecoder(resp.Body).Decode(&links)
+	if !bytes.Contains([]byte(links.HTML), []byte("go.dev")) || bytes.Contains([]byte(links.HTML), []byte("example.com")) {
+		t.Errorf("tag filter html:\n%s", links.HTML)
+	}
+	if !bytes.Contains([]byte(links.HTML), []byte("#lang/go")) {
+		t.Errorf("tags not shown:\n%s", links.HTML)
+	}
+}
+
+func TestFilesHandler_BulkArchiveStreamsProgress(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := services.NewNoteManager(dir)
+	if err != nil {
+		t.Fatalf("NewNoteManager: %v", err)
+	}
+	// An existing snapshot is linked without fetching the page.
+	os.WriteFile(filepath.Join(dir, "assets", "sites", "2026_10_15_080000_Kept-kept.example.html"),
+		[]byte("<!-- ARCHIVED PAGE - Original URL: https://kept.example/ - Archived: 2026-10-15 08:00:00 -->"), 0644)
+	if err := mgr.AddNote("", "read https://kept.example/"); err != nil {
+		t.Fatalf("AddNote: %v", err)
+	}
+
+	h := NewFilesHandler(mgr)
+	app := fiber.New()
+	app.Post("/bulk", h.BulkArchive)
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/bulk", nil), -1)
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	for _, want := range []string{
+		"event: start\ndata: {\"type\":\"start\",\"total\":1,",
+		"event: link\ndata: {\"type\":\"link\",\"total\":1,\"done\":1,\"failed\":0,\"url\":\"https://kept.example/\"",
+		"event: done\n",
+	} {
+		if !bytes.Contains(body, []byte(want)) {
+			t.Errorf("stream lacks %q:\n%s", want, body)
+		}
+	}
+	note, _ := mgr.GetNote(0)
+	if !bytes.Contains([]byte(note.Content), []byte("([archived 2026-10-15 08:00](assets/sites/2026_10_15_080000_Kept-kept.example.html))")) {
+		t.Errorf("note not annotated: %q", note.Content)
+	}
+}
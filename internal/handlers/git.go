@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"github.com/Xafloc/NoteFlow-Go/internal/git"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultGitLogLimit is how many commits GET /api/git/log returns without
+// a limit.
+const defaultGitLogLimit = 20
+
+// GitHandler exposes the folder's git history and sync.
+type GitHandler struct {
+	git *services.GitSyncService
+}
+
+// NewGitHandler creates a new git handler
+func NewGitHandler(git *services.GitSyncService) *GitHandler {
+	return &GitHandler{git: git}
+}
+
+// Log lists recent commits of the notes, newest first.
+// GET /api/git/log?limit=20
+func (h *GitHandler) Log(c *fiber.Ctx) error {
+	if !h.git.Enabled() {
+		return fiber.NewError(fiber.StatusBadRequest, services.ErrGitNotConfigured.Error())
+	}
+	limit := c.QueryInt("limit", defaultGitLogLimit)
+	if limit <= 0 || limit > 1000 {
+		return fiber.NewError(fiber.StatusBadRequest, "limit must be between 1 and 1000")
+	}
+	commits, err := h.git.Log(c.UserContext(), limit)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	if commits == nil {
+		commits = []git.Commit{}
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   commits,
+	})
+}
+
+// Pull commits outstanding changes, then rebases onto the remote branch
+// and reloads the notes.
+// POST /api/git/pull
+func (h *GitHandler) Pull(c *fiber.Ctx) error {
+	if !h.git.Enabled() {
+		return fiber.NewError(fiber.StatusBadRequest, services.ErrGitNotConfigured.Error())
+	}
+	if err := h.git.Pull(c.UserContext()); err != nil {
+		return fiber.NewError(fiber.StatusBadGateway, "git pull failed: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: "Pulled",
+	})
+}
+
+// Push commits outstanding changes and pushes them to the remote branch.
+// POST /api/git/push
+func (h *GitHandler) Push(c *fiber.Ctx) error {
+	if !h.git.Enabled() {
+		return fiber.NewError(fiber.StatusBadRequest, services.ErrGitNotConfigured.Error())
+	}
+	if err := h.git.Push(c.UserContext()); err != nil {
+		return fiber.NewError(fiber.StatusBadGateway, "git push failed: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: "Pushed",
+	})
+}
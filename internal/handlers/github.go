@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GitHubHandler exposes the GitHub issue integration.
+type GitHubHandler struct {
+	github *services.GitHubService
+}
+
+// NewGitHubHandler creates a new GitHub handler
+func NewGitHubHandler(github *services.GitHubService) *GitHubHandler {
+	return &GitHubHandler{github: github}
+}
+
+// ExportTasks creates GitHub issues from the selected tasks and writes each
+// issue URL back into its task line.
+// POST /api/github/export  {"tasks": [0, 3, 4]}
+func (h *GitHubHandler) ExportTasks(c *fiber.Ctx) error {
+	var req models.GitHubExportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
+	}
+	if len(req.Tasks) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "No tasks selected")
+	}
+	if !h.github.Enabled() {
+		return fiber.NewError(fiber.StatusBadRequest, "No GitHub repo configured for this folder (set github.repo in "+models.FolderConfigFile+")")
+	}
+
+	results, err := h.github.ExportTasks(c.UserContext(), req.Tasks)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadGateway, "GitHub export failed: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   results,
+	})
+}
+
+// ImportIssues runs the assigned-issue import immediately instead of
+// waiting for the next background refresh.
+// POST /api/github/import
+func (h *GitHubHandler) ImportIssues(c *fiber.Ctx) error {
+	if !h.github.ImportEnabled() {
+		return fiber.NewError(fiber.StatusBadRequest, "No import repos configured for this folder (set github.import in "+models.FolderConfigFile+")")
+	}
+	if err := h.github.ImportAssignedIssues(c.UserContext()); err != nil {
+		return fiber.NewError(fiber.StatusBadGateway, "GitHub import failed: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+	})
+}
@@ -1,7 +1,14 @@
 package handlers
 
 import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Xafloc/NoteFlow-Go/internal/models"
 	"github.com/Xafloc/NoteFlow-Go/internal/services"
@@ -20,10 +27,26 @@ func NewGlobalTasksHandler(taskRegistry *services.TaskRegistryService) *GlobalTa
 	}
 }
 
-// GetGlobalTasks returns all tasks across all registered folders
-// GET /api/global-tasks
+// GetGlobalTasks returns tasks across all registered folders, each with
+// its due date and an overdue flag. Filters, all optional and combinable:
+// ?folder= (ID or path), ?completed=true|false, ?q= (text search),
+// ?due=today|week|overdue|none|YYYY-MM-DD and ?due_from=/?due_to=
+// (YYYY-MM-DD, inclusive). ?sort=due|updated|text|folder, "-" prefixed for
+// descending, and ?limit=/?offset= page the result; "total" counts every
+// match.
+// GET /api/global-tasks?due=week&sort=due&limit=50
 func (gth *GlobalTasksHandler) GetGlobalTasks(c *fiber.Ctx) error {
-	globalTasks, err := gth.taskRegistry.GetGlobalTasks()
+	q, err := globalTaskQuery(c)
+	var globalTasks *models.GlobalTasksResponse
+	if err == nil {
+		globalTasks, err = gth.taskRegistry.QueryGlobalTasks(q, time.Now())
+	}
+	if errors.Is(err, services.ErrInvalidTaskQuery) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
 			Status:  "error",
@@ -37,6 +60,103 @@ func (gth *GlobalTasksHandler) GetGlobalTasks(c *fiber.Ctx) error {
 	})
 }
 
+// GetTaskHistory returns tasks added, completed, reopened and removed per
+// ?by=day|week from ?from= to ?to= (YYYY-MM-DD), with the open count at
+// the end of each and the completion streak, for ?folder= (ID or path)
+// and ?group= or every folder.
+// GET /api/global-tasks/history?by=week&from=2026-07-01
+func (gth *GlobalTasksHandler) GetTaskHistory(c *fiber.Ctx) error {
+	history, err := gth.taskRegistry.TaskHistory(services.TaskHistoryQuery{
+		Folder: c.Query("folder"),
+		Group:  c.Query("group"),
+		By:     c.Query("by"),
+		From:   c.Query("from"),
+		To:     c.Query("to"),
+	}, time.Now())
+	if errors.Is(err, services.ErrInvalidTaskQuery) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Failed to get task history: " + err.Error(),
+		})
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   history,
+	})
+}
+
+// globalTaskQuery reads GetGlobalTasks' query parameters.
+func globalTaskQuery(c *fiber.Ctx) (services.GlobalTaskQuery, error) {
+	q := services.GlobalTaskQuery{
+		Folder:  c.Query("folder"),
+		Group:   c.Query("group"),
+		Search:  c.Query("q"),
+		Due:     c.Query("due"),
+		DueFrom: c.Query("due_from"),
+		DueTo:   c.Query("due_to"),
+		Sort:    c.Query("sort"),
+	}
+	if v := c.Query("completed"); v != "" {
+		completed, err := strconv.ParseBool(v)
+		if err != nil {
+			return q, fmt.Errorf("%w: completed must be true or false", services.ErrInvalidTaskQuery)
+		}
+		q.Completed = &completed
+	}
+	for name, dst := range map[string]*int{"limit": &q.Limit, "offset": &q.Offset} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return q, fmt.Errorf("%w: %s must be a number", services.ErrInvalidTaskQuery, name)
+			}
+			*dst = n
+		}
+	}
+	return q, nil
+}
+
+// ExportTasks downloads the tasks GetGlobalTasks' filters select, all of
+// them rather than a page, as CSV, or TSV at export.tsv, for spreadsheets:
+// folder, note, text, state, priority, due date, tags, and when each task
+// was created, last changed and completed.
+// GET /api/global-tasks/export.csv?completed=false
+func (gth *GlobalTasksHandler) ExportTasks(c *fiber.Ctx) error {
+	q, err := globalTaskQuery(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return sendTaskExport(c, gth.taskRegistry, q, "noteflow-tasks")
+}
+
+// sendTaskExport responds with the tasks q selects as CSV, or as TSV for
+// a path ending in .tsv, in a file named after name.
+func sendTaskExport(c *fiber.Ctx, trs *services.TaskRegistryService, q services.GlobalTaskQuery, name string) error {
+	rows, err := trs.ExportTasks(q, time.Now())
+	if errors.Is(err, services.ErrInvalidTaskQuery) {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to export tasks: "+err.Error())
+	}
+	comma, ext, contentType := ',', "csv", "text/csv; charset=utf-8"
+	if strings.HasSuffix(c.Path(), ".tsv") {
+		comma, ext, contentType = '\t', "tsv", "text/tab-separated-values; charset=utf-8"
+	}
+	var buf bytes.Buffer
+	if err := services.WriteTaskRows(&buf, rows, comma); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to write tasks: "+err.Error())
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name+"."+ext))
+	return c.Send(buf.Bytes())
+}
+
 // UpdateGlobalTask updates the completion status of a global task
 // POST /api/global-tasks/:id/toggle
 func (gth *GlobalTasksHandler) UpdateGlobalTask(c *fiber.Ctx) error {
@@ -49,9 +169,7 @@ func (gth *GlobalTasksHandler) UpdateGlobalTask(c *fiber.Ctx) error {
 		})
 	}
 
-	var req struct {
-		Completed bool `json:"completed"`
-	}
+	var req models.GlobalTaskToggle
 
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
@@ -61,6 +179,18 @@ func (gth *GlobalTasksHandler) UpdateGlobalTask(c *fiber.Ctx) error {
 	}
 
 	err = gth.taskRegistry.UpdateGlobalTaskCompletion(taskID, req.Completed)
+	if errors.Is(err, services.ErrGlobalTaskNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(models.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
+	if errors.Is(err, services.ErrTaskNotInNotes) {
+		return c.Status(fiber.StatusConflict).JSON(models.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
 			Status:  "error",
@@ -74,10 +204,41 @@ func (gth *GlobalTasksHandler) UpdateGlobalTask(c *fiber.Ctx) error {
 	})
 }
 
-// GetActiveFolders returns all active registered folders
+// GetTaskSource resolves a global task to its folder, note and line so the
+// global task list can link to it.
+// GET /api/global-tasks/:id/source
+func (gth *GlobalTasksHandler) GetTaskSource(c *fiber.Ctx) error {
+	taskID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Invalid task ID",
+		})
+	}
+	src, err := gth.taskRegistry.TaskSource(taskID)
+	if errors.Is(err, services.ErrGlobalTaskNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(models.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Failed to resolve task: " + err.Error(),
+		})
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   src,
+	})
+}
+
+// GetActiveFolders returns all active registered folders, and the
+// forgotten ones after them with ?inactive=true
 // GET /api/global-folders
 func (gth *GlobalTasksHandler) GetActiveFolders(c *fiber.Ctx) error {
-	folders, err := gth.taskRegistry.GetActiveFolders()
+	folders, err := gth.taskRegistry.GetFolders(c.QueryBool("inactive"))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
 			Status:  "error",
@@ -114,9 +275,7 @@ func (gth *GlobalTasksHandler) ForceSync(c *fiber.Ctx) error {
 // they've moved/copied/created outside the normal flow.
 // POST /api/global-folders/add  {"path": "/abs/or/relative"}
 func (gth *GlobalTasksHandler) AddFolder(c *fiber.Ctx) error {
-	var req struct {
-		Path string `json:"path"`
-	}
+	var req models.FolderAddRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
 			Status:  "error",
@@ -142,6 +301,30 @@ func (gth *GlobalTasksHandler) AddFolder(c *fiber.Ctx) error {
 	})
 }
 
+// DiscoverFolders registers every notes folder under a root directory
+// that isn't registered yet; see TaskRegistryService.DiscoverFolders.
+// POST /api/global-folders/discover  {"root": "/home/ana/code", "ignore": ["archive"], "dry_run": true}
+func (gth *GlobalTasksHandler) DiscoverFolders(c *fiber.Ctx) error {
+	var req models.FolderDiscoverRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Invalid request body",
+		})
+	}
+	result, err := gth.taskRegistry.DiscoverFolders(req.Root, req.Ignore, req.DryRun)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   result,
+	})
+}
+
 // ForgetFolder soft-removes a folder from active tracking. The row stays
 // in the DB with active=0 (audit trail); re-adding the same path later
 // resurrects the same id with its history intact.
@@ -155,7 +338,7 @@ func (gth *GlobalTasksHandler) ForgetFolder(c *fiber.Ctx) error {
 		})
 	}
 	if err := gth.taskRegistry.ForgetFolder(folderID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+		return c.Status(folderErrorStatus(err)).JSON(models.APIResponse{
 			Status:  "error",
 			Message: err.Error(),
 		})
@@ -166,6 +349,105 @@ func (gth *GlobalTasksHandler) ForgetFolder(c *fiber.Ctx) error {
 	})
 }
 
+// ReactivateFolder brings a forgotten folder back into the global task
+// view with its old id, alias and group.
+// POST /api/global-folders/:id/reactivate
+func (gth *GlobalTasksHandler) ReactivateFolder(c *fiber.Ctx) error {
+	folderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Invalid folder ID",
+		})
+	}
+	folder, err := gth.taskRegistry.ReactivateFolder(folderID)
+	if err != nil {
+		status := folderErrorStatus(err)
+		if status == fiber.StatusInternalServerError {
+			// The folder's notes are gone or out of reach.
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(models.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: "Folder reactivated",
+		Data:    folder,
+	})
+}
+
+// DeleteFolder removes a folder and its tasks from the registry outright,
+// leaving no inactive row behind. Nothing on disk is touched.
+// DELETE /api/global-folders/:id
+func (gth *GlobalTasksHandler) DeleteFolder(c *fiber.Ctx) error {
+	folderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Invalid folder ID",
+		})
+	}
+	if err := gth.taskRegistry.DeleteFolder(folderID); err != nil {
+		return c.Status(folderErrorStatus(err)).JSON(models.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: "Folder deleted from the registry",
+	})
+}
+
+// folderErrorStatus is the status for an error from a per-folder action:
+// 404 for a folder that isn't registered (or is another user's).
+func folderErrorStatus(err error) int {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fiber.StatusNotFound
+	}
+	return fiber.StatusInternalServerError
+}
+
+// SetFolderLabels sets the alias a folder is shown under and the group it
+// is listed in.
+// PUT /api/global-folders/:id/labels
+func (gth *GlobalTasksHandler) SetFolderLabels(c *fiber.Ctx) error {
+	folderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Invalid folder ID",
+		})
+	}
+	var req models.FolderLabelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Invalid request body",
+		})
+	}
+	folder, err := gth.taskRegistry.SetFolderLabels(folderID, req.Alias, req.Group)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Folder not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   folder,
+	})
+}
+
 // SyncFolder re-syncs a single folder's notes.md. Useful when the user has
 // edited the file externally and wants the global view to catch up without
 // waiting for the 30s background tick.
@@ -188,4 +470,173 @@ func (gth *GlobalTasksHandler) SyncFolder(c *fiber.Ctx) error {
 		Status:  "success",
 		Message: "Folder synced",
 	})
-}
\ No newline at end of file
+}
+
+// ExportRegistry downloads the registry, folders, tasks and saved views,
+// as JSON for ImportRegistry on another machine.
+// GET /api/global-registry/export
+func (gth *GlobalTasksHandler) ExportRegistry(c *fiber.Ctx) error {
+	export, err := gth.taskRegistry.ExportRegistry(time.Now())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Failed to export registry: " + err.Error(),
+		})
+	}
+	c.Set("Content-Disposition", `attachment; filename="noteflow-registry.json"`)
+	return c.JSON(export)
+}
+
+// ImportRegistry merges a registry from ExportRegistry into this one,
+// moving folders under each ?rewrite=OLD=NEW (repeatable) first.
+// POST /api/global-registry/import?rewrite=/Users/ana=/home/ana
+func (gth *GlobalTasksHandler) ImportRegistry(c *fiber.Ctx) error {
+	var rewrites []services.PathRewrite
+	for _, v := range c.Context().QueryArgs().PeekMulti("rewrite") {
+		r, err := services.ParsePathRewrite(string(v))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+				Status:  "error",
+				Message: err.Error(),
+			})
+		}
+		rewrites = append(rewrites, r)
+	}
+	var export services.RegistryExport
+	if err := json.Unmarshal(c.Body(), &export); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Invalid registry export: " + err.Error(),
+		})
+	}
+	result, err := gth.taskRegistry.ImportRegistry(&export, rewrites)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   result,
+	})
+}
+
+// FindDuplicates lists open tasks that appear, word for word or nearly, in
+// two or more folders; ?threshold= (0-1, default 0.8) is how many of their
+// words near-duplicates must share.
+// GET /api/global-tasks/duplicates?threshold=0.7
+func (gth *GlobalTasksHandler) FindDuplicates(c *fiber.Ctx) error {
+	threshold := services.DefaultDuplicateThreshold
+	if v := c.Query("threshold"); v != "" {
+		var err error
+		if threshold, err = strconv.ParseFloat(v, 64); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+				Status:  "error",
+				Message: "threshold must be a number",
+			})
+		}
+	}
+	groups, err := gth.taskRegistry.FindDuplicateTasks(threshold)
+	if errors.Is(err, services.ErrInvalidTaskQuery) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Failed to find duplicates: " + err.Error(),
+		})
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   groups,
+	})
+}
+
+// taskLinkErrorStatus is the status for an error from linking or
+// deduping tasks.
+func taskLinkErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidLink):
+		return fiber.StatusBadRequest
+	case errors.Is(err, services.ErrGlobalTaskNotFound), errors.Is(err, sql.ErrNoRows):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrTaskNotInNotes):
+		return fiber.StatusConflict
+	}
+	return fiber.StatusInternalServerError
+}
+
+// LinkTasks links tasks, typically duplicates, so that completing or
+// reopening one does the same to the others.
+// POST /api/global-tasks/links
+func (gth *GlobalTasksHandler) LinkTasks(c *fiber.Ctx) error {
+	var req models.TaskLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Invalid request body",
+		})
+	}
+	linkID, err := gth.taskRegistry.LinkTasks(req.Tasks)
+	if err != nil {
+		return c.Status(taskLinkErrorStatus(err)).JSON(models.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   models.TaskLinkResponse{LinkID: linkID},
+	})
+}
+
+// UnlinkTasks dissolves a link; the tasks stay as they are.
+// DELETE /api/global-tasks/links/:id
+func (gth *GlobalTasksHandler) UnlinkTasks(c *fiber.Ctx) error {
+	linkID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Invalid link ID",
+		})
+	}
+	if err := gth.taskRegistry.UnlinkTasks(linkID); err != nil {
+		return c.Status(taskLinkErrorStatus(err)).JSON(models.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: "Tasks unlinked",
+	})
+}
+
+// DedupeTasks keeps one of a set of duplicates and deletes the rest from
+// their notes.
+// POST /api/global-tasks/duplicates/dedupe
+func (gth *GlobalTasksHandler) DedupeTasks(c *fiber.Ctx) error {
+	var req models.TaskDedupeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Invalid request body",
+		})
+	}
+	result, err := gth.taskRegistry.DedupeTasks(req.Keep, req.Remove)
+	if err != nil {
+		return c.Status(taskLinkErrorStatus(err)).JSON(models.APIResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    result,
+		})
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   result,
+	})
+}
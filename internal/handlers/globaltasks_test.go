@@ -44,6 +44,8 @@ func setupFoldersApp(t *testing.T) (*fiber.App, *services.TaskRegistryService, s
 	app.Post("/api/global-folders/add", h.AddFolder)
 	app.Post("/api/global-folders/:id/forget", h.ForgetFolder)
 	app.Post("/api/global-folders/:id/sync", h.SyncFolder)
+	app.Post("/api/global-folders/:id/reactivate", h.ReactivateFolder)
+	app.Delete("/api/global-folders/:id", h.DeleteFolder)
 	return app, registry, t.TempDir() // returned tempdir is a fresh empty folder we can use as a project root
 }
 
@@ -193,6 +195,90 @@ func TestForgetFolder_SoftDeletesAndPreservesAuditRow(t *testing.T) {
 	_ = registry // keeps the linter quiet about an otherwise-unused var
 }
 
+// addTestFolder registers dir through the API and returns its id.
+func addTestFolder(t *testing.T, app *fiber.App, dir string) int {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"path": dir})
+	req := httptest.NewRequest(http.MethodPost, "/api/global-folders/add", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	var folder struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(decode(t, resp).Data, &folder); err != nil || folder.ID == 0 {
+		t.Fatalf("decode folder: %v", err)
+	}
+	return folder.ID
+}
+
+func TestReactivateAndDeleteFolder(t *testing.T) {
+	app, _, projDir := setupFoldersApp(t)
+	id := addTestFolder(t, app, projDir)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/global-folders/%d/forget", id), nil))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("forget: status %d", resp.StatusCode)
+	}
+	// Forgotten folders are listed only on request.
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/api/global-folders", nil))
+	if data := string(decode(t, resp).Data); strings.Contains(data, projDir) {
+		t.Errorf("forgotten folder in the default list: %s", data)
+	}
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/api/global-folders?inactive=true", nil))
+	if data := string(decode(t, resp).Data); !strings.Contains(data, projDir) || !strings.Contains(data, `"active":false`) {
+		t.Errorf("?inactive=true list = %s", data)
+	}
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/global-folders/%d/reactivate", id), nil))
+	if resp.StatusCode != http.StatusOK {
+		buf, _ := io.ReadAll(resp.Body)
+		t.Fatalf("reactivate: status %d, body %s", resp.StatusCode, buf)
+	}
+	if data := string(decode(t, resp).Data); !strings.Contains(data, `"active":true`) {
+		t.Errorf("reactivated folder = %s", data)
+	}
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/global-folders/%d", id), nil))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("delete: status %d", resp.StatusCode)
+	}
+	if _, err := os.Stat(filepath.Join(projDir, "notes.md")); err != nil {
+		t.Errorf("delete touched the folder's notes: %v", err)
+	}
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/api/global-folders?inactive=true", nil))
+	if data := string(decode(t, resp).Data); strings.Contains(data, projDir) {
+		t.Errorf("deleted folder still listed: %s", data)
+	}
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/global-folders/%d", id), nil),
+		httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/global-folders/%d/reactivate", id), nil),
+	} {
+		if resp, _ := app.Test(req); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s %s after delete: status %d, want 404", req.Method, req.URL.Path, resp.StatusCode)
+		}
+	}
+	// Adding the path again starts over with a new row.
+	if again := addTestFolder(t, app, projDir); again == id {
+		t.Errorf("re-added deleted folder got its old id %d", id)
+	}
+}
+
+func TestReactivateFolder_NotesGone(t *testing.T) {
+	app, _, projDir := setupFoldersApp(t)
+	id := addTestFolder(t, app, projDir)
+	app.Test(httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/global-folders/%d/forget", id), nil))
+	if err := os.Remove(filepath.Join(projDir, "notes.md")); err != nil {
+		t.Fatal(err)
+	}
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/global-folders/%d/reactivate", id), nil))
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("reactivating a folder without notes: status %d, want 409", resp.StatusCode)
+	}
+}
+
 func TestForgetFolder_InvalidID(t *testing.T) {
 	app, _, _ := setupFoldersApp(t)
 	req := httptest.NewRequest(http.MethodPost, "/api/global-folders/not-a-number/forget", nil)
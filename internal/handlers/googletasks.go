@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GoogleTasksHandler exposes the Google Tasks mirror.
+type GoogleTasksHandler struct {
+	googleTasks *services.GoogleTasksService
+}
+
+// NewGoogleTasksHandler creates a new Google Tasks handler
+func NewGoogleTasksHandler(googleTasks *services.GoogleTasksService) *GoogleTasksHandler {
+	return &GoogleTasksHandler{googleTasks: googleTasks}
+}
+
+// Sync mirrors tasks immediately instead of waiting for the next
+// background pass.
+// POST /api/google-tasks/sync
+func (h *GoogleTasksHandler) Sync(c *fiber.Ctx) error {
+	if !h.googleTasks.Enabled() {
+		return fiber.NewError(fiber.StatusBadRequest, "Google Tasks is not set up for this folder (run 'noteflow-go google-auth' and set google_tasks in "+models.FolderConfigFile+")")
+	}
+	result, err := h.googleTasks.Sync(c.UserContext())
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadGateway, "Google Tasks sync failed: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   result,
+	})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Idempotency headers: the client's key for a request, and the marker on
+// a response replayed for a retry of it.
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKey is the longest Idempotency-Key accepted.
+const maxIdempotencyKey = 255
+
+// Idempotent lets clients retry h safely: a request with an
+// Idempotency-Key header gets the stored response of the first request
+// made with that key instead of running h again. Reusing a key for a
+// different body is a 422, and a retry while the first request is still
+// running a 409. Only successful responses are stored, so failed requests
+// can be retried with the same key.
+func Idempotent(store *services.IdempotencyStore, h fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(IdempotencyKeyHeader)
+		if key == "" {
+			return h(c)
+		}
+		if len(key) > maxIdempotencyKey {
+			return fiber.NewError(fiber.StatusBadRequest, "Idempotency-Key is too long")
+		}
+		hash := requestHash(c)
+
+		stored, err := store.Begin(key, time.Now())
+		if errors.Is(err, services.ErrIdempotencyInFlight) {
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		if stored != nil {
+			if stored.RequestHash != hash {
+				return fiber.NewError(fiber.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+			}
+			c.Set(IdempotencyReplayedHeader, "true")
+			c.Set(fiber.HeaderContentType, stored.ContentType)
+			return c.Status(stored.Status).Send(stored.Body)
+		}
+
+		var result *services.StoredResponse
+		defer func() { store.End(key, result, time.Now()) }()
+		if err := h(c); err != nil {
+			return err
+		}
+		resp := c.Response()
+		if status := resp.StatusCode(); status >= 200 && status < 300 {
+			result = &services.StoredResponse{
+				RequestHash: hash,
+				Status:      status,
+				ContentType: string(resp.Header.ContentType()),
+				Body:        append([]byte(nil), resp.Body()...),
+			}
+		}
+		return nil
+	}
+}
+
+// requestHash identifies a request by its method and body, so a key
+// reused for something else is caught.
+func requestHash(c *fiber.Ctx) string {
+	sum := sha256.New()
+	sum.Write([]byte(c.Method()))
+	sum.Write([]byte{0})
+	sum.Write(c.Body())
+	return hex.EncodeToString(sum.Sum(nil))
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestIdempotent_ReplaysRetries(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	registry, err := services.NewTaskRegistryService()
+	if err != nil {
+		t.Fatalf("NewTaskRegistryService: %v", err)
+	}
+	t.Cleanup(func() { _ = registry.Close() })
+	dir := t.TempDir()
+	mgr, err := services.NewNoteManager(dir)
+	if err != nil {
+		t.Fatalf("NewNoteManager: %v", err)
+	}
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			code := fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				code = e.Code
+			}
+			return c.Status(code).SendString(err.Error())
+		},
+	})
+	app.Post("/notes", Idempotent(registry.Idempotency(dir), NewNotesHandler(mgr, nil).AddNote))
+
+	post := func(key, body string) (int, string, string) {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/notes", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, resp.Header.Get(IdempotencyReplayedHeader), string(b)
+	}
+
+	const note = `{"title":"Groceries","content":"milk"}`
+	status, replayed, first := post("k1", note)
+	if status != 200 || replayed != "" {
+		t.Fatalf("first request: %d %q %s", status, replayed, first)
+	}
+	status, replayed, again := post("k1", note)
+	if status != 200 || replayed != "true" || again != first {
+		t.Errorf("retry: %d %q %s, want the first response %s", status, replayed, again, first)
+	}
+	if n := len(mgr.GetAllNotes()); n != 1 {
+		t.Errorf("%d notes after a retry, want 1", n)
+	}
+
+	if status, _, _ := post("k1", `{"title":"Other","content":"x"}`); status != fiber.StatusUnprocessableEntity {
+		t.Errorf("key reused for another body: %d, want 422", status)
+	}
+	// Without a key, or with a new one, every request adds a note.
+	post("", note)
+	post("k2", note)
+	if n := len(mgr.GetAllNotes()); n != 3 {
+		t.Errorf("%d notes, want 3", n)
+	}
+}
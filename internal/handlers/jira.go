@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// JiraHandler exposes the Jira issue sync.
+type JiraHandler struct {
+	jira *services.JiraService
+}
+
+// NewJiraHandler creates a new Jira handler
+func NewJiraHandler(jira *services.JiraService) *JiraHandler {
+	return &JiraHandler{jira: jira}
+}
+
+// Sync creates issues for new tasks and pulls issue status immediately
+// instead of waiting for the next background pass.
+// POST /api/jira/sync
+func (h *JiraHandler) Sync(c *fiber.Ctx) error {
+	if !h.jira.Enabled() {
+		return fiber.NewError(fiber.StatusBadRequest, "No Jira project configured for this folder (set jira.url, jira.project and jira.tag or jira.section in "+models.FolderConfigFile+")")
+	}
+	result, err := h.jira.Sync(c.UserContext())
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadGateway, "Jira sync failed: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   result,
+	})
+}
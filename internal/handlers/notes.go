@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/Xafloc/NoteFlow-Go/internal/models"
@@ -10,19 +11,68 @@ import (
 
 // NotesHandler handles note-related HTTP requests
 type NotesHandler struct {
-	noteManager *services.NoteManager
+	noteManager  *services.NoteManager
+	templates    *services.NoteTemplateService
+	config       *models.Config                // for the site export's default theme; may be nil
+	taskRegistry *services.TaskRegistryService // registers the folders an import makes; may be nil
 }
 
-// NewNotesHandler creates a new notes handler
-func NewNotesHandler(noteManager *services.NoteManager) *NotesHandler {
+// NewNotesHandler creates a new notes handler. templates may be nil, in
+// which case the template parameter of AddNote is rejected.
+func NewNotesHandler(noteManager *services.NoteManager, templates *services.NoteTemplateService) *NotesHandler {
 	return &NotesHandler{
 		noteManager: noteManager,
+		templates:   templates,
 	}
 }
 
-// GetNotes returns all notes as HTML
+// SetConfig gives the handler the user config, whose theme a site export
+// uses unless the request names another.
+func (h *NotesHandler) SetConfig(config *models.Config) {
+	h.config = config
+}
+
+// SetTaskRegistry gives the handler the task registry, which the folders
+// ImportJoplin makes for notebooks are added to.
+func (h *NotesHandler) SetTaskRegistry(taskRegistry *services.TaskRegistryService) {
+	h.taskRegistry = taskRegistry
+}
+
+// saveError is the response to a change the note manager refused: a 409
+// when notes.md was changed by another program meanwhile, which the client
+// answers by reloading, else code with message.
+func saveError(err error, code int, message string) error {
+	if errors.Is(err, services.ErrNotesChangedOnDisk) {
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	}
+	return fiber.NewError(code, message)
+}
+
+// GetNotes returns all notes as HTML. ?tag=project limits the list to notes
+// tagged #project or anything nested under it (#project/clientA, ...), and
+// ?mention=ana to notes mentioning @ana. Its ETag is known before
+// rendering, so If-None-Match is answered without one.
 func (h *NotesHandler) GetNotes(c *fiber.Ctx) error {
-	html, err := h.noteManager.RenderNotesHTML()
+	tag := c.Query("tag")
+	if tag != "" {
+		var ok bool
+		if tag, ok = models.NormalizeTagName(tag); !ok {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid tag name")
+		}
+	}
+	mention := c.Query("mention")
+	if mention != "" {
+		var ok bool
+		if mention, ok = models.NormalizeMentionName(mention); !ok {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid mention name")
+		}
+	}
+	// A client polling unchanged notes gets 304 without a render
+	c.Set(fiber.HeaderETag, h.noteManager.NotesETag(tag, mention))
+	if c.Get(fiber.HeaderIfNoneMatch) != "" && c.Fresh() {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	html, err := h.noteManager.RenderNotesHTMLFiltered(tag, mention)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to render notes: "+err.Error())
 	}
@@ -31,9 +81,12 @@ func (h *NotesHandler) GetNotes(c *fiber.Ctx) error {
 	return c.SendString(html)
 }
 
-// AddNote creates a new note
+// AddNote creates a new note. With a template name the note starts from
+// that template, placeholders expanded, and any content is inserted at
+// its {{cursor}}; the response then carries the expanded text's cursor
+// offset so the editor can put the caret there.
 func (h *NotesHandler) AddNote(c *fiber.Ctx) error {
-	var title, content string
+	var title, content, template string
 	
 	// Check content type to handle both JSON and FormData
 	contentType := c.Get("Content-Type")
@@ -45,10 +98,25 @@ func (h *NotesHandler) AddNote(c *fiber.Ctx) error {
 		}
 		title = req.Title
 		content = req.Content
+		template = req.Template
 	} else {
 		// Handle FormData request (web form)
 		title = c.FormValue("title")
 		content = c.FormValue("content")
+		template = c.FormValue("template")
+	}
+
+	var data interface{}
+	if template != "" {
+		if h.templates == nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Note templates are not available")
+		}
+		expanded, err := h.templates.Expand(template, title, content)
+		if err != nil {
+			return noteTemplateError(err)
+		}
+		title, content = expanded.Title, expanded.Content
+		data = models.NoteCursor{Cursor: expanded.Cursor}
 	}
 
 	if content == "" {
@@ -56,11 +124,12 @@ func (h *NotesHandler) AddNote(c *fiber.Ctx) error {
 	}
 
 	if err := h.noteManager.AddNote(title, content); err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to add note: "+err.Error())
+		return saveError(err, fiber.StatusInternalServerError, "Failed to add note: "+err.Error())
 	}
 
 	return c.JSON(models.APIResponse{
 		Status: "success",
+		Data:   data,
 	})
 }
 
@@ -77,16 +146,48 @@ func (h *NotesHandler) GetNote(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusNotFound, "Note not found")
 	}
 
-	response := map[string]interface{}{
-		"timestamp": note.Timestamp.Format("2006-01-02 15:04:05"),
-		"content":   note.Content,
-		"title":     note.Title,
+	return c.JSON(noteView(note))
+}
+
+func noteView(note *models.Note) models.NoteView {
+	return models.NoteView{
+		Timestamp: note.Timestamp.Format("2006-01-02 15:04:05"),
+		Content:   note.Content,
+		Title:     note.Title,
+		Metadata:  note.Metadata,
+		Version:   note.Version(),
 	}
+}
 
-	return c.JSON(response)
+// GetNoteRaw returns a note's markdown source, without its header line.
+// GET /api/notes/:index/raw
+func (h *NotesHandler) GetNoteRaw(c *fiber.Ctx) error {
+	index, err := strconv.Atoi(c.Params("index"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid note index")
+	}
+	note, err := h.noteManager.GetNote(index)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Note not found")
+	}
+	c.Set("Content-Type", "text/markdown; charset=utf-8")
+	return c.SendString(note.Content)
+}
+
+// GetNotesRaw returns the whole notes.md file.
+// GET /api/notes/raw
+func (h *NotesHandler) GetNotesRaw(c *fiber.Ctx) error {
+	data, err := h.noteManager.RawNotes()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	c.Set("Content-Type", "text/markdown; charset=utf-8")
+	return c.Send(data)
 }
 
-// UpdateNote updates an existing note
+// UpdateNote updates an existing note. With the version the edit started
+// from, a note changed meanwhile is not overwritten: the answer is a 409
+// with both versions (models.NoteConflict), which MergeNote can combine.
 func (h *NotesHandler) UpdateNote(c *fiber.Ctx) error {
 	indexStr := c.Params("index")
 	index, err := strconv.Atoi(indexStr)
@@ -94,26 +195,22 @@ func (h *NotesHandler) UpdateNote(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid note index")
 	}
 
-	var title, content string
-	
-	// Check content type to handle both JSON and FormData
-	contentType := c.Get("Content-Type")
-	if contentType == "application/json" {
-		// Handle JSON request (API calls)
-		var req models.NoteRequest
-		if err := c.BodyParser(&req); err != nil {
-			return fiber.NewError(fiber.StatusBadRequest, "Invalid JSON request format")
-		}
-		title = req.Title
-		content = req.Content
-	} else {
-		// Handle FormData request (web form)
-		title = c.FormValue("title")
-		content = c.FormValue("content")
+	req, err := noteEdit(c)
+	if err != nil {
+		return err
 	}
 
-	if err := h.noteManager.UpdateNote(index, title, content); err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to update note: "+err.Error())
+	err = h.noteManager.UpdateNoteAt(index, req.Version, req.Title, req.Content)
+	var conflict *services.NoteConflictError
+	if errors.As(err, &conflict) {
+		return c.Status(fiber.StatusConflict).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "The note was changed since you started editing it",
+			Data:    models.NoteConflict{Current: noteView(&conflict.Current), Yours: req},
+		})
+	}
+	if err != nil {
+		return saveError(err, fiber.StatusInternalServerError, "Failed to update note: "+err.Error())
 	}
 
 	return c.JSON(models.APIResponse{
@@ -121,7 +218,50 @@ func (h *NotesHandler) UpdateNote(c *fiber.Ctx) error {
 	})
 }
 
-// DeleteNote deletes a specific note
+// MergeNote merges an edit that started from an older version of a note
+// into the current one, without saving it: the client reviews the result,
+// resolves any conflict markers and saves it with the returned version.
+// POST /api/notes/:index/merge
+func (h *NotesHandler) MergeNote(c *fiber.Ctx) error {
+	index, err := strconv.Atoi(c.Params("index"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid note index")
+	}
+	req, err := noteEdit(c)
+	if err != nil {
+		return err
+	}
+	if req.Version == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "version is required")
+	}
+	merged, err := h.noteManager.MergeNote(index, req.Version, req.Title, req.Content)
+	if errors.Is(err, services.ErrRevisionNotFound) {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "The version the edit started from is not in the note's history")
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Note not found")
+	}
+	return c.JSON(models.APIResponse{Status: "success", Data: merged})
+}
+
+// noteEdit reads a note edit sent as JSON (API calls) or form data (the
+// web form).
+func noteEdit(c *fiber.Ctx) (models.NoteRequest, error) {
+	if c.Get("Content-Type") == "application/json" {
+		var req models.NoteRequest
+		if err := c.BodyParser(&req); err != nil {
+			return req, fiber.NewError(fiber.StatusBadRequest, "Invalid JSON request format")
+		}
+		return req, nil
+	}
+	return models.NoteRequest{
+		Title:   c.FormValue("title"),
+		Content: c.FormValue("content"),
+		Version: c.FormValue("version"),
+	}, nil
+}
+
+// DeleteNote moves a specific note to the trash
 func (h *NotesHandler) DeleteNote(c *fiber.Ctx) error {
 	indexStr := c.Params("index")
 	index, err := strconv.Atoi(indexStr)
@@ -130,10 +270,164 @@ func (h *NotesHandler) DeleteNote(c *fiber.Ctx) error {
 	}
 
 	if err := h.noteManager.DeleteNote(index); err != nil {
+		return saveError(err, fiber.StatusNotFound, "Note not found")
+	}
+
+	return c.JSON(models.APIResponse{
+		Status: "success",
+	})
+}
+// GetNoteDiff returns a word-level diff between two versions of a note,
+// plus a side-by-side HTML rendering. from/to take a revision ID,
+// "current", or a YYYY-MM-DD date; see NoteManager.DiffNote for defaults.
+// GET /api/notes/:index/diff?from=2026-10-09&to=current
+func (h *NotesHandler) GetNoteDiff(c *fiber.Ctx) error {
+	index, err := strconv.Atoi(c.Params("index"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid note index")
+	}
+	result, err := h.noteManager.DiffNote(index, c.Query("from"), c.Query("to"))
+	if errors.Is(err, services.ErrRevisionNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Note not found: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   result,
+	})
+}
+
+// historyError maps history lookups to 404s: both an unknown revision and
+// an out-of-range note index mean "nothing there".
+func historyError(err error) error {
+	if errors.Is(err, services.ErrRevisionNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	return fiber.NewError(fiber.StatusNotFound, "Note not found: "+err.Error())
+}
+
+// GetNoteHistory lists a note's saved versions, newest first.
+// GET /api/notes/:index/history
+func (h *NotesHandler) GetNoteHistory(c *fiber.Ctx) error {
+	index, err := strconv.Atoi(c.Params("index"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid note index")
+	}
+	revs, err := h.noteManager.NoteHistory(index)
+	if err != nil {
+		return historyError(err)
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   revs,
+	})
+}
+
+// GetNoteRevision returns one saved version of a note and a unified diff
+// from it to the current text. ?format=diff returns just the diff as
+// text/x-diff, for piping into patch or a diff viewer.
+// GET /api/notes/:index/history/:rev
+func (h *NotesHandler) GetNoteRevision(c *fiber.Ctx) error {
+	index, err := strconv.Atoi(c.Params("index"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid note index")
+	}
+	rev, err := h.noteManager.NoteRevision(index, c.Params("rev"))
+	if err != nil {
+		return historyError(err)
+	}
+	if c.Query("format") == "diff" {
+		c.Set("Content-Type", "text/x-diff; charset=utf-8")
+		return c.SendString(rev.Diff)
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   rev,
+	})
+}
+
+// RestoreNoteRevision makes a saved version current again. The replaced
+// version is kept in the history.
+// POST /api/notes/:index/history/:rev/restore
+func (h *NotesHandler) RestoreNoteRevision(c *fiber.Ctx) error {
+	index, err := strconv.Atoi(c.Params("index"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid note index")
+	}
+	if err := h.noteManager.RestoreRevision(index, c.Params("rev")); err != nil {
+		return historyError(err)
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: "Note restored",
+	})
+}
+
+// GetNoteBacklinks lists the notes that link to this one with [[Title]].
+// GET /api/notes/:index/backlinks
+func (h *NotesHandler) GetNoteBacklinks(c *fiber.Ctx) error {
+	index, err := strconv.Atoi(c.Params("index"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid note index")
+	}
+	links, err := h.noteManager.Backlinks(index)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Note not found")
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   links,
+	})
+}
+
+// GetNoteTOC lists the headings of a note with their anchors on the page.
+// GET /api/notes/:index/toc
+func (h *NotesHandler) GetNoteTOC(c *fiber.Ctx) error {
+	index, err := strconv.Atoi(c.Params("index"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid note index")
+	}
+	toc, err := h.noteManager.NoteTOC(index)
+	if err != nil {
 		return fiber.NewError(fiber.StatusNotFound, "Note not found")
 	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   toc,
+	})
+}
+
+// GetTOC lists the headings of every note that has any.
+// GET /api/toc
+func (h *NotesHandler) GetTOC(c *fiber.Ctx) error {
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   h.noteManager.TOC(),
+	})
+}
 
+// GetRenderCacheStats reports the rendered-note cache's size and hits.
+// GET /api/debug/render-cache
+func (h *NotesHandler) GetRenderCacheStats(c *fiber.Ctx) error {
 	return c.JSON(models.APIResponse{
 		Status: "success",
+		Data:   h.noteManager.RenderCacheStats(),
+	})
+}
+
+// QueryNoteMetadata lists notes by frontmatter. Each query parameter is a
+// filter: ?status=draft keeps notes whose status is draft (or whose list
+// value contains it), ?due= keeps notes that set due at all.
+// GET /api/notes/metadata?status=draft&tags=q4
+func (h *NotesHandler) QueryNoteMetadata(c *fiber.Ctx) error {
+	filters := make(map[string]string)
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		filters[string(key)] = string(value)
 	})
-}
\ No newline at end of file
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   h.noteManager.QueryMetadata(filters),
+	})
+}
@@ -25,7 +25,9 @@ func setupNotesApp(t *testing.T) *fiber.App {
 	if err != nil {
 		t.Fatalf("NewNoteManager: %v", err)
 	}
-	h := NewNotesHandler(mgr)
+	templates := services.NewNoteTemplateService(dir)
+	h := NewNotesHandler(mgr, templates)
+	th := NewNoteTemplatesHandler(templates)
 
 	app := fiber.New(fiber.Config{
 		// Surface handler errors as the response body for clearer test failures.
@@ -39,7 +41,20 @@ func setupNotesApp(t *testing.T) *fiber.App {
 	})
 	app.Get("/notes", h.GetNotes)
 	app.Post("/notes", h.AddNote)
+	app.Get("/notes/raw", h.GetNotesRaw)
 	app.Get("/notes/:index", h.GetNote)
+	app.Get("/notes/:index/raw", h.GetNoteRaw)
+	app.Put("/notes/:index", h.UpdateNote)
+	app.Post("/notes/:index/merge", h.MergeNote)
+	app.Get("/notes/:index/diff", h.GetNoteDiff)
+	app.Get("/notes/:index/history", h.GetNoteHistory)
+	app.Get("/notes/:index/history/:rev", h.GetNoteRevision)
+	app.Post("/notes/:index/history/:rev/restore", h.RestoreNoteRevision)
+	app.Get("/templates", th.List)
+	app.Post("/templates", th.Create)
+	app.Get("/templates/:name", th.Get)
+	app.Put("/templates/:name", th.Update)
+	app.Delete("/templates/:name", th.Delete)
 	return app
 }
 
@@ -61,6 +76,49 @@ func TestNotesHandler_GetNotes_Empty(t *testing.T) {
 	}
 }
 
+func TestNotesHandler_GetNotes_ETag(t *testing.T) {
+	app := setupNotesApp(t)
+	get := func(url, ifNoneMatch string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Test: %v", err)
+		}
+		return resp
+	}
+	add := func(content string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/notes", strings.NewReader(`{"content":"`+content+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if resp, err := app.Test(req); err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("add note: %v, %v", resp, err)
+		}
+	}
+
+	add("first #x")
+	etag := get("/notes", "").Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+	if resp := get("/notes", etag); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("unchanged notes: status = %d, want 304", resp.StatusCode)
+	}
+	// Filters render different pages.
+	if resp := get("/notes?tag=x", etag); resp.StatusCode != http.StatusOK {
+		t.Errorf("filtered notes: status = %d, want 200", resp.StatusCode)
+	}
+
+	add("second")
+	resp := get("/notes", etag)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == etag {
+		t.Errorf("after a change: status = %d, ETag %s", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+}
+
 func TestNotesHandler_AddNote_JSON(t *testing.T) {
 	app := setupNotesApp(t)
 
@@ -152,3 +210,259 @@ func TestNotesHandler_GetNote_NonIntegerIndex(t *testing.T) {
 		t.Errorf("status = %d, want 400 for non-integer index", resp.StatusCode)
 	}
 }
+
+func TestNotesHandler_GetNoteDiff(t *testing.T) {
+	app := setupNotesApp(t)
+	send := func(method, url, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Test: %v", err)
+		}
+		return resp
+	}
+
+	// No edits yet: nothing to compare against.
+	send(http.MethodPost, "/notes", `{"title":"Plan","content":"ship on friday"}`)
+	if resp := send(http.MethodGet, "/notes/0/diff", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("diff without history: status = %d, want 404", resp.StatusCode)
+	}
+
+	send(http.MethodPut, "/notes/0", `{"title":"Plan","content":"ship on monday"}`)
+	resp := send(http.MethodGet, "/notes/0/diff", "")
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("status = %d: %s", resp.StatusCode, body)
+	}
+	var out struct {
+		Data struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+			Ops  []struct {
+				Op   string `json:"op"`
+				Text string `json:"text"`
+			} `json:"ops"`
+			HTML string `json:"html"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+	if out.Data.To != "current" || len(out.Data.Ops) != 3 || out.Data.Ops[1].Text != "friday" || out.Data.Ops[2].Text != "monday" {
+		t.Errorf("diff = %+v", out.Data)
+	}
+	if !strings.Contains(out.Data.HTML, "<del>friday</del>") || !strings.Contains(out.Data.HTML, "<ins>monday</ins>") {
+		t.Errorf("html = %s", out.Data.HTML)
+	}
+}
+
+func TestNotesHandler_UpdateConflictAndMerge(t *testing.T) {
+	app := setupNotesApp(t)
+	send := func(method, url, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Test: %v", err)
+		}
+		return resp
+	}
+
+	send(http.MethodPost, "/notes", `{"title":"Plan","content":"milk\neggs\nbread"}`)
+	var opened struct {
+		Version string `json:"version"`
+	}
+	json.NewDecoder(send(http.MethodGet, "/notes/0", "").Body).Decode(&opened)
+	if opened.Version == "" {
+		t.Fatal("GET /notes/0 has no version")
+	}
+
+	// Another tab saves first, from the same version.
+	if resp := send(http.MethodPut, "/notes/0", `{"title":"Plan","content":"oat milk\neggs\nbread","version":"`+opened.Version+`"}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("first update: status %d", resp.StatusCode)
+	}
+
+	// The slower tab is refused, and told what the note is now.
+	edit := `{"title":"Plan","content":"milk\neggs\nrye bread","version":"` + opened.Version + `"}`
+	resp := send(http.MethodPut, "/notes/0", edit)
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("stale update: status %d, want 409", resp.StatusCode)
+	}
+	var conflict struct {
+		Data struct {
+			Current struct {
+				Content string `json:"content"`
+				Version string `json:"version"`
+			} `json:"current"`
+			Yours struct {
+				Content string `json:"content"`
+			} `json:"yours"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&conflict)
+	if conflict.Data.Current.Content != "oat milk\neggs\nbread" || conflict.Data.Yours.Content != "milk\neggs\nrye bread" {
+		t.Errorf("conflict = %+v", conflict.Data)
+	}
+
+	resp = send(http.MethodPost, "/notes/0/merge", edit)
+	var merged struct {
+		Data struct {
+			Content   string `json:"content"`
+			Version   string `json:"version"`
+			Conflicts int    `json:"conflicts"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&merged)
+	if resp.StatusCode != http.StatusOK || merged.Data.Content != "oat milk\neggs\nrye bread" || merged.Data.Conflicts != 0 {
+		t.Fatalf("merge: status %d, %+v", resp.StatusCode, merged.Data)
+	}
+	if merged.Data.Version != conflict.Data.Current.Version {
+		t.Errorf("merge version = %q, want current %q", merged.Data.Version, conflict.Data.Current.Version)
+	}
+
+	if resp := send(http.MethodPost, "/notes/0/merge", `{"title":"Plan","content":"x","version":"0000000000000000"}`); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("merge from unknown version: status %d, want 422", resp.StatusCode)
+	}
+}
+
+func TestNotesHandler_HistoryAndRestore(t *testing.T) {
+	app := setupNotesApp(t)
+	send := func(method, url, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Test: %v", err)
+		}
+		return resp
+	}
+
+	send(http.MethodPost, "/notes", `{"title":"Plan","content":"ship on friday"}`)
+	send(http.MethodPut, "/notes/0", `{"title":"Plan","content":"ship on monday"}`)
+
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	json.NewDecoder(send(http.MethodGet, "/notes/0/history", "").Body).Decode(&list)
+	if len(list.Data) != 1 {
+		t.Fatalf("history = %+v", list.Data)
+	}
+	rev := list.Data[0].ID
+
+	resp := send(http.MethodGet, "/notes/0/history/"+rev+"?format=diff", "")
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "-ship on friday") || !strings.Contains(string(body), "+ship on monday") {
+		t.Errorf("diff: status %d, body %s", resp.StatusCode, body)
+	}
+
+	if resp := send(http.MethodPost, "/notes/0/history/"+rev+"/restore", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("restore: status %d", resp.StatusCode)
+	}
+	var note struct {
+		Content string `json:"content"`
+	}
+	json.NewDecoder(send(http.MethodGet, "/notes/0", "").Body).Decode(&note)
+	if note.Content != "ship on friday" {
+		t.Errorf("content after restore = %q", note.Content)
+	}
+
+	if resp := send(http.MethodGet, "/notes/0/history/123", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown revision: status %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestNotesHandler_AddNoteFromTemplate(t *testing.T) {
+	app := setupNotesApp(t)
+	send := func(method, url, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Test: %v", err)
+		}
+		return resp
+	}
+
+	if resp := send(http.MethodPost, "/templates", `{"name":"daily standup","content":"## Done\n- {{cursor}}\n## Next\n"}`); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create: status %d", resp.StatusCode)
+	}
+	if resp := send(http.MethodPost, "/templates", `{"name":"daily standup","content":""}`); resp.StatusCode != http.StatusConflict {
+		t.Errorf("duplicate create: status %d, want 409", resp.StatusCode)
+	}
+	if resp := send(http.MethodPost, "/templates", `{"name":"../escape","content":"x"}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bad name: status %d, want 400", resp.StatusCode)
+	}
+
+	resp := send(http.MethodPost, "/notes", `{"title":"Standup","template":"daily standup","content":"fixed the build"}`)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("add: status %d, body %s", resp.StatusCode, body)
+	}
+	var added struct {
+		Data struct {
+			Cursor int `json:"cursor"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&added)
+	if want := len("## Done\n- fixed the build"); added.Data.Cursor != want {
+		t.Errorf("cursor = %d, want %d", added.Data.Cursor, want)
+	}
+
+	var note struct {
+		Title   string `json:"title"`
+		Content string `json:"content"`
+	}
+	json.NewDecoder(send(http.MethodGet, "/notes/0", "").Body).Decode(&note)
+	if note.Title != "Standup" || note.Content != "## Done\n- fixed the build\n## Next\n" {
+		t.Errorf("note = %+v", note)
+	}
+
+	if resp := send(http.MethodPost, "/notes", `{"template":"missing"}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("missing template: status %d, want 404", resp.StatusCode)
+	}
+	if resp := send(http.MethodDelete, "/templates/daily%20standup", ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("delete: status %d", resp.StatusCode)
+	}
+}
+
+func TestNotesHandler_Raw(t *testing.T) {
+	app := setupNotesApp(t)
+	for _, body := range []string{
+		`{"title":"first","content":"# Plan\n\n- [ ] ship"}`,
+		`{"title":"second","content":"**bold** text"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/notes", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if resp, err := app.Test(req); err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("add note: %v %v", resp, err)
+		}
+	}
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/notes/1/raw", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(got) != "# Plan\n\n- [ ] ship" ||
+		!strings.HasPrefix(resp.Header.Get("Content-Type"), "text/markdown") {
+		t.Errorf("note raw = %d %q (%s)", resp.StatusCode, got, resp.Header.Get("Content-Type"))
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/notes/raw", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ = io.ReadAll(resp.Body)
+	if !strings.Contains(string(got), " - second\n\n**bold** text") || !strings.Contains(string(got), "- [ ] ship") {
+		t.Errorf("file raw = %q", got)
+	}
+
+	if resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/notes/7/raw", nil)); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("missing note: status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// NoteTemplatesHandler serves CRUD for the folder's note templates.
+type NoteTemplatesHandler struct {
+	templates *services.NoteTemplateService
+}
+
+// NewNoteTemplatesHandler creates a new note templates handler
+func NewNoteTemplatesHandler(templates *services.NoteTemplateService) *NoteTemplatesHandler {
+	return &NoteTemplatesHandler{templates: templates}
+}
+
+// noteTemplateError maps template lookups to 404s and bad names to 400s.
+func noteTemplateError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrTemplateNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, storage.ErrInvalidTemplateName):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
+
+// templateName returns the :name route parameter, unescaped so names
+// with spaces work.
+func templateName(c *fiber.Ctx) string {
+	name, err := url.PathUnescape(c.Params("name"))
+	if err != nil {
+		return c.Params("name")
+	}
+	return name
+}
+
+// List returns every template with its content.
+// GET /api/templates
+func (h *NoteTemplatesHandler) List(c *fiber.Ctx) error {
+	templates, err := h.templates.List()
+	if err != nil {
+		return noteTemplateError(err)
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   templates,
+	})
+}
+
+// Get returns one template.
+// GET /api/templates/:name
+func (h *NoteTemplatesHandler) Get(c *fiber.Ctx) error {
+	tmpl, err := h.templates.Get(templateName(c))
+	if err != nil {
+		return noteTemplateError(err)
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   tmpl,
+	})
+}
+
+// Create adds a template, refusing to overwrite an existing one.
+// POST /api/templates  {"name": "standup", "content": "..."}
+func (h *NoteTemplatesHandler) Create(c *fiber.Ctx) error {
+	var req services.NoteTemplate
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
+	}
+	if _, err := h.templates.Get(req.Name); err == nil {
+		return fiber.NewError(fiber.StatusConflict, "A template with that name already exists")
+	} else if !errors.Is(err, services.ErrTemplateNotFound) {
+		return noteTemplateError(err)
+	}
+	if err := h.templates.Save(req.Name, req.Content); err != nil {
+		return noteTemplateError(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(models.APIResponse{
+		Status:  "success",
+		Message: "Template created",
+	})
+}
+
+// Update creates or replaces a template.
+// PUT /api/templates/:name  {"content": "..."}
+func (h *NoteTemplatesHandler) Update(c *fiber.Ctx) error {
+	var req models.TemplateContentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
+	}
+	if err := h.templates.Save(templateName(c), req.Content); err != nil {
+		return noteTemplateError(err)
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: "Template saved",
+	})
+}
+
+// Delete removes a template.
+// DELETE /api/templates/:name
+func (h *NoteTemplatesHandler) Delete(c *fiber.Ctx) error {
+	if err := h.templates.Delete(templateName(c)); err != nil {
+		return noteTemplateError(err)
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: "Template deleted",
+	})
+}
@@ -9,16 +9,38 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-// SearchHandler implements the v1.5 cross-folder search endpoint. The
-// per-folder local search is purely client-side (the notes are already
-// rendered into the DOM); this endpoint exists for the "Cmd+Enter to
-// search every NoteFlow folder" path.
+// SearchHandler implements the search endpoints: the indexed full-text
+// search over this folder's notes, and the v1.5 cross-folder search. The
+// in-page filter is still purely client-side (the notes are already
+// rendered into the DOM); the cross-folder endpoint exists for the
+// "Cmd+Enter to search every NoteFlow folder" path.
 type SearchHandler struct {
 	taskRegistry *services.TaskRegistryService
+	search       *services.SearchService
 }
 
-func NewSearchHandler(taskRegistry *services.TaskRegistryService) *SearchHandler {
-	return &SearchHandler{taskRegistry: taskRegistry}
+func NewSearchHandler(taskRegistry *services.TaskRegistryService, search *services.SearchService) *SearchHandler {
+	return &SearchHandler{taskRegistry: taskRegistry, search: search}
+}
+
+// Search runs a full-text query over this folder's note titles, bodies and
+// task lines. Every word must match (as a word prefix); results carry the
+// note index, timestamp and an HTML snippet with hits in <mark>.
+//
+// GET /api/search?q=<query>&limit=<n>
+func (h *SearchHandler) Search(c *fiber.Ctx) error {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "q parameter is required")
+	}
+	if len(query) > 500 {
+		return fiber.NewError(fiber.StatusBadRequest, "q must be 500 chars or fewer")
+	}
+	limit := c.QueryInt("limit", 50)
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   h.search.Search(query, limit),
+	})
 }
 
 // SearchResultNote is one matching note in one folder.
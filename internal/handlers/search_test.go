@@ -52,7 +52,7 @@ func setupSearchApp(t *testing.T) (*fiber.App, *services.TaskRegistryService, st
 		t.Fatal(err)
 	}
 
-	h := NewSearchHandler(registry)
+	h := NewSearchHandler(registry, nil)
 	app := fiber.New(fiber.Config{
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
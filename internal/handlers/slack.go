@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SlackHandler answers the slash commands of a Slack app.
+type SlackHandler struct {
+	slack *services.SlackService
+}
+
+// NewSlackHandler creates a new Slack handler
+func NewSlackHandler(slack *services.SlackService) *SlackHandler {
+	return &SlackHandler{slack: slack}
+}
+
+// Command answers a slash command, which Slack posts as a form signed
+// with the app's signing secret; the reply is shown only to whoever gave
+// the command. It needs no NoteFlow login, the signature standing in.
+// POST /slack/command
+func (h *SlackHandler) Command(c *fiber.Ctx) error {
+	if !h.slack.Enabled() {
+		return fiber.NewError(fiber.StatusNotFound, "No Slack app configured (set slack.signing_secret in noteflow.json)")
+	}
+	now := time.Now()
+	if err := h.slack.Verify(c.Get("X-Slack-Request-Timestamp"), c.Get("X-Slack-Signature"), c.Body(), now); err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"response_type": "ephemeral",
+		"text":          h.slack.Command(c.FormValue("command"), c.FormValue("text"), now),
+	})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxSpellcheckText caps the text accepted by one check. Notes are far
+// smaller; this only stops an accidental paste from tying up the server.
+const maxSpellcheckText = 1 << 20
+
+// SpellcheckHandler serves spell checking for the editor.
+type SpellcheckHandler struct {
+	spellcheck *services.SpellcheckService
+}
+
+// NewSpellcheckHandler creates a new spellcheck handler
+func NewSpellcheckHandler(spellcheck *services.SpellcheckService) *SpellcheckHandler {
+	return &SpellcheckHandler{spellcheck: spellcheck}
+}
+
+// Check returns the misspelled words in the posted text with their
+// offsets (JavaScript string indices) and suggestions.
+// POST /api/spellcheck  {"text": "..."}
+func (h *SpellcheckHandler) Check(c *fiber.Ctx) error {
+	var req models.SpellcheckRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
+	}
+	if len(req.Text) > maxSpellcheckText {
+		return fiber.NewError(fiber.StatusRequestEntityTooLarge, "Text too long to spell check")
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   h.spellcheck.Check(req.Text),
+	})
+}
+
+// GetWords returns the folder's custom word list.
+// GET /api/spellcheck/words
+func (h *SpellcheckHandler) GetWords(c *fiber.Ctx) error {
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   h.spellcheck.Words(),
+	})
+}
+
+// AddWord adds a word to the folder's custom list in .noteflow.json.
+// POST /api/spellcheck/words  {"word": "NoteFlow"}
+func (h *SpellcheckHandler) AddWord(c *fiber.Ctx) error {
+	var req models.WordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
+	}
+	if err := h.spellcheck.AddWord(req.Word); err != nil {
+		if errors.Is(err, services.ErrInvalidWord) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to save word: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: "Word added",
+		Data:    h.spellcheck.Words(),
+	})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// StatsHandler serves activity statistics.
+type StatsHandler struct {
+	noteManager  *services.NoteManager
+	taskRegistry *services.TaskRegistryService
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(noteManager *services.NoteManager, taskRegistry *services.TaskRegistryService) *StatsHandler {
+	return &StatsHandler{noteManager: noteManager, taskRegistry: taskRegistry}
+}
+
+// ExportCSV returns per-day note and task metrics for this folder as CSV.
+// GET /api/stats/export.csv
+func (h *StatsHandler) ExportCSV(c *fiber.Ctx) error {
+	var completions []time.Time
+	if global, err := h.taskRegistry.GetGlobalTasks(); err == nil {
+		completions = services.FolderCompletions(global.Tasks, h.noteManager.GetBasePath())
+	}
+	days, err := h.noteManager.DailyStats(completions, time.Now())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to compute stats: "+err.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteStatsCSV(&buf, days); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to write CSV: "+err.Error())
+	}
+	c.Set("Content-Type", "text/csv; charset=utf-8")
+	c.Set("Content-Disposition", `attachment; filename="noteflow-stats.csv"`)
+	return c.Send(buf.Bytes())
+}
+
+// Dashboard returns notes, task, archive and storage figures for every
+// registered folder and in total, with ?weeks= weeks of task activity.
+// GET /api/stats
+func (h *StatsHandler) Dashboard(c *fiber.Ctx) error {
+	weeks := 0
+	if v := c.Query("weeks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > services.MaxDashboardWeeks {
+			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+				Status:  "error",
+				Message: "weeks must be a number from 1 to " + strconv.Itoa(services.MaxDashboardWeeks),
+			})
+		}
+		weeks = n
+	}
+	dashboard, err := h.taskRegistry.Dashboard(weeks, time.Now())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Status:  "error",
+			Message: "Failed to compute stats: " + err.Error(),
+		})
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   dashboard,
+	})
+}
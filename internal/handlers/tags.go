@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TagsHandler handles tag maintenance requests
+type TagsHandler struct {
+	noteManager *services.NoteManager
+}
+
+// NewTagsHandler creates a new tags handler
+func NewTagsHandler(noteManager *services.NoteManager) *TagsHandler {
+	return &TagsHandler{noteManager: noteManager}
+}
+
+// GetTags returns the tags used in this folder as a tree; nested tags like
+// #project/clientA/website hang under their parents.
+// GET /api/tags
+func (h *TagsHandler) GetTags(c *fiber.Ctx) error {
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   h.noteManager.TagTree(),
+	})
+}
+
+// GetMentions lists the people @mentioned in this folder's notes with how
+// many notes mention each.
+// GET /api/mentions
+func (h *TagsHandler) GetMentions(c *fiber.Ctx) error {
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   h.noteManager.Mentions(),
+	})
+}
+
+// GetTagStats returns per-tag note and open-task counts, last-used dates
+// and tag co-occurrence, e.g. for a tag cloud.
+// GET /api/tags/stats
+func (h *TagsHandler) GetTagStats(c *fiber.Ctx) error {
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   h.noteManager.TagStats(),
+	})
+}
+
+// RenameTag rewrites one tag to a new, unused name in every note.
+// POST /api/tags/rename  {"from": "wrk", "to": "work", "dry_run": true}
+func (h *TagsHandler) RenameTag(c *fiber.Ctx) error {
+	var req models.TagRenameRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
+	}
+	from, okFrom := models.NormalizeTagName(req.From)
+	to, okTo := models.NormalizeTagName(req.To)
+	if !okFrom || !okTo {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid tag name")
+	}
+	result, err := h.noteManager.RenameTag(from, to, req.DryRun)
+	if err != nil {
+		return tagRewriteError(err)
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   result,
+	})
+}
+
+// MergeTags folds several tags into one, which may already exist.
+// POST /api/tags/merge  {"from": ["proj-x", "projx"], "into": "projectx", "dry_run": true}
+func (h *TagsHandler) MergeTags(c *fiber.Ctx) error {
+	var req models.TagMergeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
+	}
+	into, ok := models.NormalizeTagName(req.Into)
+	if !ok || len(req.From) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid tag name")
+	}
+	sources := make([]string, 0, len(req.From))
+	for _, s := range req.From {
+		name, ok := models.NormalizeTagName(s)
+		if !ok {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid tag name: "+s)
+		}
+		sources = append(sources, name)
+	}
+	result, err := h.noteManager.MergeTags(sources, into, req.DryRun)
+	if err != nil {
+		return tagRewriteError(err)
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   result,
+	})
+}
+
+func tagRewriteError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrTagNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrTagExists):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+}
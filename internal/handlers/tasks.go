@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"errors"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Xafloc/NoteFlow-Go/internal/models"
 	"github.com/Xafloc/NoteFlow-Go/internal/services"
@@ -10,7 +14,8 @@ import (
 
 // TasksHandler handles task-related HTTP requests
 type TasksHandler struct {
-	noteManager *services.NoteManager
+	noteManager  *services.NoteManager
+	taskRegistry *services.TaskRegistryService
 }
 
 // NewTasksHandler creates a new tasks handler
@@ -20,13 +25,21 @@ func NewTasksHandler(noteManager *services.NoteManager) *TasksHandler {
 	}
 }
 
+// SetTaskRegistry gives the handler the task registry, which ExportTasks
+// reads the tasks' times from.
+func (h *TasksHandler) SetTaskRegistry(taskRegistry *services.TaskRegistryService) {
+	h.taskRegistry = taskRegistry
+}
+
 // GetTasks returns all active tasks as JSON
 func (h *TasksHandler) GetTasks(c *fiber.Ctx) error {
 	tasks := h.noteManager.GetActiveTasks()
 	return c.JSON(tasks)
 }
 
-// UpdateTask updates a task's completion status
+// UpdateTask updates a task's completion status. With "cascade" set the
+// task's subtasks get the same status.
+// POST /api/tasks/:index  {"checked": true, "cascade": true}
 func (h *TasksHandler) UpdateTask(c *fiber.Ctx) error {
 	indexStr := c.Params("index")
 	index, err := strconv.Atoi(indexStr)
@@ -39,11 +52,87 @@ func (h *TasksHandler) UpdateTask(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
 	}
 
-	if err := h.noteManager.UpdateTask(index, req.Checked); err != nil {
-		return fiber.NewError(fiber.StatusNotFound, "Task not found: "+err.Error())
+	update := h.noteManager.UpdateTask
+	if req.Cascade {
+		update = h.noteManager.UpdateTaskCascade
+	}
+	if err := update(index, req.Checked); err != nil {
+		return saveError(err, fiber.StatusNotFound, "Task not found: "+err.Error())
+	}
+
+	return c.JSON(models.APIResponse{
+		Status: "success",
+	})
+}
+
+// CaptureTask quick-adds one task from compact syntax, e.g.
+// "Buy cake #errands !2 @due(sat) >ProjectX/Shopping".
+// POST /api/capture  {"text": "..."}
+func (h *TasksHandler) CaptureTask(c *fiber.Ctx) error {
+	var req models.CaptureRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Nothing to capture")
+	}
+	added, err := h.noteManager.QuickAddTask(req.Text)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Capture failed: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   added,
+	})
+}
+
+// ArchiveCompleted moves checked tasks from notes older than "days" (30 by
+// default) under a "Completed" heading in their note, or with
+// "target": "file" out to archive.md. A dry run lists what would move.
+// POST /api/tasks/archive-completed  {"days": 30, "target": "file", "dry_run": true}
+func (h *TasksHandler) ArchiveCompleted(c *fiber.Ctx) error {
+	var req models.ArchiveCompletedRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
+		}
+	}
+	opts := services.ArchiveCompletedOptions{
+		Days:   services.DefaultArchiveCompletedDays,
+		Target: req.Target,
+		DryRun: req.DryRun,
+	}
+	if req.Days != nil {
+		if *req.Days < 0 {
+			return fiber.NewError(fiber.StatusBadRequest, "days must not be negative")
+		}
+		opts.Days = *req.Days
 	}
 
+	res, err := h.noteManager.ArchiveCompletedTasks(opts, time.Now())
+	if errors.Is(err, services.ErrInvalidArchiveTarget) {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Archive failed: "+err.Error())
+	}
 	return c.JSON(models.APIResponse{
 		Status: "success",
+		Data:   res,
 	})
-}
\ No newline at end of file
+}
+
+// ExportTasks downloads this folder's tasks as CSV, or TSV at export.tsv,
+// with the columns and filters of GET /api/global-tasks/export.csv.
+// GET /api/tasks/export.csv
+func (h *TasksHandler) ExportTasks(c *fiber.Ctx) error {
+	if h.taskRegistry == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "The task registry is not available")
+	}
+	q, err := globalTaskQuery(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	q.Folder = h.noteManager.GetBasePath()
+	return sendTaskExport(c, h.taskRegistry, q, "noteflow-"+filepath.Base(q.Folder)+"-tasks")
+}
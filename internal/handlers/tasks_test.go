@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestTasksHandler_CaptureTask(t *testing.T) {
+	mgr, err := services.NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewNoteManager: %v", err)
+	}
+	app := fiber.New()
+	app.Post("/capture", NewTasksHandler(mgr).CaptureTask)
+
+	req := httptest.NewRequest(http.MethodPost, "/capture", bytes.NewBufferString(`{"text":"Buy cake #errands !2 >Errands"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var out struct {
+		Data services.QuickAdd `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Data.Line != "- [ ] Buy cake #errands !p2" || out.Data.Note != "Errands" {
+		t.Errorf("data = %+v", out.Data)
+	}
+	if tasks := mgr.GetAllTasks(); len(tasks) != 1 || tasks[0].Priority != 2 {
+		t.Errorf("tasks = %+v", tasks)
+	}
+
+	empty := httptest.NewRequest(http.MethodPost, "/capture", bytes.NewBufferString(`{"text":"  "}`))
+	empty.Header.Set("Content-Type", "application/json")
+	if resp, _ := app.Test(empty); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("empty capture status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestTasksHandler_ArchiveCompleted(t *testing.T) {
+	mgr, err := services.NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewNoteManager: %v", err)
+	}
+	if err := mgr.AddNote("Sprint", "- [x] Ship\n- [ ] Open"); err != nil {
+		t.Fatal(err)
+	}
+	app := fiber.New()
+	app.Post("/tasks/archive-completed", NewTasksHandler(mgr).ArchiveCompleted)
+
+	post := func(body string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/tasks/archive-completed", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Test: %v", err)
+		}
+		return resp
+	}
+
+	resp := post(`{"days":0,"dry_run":true}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var out struct {
+		Data services.ArchiveCompletedResult `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !out.Data.DryRun || out.Data.Archived != 1 || out.Data.Target != services.ArchiveToSection {
+		t.Errorf("data = %+v", out.Data)
+	}
+	if tasks := mgr.GetAllTasks(); tasks[0].Section == services.CompletedSection {
+		t.Errorf("dry run moved the task: %+v", tasks[0])
+	}
+	if resp := post(`{"target":"trash"}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bad target status = %d, want 400", resp.StatusCode)
+	}
+}
@@ -32,16 +32,12 @@ func (h *ThemesHandler) GetThemes(c *fiber.Ctx) error {
 
 // GetCurrentTheme returns the currently active theme
 func (h *ThemesHandler) GetCurrentTheme(c *fiber.Ctx) error {
-	return c.JSON(map[string]string{
-		"theme": h.config.Theme,
-	})
+	return c.JSON(models.CurrentTheme{Theme: h.config.Theme})
 }
 
 // SetTheme sets the current theme (runtime only)
 func (h *ThemesHandler) SetTheme(c *fiber.Ctx) error {
-	var req struct {
-		Theme string `form:"theme" json:"theme"`
-	}
+	var req models.ThemeRequest
 
 	if err := c.BodyParser(&req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
@@ -69,10 +65,10 @@ func (h *ThemesHandler) GetFontScales(c *fiber.Ctx) error {
 	}
 	return c.JSON(models.APIResponse{
 		Status: "success",
-		Data: map[string]any{
-			"scales": out,
-			"min":    models.FontScaleMin,
-			"max":    models.FontScaleMax,
+		Data: models.FontScales{
+			Scales: out,
+			Min:    models.FontScaleMin,
+			Max:    models.FontScaleMax,
 		},
 	})
 }
@@ -81,10 +77,7 @@ func (h *ThemesHandler) GetFontScales(c *fiber.Ctx) error {
 // section name must be one of models.FontScaleSections; the value is
 // clamped to [FontScaleMin, FontScaleMax] before being stored.
 func (h *ThemesHandler) SaveFontScale(c *fiber.Ctx) error {
-	var req struct {
-		Section string  `json:"section"`
-		Scale   float64 `json:"scale"`
-	}
+	var req models.FontScaleRequest
 	if err := c.BodyParser(&req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
 	}
@@ -112,9 +105,7 @@ func (h *ThemesHandler) SaveFontScale(c *fiber.Ctx) error {
 
 // SaveTheme saves the user's theme preference to config file
 func (h *ThemesHandler) SaveTheme(c *fiber.Ctx) error {
-	var req struct {
-		Theme string `form:"theme" json:"theme"`
-	}
+	var req models.ThemeRequest
 
 	if err := c.BodyParser(&req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid request format")
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TodoistHandler exposes the Todoist sync.
+type TodoistHandler struct {
+	todoist *services.TodoistService
+}
+
+// NewTodoistHandler creates a new Todoist handler
+func NewTodoistHandler(todoist *services.TodoistService) *TodoistHandler {
+	return &TodoistHandler{todoist: todoist}
+}
+
+// Sync runs a two-way sync immediately instead of waiting for the next
+// background pass.
+// POST /api/todoist/sync
+func (h *TodoistHandler) Sync(c *fiber.Ctx) error {
+	if !h.todoist.Enabled() {
+		return fiber.NewError(fiber.StatusBadRequest, "No Todoist project configured for this folder (set todoist.project_id in "+models.FolderConfigFile+")")
+	}
+	result, err := h.todoist.Sync(c.UserContext())
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadGateway, "Todoist sync failed: "+err.Error())
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   result,
+	})
+}
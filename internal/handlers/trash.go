@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// trashError maps an unknown trash ID to a 404.
+func trashError(err error) error {
+	if errors.Is(err, services.ErrTrashNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
+
+// ListTrash returns the deleted notes, most recently deleted first.
+// GET /api/trash
+func (h *NotesHandler) ListTrash(c *fiber.Ctx) error {
+	entries, err := h.noteManager.ListTrash()
+	if err != nil {
+		return trashError(err)
+	}
+	return c.JSON(models.APIResponse{
+		Status: "success",
+		Data:   entries,
+	})
+}
+
+// RestoreTrashedNote moves a deleted note back into notes.md and returns
+// its new index.
+// POST /api/trash/:entry/restore
+func (h *NotesHandler) RestoreTrashedNote(c *fiber.Ctx) error {
+	index, err := h.noteManager.RestoreTrashedNote(c.Params("entry"))
+	if err != nil {
+		return trashError(err)
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: "Note restored",
+		Data:    models.NoteIndex{Index: index},
+	})
+}
+
+// PurgeTrashedNote permanently deletes one note from the trash.
+// DELETE /api/trash/:entry
+func (h *NotesHandler) PurgeTrashedNote(c *fiber.Ctx) error {
+	if err := h.noteManager.PurgeTrashedNote(c.Params("entry")); err != nil {
+		return trashError(err)
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: "Note permanently deleted",
+	})
+}
+
+// EmptyTrash permanently deletes every note in the trash.
+// DELETE /api/trash
+func (h *NotesHandler) EmptyTrash(c *fiber.Ctx) error {
+	n, err := h.noteManager.EmptyTrash()
+	if err != nil {
+		return trashError(err)
+	}
+	return c.JSON(models.APIResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("Permanently deleted %d note(s)", n),
+	})
+}
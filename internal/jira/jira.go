@@ -0,0 +1,181 @@
+// Package jira is a minimal client for the Jira REST API (v2) covering the
+// calls NoteFlow's task sync needs. Version 2 is used because it takes
+// plain-text fields and is served by both Jira Cloud and Server/Data
+// Center. Like internal/todoist it uses net/http directly and only models
+// the fields NoteFlow reads.
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by GetIssue when the issue was deleted or moved
+// out of reach of the token.
+var ErrNotFound = errors.New("jira: issue not found")
+
+// Client performs authenticated Jira API calls.
+type Client struct {
+	baseURL string
+	email   string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a client for the server at baseURL. With an email the
+// token is sent as basic auth (Jira Cloud API tokens); without one it is
+// sent as a bearer token (Server/Data Center personal access tokens).
+func NewClient(baseURL, email, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		email:   email,
+		token:   token,
+		http:    &http.Client{Timeout: 20 * time.Second},
+	}
+}
+
+// Status is an issue's workflow status. Workflows name their statuses
+// freely, so whether an issue is finished is read from the category, whose
+// keys are fixed: "new", "indeterminate" and "done".
+type Status struct {
+	Name           string `json:"name"`
+	StatusCategory struct {
+		Key string `json:"key"`
+	} `json:"statusCategory"`
+}
+
+// Issue is the subset of a Jira issue NoteFlow cares about.
+type Issue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary string `json:"summary"`
+		Status  Status `json:"status"`
+	} `json:"fields"`
+}
+
+// Done reports whether the issue's status is in the "done" category.
+func (i Issue) Done() bool {
+	return i.Fields.Status.StatusCategory.Key == "done"
+}
+
+// NewIssue describes an issue to create.
+type NewIssue struct {
+	Project   string // project key
+	IssueType string // e.g. "Task"
+	Summary   string
+	DueDate   string // YYYY-MM-DD or ""
+	Labels    []string
+}
+
+// CreateIssue creates an issue and returns its key.
+func (c *Client) CreateIssue(ctx context.Context, in NewIssue) (string, error) {
+	fields := map[string]any{
+		"project":   map[string]string{"key": in.Project},
+		"issuetype": map[string]string{"name": in.IssueType},
+		"summary":   in.Summary,
+	}
+	if in.DueDate != "" {
+		fields["duedate"] = in.DueDate
+	}
+	if len(in.Labels) > 0 {
+		fields["labels"] = in.Labels
+	}
+	var resp struct {
+		Key string `json:"key"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/rest/api/2/issue", map[string]any{"fields": fields}, &resp); err != nil {
+		return "", fmt.Errorf("create issue in %s: %w", in.Project, err)
+	}
+	return resp.Key, nil
+}
+
+// GetIssue fetches an issue's summary and status. It returns ErrNotFound
+// when the issue no longer exists.
+func (c *Client) GetIssue(ctx context.Context, key string) (*Issue, error) {
+	var issue Issue
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "?fields=summary,status"
+	if err := c.do(ctx, http.MethodGet, path, nil, &issue); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("get issue %s: %w", key, err)
+	}
+	return &issue, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(buf)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch {
+	case c.email != "":
+		req.SetBasicAuth(c.email, c.token)
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// issueLinkRE matches the issue link written back into task text: a
+// markdown link whose text is the key and whose target is the browse URL.
+var issueLinkRE = regexp.MustCompile(`\[([A-Z][A-Z0-9_]*-\d+)\]\(https?://[^)\s]+/browse/[A-Z][A-Z0-9_]*-\d+\)`)
+
+// IssueLink returns the markdown link for key on the server at baseURL,
+// e.g. "[NF-12](https://acme.atlassian.net/browse/NF-12)". The key stays
+// readable in notes.md and the rendered note links to the issue.
+func IssueLink(baseURL, key string) string {
+	return "[" + key + "](" + strings.TrimRight(baseURL, "/") + "/browse/" + key + ")"
+}
+
+// ParseIssueLink finds the first issue link in text and returns its key.
+// ok is false when text has no link.
+func ParseIssueLink(text string) (key string, ok bool) {
+	m := issueLinkRE.FindStringSubmatch(text)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// StripIssueLink removes any issue link from text.
+func StripIssueLink(text string) string {
+	return strings.Join(strings.Fields(issueLinkRE.ReplaceAllString(text, "")), " ")
+}
@@ -0,0 +1,73 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateIssue_SendsFieldsWithBasicAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me@example.com" || pass != "secret" {
+			t.Errorf("basic auth = %q, %q, %v", user, pass, ok)
+		}
+		var body struct {
+			Fields map[string]any `json:"fields"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/rest/api/2/issue" || body.Fields["summary"] != "ship it" || body.Fields["duedate"] != "2026-10-20" {
+			t.Errorf("request = %s %+v", r.URL.Path, body.Fields)
+		}
+		w.Write([]byte(`{"id":"10001","key":"NF-7"}`))
+	}))
+	defer srv.Close()
+
+	key, err := NewClient(srv.URL+"/", "me@example.com", "secret").CreateIssue(context.Background(),
+		NewIssue{Project: "NF", IssueType: "Task", Summary: "ship it", DueDate: "2026-10-20"})
+	if err != nil || key != "NF-7" {
+		t.Fatalf("CreateIssue = %q, %v", key, err)
+	}
+}
+
+func TestGetIssue_StatusAndNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer pat" {
+			t.Errorf("auth = %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Path == "/rest/api/2/issue/NF-404" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"key":"NF-1","fields":{"summary":"x","status":{"name":"Closed","statusCategory":{"key":"done"}}}}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "pat")
+	issue, err := c.GetIssue(context.Background(), "NF-1")
+	if err != nil || !issue.Done() || issue.Fields.Status.Name != "Closed" {
+		t.Fatalf("GetIssue = %+v, %v", issue, err)
+	}
+	if _, err := c.GetIssue(context.Background(), "NF-404"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestIssueLinkRoundTrip(t *testing.T) {
+	link := IssueLink("https://acme.atlassian.net/", "NF-12")
+	if link != "[NF-12](https://acme.atlassian.net/browse/NF-12)" {
+		t.Errorf("IssueLink = %q", link)
+	}
+	text := "[ ] ship it #jira " + link
+	if key, ok := ParseIssueLink(text); !ok || key != "NF-12" {
+		t.Errorf("ParseIssueLink = %q, %v", key, ok)
+	}
+	if got := StripIssueLink(text); got != "[ ] ship it #jira" {
+		t.Errorf("StripIssueLink = %q", got)
+	}
+	if _, ok := ParseIssueLink("[ ] see [NF-12](https://example.com/docs)"); ok {
+		t.Error("a plain link should not parse as an issue link")
+	}
+}
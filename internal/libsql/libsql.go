@@ -0,0 +1,443 @@
+// Package libsql is a database/sql driver for remote libsql servers (sqld,
+// Turso) spoken to over HTTP with the Hrana v2 pipeline protocol. They run
+// SQLite, so SQL written for the local task DB runs on them unchanged.
+package libsql
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeout bounds each request to the server.
+const timeout = 30 * time.Second
+
+// IsDSN reports whether dsn names a remote libsql server rather than a
+// database file: libsql://, https:// or http:// URLs.
+func IsDSN(dsn string) bool {
+	for _, scheme := range []string{"libsql://", "https://", "http://"} {
+		if strings.HasPrefix(dsn, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// Connector connects to the libsql server at a URL. Use it with
+// sql.OpenDB.
+type Connector struct {
+	url    string // http(s) base URL
+	token  string
+	client *http.Client
+}
+
+// NewConnector returns a Connector for dsn, a libsql://, https:// or
+// http:// URL; libsql:// means https. token, when set, is sent as a
+// bearer token (Turso's database tokens).
+func NewConnector(dsn, token string) (*Connector, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid libsql URL %q", dsn)
+	}
+	switch u.Scheme {
+	case "libsql":
+		u.Scheme = "https"
+	case "https", "http":
+	default:
+		return nil, fmt.Errorf("invalid libsql URL %q", dsn)
+	}
+	if u.User != nil || u.RawQuery != "" {
+		return nil, fmt.Errorf("libsql URL %q: pass the token separately, not in the URL", u.Redacted())
+	}
+	return &Connector{url: strings.TrimRight(u.String(), "/"), token: token, client: &http.Client{Timeout: timeout}}, nil
+}
+
+// Connect opens a connection; its stream is opened by its first request.
+func (c *Connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{connector: c, baseURL: c.url}, nil
+}
+
+// Driver returns a driver that only works through a Connector.
+func (c *Connector) Driver() driver.Driver { return connectorOnly{} }
+
+type connectorOnly struct{}
+
+func (connectorOnly) Open(string) (driver.Conn, error) {
+	return nil, errors.New("libsql: open with sql.OpenDB(libsql.NewConnector(...))")
+}
+
+// conn is one Hrana stream. The server keeps a stream's state between
+// requests (transactions, PRAGMAs) for as long as the client sends back
+// the baton it was last given; an idle stream expires after a few
+// seconds, so the pool should not keep connections idle for long.
+type conn struct {
+	connector *Connector
+	baseURL   string
+	baton     string
+	inTx      bool
+	bad       bool
+}
+
+type hranaValue struct {
+	Type   string `json:"type"`
+	Value  any    `json:"value,omitempty"`
+	Base64 string `json:"base64,omitempty"`
+}
+
+type hranaStmt struct {
+	SQL      string       `json:"sql"`
+	Args     []hranaValue `json:"args,omitempty"`
+	WantRows bool         `json:"want_rows"`
+}
+
+type hranaRequest struct {
+	Type string     `json:"type"`
+	Stmt *hranaStmt `json:"stmt,omitempty"`
+	SQL  string     `json:"sql,omitempty"` // for sequence
+}
+
+type hranaError struct {
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+}
+
+func (e *hranaError) Error() string {
+	if e.Code != "" {
+		return "libsql: " + e.Message + " (" + e.Code + ")"
+	}
+	return "libsql: " + e.Message
+}
+
+type hranaResult struct {
+	Cols []struct {
+		Name     string `json:"name"`
+		Decltype string `json:"decltype"`
+	} `json:"cols"`
+	Rows             [][]hranaValue `json:"rows"`
+	AffectedRowCount int64          `json:"affected_row_count"`
+	LastInsertRowid  *string        `json:"last_insert_rowid"`
+}
+
+type pipelineResponse struct {
+	Baton   *string `json:"baton"`
+	BaseURL *string `json:"base_url"`
+	Results []struct {
+		Type     string      `json:"type"`
+		Error    *hranaError `json:"error"`
+		Response struct {
+			Type   string      `json:"type"`
+			Result hranaResult `json:"result"`
+		} `json:"response"`
+	} `json:"results"`
+}
+
+// pipeline sends requests on the connection's stream and returns the
+// result of the first.
+func (c *conn) pipeline(ctx context.Context, requests ...hranaRequest) (*hranaResult, error) {
+	if c.bad {
+		return nil, driver.ErrBadConn
+	}
+	body, err := json.Marshal(map[string]any{"baton": nilIfEmpty(c.baton), "requests": requests})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v2/pipeline", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.connector.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.connector.token)
+	}
+	resp, err := c.connector.client.Do(req)
+	if err != nil {
+		return nil, c.fail(fmt.Errorf("libsql: %w", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, c.fail(fmt.Errorf("libsql: %s %s", resp.Status, bytes.TrimSpace(msg)))
+	}
+	var out pipelineResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, c.fail(fmt.Errorf("libsql: invalid response: %w", err))
+	}
+	c.baton = ""
+	if out.Baton != nil {
+		c.baton = *out.Baton
+	}
+	if out.BaseURL != nil && *out.BaseURL != "" {
+		c.baseURL = strings.TrimRight(*out.BaseURL, "/")
+	}
+	if len(out.Results) == 0 {
+		return nil, errors.New("libsql: empty response")
+	}
+	first := out.Results[0]
+	if first.Type == "error" && first.Error != nil {
+		return nil, first.Error
+	}
+	return &first.Response.Result, nil
+}
+
+// fail handles a request that didn't get an answer from the stream. Out
+// of a transaction nothing was lost with it, so database/sql may retry on
+// a new connection; in one, the transaction is gone.
+func (c *conn) fail(err error) error {
+	hadStream := c.baton != ""
+	c.baton = ""
+	c.bad = true
+	if hadStream && !c.inTx {
+		return driver.ErrBadConn
+	}
+	return err
+}
+
+func (c *conn) execute(ctx context.Context, query string, args []driver.NamedValue, wantRows bool) (*hranaResult, error) {
+	stmt := &hranaStmt{SQL: query, WantRows: wantRows}
+	for _, a := range args {
+		if a.Name != "" {
+			return nil, fmt.Errorf("libsql: named argument %q is not supported", a.Name)
+		}
+		v, err := encodeValue(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Args = append(stmt.Args, v)
+	}
+	return c.pipeline(ctx, hranaRequest{Type: "execute", Stmt: stmt})
+}
+
+// ExecContext runs a statement. Several statements without arguments, as
+// schema migrations use, run as one sequence.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) == 0 && strings.Contains(strings.TrimRight(strings.TrimSpace(query), ";"), ";") {
+		if _, err := c.pipeline(ctx, hranaRequest{Type: "sequence", SQL: query}); err != nil {
+			return nil, err
+		}
+		return driver.RowsAffected(0), nil
+	}
+	res, err := c.execute(ctx, query, args, false)
+	if err != nil {
+		return nil, err
+	}
+	r := result{affected: res.AffectedRowCount}
+	if res.LastInsertRowid != nil {
+		r.lastID, _ = strconv.ParseInt(*res.LastInsertRowid, 10, 64)
+	}
+	return r, nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	res, err := c.execute(ctx, query, args, true)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{result: res}, nil
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if opts.ReadOnly {
+		return nil, errors.New("libsql: read-only transactions are not supported")
+	}
+	if _, err := c.execute(ctx, "BEGIN", nil, false); err != nil {
+		return nil, err
+	}
+	c.inTx = true
+	return &tx{conn: c}, nil
+}
+
+// Close closes the stream on the server.
+func (c *conn) Close() error {
+	if c.baton == "" || c.bad {
+		return nil
+	}
+	_, err := c.pipeline(context.Background(), hranaRequest{Type: "close"})
+	return err
+}
+
+// IsValid keeps connections whose stream failed out of the pool.
+func (c *conn) IsValid() bool { return !c.bad }
+
+type tx struct{ conn *conn }
+
+func (t *tx) Commit() error   { return t.end("COMMIT") }
+func (t *tx) Rollback() error { return t.end("ROLLBACK") }
+
+func (t *tx) end(statement string) error {
+	_, err := t.conn.execute(context.Background(), statement, nil, false)
+	t.conn.inTx = false
+	return err
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	out := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		out[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return out
+}
+
+type result struct {
+	lastID   int64
+	affected int64
+}
+
+func (r result) LastInsertId() (int64, error) { return r.lastID, nil }
+func (r result) RowsAffected() (int64, error) { return r.affected, nil }
+
+type rows struct {
+	result *hranaResult
+	next   int
+}
+
+func (r *rows) Columns() []string {
+	cols := make([]string, len(r.result.Cols))
+	for i, col := range r.result.Cols {
+		cols[i] = col.Name
+	}
+	return cols
+}
+
+func (r *rows) Close() error { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.Rows) {
+		return io.EOF
+	}
+	row := r.result.Rows[r.next]
+	r.next++
+	for i := range dest {
+		if i >= len(row) {
+			dest[i] = nil
+			continue
+		}
+		decltype := ""
+		if i < len(r.result.Cols) {
+			decltype = r.result.Cols[i].Decltype
+		}
+		v, err := decodeValue(row[i], decltype)
+		if err != nil {
+			return err
+		}
+		dest[i] = v
+	}
+	return nil
+}
+
+// timeFormat is how times are written, as the local SQLite driver does.
+const timeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+func encodeValue(v driver.Value) (hranaValue, error) {
+	switch v := v.(type) {
+	case nil:
+		return hranaValue{Type: "null"}, nil
+	case int64:
+		return hranaValue{Type: "integer", Value: strconv.FormatInt(v, 10)}, nil
+	case float64:
+		return hranaValue{Type: "float", Value: v}, nil
+	case bool:
+		if v {
+			return hranaValue{Type: "integer", Value: "1"}, nil
+		}
+		return hranaValue{Type: "integer", Value: "0"}, nil
+	case string:
+		return hranaValue{Type: "text", Value: v}, nil
+	case []byte:
+		return hranaValue{Type: "blob", Base64: base64.StdEncoding.EncodeToString(v)}, nil
+	case time.Time:
+		return hranaValue{Type: "text", Value: v.Format(timeFormat)}, nil
+	}
+	return hranaValue{}, fmt.Errorf("libsql: unsupported argument type %T", v)
+}
+
+// decodeValue converts a result value. Text in DATE, DATETIME and
+// TIMESTAMP columns comes back as time.Time, as with the local driver.
+func decodeValue(v hranaValue, decltype string) (driver.Value, error) {
+	switch v.Type {
+	case "null":
+		return nil, nil
+	case "integer":
+		s, _ := v.Value.(string)
+		return strconv.ParseInt(s, 10, 64)
+	case "float":
+		switch f := v.Value.(type) {
+		case float64:
+			return f, nil
+		case string:
+			return strconv.ParseFloat(f, 64)
+		}
+		return nil, fmt.Errorf("libsql: invalid float %v", v.Value)
+	case "text":
+		s, _ := v.Value.(string)
+		switch strings.ToUpper(decltype) {
+		case "DATE", "DATETIME", "TIMESTAMP":
+			if t, ok := parseTime(s); ok {
+				return t, nil
+			}
+		}
+		return s, nil
+	case "blob":
+		return base64.StdEncoding.DecodeString(v.Base64)
+	}
+	return nil, fmt.Errorf("libsql: unknown value type %q", v.Type)
+}
+
+var timeFormats = []string{
+	timeFormat,
+	"2006-01-02T15:04:05.999999999-07:00",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+func parseTime(s string) (time.Time, bool) {
+	for _, format := range timeFormats {
+		if t, err := time.Parse(format, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func nilIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
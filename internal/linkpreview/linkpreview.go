@@ -0,0 +1,135 @@
+// Package linkpreview reads the OpenGraph (and plain HTML) metadata of a
+// page — title, description, image and site name — for the preview cards
+// shown under links in notes. It only depends on golang.org/x/net/html.
+package linkpreview
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxHead is how much of a page is read looking for metadata; it all
+// lives in <head>, so there is no need to download the whole page.
+const maxHead = 512 << 10
+
+// Maximum lengths of the text kept in a preview, in runes.
+const (
+	maxTitle       = 200
+	maxDescription = 300
+)
+
+// Fetch downloads the page at pageURL with client and returns its
+// metadata. The preview's URL is the one asked for, not where redirects
+// ended, so it can be looked up by the link in the note.
+func Fetch(ctx context.Context, client *http.Client, pageURL string) (*models.LinkPreview, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.5")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("linkpreview: %s returned %s", pageURL, resp.Status)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "html") {
+		return nil, fmt.Errorf("linkpreview: %s is %s, not a web page", pageURL, ct)
+	}
+	p, err := Parse(io.LimitReader(resp.Body, maxHead), resp.Request.URL)
+	if err != nil {
+		return nil, err
+	}
+	p.URL = pageURL
+	return p, nil
+}
+
+// Parse reads the metadata of the HTML page in r, resolving a relative
+// image URL against base. OpenGraph properties win over Twitter cards,
+// which win over <title> and <meta name="description">.
+func Parse(r io.Reader, base *url.URL) (*models.LinkPreview, error) {
+	doc, err := nethtml.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("linkpreview: %w", err)
+	}
+	meta := make(map[string]string)
+	var title string
+	var walk func(*nethtml.Node)
+	walk = func(n *nethtml.Node) {
+		if n.Type == nethtml.ElementNode {
+			switch n.DataAtom {
+			case atom.Body:
+				return
+			case atom.Title:
+				if title == "" && n.FirstChild != nil {
+					title = n.FirstChild.Data
+				}
+			case atom.Meta:
+				key := attr(n, "property")
+				if key == "" {
+					key = attr(n, "name")
+				}
+				key = strings.ToLower(key)
+				if _, seen := meta[key]; key != "" && !seen {
+					meta[key] = attr(n, "content")
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	p := &models.LinkPreview{
+		Title:       clip(first(meta["og:title"], meta["twitter:title"], title), maxTitle),
+		Description: clip(first(meta["og:description"], meta["twitter:description"], meta["description"]), maxDescription),
+		SiteName:    clip(meta["og:site_name"], maxTitle),
+	}
+	if img := first(meta["og:image"], meta["og:image:url"], meta["twitter:image"]); img != "" && base != nil {
+		if u, err := base.Parse(img); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			p.Image = u.String()
+		}
+	}
+	if p.SiteName == "" && base != nil {
+		p.SiteName = strings.TrimPrefix(base.Hostname(), "www.")
+	}
+	return p, nil
+}
+
+func attr(n *nethtml.Node, key string) string {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// clip collapses whitespace in s and shortens it to max runes.
+func clip(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > max {
+		return strings.TrimSpace(string(r[:max-1])) + "…"
+	}
+	return s
+}
@@ -0,0 +1,56 @@
+package linkpreview
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	page := `<!doctype html><html><head>
+<title>Fallback title</title>
+<meta name="description" content="Plain   description">
+<meta property="og:title" content="OG title">
+<meta property="og:image" content="/img/card.png">
+</head><body><meta property="og:title" content="ignored"></body></html>`
+	base, _ := url.Parse("https://www.example.com/post/1")
+	p, err := Parse(strings.NewReader(page), base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Title != "OG title" || p.Description != "Plain description" ||
+		p.Image != "https://www.example.com/img/card.png" || p.SiteName != "example.com" {
+		t.Errorf("preview = %+v", p)
+	}
+
+	p, _ = Parse(strings.NewReader(`<title>`+strings.Repeat("x", 300)+`</title><meta property="og:image" content="javascript:alert(1)">`), base)
+	if len([]rune(p.Title)) != maxTitle || p.Image != "" {
+		t.Errorf("clipped preview = %+v", p)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if r.URL.Path == "/file.zip" {
+			w.Header().Set("Content-Type", "application/zip")
+		}
+		io.WriteString(w, `<meta property="og:title" content="Hello"><meta property="og:site_name" content="Site">`)
+	}))
+	defer srv.Close()
+
+	p, err := Fetch(context.Background(), srv.Client(), srv.URL+"/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.URL != srv.URL+"/a" || p.Title != "Hello" || p.SiteName != "Site" {
+		t.Errorf("preview = %+v", p)
+	}
+	if _, err := Fetch(context.Background(), srv.Client(), srv.URL+"/file.zip"); err == nil {
+		t.Error("non-HTML response accepted")
+	}
+}
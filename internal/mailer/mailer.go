@@ -0,0 +1,163 @@
+// Package mailer sends plain SMTP email with net/smtp. It exists for the
+// task digest and has no queue or retry logic of its own: callers run on a
+// schedule and simply try again next time.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// Message is one email with a plain-text body and an optional HTML
+// alternative.
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender delivers a Message.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTP sends through an SMTP server with PLAIN auth.
+type SMTP struct {
+	cfg models.SMTPConfig
+}
+
+// NewSMTP creates an SMTP sender. Port defaults to 587.
+func NewSMTP(cfg models.SMTPConfig) *SMTP {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &SMTP{cfg: cfg}
+}
+
+// Send delivers msg. Port 465 speaks TLS from the first byte; other ports
+// start in plain text and upgrade with STARTTLS when offered. Credentials
+// are only sent over TLS (net/smtp refuses PLAIN auth otherwise, except to
+// localhost).
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+
+	var conn net.Conn
+	var err error
+	if s.cfg.Port == 465 {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: s.cfg.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("smtp: connect %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && s.cfg.Port != 465 {
+		if err := c.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return fmt.Errorf("smtp: starttls: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		auth := smtp.PlainAuth("", s.cfg.Username, s.cfg.ResolvedPassword(), s.cfg.Host)
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("smtp: auth: %w", err)
+		}
+	}
+
+	from := msg.From
+	if from == "" {
+		from = s.cfg.Username
+	}
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("smtp: MAIL FROM: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("smtp: RCPT TO %s: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp: DATA: %w", err)
+	}
+	msg.From = from
+	if _, err := w.Write(Build(msg, time.Now())); err != nil {
+		return fmt.Errorf("smtp: write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	return c.Quit()
+}
+
+// Build renders msg as an RFC 5322 message: text/plain alone, or
+// multipart/alternative when an HTML body is present. Bodies are
+// quoted-printable so long lines and non-ASCII survive any relay.
+func Build(msg Message, date time.Time) []byte {
+	var b bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	header("From", msg.From)
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	if msg.HTML == "" {
+		header("Content-Type", `text/plain; charset="utf-8"`)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		b.WriteString("\r\n")
+		writeQP(&b, msg.Text)
+		return b.Bytes()
+	}
+
+	boundary := newBoundary()
+	header("Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	b.WriteString("\r\n")
+	for _, part := range []struct{ typ, body string }{{"text/plain", msg.Text}, {"text/html", msg.HTML}} {
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+		fmt.Fprintf(&b, "Content-Type: %s; charset=\"utf-8\"\r\n", part.typ)
+		b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		writeQP(&b, part.body)
+		b.WriteString("\r\n")
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return b.Bytes()
+}
+
+func writeQP(b *bytes.Buffer, s string) {
+	w := quotedprintable.NewWriter(b)
+	w.Write([]byte(strings.ReplaceAll(s, "\n", "\r\n")))
+	w.Close()
+}
+
+func newBoundary() string {
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	return "noteflow-" + hex.EncodeToString(buf)
+}
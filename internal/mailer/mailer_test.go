@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuild_MultipartAlternative(t *testing.T) {
+	raw := Build(Message{
+		From:    "noteflow@example.com",
+		To:      []string{"a@example.com", "b@example.com"},
+		Subject: "Digest — 2 overdue",
+		Text:    "Overdue (2)\n  - café run",
+		HTML:    "<h3>Overdue (2)</h3>",
+	}, time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC))
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if subj, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); subj != "Digest — 2 overdue" {
+		t.Errorf("subject = %q", subj)
+	}
+	if got := msg.Header.Get("To"); got != "a@example.com, b@example.com" {
+		t.Errorf("to = %q", got)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content type = %q, %v", mediaType, err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	var bodies []string
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(p) // multipart decodes quoted-printable
+		bodies = append(bodies, string(b))
+	}
+	if len(bodies) != 2 || bodies[0] != "Overdue (2)\r\n  - café run" || bodies[1] != "<h3>Overdue (2)</h3>" {
+		t.Errorf("bodies = %q", bodies)
+	}
+}
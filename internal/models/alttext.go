@@ -0,0 +1,15 @@
+package models
+
+// AltTextConfig enables generated alt text for uploaded images. Leave
+// Provider empty to keep it off.
+type AltTextConfig struct {
+	// Provider is "openai" (any OpenAI-compatible chat-completions API with
+	// image input) or "ollama" (the same API served by a local Ollama,
+	// so images never leave the machine).
+	Provider string `json:"provider,omitempty"`
+	APIURL   string `json:"api_url,omitempty"` // overrides the provider's default base URL
+	APIKey   string `json:"api_key,omitempty"` // openai: falls back to $OPENAI_API_KEY
+	Model    string `json:"model,omitempty"`   // default "gpt-4o-mini" / "llava"
+	// Prompt replaces the built-in instruction sent with each image.
+	Prompt string `json:"prompt,omitempty"`
+}
@@ -0,0 +1,204 @@
+package models
+
+import "time"
+
+// Request and response bodies of the REST API that aren't models of their
+// own. Keeping them named (rather than anonymous structs and maps in the
+// handlers) lets the OpenAPI document describe them.
+
+// NoteView is a note as returned for editing
+type NoteView struct {
+	Timestamp string            `json:"timestamp"` // "2006-01-02 15:04:05"
+	Content   string            `json:"content"`
+	Title     string            `json:"title"`
+	Metadata  map[string]string `json:"metadata"`
+	Version   string            `json:"version"` // see Note.Version
+}
+
+// NoteConflict is the data of a 409 to an update whose version is stale:
+// the note as it is now and the edit that was refused
+type NoteConflict struct {
+	Current NoteView    `json:"current"`
+	Yours   NoteRequest `json:"yours"`
+}
+
+// NoteMerge is an edit merged into the note's current version. Version is
+// the current version, to send with the merged text; Conflicts counts
+// the places both changed, marked in Content as git does.
+type NoteMerge struct {
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	Version   string `json:"version"`
+	Conflicts int    `json:"conflicts"`
+}
+
+// NoteCursor tells the editor where to put the caret in a note created
+// from a template, as a UTF-16 offset into its content
+type NoteCursor struct {
+	Cursor int `json:"cursor"`
+}
+
+// NoteIndex locates a note in notes.md, e.g. one restored from the trash
+type NoteIndex struct {
+	Index int `json:"index"`
+}
+
+// CaptureRequest quick-adds a task from compact syntax
+type CaptureRequest struct {
+	Text string `json:"text" form:"text"`
+}
+
+// ArchiveCompletedRequest moves checked tasks out of older notes
+type ArchiveCompletedRequest struct {
+	Days   *int   `json:"days,omitempty"` // nil means the default
+	Target string `json:"target,omitempty"`
+	DryRun bool   `json:"dry_run,omitempty"`
+}
+
+// TaskStateRequest moves a task to a board column
+type TaskStateRequest struct {
+	State string `json:"state" form:"state"`
+}
+
+// TagRenameRequest renames one tag in every note
+type TagRenameRequest struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	DryRun bool   `json:"dry_run,omitempty"`
+}
+
+// TagMergeRequest folds several tags into one
+type TagMergeRequest struct {
+	From   []string `json:"from"`
+	Into   string   `json:"into"`
+	DryRun bool     `json:"dry_run,omitempty"`
+}
+
+// SpellcheckRequest is text to spell check
+type SpellcheckRequest struct {
+	Text string `json:"text"`
+}
+
+// WordRequest adds a word to the custom spelling list
+type WordRequest struct {
+	Word string `json:"word"`
+}
+
+// TemplateContentRequest replaces a note template's text
+type TemplateContentRequest struct {
+	Content string `json:"content"`
+}
+
+// ThemeRequest selects a theme
+type ThemeRequest struct {
+	Theme string `json:"theme" form:"theme"`
+}
+
+// CurrentTheme names the configured theme
+type CurrentTheme struct {
+	Theme string `json:"theme"`
+}
+
+// FontScaleRequest sets one section's font-size multiplier
+type FontScaleRequest struct {
+	Section string  `json:"section"`
+	Scale   float64 `json:"scale"`
+}
+
+// FontScales lists every section's font-size multiplier and their bounds
+type FontScales struct {
+	Scales map[string]float64 `json:"scales"`
+	Min    float64            `json:"min"`
+	Max    float64            `json:"max"`
+}
+
+// UploadResult describes an uploaded file and the markdown that embeds
+// it. Transcripts and alt text are best effort; a failure is reported in
+// its *Error field without failing the upload.
+type UploadResult struct {
+	FilePath        string `json:"filePath"`
+	IsImage         bool   `json:"isImage"`
+	IsAudio         bool   `json:"isAudio"`
+	ContentType     string `json:"contentType"`
+	Transcript      string `json:"transcript,omitempty"`
+	TranscriptError string `json:"transcriptError,omitempty"`
+	AltText         string `json:"altText,omitempty"`
+	AltTextError    string `json:"altTextError,omitempty"`
+	Markdown        string `json:"markdown"`
+}
+
+// LinksView is the links panel, as HTML for the page and as markdown
+type LinksView struct {
+	HTML     string `json:"html"`
+	Markdown string `json:"markdown"`
+}
+
+// ArchiveDeleteRequest names an archived site to delete
+type ArchiveDeleteRequest struct {
+	Filename string `json:"filename"`
+}
+
+// ArchiveSnapshot is a newly captured snapshot of an archived page
+type ArchiveSnapshot struct {
+	Title      string    `json:"title"`
+	FilePath   string    `json:"file_path"`
+	ReaderPath string    `json:"reader_path"`
+	Archived   time.Time `json:"archived"`
+}
+
+// GlobalTaskToggle sets a global task's completion
+type GlobalTaskToggle struct {
+	Completed bool `json:"completed"`
+}
+
+// FolderAddRequest registers a notes folder for global tasks
+type FolderAddRequest struct {
+	Path string `json:"path"`
+}
+
+// FolderDiscoverRequest scans a directory tree for notes folders to
+// register for global tasks
+type FolderDiscoverRequest struct {
+	Root   string   `json:"root"`
+	Ignore []string `json:"ignore,omitempty"` // patterns of directories to skip
+	DryRun bool     `json:"dry_run,omitempty"`
+}
+
+// FolderLabelRequest sets how a registered folder is shown in global
+// tasks; empty fields clear the label
+type FolderLabelRequest struct {
+	Alias string `json:"alias"`
+	Group string `json:"group"`
+}
+
+// TaskLinkRequest lists global tasks, by ID, to link so that completing
+// one completes the others
+type TaskLinkRequest struct {
+	Tasks []int `json:"tasks"`
+}
+
+// TaskLinkResponse is the link the tasks were put in
+type TaskLinkResponse struct {
+	LinkID int `json:"link_id"`
+}
+
+// TaskDedupeRequest keeps one of a set of duplicate global tasks and
+// deletes the others from their notes
+type TaskDedupeRequest struct {
+	Keep   int   `json:"keep"`
+	Remove []int `json:"remove"`
+}
+
+// GitHubExportRequest lists the tasks to export as issues, by index
+type GitHubExportRequest struct {
+	Tasks []int `json:"tasks"`
+}
+
+// ConfigView is the configuration in effect, as GET /api/config shows it:
+// the config file with the environment and command line applied, and
+// credentials redacted (see Config.Redacted)
+type ConfigView struct {
+	Path   string         `json:"path"`   // the config file
+	Folder string         `json:"folder"` // the folder this workspace serves
+	Config map[string]any `json:"config"`
+}
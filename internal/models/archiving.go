@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// ArchiveConfig tunes how +URL links are archived.
+type ArchiveConfig struct {
+	// Reader stores a reader-mode copy (the article text without site
+	// chrome) next to every archive, as if each link were written ++URL.
+	Reader bool `json:"reader,omitempty"`
+	// Format is the default archive file type: "html" (default), "pdf",
+	// "mhtml" or "warc" (gzipped WARC). A link can pick its own with a
+	// prefix such as +pdf:URL or +html:URL.
+	Format string `json:"format,omitempty"`
+	// ChromePath is the Chrome or Chromium binary used to print PDFs.
+	// Empty finds one on $PATH or in the usual install locations; without
+	// one, PDF archives fall back to HTML.
+	ChromePath string `json:"chrome_path,omitempty"`
+
+	// Fetch limits; zero values use the defaults noted.
+	RequestTimeout int    `json:"request_timeout,omitempty"` // seconds per request, default 30
+	PageTimeout    int    `json:"page_timeout,omitempty"`    // seconds for a page and its resources, default 90
+	MaxPageMB      int    `json:"max_page_mb,omitempty"`     // per response, default 50
+	MaxRedirects   int    `json:"max_redirects,omitempty"`   // default 10; negative follows none
+	UserAgent      string `json:"user_agent,omitempty"`      // default "NoteFlow-Go archive"
+	// DomainDelay is the least time, in seconds, between two archives of
+	// pages on the same host; default 1, negative for no delay.
+	DomainDelay float64 `json:"domain_delay,omitempty"`
+	// AllowPrivate lets links to loopback, private-network and link-local
+	// addresses be archived. Off by default so a link in a note can't make
+	// NoteFlow probe the local network.
+	AllowPrivate bool `json:"allow_private,omitempty"`
+}
+
+// ArchiveMeta describes one archived snapshot. It is saved next to the
+// archive as <name>.json so the page can be captured again later.
+type ArchiveMeta struct {
+	URL        string    `json:"url"`
+	Title      string    `json:"title,omitempty"`
+	ArchivedAt time.Time `json:"archived_at"`
+	Format     string    `json:"format,omitempty"` // html, pdf, mhtml or warc
+	Notes      string    `json:"notes,omitempty"`
+	// Tags live in a <name>.tags file beside the archive, one per line,
+	// rather than in the .json.
+	Tags []string `json:"tags,omitempty"`
+}
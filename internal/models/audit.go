@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Audit actions. Create, update, delete and toggle describe a change to
+// one note; request is a change made through the API that touched no note
+// of the folder, e.g. an upload or a saved theme.
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditToggle  = "toggle"
+	AuditRequest = "request"
+)
+
+// AuditEntry records one change made through the API: who made it, with
+// which request, and a summary of the note before and after.
+type AuditEntry struct {
+	ID      int64     `json:"id"`
+	Time    time.Time `json:"time"`
+	IP      string    `json:"ip"`
+	Request string    `json:"request"` // method and path, e.g. "PUT /api/v1/notes/3"
+	Action  string    `json:"action"`
+	// NoteID and Title identify the note changed; see Note.HistoryKey.
+	NoteID string `json:"note_id,omitempty"`
+	Title  string `json:"title,omitempty"`
+	// Before and After hold the lines that changed, shortened: a created
+	// note has only After, a deleted one only Before.
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "os"
+
+// AuthConfig protects the UI and API. With neither a password nor a token
+// set, NoteFlow is open to anyone who can reach it, which is fine on
+// localhost but not beyond.
+type AuthConfig struct {
+	// Password is asked for on the login page; $NOTEFLOW_PASSWORD
+	// overrides it (see Config.WithEnv).
+	Password string `json:"password,omitempty"`
+	// Token is accepted as "Authorization: Bearer <token>" by scripts
+	// calling the API; $NOTEFLOW_API_TOKEN overrides it.
+	Token string `json:"token,omitempty"`
+	// SessionHours is how long a login lasts; default 168 (a week).
+	SessionHours int `json:"session_hours,omitempty"`
+}
+
+// ResolvedPassword returns the configured password or $NOTEFLOW_PASSWORD.
+func (a AuthConfig) ResolvedPassword() string {
+	if a.Password != "" {
+		return a.Password
+	}
+	return os.Getenv("NOTEFLOW_PASSWORD")
+}
+
+// ResolvedToken returns the configured token or $NOTEFLOW_API_TOKEN.
+func (a AuthConfig) ResolvedToken() string {
+	if a.Token != "" {
+		return a.Token
+	}
+	return os.Getenv("NOTEFLOW_API_TOKEN")
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// BackupsDir holds timestamped copies of notes.md, taken before saves that
+// change it. Like the note history it lives under assets, and starts with
+// a dot so it stays out of the file listings.
+const BackupsDir = "assets/.backups"
+
+// DefaultBackupKeep is how many backups are kept when the folder config
+// doesn't say.
+const DefaultBackupKeep = 20
+
+// Backup is a copy of notes.md in BackupsDir.
+type Backup struct {
+	Name  string    `json:"name"` // notes-YYYYMMDD-HHMMSS.md
+	Taken time.Time `json:"taken"`
+	Size  int64     `json:"size"`
+}
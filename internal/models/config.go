@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // Config represents the application configuration
@@ -14,6 +15,52 @@ type Config struct {
 	// inclusive range FontScaleMin..FontScaleMax (clamped on read). A value
 	// of 1.0 means "use the default font size."
 	FontScales map[string]float64 `json:"font_scales,omitempty"`
+	// Timezone is the IANA zone (e.g. "Europe/Berlin") used to resolve
+	// natural-language due dates like "tomorrow 5pm". Empty means the
+	// system zone.
+	Timezone string `json:"timezone,omitempty"`
+	// Notifications configures optional push channels (ntfy, Pushover).
+	Notifications NotificationsConfig `json:"notifications,omitempty"`
+	// GitHub holds credentials for the issue export/import integrations.
+	GitHub GitHubConfig `json:"github,omitempty"`
+	// Todoist holds credentials for two-way Todoist sync.
+	Todoist TodoistConfig `json:"todoist,omitempty"`
+	// Transcription turns uploaded voice notes into searchable text.
+	Transcription TranscriptionConfig `json:"transcription,omitempty"`
+	// AltText describes uploaded images for accessibility and search.
+	AltText AltTextConfig `json:"alt_text,omitempty"`
+	// Google holds OAuth credentials for the Google Tasks mirror.
+	Google GoogleConfig `json:"google,omitempty"`
+	// Digest emails a periodic summary of open and overdue tasks.
+	Digest DigestConfig `json:"digest,omitempty"`
+	// Jira holds API tokens for the Jira issue sync, per server.
+	Jira JiraConfig `json:"jira,omitempty"`
+	// Telegram runs a bot that captures notes and tasks from a phone.
+	Telegram TelegramConfig `json:"telegram,omitempty"`
+	// Slack answers the slash commands of a Slack app.
+	Slack SlackConfig `json:"slack,omitempty"`
+	// Archive tunes +URL website archiving.
+	Archive ArchiveConfig `json:"archive,omitempty"`
+	// LinkPreviews shows preview cards under plain URLs in notes.
+	LinkPreviews LinkPreviewConfig `json:"link_previews,omitempty"`
+	// Server sets the listen address and URL prefix.
+	Server ServerConfig `json:"server,omitempty"`
+	// Auth requires a password or API token for the UI and API.
+	Auth AuthConfig `json:"auth,omitempty"`
+	// Webhooks are called on note and task events.
+	Webhooks []WebhookConfig `json:"webhooks,omitempty"`
+	// Limits caps request rates and body sizes.
+	Limits LimitsConfig `json:"limits,omitempty"`
+	// DataDir is the folder to serve when NoteFlow isn't told one; empty
+	// means the current directory.
+	DataDir string `json:"data_dir,omitempty"`
+	// Log adds a log file and request logging.
+	Log LogConfig `json:"log,omitempty"`
+	// TaskDB is where the global task registry is kept: a file path, or
+	// a libsql server URL (libsql://, https://) to share it between
+	// machines, its token in $NOTEFLOW_TASK_DB_TOKEN. Empty means
+	// ~/.config/noteflow/tasks.db.
+	TaskDB string `json:"task_db,omitempty"`
 }
 
 // Font-scale clamps used by the API handler and the client UI.
@@ -81,6 +128,16 @@ func DefaultConfig() *Config {
 	}
 }
 
+// DefaultConfigPath returns the conventional location of the user config:
+// ~/.config/noteflow/noteflow.json.
+func DefaultConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "noteflow", "noteflow.json"), nil
+}
+
 // LoadConfig loads configuration from the given file path
 func LoadConfig(configPath string) (*Config, error) {
 	// Create config directory if it doesn't exist
@@ -111,20 +168,61 @@ func LoadConfig(configPath string) (*Config, error) {
 	return &config, nil
 }
 
-// SaveConfig saves configuration to the given file path
+// Location returns the zone named by Timezone, or time.Local when it is
+// empty.
+func (c Config) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(c.Timezone)
+}
+
+// SaveConfig saves configuration to the given file path. The file holds
+// passwords, tokens and OAuth secrets, so it is written readable by its
+// owner only, whatever its mode was: through a temp file that is renamed
+// over it, so a crash never leaves it half written.
 func SaveConfig(config *Config, configPath string) error {
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0644)
+	if target, err := filepath.EvalSymlinks(configPath); err == nil {
+		configPath = target
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(configPath), "."+filepath.Base(configPath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), configPath)
 }
 
 // NoteRequest represents a note creation/update request
 type NoteRequest struct {
 	Title   string `form:"title" json:"title"`
 	Content string `form:"content" json:"content"`
+	// Template names a note template to start from (create only).
+	Template string `form:"template" json:"template,omitempty"`
+	// Version is the note's Version when the edit started (update and
+	// merge only). An update is refused if the note has changed since;
+	// without it, updates overwrite.
+	Version string `form:"version" json:"version,omitempty"`
 }
 
 // APIResponse represents a standard API response
@@ -1,6 +1,10 @@
 package models
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 // Tests for the v1.4 per-section font-scale storage. Pins:
 //   - GetFontScale returns the default when nothing has been set
@@ -68,3 +72,25 @@ func TestDefaultConfig_PopulatesAllSections(t *testing.T) {
 		}
 	}
 }
+
+func TestSaveConfig_OwnerOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.Theme = "light-blue"
+	if err := SaveConfig(cfg, path); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("mode = %o, want 600", mode)
+	}
+	if loaded, err := LoadConfig(path); err != nil || loaded.Theme != "light-blue" {
+		t.Errorf("LoadConfig = %+v, %v", loaded, err)
+	}
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// DigestConfig enables the email digest of open, due-soon and overdue tasks
+// across all registered folders. Leave To empty to keep it off.
+//
+//	"digest": {
+//	  "to": ["me@example.com"],
+//	  "schedule": "weekly", "weekday": "monday", "hour": 7,
+//	  "smtp": {"host": "smtp.example.com", "port": 587,
+//	           "username": "me@example.com", "password": "app-password"}
+//	}
+type DigestConfig struct {
+	To   []string `json:"to,omitempty"`
+	From string   `json:"from,omitempty"` // default: smtp.username
+	// Schedule is "daily" (default) or "weekly".
+	Schedule string `json:"schedule,omitempty"`
+	// Weekday names the day weekly digests go out; default Monday.
+	Weekday string `json:"weekday,omitempty"`
+	// Hour is the local hour (0-23) the digest is sent at; default 7.
+	Hour *int `json:"hour,omitempty"`
+	// DueSoonDays is how far ahead "due soon" looks; default 3.
+	DueSoonDays int `json:"due_soon_days,omitempty"`
+
+	SMTP SMTPConfig `json:"smtp"`
+}
+
+// SMTPConfig is an outgoing mail server. Port 465 uses implicit TLS; any
+// other port (default 587) upgrades with STARTTLS when the server offers it.
+type SMTPConfig struct {
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"` // falls back to $NOTEFLOW_SMTP_PASSWORD
+}
+
+// ResolvedPassword returns the configured password or $NOTEFLOW_SMTP_PASSWORD.
+func (s SMTPConfig) ResolvedPassword() string {
+	if s.Password != "" {
+		return s.Password
+	}
+	return os.Getenv("NOTEFLOW_SMTP_PASSWORD")
+}
+
+// Enabled reports whether a digest should be sent at all.
+func (d DigestConfig) Enabled() bool {
+	return len(d.To) > 0 && d.SMTP.Host != ""
+}
+
+// Weekly reports whether the digest goes out once a week instead of daily.
+func (d DigestConfig) Weekly() bool {
+	return strings.EqualFold(d.Schedule, "weekly")
+}
+
+// SendWeekday is the day weekly digests go out.
+func (d DigestConfig) SendWeekday() time.Weekday {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if strings.EqualFold(d.Weekday, wd.String()) || strings.EqualFold(d.Weekday, wd.String()[:3]) {
+			return wd
+		}
+	}
+	return time.Monday
+}
+
+// SendHour is the local hour the digest goes out.
+func (d DigestConfig) SendHour() int {
+	if d.Hour == nil || *d.Hour < 0 || *d.Hour > 23 {
+		return 7
+	}
+	return *d.Hour
+}
+
+// DueSoonWindow is how many days ahead count as due soon.
+func (d DigestConfig) DueSoonWindow() int {
+	if d.DueSoonDays <= 0 {
+		return 3
+	}
+	return d.DueSoonDays
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// MatchesDueFilter reports whether a task with the given due date (nil for
+// none) and completion state passes a due filter. Filters:
+//
+//	today      due is today
+//	week       due within the next 7 days (today inclusive)
+//	overdue    due strictly before today and not done
+//	none       no due date
+//	YYYY-MM-DD due is exactly that date
+//
+// today is midnight of the current day in the caller's zone. Tasks with
+// no due date only pass "none".
+func MatchesDueFilter(filter string, due *time.Time, done bool, today time.Time) (bool, error) {
+	if filter == "none" {
+		return due == nil, nil
+	}
+	var exactDay time.Time
+	switch filter {
+	case "today", "week", "overdue":
+	default:
+		exact, err := time.Parse("2006-01-02", filter)
+		if err != nil {
+			return false, fmt.Errorf("invalid due filter %q (want today|week|overdue|none|YYYY-MM-DD)", filter)
+		}
+		exactDay = time.Date(exact.Year(), exact.Month(), exact.Day(), 0, 0, 0, 0, today.Location())
+	}
+	if due == nil {
+		return false, nil
+	}
+	d := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, today.Location())
+	switch filter {
+	case "today":
+		return d.Equal(today), nil
+	case "week":
+		end := today.AddDate(0, 0, 7)
+		return !d.Before(today) && d.Before(end), nil
+	case "overdue":
+		return d.Before(today) && !done, nil
+	}
+	return d.Equal(exactDay), nil
+}
+
+// IsOverdue reports whether an open task with the given due date is past
+// due at now. A date-only due date (midnight) is overdue from the next
+// day on; one with a time of day is overdue once that moment has passed.
+func IsOverdue(due time.Time, done bool, now time.Time) bool {
+	if due.IsZero() || done {
+		return false
+	}
+	if due.Hour() != 0 || due.Minute() != 0 {
+		return due.Before(now)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, now.Location()).Before(today)
+}
+
+// FormatDueValue renders a parsed due date the way it is stored outside
+// notes.md (the task DB): "YYYY-MM-DD", or "YYYY-MM-DDTHH:MM" when it has
+// a time of day. Both sort as text. The zero time yields "".
+func FormatDueValue(due time.Time) string {
+	if due.IsZero() {
+		return ""
+	}
+	if due.Hour() != 0 || due.Minute() != 0 {
+		return due.Format("2006-01-02T15:04")
+	}
+	return due.Format("2006-01-02")
+}
+
+// ParseDueValue is the inverse of FormatDueValue.
+func ParseDueValue(v string) time.Time {
+	return parseDueToken(v)
+}
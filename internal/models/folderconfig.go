@@ -0,0 +1,296 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FolderConfigFile is the per-project settings file, stored next to
+// notes.md. Unlike ~/.config/noteflow/noteflow.json it travels with the
+// repo, so it must never hold secrets — tokens stay in the user config.
+const FolderConfigFile = ".noteflow.json"
+
+// FolderConfig holds settings that differ between project folders.
+type FolderConfig struct {
+	GitHub  *GitHubFolderConfig  `json:"github,omitempty"`
+	Todoist *TodoistFolderConfig `json:"todoist,omitempty"`
+	// GoogleTasks mirrors tasks into a Google Tasks list.
+	GoogleTasks *GoogleTasksFolderConfig `json:"google_tasks,omitempty"`
+	// Jira creates issues from tasks and pulls their status back.
+	Jira *JiraFolderConfig `json:"jira,omitempty"`
+	// Spellcheck picks the dictionary and holds the folder's own words.
+	Spellcheck *SpellcheckFolderConfig `json:"spellcheck,omitempty"`
+	// Trash controls how long deleted notes are kept.
+	Trash *TrashFolderConfig `json:"trash,omitempty"`
+	// Backups controls the timestamped copies of notes.md.
+	Backups *BackupsFolderConfig `json:"backups,omitempty"`
+	// Git commits the notes to the folder's repository and syncs them.
+	Git *GitFolderConfig `json:"git,omitempty"`
+	// Storage is where the notes are kept: NotesStorageMarkdown (the
+	// default), NotesStorageSQLite, NotesStorageFiles,
+	// NotesStorageEncrypted or NotesStorageWebDAV. `noteflow storage`
+	// switches it.
+	Storage string `json:"storage,omitempty"`
+	// Encryption holds what the encrypted storage mode needs, apart from
+	// the passphrase.
+	Encryption *EncryptionFolderConfig `json:"encryption,omitempty"`
+	// WebDAV is the server the WebDAV storage mode keeps notes.md on.
+	WebDAV *WebDAVFolderConfig `json:"webdav,omitempty"`
+	// S3 keeps uploads and archived sites in a bucket instead of assets/.
+	S3 *S3FolderConfig `json:"s3,omitempty"`
+	// SaveDelaySeconds holds changes in memory for this long after the
+	// last one before writing the notes, so a burst of checkbox clicks
+	// is one write instead of many. Zero writes every change at once.
+	SaveDelaySeconds int `json:"save_delay_seconds,omitempty"`
+}
+
+// SaveDelay returns how long changes wait before the notes are written.
+func (c *FolderConfig) SaveDelay() time.Duration {
+	return time.Duration(max(c.SaveDelaySeconds, 0)) * time.Second
+}
+
+// Notes storage modes.
+const (
+	NotesStorageMarkdown  = "markdown"  // notes.md
+	NotesStorageSQLite    = "sqlite"    // notes.db, one row per note
+	NotesStorageFiles     = "files"     // notes/, one markdown file per note
+	NotesStorageEncrypted = "encrypted" // notes.md.enc, notes.md sealed with a passphrase
+	NotesStorageWebDAV    = "webdav"    // notes.md on a WebDAV server, cached locally
+)
+
+// EncryptionFolderConfig is the encrypted storage mode's key derivation
+// salt. It is not secret: the key comes from it and the passphrase, which
+// is never stored.
+type EncryptionFolderConfig struct {
+	Salt string `json:"salt"` // base64
+}
+
+// WebDAVFolderConfig points the WebDAV storage mode at the remote
+// notes.md. The password comes from NOTEFLOW_WEBDAV_PASSWORD, never from
+// this file.
+type WebDAVFolderConfig struct {
+	// URL is the file itself, e.g.
+	// "https://cloud.example.com/remote.php/dav/files/ana/work/notes.md".
+	URL      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	// PollSeconds sets how often the server is checked for edits made
+	// elsewhere (default 30).
+	PollSeconds int `json:"poll_seconds,omitempty"`
+}
+
+// DefaultWebDAVPollSeconds is how often a WebDAV folder is checked for
+// remote edits when the folder config doesn't say.
+const DefaultWebDAVPollSeconds = 30
+
+// S3FolderConfig points a folder's assets at an S3-compatible bucket. The
+// credentials come from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY,
+// never from this file.
+type S3FolderConfig struct {
+	Bucket string `json:"bucket"`
+	Region string `json:"region,omitempty"` // default "us-east-1"
+	// Endpoint is the server for other providers than AWS, e.g.
+	// "https://minio.example.com" or an R2 account URL.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Prefix is prepended to every key, so folders can share a bucket.
+	Prefix string `json:"prefix,omitempty"`
+	// PathStyle puts the bucket in the path rather than the host name,
+	// as MinIO and most self-hosted servers need.
+	PathStyle bool `json:"path_style,omitempty"`
+	// URLMinutes is how long the signed links assets are served through
+	// stay valid (default 60).
+	URLMinutes int `json:"url_minutes,omitempty"`
+}
+
+// S3Region returns the bucket's region, applying the default.
+func (c *S3FolderConfig) S3Region() string {
+	if c.Region == "" {
+		return "us-east-1"
+	}
+	return c.Region
+}
+
+// URLExpiry returns how long a signed asset link stays valid.
+func (c *S3FolderConfig) URLExpiry() time.Duration {
+	if c.URLMinutes > 0 {
+		return time.Duration(c.URLMinutes) * time.Minute
+	}
+	return time.Hour
+}
+
+// RemotePollInterval returns how often the notes are checked for edits
+// made on another machine: zero unless they are kept on a server.
+func (c *FolderConfig) RemotePollInterval() time.Duration {
+	if c.NotesStorage() != NotesStorageWebDAV || c.WebDAV == nil {
+		return 0
+	}
+	if c.WebDAV.PollSeconds > 0 {
+		return time.Duration(c.WebDAV.PollSeconds) * time.Second
+	}
+	return DefaultWebDAVPollSeconds * time.Second
+}
+
+// NotesStorage returns the folder's storage mode, applying the default.
+func (c *FolderConfig) NotesStorage() string {
+	if c.Storage == "" {
+		return NotesStorageMarkdown
+	}
+	return c.Storage
+}
+
+// GitHubFolderConfig routes a folder's tasks to a GitHub repository.
+type GitHubFolderConfig struct {
+	Repo   string   `json:"repo"`             // "owner/name"
+	Labels []string `json:"labels,omitempty"` // applied to every exported issue
+	// CloseOnComplete closes the linked issue when its task is checked.
+	CloseOnComplete bool `json:"close_on_complete,omitempty"`
+	// Import lists repos whose open issues assigned to the token owner are
+	// mirrored as tasks into the ImportNote note.
+	Import []string `json:"import,omitempty"`
+	// ImportNote is the title of the note that holds imported issues.
+	// Defaults to DefaultGitHubImportNote.
+	ImportNote string `json:"import_note,omitempty"`
+	// ImportIntervalMinutes sets how often imports refresh (default 10).
+	ImportIntervalMinutes int `json:"import_interval_minutes,omitempty"`
+}
+
+// DefaultGitHubImportNote is the note title used for imported issues when
+// the folder config doesn't name one.
+const DefaultGitHubImportNote = "GitHub Issues"
+
+// TodoistFolderConfig maps a folder's tasks to a Todoist project.
+type TodoistFolderConfig struct {
+	ProjectID string `json:"project_id"`
+	// Tag limits the sync to tasks carrying #Tag. Empty syncs every task.
+	Tag string `json:"tag,omitempty"`
+	// IntervalMinutes sets how often the sync runs (default 5).
+	IntervalMinutes int `json:"interval_minutes,omitempty"`
+}
+
+// GoogleTasksFolderConfig picks the Google Tasks list a folder mirrors to.
+type GoogleTasksFolderConfig struct {
+	// ListID is the target task list; empty means the user's default list.
+	ListID string `json:"list_id,omitempty"`
+	// Tag limits the mirror to tasks carrying #Tag. Empty mirrors every task.
+	Tag string `json:"tag,omitempty"`
+	// IntervalMinutes sets how often the mirror runs (default 5).
+	IntervalMinutes int `json:"interval_minutes,omitempty"`
+}
+
+// JiraFolderConfig maps a tag or section of a folder's tasks to a Jira
+// project. The API token is looked up by URL in the user config.
+type JiraFolderConfig struct {
+	URL     string `json:"url"`     // server, e.g. "https://acme.atlassian.net"
+	Project string `json:"project"` // project key, e.g. "NF"
+	// Tag selects tasks carrying #Tag; Section selects tasks under a
+	// heading with this text. When both are set a task needs both.
+	Tag     string `json:"tag,omitempty"`
+	Section string `json:"section,omitempty"`
+	// IssueType names the type of created issues (default "Task").
+	IssueType string `json:"issue_type,omitempty"`
+	// Labels are applied to every created issue.
+	Labels []string `json:"labels,omitempty"`
+	// IntervalMinutes sets how often the sync runs (default 5).
+	IntervalMinutes int `json:"interval_minutes,omitempty"`
+}
+
+// SpellcheckFolderConfig configures /api/spellcheck for a folder.
+type SpellcheckFolderConfig struct {
+	// Language names a bundled dictionary (default "en").
+	Language string `json:"language,omitempty"`
+	// Words are accepted in addition to the dictionary: names, jargon and
+	// anything else added with "Add to dictionary".
+	Words []string `json:"words,omitempty"`
+}
+
+// TrashFolderConfig configures trash.md for a folder.
+type TrashFolderConfig struct {
+	// RetentionDays is how long deleted notes are kept before they are
+	// purged (default DefaultTrashRetentionDays). Negative keeps them
+	// until they are purged by hand.
+	RetentionDays int `json:"retention_days,omitempty"`
+}
+
+// TrashRetentionDays returns the folder's trash retention, applying the
+// default. Zero or less means never purge automatically.
+func (c *FolderConfig) TrashRetentionDays() int {
+	if c.Trash == nil || c.Trash.RetentionDays == 0 {
+		return DefaultTrashRetentionDays
+	}
+	return max(c.Trash.RetentionDays, 0)
+}
+
+// BackupsFolderConfig configures the backups of notes.md in BackupsDir.
+type BackupsFolderConfig struct {
+	// Keep is how many backups are kept, oldest deleted first (default
+	// DefaultBackupKeep). Negative turns backups off.
+	Keep int `json:"keep,omitempty"`
+	// IntervalMinutes is the least time between two backups. Zero backs
+	// up before every save that changes notes.md.
+	IntervalMinutes int `json:"interval_minutes,omitempty"`
+}
+
+// BackupKeep returns how many backups the folder keeps, applying the
+// default. Zero means backups are off.
+func (c *FolderConfig) BackupKeep() int {
+	if c.Backups == nil || c.Backups.Keep == 0 {
+		return DefaultBackupKeep
+	}
+	return max(c.Backups.Keep, 0)
+}
+
+// BackupInterval returns the least time between two backups.
+func (c *FolderConfig) BackupInterval() time.Duration {
+	if c.Backups == nil || c.Backups.IntervalMinutes < 0 {
+		return 0
+	}
+	return time.Duration(c.Backups.IntervalMinutes) * time.Minute
+}
+
+// GitFolderConfig commits a folder's notes, trash, templates and assets to
+// the git repository it is in. Push and pull use git's own credentials
+// (SSH keys, credential helpers); none are stored here.
+type GitFolderConfig struct {
+	// AutoCommit commits after each change, a few seconds after the last
+	// one in a burst.
+	AutoCommit bool `json:"auto_commit,omitempty"`
+	// Remote and Branch are where pushes and pulls go (default "origin"
+	// and the current branch).
+	Remote string `json:"remote,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	// SyncMinutes pulls and pushes this often. Zero syncs only when asked
+	// to through the API.
+	SyncMinutes int `json:"sync_minutes,omitempty"`
+}
+
+// DefaultGitRemote is the remote pushes and pulls go to when the folder
+// config doesn't name one.
+const DefaultGitRemote = "origin"
+
+// LoadFolderConfig reads basePath/.noteflow.json. A missing file is not an
+// error — it yields an empty config.
+func LoadFolderConfig(basePath string) (*FolderConfig, error) {
+	data, err := os.ReadFile(filepath.Join(basePath, FolderConfigFile))
+	if os.IsNotExist(err) {
+		return &FolderConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cfg FolderConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", FolderConfigFile, err)
+	}
+	return &cfg, nil
+}
+
+// SaveFolderConfig writes cfg to basePath/.noteflow.json.
+func SaveFolderConfig(basePath string, cfg *FolderConfig) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(basePath, FolderConfigFile), append(data, '\n'), 0644)
+}
@@ -0,0 +1,205 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// frontmatterKeyRE matches a "key: value" line of a frontmatter block.
+var frontmatterKeyRE = regexp.MustCompile(`^([A-Za-z0-9_][A-Za-z0-9_.-]*)[ \t]*:(?:[ \t]+(.*))?$`)
+
+// ParseFrontmatter reads an optional YAML frontmatter block at the top of
+// a note's content:
+//
+//	---
+//	status: draft
+//	due: 2026-11-01
+//	tags: [planning, q4]
+//	---
+//
+// Only the flat subset notes need is understood: scalar values, flow
+// lists ([a, b]) and block lists ("- a" lines under an empty key). Lists
+// are joined with ", ". A block containing anything else, or no keys at
+// all, is not treated as frontmatter, so a note that merely opens with a
+// horizontal rule is left alone. end is the byte offset where the body
+// starts; ok is false when there is no frontmatter.
+func ParseFrontmatter(content string) (meta map[string]string, end int, ok bool) {
+	rest, found := strings.CutPrefix(content, "---\n")
+	if !found {
+		return nil, 0, false
+	}
+	pos := len(content) - len(rest)
+	meta = make(map[string]string)
+	lastKey := ""
+	var list []string
+	flush := func() {
+		if lastKey != "" && list != nil {
+			meta[lastKey] = strings.Join(list, ", ")
+		}
+		list = nil
+	}
+
+	for rest != "" {
+		line, next, _ := strings.Cut(rest, "\n")
+		lineEnd := pos + len(line) + 1
+		if lineEnd > len(content) {
+			lineEnd = len(content)
+		}
+		rest, pos = next, lineEnd
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case line == "---" || line == "...":
+			flush()
+			if len(meta) == 0 {
+				return nil, 0, false
+			}
+			return meta, lineEnd, true
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(trimmed, "- ") || trimmed == "-":
+			if lastKey == "" || meta[lastKey] != "" {
+				return nil, 0, false
+			}
+			list = append(list, unquoteYAML(strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))))
+			continue
+		}
+
+		m := frontmatterKeyRE.FindStringSubmatch(line)
+		if m == nil {
+			return nil, 0, false
+		}
+		flush()
+		lastKey = m[1]
+		meta[lastKey] = yamlValue(strings.TrimSpace(m[2]))
+	}
+	return nil, 0, false // never closed
+}
+
+// yamlLineRE matches a line of YAML that isn't prose: a "key:" line,
+// with any key, or an indented line.
+var yamlLineRE = regexp.MustCompile(`^(?:[^\s:#-][^:]*:(?:[ \t]|$)|[ \t])`)
+
+// LooseFrontmatter reads a frontmatter block as ParseFrontmatter does,
+// but skips what ParseFrontmatter rejects instead of giving up on the
+// block: nested maps, block scalars (| and >) and keys that aren't plain
+// words. It is for notes imported from apps with richer YAML. A block
+// with a line that isn't YAML-shaped is still not frontmatter, so prose
+// between two horizontal rules isn't mistaken for one; a block with only
+// unreadable keys is, with no metadata.
+func LooseFrontmatter(content string) (meta map[string]string, end int, ok bool) {
+	if meta, end, ok := ParseFrontmatter(content); ok {
+		return meta, end, ok
+	}
+	rest, found := strings.CutPrefix(content, "---\n")
+	if !found {
+		return nil, 0, false
+	}
+	pos := len(content) - len(rest)
+	meta = make(map[string]string)
+	// key is the top-level key whose list items or nested lines follow,
+	// nested whether it turned out to hold a map.
+	key, nested := "", false
+	var list []string
+	flush := func() {
+		if key != "" && nested {
+			delete(meta, key)
+		} else if key != "" && list != nil {
+			meta[key] = strings.Join(list, ", ")
+		}
+		key, nested, list = "", false, nil
+	}
+
+	for rest != "" {
+		line, next, _ := strings.Cut(rest, "\n")
+		lineEnd := min(pos+len(line)+1, len(content))
+		rest, pos = next, lineEnd
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case line == "---" || line == "...":
+			flush()
+			return meta, lineEnd, true
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(line, "- ") || line == "-" || line[0] == ' ' || line[0] == '\t':
+			if key == "" || meta[key] != "" {
+				continue // under a skipped key or a block scalar
+			}
+			if item, isItem := strings.CutPrefix(trimmed, "-"); isItem && !nested && !strings.Contains(item, ": ") {
+				list = append(list, unquoteYAML(strings.TrimSpace(item)))
+			} else {
+				nested = true
+			}
+			continue
+		case !yamlLineRE.MatchString(line):
+			return nil, 0, false
+		}
+
+		flush()
+		m := frontmatterKeyRE.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		value := strings.TrimSpace(m[2])
+		if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			continue
+		}
+		key = m[1]
+		meta[key] = yamlValue(value)
+	}
+	return nil, 0, false // never closed
+}
+
+// yamlValue converts a scalar or flow list to its string form.
+func yamlValue(v string) string {
+	if i := strings.Index(v, " #"); i >= 0 && !strings.HasPrefix(v, `"`) && !strings.HasPrefix(v, "'") {
+		v = strings.TrimSpace(v[:i]) // trailing comment
+	}
+	if strings.HasPrefix(v, "[") && strings.HasSuffix(v, "]") {
+		var items []string
+		for _, item := range strings.Split(v[1:len(v)-1], ",") {
+			if item = unquoteYAML(strings.TrimSpace(item)); item != "" {
+				items = append(items, item)
+			}
+		}
+		return strings.Join(items, ", ")
+	}
+	return unquoteYAML(v)
+}
+
+// unquoteYAML strips matching single or double quotes.
+func unquoteYAML(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// RenderFrontmatter writes meta as a frontmatter block ParseFrontmatter
+// reads back, keys sorted and values quoted. Keys must be plain words and
+// values single lines.
+func RenderFrontmatter(meta map[string]string) (string, error) {
+	keys := make([]string, 0, len(meta))
+	for key, value := range meta {
+		if !frontmatterKeyRE.MatchString(key + ":") {
+			return "", fmt.Errorf("invalid frontmatter key %q", key)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return "", fmt.Errorf("frontmatter value of %q spans lines", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString("---\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s: \"%s\"\n", key, meta[key])
+	}
+	b.WriteString("---\n")
+	return b.String(), nil
+}
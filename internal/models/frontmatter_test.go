@@ -0,0 +1,84 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFrontmatter(t *testing.T) {
+	content := "---\nstatus: draft\ndue: \"2026-11-01\" \ntags: [planning, 'q4']\n# a comment\nowners:\n  - ana\n  - raj\n---\nBody"
+	meta, end, ok := ParseFrontmatter(content)
+	if !ok {
+		t.Fatal("frontmatter not found")
+	}
+	want := map[string]string{"status": "draft", "due": "2026-11-01", "tags": "planning, q4", "owners": "ana, raj"}
+	if !reflect.DeepEqual(meta, want) {
+		t.Errorf("meta = %v, want %v", meta, want)
+	}
+	if content[end:] != "Body" {
+		t.Errorf("body = %q", content[end:])
+	}
+}
+
+func TestParseFrontmatter_NotFrontmatter(t *testing.T) {
+	for _, content := range []string{
+		"Body\n---\nstatus: draft\n---",
+		"---\nJust a rule above some prose.\n---\n",
+		"---\n---\nTwo rules",
+		"---\nstatus: draft\nnever closed",
+	} {
+		if _, _, ok := ParseFrontmatter(content); ok {
+			t.Errorf("ParseFrontmatter(%q) found frontmatter", content)
+		}
+	}
+}
+
+func TestLooseFrontmatter(t *testing.T) {
+	content := "---\ntitle: Plan\naliases:\n  - plan v2\n  - \"the plan\"\ncssclasses: [wide]\nlinks:\n  repo: x\n  docs: y\n" +
+		"people:\n  - name: ana\nsummary: |\n  two\n  lines\nmy key: dropped\n---\nBody"
+	meta, end, ok := LooseFrontmatter(content)
+	if !ok {
+		t.Fatal("frontmatter not found")
+	}
+	want := map[string]string{"title": "Plan", "aliases": "plan v2, the plan", "cssclasses": "wide"}
+	if !reflect.DeepEqual(meta, want) {
+		t.Errorf("meta = %v, want %v", meta, want)
+	}
+	if content[end:] != "Body" {
+		t.Errorf("body = %q", content[end:])
+	}
+	if _, _, ok := LooseFrontmatter("---\nJust a rule above some prose.\n---\n"); ok {
+		t.Error("prose between rules read as frontmatter")
+	}
+}
+
+func TestNoteMetadataRoundTrip(t *testing.T) {
+	text := "## 2026-10-16 09:00:00 - Plan\n\n---\nstatus: draft\n---\n- [ ] write it"
+	note, err := NewNoteFromText(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if note.Metadata["status"] != "draft" || len(note.Tasks) != 1 {
+		t.Errorf("metadata = %v, tasks = %d", note.Metadata, len(note.Tasks))
+	}
+	if got := note.Render(); got != text+"\n" {
+		t.Errorf("Render = %q", got)
+	}
+}
+
+func TestRenderFrontmatter(t *testing.T) {
+	meta := map[string]string{"status": "draft: #2", "source": `say "hi"`, "due": ""}
+	block, err := RenderFrontmatter(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, end, ok := ParseFrontmatter(block + "Body")
+	if !ok || !reflect.DeepEqual(got, meta) || block[end:] != "" {
+		t.Errorf("round trip of %q = %v, %v", block, got, ok)
+	}
+	for _, bad := range []map[string]string{{"two words": "x"}, {"note": "a\nb"}} {
+		if _, err := RenderFrontmatter(bad); err == nil {
+			t.Errorf("RenderFrontmatter(%v) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitHubConfig holds the user-level GitHub credentials. Per-folder routing
+// (which repo, which labels) lives in FolderConfig instead.
+type GitHubConfig struct {
+	Token string `json:"token,omitempty"`
+	// API overrides the REST base URL for GitHub Enterprise.
+	API string `json:"api,omitempty"`
+}
+
+// ResolvedToken returns the configured token, falling back to the
+// GITHUB_TOKEN environment variable that most developer machines already
+// export for the gh CLI.
+func (g GitHubConfig) ResolvedToken() string {
+	if g.Token != "" {
+		return g.Token
+	}
+	return os.Getenv("GITHUB_TOKEN")
+}
+
+// TodoistConfig holds the user-level Todoist credentials. Which project a
+// folder syncs with lives in FolderConfig.
+type TodoistConfig struct {
+	Token string `json:"token,omitempty"`
+	// API overrides the REST base URL; only useful for testing.
+	API string `json:"api,omitempty"`
+}
+
+// ResolvedToken returns the configured token, falling back to the
+// TODOIST_API_TOKEN environment variable.
+func (t TodoistConfig) ResolvedToken() string {
+	if t.Token != "" {
+		return t.Token
+	}
+	return os.Getenv("TODOIST_API_TOKEN")
+}
+
+// JiraConfig holds the user-level Jira credentials, keyed by server URL
+// ("https://acme.atlassian.net") so folders synced with different Jira
+// sites each get the right token. Which server and project a folder syncs
+// with lives in FolderConfig.
+type JiraConfig struct {
+	Sites map[string]JiraCredentials `json:"sites,omitempty"`
+}
+
+// JiraCredentials authenticate against one Jira server. With an Email the
+// token is a Jira Cloud API token sent as basic auth; without one it is a
+// Server/Data Center personal access token sent as a bearer token.
+type JiraCredentials struct {
+	Email string `json:"email,omitempty"`
+	Token string `json:"token,omitempty"`
+}
+
+// Credentials returns the credentials for serverURL, falling back to the
+// JIRA_EMAIL and JIRA_API_TOKEN environment variables.
+func (j JiraConfig) Credentials(serverURL string) JiraCredentials {
+	if c, ok := j.Sites[strings.TrimRight(serverURL, "/")]; ok && c.Token != "" {
+		return c
+	}
+	return JiraCredentials{Email: os.Getenv("JIRA_EMAIL"), Token: os.Getenv("JIRA_API_TOKEN")}
+}
+
+// GoogleConfig holds the OAuth client and the refresh token obtained by
+// `noteflow-go google-auth`. The client ID and secret come from a
+// "Desktop app" OAuth client the user creates in Google Cloud Console.
+type GoogleConfig struct {
+	ClientID     string `json:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// TasksAPI overrides the Google Tasks base URL; only useful for testing.
+	TasksAPI string `json:"tasks_api,omitempty"`
+}
+
+// Authorized reports whether the OAuth flow has been completed.
+func (g GoogleConfig) Authorized() bool {
+	return g.ClientID != "" && g.RefreshToken != ""
+}
+
+// TelegramConfig runs a Telegram bot for quick capture: messages to it
+// become notes or tasks, and /tasks lists the open ones. Only the chats
+// listed may use it; the bot tells any other chat its ID to add.
+//
+//	"telegram": {"token": "123456:ABC-DEF", "chats": [123456789], "folder": "/home/me/notes"}
+type TelegramConfig struct {
+	Token string  `json:"token,omitempty"` // from @BotFather
+	Chats []int64 `json:"chats,omitempty"`
+	// Folder is the notes folder that takes the captures; its NoteFlow
+	// server runs the bot, as only one program may poll it. Empty means
+	// the folder of whichever server is started.
+	Folder string `json:"folder,omitempty"`
+	// API overrides the Bot API base URL; only useful for testing.
+	API string `json:"api,omitempty"`
+}
+
+// ResolvedToken returns the configured token, falling back to the
+// TELEGRAM_BOT_TOKEN environment variable.
+func (t TelegramConfig) ResolvedToken() string {
+	if t.Token != "" {
+		return t.Token
+	}
+	return os.Getenv("TELEGRAM_BOT_TOKEN")
+}
+
+// Allowed reports whether chat may use the bot.
+func (t TelegramConfig) Allowed(chat int64) bool {
+	for _, c := range t.Chats {
+		if c == chat {
+			return true
+		}
+	}
+	return false
+}
+
+// RunsIn reports whether the server of folder, an absolute path, runs the
+// bot: Folder is empty or names it, a leading "~" standing for home.
+func (t TelegramConfig) RunsIn(folder string) bool {
+	dir := t.Folder
+	if dir == "" {
+		return true
+	}
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return false
+		}
+		dir = filepath.Join(home, dir[1:])
+	}
+	abs, err := filepath.Abs(dir)
+	return err == nil && abs == filepath.Clean(folder)
+}
+
+// SlackConfig lets a Slack app's slash commands reach NoteFlow at
+// /slack/command: /note adds a note and /tasks lists the open tasks.
+// Requests are checked against the app's signing secret, from its "Basic
+// Information" page. Alerts to a Slack channel are configured under
+// notifications instead.
+//
+//	"slack": {"signing_secret": "8f742231b10e8888abcd99yyyzzz85a5"}
+type SlackConfig struct {
+	SigningSecret string `json:"signing_secret,omitempty"`
+}
+
+// ResolvedSigningSecret returns the configured signing secret, falling
+// back to the SLACK_SIGNING_SECRET environment variable.
+func (s SlackConfig) ResolvedSigningSecret() string {
+	if s.SigningSecret != "" {
+		return s.SigningSecret
+	}
+	return os.Getenv("SLACK_SIGNING_SECRET")
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LogConfig sets where the server logs go.
+type LogConfig struct {
+	// File receives the log as well as stderr, appended to.
+	File string `json:"file,omitempty"`
+	// Requests logs every HTTP request: method, path, status and time.
+	Requests bool `json:"requests,omitempty"`
+}
+
+// envSettings maps NOTEFLOW_* variables to the setting each overrides.
+// The server settings are read by ServerConfigFromEnv.
+var envSettings = []struct {
+	name  string
+	field func(c *Config) any // pointer to a string, int or bool
+}{
+	{"NOTEFLOW_DIR", func(c *Config) any { return &c.DataDir }},
+	{"NOTEFLOW_TIMEZONE", func(c *Config) any { return &c.Timezone }},
+	{"NOTEFLOW_LOG_FILE", func(c *Config) any { return &c.Log.File }},
+	{"NOTEFLOW_LOG_REQUESTS", func(c *Config) any { return &c.Log.Requests }},
+	{"NOTEFLOW_PASSWORD", func(c *Config) any { return &c.Auth.Password }},
+	{"NOTEFLOW_API_TOKEN", func(c *Config) any { return &c.Auth.Token }},
+	{"NOTEFLOW_SESSION_HOURS", func(c *Config) any { return &c.Auth.SessionHours }},
+	{"NOTEFLOW_ARCHIVE_FORMAT", func(c *Config) any { return &c.Archive.Format }},
+	{"NOTEFLOW_ARCHIVE_READER", func(c *Config) any { return &c.Archive.Reader }},
+	{"NOTEFLOW_CHROME_PATH", func(c *Config) any { return &c.Archive.ChromePath }},
+	{"NOTEFLOW_ARCHIVE_ALLOW_PRIVATE", func(c *Config) any { return &c.Archive.AllowPrivate }},
+	{"NOTEFLOW_REQUESTS_PER_MINUTE", func(c *Config) any { return &c.Limits.RequestsPerMinute }},
+	{"NOTEFLOW_UPLOAD_MB", func(c *Config) any { return &c.Limits.UploadMB }},
+	{"NOTEFLOW_BODY_MB", func(c *Config) any { return &c.Limits.BodyMB }},
+	{"NOTEFLOW_TASK_DB", func(c *Config) any { return &c.TaskDB }},
+}
+
+// WithEnv returns c with the settings given in NOTEFLOW_* environment
+// variables applied over it. Unset or empty variables change nothing.
+func (c Config) WithEnv() (Config, error) {
+	server, err := ServerConfigFromEnv()
+	if err != nil {
+		return c, err
+	}
+	c.Server = c.Server.Merge(server)
+	for _, s := range envSettings {
+		v := os.Getenv(s.name)
+		if v == "" {
+			continue
+		}
+		if err := setSetting(s.field(&c), v); err != nil {
+			return c, fmt.Errorf("%s: %w", s.name, err)
+		}
+	}
+	return c, nil
+}
+
+func setSetting(field any, v string) error {
+	switch p := field.(type) {
+	case *string:
+		*p = v
+	case *int:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", v)
+		}
+		*p = n
+	case *bool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid value %q", v)
+		}
+		*p = b
+	default:
+		return fmt.Errorf("unsupported setting type %T", field)
+	}
+	return nil
+}
+
+// Override returns c with the settings the command line can give, taken
+// from flags where set: the server settings, the data directory and
+// logging.
+func (c Config) Override(flags Config) Config {
+	c.Server = c.Server.Merge(flags.Server)
+	if flags.DataDir != "" {
+		c.DataDir = flags.DataDir
+	}
+	if flags.Log.File != "" {
+		c.Log.File = flags.Log.File
+	}
+	c.Log.Requests = c.Log.Requests || flags.Log.Requests
+	return c
+}
+
+// ResolveDataDir returns the folder to serve: DataDir, with a leading "~"
+// expanded, or workingDir when it is unset.
+func (c Config) ResolveDataDir(workingDir string) (string, error) {
+	dir := c.DataDir
+	if dir == "" {
+		return workingDir, nil
+	}
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, dir[1:])
+	}
+	return filepath.Abs(dir)
+}
+
+// secretKeyRE matches the config keys whose values are credentials.
+var secretKeyRE = regexp.MustCompile(`(?i)password|token|secret|(^|_)key$`)
+
+// Redacted returns c as a JSON object with every credential replaced by
+// "***", for showing the settings in effect. Webhook URLs count as
+// credentials, since many services put the secret in the URL.
+func (c Config) Redacted() (map[string]any, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	redact(m, "", false)
+	return m, nil
+}
+
+// redact replaces the credentials in v, a decoded JSON value under the
+// key parent. Everything under a secret key is a credential.
+func redact(v any, parent string, secret bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			v[k] = redact(child, k, secret || secretKeyRE.MatchString(k) || (parent == "webhooks" && k == "url") || k == "webhook_url")
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = redact(child, parent, secret)
+		}
+	case string:
+		if secret && v != "" {
+			return "***"
+		}
+	}
+	return v
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigLayering(t *testing.T) {
+	file := Config{
+		Server:  ServerConfig{Host: "0.0.0.0", Port: 8080},
+		Archive: ArchiveConfig{Format: "pdf"},
+		Auth:    AuthConfig{Password: "from-file"},
+		DataDir: "/notes/file",
+	}
+	t.Setenv("NOTEFLOW_PORT", "9000")
+	t.Setenv("NOTEFLOW_PASSWORD", "from-env")
+	t.Setenv("NOTEFLOW_ARCHIVE_READER", "true")
+	t.Setenv("NOTEFLOW_UPLOAD_MB", "10")
+	t.Setenv("NOTEFLOW_DIR", "/notes/env")
+
+	got, err := file.WithEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = got.Override(Config{Server: ServerConfig{Port: 9100}, Log: LogConfig{Requests: true}})
+
+	if got.Server.Host != "0.0.0.0" || got.Server.Port != 9100 {
+		t.Errorf("server = %+v, want file host and flag port", got.Server)
+	}
+	if got.Auth.Password != "from-env" || got.DataDir != "/notes/env" {
+		t.Errorf("env didn't override the file: password %q, data dir %q", got.Auth.Password, got.DataDir)
+	}
+	if got.Archive.Format != "pdf" || !got.Archive.Reader || got.Limits.UploadMB != 10 || !got.Log.Requests {
+		t.Errorf("settings = %+v", got)
+	}
+	if file.Auth.Password != "from-file" || file.Server.Port != 8080 {
+		t.Error("WithEnv changed the config it was called on")
+	}
+
+	t.Setenv("NOTEFLOW_UPLOAD_MB", "lots")
+	if _, err := file.WithEnv(); err == nil {
+		t.Error("invalid NOTEFLOW_UPLOAD_MB accepted")
+	}
+}
+
+func TestConfigResolveDataDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	tests := map[string]string{
+		"":         "/work",
+		"~/notes":  filepath.Join(home, "notes"),
+		"/srv/nf/": "/srv/nf",
+	}
+	for dataDir, want := range tests {
+		got, err := Config{DataDir: dataDir}.ResolveDataDir("/work")
+		if err != nil || got != want {
+			t.Errorf("ResolveDataDir(%q) = %q, %v; want %q", dataDir, got, err, want)
+		}
+	}
+}
+
+func TestConfigRedacted(t *testing.T) {
+	c := Config{
+		Theme:         "dark-orange",
+		Auth:          AuthConfig{Password: "hunter2"},
+		Jira:          JiraConfig{Sites: map[string]JiraCredentials{"https://jira.example.com": {Email: "me@example.com", Token: "t0k"}}},
+		Webhooks:      []WebhookConfig{{URL: "https://hooks.example.com/secret-path"}},
+		Server:        ServerConfig{TLSKey: "key.pem", Port: 8000},
+		Notifications: NotificationsConfig{Slack: &SlackWebhookConfig{WebhookURL: "https://hooks.slack.com/services/T0/B0/x"}},
+	}
+	m, err := c.Redacted()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["theme"] != "dark-orange" {
+		t.Errorf("theme = %v", m["theme"])
+	}
+	auth := m["auth"].(map[string]any)
+	site := m["jira"].(map[string]any)["sites"].(map[string]any)["https://jira.example.com"].(map[string]any)
+	hook := m["webhooks"].([]any)[0].(map[string]any)
+	server := m["server"].(map[string]any)
+	slack := m["notifications"].(map[string]any)["slack"].(map[string]any)
+	for name, got := range map[string]any{"auth.password": auth["password"], "jira token": site["token"], "webhook url": hook["url"], "tls_key": server["tls_key"], "slack webhook_url": slack["webhook_url"]} {
+		if got != "***" {
+			t.Errorf("%s = %v, want redacted", name, got)
+		}
+	}
+	if site["email"] != "me@example.com" || server["port"] != float64(8000) {
+		t.Errorf("non-secrets redacted: %v, %v", site["email"], server["port"])
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+// Defaults for LimitsConfig.
+const (
+	DefaultRequestsPerMinute = 600
+	DefaultUploadMB          = 50
+	DefaultBodyMB            = 4
+)
+
+// LimitsConfig caps what one client can ask of the server, so a runaway
+// script or hostile client can't exhaust it. Zero values use the defaults
+// noted.
+//
+// Stored under "limits" in ~/.config/noteflow/noteflow.json:
+//
+//	"limits": {"requests_per_minute": 600, "upload_mb": 50, "body_mb": 4}
+type LimitsConfig struct {
+	// RequestsPerMinute is how many requests one IP address may make per
+	// minute; default 600, negative for no limit. Static files don't count.
+	RequestsPerMinute int `json:"requests_per_minute,omitempty"`
+	// UploadMB caps file uploads; default 50.
+	UploadMB int `json:"upload_mb,omitempty"`
+	// BodyMB caps every other request body, note saves included; default 4.
+	BodyMB int `json:"body_mb,omitempty"`
+}
+
+// RequestLimit returns the requests allowed per IP and minute, 0 for no
+// limit.
+func (l LimitsConfig) RequestLimit() int {
+	switch {
+	case l.RequestsPerMinute < 0:
+		return 0
+	case l.RequestsPerMinute == 0:
+		return DefaultRequestsPerMinute
+	}
+	return l.RequestsPerMinute
+}
+
+// UploadBytes returns the largest upload accepted.
+func (l LimitsConfig) UploadBytes() int {
+	return megabytes(l.UploadMB, DefaultUploadMB)
+}
+
+// BodyBytes returns the largest body accepted by everything but uploads.
+func (l LimitsConfig) BodyBytes() int {
+	return megabytes(l.BodyMB, DefaultBodyMB)
+}
+
+func megabytes(mb, def int) int {
+	if mb <= 0 {
+		mb = def
+	}
+	return mb << 20
+}
@@ -0,0 +1,14 @@
+package models
+
+import "testing"
+
+func TestLimitsConfig_Defaults(t *testing.T) {
+	var l LimitsConfig
+	if l.RequestLimit() != DefaultRequestsPerMinute || l.UploadBytes() != 50<<20 || l.BodyBytes() != 4<<20 {
+		t.Errorf("zero config: %d, %d, %d", l.RequestLimit(), l.UploadBytes(), l.BodyBytes())
+	}
+	l = LimitsConfig{RequestsPerMinute: -1, UploadMB: 200, BodyMB: 1}
+	if l.RequestLimit() != 0 || l.UploadBytes() != 200<<20 || l.BodyBytes() != 1<<20 {
+		t.Errorf("%+v: %d, %d, %d", l, l.RequestLimit(), l.UploadBytes(), l.BodyBytes())
+	}
+}
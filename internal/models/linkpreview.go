@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// LinkPreviewsFile caches fetched link previews, relative to the folder.
+const LinkPreviewsFile = "assets/previews.json"
+
+// LinkPreviewConfig turns on preview cards under plain URLs in notes.
+type LinkPreviewConfig struct {
+	// Enabled fetches the pages linked from notes in the background. Off by
+	// default: it contacts every site a note links to.
+	Enabled bool `json:"enabled,omitempty"`
+	// MaxAgeDays is how long a preview is kept before it is fetched again;
+	// default 30. Failed fetches are retried after a day.
+	MaxAgeDays int `json:"max_age_days,omitempty"`
+}
+
+// LinkPreview is the metadata shown in a link's preview card. A preview
+// whose fetch failed is kept with Error set, so the page isn't fetched
+// again on every render.
+type LinkPreview struct {
+	URL         string    `json:"url"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Image       string    `json:"image,omitempty"`
+	SiteName    string    `json:"site_name,omitempty"`
+	FetchedAt   time.Time `json:"fetched_at"`
+	Error       string    `json:"error,omitempty"`
+}
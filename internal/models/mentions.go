@@ -0,0 +1,62 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionTokenRE matches @person. Names start with a letter, so due tokens
+// (@2026-05-20) never match, and the "@" must follow whitespace or the
+// start of the text, so e-mail addresses don't either.
+var mentionTokenRE = regexp.MustCompile(`(?:^|\s)@([A-Za-z][A-Za-z0-9_-]*)`)
+
+// mentionNameRE is the shape of a bare mention name.
+var mentionNameRE = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// NormalizeMentionName trims whitespace and a leading "@" from name and
+// reports whether what is left is a valid mention name.
+func NormalizeMentionName(name string) (string, bool) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+	return name, mentionNameRE.MatchString(name)
+}
+
+// MentionKey normalizes a mention for matching: @Ana and @ana are the same
+// person.
+func MentionKey(name string) string {
+	return strings.ToLower(name)
+}
+
+// ExtractMentions returns the distinct @mentions in content, in order of
+// first appearance and as first written. Mentions in code are skipped the
+// way tags are.
+func ExtractMentions(content string) []string {
+	var mentions []string
+	seen := make(map[string]bool)
+	ReplaceMentionTokens(content, func(name string) string {
+		if key := MentionKey(name); !seen[key] {
+			seen[key] = true
+			mentions = append(mentions, name)
+		}
+		return ""
+	})
+	return mentions
+}
+
+// ReplaceMentionTokens calls fn for every @mention outside code in content
+// and substitutes its result for the "@name" text (the leading whitespace
+// is kept). "@due(...)" is a due date phrase, not a mention.
+func ReplaceMentionTokens(content string, fn func(name string) string) string {
+	codeRanges := findCodeRanges(content)
+	var b strings.Builder
+	last := 0
+	for _, m := range mentionTokenRE.FindAllStringSubmatchIndex(content, -1) {
+		if posInRanges(m[2], codeRanges) || strings.HasPrefix(content[m[3]:], "(") {
+			continue
+		}
+		b.WriteString(content[last : m[2]-1])
+		b.WriteString(fn(content[m[2]:m[3]]))
+		last = m[3]
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
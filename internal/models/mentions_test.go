@@ -0,0 +1,23 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractMentions(t *testing.T) {
+	content := "Sync with @ana and @Bo_2 @2026-05-20 @due(friday)\nmail ana@example.com `@code` @ANA again"
+	if got, want := ExtractMentions(content), []string{"ana", "Bo_2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractMentions = %v, want %v", got, want)
+	}
+	got := ReplaceMentionTokens("@ana, ping @bo", func(name string) string { return "<" + name + ">" })
+	if got != "<ana>, ping <bo>" {
+		t.Errorf("ReplaceMentionTokens = %q", got)
+	}
+	if name, ok := NormalizeMentionName(" @ana "); !ok || name != "ana" {
+		t.Errorf("NormalizeMentionName = %q %v", name, ok)
+	}
+	if _, ok := NormalizeMentionName("2026"); ok {
+		t.Error("digits accepted as a mention name")
+	}
+}
@@ -15,6 +15,12 @@ type Note struct {
 	Content   string    `json:"content"`
 	Timestamp time.Time `json:"timestamp"`
 	Tasks     []*Task   `json:"tasks"`
+	Tags      []string  `json:"tags,omitempty"` // distinct #tags in Content, kept current by the methods below
+	Links     []string  `json:"links,omitempty"` // distinct [[wiki link]] targets (WikiLinkKey form), kept current likewise
+	Mentions  []string  `json:"mentions,omitempty"` // distinct @mentions in Content, kept current likewise
+	// Metadata holds the note's frontmatter (see ParseFrontmatter). The
+	// block itself stays in Content, so Render writes it back verbatim.
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // NewNote creates a new note with the given title and content
@@ -79,34 +85,78 @@ func NewNoteFromText(text string) (*Note, error) {
 // inline code spans (`...`). Without that skip, prose documenting the
 // task syntax — e.g. a Go comment containing `"- [ ] "` or a table cell
 // containing `` `- [ ]` `` — would surface as phantom tasks in the
-// global tasks view.
+// global tasks view. It also refreshes Tags, Links and Mentions, which
+// skip code the same way, and Metadata.
 func (n *Note) parseTasks() {
 	n.Tasks = make([]*Task, 0)
+	n.Tags = ExtractTags(n.Content)
+	n.Links = ExtractWikiLinks(n.Content)
+	n.Mentions = ExtractMentions(n.Content)
+	n.Metadata, _, _ = ParseFrontmatter(n.Content)
 
 	codeRanges := findCodeRanges(n.Content)
-	checkboxPattern := regexp.MustCompile(`\[([xX ])\]`)
+	checkboxPattern := regexp.MustCompile(`\[([xX /])\]`)
 	matches := checkboxPattern.FindAllStringSubmatchIndex(n.Content, -1)
+	headings := findHeadings(n.Content, codeRanges)
 
 	idx := 0
+	var positions []int
 	for _, match := range matches {
 		if posInRanges(match[0], codeRanges) {
 			continue
 		}
-		checked := strings.ToLower(n.Content[match[2]:match[3]]) == "x"
+		positions = append(positions, match[0])
+		state := taskStateFromMark(n.Content[match[2]:match[3]])
 		taskText := n.extractTaskText(match[0])
 		priority, due, tags := ParseTaskMetadata(taskText)
 
 		task := &Task{
 			Index:    idx, // Will be updated by manager with global index
-			Checked:  checked,
+			Checked:  state == TaskDone,
+			State:    state,
 			Text:     taskText,
 			Priority: priority,
 			DueDate:  due,
 			Tags:     tags,
+			Section:  sectionAt(headings, match[0]),
 		}
 		n.Tasks = append(n.Tasks, task)
 		idx++
 	}
+	n.linkSubtasks(positions)
+}
+
+// headingRE matches an ATX heading line. The space after the hashes is what
+// tells "## Sprint" apart from a "#tag" at the start of a line.
+var headingRE = regexp.MustCompile(`(?m)^ {0,3}#{1,6}[ \t]+(.*?)[ \t#]*$`)
+
+// heading is a markdown heading's text and byte offset in a note.
+type heading struct {
+	pos  int
+	text string
+}
+
+// findHeadings returns the headings in content outside code, in order.
+func findHeadings(content string, codeRanges [][2]int) []heading {
+	var out []heading
+	for _, m := range headingRE.FindAllStringSubmatchIndex(content, -1) {
+		if !posInRanges(m[0], codeRanges) {
+			out = append(out, heading{pos: m[0], text: content[m[2]:m[3]]})
+		}
+	}
+	return out
+}
+
+// sectionAt returns the text of the last heading before pos, or "".
+func sectionAt(headings []heading, pos int) string {
+	section := ""
+	for _, h := range headings {
+		if h.pos > pos {
+			break
+		}
+		section = h.text
+	}
+	return section
 }
 
 // findCodeRanges scans content and returns half-open byte ranges
@@ -187,27 +237,28 @@ func (n *Note) Update(title, content string) {
 
 // UpdateTask updates a specific task's completion status
 func (n *Note) UpdateTask(taskIndex int, checked bool) bool {
+	state := TaskTodo
+	if checked {
+		state = TaskDone
+	}
+	return n.SetTaskState(taskIndex, state)
+}
+
+// SetTaskText replaces the full line of the task with taskIndex, keeping its
+// index and re-deriving inline metadata from the new text. Returns false when
+// no task with that index lives in this note.
+func (n *Note) SetTaskText(taskIndex int, text string) bool {
 	for _, task := range n.Tasks {
-		if task.Index == taskIndex {
-			oldMark := "[x]"
-			newMark := "[ ]"
-			if checked {
-				oldMark = "[ ]"
-				newMark = "[x]"
-			}
-			
-			// Replace the checkbox in the original task text
-			oldLine := task.Text
-			newLine := strings.Replace(oldLine, oldMark, newMark, 1)
-			
-			// Update note content
-			n.Content = strings.Replace(n.Content, oldLine, newLine, 1)
-			
-			// Update task
-			task.Text = newLine
-			task.Checked = checked
-			return true
+		if task.Index != taskIndex {
+			continue
 		}
+		n.Content = strings.Replace(n.Content, task.Text, text, 1)
+		task.Text = text
+		task.Priority, task.DueDate, task.Tags = ParseTaskMetadata(text)
+		n.Tags = ExtractTags(n.Content)
+		n.Links = ExtractWikiLinks(n.Content)
+		n.Mentions = ExtractMentions(n.Content)
+		return true
 	}
 	return false
 }
@@ -220,8 +271,11 @@ func (n *Note) GetUncheckedTasks() []*TaskInfo {
 			// Clean the task text by removing checkbox markers
 			cleanText := strings.TrimSpace(
 				strings.Replace(
-					strings.Replace(task.Text, "[x]", "", 1),
-					"[ ]", "", 1,
+					strings.Replace(
+						strings.Replace(task.Text, "[x]", "", 1),
+						"[ ]", "", 1,
+					),
+					"[/]", "", 1,
 				),
 			)
 			
@@ -246,4 +300,10 @@ func (n *Note) Render() string {
 	}
 	
 	return fmt.Sprintf("## %s%s\n\n%s\n", timestampStr, titleStr, n.Content)
-}
\ No newline at end of file
+}
+// CodeRanges returns the byte ranges of fenced code blocks and inline code
+// spans in content, for callers outside this package that must leave code
+// alone the way task and tag parsing do.
+func CodeRanges(content string) [][2]int {
+	return findCodeRanges(content)
+}
@@ -358,3 +358,34 @@ func TestEmptyInputReturnsError(t *testing.T) {
 		t.Log("NOTE: NewNoteFromText currently accepts empty input; schema §3 says header is required. Tighten parser to enforce.")
 	}
 }
+
+func TestParseTasks_RecordsSection(t *testing.T) {
+	input := strings.Join([]string{
+		"## 2026-05-13 09:00:00 - Planning",
+		"",
+		"- [ ] before any heading",
+		"### Sprint 12 ###",
+		"- [ ] fix login",
+		"```",
+		"# not a heading",
+		"```",
+		"- [ ] still sprint 12",
+		"#tag is not a heading either",
+		"#### Later",
+		"- [ ] rewrite docs",
+	}, "\n")
+
+	note, err := NewNoteFromText(input)
+	if err != nil {
+		t.Fatalf("NewNoteFromText: %v", err)
+	}
+	want := []string{"", "Sprint 12", "Sprint 12", "Later"}
+	if len(note.Tasks) != len(want) {
+		t.Fatalf("got %d tasks, want %d", len(note.Tasks), len(want))
+	}
+	for i, task := range note.Tasks {
+		if task.Section != want[i] {
+			t.Errorf("task %d (%q) section = %q, want %q", i, task.Text, task.Section, want[i])
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+// NotificationsConfig selects which push-notification channels NoteFlow uses
+// for out-of-band alerts (overdue tasks, failed archives, and so on). Each
+// channel is optional; a nil entry means "not configured". When more than
+// one channel is configured every alert fans out to all of them.
+//
+// Stored under "notifications" in ~/.config/noteflow/noteflow.json:
+//
+//	"notifications": {
+//	  "ntfy":     {"server": "https://ntfy.sh", "topic": "my-noteflow", "token": ""},
+//	  "pushover": {"token": "app-token", "user": "user-key"},
+//	  "slack":    {"webhook_url": "https://hooks.slack.com/services/T000/B000/XXXX"}
+//	}
+type NotificationsConfig struct {
+	Ntfy     *NtfyConfig         `json:"ntfy,omitempty"`
+	Pushover *PushoverConfig     `json:"pushover,omitempty"`
+	Slack    *SlackWebhookConfig `json:"slack,omitempty"`
+	// DisableOverdue turns off the once-a-day overdue task alert while
+	// keeping the channels available for other events.
+	DisableOverdue bool `json:"disable_overdue,omitempty"`
+}
+
+// NtfyConfig configures an ntfy (https://ntfy.sh or self-hosted) topic.
+// Server defaults to https://ntfy.sh when empty. Token is optional and only
+// needed for access-controlled topics.
+type NtfyConfig struct {
+	Server string `json:"server,omitempty"`
+	Topic  string `json:"topic"`
+	Token  string `json:"token,omitempty"`
+}
+
+// PushoverConfig configures the Pushover API. Token is the application API
+// token; User is the user (or group) key that receives the message.
+type PushoverConfig struct {
+	Token string `json:"token"`
+	User  string `json:"user"`
+}
+
+// SlackWebhookConfig configures a Slack incoming webhook, which posts to
+// the channel it was created for.
+type SlackWebhookConfig struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+// Enabled reports whether at least one channel is configured.
+func (n NotificationsConfig) Enabled() bool {
+	return n.Ntfy != nil || n.Pushover != nil || n.Slack != nil
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"path/filepath"
 	"time"
 )
 
@@ -10,6 +11,32 @@ type FolderRegistry struct {
 	Path     string    `json:"path" db:"path"`
 	LastScan time.Time `json:"last_scan" db:"last_scan"`
 	Active   bool      `json:"active" db:"active"`
+	// Alias is the name the folder is shown under instead of its path, and
+	// Group the heading it is listed under; both optional.
+	Alias string `json:"alias,omitempty" db:"alias"`
+	Group string `json:"group,omitempty" db:"group_name"`
+}
+
+// FolderName is how a registered folder is shown: its alias, else the
+// base name of its path.
+func FolderName(path, alias string) string {
+	if alias != "" {
+		return alias
+	}
+	return filepath.Base(path)
+}
+
+// Project is a registered folder as the web server offers it: each is
+// served under /p/<alias>/, and the folder the server was started in (or a
+// user's notes root) also at the root.
+type Project struct {
+	Alias    string `json:"alias"`
+	FolderID int    `json:"folder_id"`
+	Path     string `json:"path"`
+	// URL is where the project's notes page is served; Current marks the
+	// project the request was made in.
+	URL     string `json:"url"`
+	Current bool   `json:"current"`
 }
 
 // GlobalTask represents a task from any registered folder
@@ -21,23 +48,54 @@ type GlobalTask struct {
 	Content     string    `json:"content" db:"content"`
 	Completed   bool      `json:"completed" db:"completed"`
 	LastUpdated time.Time `json:"last_updated" db:"last_updated"`
+	// DueDate comes from the task's due token; nil when it has none.
+	DueDate *time.Time `json:"due_date,omitempty" db:"due_date"`
+	// Overdue is computed when the task is read; see IsOverdue.
+	Overdue bool `json:"overdue"`
+	// NoteID is the HistoryKey of the note holding the task and CharOffset
+	// the checkbox's UTF-16 offset in that note's body; LineNumber is the
+	// checkbox's 1-based line in FilePath.
+	NoteID     string `json:"note_id,omitempty" db:"note_id"`
+	CharOffset int    `json:"char_offset" db:"char_offset"`
+	// Hash is the task's stable ID across syncs (see
+	// services.ComputeTaskHashes), as taken by `noteflow tasks --toggle`.
+	Hash string `json:"hash,omitempty" db:"task_hash"`
+	// LinkID groups the copies of this task in other folders that
+	// complete together; 0 when it isn't linked.
+	LinkID int `json:"link_id,omitempty"`
 	
 	// Joined fields from folder
 	FolderPath  string    `json:"folder_path,omitempty"`
+	FolderName  string    `json:"folder_name,omitempty"`  // see FolderName
+	FolderGroup string    `json:"folder_group,omitempty"`
 }
 
 // TaskSummary provides aggregated task information for a folder
 type TaskSummary struct {
+	FolderID        int    `json:"folder_id"`
 	FolderPath      string `json:"folder_path"`
+	FolderName      string `json:"folder_name"` // see FolderName
+	FolderGroup     string `json:"folder_group,omitempty"`
 	TotalTasks      int    `json:"total_tasks"`
 	CompletedTasks  int    `json:"completed_tasks"`
 	PendingTasks    int    `json:"pending_tasks"`
 	LastUpdated     time.Time `json:"last_updated"`
 }
 
+// TaskGroupSummary totals the summaries of the folders in one group;
+// folders without a group make up the group "".
+type TaskGroupSummary struct {
+	Group          string   `json:"group"`
+	Folders        []string `json:"folders"` // folder names, as listed
+	TotalTasks     int      `json:"total_tasks"`
+	CompletedTasks int      `json:"completed_tasks"`
+	PendingTasks   int      `json:"pending_tasks"`
+}
+
 // GlobalTasksResponse represents the response for global tasks endpoint
 type GlobalTasksResponse struct {
 	Tasks     []GlobalTask  `json:"tasks"`
-	Summaries []TaskSummary `json:"summaries"`
+	Summaries []TaskSummary `json:"summaries"` // by group, then folder name
+	Groups    []TaskGroupSummary `json:"groups"`
 	Total     int           `json:"total"`
 }
\ No newline at end of file
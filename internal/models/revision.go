@@ -0,0 +1,37 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Revision is a saved earlier version of a note, written to the note's
+// history each time the note is edited.
+type Revision struct {
+	ID      string    `json:"id"`    // sortable; derived from Saved
+	Saved   time.Time `json:"saved"` // when this version was replaced
+	Title   string    `json:"title"`
+	Content string    `json:"content,omitempty"`
+}
+
+// HistoryKey identifies a note's history across edits and reorderings.
+// Note indices shift whenever a note is added, so history is keyed by the
+// creation timestamp, which is written to notes.md and never changes.
+func (n *Note) HistoryKey() string {
+	return n.Timestamp.Format("20060102-150405")
+}
+
+// Version identifies the note's current title and content. Editors send
+// back the version they loaded, so an edit made meanwhile elsewhere isn't
+// silently overwritten.
+func (n *Note) Version() string {
+	return NoteVersion(n.Title, n.Content)
+}
+
+// NoteVersion is the Version of a note with title and content: the first
+// 16 hex digits of their SHA-256.
+func NoteVersion(title, content string) string {
+	sum := sha256.Sum256([]byte(title + "\x00" + content))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultPort is where the server listens unless a port is configured.
+// When it is busy the next free port above it is used instead.
+const DefaultPort = 8000
+
+// ServerConfig sets where and how the web server listens. Host, Port and
+// BasePath can be overridden by $NOTEFLOW_HOST, $NOTEFLOW_PORT and
+// $NOTEFLOW_BASE_PATH, and every field by the matching flag.
+type ServerConfig struct {
+	// Host is the interface to bind, e.g. "127.0.0.1"; empty means all.
+	Host string `json:"host,omitempty"`
+	// Port is the port to listen on. Zero means DefaultPort or the next
+	// free one; an explicit port that is busy is an error.
+	Port int `json:"port,omitempty"`
+	// BasePath serves NoteFlow under a URL prefix such as "/notes", for
+	// running behind a reverse proxy that forwards a sub-path.
+	BasePath string `json:"base_path,omitempty"`
+	// TLSCert and TLSKey are PEM files to serve HTTPS with.
+	TLSCert string `json:"tls_cert,omitempty"`
+	TLSKey  string `json:"tls_key,omitempty"`
+	// SelfSigned serves HTTPS with a generated self-signed certificate
+	// when no TLSCert is given, for use on a LAN.
+	SelfSigned bool `json:"self_signed,omitempty"`
+	// NoBrowser stops the server opening the default browser at its URL
+	// once it is listening.
+	NoBrowser bool `json:"no_browser,omitempty"`
+}
+
+// TLS reports whether the server should use HTTPS.
+func (s ServerConfig) TLS() bool {
+	return s.TLSCert != "" || s.SelfSigned
+}
+
+// ValidateTLS checks that a certificate and key are given together.
+func (s ServerConfig) ValidateTLS() error {
+	if (s.TLSCert == "") != (s.TLSKey == "") {
+		return fmt.Errorf("tls_cert and tls_key must be set together")
+	}
+	return nil
+}
+
+// ServerConfigFromEnv reads the server settings given in the environment.
+func ServerConfigFromEnv() (ServerConfig, error) {
+	s := ServerConfig{
+		Host:     os.Getenv("NOTEFLOW_HOST"),
+		BasePath: os.Getenv("NOTEFLOW_BASE_PATH"),
+	}
+	if v := os.Getenv("NOTEFLOW_PORT"); v != "" {
+		port, err := ParsePort(v)
+		if err != nil {
+			return s, fmt.Errorf("NOTEFLOW_PORT: %w", err)
+		}
+		s.Port = port
+	}
+	if v := os.Getenv("NOTEFLOW_NO_BROWSER"); v != "" {
+		noBrowser, err := strconv.ParseBool(v)
+		if err != nil {
+			return s, fmt.Errorf("NOTEFLOW_NO_BROWSER: invalid value %q", v)
+		}
+		s.NoBrowser = noBrowser
+	}
+	return s, nil
+}
+
+// ParsePort parses a TCP port number, 1-65535.
+func ParsePort(v string) (int, error) {
+	port, err := strconv.Atoi(v)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q", v)
+	}
+	return port, nil
+}
+
+// Merge returns s with every field that is set in over replaced by it.
+func (s ServerConfig) Merge(over ServerConfig) ServerConfig {
+	if over.Host != "" {
+		s.Host = over.Host
+	}
+	if over.Port != 0 {
+		s.Port = over.Port
+	}
+	if over.BasePath != "" {
+		s.BasePath = over.BasePath
+	}
+	if over.TLSCert != "" || over.TLSKey != "" {
+		s.TLSCert, s.TLSKey = over.TLSCert, over.TLSKey
+	}
+	s.SelfSigned = s.SelfSigned || over.SelfSigned
+	s.NoBrowser = s.NoBrowser || over.NoBrowser
+	return s
+}
+
+// CleanBasePath normalizes a URL prefix to "/a/b" form: a leading slash,
+// no trailing slash, and "" for the root.
+func CleanBasePath(p string) string {
+	p = strings.Trim(strings.TrimSpace(p), "/")
+	if p == "" {
+		return ""
+	}
+	return "/" + p
+}
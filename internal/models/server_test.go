@@ -0,0 +1,74 @@
+package models
+
+import "testing"
+
+func TestServerConfigFromEnv(t *testing.T) {
+	t.Setenv("NOTEFLOW_HOST", "127.0.0.1")
+	t.Setenv("NOTEFLOW_PORT", "9090")
+	t.Setenv("NOTEFLOW_BASE_PATH", "/notes")
+	t.Setenv("NOTEFLOW_NO_BROWSER", "1")
+	got, err := ServerConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ServerConfig{Host: "127.0.0.1", Port: 9090, BasePath: "/notes", NoBrowser: true}
+	if got != want {
+		t.Errorf("ServerConfigFromEnv() = %+v, want %+v", got, want)
+	}
+
+	t.Setenv("NOTEFLOW_NO_BROWSER", "maybe")
+	if _, err := ServerConfigFromEnv(); err == nil {
+		t.Error("invalid NOTEFLOW_NO_BROWSER accepted")
+	}
+	t.Setenv("NOTEFLOW_NO_BROWSER", "")
+
+	t.Setenv("NOTEFLOW_PORT", "70000")
+	if _, err := ServerConfigFromEnv(); err == nil {
+		t.Error("out-of-range NOTEFLOW_PORT accepted")
+	}
+}
+
+func TestServerConfig_Merge(t *testing.T) {
+	base := ServerConfig{Host: "0.0.0.0", Port: 8080, BasePath: "/a"}
+	got := base.Merge(ServerConfig{Port: 9000})
+	want := ServerConfig{Host: "0.0.0.0", Port: 9000, BasePath: "/a"}
+	if got != want {
+		t.Errorf("Merge = %+v, want %+v", got, want)
+	}
+
+	// A config file's no_browser isn't undone by a flag left unset.
+	got = ServerConfig{NoBrowser: true}.Merge(ServerConfig{Port: 9000})
+	if !got.NoBrowser {
+		t.Error("Merge dropped NoBrowser")
+	}
+}
+
+func TestCleanBasePath(t *testing.T) {
+	tests := map[string]string{
+		"":        "",
+		"/":       "",
+		"notes":   "/notes",
+		"/notes/": "/notes",
+		" /a/b/ ": "/a/b",
+		"//x//":   "/x",
+	}
+	for in, want := range tests {
+		if got := CleanBasePath(in); got != want {
+			t.Errorf("CleanBasePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestServerConfig_TLS(t *testing.T) {
+	base := ServerConfig{TLSCert: "old.pem", TLSKey: "old.key"}
+	got := base.Merge(ServerConfig{TLSCert: "new.pem", TLSKey: "new.key"})
+	if got.TLSCert != "new.pem" || got.TLSKey != "new.key" || !got.TLS() {
+		t.Errorf("Merge = %+v, want the new pair", got)
+	}
+	if !(ServerConfig{SelfSigned: true}).TLS() {
+		t.Error("SelfSigned alone should enable TLS")
+	}
+	if err := (ServerConfig{TLSCert: "cert.pem"}).ValidateTLS(); err == nil {
+		t.Error("certificate without key accepted")
+	}
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// listItemPrefixRE matches what may precede a task's checkbox on its line
+// for the task to be a list item: indentation and a bullet or number.
+var listItemPrefixRE = regexp.MustCompile(`^([ \t]*)(?:[-*+]|\d+[.)])[ \t]+$`)
+
+// indentWidth measures leading whitespace, counting a tab as four columns
+// like CommonMark.
+func indentWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		switch r {
+		case ' ':
+			w++
+		case '\t':
+			w += 4 - w%4
+		default:
+			return w
+		}
+	}
+	return w
+}
+
+// linkSubtasks sets Depth and ParentIndex from list indentation. positions
+// holds each task's checkbox offset in Content. A task nests under the
+// closest task above it whose list item is indented less; any line in
+// between that is indented no deeper than an open task closes it, so a
+// paragraph or heading ends the nesting. Checkboxes that aren't list items
+// are always top level. ParentIndex is note-local here; AssignTaskIndices
+// makes it global.
+func (n *Note) linkSubtasks(positions []int) {
+	type open struct{ indent, pos int }
+	var stack []open
+	prevEnd := 0
+	for i, task := range n.Tasks {
+		lineStart := strings.LastIndex(n.Content[:positions[i]], "\n") + 1
+		for _, line := range strings.Split(n.Content[min(prevEnd, lineStart):lineStart], "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			w := indentWidth(line)
+			for len(stack) > 0 && stack[len(stack)-1].indent >= w {
+				stack = stack[:len(stack)-1]
+			}
+		}
+		if end := strings.IndexByte(n.Content[positions[i]:], '\n'); end >= 0 {
+			prevEnd = positions[i] + end + 1
+		} else {
+			prevEnd = len(n.Content)
+		}
+
+		task.parent, task.ParentIndex, task.Depth = -1, -1, 0
+		m := listItemPrefixRE.FindStringSubmatch(n.Content[lineStart:positions[i]])
+		if m == nil {
+			stack = stack[:0]
+			continue
+		}
+		indent := indentWidth(m[1])
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) > 0 {
+			task.parent = stack[len(stack)-1].pos
+			task.ParentIndex = task.parent
+			task.Depth = len(stack)
+		}
+		stack = append(stack, open{indent: indent, pos: i})
+	}
+}
+
+// AssignTaskIndices numbers the note's tasks start, start+1, ... and
+// points ParentIndex at the parents' new numbers. It returns the next free
+// index.
+func (n *Note) AssignTaskIndices(start int) int {
+	for i, task := range n.Tasks {
+		task.Index = start + i
+	}
+	for _, task := range n.Tasks {
+		if task.parent >= 0 && task.parent < len(n.Tasks) {
+			task.ParentIndex = n.Tasks[task.parent].Index
+		}
+	}
+	return start + len(n.Tasks)
+}
+
+// Subtasks returns every task nested under the task with taskIndex,
+// children and their descendants, in note order. It is empty for a task
+// without subtasks or not in this note.
+func (n *Note) Subtasks(taskIndex int) []*Task {
+	for i, task := range n.Tasks {
+		if task.Index != taskIndex {
+			continue
+		}
+		var sub []*Task
+		for _, t := range n.Tasks[i+1:] {
+			if t.Depth <= task.Depth {
+				break
+			}
+			sub = append(sub, t)
+		}
+		return sub
+	}
+	return nil
+}
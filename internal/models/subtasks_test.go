@@ -0,0 +1,44 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSubtaskNesting(t *testing.T) {
+	content := "- [ ] Release\n" +
+		"  - [ ] Changelog\n" +
+		"  - [x] Tag\n" +
+		"\t- [ ] Push tag\n" +
+		"- [ ] Unrelated\n" +
+		"    1. [ ] Numbered child\n" +
+		"\n" +
+		"A paragraph ends the list.\n" +
+		"  - [ ] Orphan\n" +
+		"Inline [ ] checkbox\n"
+	n := NewNote("Plan", content)
+	n.AssignTaskIndices(10)
+
+	var depths, parents []int
+	for _, task := range n.Tasks {
+		depths = append(depths, task.Depth)
+		parents = append(parents, task.ParentIndex)
+	}
+	if want := []int{0, 1, 1, 2, 0, 1, 0, 0}; !reflect.DeepEqual(depths, want) {
+		t.Errorf("depths = %v, want %v", depths, want)
+	}
+	if want := []int{-1, 10, 10, 12, -1, 14, -1, -1}; !reflect.DeepEqual(parents, want) {
+		t.Errorf("parents = %v, want %v", parents, want)
+	}
+
+	var sub []int
+	for _, task := range n.Subtasks(10) {
+		sub = append(sub, task.Index)
+	}
+	if want := []int{11, 12, 13}; !reflect.DeepEqual(sub, want) {
+		t.Errorf("Subtasks(10) = %v, want %v", sub, want)
+	}
+	if got := n.Subtasks(11); len(got) != 0 {
+		t.Errorf("Subtasks(11) = %v, want none", got)
+	}
+}
@@ -0,0 +1,215 @@
+package models
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tagNameRE is the shape of a bare tag name, i.e. tagTokenRE without the
+// leading "#" and anchoring whitespace.
+var tagNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*(?:/[A-Za-z0-9_-]+)*$`)
+
+// NormalizeTagName trims whitespace and a leading "#" from name and reports
+// whether what is left is a valid tag name.
+func NormalizeTagName(name string) (string, bool) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "#")
+	return name, tagNameRE.MatchString(name)
+}
+
+// TagMatches reports whether tag is filter or nested under it, so that
+// filtering on "project" also finds "project/clientA/website".
+func TagMatches(tag, filter string) bool {
+	return tag == filter || strings.HasPrefix(tag, filter+"/")
+}
+
+// ExtractTags returns the distinct #tags in content, in order of first
+// appearance. Tokens inside fenced code blocks and inline code spans are
+// skipped, the same as phantom task markers are in parseTasks.
+func ExtractTags(content string) []string {
+	codeRanges := findCodeRanges(content)
+	seen := make(map[string]bool)
+	var tags []string
+	for _, m := range tagTokenRE.FindAllStringSubmatchIndex(content, -1) {
+		if posInRanges(m[2], codeRanges) {
+			continue
+		}
+		name := content[m[2]:m[3]]
+		if !seen[name] {
+			seen[name] = true
+			tags = append(tags, name)
+		}
+	}
+	return tags
+}
+
+// TagLineChange is one line rewritten by RewriteTags.
+type TagLineChange struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// RewriteTags renames every #tag in content that is a key of mapping, or is
+// nested under one, to the corresponding value: with {"proj": "work"},
+// "#proj/site" becomes "#work/site". Matching is by whole path segment and
+// case-sensitive, so "#work" does not touch "#workshop" or "#Work". Code is
+// left alone. When a rewrite would repeat a tag already on the same line
+// (merging "#a #b" into "#c") the rewritten duplicate is dropped rather
+// than written twice.
+//
+// It returns the new content, the number of tokens rewritten and the
+// changed lines.
+func RewriteTags(content string, mapping map[string]string) (string, int, []TagLineChange) {
+	codeRanges := findCodeRanges(content)
+	var (
+		out     strings.Builder
+		count   int
+		changes []TagLineChange
+		pos     int
+	)
+	for _, line := range strings.SplitAfter(content, "\n") {
+		var matches [][]int
+		for _, m := range tagTokenRE.FindAllStringSubmatchIndex(line, -1) {
+			if !posInRanges(pos+m[2], codeRanges) {
+				matches = append(matches, m)
+			}
+		}
+		pos += len(line)
+
+		// Tags the line keeps as they are win over rewritten duplicates.
+		seen := make(map[string]bool)
+		for _, m := range matches {
+			if _, renamed := rewriteTagName(line[m[2]:m[3]], mapping); !renamed {
+				seen[line[m[2]:m[3]]] = true
+			}
+		}
+
+		var b strings.Builder
+		last, lineCount := 0, 0
+		for _, m := range matches {
+			to, renamed := rewriteTagName(line[m[2]:m[3]], mapping)
+			if !renamed {
+				continue
+			}
+			lineCount++
+			if seen[to] {
+				// Drop the token along with its leading whitespace.
+				b.WriteString(line[last:m[0]])
+			} else {
+				b.WriteString(line[last:m[2]])
+				b.WriteString(to)
+				seen[to] = true
+			}
+			last = m[1]
+		}
+		if lineCount == 0 {
+			out.WriteString(line)
+			continue
+		}
+		b.WriteString(line[last:])
+		count += lineCount
+		changes = append(changes, TagLineChange{
+			Before: strings.TrimRight(line, "\n"),
+			After:  strings.TrimRight(b.String(), "\n"),
+		})
+		out.WriteString(b.String())
+	}
+	return out.String(), count, changes
+}
+
+// rewriteTagName maps name through mapping, matching the longest key that
+// is name itself or one of its ancestors.
+func rewriteTagName(name string, mapping map[string]string) (string, bool) {
+	for prefix := name; ; {
+		if to, ok := mapping[prefix]; ok {
+			return to + name[len(prefix):], true
+		}
+		i := strings.LastIndex(prefix, "/")
+		if i < 0 {
+			return name, false
+		}
+		prefix = prefix[:i]
+	}
+}
+
+// ReplaceTagTokens calls fn for every #tag outside code in content and
+// substitutes its result for the "#tag" text (the leading whitespace is
+// kept).
+func ReplaceTagTokens(content string, fn func(tag string) string) string {
+	codeRanges := findCodeRanges(content)
+	var b strings.Builder
+	last := 0
+	for _, m := range tagTokenRE.FindAllStringSubmatchIndex(content, -1) {
+		if posInRanges(m[2], codeRanges) {
+			continue
+		}
+		b.WriteString(content[last : m[2]-1])
+		b.WriteString(fn(content[m[2]:m[3]]))
+		last = m[3]
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// TagNode is one level of the tag hierarchy. Notes counts notes tagged with
+// exactly Path; Total also counts notes tagged with any descendant, each
+// note once.
+type TagNode struct {
+	Name     string     `json:"name"` // last path segment
+	Path     string     `json:"path"` // full tag, without "#"
+	Notes    int        `json:"notes"`
+	Total    int        `json:"total"`
+	Children []*TagNode `json:"children,omitempty"`
+}
+
+// BuildTagTree arranges the tags of a set of notes — one ExtractTags result
+// per note — into a tree sorted by name. Ancestors that are never used on
+// their own ("project" for "#project/site") appear with Notes == 0.
+func BuildTagTree(noteTags [][]string) []*TagNode {
+	root := &TagNode{}
+	nodes := map[string]*TagNode{"": root}
+	var node func(path string) *TagNode
+	node = func(path string) *TagNode {
+		if n, ok := nodes[path]; ok {
+			return n
+		}
+		parentPath, name := "", path
+		if i := strings.LastIndex(path, "/"); i >= 0 {
+			parentPath, name = path[:i], path[i+1:]
+		}
+		parent := node(parentPath)
+		n := &TagNode{Name: name, Path: path}
+		parent.Children = append(parent.Children, n)
+		nodes[path] = n
+		return n
+	}
+
+	for _, tags := range noteTags {
+		counted := make(map[*TagNode]bool)
+		for _, tag := range tags {
+			n := node(tag)
+			n.Notes++
+			for p := tag; ; {
+				if a := nodes[p]; !counted[a] {
+					counted[a] = true
+					a.Total++
+				}
+				i := strings.LastIndex(p, "/")
+				if i < 0 {
+					break
+				}
+				p = p[:i]
+			}
+		}
+	}
+
+	var sortNodes func([]*TagNode)
+	sortNodes = func(ns []*TagNode) {
+		sort.Slice(ns, func(i, j int) bool { return ns[i].Name < ns[j].Name })
+		for _, n := range ns {
+			sortNodes(n.Children)
+		}
+	}
+	sortNodes(root.Children)
+	return root.Children
+}
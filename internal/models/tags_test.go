@@ -0,0 +1,85 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractTags(t *testing.T) {
+	content := "# Heading\nPlanning #work and #home, again #work.\n" +
+		"See http://example.com/#anchor and `#notatag`.\n```\n#alsonot\n```\n- [ ] call #dentist"
+	want := []string{"work", "home", "dentist"}
+	if got := ExtractTags(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractTags = %v, want %v", got, want)
+	}
+}
+
+func TestRewriteTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		mapping map[string]string
+		want    string
+		count   int
+	}{
+		{"rename", "- [ ] ship #wrk\n#wrk notes", map[string]string{"wrk": "work"}, "- [ ] ship #work\n#work notes", 2},
+		{"whole token only", "#workshop #Work #wrk-old", map[string]string{"wrk": "work", "Work": "work"}, "#workshop #work #wrk-old", 1},
+		{"merge drops duplicate", "- [ ] a #projx #proj-x", map[string]string{"projx": "x", "proj-x": "x"}, "- [ ] a #x", 2},
+		{"existing target kept", "#x then #projx", map[string]string{"projx": "x"}, "#x then", 1},
+		{"code untouched", "`#wrk`\n```\n#wrk\n```\n#wrk", map[string]string{"wrk": "work"}, "`#wrk`\n```\n#wrk\n```\n#work", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, count, changes := RewriteTags(tt.content, tt.mapping)
+			if got != tt.want || count != tt.count {
+				t.Errorf("RewriteTags = %q, %d; want %q, %d", got, count, tt.want, tt.count)
+			}
+			if count > 0 && len(changes) == 0 {
+				t.Error("no line changes reported")
+			}
+		})
+	}
+}
+
+func TestNormalizeTagName(t *testing.T) {
+	if name, ok := NormalizeTagName(" #work "); !ok || name != "work" {
+		t.Errorf("NormalizeTagName = %q, %v", name, ok)
+	}
+	for _, bad := range []string{"", "#", "12", "two words"} {
+		if _, ok := NormalizeTagName(bad); ok {
+			t.Errorf("NormalizeTagName(%q) accepted", bad)
+		}
+	}
+}
+
+func TestNestedTags(t *testing.T) {
+	if got := ExtractTags("- [ ] mockups #project/clientA/website #project"); !reflect.DeepEqual(got, []string{"project/clientA/website", "project"}) {
+		t.Errorf("ExtractTags = %v", got)
+	}
+	if !TagMatches("project/clientA", "project") || TagMatches("projects", "project") || TagMatches("project", "project/clientA") {
+		t.Error("TagMatches should match the tag itself and its descendants only")
+	}
+	got, _, _ := RewriteTags("#proj #proj/site #projx", map[string]string{"proj": "work/acme"})
+	if got != "#work/acme #work/acme/site #projx" {
+		t.Errorf("RewriteTags = %q", got)
+	}
+}
+
+func TestBuildTagTree(t *testing.T) {
+	tree := BuildTagTree([][]string{
+		{"project/clientA/website", "project/clientB"},
+		{"project/clientA"},
+		{"home"},
+	})
+	if len(tree) != 2 || tree[0].Path != "home" || tree[1].Path != "project" {
+		t.Fatalf("roots = %+v", tree)
+	}
+	project := tree[1]
+	if project.Notes != 0 || project.Total != 2 || len(project.Children) != 2 {
+		t.Errorf("project = %+v", project)
+	}
+	clientA := project.Children[0]
+	if clientA.Name != "clientA" || clientA.Notes != 1 || clientA.Total != 2 || clientA.Children[0].Path != "project/clientA/website" {
+		t.Errorf("clientA = %+v", clientA)
+	}
+}
@@ -16,10 +16,26 @@ import (
 type Task struct {
 	Index    int       `json:"index"`              // Unique global identifier
 	Checked  bool      `json:"checked"`            // Completion state
+	State    TaskState `json:"state"`              // todo, doing or done; Checked means done
 	Text     string    `json:"text"`               // Full task text including checkbox + metadata tokens
 	Priority int       `json:"priority,omitempty"` // 0 = none, 1..3 = !p1..!p3; lower = more urgent
 	DueDate  time.Time `json:"due_date,omitempty"` // zero value = no due date
 	Tags     []string  `json:"tags,omitempty"`     // values without the leading "#"
+	Section  string    `json:"section,omitempty"`  // text of the nearest markdown heading above the task
+	// Depth is how many tasks this one is nested under (0 = top level) and
+	// ParentIndex the Index of the task directly above it in that nesting,
+	// or -1; see Note.linkSubtasks.
+	Depth       int `json:"depth"`
+	ParentIndex int `json:"parent_index"`
+
+	// NoteID, Line and Offset locate the task in notes.md: its note's
+	// HistoryKey, the 1-based file line of its checkbox and the checkbox's
+	// UTF-16 offset in the note body. Only NoteManager.GetAllTasks sets them.
+	NoteID string `json:"note_id,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+
+	parent int // position of the parent in Note.Tasks, or -1
 }
 
 // TaskInfo represents task information for API responses
@@ -33,6 +49,8 @@ type TaskInfo struct {
 // TaskUpdate represents a task update request
 type TaskUpdate struct {
 	Checked bool `json:"checked"`
+	// Cascade applies the same state to every subtask nested under the task.
+	Cascade bool `json:"cascade"`
 }
 
 // Inline metadata token shapes. These are intentionally narrow — they must
@@ -40,8 +58,11 @@ type TaskUpdate struct {
 // mention, "#1" as an issue ref) so all three require a specific structure:
 //
 //	priority:  !p<digit>     where digit is 0..3
-//	due date:  @YYYY-MM-DD   (exact 4-2-2 digit form)
-//	tag:       #<word>       where word is letters/digits/_/- (not pure digits)
+//	due date:  @YYYY-MM-DD   (exact 4-2-2 digit form), optionally with a
+//	           local time of day as @YYYY-MM-DDTHH:MM; @due(YYYY-MM-DD)
+//	           and the Tasks-plugin style 📅 YYYY-MM-DD are read the same
+//	tag:       #<word>       where word is letters/digits/_/- (not pure digits),
+//	           optionally nested as #<word>/<segment>/... (#project/clientA)
 //
 // Tokens must be preceded by whitespace or start-of-text. The trailing
 // boundary uses \b (zero-width) rather than consuming whitespace so that
@@ -49,8 +70,9 @@ type TaskUpdate struct {
 // consumed trailing space would eat the next token's leading anchor.
 var (
 	priorityTokenRE = regexp.MustCompile(`(?:^|\s)!p([0-3])\b`)
-	dueDateTokenRE  = regexp.MustCompile(`(?:^|\s)@(\d{4}-\d{2}-\d{2})\b`)
-	tagTokenRE      = regexp.MustCompile(`(?:^|\s)#([A-Za-z_][A-Za-z0-9_-]*)`)
+	dueDateTokenRE  = regexp.MustCompile(`(?:^|\s)@(\d{4}-\d{2}-\d{2}(?:T\d{2}:\d{2})?)\b`)
+	dueAltTokenRE   = regexp.MustCompile(`(?:^|\s)(?:@due\(\s*(\d{4}-\d{2}-\d{2}(?:T\d{2}:\d{2})?)\s*\)|📅\s*(\d{4}-\d{2}-\d{2}(?:T\d{2}:\d{2})?)\b)`)
+	tagTokenRE      = regexp.MustCompile(`(?:^|\s)#([A-Za-z_][A-Za-z0-9_-]*(?:/[A-Za-z0-9_-]+)*)`)
 )
 
 // ParseTaskMetadata extracts inline priority/due/tag tokens from a task
@@ -75,9 +97,9 @@ func ParseTaskMetadata(line string) (priority int, due time.Time, tags []string)
 		}
 	}
 	if m := dueDateTokenRE.FindStringSubmatch(line); m != nil {
-		if t, err := time.Parse("2006-01-02", m[1]); err == nil {
-			due = t
-		}
+		due = parseDueToken(m[1])
+	} else if m := dueAltTokenRE.FindStringSubmatch(line); m != nil {
+		due = parseDueToken(m[1] + m[2])
 	}
 	for _, m := range tagTokenRE.FindAllStringSubmatch(line, -1) {
 		tags = append(tags, m[1])
@@ -85,12 +107,34 @@ func ParseTaskMetadata(line string) (priority int, due time.Time, tags []string)
 	return priority, due, tags
 }
 
+// parseDueToken parses the value of a due token. Date-only values stay in
+// UTC as they always have; values with a time of day are wall-clock times
+// in the local zone. Invalid values yield the zero time.
+func parseDueToken(v string) time.Time {
+	if len(v) > len("2006-01-02") {
+		t, _ := time.ParseInLocation("2006-01-02T15:04", v, time.Local)
+		return t
+	}
+	t, _ := time.Parse("2006-01-02", v)
+	return t
+}
+
+// FormatDueToken renders t as a due token: "@YYYY-MM-DD", or
+// "@YYYY-MM-DDTHH:MM" when hasTime is set.
+func FormatDueToken(t time.Time, hasTime bool) string {
+	if hasTime {
+		return "@" + t.Format("2006-01-02T15:04")
+	}
+	return "@" + t.Format("2006-01-02")
+}
+
 // CleanTaskText returns the task text with metadata tokens stripped, for
 // display surfaces that want just the human-readable description. The
 // stored Text field on Task always retains the original tokens.
 func CleanTaskText(line string) string {
 	out := priorityTokenRE.ReplaceAllString(line, " ")
 	out = dueDateTokenRE.ReplaceAllString(out, " ")
+	out = dueAltTokenRE.ReplaceAllString(out, " ")
 	out = tagTokenRE.ReplaceAllString(out, " ")
 	return strings.TrimSpace(strings.Join(strings.Fields(out), " "))
 }
\ No newline at end of file
@@ -41,6 +41,12 @@ func TestParseTaskMetadata_DueDate(t *testing.T) {
 		{"- [ ] @2026-13-01 invalid month", time.Time{}},
 		{"- [ ] email me @alice next week", time.Time{}}, // mention-style, not a date
 		{"- [ ] @2026-05-20", time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC)},
+		{"- [ ] @2026-05-20T17:30 with time", time.Date(2026, 5, 20, 17, 30, 0, 0, time.Local)},
+		{"- [ ] @2026-05-20T25:00 invalid time", time.Time{}},
+		{"- [ ] ship @due(2026-05-20)", time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC)},
+		{"- [ ] ship 📅 2026-05-20", time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC)},
+		{"- [ ] ship 📅2026-05-20T09:15", time.Date(2026, 5, 20, 9, 15, 0, 0, time.Local)},
+		{"- [ ] ship @due(next friday)", time.Time{}}, // phrases are resolved on save, not on read
 	}
 	for _, tt := range tests {
 		_, got, _ := ParseTaskMetadata(tt.in)
@@ -0,0 +1,79 @@
+package models
+
+import "strings"
+
+// TaskState is where a task stands on the board. It is stored in the
+// checkbox itself: "[ ]" todo, "[/]" doing, "[x]" done. Checked is true
+// only for done, so tools that know just two states see a task in progress
+// as open.
+type TaskState string
+
+const (
+	TaskTodo  TaskState = "todo"
+	TaskDoing TaskState = "doing"
+	TaskDone  TaskState = "done"
+)
+
+// TaskStates lists the states in board order.
+var TaskStates = []TaskState{TaskTodo, TaskDoing, TaskDone}
+
+// ParseTaskState reads a state name as used by the API, case-insensitively.
+func ParseTaskState(s string) (TaskState, bool) {
+	state := TaskState(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range TaskStates {
+		if state == known {
+			return state, true
+		}
+	}
+	return "", false
+}
+
+// TaskStateOf reads the state of a task from its text, which starts at the
+// checkbox as Task.Text does; todo without one.
+func TaskStateOf(text string) TaskState {
+	if len(text) >= 3 && text[0] == '[' && text[2] == ']' {
+		return taskStateFromMark(text[1:2])
+	}
+	return TaskTodo
+}
+
+// taskStateFromMark maps the character between a checkbox's brackets to
+// its state.
+func taskStateFromMark(mark string) TaskState {
+	switch mark {
+	case "x", "X":
+		return TaskDone
+	case "/":
+		return TaskDoing
+	}
+	return TaskTodo
+}
+
+// Mark returns the checkbox written for the state.
+func (s TaskState) Mark() string {
+	switch s {
+	case TaskDone:
+		return "[x]"
+	case TaskDoing:
+		return "[/]"
+	}
+	return "[ ]"
+}
+
+// SetTaskState rewrites the checkbox of the task with taskIndex to state.
+// Returns false when no task with that index lives in this note.
+func (n *Note) SetTaskState(taskIndex int, state TaskState) bool {
+	for _, task := range n.Tasks {
+		if task.Index != taskIndex {
+			continue
+		}
+		// Text starts at the checkbox; see extractTaskText.
+		newText := state.Mark() + task.Text[min(3, len(task.Text)):]
+		n.Content = strings.Replace(n.Content, task.Text, newText, 1)
+		task.Text = newText
+		task.State = state
+		task.Checked = state == TaskDone
+		return true
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package models
+
+import "testing"
+
+func TestTaskStates(t *testing.T) {
+	n := NewNote("Sprint", "- [ ] Plan\n- [/] Build\n- [X] Ship")
+	n.AssignTaskIndices(0)
+
+	want := []TaskState{TaskTodo, TaskDoing, TaskDone}
+	for i, task := range n.Tasks {
+		if task.State != want[i] || task.Checked != (want[i] == TaskDone) {
+			t.Errorf("task %d: state %q checked %v", i, task.State, task.Checked)
+		}
+	}
+
+	n.SetTaskState(0, TaskDoing)
+	n.UpdateTask(1, true)
+	n.UpdateTask(2, false)
+	if want := "- [/] Plan\n- [x] Build\n- [ ] Ship"; n.Content != want {
+		t.Errorf("content = %q, want %q", n.Content, want)
+	}
+	if n.Tasks[0].State != TaskDoing || n.Tasks[1].State != TaskDone || !n.Tasks[1].Checked {
+		t.Errorf("states not updated: %+v %+v", n.Tasks[0], n.Tasks[1])
+	}
+
+	if _, ok := ParseTaskState("Doing"); !ok {
+		t.Error("ParseTaskState(Doing) failed")
+	}
+	if _, ok := ParseTaskState("blocked"); ok {
+		t.Error("ParseTaskState accepted an unknown state")
+	}
+}
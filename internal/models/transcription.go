@@ -0,0 +1,21 @@
+package models
+
+// TranscriptionConfig enables speech-to-text for uploaded audio. Leave
+// Provider empty to keep transcription off.
+type TranscriptionConfig struct {
+	// Provider is "whisper-cpp" (local, nothing leaves the machine) or
+	// "openai" (any OpenAI-compatible /audio/transcriptions endpoint).
+	Provider string `json:"provider,omitempty"`
+	// Language is an optional ISO-639-1 hint such as "en"; empty lets the
+	// model detect it.
+	Language string `json:"language,omitempty"`
+
+	// whisper-cpp settings.
+	WhisperBinary string `json:"whisper_binary,omitempty"` // default "whisper-cli" on $PATH
+	WhisperModel  string `json:"whisper_model,omitempty"`  // path to a ggml model file
+
+	// openai settings.
+	APIKey string `json:"api_key,omitempty"` // falls back to $OPENAI_API_KEY
+	APIURL string `json:"api_url,omitempty"` // default https://api.openai.com/v1
+	Model  string `json:"model,omitempty"`   // default "whisper-1"
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// TrashFile holds deleted notes next to notes.md until they are restored
+// or purged. It uses the notes.md format with a deletion marker above
+// each note, so it stays readable and recoverable by hand.
+const TrashFile = "trash.md"
+
+// DefaultTrashRetentionDays is how long deleted notes are kept when the
+// folder config doesn't say.
+const DefaultTrashRetentionDays = 30
+
+// TrashedNote is a deleted note and when it was deleted.
+type TrashedNote struct {
+	Note    *Note
+	Deleted time.Time
+}
+
+// ID identifies the entry in the trash API. It is the note's HistoryKey,
+// so a restored note keeps its history.
+func (t TrashedNote) ID() string {
+	return t.Note.HistoryKey()
+}
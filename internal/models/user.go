@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// User is an account in multi-user mode. Each user has their own notes
+// folder and their own set of registered folders for global tasks.
+type User struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	NotesRoot string    `json:"notes_root"` // the folder their NoteFlow opens
+	Created   time.Time `json:"created"`
+}
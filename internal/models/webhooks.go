@@ -0,0 +1,19 @@
+package models
+
+// WebhookConfig registers a URL that is POSTed a JSON payload when notes
+// change or tasks are completed.
+//
+// Stored under "webhooks" in ~/.config/noteflow/noteflow.json:
+//
+//	"webhooks": [
+//	  {"url": "https://example.com/noteflow", "secret": "s3cret", "events": ["task.completed"]}
+//	]
+type WebhookConfig struct {
+	URL string `json:"url"`
+	// Secret signs every payload: the X-NoteFlow-Signature header carries
+	// "sha256=" and the hex HMAC-SHA256 of the body under this key.
+	Secret string `json:"secret,omitempty"`
+	// Events limits the hook to these event types (note.created,
+	// note.updated, note.deleted, task.completed); empty means all.
+	Events []string `json:"events,omitempty"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// wikiLinkRE matches [[Note Title]] and [[Note Title|label]].
+var wikiLinkRE = regexp.MustCompile(`\[\[([^\[\]|\n]+)(?:\|([^\[\]\n]+))?\]\]`)
+
+// WikiLinkKey normalizes a note title or link target for matching: links
+// ignore case and runs of whitespace, so [[weekly  plan]] finds "Weekly Plan".
+func WikiLinkKey(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
+
+// ExtractWikiLinks returns the distinct targets of the [[...]] links in
+// content, normalized with WikiLinkKey, in order of first appearance.
+// Links inside code are skipped the way tags are.
+func ExtractWikiLinks(content string) []string {
+	var links []string
+	seen := make(map[string]bool)
+	ReplaceWikiLinks(content, func(target, _ string) string {
+		if key := WikiLinkKey(target); key != "" && !seen[key] {
+			seen[key] = true
+			links = append(links, key)
+		}
+		return ""
+	})
+	return links
+}
+
+// ReplaceWikiLinks calls fn for every [[...]] link outside code in content
+// and substitutes its result for the link. label is the text after "|", or
+// the target when the link has none.
+func ReplaceWikiLinks(content string, fn func(target, label string) string) string {
+	return ReplaceOutsideCode(content, wikiLinkRE, func(m []string) string {
+		target := strings.TrimSpace(m[1])
+		label := target
+		if m[2] != "" {
+			label = strings.TrimSpace(m[2])
+		}
+		return fn(target, label)
+	})
+}
+
+// ReplaceOutsideCode calls fn for every match of re outside code in
+// content, with the match and its submatches ("" for a group that didn't
+// take part), and substitutes its result for the match.
+func ReplaceOutsideCode(content string, re *regexp.Regexp, fn func(m []string) string) string {
+	codeRanges := findCodeRanges(content)
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(content, -1) {
+		if posInRanges(loc[0], codeRanges) {
+			continue
+		}
+		m := make([]string, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = content[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		b.WriteString(content[last:loc[0]])
+		b.WriteString(fn(m))
+		last = loc[1]
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractWikiLinks(t *testing.T) {
+	content := "See [[Weekly  Plan]] and [[weekly plan|the plan]], [[Ideas]].\n" +
+		"`[[Not A Link]]`\n```\n[[Also Not]]\n```"
+	got := ExtractWikiLinks(content)
+	want := []string{"weekly plan", "ideas"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractWikiLinks = %v, want %v", got, want)
+	}
+}
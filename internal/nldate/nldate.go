@@ -0,0 +1,236 @@
+// Package nldate parses the short, human due-date phrases people type on a
+// phone — "tomorrow 5pm", "next friday", "in 3 days", "oct 20" — into
+// concrete times. It is deliberately small: a fixed grammar with no
+// locale support, so that every accepted phrase has one obvious meaning.
+package nldate
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result is a parsed phrase. HasTime is false for date-only phrases, in
+// which case Time is midnight.
+type Result struct {
+	Time    time.Time
+	HasTime bool
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"jan": time.January, "january": time.January,
+	"feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March,
+	"apr": time.April, "april": time.April,
+	"may": time.May,
+	"jun": time.June, "june": time.June,
+	"jul": time.July, "july": time.July,
+	"aug": time.August, "august": time.August,
+	"sep": time.September, "sept": time.September, "september": time.September,
+	"oct": time.October, "october": time.October,
+	"nov": time.November, "november": time.November,
+	"dec": time.December, "december": time.December,
+}
+
+var (
+	// 5pm, 5:30pm, 5 pm, 17:00
+	clockRE = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+	// in 3 days, in 2 weeks, in 1 month
+	relativeRE = regexp.MustCompile(`^in (\d+) (day|days|week|weeks|month|months)$`)
+	isoRE      = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// Parse interprets phrase relative to now, in now's location. Accepted
+// forms, case-insensitive, optionally followed by a time of day ("5pm",
+// "17:30", "noon", optionally introduced by "at"):
+//
+//	today, tonight, tomorrow (tmr, tmrw)
+//	mon … sun, monday … sunday   the next such day, today included
+//	next mon … next sunday       the next such day, today excluded
+//	next week                    the coming Monday
+//	next month                   the 1st of next month
+//	in N days|weeks|months
+//	oct 20, 20 oct, october 20   this year, or next year once passed
+//	YYYY-MM-DD
+//
+// A bare time of day means today, or tomorrow once that time has passed.
+func Parse(phrase string, now time.Time) (Result, bool) {
+	s := strings.Join(strings.Fields(strings.ToLower(phrase)), " ")
+	if s == "" {
+		return Result{}, false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	datePart, clock, hasClock := splitClock(s)
+	if datePart == "" {
+		if !hasClock {
+			return Result{}, false
+		}
+		t := today.Add(clock)
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return Result{Time: t, HasTime: true}, true
+	}
+
+	day, ok := parseDate(datePart, today)
+	if !ok {
+		return Result{}, false
+	}
+	if datePart == "tonight" && !hasClock {
+		clock, hasClock = 20*time.Hour, true
+	}
+	if hasClock {
+		return Result{Time: day.Add(clock), HasTime: true}, true
+	}
+	return Result{Time: day}, true
+}
+
+// splitClock peels a trailing time of day off s.
+func splitClock(s string) (rest string, clock time.Duration, ok bool) {
+	words := strings.Split(s, " ")
+	// Try the last two words first so "5 pm" is read as one time.
+	for n := 2; n >= 1; n-- {
+		if len(words) < n {
+			continue
+		}
+		if c, ok := parseClock(strings.Join(words[len(words)-n:], " ")); ok {
+			words = words[:len(words)-n]
+			if len(words) > 0 && words[len(words)-1] == "at" {
+				words = words[:len(words)-1]
+			}
+			return strings.Join(words, " "), c, true
+		}
+	}
+	return s, 0, false
+}
+
+func parseClock(s string) (time.Duration, bool) {
+	switch s {
+	case "noon":
+		return 12 * time.Hour, true
+	case "midnight":
+		return 0, true
+	}
+	m := clockRE.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	// A bare number is a day ("20"), not an hour, unless it has minutes.
+	if m[2] == "" && m[3] == "" {
+		return 0, false
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return 0, false
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return 0, false
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, false
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, true
+}
+
+func parseDate(s string, today time.Time) (time.Time, bool) {
+	switch s {
+	case "today", "tonight":
+		return today, true
+	case "tomorrow", "tmr", "tmrw":
+		return today.AddDate(0, 0, 1), true
+	case "next week":
+		return nextWeekday(today, time.Monday, false), true
+	case "next month":
+		return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), true
+	}
+	if wd, ok := weekdays[s]; ok {
+		return nextWeekday(today, wd, true), true
+	}
+	if rest, ok := strings.CutPrefix(s, "next "); ok {
+		if wd, ok := weekdays[rest]; ok {
+			return nextWeekday(today, wd, false), true
+		}
+	}
+	if m := relativeRE.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch strings.TrimSuffix(m[2], "s") {
+		case "day":
+			return today.AddDate(0, 0, n), true
+		case "week":
+			return today.AddDate(0, 0, 7*n), true
+		default:
+			return today.AddDate(0, n, 0), true
+		}
+	}
+	if isoRE.MatchString(s) {
+		t, err := time.ParseInLocation("2006-01-02", s, today.Location())
+		return t, err == nil
+	}
+	return parseMonthDay(s, today)
+}
+
+// nextWeekday returns the next wd on or after today (inclusive) or
+// strictly after it.
+func nextWeekday(today time.Time, wd time.Weekday, inclusive bool) time.Time {
+	days := (int(wd) - int(today.Weekday()) + 7) % 7
+	if days == 0 && !inclusive {
+		days = 7
+	}
+	return today.AddDate(0, 0, days)
+}
+
+// parseMonthDay handles "oct 20", "20 oct" and "october 20th".
+func parseMonthDay(s string, today time.Time) (time.Time, bool) {
+	parts := strings.Split(s, " ")
+	if len(parts) != 2 {
+		return time.Time{}, false
+	}
+	month, ok := months[parts[0]]
+	dayStr := parts[1]
+	if !ok {
+		month, ok = months[parts[1]]
+		dayStr = parts[0]
+	}
+	if !ok {
+		return time.Time{}, false
+	}
+	dayStr = strings.TrimRight(dayStr, "stndrh") // 1st, 2nd, 3rd, 20th
+	day, err := strconv.Atoi(dayStr)
+	if err != nil || day < 1 || day > 31 {
+		return time.Time{}, false
+	}
+	t := time.Date(today.Year(), month, day, 0, 0, 0, 0, today.Location())
+	if t.Day() != day {
+		return time.Time{}, false // e.g. feb 30
+	}
+	if t.Before(today) {
+		t = t.AddDate(1, 0, 0)
+	}
+	return t, true
+}
@@ -0,0 +1,61 @@
+package nldate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*3600)
+	// Friday 2026-10-16, 14:00 local.
+	now := time.Date(2026, 10, 16, 14, 0, 0, 0, loc)
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, loc) }
+	at := func(m time.Month, d, h, min int) time.Time { return time.Date(2026, m, d, h, min, 0, 0, loc) }
+
+	tests := []struct {
+		in      string
+		want    time.Time
+		hasTime bool
+	}{
+		{"today", day(10, 16), false},
+		{"Tomorrow", day(10, 17), false},
+		{"tomorrow 5pm", at(10, 17, 17, 0), true},
+		{"tomorrow at 9:30am", at(10, 17, 9, 30), true},
+		{"tonight", at(10, 16, 20, 0), true},
+		{"fri", day(10, 16), false},
+		{"next friday", day(10, 23), false},
+		{"sat", day(10, 17), false},
+		{"sat 5 pm", at(10, 17, 17, 0), true},
+		{"monday noon", at(10, 19, 12, 0), true},
+		{"next week", day(10, 19), false},
+		{"next month", day(11, 1), false},
+		{"in 3 days", day(10, 19), false},
+		{"in 2 weeks", day(10, 30), false},
+		{"oct 20", day(10, 20), false},
+		{"20th october", day(10, 20), false},
+		{"jan 5", time.Date(2027, 1, 5, 0, 0, 0, 0, loc), false}, // already passed this year
+		{"2026-12-01", day(12, 1), false},
+		{"3pm", at(10, 16, 15, 0), true},
+		{"9am", at(10, 17, 9, 0), true}, // already passed today
+		{"17:45", at(10, 16, 17, 45), true},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.in, now)
+		if !ok {
+			t.Errorf("Parse(%q) failed", tt.in)
+			continue
+		}
+		if !got.Time.Equal(tt.want) || got.HasTime != tt.hasTime {
+			t.Errorf("Parse(%q) = %v (time=%v), want %v (time=%v)", tt.in, got.Time, got.HasTime, tt.want, tt.hasTime)
+		}
+	}
+}
+
+func TestParse_Rejects(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	for _, in := range []string{"", "someday", "feb 30", "13pm", "20", "next blursday", "2 + 2"} {
+		if got, ok := Parse(in, now); ok {
+			t.Errorf("Parse(%q) = %v, want failure", in, got)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Package notify delivers short out-of-band alerts (overdue tasks, failed
+// archives, webhook errors) to push services the user already has on their
+// phone, or to a Slack channel. Channels are deliberately thin HTTP clients over the providers'
+// public APIs — no SDKs — so adding one costs no new dependencies.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// Priority levels shared by every channel. Each implementation maps these
+// onto its provider's own scale.
+const (
+	PriorityLow     = -1
+	PriorityDefault = 0
+	PriorityHigh    = 1
+)
+
+// Message is a single alert. Title and Body are plain text; URL, when set,
+// is attached as the tap/click target.
+type Message struct {
+	Title    string
+	Body     string
+	Priority int
+	Tags     []string
+	URL      string
+}
+
+// Notifier sends a Message to one or more push channels.
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// requestTimeout bounds every provider call so a slow push service can never
+// stall the background sync loop that triggers most alerts.
+const requestTimeout = 10 * time.Second
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: requestTimeout}
+}
+
+// New builds a Notifier from config. It returns (nil, nil) when no channel
+// is configured so callers can treat "no notifier" as the normal case.
+func New(cfg models.NotificationsConfig) (Notifier, error) {
+	var channels Multi
+	if cfg.Ntfy != nil {
+		if cfg.Ntfy.Topic == "" {
+			return nil, fmt.Errorf("notifications.ntfy: topic is required")
+		}
+		channels = append(channels, NewNtfy(*cfg.Ntfy))
+	}
+	if cfg.Pushover != nil {
+		if cfg.Pushover.Token == "" || cfg.Pushover.User == "" {
+			return nil, fmt.Errorf("notifications.pushover: token and user are required")
+		}
+		channels = append(channels, NewPushover(*cfg.Pushover))
+	}
+	if cfg.Slack != nil {
+		if u := cfg.Slack.WebhookURL; !strings.HasPrefix(u, "https://") && !strings.HasPrefix(u, "http://") {
+			return nil, fmt.Errorf("notifications.slack: webhook_url is required")
+		}
+		channels = append(channels, NewSlack(*cfg.Slack))
+	}
+	switch len(channels) {
+	case 0:
+		return nil, nil
+	case 1:
+		return channels[0], nil
+	default:
+		return channels, nil
+	}
+}
+
+// Multi fans a message out to several channels. Every channel is attempted
+// even if an earlier one fails; the returned error joins all failures.
+type Multi []Notifier
+
+// Notify implements Notifier.
+func (m Multi) Notify(ctx context.Context, msg Message) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Send is a nil-safe convenience wrapper: it is a no-op when n is nil, so
+// call sites don't need to guard every alert with an "is configured" check.
+func Send(ctx context.Context, n Notifier, msg Message) error {
+	if n == nil {
+		return nil
+	}
+	return n.Notify(ctx, msg)
+}
@@ -0,0 +1,201 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// Each channel is exercised against an httptest server standing in for the
+// provider, pinning the wire format (headers for ntfy, form fields for
+// Pushover) that the real services expect.
+
+func TestNtfy_PublishesWithHeaders(t *testing.T) {
+	var gotPath, gotBody string
+	var gotHeader http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotHeader = r.Header.Clone()
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+	}))
+	defer srv.Close()
+
+	n := NewNtfy(models.NtfyConfig{Server: srv.URL + "/", Topic: "nf-test", Token: "tk"})
+	err := n.Notify(context.Background(), Message{
+		Title:    "hello",
+		Body:     "two tasks overdue",
+		Priority: PriorityHigh,
+		Tags:     []string{"warning", "calendar"},
+		URL:      "http://localhost:8000/global-tasks",
+	})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if gotPath != "/nf-test" {
+		t.Errorf("path = %q, want /nf-test", gotPath)
+	}
+	if gotBody != "two tasks overdue" {
+		t.Errorf("body = %q", gotBody)
+	}
+	checks := map[string]string{
+		"Title":         "hello",
+		"Priority":      "4",
+		"Tags":          "warning,calendar",
+		"Click":         "http://localhost:8000/global-tasks",
+		"Authorization": "Bearer tk",
+	}
+	for k, want := range checks {
+		if got := gotHeader.Get(k); got != want {
+			t.Errorf("header %s = %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestNtfy_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden topic", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	err := NewNtfy(models.NtfyConfig{Server: srv.URL, Topic: "x"}).Notify(context.Background(), Message{Body: "b"})
+	if err == nil || !strings.Contains(err.Error(), "forbidden topic") {
+		t.Errorf("err = %v, want provider message surfaced", err)
+	}
+}
+
+func TestPushover_PostsForm(t *testing.T) {
+	var form url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm: %v", err)
+		}
+		form = r.PostForm
+		w.Write([]byte(`{"status":1}`))
+	}))
+	defer srv.Close()
+
+	p := NewPushover(models.PushoverConfig{Token: "app", User: "usr"})
+	p.endpoint = srv.URL
+	if err := p.Notify(context.Background(), Message{Title: "t", Body: "b", Priority: PriorityHigh}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	for k, want := range map[string]string{"token": "app", "user": "usr", "title": "t", "message": "b", "priority": "1"} {
+		if got := form.Get(k); got != want {
+			t.Errorf("form %s = %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestSlack_PostsMrkdwn(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("content type = %q", r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	err := NewSlack(models.SlackWebhookConfig{WebhookURL: srv.URL}).Notify(context.Background(), Message{
+		Title:    "NoteFlow: 1 overdue task(s)",
+		Body:     "• pay <rent> & bills (home)",
+		Priority: PriorityHigh,
+		URL:      "http://localhost:8000/global-tasks",
+	})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	want := ":warning: *NoteFlow: 1 overdue task(s)*\n• pay &lt;rent&gt; &amp; bills (home)\n<http://localhost:8000/global-tasks|Open in NoteFlow>"
+	if got["text"] != want {
+		t.Errorf("text = %q, want %q", got["text"], want)
+	}
+}
+
+func TestSlack_ErrorKeepsURLSecret(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	err := NewSlack(models.SlackWebhookConfig{WebhookURL: srv.URL + "/services/secret"}).Notify(context.Background(), Message{Body: "b"})
+	if err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("err = %v, want provider message surfaced", err)
+	}
+	srv.Close()
+	err = NewSlack(models.SlackWebhookConfig{WebhookURL: srv.URL + "/services/secret"}).Notify(context.Background(), Message{Body: "b"})
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("err = %v, want one without the webhook URL", err)
+	}
+}
+
+func TestNew_ChannelSelection(t *testing.T) {
+	n, err := New(models.NotificationsConfig{})
+	if err != nil || n != nil {
+		t.Errorf("empty config: got (%v, %v), want (nil, nil)", n, err)
+	}
+
+	n, err = New(models.NotificationsConfig{Ntfy: &models.NtfyConfig{Topic: "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := n.(*Ntfy); !ok {
+		t.Errorf("single channel: got %T, want *Ntfy", n)
+	}
+
+	n, err = New(models.NotificationsConfig{
+		Ntfy:     &models.NtfyConfig{Topic: "a"},
+		Pushover: &models.PushoverConfig{Token: "t", User: "u"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := n.(Multi); !ok || len(m) != 2 {
+		t.Errorf("two channels: got %T, want Multi of 2", n)
+	}
+
+	if _, err := New(models.NotificationsConfig{Ntfy: &models.NtfyConfig{}}); err == nil {
+		t.Error("ntfy without topic should be rejected")
+	}
+	if _, err := New(models.NotificationsConfig{Pushover: &models.PushoverConfig{Token: "t"}}); err == nil {
+		t.Error("pushover without user should be rejected")
+	}
+	if _, err := New(models.NotificationsConfig{Slack: &models.SlackWebhookConfig{}}); err == nil {
+		t.Error("slack without webhook_url should be rejected")
+	}
+}
+
+type recordingNotifier struct {
+	got []Message
+	err error
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, m Message) error {
+	r.got = append(r.got, m)
+	return r.err
+}
+
+func TestMulti_AttemptsEveryChannel(t *testing.T) {
+	a := &recordingNotifier{err: errors.New("a down")}
+	b := &recordingNotifier{}
+	err := Multi{a, b}.Notify(context.Background(), Message{Body: "x"})
+	if err == nil || !strings.Contains(err.Error(), "a down") {
+		t.Errorf("err = %v, want a's failure surfaced", err)
+	}
+	if len(b.got) != 1 {
+		t.Errorf("second channel not attempted after first failed")
+	}
+}
+
+func TestSend_NilNotifierIsNoop(t *testing.T) {
+	if err := Send(context.Background(), nil, Message{Body: "x"}); err != nil {
+		t.Errorf("Send(nil) = %v, want nil", err)
+	}
+}
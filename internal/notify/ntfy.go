@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+const defaultNtfyServer = "https://ntfy.sh"
+
+// Ntfy publishes messages to an ntfy topic using the plain-HTTP publish API:
+// the body is the message and metadata travels in headers. See
+// https://docs.ntfy.sh/publish/.
+type Ntfy struct {
+	server string
+	topic  string
+	token  string
+	client *http.Client
+}
+
+// NewNtfy creates an ntfy channel from config.
+func NewNtfy(cfg models.NtfyConfig) *Ntfy {
+	server := strings.TrimRight(cfg.Server, "/")
+	if server == "" {
+		server = defaultNtfyServer
+	}
+	return &Ntfy{
+		server: server,
+		topic:  cfg.Topic,
+		token:  cfg.Token,
+		client: newHTTPClient(),
+	}
+}
+
+// Notify implements Notifier.
+func (n *Ntfy) Notify(ctx context.Context, msg Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.server+"/"+n.topic, strings.NewReader(msg.Body))
+	if err != nil {
+		return fmt.Errorf("ntfy: build request: %w", err)
+	}
+	if msg.Title != "" {
+		req.Header.Set("Title", msg.Title)
+	}
+	req.Header.Set("Priority", ntfyPriority(msg.Priority))
+	if len(msg.Tags) > 0 {
+		req.Header.Set("Tags", strings.Join(msg.Tags, ","))
+	}
+	if msg.URL != "" {
+		req.Header.Set("Click", msg.URL)
+	}
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ntfy: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("ntfy: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// ntfyPriority maps our three-level scale onto ntfy's 1..5.
+func ntfyPriority(p int) string {
+	switch {
+	case p < PriorityDefault:
+		return "2"
+	case p > PriorityDefault:
+		return "4"
+	default:
+		return "3"
+	}
+}
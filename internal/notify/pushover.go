@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+const pushoverEndpoint = "https://api.pushover.net/1/messages.json"
+
+// Pushover sends messages through the Pushover message API. See
+// https://pushover.net/api.
+type Pushover struct {
+	endpoint string
+	token    string
+	user     string
+	client   *http.Client
+}
+
+// NewPushover creates a Pushover channel from config.
+func NewPushover(cfg models.PushoverConfig) *Pushover {
+	return &Pushover{
+		endpoint: pushoverEndpoint,
+		token:    cfg.Token,
+		user:     cfg.User,
+		client:   newHTTPClient(),
+	}
+}
+
+// Notify implements Notifier.
+func (p *Pushover) Notify(ctx context.Context, msg Message) error {
+	form := url.Values{
+		"token":    {p.token},
+		"user":     {p.user},
+		"message":  {msg.Body},
+		"priority": {strconv.Itoa(msg.Priority)},
+	}
+	if msg.Title != "" {
+		form.Set("title", msg.Title)
+	}
+	if msg.URL != "" {
+		form.Set("url", msg.URL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("pushover: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("pushover: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("pushover: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
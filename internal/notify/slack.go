@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// slackEscaper escapes the characters Slack's mrkdwn reads as markup.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Slack posts messages to a Slack channel through an incoming webhook. See
+// https://api.slack.com/messaging/webhooks.
+type Slack struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlack creates a Slack channel from config.
+func NewSlack(cfg models.SlackWebhookConfig) *Slack {
+	return &Slack{
+		webhookURL: cfg.WebhookURL,
+		client:     newHTTPClient(),
+	}
+}
+
+// Notify implements Notifier.
+func (s *Slack) Notify(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(map[string]string{"text": slackText(msg)})
+	if err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("slack: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The webhook URL is the credential; keep it out of logs.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("slack: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// slackText renders msg as mrkdwn: the title in bold, the body, and the
+// URL as a link. Slack has no priorities, so a high one adds a warning.
+func slackText(msg Message) string {
+	var b strings.Builder
+	if msg.Priority > PriorityDefault {
+		b.WriteString(":warning: ")
+	}
+	if msg.Title != "" {
+		b.WriteString("*" + slackEscaper.Replace(msg.Title) + "*\n")
+	}
+	b.WriteString(slackEscaper.Replace(msg.Body))
+	if msg.URL != "" {
+		b.WriteString("\n<" + msg.URL + "|Open in NoteFlow>")
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,351 @@
+// Package openapi builds the OpenAPI 3 document describing NoteFlow's REST
+// API. Routes are described by Operations next to the handlers they mount;
+// request and response schemas are derived from the Go types the handlers
+// read and write, so the document can't drift from the code.
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Param is a query parameter or form field.
+type Param struct {
+	Name        string
+	Description string
+	// Binary marks a file upload field.
+	Binary bool
+}
+
+// Operation describes one API route.
+type Operation struct {
+	Method  string // GET, POST, ...
+	Path    string // relative to the API root, in Fiber syntax: /notes/:index
+	Tag     string // groups operations in viewers, e.g. "notes"
+	Summary string
+	Query   []Param
+	// Headers lists request headers the operation reads.
+	Headers []Param
+	// Body is a value of the JSON request body's type; nil for none.
+	Body any
+	// Form lists the fields of a multipart/form-data body.
+	Form []Param
+	// Data is a value of the type a successful call puts in the
+	// response's "data"; nil when it carries none.
+	Data any
+	// Bare marks a JSON response that is Data itself rather than the
+	// {"status", "message", "data"} envelope.
+	Bare bool
+	// Produces is the content type of a response that isn't JSON, such as
+	// "text/html"; Data and Bare are ignored then.
+	Produces string
+	// Conditional marks a GET whose response carries an ETag, answered
+	// with 304 Not Modified when If-None-Match already has it.
+	Conditional bool
+}
+
+// Info describes the API as a whole.
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+	ServerURL   string // the API root, e.g. "/api/v1"
+	// SessionCookie, when set, marks the API as requiring a bearer token
+	// or the login session cookie of this name.
+	SessionCookie string
+}
+
+// Document returns the OpenAPI 3.0 document for ops, ready to be
+// marshalled to JSON.
+func Document(info Info, ops []Operation) map[string]any {
+	g := &generator{schemas: map[string]any{}, named: map[string]reflect.Type{}}
+	g.schemas["APIResponse"] = map[string]any{
+		"type":     "object",
+		"required": []string{"status"},
+		"properties": map[string]any{
+			"status":  map[string]any{"type": "string", "enum": []string{"success", "error"}},
+			"message": map[string]any{"type": "string"},
+			"data":    map[string]any{},
+		},
+	}
+
+	paths := map[string]any{}
+	for _, op := range ops {
+		path, params := pathParams(op.Path)
+		item, _ := paths[path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[path] = item
+		}
+		item[strings.ToLower(op.Method)] = g.operation(op, params)
+	}
+
+	components := map[string]any{"schemas": g.schemas}
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       info.Title,
+			"version":     info.Version,
+			"description": info.Description,
+		},
+		"servers":    []any{map[string]any{"url": info.ServerURL}},
+		"paths":      paths,
+		"components": components,
+	}
+	if info.SessionCookie != "" {
+		components["securitySchemes"] = map[string]any{
+			"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+			"cookieAuth": map[string]any{"type": "apiKey", "in": "cookie", "name": info.SessionCookie},
+		}
+		doc["security"] = []any{
+			map[string]any{"bearerAuth": []string{}},
+			map[string]any{"cookieAuth": []string{}},
+		}
+	}
+	return doc
+}
+
+var fiberParam = regexp.MustCompile(`:([A-Za-z_]+)`)
+
+// pathParams converts a Fiber path to OpenAPI's {name} form and returns
+// the names of its parameters.
+func pathParams(path string) (string, []string) {
+	var names []string
+	for _, m := range fiberParam.FindAllStringSubmatch(path, -1) {
+		names = append(names, m[1])
+	}
+	return fiberParam.ReplaceAllString(path, "{$1}"), names
+}
+
+func (g *generator) operation(op Operation, pathNames []string) map[string]any {
+	out := map[string]any{
+		"summary":     op.Summary,
+		"operationId": operationID(op),
+	}
+	if op.Tag != "" {
+		out["tags"] = []string{op.Tag}
+	}
+
+	var params []any
+	for _, name := range pathNames {
+		typ := "string"
+		// Note, task and folder positions and DB ids are numbers.
+		if name == "index" || name == "id" {
+			typ = "integer"
+		}
+		params = append(params, map[string]any{
+			"name": name, "in": "path", "required": true,
+			"schema": map[string]any{"type": typ},
+		})
+	}
+	for _, q := range op.Query {
+		params = append(params, map[string]any{
+			"name": q.Name, "in": "query", "description": q.Description,
+			"schema": map[string]any{"type": "string"},
+		})
+	}
+	for _, h := range op.Headers {
+		params = append(params, map[string]any{
+			"name": h.Name, "in": "header", "description": h.Description,
+			"schema": map[string]any{"type": "string"},
+		})
+	}
+	if op.Conditional {
+		params = append(params, map[string]any{
+			"name": "If-None-Match", "in": "header", "description": "ETag of the copy the client has",
+			"schema": map[string]any{"type": "string"},
+		})
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+
+	switch {
+	case op.Body != nil:
+		out["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": g.schema(reflect.TypeOf(op.Body))},
+			},
+		}
+	case len(op.Form) > 0:
+		props := map[string]any{}
+		for _, f := range op.Form {
+			field := map[string]any{"type": "string", "description": f.Description}
+			if f.Binary {
+				field["format"] = "binary"
+			}
+			props[f.Name] = field
+		}
+		out["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"multipart/form-data": map[string]any{
+					"schema": map[string]any{"type": "object", "properties": props},
+				},
+			},
+		}
+	}
+
+	var ok map[string]any
+	switch {
+	case op.Produces != "":
+		ok = map[string]any{op.Produces: map[string]any{"schema": map[string]any{"type": "string"}}}
+	case op.Bare:
+		ok = map[string]any{"application/json": map[string]any{"schema": g.schema(reflect.TypeOf(op.Data))}}
+	case op.Data != nil:
+		ok = map[string]any{"application/json": map[string]any{"schema": map[string]any{
+			"allOf": []any{
+				ref("APIResponse"),
+				map[string]any{"properties": map[string]any{"data": g.schema(reflect.TypeOf(op.Data))}},
+			},
+		}}}
+	default:
+		ok = map[string]any{"application/json": map[string]any{"schema": ref("APIResponse")}}
+	}
+	responses := map[string]any{
+		"200": map[string]any{"description": "Success", "content": ok},
+		"default": map[string]any{
+			"description": "Error",
+			"content":     map[string]any{"application/json": map[string]any{"schema": ref("APIResponse")}},
+		},
+	}
+	if op.Conditional {
+		responses["200"].(map[string]any)["headers"] = map[string]any{
+			"ETag": map[string]any{"schema": map[string]any{"type": "string"}},
+		}
+		responses["304"] = map[string]any{"description": "Not modified since the If-None-Match ETag"}
+	}
+	out["responses"] = responses
+	return out
+}
+
+// operationID names an operation after its method and path, e.g.
+// GET /notes/:index/history -> getNotesIndexHistory.
+func operationID(op Operation) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	for _, word := range strings.FieldsFunc(op.Path, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+// generator derives JSON schemas from Go types. Named struct types become
+// components, so recursive types and shared models are written once.
+type generator struct {
+	schemas map[string]any
+	named   map[string]reflect.Type // component name -> its type
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+func (g *generator) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		return map[string]any{} // custom encoding; any value
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := g.componentName(t)
+		if _, done := g.schemas[name]; !done {
+			g.schemas[name] = nil // placeholder: recursive fields see it as done
+			g.schemas[name] = g.object(t)
+		}
+		return ref(name)
+	}
+	return map[string]any{}
+}
+
+// componentName names the component of struct type t after the type,
+// qualified by its package when another package has a type of that name.
+func (g *generator) componentName(t reflect.Type) string {
+	name := t.Name()
+	if other, ok := g.named[name]; ok && other != t {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+	g.named[name] = t
+	return name
+}
+
+// object is the inline schema of struct type t, following encoding/json:
+// exported fields under their json names, embedded structs flattened.
+func (g *generator) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	g.fields(t, props, &required)
+	out := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		out["required"] = required
+	}
+	return out
+}
+
+func (g *generator) fields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, props, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
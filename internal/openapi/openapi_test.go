@@ -0,0 +1,155 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type node struct {
+	Name     string    `json:"name"`
+	Children []*node   `json:"children,omitempty"`
+	Seen     time.Time `json:"seen"`
+	secret   string
+	Skipped  string `json:"-"`
+}
+
+type embedded struct {
+	ID int `json:"id"`
+}
+
+type request struct {
+	embedded
+	Tags  []string       `json:"tags"`
+	Limit *int           `json:"limit"`
+	Extra map[string]any `json:"extra,omitempty"`
+}
+
+// roundTrip marshals doc the way it is served and decodes it generically.
+func roundTrip(t *testing.T, doc map[string]any) map[string]any {
+	t.Helper()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// get walks a decoded document along keys.
+func get(t *testing.T, v any, keys ...string) any {
+	t.Helper()
+	for _, k := range keys {
+		m, ok := v.(map[string]any)
+		if !ok {
+			t.Fatalf("no %q in %v", k, v)
+		}
+		v = m[k]
+	}
+	return v
+}
+
+func TestDocument_DescribesOperations(t *testing.T) {
+	doc := roundTrip(t, Document(Info{Title: "T", Version: "1", ServerURL: "/api/v1"}, []Operation{
+		{Method: "GET", Path: "/nodes/:index", Summary: "Get a node", Data: node{}},
+		{Method: "PUT", Path: "/nodes/:index/:name", Body: request{}, Bare: true, Data: []string{}},
+		{Method: "GET", Path: "/nodes/raw", Produces: "text/markdown", Query: []Param{{Name: "tag"}}, Conditional: true},
+	}))
+
+	if got := get(t, doc, "openapi"); got != "3.0.3" {
+		t.Errorf("openapi = %v", got)
+	}
+	if _, ok := doc["security"]; ok {
+		t.Error("security set without a session cookie")
+	}
+
+	op := get(t, doc, "paths", "/nodes/{index}", "get")
+	if id := get(t, op, "operationId"); id != "getNodesIndex" {
+		t.Errorf("operationId = %v", id)
+	}
+	params := get(t, op, "parameters").([]any)
+	if len(params) != 1 || get(t, params[0], "schema", "type") != "integer" {
+		t.Errorf("parameters = %v", params)
+	}
+	// Data is wrapped in the APIResponse envelope.
+	allOf := get(t, op, "responses", "200", "content", "application/json", "schema", "allOf").([]any)
+	if get(t, allOf[1], "properties", "data", "$ref") != "#/components/schemas/node" {
+		t.Errorf("envelope = %v", allOf)
+	}
+
+	put := get(t, doc, "paths", "/nodes/{index}/{name}", "put")
+	params = get(t, put, "parameters").([]any)
+	if len(params) != 2 || get(t, params[1], "schema", "type") != "string" {
+		t.Errorf("parameters = %v", params)
+	}
+	if get(t, put, "responses", "200", "content", "application/json", "schema", "type") != "array" {
+		t.Error("bare response is not the data itself")
+	}
+
+	raw := get(t, doc, "paths", "/nodes/raw", "get", "responses", "200", "content")
+	if _, ok := raw.(map[string]any)["text/markdown"]; !ok {
+		t.Errorf("content = %v", raw)
+	}
+	// Conditional GETs take If-None-Match and may answer 304.
+	rawOp := get(t, doc, "paths", "/nodes/raw", "get")
+	params = get(t, rawOp, "parameters").([]any)
+	if len(params) != 2 || get(t, params[1], "in") != "header" || get(t, params[1], "name") != "If-None-Match" {
+		t.Errorf("parameters = %v", params)
+	}
+	if get(t, rawOp, "responses", "304") == nil || get(t, rawOp, "responses", "200", "headers", "ETag") == nil {
+		t.Errorf("responses = %v", get(t, rawOp, "responses"))
+	}
+	if get(t, op, "responses", "304") != nil {
+		t.Error("304 documented for an unconditional GET")
+	}
+}
+
+func TestDocument_Schemas(t *testing.T) {
+	doc := roundTrip(t, Document(Info{}, []Operation{
+		{Method: "POST", Path: "/x", Body: request{}, Data: node{}},
+	}))
+	schemas := get(t, doc, "components", "schemas")
+
+	n := get(t, schemas, "node")
+	props := get(t, n, "properties").(map[string]any)
+	if len(props) != 3 {
+		t.Errorf("node properties = %v, want name, children and seen", props)
+	}
+	// Recursive types refer to themselves.
+	if get(t, props["children"], "items", "$ref") != "#/components/schemas/node" {
+		t.Errorf("children = %v", props["children"])
+	}
+	if get(t, props["seen"], "format") != "date-time" {
+		t.Errorf("seen = %v", props["seen"])
+	}
+	if got := get(t, n, "required"); !reflect.DeepEqual(got, []any{"name", "seen"}) {
+		t.Errorf("node required = %v", got)
+	}
+
+	r := get(t, schemas, "request")
+	props = get(t, r, "properties").(map[string]any)
+	if get(t, props["id"], "type") != "integer" {
+		t.Errorf("embedded field not flattened: %v", props)
+	}
+	if get(t, props["extra"], "type") != "object" {
+		t.Errorf("extra = %v", props["extra"])
+	}
+	// Pointers and omitempty fields are optional.
+	if got := get(t, r, "required"); !reflect.DeepEqual(got, []any{"id", "tags"}) {
+		t.Errorf("request required = %v", got)
+	}
+}
+
+func TestDocument_SessionCookieAddsSecurity(t *testing.T) {
+	doc := roundTrip(t, Document(Info{SessionCookie: "sess"}, nil))
+	if get(t, doc, "components", "securitySchemes", "cookieAuth", "name") != "sess" {
+		t.Error("cookie scheme missing")
+	}
+	if len(doc["security"].([]any)) != 2 {
+		t.Errorf("security = %v", doc["security"])
+	}
+}
@@ -0,0 +1,442 @@
+// Package reader extracts the main article from a web page, the way browser
+// reader modes do. It is a small take on the Arc90 readability heuristics:
+// paragraphs score their ancestors by how much prose they hold, boilerplate
+// containers (navigation, sidebars, comments) are dropped by class and id,
+// and the best-scoring container plus any sibling that scores nearly as
+// well is kept. It only depends on golang.org/x/net/html.
+package reader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrNoArticle is returned when a page has no block of prose to extract,
+// e.g. a login wall or a page built entirely by JavaScript.
+var ErrNoArticle = errors.New("reader: no article content found")
+
+// minArticleText is how much text, in bytes, the extracted article must
+// hold to count as one.
+const minArticleText = 250
+
+var (
+	unlikelyRE = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|extra|footer|header|menu|modal|nav|popup|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|promo|ad-break|agegate|pagination|pager`)
+	maybeRE    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveRE = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	negativeRE = regexp.MustCompile(`(?i)hidden|banner|combx|comment|contact|foot|footer|footnote|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// dropTags never hold article text.
+var dropTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Form: true, atom.Button: true, atom.Input: true, atom.Select: true,
+	atom.Textarea: true, atom.Nav: true, atom.Aside: true, atom.Footer: true,
+	atom.Svg: true, atom.Canvas: true, atom.Object: true, atom.Embed: true,
+	atom.Link: true, atom.Meta: true,
+}
+
+// keepAttrs are the attributes that survive in the extracted HTML.
+var keepAttrs = map[string]bool{
+	"href": true, "src": true, "alt": true, "title": true,
+	"colspan": true, "rowspan": true, "datetime": true,
+}
+
+// Article is the extracted main content of a page.
+type Article struct {
+	Title string
+	// Content is the article as sanitized HTML: no scripts, styles, forms
+	// or presentational attributes.
+	Content string
+	// Text is the article's plain text.
+	Text string
+}
+
+// Extract finds the article in an HTML document.
+func Extract(document string) (*Article, error) {
+	doc, err := nethtml.Parse(strings.NewReader(document))
+	if err != nil {
+		return nil, fmt.Errorf("reader: parse: %w", err)
+	}
+	title := pageTitle(doc)
+	body := find(doc, atom.Body)
+	if body == nil {
+		body = doc
+	}
+	prune(body)
+
+	top, scores := bestCandidate(body)
+	if top == nil {
+		return nil, ErrNoArticle
+	}
+	content := &nethtml.Node{Type: nethtml.ElementNode, Data: "div", DataAtom: atom.Div}
+	for _, n := range withSiblings(top, scores) {
+		content.AppendChild(clone(n))
+	}
+	clean(content)
+	text := strings.Join(strings.Fields(textOf(content)), " ")
+	if len(text) < minArticleText {
+		return nil, ErrNoArticle
+	}
+
+	var buf bytes.Buffer
+	for c := content.FirstChild; c != nil; c = c.NextSibling {
+		if err := nethtml.Render(&buf, c); err != nil {
+			return nil, fmt.Errorf("reader: render: %w", err)
+		}
+	}
+	return &Article{Title: title, Content: buf.String(), Text: text}, nil
+}
+
+// Page wraps an article in a standalone, readable HTML page that credits
+// the original URL.
+func Page(a *Article, sourceURL string, archivedAt time.Time) string {
+	title := html.EscapeString(a.Title)
+	src := html.EscapeString(sourceURL)
+	return `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>` + title + `</title>
+<style>
+body { max-width: 42em; margin: 2em auto; padding: 0 1em; font: 18px/1.6 Georgia, serif; color: #222; background: #fdfdfb; }
+h1 { line-height: 1.2; }
+img, video { max-width: 100%; height: auto; }
+pre { overflow-x: auto; background: #f4f4f4; padding: 0.5em; font-size: 0.85em; }
+blockquote { border-left: 3px solid #ccc; margin-left: 0; padding-left: 1em; color: #555; }
+.reader-source { font: 13px/1.4 Arial, sans-serif; color: #777; border-bottom: 1px solid #ddd; padding-bottom: 0.5em; }
+</style>
+</head>
+<body>
+<!-- READER VIEW - Original URL: ` + src + ` - Archived: ` + archivedAt.Format("2006-01-02 15:04:05") + ` -->
+<p class="reader-source">Reader view of <a href="` + src + `">` + src + `</a>, archived ` + archivedAt.Format("2006-01-02 15:04") + `</p>
+<h1>` + title + `</h1>
+` + a.Content + `
+</body>
+</html>
+`
+}
+
+// pageTitle prefers og:title, which sites keep free of the " | Site name"
+// suffix they put in <title>.
+func pageTitle(doc *nethtml.Node) string {
+	var title, og string
+	walk(doc, func(n *nethtml.Node) bool {
+		switch n.DataAtom {
+		case atom.Title:
+			if title == "" {
+				title = strings.TrimSpace(textOf(n))
+			}
+		case atom.Meta:
+			if attr(n, "property") == "og:title" && og == "" {
+				og = strings.TrimSpace(attr(n, "content"))
+			}
+		}
+		return true
+	})
+	if og != "" {
+		return og
+	}
+	return title
+}
+
+// prune removes nodes that never hold the article: scripts, navigation and
+// containers whose class or id says they are boilerplate.
+func prune(root *nethtml.Node) {
+	var remove []*nethtml.Node
+	walk(root, func(n *nethtml.Node) bool {
+		switch {
+		case n.Type == nethtml.CommentNode:
+			remove = append(remove, n)
+			return false
+		case n.Type != nethtml.ElementNode:
+			return true
+		case dropTags[n.DataAtom], hidden(n):
+			remove = append(remove, n)
+			return false
+		case n.DataAtom != atom.Body && n.DataAtom != atom.Article && n.DataAtom != atom.A:
+			id := attr(n, "class") + " " + attr(n, "id")
+			if unlikelyRE.MatchString(id) && !maybeRE.MatchString(id) {
+				remove = append(remove, n)
+				return false
+			}
+		}
+		return true
+	})
+	for _, n := range remove {
+		n.Parent.RemoveChild(n)
+	}
+}
+
+func hidden(n *nethtml.Node) bool {
+	if _, ok := attrOK(n, "hidden"); ok {
+		return true
+	}
+	style := strings.ReplaceAll(strings.ToLower(attr(n, "style")), " ", "")
+	return strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") ||
+		attr(n, "aria-hidden") == "true"
+}
+
+// bestCandidate scores the ancestors of every paragraph and returns the
+// highest-scoring one, adjusted for link density, along with all scores.
+func bestCandidate(root *nethtml.Node) (*nethtml.Node, map[*nethtml.Node]float64) {
+	scores := make(map[*nethtml.Node]float64)
+	var order []*nethtml.Node
+	track := func(n *nethtml.Node) {
+		if _, ok := scores[n]; ok {
+			return
+		}
+		scores[n] = baseScore(n)
+		order = append(order, n)
+	}
+	walk(root, func(n *nethtml.Node) bool {
+		switch n.DataAtom {
+		case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		default:
+			return true
+		}
+		text := strings.TrimSpace(textOf(n))
+		if len(text) < 25 || n.Parent == nil {
+			return false
+		}
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+		for level, anc := 0, n.Parent; level < 3 && anc != nil && anc.Type == nethtml.ElementNode; level, anc = level+1, anc.Parent {
+			track(anc)
+			switch level {
+			case 0:
+				scores[anc] += score
+			case 1:
+				scores[anc] += score / 2
+			default:
+				scores[anc] += score / 6
+			}
+		}
+		return false
+	})
+
+	var top *nethtml.Node
+	best := 0.0
+	for _, n := range order {
+		s := scores[n] * (1 - linkDensity(n))
+		scores[n] = s
+		if s > best {
+			top, best = n, s
+		}
+	}
+	if top == nil {
+		return nil, nil
+	}
+	// A lone wrapper div holding the real article: climb while the parent
+	// adds nothing but the candidate.
+	for top.Parent != nil && top.Parent.DataAtom != atom.Body && top.Parent.Type == nethtml.ElementNode && onlyChild(top) {
+		top = top.Parent
+	}
+	scores[top] = best
+	return top, scores
+}
+
+// withSiblings returns top plus the siblings that look like part of the
+// same article: scored containers close to top's score and paragraphs of
+// plain prose.
+func withSiblings(top *nethtml.Node, scores map[*nethtml.Node]float64) []*nethtml.Node {
+	if top.Parent == nil {
+		return []*nethtml.Node{top}
+	}
+	threshold := math.Max(10, scores[top]*0.2)
+	var out []*nethtml.Node
+	for s := top.Parent.FirstChild; s != nil; s = s.NextSibling {
+		if s == top {
+			out = append(out, s)
+			continue
+		}
+		if s.Type != nethtml.ElementNode {
+			continue
+		}
+		if score, ok := scores[s]; ok && score >= threshold {
+			out = append(out, s)
+			continue
+		}
+		if s.DataAtom == atom.P {
+			text := strings.TrimSpace(textOf(s))
+			if (len(text) > 80 && linkDensity(s) < 0.25) || (len(text) > 0 && linkDensity(s) == 0 && strings.HasSuffix(text, ".")) {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func baseScore(n *nethtml.Node) float64 {
+	var s float64
+	switch n.DataAtom {
+	case atom.Article:
+		s = 10
+	case atom.Div, atom.Main, atom.Section:
+		s = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		s = 3
+	case atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		s = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		s = -5
+	}
+	for _, v := range []string{attr(n, "class"), attr(n, "id")} {
+		if v == "" {
+			continue
+		}
+		if negativeRE.MatchString(v) {
+			s -= 25
+		}
+		if positiveRE.MatchString(v) {
+			s += 25
+		}
+	}
+	return s
+}
+
+// linkDensity is the share of n's text that sits inside links.
+func linkDensity(n *nethtml.Node) float64 {
+	total := len(strings.TrimSpace(textOf(n)))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	walk(n, func(c *nethtml.Node) bool {
+		if c.DataAtom == atom.A {
+			links += len(strings.TrimSpace(textOf(c)))
+			return false
+		}
+		return true
+	})
+	return float64(links) / float64(total)
+}
+
+// clean strips presentational attributes, empty containers and link-heavy
+// lists (leftover navigation) from the extracted copy.
+func clean(root *nethtml.Node) {
+	var remove []*nethtml.Node
+	walk(root, func(n *nethtml.Node) bool {
+		if n.Type != nethtml.ElementNode || n == root {
+			return true
+		}
+		attrs := n.Attr[:0]
+		for _, a := range n.Attr {
+			if keepAttrs[a.Key] && !strings.HasPrefix(strings.ToLower(strings.TrimSpace(a.Val)), "javascript:") {
+				attrs = append(attrs, a)
+			}
+		}
+		n.Attr = attrs
+		switch n.DataAtom {
+		case atom.Ul, atom.Ol, atom.Table, atom.Div, atom.Section:
+			if linkDensity(n) > 0.5 && len(textOf(n)) < 1000 {
+				remove = append(remove, n)
+				return false
+			}
+		}
+		return true
+	})
+	for _, n := range remove {
+		n.Parent.RemoveChild(n)
+	}
+	removeEmpty(root)
+}
+
+// removeEmpty drops containers left with neither text nor media.
+func removeEmpty(n *nethtml.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == nethtml.ElementNode {
+			removeEmpty(c)
+			switch c.DataAtom {
+			case atom.Div, atom.Span, atom.Section, atom.P, atom.Li, atom.Ul, atom.Ol:
+				if strings.TrimSpace(textOf(c)) == "" && find(c, atom.Img) == nil && find(c, atom.Picture) == nil && find(c, atom.Video) == nil {
+					n.RemoveChild(c)
+				}
+			}
+		}
+		c = next
+	}
+}
+
+func onlyChild(n *nethtml.Node) bool {
+	for s := n.Parent.FirstChild; s != nil; s = s.NextSibling {
+		if s != n && (s.Type == nethtml.ElementNode || strings.TrimSpace(s.Data) != "" && s.Type == nethtml.TextNode) {
+			return false
+		}
+	}
+	return true
+}
+
+// walk visits n and its descendants depth-first; returning false from fn
+// skips a node's children.
+func walk(n *nethtml.Node, fn func(*nethtml.Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling // fn may detach c's children, never c itself
+		walk(c, fn)
+		c = next
+	}
+}
+
+func find(n *nethtml.Node, a atom.Atom) *nethtml.Node {
+	var found *nethtml.Node
+	walk(n, func(c *nethtml.Node) bool {
+		if found != nil {
+			return false
+		}
+		if c.Type == nethtml.ElementNode && c.DataAtom == a {
+			found = c
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+func textOf(n *nethtml.Node) string {
+	var b strings.Builder
+	walk(n, func(c *nethtml.Node) bool {
+		if c.Type == nethtml.TextNode {
+			b.WriteString(c.Data)
+			b.WriteByte(' ')
+		}
+		return true
+	})
+	return b.String()
+}
+
+func attr(n *nethtml.Node, key string) string {
+	v, _ := attrOK(n, key)
+	return v
+}
+
+func attrOK(n *nethtml.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// clone deep-copies n so the extracted article doesn't share nodes with
+// the parsed page.
+func clone(n *nethtml.Node) *nethtml.Node {
+	c := &nethtml.Node{Type: n.Type, DataAtom: n.DataAtom, Data: n.Data, Namespace: n.Namespace}
+	c.Attr = append([]nethtml.Attribute(nil), n.Attr...)
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.AppendChild(clone(child))
+	}
+	return c
+}
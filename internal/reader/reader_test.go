@@ -0,0 +1,69 @@
+package reader
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html><head>
+<title>Why tabs win | The Indentation Blog</title>
+<meta property="og:title" content="Why tabs win">
+<script>track()</script>
+</head><body>
+<nav><a href="/">Home</a> <a href="/about">About</a></nav>
+<div class="sidebar"><p>Subscribe to our newsletter, it is really very good, honestly.</p></div>
+<div id="main" class="content">
+  <article class="post">
+    <h2>Part one</h2>
+    <p style="color:red" onclick="x()">Tabs let every reader pick an indentation width, which is kind to people with low vision, and they keep files small.</p>
+    <p>Spaces, on the other hand, look identical everywhere, so alignment survives copy and paste, code review tools and terminals.</p>
+    <p>In the end, consistency within a project matters far more than the choice itself, as any long-lived codebase will show.</p>
+    <img src="data:image/png;base64,AAAA" alt="chart">
+    <div class="share"><a href="https://twitter.com">Share</a></div>
+  </article>
+</div>
+<div class="comments"><p>First! This comment is long enough to count as a paragraph, surely.</p></div>
+<footer><p>Copyright 2026, all rights reserved, do not copy this page anywhere.</p></footer>
+</body></html>`
+
+func TestExtract(t *testing.T) {
+	a, err := Extract(articlePage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Title != "Why tabs win" {
+		t.Errorf("Title = %q", a.Title)
+	}
+	for _, want := range []string{"Tabs let every reader", "alignment survives", "consistency within a project", `alt="chart"`, "<h2>Part one</h2>"} {
+		if !strings.Contains(a.Content, want) {
+			t.Errorf("Content lacks %q:\n%s", want, a.Content)
+		}
+	}
+	for _, unwanted := range []string{"newsletter", "First!", "Copyright", "Home", "Share", "track()", "onclick", "style="} {
+		if strings.Contains(a.Content, unwanted) {
+			t.Errorf("Content has %q:\n%s", unwanted, a.Content)
+		}
+	}
+	if strings.Contains(a.Text, "<") {
+		t.Errorf("Text has markup: %q", a.Text)
+	}
+}
+
+func TestExtractNoArticle(t *testing.T) {
+	_, err := Extract(`<html><body><nav><a href="/">Home</a></nav><p>Log in to continue.</p></body></html>`)
+	if !errors.Is(err, ErrNoArticle) {
+		t.Errorf("err = %v, want ErrNoArticle", err)
+	}
+}
+
+func TestPage(t *testing.T) {
+	page := Page(&Article{Title: "Tabs & spaces", Content: "<p>Body</p>"}, "https://example.com/a?b=1&c=2", time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC))
+	for _, want := range []string{"<title>Tabs &amp; spaces</title>", `href="https://example.com/a?b=1&amp;c=2"`, "archived 2026-10-16 09:30", "<p>Body</p>"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page lacks %q", want)
+		}
+	}
+}
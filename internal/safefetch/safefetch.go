@@ -0,0 +1,291 @@
+// Package safefetch makes outbound HTTP requests on behalf of note content
+// safe to run unattended: every request is bounded in time and size,
+// redirects are capped, and connections to loopback, private-network and
+// link-local addresses are refused unless allowed, so a link in a note
+// can't be used to probe the machine's own network.
+package safefetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ErrBlocked is returned for a request to an address on a private or
+// local network.
+var ErrBlocked = errors.New("safefetch: address is on a private or local network")
+
+// ErrTooLarge is returned by a response body read past Options.MaxBytes.
+var ErrTooLarge = errors.New("safefetch: response too large")
+
+// ErrTooManyRedirects is returned when a request is redirected more than
+// Options.MaxRedirects times.
+var ErrTooManyRedirects = errors.New("safefetch: too many redirects")
+
+// Options are the limits a Transport enforces.
+type Options struct {
+	// UserAgent replaces the User-Agent of every request when set.
+	UserAgent string
+	// Timeout bounds each request from dial to the end of the response
+	// headers; zero means no limit.
+	Timeout time.Duration
+	// MaxBytes caps each response body; zero means no limit.
+	MaxBytes int64
+	// MaxRedirects is how many redirects a GET or HEAD may follow; zero
+	// means none.
+	MaxRedirects int
+	// AllowPrivate permits loopback, private-network and link-local
+	// addresses.
+	AllowPrivate bool
+}
+
+// cgnat is the carrier-grade NAT range, private in practice though
+// net.IP.IsPrivate doesn't count it.
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Blocked reports whether ip is one Transport refuses to connect to
+// unless Options.AllowPrivate is set.
+func Blocked(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || cgnat.Contains(ip)
+}
+
+// transport enforces Options on top of an http.Transport.
+type transport struct {
+	base *http.Transport
+	opts Options
+}
+
+// NewTransport returns an http.RoundTripper that enforces opts. Redirects
+// of GET and HEAD requests are followed inside the transport, so the
+// limit holds even for clients that follow redirects on their own; the
+// caller sees the final response, whose Request carries the final URL.
+//
+// Addresses are checked when connecting, after DNS resolution, so a host
+// name can't be pointed at a private address between check and use. A
+// proxy from $HTTP_PROXY / $HTTPS_PROXY is trusted and used as usual; the
+// target host is then checked by resolving it before the request.
+func NewTransport(opts Options) http.RoundTripper {
+	proxies := proxyAddrs()
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	checked := &net.Dialer{
+		Timeout:   dialer.Timeout,
+		KeepAlive: dialer.KeepAlive,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || Blocked(ip) {
+				return fmt.Errorf("%w: %s", ErrBlocked, host)
+			}
+			return nil
+		},
+	}
+	base := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if opts.AllowPrivate || proxies[addr] {
+				return dialer.DialContext(ctx, network, addr)
+			}
+			return checked.DialContext(ctx, network, addr)
+		},
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: opts.Timeout,
+		ExpectContinueTimeout: time.Second,
+	}
+	return &transport{base: base, opts: opts}
+}
+
+// NewClient returns a client using NewTransport(opts) and opts.Timeout as
+// its overall deadline.
+func NewClient(opts Options) *http.Client {
+	return &http.Client{Transport: NewTransport(opts), Timeout: opts.Timeout}
+}
+
+// proxyAddrs is the host:port of each proxy configured in the
+// environment.
+func proxyAddrs() map[string]bool {
+	addrs := make(map[string]bool)
+	cfg := httpproxy.FromEnvironment()
+	for _, p := range []string{cfg.HTTPProxy, cfg.HTTPSProxy} {
+		if p == "" {
+			continue
+		}
+		u, err := url.Parse(p)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		port := u.Port()
+		if port == "" {
+			port = "80"
+			if u.Scheme == "https" {
+				port = "443"
+			}
+		}
+		addrs[net.JoinHostPort(u.Hostname(), port)] = true
+	}
+	return addrs
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for redirects := 0; ; redirects++ {
+		if err := t.check(req); err != nil {
+			return nil, err
+		}
+		if t.opts.UserAgent != "" {
+			req = req.Clone(req.Context())
+			req.Header.Set("User-Agent", t.opts.UserAgent)
+		}
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		next := redirectTarget(req, resp)
+		if next == nil {
+			if t.opts.MaxBytes > 0 {
+				resp.Body = &limitedBody{ReadCloser: resp.Body, left: t.opts.MaxBytes}
+				if resp.ContentLength > t.opts.MaxBytes {
+					resp.Body.Close()
+					return nil, fmt.Errorf("%w: %s is %d bytes", ErrTooLarge, req.URL, resp.ContentLength)
+				}
+			}
+			return resp, nil
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+		if redirects >= t.opts.MaxRedirects {
+			return nil, fmt.Errorf("%w: %s", ErrTooManyRedirects, req.URL)
+		}
+		req = next
+	}
+}
+
+// check refuses non-HTTP schemes and, for requests through a proxy, a
+// target that resolves to a blocked address; direct connections are
+// checked when dialing.
+func (t *transport) check(req *http.Request) error {
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("safefetch: unsupported scheme %q", req.URL.Scheme)
+	}
+	if t.opts.AllowPrivate {
+		return nil
+	}
+	if proxy, err := t.base.Proxy(req); err != nil || proxy == nil {
+		return err
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(req.Context(), req.URL.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		if Blocked(ip.IP) {
+			return fmt.Errorf("%w: %s", ErrBlocked, req.URL.Hostname())
+		}
+	}
+	return nil
+}
+
+// redirectTarget is the request a redirect response asks for, or nil when
+// resp is not a redirect to follow.
+func redirectTarget(req *http.Request, resp *http.Response) *http.Request {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return nil
+	}
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return nil
+	}
+	loc, err := req.URL.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.Header.Get("Location") == "" {
+		return nil
+	}
+	next := req.Clone(req.Context())
+	next.URL = loc
+	next.Host = ""
+	// Credentials and cookies stay with the host they were meant for.
+	if loc.Host != req.URL.Host {
+		next.Header.Del("Authorization")
+		next.Header.Del("Cookie")
+	}
+	next.Header.Set("Referer", req.URL.String())
+	return next
+}
+
+// limitedBody fails reads past the size limit rather than truncating
+// silently, so a too-large page is an error, not a broken archive.
+type limitedBody struct {
+	io.ReadCloser
+	left int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.left <= 0 {
+		// Allow a clean EOF exactly at the limit.
+		var one [1]byte
+		if n, _ := b.ReadCloser.Read(one[:]); n == 0 {
+			return 0, io.EOF
+		}
+		return 0, ErrTooLarge
+	}
+	if int64(len(p)) > b.left {
+		p = p[:b.left]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.left -= int64(n)
+	return n, err
+}
+
+// HostLimiter spaces out requests to the same host.
+type HostLimiter struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// NewHostLimiter returns an empty limiter.
+func NewHostLimiter() *HostLimiter {
+	return &HostLimiter{next: make(map[string]time.Time)}
+}
+
+// Wait blocks until at least interval has passed since the previous
+// request to host was let through, or ctx is done. Waiting callers are let
+// through one interval apart.
+func (l *HostLimiter) Wait(ctx context.Context, host string, interval time.Duration) error {
+	if interval <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(interval)
+	l.mu.Unlock()
+
+	if d := time.Until(at); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package safefetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBlocked(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1":       true,
+		"10.1.2.3":        true,
+		"192.168.0.10":    true,
+		"169.254.169.254": true, // cloud metadata endpoint
+		"100.64.0.1":      true,
+		"0.0.0.0":         true,
+		"::1":             true,
+		"fd00::1":         true,
+		"::ffff:10.0.0.1": true,
+		"93.184.216.34":   false,
+		"2606:4700::1111": false,
+	} {
+		if got := Blocked(net.ParseIP(addr)); got != want {
+			t.Errorf("Blocked(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestTransportBlocksPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "internal")
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(Options{Timeout: 5 * time.Second}).Get(srv.URL)
+	if !errors.Is(err, ErrBlocked) {
+		t.Errorf("err = %v, want ErrBlocked", err)
+	}
+	resp, err := NewClient(Options{Timeout: 5 * time.Second, AllowPrivate: true}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("AllowPrivate: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestTransportLimits(t *testing.T) {
+	var gotUA string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hop/", func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/hop/"), "%d", &n)
+		if n == 0 {
+			gotUA = r.UserAgent()
+			io.WriteString(w, "landed")
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/hop/%d", n-1), http.StatusFound)
+	})
+	mux.HandleFunc("/big", func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush() // no Content-Length: the limit trips mid-read
+		io.WriteString(w, strings.Repeat("x", 2000))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := NewClient(Options{UserAgent: "polite-bot", MaxRedirects: 2, MaxBytes: 1000, AllowPrivate: true, Timeout: 5 * time.Second})
+
+	resp, err := client.Get(srv.URL + "/hop/2")
+	if err != nil {
+		t.Fatalf("two redirects: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "landed" || gotUA != "polite-bot" || !strings.HasSuffix(resp.Request.URL.Path, "/hop/0") {
+		t.Errorf("body %q, UA %q, final URL %s", body, gotUA, resp.Request.URL)
+	}
+
+	if _, err := client.Get(srv.URL + "/hop/3"); !errors.Is(err, ErrTooManyRedirects) {
+		t.Errorf("three redirects: err = %v", err)
+	}
+
+	resp, err = client.Get(srv.URL + "/big")
+	if err != nil {
+		t.Fatalf("big: %v", err)
+	}
+	_, err = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("big body: err = %v, want ErrTooLarge", err)
+	}
+
+	if _, err := client.Get("file:///etc/passwd"); err == nil {
+		t.Error("file:// URL was fetched")
+	}
+}
+
+func TestHostLimiter(t *testing.T) {
+	l := NewHostLimiter()
+	start := time.Now()
+	for range 3 {
+		if err := l.Wait(context.Background(), "example.com", 20*time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Wait(context.Background(), "other.example", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Errorf("three requests to one host took %v, want about 40ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l.Wait(ctx, "slow.example", time.Hour)
+	if err := l.Wait(ctx, "slow.example", time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled wait: err = %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+// Package selfsigned creates the certificate NoteFlow serves HTTPS with
+// when no certificate is supplied: a self-signed ECDSA certificate for
+// localhost, the machine's hostname and its network addresses, so the
+// server can be opened over HTTPS from other devices on a LAN once the
+// browser has been told to trust it.
+package selfsigned
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Validity is how long a generated certificate lasts. A certificate is
+// replaced once less than RenewBefore of it remains.
+const (
+	Validity    = 365 * 24 * time.Hour
+	RenewBefore = 30 * 24 * time.Hour
+)
+
+// File names inside the directory passed to Ensure.
+const (
+	CertFile = "cert.pem"
+	KeyFile  = "key.pem"
+)
+
+// Ensure returns the paths of a self-signed certificate and key in dir,
+// generating them when they are missing, expiring within RenewBefore, or
+// don't cover every name in hosts. hosts are DNS names or IP addresses;
+// see Hosts for the usual set.
+func Ensure(dir string, hosts []string) (certPath, keyPath string, err error) {
+	certPath = filepath.Join(dir, CertFile)
+	keyPath = filepath.Join(dir, KeyFile)
+	if usable(certPath, keyPath, hosts, time.Now()) {
+		return certPath, keyPath, nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", "", err
+	}
+	certPEM, keyPEM, err := Generate(hosts, time.Now())
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+		return "", "", err
+	}
+	return certPath, keyPath, nil
+}
+
+// usable reports whether the pair on disk loads, is valid for long enough
+// at now, and names every host.
+func usable(certPath, keyPath string, hosts []string, now time.Time) bool {
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil || now.Add(RenewBefore).After(cert.NotAfter) {
+		return false
+	}
+	for _, h := range hosts {
+		if cert.VerifyHostname(h) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Generate creates a PEM-encoded certificate valid from now for Validity,
+// and its private key, naming hosts.
+func Generate(hosts []string, now time.Time) (certPEM, keyPEM []byte, err error) {
+	if len(hosts) == 0 {
+		return nil, nil, errors.New("selfsigned: no host names")
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"NoteFlow"}, CommonName: hosts[0]},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(Validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("selfsigned: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// Hosts lists the names a LAN server is reached by: localhost, the
+// loopback addresses, the machine's hostname, every address of its
+// network interfaces, and extra (such as a configured bind address).
+// Unspecified addresses and duplicates are left out.
+func Hosts(extra ...string) []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	if name, err := os.Hostname(); err == nil && name != "" {
+		hosts = append(hosts, name)
+	}
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, a := range addrs {
+			if ipNet, ok := a.(*net.IPNet); ok && !ipNet.IP.IsLinkLocalUnicast() {
+				hosts = append(hosts, ipNet.IP.String())
+			}
+		}
+	}
+	for _, h := range extra {
+		if ip := net.ParseIP(h); h != "" && (ip == nil || !ip.IsUnspecified()) {
+			hosts = append(hosts, h)
+		}
+	}
+	var unique []string
+	for _, h := range hosts {
+		if !slices.Contains(unique, h) {
+			unique = append(unique, h)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,93 @@
+package selfsigned
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGenerate_NamesHosts(t *testing.T) {
+	certPEM, keyPEM, err := Generate([]string{"localhost", "192.168.1.20", "notes.lan"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []string{"localhost", "192.168.1.20", "notes.lan"} {
+		if err := cert.VerifyHostname(h); err != nil {
+			t.Errorf("VerifyHostname(%q): %v", h, err)
+		}
+	}
+	if cert.VerifyHostname("example.com") == nil {
+		t.Error("certificate valid for a host it wasn't made for")
+	}
+}
+
+func TestEnsure_ReusesUntilHostsChange(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tls")
+	certPath, keyPath, err := Ensure(dir, []string{"localhost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(keyPath); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("key file: %v, %v", info, err)
+	}
+	first, _ := os.ReadFile(certPath)
+
+	if _, _, err := Ensure(dir, []string{"localhost"}); err != nil {
+		t.Fatal(err)
+	}
+	again, _ := os.ReadFile(certPath)
+	if !slices.Equal(first, again) {
+		t.Error("certificate regenerated although still usable")
+	}
+
+	if _, _, err := Ensure(dir, []string{"localhost", "10.0.0.5"}); err != nil {
+		t.Fatal(err)
+	}
+	widened, _ := os.ReadFile(certPath)
+	if slices.Equal(first, widened) {
+		t.Error("certificate not regenerated for a new host")
+	}
+}
+
+func TestUsable_RenewsBeforeExpiry(t *testing.T) {
+	dir := t.TempDir()
+	certPEM, keyPEM, err := Generate([]string{"localhost"}, time.Now().Add(-Validity+RenewBefore/2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath, keyPath := filepath.Join(dir, CertFile), filepath.Join(dir, KeyFile)
+	os.WriteFile(certPath, certPEM, 0644)
+	os.WriteFile(keyPath, keyPEM, 0600)
+	if usable(certPath, keyPath, []string{"localhost"}, time.Now()) {
+		t.Error("certificate close to expiry reported usable")
+	}
+}
+
+func TestHosts_SkipsUnspecified(t *testing.T) {
+	hosts := Hosts("0.0.0.0", "notes.lan", "localhost")
+	if slices.Contains(hosts, "0.0.0.0") {
+		t.Errorf("Hosts includes the unspecified address: %v", hosts)
+	}
+	if !slices.Contains(hosts, "notes.lan") {
+		t.Errorf("Hosts drops an extra name: %v", hosts)
+	}
+	seen := make(map[string]bool)
+	for _, h := range hosts {
+		if seen[h] {
+			t.Errorf("Hosts lists %q twice: %v", h, hosts)
+		}
+		seen[h] = true
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// AgendaItem is one open, dated task on the agenda. Local tasks carry
+// their note title and task index (for toggling via /api/tasks/:index);
+// tasks from other folders carry the folder and their global task ID.
+type AgendaItem struct {
+	Text     string    `json:"text"`    // task text without checkbox or tokens
+	Content  string    `json:"content"` // the task line as written
+	Due      time.Time `json:"due"`
+	Priority int       `json:"priority,omitempty"`
+	Tags     []string  `json:"tags,omitempty"`
+	Note     string    `json:"note,omitempty"`
+	Index    *int      `json:"index,omitempty"`
+	Folder   string    `json:"folder,omitempty"`
+	TaskID   int       `json:"task_id,omitempty"`
+}
+
+// Agenda buckets dated open tasks by when they are due. "This week" runs
+// from the day after tomorrow through Sunday. Tasks without a due date,
+// or due after this week, are not on the agenda.
+type Agenda struct {
+	Overdue  []AgendaItem `json:"overdue"`
+	Today    []AgendaItem `json:"today"`
+	Tomorrow []AgendaItem `json:"tomorrow"`
+	ThisWeek []AgendaItem `json:"this_week"`
+}
+
+// BuildAgenda sorts items into buckets relative to now. Within a bucket,
+// items are ordered by due time, then priority.
+func BuildAgenda(items []AgendaItem, now time.Time) *Agenda {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	tomorrow := today.AddDate(0, 0, 1)
+	// Days until the end of Sunday; Go weeks start on Sunday, ISO weeks on
+	// Monday, and the agenda follows ISO.
+	endOfWeek := today.AddDate(0, 0, (7-int(today.Weekday()))%7+1)
+
+	a := &Agenda{Overdue: []AgendaItem{}, Today: []AgendaItem{}, Tomorrow: []AgendaItem{}, ThisWeek: []AgendaItem{}}
+	for _, it := range items {
+		day := time.Date(it.Due.Year(), it.Due.Month(), it.Due.Day(), 0, 0, 0, 0, now.Location())
+		switch {
+		case it.Due.IsZero():
+		case day.Before(today):
+			a.Overdue = append(a.Overdue, it)
+		case day.Equal(today):
+			a.Today = append(a.Today, it)
+		case day.Equal(tomorrow):
+			a.Tomorrow = append(a.Tomorrow, it)
+		case day.Before(endOfWeek):
+			a.ThisWeek = append(a.ThisWeek, it)
+		}
+	}
+	for _, bucket := range [][]AgendaItem{a.Overdue, a.Today, a.Tomorrow, a.ThisWeek} {
+		sort.SliceStable(bucket, func(i, j int) bool {
+			if !bucket[i].Due.Equal(bucket[j].Due) {
+				return bucket[i].Due.Before(bucket[j].Due)
+			}
+			return agendaPriority(bucket[i]) < agendaPriority(bucket[j])
+		})
+	}
+	return a
+}
+
+// agendaPriority orders !p1 first and unprioritized tasks last.
+func agendaPriority(it AgendaItem) int {
+	if it.Priority == 0 {
+		return 4
+	}
+	return it.Priority
+}
+
+// AgendaItems returns this folder's open tasks that have a due date.
+func (nm *NoteManager) AgendaItems() []AgendaItem {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	var items []AgendaItem
+	for _, note := range nm.notes {
+		for _, task := range note.Tasks {
+			if task.Checked || task.DueDate.IsZero() {
+				continue
+			}
+			index := task.Index
+			items = append(items, AgendaItem{
+				Text:     stripTaskCheckbox(models.CleanTaskText(task.Text)),
+				Content:  task.Text,
+				Due:      task.DueDate,
+				Priority: task.Priority,
+				Tags:     task.Tags,
+				Note:     note.Title,
+				Index:    &index,
+			})
+		}
+	}
+	return items
+}
+
+// GlobalAgendaItems turns registry tasks from every folder into agenda
+// items, skipping completed and undated ones.
+func GlobalAgendaItems(tasks []models.GlobalTask) []AgendaItem {
+	var items []AgendaItem
+	for _, t := range tasks {
+		if t.Completed {
+			continue
+		}
+		priority, due, tags := models.ParseTaskMetadata(t.Content)
+		if due.IsZero() {
+			continue
+		}
+		items = append(items, AgendaItem{
+			Text:     stripTaskCheckbox(models.CleanTaskText(t.Content)),
+			Content:  t.Content,
+			Due:      due,
+			Priority: priority,
+			Tags:     tags,
+			Folder:   t.FolderPath,
+			TaskID:   t.ID,
+		})
+	}
+	return items
+}
+
+// Markdown renders the agenda as plain bullet lists under "###" headings,
+// for pasting into a daily note. Checkboxes are deliberately left out so
+// the copy doesn't become a second, independent set of tasks.
+func (a *Agenda) Markdown() string {
+	var b strings.Builder
+	for _, s := range []struct {
+		title string
+		items []AgendaItem
+	}{{"Overdue", a.Overdue}, {"Today", a.Today}, {"Tomorrow", a.Tomorrow}, {"This week", a.ThisWeek}} {
+		if len(s.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "### %s\n", s.title)
+		for _, it := range s.items {
+			b.WriteString("- " + it.Text)
+			if s.title != "Today" && s.title != "Tomorrow" {
+				b.WriteString(" — " + it.Due.Format("Mon Jan 2"))
+			}
+			if it.Due.Hour() != 0 || it.Due.Minute() != 0 {
+				b.WriteString(" " + it.Due.Format("15:04"))
+			}
+			if it.Folder != "" {
+				b.WriteString(" (" + filepath.Base(it.Folder) + ")")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
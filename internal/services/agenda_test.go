@@ -0,0 +1,69 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestBuildAgenda(t *testing.T) {
+	// Friday 16 Oct 2026: tomorrow is Saturday, so "this week" is just Sunday.
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+	items := []AgendaItem{
+		{Text: "late", Due: day(14)},
+		{Text: "today low", Due: day(16), Priority: 3},
+		{Text: "today urgent", Due: day(16), Priority: 1},
+		{Text: "tomorrow", Due: day(17)},
+		{Text: "sunday", Due: day(18)},
+		{Text: "next monday", Due: day(19)},
+		{Text: "someday"},
+	}
+	a := BuildAgenda(items, now)
+	texts := func(its []AgendaItem) string {
+		var out []string
+		for _, it := range its {
+			out = append(out, it.Text)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := texts(a.Overdue); got != "late" {
+		t.Errorf("overdue = %s", got)
+	}
+	if got := texts(a.Today); got != "today urgent,today low" {
+		t.Errorf("today = %s", got)
+	}
+	if got := texts(a.Tomorrow); got != "tomorrow" {
+		t.Errorf("tomorrow = %s", got)
+	}
+	if got := texts(a.ThisWeek); got != "sunday" {
+		t.Errorf("this week = %s", got)
+	}
+	if md := a.Markdown(); !strings.Contains(md, "### Today\n- today urgent\n") || !strings.Contains(md, "- late — Wed Oct 14") {
+		t.Errorf("markdown:\n%s", md)
+	}
+}
+
+func TestAgendaItems_LocalAndGlobal(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Errands", "- [ ] dentist @2026-10-16 !p1\n- [x] done @2026-10-16\n- [ ] undated"); err != nil {
+		t.Fatal(err)
+	}
+	items := mgr.AgendaItems()
+	if len(items) != 1 || items[0].Text != "dentist" || items[0].Note != "Errands" || items[0].Index == nil || items[0].Priority != 1 {
+		t.Errorf("local items = %+v", items)
+	}
+
+	global := GlobalAgendaItems([]models.GlobalTask{
+		{ID: 7, Content: "- [ ] ship @2026-10-17", FolderPath: "/w/app"},
+		{ID: 8, Content: "- [ ] no date", FolderPath: "/w/app"},
+	})
+	if len(global) != 1 || global[0].TaskID != 7 || global[0].Folder != "/w/app" {
+		t.Errorf("global items = %+v", global)
+	}
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// DefaultBulkArchiveConcurrency is how many pages BulkArchive fetches at
+// once unless told otherwise; MaxBulkArchiveConcurrency caps the option.
+const (
+	DefaultBulkArchiveConcurrency = 2
+	MaxBulkArchiveConcurrency     = 8
+)
+
+// ErrBulkArchiveRunning is returned by BulkArchive while another run is
+// in progress.
+var ErrBulkArchiveRunning = errors.New("a bulk archive is already running")
+
+// externalLinkRE matches the link forms a note can hold: a markdown link,
+// an autolink in angle brackets, or a bare URL.
+var externalLinkRE = regexp.MustCompile(`\[[^\]\n]*\]\((https?://[^)\s]+)\)|<(https?://[^>\s]+)>|(https?://[^\s<>()\[\]"'` + "`" + `]+)`)
+
+// archivedMarkers are what follows a link that already has an archive:
+// a bulk archive reference or a pending +URL placeholder.
+var archivedMarkers = []string{" ([archived ", " (archive pending"}
+
+// ExternalLink is an http(s) link in the notes without an archive
+// reference.
+type ExternalLink struct {
+	URL   string `json:"url"`
+	Notes []int  `json:"notes"` // indexes of the notes linking to it
+	// Snapshot is the newest existing archive of URL, which BulkArchive
+	// links rather than fetching the page again.
+	Snapshot string `json:"snapshot,omitempty"`
+}
+
+// BulkArchiveOptions tunes BulkArchive.
+type BulkArchiveOptions struct {
+	Concurrency int `json:"concurrency"` // pages fetched at once; 0 means the default
+	// Refetch archives pages again even when a snapshot of them exists.
+	Refetch bool `json:"refetch"`
+}
+
+// Bulk archive progress event types.
+const (
+	BulkArchiveStart  = "start"
+	BulkArchiveLink   = "link"
+	BulkArchiveFinish = "done"
+)
+
+// BulkArchiveEvent reports BulkArchive's progress: one start event with
+// the total, one link event per URL as it finishes, and a done event.
+type BulkArchiveEvent struct {
+	Type     string `json:"type"`
+	Total    int    `json:"total"`
+	Done     int    `json:"done"`
+	Failed   int    `json:"failed"`
+	URL      string `json:"url,omitempty"`
+	FilePath string `json:"file_path,omitempty"`
+	Reused   bool   `json:"reused,omitempty"` // an existing snapshot was linked
+	Error    string `json:"error,omitempty"`
+}
+
+// externalLinkMatch is one link found in a note body; end is where an
+// archive reference goes.
+type externalLinkMatch struct {
+	url string
+	end int
+}
+
+// findExternalLinks returns the links in content that have no archive
+// reference yet, skipping code, +URL sigils and links into assets.
+func findExternalLinks(content string) []externalLinkMatch {
+	code := models.CodeRanges(content)
+	var links []externalLinkMatch
+	for _, m := range externalLinkRE.FindAllStringSubmatchIndex(content, -1) {
+		start, end := m[0], m[1]
+		if inRanges(start, code) {
+			continue
+		}
+		var u string
+		switch {
+		case m[2] >= 0:
+			u = content[m[2]:m[3]]
+		case m[4] >= 0:
+			u = content[m[4]:m[5]]
+		default:
+			// A bare URL right after "+" or "+pdf:" is an archive sigil.
+			if start > 0 && strings.ContainsRune("+:", rune(content[start-1])) {
+				continue
+			}
+			u = strings.TrimRight(content[m[6]:m[7]], ".,;:!?")
+			end = m[6] + len(u)
+		}
+		if hasArchivedMarker(content[end:]) {
+			continue
+		}
+		links = append(links, externalLinkMatch{url: u, end: end})
+	}
+	return links
+}
+
+func hasArchivedMarker(rest string) bool {
+	for _, marker := range archivedMarkers {
+		if strings.HasPrefix(rest, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func inRanges(pos int, ranges [][2]int) bool {
+	for _, r := range ranges {
+		if pos >= r[0] && pos < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveReference is the text added after a link archived in bulk.
+func archiveReference(info *ArchiveInfo) string {
+	return " ([archived " + info.Timestamp.Format("2006-01-02 15:04") + "](" + info.FilePath + "))"
+}
+
+// ExternalLinks lists the distinct http(s) links in the notes that have
+// no archive reference, in order of first appearance (newest note first).
+func (nm *NoteManager) ExternalLinks() ([]ExternalLink, error) {
+	snapshots, err := nm.latestSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	var links []ExternalLink
+	seen := make(map[string]int)
+	for i, note := range nm.notes {
+		for _, m := range findExternalLinks(note.Content) {
+			j, ok := seen[m.url]
+			if !ok {
+				j = len(links)
+				seen[m.url] = j
+				links = append(links, ExternalLink{URL: m.url, Snapshot: snapshots[m.url]})
+			}
+			if n := links[j].Notes; len(n) == 0 || n[len(n)-1] != i {
+				links[j].Notes = append(n, i)
+			}
+		}
+	}
+	return links, nil
+}
+
+// latestSnapshots maps each archived URL to its newest snapshot's filename.
+func (nm *NoteManager) latestSnapshots() (map[string]string, error) {
+	groups, err := nm.storage.ListArchivedSites()
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]string)
+	for _, group := range groups {
+		for _, archive := range group.(map[string]interface{})["archives"].([]map[string]string) {
+			if u := archive["url"]; u != "" && archive["filename"] > latest[u] {
+				latest[u] = archive["filename"]
+			}
+		}
+	}
+	return latest, nil
+}
+
+// BulkArchive archives every link ExternalLinks finds, opts.Concurrency
+// pages at a time, and adds an archive reference after each occurrence:
+//
+//	https://example.com ([archived 2026-10-16 09:00](assets/sites/...))
+//
+// A URL with an existing snapshot is linked to it instead of being fetched
+// again, unless opts.Refetch. Each note is saved as soon as a link in it
+// is archived, with a history revision, so an interrupted run keeps what
+// it did. Canceling ctx stops it from starting further pages. progress,
+// if set, is called from one goroutine at a time. Only one run at a time
+// is allowed.
+func (nm *NoteManager) BulkArchive(ctx context.Context, opts BulkArchiveOptions, progress func(BulkArchiveEvent)) (BulkArchiveEvent, error) {
+	if !nm.bulkArchiving.TryLock() {
+		return BulkArchiveEvent{}, ErrBulkArchiveRunning
+	}
+	defer nm.bulkArchiving.Unlock()
+	if progress == nil {
+		progress = func(BulkArchiveEvent) {}
+	}
+	links, err := nm.ExternalLinks()
+	if err != nil {
+		return BulkArchiveEvent{}, err
+	}
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = DefaultBulkArchiveConcurrency
+	}
+	workers = min(workers, MaxBulkArchiveConcurrency)
+
+	var mu sync.Mutex
+	state := BulkArchiveEvent{Type: BulkArchiveStart, Total: len(links)}
+	progress(state)
+
+	jobs := make(chan ExternalLink)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range jobs {
+				event := nm.bulkArchiveLink(link, opts.Refetch)
+				mu.Lock()
+				state.Done++
+				if event.Error != "" {
+					state.Failed++
+				}
+				event.Total, event.Done, event.Failed = state.Total, state.Done, state.Failed
+				progress(event)
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for _, link := range links {
+		select {
+		case jobs <- link:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	state.Type = BulkArchiveFinish
+	progress(state)
+	return state, ctx.Err()
+}
+
+// bulkArchiveLink archives one link, or finds its existing snapshot, and
+// annotates the notes.
+func (nm *NoteManager) bulkArchiveLink(link ExternalLink, refetch bool) BulkArchiveEvent {
+	event := BulkArchiveEvent{Type: BulkArchiveLink, URL: link.URL}
+
+	var info *ArchiveInfo
+	if link.Snapshot != "" && !refetch {
+		info = snapshotInfo(link.Snapshot)
+		event.Reused = true
+	} else {
+		nm.mu.RLock()
+		spec := nm.resolveArchive(archiveSpec{URL: link.URL})
+		nm.mu.RUnlock()
+		var err error
+		if info, err = nm.archive(spec); err != nil {
+			log.Printf("Warning: failed to archive %s: %v", link.URL, err)
+			event.Error = err.Error()
+			return event
+		}
+	}
+	event.FilePath = info.FilePath
+
+	if err := nm.annotateArchivedLink(link.URL, info); err != nil {
+		log.Printf("Warning: failed to save archive link for %s: %v", link.URL, err)
+		event.Error = err.Error()
+	}
+	return event
+}
+
+// snapshotInfo describes an existing archive well enough to link it.
+func snapshotInfo(filename string) *ArchiveInfo {
+	info := &ArchiveInfo{FilePath: "assets/sites/" + filename}
+	if len(filename) >= len("2006_01_02_150405") {
+		info.Timestamp, _ = time.ParseInLocation("2006_01_02_150405", filename[:len("2006_01_02_150405")], time.Local)
+	}
+	return info
+}
+
+// annotateArchivedLink adds the archive reference after every unannotated
+// occurrence of pageURL in the notes.
+func (nm *NoteManager) annotateArchivedLink(pageURL string, info *ArchiveInfo) error {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	ref := archiveReference(info)
+	changed := false
+	for _, note := range nm.notes {
+		var ends []int
+		for _, m := range findExternalLinks(note.Content) {
+			if m.url == pageURL {
+				ends = append(ends, m.end)
+			}
+		}
+		if len(ends) == 0 {
+			continue
+		}
+		// Insert from the end so earlier offsets stay valid.
+		slices.Reverse(ends)
+		content := note.Content
+		for _, end := range ends {
+			content = content[:end] + ref + content[end:]
+		}
+		nm.saveRevision(note, note.Title, content)
+		note.Update(note.Title, content)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	nm.assignTaskIndices()
+	nm.needsSave = true
+	return nm.save()
+}
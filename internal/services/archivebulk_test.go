@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestFindExternalLinks(t *testing.T) {
+	content := "see https://a.example/x. and [B](https://b.example) or <https://c.example>\n" +
+		"+https://sigil.example +pdf:https://pdf.example\n" +
+		"`https://code.example` and [p](https://p.example) (archive pending)\n" +
+		"https://done.example ([archived 2026-10-16 09:00](assets/sites/x.html))\n"
+	var got []string
+	for _, m := range findExternalLinks(content) {
+		got = append(got, m.url+"@"+content[m.end-1:m.end])
+	}
+	want := []string{"https://a.example/x@x", "https://b.example@)", "https://c.example@>"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("findExternalLinks = %v, want %v", got, want)
+	}
+}
+
+func TestBulkArchive(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sites := filepath.Join(dir, "assets", "sites")
+	os.WriteFile(filepath.Join(sites, "2026_10_15_080000_Kept-kept.example.html"), []byte("<html></html>"), 0644)
+	mgr.storage.SaveArchiveMeta("2026_10_15_080000_Kept-kept.example.html", models.ArchiveMeta{URL: "https://kept.example/"})
+
+	mgr.AddNote("one", "read https://a.example/post and https://kept.example/")
+	mgr.AddNote("two", "again https://a.example/post, and https://broken.example")
+
+	links, err := mgr.ExternalLinks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 3 || links[0].URL != "https://a.example/post" || len(links[0].Notes) != 2 || links[2].Snapshot == "" {
+		t.Fatalf("ExternalLinks = %+v", links)
+	}
+
+	var mu sync.Mutex
+	var fetched []string
+	mgr.archive = func(spec archiveSpec) (*ArchiveInfo, error) {
+		mu.Lock()
+		fetched = append(fetched, spec.URL)
+		mu.Unlock()
+		if strings.Contains(spec.URL, "broken") {
+			return nil, errors.New("404")
+		}
+		return &ArchiveInfo{FilePath: "assets/sites/post.html", Timestamp: time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)}, nil
+	}
+	var events []BulkArchiveEvent
+	final, err := mgr.BulkArchive(context.Background(), BulkArchiveOptions{Concurrency: 3}, func(e BulkArchiveEvent) {
+		events = append(events, e)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if final.Done != 3 || final.Failed != 1 || len(events) != 5 || events[0].Type != BulkArchiveStart || events[4].Type != BulkArchiveFinish {
+		t.Errorf("final = %+v, events = %+v", final, events)
+	}
+	if len(fetched) != 2 {
+		t.Errorf("fetched %v; the kept snapshot should be reused", fetched)
+	}
+
+	notes := mgr.notes
+	if want := "again https://a.example/post ([archived 2026-10-16 09:00](assets/sites/post.html)), and https://broken.example"; notes[0].Content != want {
+		t.Errorf("note two = %q", notes[0].Content)
+	}
+	if !strings.Contains(notes[1].Content, "https://kept.example/ ([archived 2026-10-15 08:00](assets/sites/2026_10_15_080000_Kept-kept.example.html))") {
+		t.Errorf("note one = %q", notes[1].Content)
+	}
+
+	// Only the failed link is left for the next run.
+	if links, _ := mgr.ExternalLinks(); len(links) != 1 || links[0].URL != "https://broken.example" {
+		t.Errorf("after run: %+v", links)
+	}
+}
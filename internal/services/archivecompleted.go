@@ -0,0 +1,244 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// DefaultArchiveCompletedDays is the age below which completed tasks stay
+// where they are when no age is given.
+const DefaultArchiveCompletedDays = 30
+
+// CompletedSection is the heading archived tasks are moved under when they
+// stay in their note.
+const CompletedSection = "Completed"
+
+// Archive targets for ArchiveCompletedOptions.Target.
+const (
+	ArchiveToSection = "section"
+	ArchiveToFile    = "file"
+)
+
+// ErrInvalidArchiveTarget is returned for a target other than "section" or
+// "file".
+var ErrInvalidArchiveTarget = errors.New(`archive target must be "section" or "file"`)
+
+// completedHeadingRE matches the heading of the Completed section.
+var completedHeadingRE = regexp.MustCompile(`^ {0,3}#{1,6}[ \t]+` + CompletedSection + `[ \t#]*$`)
+
+// anyHeadingRE matches any ATX heading line.
+var anyHeadingRE = regexp.MustCompile(`^ {0,3}#{1,6}[ \t]`)
+
+// ArchiveCompletedOptions selects what ArchiveCompletedTasks moves and
+// where.
+type ArchiveCompletedOptions struct {
+	// Days: only tasks in notes created more than this many days ago are
+	// moved. Task completion isn't dated, so the note's age stands in for
+	// the task's.
+	Days int
+	// Target is ArchiveToSection (default) to move tasks under a
+	// "### Completed" heading at the end of their note, or ArchiveToFile to
+	// move them out to archive.md.
+	Target string
+	// DryRun reports what would move without changing anything.
+	DryRun bool
+}
+
+// ArchivedTask is one task moved (or, on a dry run, to be moved).
+type ArchivedTask struct {
+	NoteIndex int    `json:"note_index"`
+	NoteTitle string `json:"note_title"`
+	Text      string `json:"text"`
+}
+
+// ArchiveCompletedResult reports an ArchiveCompletedTasks run.
+type ArchiveCompletedResult struct {
+	Target   string         `json:"target"`
+	DryRun   bool           `json:"dry_run"`
+	Archived int            `json:"archived"`
+	Tasks    []ArchivedTask `json:"tasks"`
+}
+
+// ArchiveCompletedTasks moves checked top-level tasks out of the way. A
+// task moves together with the lines nested under it, so only tasks whose
+// subtasks are all checked are taken; checked subtasks of an open task
+// stay with their parent. Tasks already under the Completed heading are
+// left alone. Each changed note gets a history revision.
+func (nm *NoteManager) ArchiveCompletedTasks(opts ArchiveCompletedOptions, now time.Time) (*ArchiveCompletedResult, error) {
+	if opts.Target == "" {
+		opts.Target = ArchiveToSection
+	}
+	if opts.Target != ArchiveToSection && opts.Target != ArchiveToFile {
+		return nil, ErrInvalidArchiveTarget
+	}
+
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.refresh()
+
+	res := &ArchiveCompletedResult{Target: opts.Target, DryRun: opts.DryRun, Tasks: []ArchivedTask{}}
+	cutoff := now.AddDate(0, 0, -opts.Days)
+	type change struct {
+		note    *models.Note
+		content string
+		moved   []string
+	}
+	var changes []change
+	var archive strings.Builder
+	for i, note := range nm.notes {
+		if !note.Timestamp.Before(cutoff) {
+			continue
+		}
+		kept, moved, tasks := splitCompletedTasks(note)
+		if len(moved) == 0 {
+			continue
+		}
+		for _, task := range tasks {
+			res.Tasks = append(res.Tasks, ArchivedTask{
+				NoteIndex: i,
+				NoteTitle: note.Title,
+				Text:      stripTaskCheckbox(models.CleanTaskText(task.Text)),
+			})
+		}
+		content := strings.Join(kept, "\n")
+		if opts.Target == ArchiveToSection {
+			content = appendToCompletedSection(kept, moved)
+		} else {
+			fmt.Fprintf(&archive, "## %s - %s\n\n%s\n\n",
+				note.Timestamp.Format("2006-01-02 15:04:05"), note.Title, strings.Join(moved, "\n"))
+		}
+		changes = append(changes, change{note: note, content: content, moved: moved})
+	}
+	res.Archived = len(res.Tasks)
+	if opts.DryRun || len(changes) == 0 {
+		return res, nil
+	}
+
+	// Write archive.md first: if saving notes.md then fails the tasks are
+	// duplicated rather than lost.
+	if archive.Len() > 0 {
+		if err := nm.storage.AppendCompletedArchive(fmt.Sprintf("<!-- archived %s -->\n%s", now.Format(time.RFC3339), archive.String())); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range changes {
+		nm.saveRevision(c.note, c.note.Title, c.content)
+		c.note.Update(c.note.Title, c.content)
+	}
+	nm.assignTaskIndices()
+	nm.needsSave = true
+	if err := nm.save(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// splitCompletedTasks splits note's lines into those that stay and the
+// blocks of completed tasks to archive, returning the archived tasks too.
+func splitCompletedTasks(note *models.Note) (kept, moved []string, tasks []*models.Task) {
+	lines := strings.Split(note.Content, "\n")
+	positions := taskPositions(note)
+	skip := make(map[int]bool)
+	for i, task := range note.Tasks {
+		if !task.Checked || task.Depth > 0 || task.Section == CompletedSection || positions[i] < 0 {
+			continue
+		}
+		open := false
+		for _, sub := range note.Subtasks(task.Index) {
+			open = open || !sub.Checked
+		}
+		if open {
+			continue
+		}
+		start := strings.Count(note.Content[:positions[i]], "\n")
+		if skip[start] {
+			continue
+		}
+		// The block is the task's line plus the lines indented under it.
+		indent := leadingSpace(lines[start])
+		end := start + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != "" && leadingSpace(lines[end]) > indent {
+			end++
+		}
+		for l := start; l < end; l++ {
+			skip[l] = true
+			moved = append(moved, lines[l][min(indent, len(lines[l])):])
+		}
+		tasks = append(tasks, task)
+	}
+	for l, line := range lines {
+		if !skip[l] {
+			kept = append(kept, line)
+		}
+	}
+	return kept, moved, tasks
+}
+
+// appendToCompletedSection adds moved to the end of the note's Completed
+// section, starting one at the end of the note when there is none.
+func appendToCompletedSection(lines, moved []string) string {
+	at := -1
+	for i, line := range lines {
+		if completedHeadingRE.MatchString(line) {
+			at = i + 1
+			break
+		}
+	}
+	if at < 0 {
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		lines = append(lines, "", "### "+CompletedSection)
+		at = len(lines)
+	}
+	// Insert before the next heading, after the section's last non-blank line.
+	end := at
+	for end < len(lines) && !anyHeadingRE.MatchString(lines[end]) {
+		end++
+	}
+	for end > at && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	out := append(append(append([]string{}, lines[:end]...), moved...), lines[end:]...)
+	return strings.Join(out, "\n")
+}
+
+// leadingSpace counts a line's indentation, a tab as four columns.
+func leadingSpace(line string) int {
+	w := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			w++
+		case '\t':
+			w += 4
+		default:
+			return w
+		}
+	}
+	return w
+}
+
+// taskPositions returns the byte offset of each of note's tasks in its
+// content, or -1 when a task's text can't be found. Task text runs from
+// the checkbox to the end of its line, so each task is searched for after
+// the previous one.
+func taskPositions(note *models.Note) []int {
+	positions := make([]int, len(note.Tasks))
+	from := 0
+	for i, task := range note.Tasks {
+		at := strings.Index(note.Content[from:], task.Text)
+		if at < 0 {
+			positions[i] = -1
+			continue
+		}
+		positions[i] = from + at
+		from = positions[i] + len(task.Text)
+	}
+	return positions
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchiveCompletedTasks(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "- [x] Ship\n  details\n- [ ] Open\n- [x] Parent\n  - [ ] Child\n\n## Later\n- [ ] Next"
+	if err := mgr.AddNote("Old", content); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("New", "- [x] Fresh"); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	old := mgr.notes[len(mgr.notes)-1]
+	if old.Title != "Old" {
+		old = mgr.notes[0]
+	}
+	old.Timestamp = now.AddDate(0, 0, -40)
+
+	dry, err := mgr.ArchiveCompletedTasks(ArchiveCompletedOptions{Days: 30, DryRun: true}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dry.Archived != 1 || dry.Tasks[0].Text != "Ship" || dry.Tasks[0].NoteTitle != "Old" {
+		t.Fatalf("dry run = %+v", dry)
+	}
+	if old.Content != content {
+		t.Fatalf("dry run changed the note: %q", old.Content)
+	}
+
+	if _, err := mgr.ArchiveCompletedTasks(ArchiveCompletedOptions{Days: 30}, now); err != nil {
+		t.Fatal(err)
+	}
+	want := "- [ ] Open\n- [x] Parent\n  - [ ] Child\n\n## Later\n- [ ] Next\n\n### Completed\n- [x] Ship\n  details"
+	if old.Content != want {
+		t.Errorf("content = %q, want %q", old.Content, want)
+	}
+	// Already under Completed: a second run moves nothing.
+	again, err := mgr.ArchiveCompletedTasks(ArchiveCompletedOptions{Days: 30}, now)
+	if err != nil || again.Archived != 0 {
+		t.Errorf("second run = %+v, %v", again, err)
+	}
+}
+
+func TestArchiveCompletedTasksToFile(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Old", "- [x] Ship\n- [ ] Open"); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	mgr.notes[0].Timestamp = now.AddDate(0, 0, -2)
+
+	if _, err := mgr.ArchiveCompletedTasks(ArchiveCompletedOptions{Target: "elsewhere"}, now); err != ErrInvalidArchiveTarget {
+		t.Errorf("err = %v, want ErrInvalidArchiveTarget", err)
+	}
+	res, err := mgr.ArchiveCompletedTasks(ArchiveCompletedOptions{Days: 1, Target: ArchiveToFile}, now)
+	if err != nil || res.Archived != 1 {
+		t.Fatalf("archive = %+v, %v", res, err)
+	}
+	if got := mgr.notes[0].Content; got != "- [ ] Open" {
+		t.Errorf("content = %q", got)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "archive.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "- Old\n\n- [x] Ship\n") {
+		t.Errorf("archive.md = %q", data)
+	}
+	notes, _ := os.ReadFile(filepath.Join(dir, "notes.md"))
+	if strings.Contains(string(notes), "Ship") {
+		t.Errorf("notes.md still has the task: %q", notes)
+	}
+}
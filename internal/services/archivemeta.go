@@ -0,0 +1,54 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"sort"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// ArchiveMetaUpdate changes the user-editable metadata of an archive.
+// Nil fields are left as they are.
+type ArchiveMetaUpdate struct {
+	Title *string   `json:"title"`
+	Notes *string   `json:"notes"`
+	Tags  *[]string `json:"tags"` // bare names, already normalized
+}
+
+// ArchiveMeta returns the metadata of the archive filename.
+func (nm *NoteManager) ArchiveMeta(filename string) (*models.ArchiveMeta, error) {
+	meta, err := nm.storage.LoadArchiveMeta(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrArchiveNotFound
+	}
+	return meta, err
+}
+
+// UpdateArchiveMeta sets the title, notes or tags of the archive filename.
+// Tags are deduplicated and sorted; an empty list removes them all.
+func (nm *NoteManager) UpdateArchiveMeta(filename string, update ArchiveMetaUpdate) (*models.ArchiveMeta, error) {
+	meta, err := nm.storage.UpdateArchiveMeta(filename, func(meta *models.ArchiveMeta) {
+		if update.Title != nil {
+			meta.Title = *update.Title
+		}
+		if update.Notes != nil {
+			meta.Notes = *update.Notes
+		}
+		if update.Tags != nil {
+			seen := make(map[string]bool)
+			meta.Tags = nil
+			for _, tag := range *update.Tags {
+				if !seen[tag] {
+					seen[tag] = true
+					meta.Tags = append(meta.Tags, tag)
+				}
+			}
+			sort.Strings(meta.Tags)
+		}
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrArchiveNotFound
+	}
+	return meta, err
+}
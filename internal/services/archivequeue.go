@@ -0,0 +1,238 @@
+package services
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// archiveWorkers is how many pages are archived at once.
+const archiveWorkers = 2
+
+// archiveHistory is how many finished jobs the status report keeps.
+const archiveHistory = 50
+
+// Archive job states reported by ArchiveStatus.
+const (
+	ArchiveJobPending = "pending"
+	ArchiveJobRunning = "running"
+	ArchiveJobDone    = "done"
+	ArchiveJobFailed  = "failed"
+)
+
+// ArchiveJob is one queued +URL archive.
+type ArchiveJob struct {
+	ID       int        `json:"id"`
+	URL      string     `json:"url"`
+	Status   string     `json:"status"`
+	Title    string     `json:"title,omitempty"`
+	FilePath string     `json:"file_path,omitempty"`
+	Reader   string     `json:"reader_path,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	Queued   time.Time  `json:"queued"`
+	Finished *time.Time `json:"finished,omitempty"`
+
+	written archiveSpec // as written in the note; see archiveSpec.placeholder
+	spec    archiveSpec // with the folder's archive settings applied
+}
+
+// ArchiveStatus is the payload behind GET /api/archives/status: the jobs
+// still queued or running, then recently finished ones, oldest first.
+type ArchiveStatus struct {
+	Pending int          `json:"pending"`
+	Jobs    []ArchiveJob `json:"jobs"`
+}
+
+// archiveQueue archives +URL pages in the background. Jobs wait in a slice
+// rather than a channel so enqueueing never blocks: it happens under the
+// NoteManager lock, which the workers need to finish a job.
+type archiveQueue struct {
+	mu     sync.Mutex
+	jobs   []*ArchiveJob
+	nextID int
+	wake   chan struct{}
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// StartArchiveQueue moves +URL archiving off the save path. From now on
+// AddNote and UpdateNote replace each +URL with a pending placeholder and
+// return at once; the placeholder is swapped for the archive link when the
+// page has been fetched, or back to the +URL if that fails, so the next
+// save retries it as before. Placeholders left in notes.md by an earlier
+// run are queued again. Without a queue, archiving stays synchronous,
+// which is what one-shot callers like the CLI want.
+func (nm *NoteManager) StartArchiveQueue() {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if nm.archives != nil {
+		return
+	}
+	q := &archiveQueue{wake: make(chan struct{}, 1), stop: make(chan struct{})}
+	nm.archives = q
+	for _, note := range nm.notes {
+		for _, written := range pendingArchives(note.Content) {
+			q.enqueue(written, nm.resolveArchive(written))
+		}
+	}
+	for range archiveWorkers {
+		q.wg.Add(1)
+		go nm.archiveWorker(q)
+	}
+}
+
+// StopArchiveQueue stops the workers once their current jobs finish.
+// Queued jobs keep their placeholders and are picked up by the next
+// StartArchiveQueue.
+func (nm *NoteManager) StopArchiveQueue() {
+	nm.mu.Lock()
+	q := nm.archives
+	nm.archives = nil
+	nm.mu.Unlock()
+	if q != nil {
+		close(q.stop)
+		q.wg.Wait()
+	}
+}
+
+// ArchiveStatus reports the archive queue. It is empty when archiving is
+// synchronous.
+func (nm *NoteManager) ArchiveStatus() *ArchiveStatus {
+	nm.mu.RLock()
+	q := nm.archives
+	nm.mu.RUnlock()
+	status := &ArchiveStatus{Jobs: []ArchiveJob{}}
+	if q == nil {
+		return status
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, job := range q.jobs {
+		if job.Status == ArchiveJobPending || job.Status == ArchiveJobRunning {
+			status.Pending++
+		}
+		status.Jobs = append(status.Jobs, *job)
+	}
+	return status
+}
+
+// enqueue adds a job for spec and wakes a worker.
+func (q *archiveQueue) enqueue(written, spec archiveSpec) {
+	q.mu.Lock()
+	q.nextID++
+	q.jobs = append(q.jobs, &ArchiveJob{
+		ID:      q.nextID,
+		URL:     spec.URL,
+		Status:  ArchiveJobPending,
+		Queued:  time.Now(),
+		written: written,
+		spec:    spec,
+	})
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *archiveQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next marks the oldest pending job running and returns it, or nil.
+func (q *archiveQueue) next() *ArchiveJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, job := range q.jobs {
+		if job.Status == ArchiveJobPending {
+			job.Status = ArchiveJobRunning
+			return job
+		}
+	}
+	return nil
+}
+
+// finish records a job's outcome and drops the oldest finished jobs
+// beyond archiveHistory.
+func (q *archiveQueue) finish(job *ArchiveJob, info *ArchiveInfo, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	job.Finished = &now
+	if err != nil {
+		job.Status, job.Error = ArchiveJobFailed, err.Error()
+	} else {
+		job.Status, job.Title, job.FilePath, job.Reader = ArchiveJobDone, info.Title, info.FilePath, info.ReaderPath
+	}
+
+	finished := 0
+	for _, j := range q.jobs {
+		if j.Finished != nil {
+			finished++
+		}
+	}
+	kept := q.jobs[:0]
+	for _, j := range q.jobs {
+		if j.Finished != nil && finished > archiveHistory {
+			finished--
+			continue
+		}
+		kept = append(kept, j)
+	}
+	q.jobs = kept
+}
+
+func (nm *NoteManager) archiveWorker(q *archiveQueue) {
+	defer q.wg.Done()
+	for {
+		job := q.next()
+		if job == nil {
+			select {
+			case <-q.wake:
+				continue
+			case <-q.stop:
+				return
+			}
+		}
+		// Another job may be waiting behind this one; let an idle worker
+		// take it.
+		q.signal()
+
+		info, err := nm.archive(job.spec)
+		if err != nil {
+			log.Printf("Warning: failed to archive %s: %v", job.URL, err)
+		}
+		if applyErr := nm.applyArchive(job.written, info, err); applyErr != nil {
+			log.Printf("Warning: failed to save archive link for %s: %v", job.URL, applyErr)
+		}
+		q.finish(job, info, err)
+	}
+}
+
+// applyArchive swaps the first placeholder for written for the archive
+// link, or back to the sigil when archiving failed. A placeholder the user
+// has since edited away is left alone; the archive file stays in
+// assets/sites either way.
+func (nm *NoteManager) applyArchive(written archiveSpec, info *ArchiveInfo, archiveErr error) error {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	replacement := written.sigil()
+	if archiveErr != nil {
+		nm.alertArchiveFailure(written.URL, archiveErr)
+	} else {
+		replacement = archiveLink(info)
+	}
+	placeholder := written.placeholder()
+	for _, note := range nm.notes {
+		if !strings.Contains(note.Content, placeholder) {
+			continue
+		}
+		note.Update(note.Title, strings.Replace(note.Content, placeholder, replacement, 1))
+		nm.assignTaskIndices()
+		nm.needsSave = true
+		return nm.save()
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestArchiveQueue(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	mgr.archive = func(spec archiveSpec) (*ArchiveInfo, error) {
+		<-release
+		if strings.Contains(spec.URL, "broken") {
+			return nil, errors.New("connection refused")
+		}
+		return &ArchiveInfo{Title: "Example", FilePath: "assets/sites/example.html", Timestamp: time.Date(2026, 10, 16, 9, 30, 0, 0, time.Local)}, nil
+	}
+	mgr.StartArchiveQueue()
+	defer mgr.StopArchiveQueue()
+
+	// AddNote returns while the archive is still blocked.
+	if err := mgr.AddNote("Links", "See +https://example.com and +https://broken.example"); err != nil {
+		t.Fatal(err)
+	}
+	want := "See [https://example.com](https://example.com) (archive pending) and [https://broken.example](https://broken.example) (archive pending)"
+	if got := mgr.notes[0].Content; got != want {
+		t.Fatalf("content = %q, want %q", got, want)
+	}
+	if s := mgr.ArchiveStatus(); s.Pending != 2 || len(s.Jobs) != 2 {
+		t.Fatalf("status = %+v", s)
+	}
+
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for mgr.ArchiveStatus().Pending > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("archives still pending")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	want = "See [Example](assets/sites/example.html) (archived 2026-10-16 09:30) and +https://broken.example"
+	if got := mgr.notes[0].Content; got != want {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+	for _, job := range mgr.ArchiveStatus().Jobs {
+		if strings.Contains(job.URL, "broken") != (job.Status == ArchiveJobFailed) {
+			t.Errorf("job = %+v", job)
+		}
+	}
+}
+
+func TestArchiveQueueResumesPlaceholders(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Links", "Read "+archiveSpec{URL: "https://example.com", Reader: true}.placeholder()); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr, err = NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan archiveSpec, 1)
+	mgr.archive = func(spec archiveSpec) (*ArchiveInfo, error) {
+		done <- spec
+		return &ArchiveInfo{Title: "Example", FilePath: "assets/sites/example.html", Timestamp: time.Now()}, nil
+	}
+	mgr.StartArchiveQueue()
+	select {
+	case spec := <-done:
+		if spec.URL != "https://example.com" || !spec.Reader {
+			t.Errorf("archived %+v", spec)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("placeholder was not queued again")
+	}
+	mgr.StopArchiveQueue()
+	if got := mgr.notes[0].Content; !strings.HasPrefix(got, "Read [Example](assets/sites/example.html)") {
+		t.Errorf("content = %q", got)
+	}
+}
+
+func TestArchiveSigils(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []archiveSpec
+	mgr.archive = func(spec archiveSpec) (*ArchiveInfo, error) {
+		got = append(got, spec)
+		if spec.URL == "https://broken.example" {
+			return nil, errors.New("timeout")
+		}
+		return &ArchiveInfo{Title: "T", FilePath: "assets/sites/t.html", ReaderPath: "assets/sites/reader/t.html", Timestamp: time.Date(2026, 10, 16, 9, 30, 0, 0, time.Local)}, nil
+	}
+	if err := mgr.AddNote("", "Read ++https://a.example and ++https://broken.example"); err != nil {
+		t.Fatal(err)
+	}
+	want := "Read [T](assets/sites/t.html) ([reader](assets/sites/reader/t.html)) (archived 2026-10-16 09:30) and ++https://broken.example"
+	if mgr.notes[0].Content != want {
+		t.Errorf("content = %q, want %q", mgr.notes[0].Content, want)
+	}
+	if len(got) != 2 || !got[0].Reader {
+		t.Errorf("archived %+v", got)
+	}
+
+	// The folder setting asks for reader copies without changing the sigil.
+	mgr.SetArchiveConfig(models.ArchiveConfig{Reader: true})
+	got = nil
+	if err := mgr.AddNote("", "+https://broken.example"); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !got[0].Reader || mgr.notes[0].Content != "+https://broken.example" {
+		t.Errorf("archived %+v, content %q", got, mgr.notes[0].Content)
+	}
+
+	specs := pendingArchives(archiveSpec{URL: "https://a.example", Reader: true}.placeholder() + " " + archiveSpec{URL: "https://b.example"}.placeholder())
+	if len(specs) != 2 || !specs[0].Reader || specs[1].Reader || specs[1].URL != "https://b.example" {
+		t.Errorf("pendingArchives = %+v", specs)
+	}
+}
+
+func TestNoteManagerClose_SavesPendingChanges(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr.StartArchiveQueue()
+	if err := mgr.AddNote("Plan", "first"); err != nil {
+		t.Fatal(err)
+	}
+	// A change not yet written, as after a failed save.
+	mgr.mu.Lock()
+	mgr.notes[0].Content = "second"
+	mgr.needsSave = true
+	mgr.mu.Unlock()
+
+	if err := mgr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if mgr.archives != nil {
+		t.Error("archive queue still running")
+	}
+	reopened, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.notes[0].Content; got != "second" {
+		t.Errorf("content = %q, want the unsaved change", got)
+	}
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"errors"
+	"os"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+)
+
+// ErrArchiveNotFound is returned for an archive filename that doesn't
+// exist in assets/sites.
+var ErrArchiveNotFound = errors.New("archive not found")
+
+// ErrArchiveURLUnknown is returned by RefreshArchive for an archive whose
+// original URL was never recorded, e.g. an old PDF.
+var ErrArchiveURLUnknown = errors.New("the archive does not record its original URL")
+
+// RefreshArchive captures a new snapshot of the page archived as filename,
+// in the same format and with a reader copy if the original had one. The
+// older snapshot is kept, and notes linking to it are left as they are;
+// GetArchivedLinks lists every snapshot of a URL together.
+func (nm *NoteManager) RefreshArchive(filename string) (*ArchiveInfo, error) {
+	meta, err := nm.storage.LoadArchiveMeta(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrArchiveNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if meta.URL == "" {
+		return nil, ErrArchiveURLUnknown
+	}
+
+	reader := nm.storage.AssetExists("assets/sites/" + storage.ReaderSitesDir + "/" + storage.ReaderCopyName(filename))
+	nm.mu.RLock()
+	spec := nm.resolveArchive(archiveSpec{URL: meta.URL, Reader: reader, Format: meta.Format})
+	nm.mu.RUnlock()
+	return nm.archive(spec)
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestRefreshArchive(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sites := filepath.Join(dir, "assets", "sites")
+	name := "2026_10_15_080000_Old-example.com.mhtml"
+	os.WriteFile(filepath.Join(sites, name), []byte("MIME"), 0644)
+	mgr.storage.SaveArchiveMeta(name, models.ArchiveMeta{URL: "https://example.com/old", Format: "mhtml"})
+	os.WriteFile(filepath.Join(sites, "2026_10_15_090000_Scan-example.com.pdf"), []byte("%PDF"), 0644)
+
+	var got archiveSpec
+	mgr.archive = func(spec archiveSpec) (*ArchiveInfo, error) {
+		got = spec
+		return &ArchiveInfo{Title: "Old", FilePath: "assets/sites/new.mhtml", Timestamp: time.Now()}, nil
+	}
+	if _, err := mgr.RefreshArchive(name); err != nil {
+		t.Fatal(err)
+	}
+	if got.URL != "https://example.com/old" || got.Format != "mhtml" || got.Reader {
+		t.Errorf("archived %+v", got)
+	}
+	if _, err := os.Stat(filepath.Join(sites, name)); err != nil {
+		t.Errorf("old snapshot removed: %v", err)
+	}
+
+	if _, err := mgr.RefreshArchive("2026_10_15_090000_Scan-example.com.pdf"); !errors.Is(err, ErrArchiveURLUnknown) {
+		t.Errorf("pdf without metadata err = %v", err)
+	}
+	if _, err := mgr.RefreshArchive("nope.html"); !errors.Is(err, ErrArchiveNotFound) {
+		t.Errorf("missing archive err = %v", err)
+	}
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/chrome"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/reader"
+	"github.com/Xafloc/NoteFlow-Go/internal/safefetch"
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+	"github.com/Xafloc/NoteFlow-Go/internal/webarchive"
+)
+
+// archiveFormats are the file types a link can ask for with +<format>:URL.
+var archiveFormats = []string{"html", "pdf", "mhtml", "warc"}
+
+// archiveSigilRE matches +URL, ++URL for an archive with a reader-mode
+// copy, and either with a format prefix such as +pdf:URL.
+var archiveSigilRE = regexp.MustCompile(`\+(\+?)(?:(` + strings.Join(archiveFormats, "|") + `):)?(https?://[^\s\)]+)`)
+
+// pendingArchiveRE matches the placeholder a sigil is replaced with while
+// its archive is queued; see archiveSpec.placeholder.
+var pendingArchiveRE = regexp.MustCompile(`\[(https?://[^\]\s]+)\]\((https?://[^)\s]+)\) \(archive pending(?:: ([a-z, ]+))?\)`)
+
+// pdfTimeout bounds one headless Chrome run.
+const pdfTimeout = 60 * time.Second
+
+// archiveSoftware names NoteFlow in the archive formats that record it.
+const archiveSoftware = "NoteFlow-Go archive"
+
+// archiveSpec is one page to archive and the extras asked for.
+type archiveSpec struct {
+	URL    string
+	Reader bool   // also store a reader-mode copy
+	Format string // one of archiveFormats; "" in a sigil means the folder default
+
+	chrome string        // browser for PDFs, from the archive config
+	limits archiveLimits // from the archive config
+}
+
+// archiveLimits are the fetch limits of the archive config with its
+// defaults applied.
+type archiveLimits struct {
+	fetch       safefetch.Options
+	pageTimeout time.Duration
+	domainDelay time.Duration
+}
+
+// Archive fetch defaults; see models.ArchiveConfig.
+const (
+	defaultArchiveRequestTimeout = 30 * time.Second
+	defaultArchivePageTimeout    = 90 * time.Second
+	defaultArchiveMaxPageMB      = 50
+	defaultArchiveMaxRedirects   = 10
+	defaultArchiveDomainDelay    = time.Second
+)
+
+// newArchiveLimits applies the defaults to cfg's fetch limits.
+func newArchiveLimits(cfg models.ArchiveConfig) archiveLimits {
+	l := archiveLimits{
+		fetch: safefetch.Options{
+			UserAgent:    cfg.UserAgent,
+			Timeout:      time.Duration(cfg.RequestTimeout) * time.Second,
+			MaxBytes:     int64(cfg.MaxPageMB) << 20,
+			MaxRedirects: cfg.MaxRedirects,
+			AllowPrivate: cfg.AllowPrivate,
+		},
+		pageTimeout: time.Duration(cfg.PageTimeout) * time.Second,
+		domainDelay: time.Duration(cfg.DomainDelay * float64(time.Second)),
+	}
+	if l.fetch.UserAgent == "" {
+		l.fetch.UserAgent = archiveSoftware
+	}
+	if l.fetch.Timeout <= 0 {
+		l.fetch.Timeout = defaultArchiveRequestTimeout
+	}
+	if l.fetch.MaxBytes <= 0 {
+		l.fetch.MaxBytes = defaultArchiveMaxPageMB << 20
+	}
+	switch {
+	case l.fetch.MaxRedirects == 0:
+		l.fetch.MaxRedirects = defaultArchiveMaxRedirects
+	case l.fetch.MaxRedirects < 0:
+		l.fetch.MaxRedirects = 0
+	}
+	if l.pageTimeout <= 0 {
+		l.pageTimeout = defaultArchivePageTimeout
+	}
+	switch {
+	case cfg.DomainDelay == 0:
+		l.domainDelay = defaultArchiveDomainDelay
+	case l.domainDelay < 0:
+		l.domainDelay = 0
+	}
+	return l
+}
+
+// parseArchiveSigil reads the spec written in a sigil matched by
+// archiveSigilRE.
+func parseArchiveSigil(m []string) archiveSpec {
+	return archiveSpec{URL: m[3], Reader: m[1] == "+", Format: m[2]}
+}
+
+// sigil is the note text that asks for s.
+func (s archiveSpec) sigil() string {
+	sigil := "+"
+	if s.Reader {
+		sigil += "+"
+	}
+	if s.Format != "" {
+		sigil += s.Format + ":"
+	}
+	return sigil + s.URL
+}
+
+// placeholder is what the sigil reads as until its archive is written: a
+// plain link to the live page, so the note is usable in the meantime, and
+// no longer a sigil, so saving the note again doesn't queue it twice. The
+// extras are kept so the sigil can be restored if archiving fails.
+func (s archiveSpec) placeholder() string {
+	var opts []string
+	if s.Reader {
+		opts = append(opts, "reader")
+	}
+	if s.Format != "" {
+		opts = append(opts, s.Format)
+	}
+	pending := "archive pending"
+	if len(opts) > 0 {
+		pending += ": " + strings.Join(opts, ", ")
+	}
+	return fmt.Sprintf("[%s](%s) (%s)", s.URL, s.URL, pending)
+}
+
+// pendingArchives returns the specs of the placeholders in content.
+func pendingArchives(content string) []archiveSpec {
+	var specs []archiveSpec
+	for _, m := range pendingArchiveRE.FindAllStringSubmatch(content, -1) {
+		if m[1] != m[2] {
+			continue
+		}
+		spec := archiveSpec{URL: m[1]}
+		for _, opt := range strings.Split(m[3], ",") {
+			switch opt = strings.TrimSpace(opt); {
+			case opt == "reader":
+				spec.Reader = true
+			case slices.Contains(archiveFormats, opt):
+				spec.Format = opt
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// resolveArchive applies the folder-wide archive settings to a spec read
+// from a note. Callers hold nm.mu.
+func (nm *NoteManager) resolveArchive(s archiveSpec) archiveSpec {
+	s.Reader = s.Reader || nm.archiveConfig.Reader
+	if s.Format == "" {
+		s.Format = nm.archiveConfig.Format
+	}
+	if !slices.Contains(archiveFormats, s.Format) {
+		s.Format = "html"
+	}
+	s.chrome = nm.archiveConfig.ChromePath
+	s.limits = newArchiveLimits(nm.archiveConfig)
+	return s
+}
+
+// archiveLink is the markdown a sigil is rewritten to once archived.
+func archiveLink(info *ArchiveInfo) string {
+	reader := ""
+	if info.ReaderPath != "" {
+		reader = fmt.Sprintf(" ([reader](%s))", info.ReaderPath)
+	}
+	return fmt.Sprintf("[%s](%s)%s (archived %s)",
+		info.Title,
+		info.FilePath,
+		reader,
+		info.Timestamp.Format("2006-01-02 15:04"))
+}
+
+// writeReaderCopy extracts the article from an archived page and saves it
+// under assets/sites/reader with the archive's filename. It returns the
+// copy's path relative to the notes folder.
+func (nm *NoteManager) writeReaderCopy(page, websiteURL, filename string, archivedAt time.Time) (string, error) {
+	filename = storage.ReaderCopyName(filename)
+	article, err := reader.Extract(page)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(nm.storage.BasePath, "assets", "sites", storage.ReaderSitesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create reader directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, filename), []byte(reader.Page(article, websiteURL, archivedAt)), 0644); err != nil {
+		return "", fmt.Errorf("failed to save reader copy: %w", err)
+	}
+	return filepath.Join("assets", "sites", storage.ReaderSitesDir, filename), nil
+}
+
+// encodeArchive turns the archived page into the file spec asks for and
+// returns it with its extension. Every format but HTML can fail for
+// reasons unrelated to the page, such as no Chrome being installed for a
+// PDF; the HTML archive is kept instead, so a link is never lost to its
+// format.
+func encodeArchive(ctx context.Context, spec archiveSpec, page, title string, archivedAt time.Time) ([]byte, string) {
+	var (
+		data []byte
+		ext  string
+		err  error
+	)
+	switch spec.Format {
+	case "pdf":
+		ext = ".pdf"
+		data, err = printPDF(page, spec.chrome)
+	case "mhtml":
+		var buf bytes.Buffer
+		ext = ".mhtml"
+		err = webarchive.WriteMHTML(&buf, page, spec.URL, title, archivedAt)
+		data = buf.Bytes()
+	case "warc":
+		// WARC records the HTTP exchange itself, so the page is fetched
+		// again as it comes off the wire rather than as processed HTML.
+		var ex *webarchive.Exchange
+		ext = ".warc.gz"
+		ex, err = webarchive.Fetch(ctx, safefetch.NewClient(spec.limits.fetch), spec.URL, spec.limits.fetch.UserAgent)
+		if err == nil {
+			var buf bytes.Buffer
+			err = webarchive.WriteWARC(&buf, ex, archiveSoftware)
+			data = buf.Bytes()
+		}
+	default:
+		return []byte(page), ".html"
+	}
+	if err != nil {
+		log.Printf("Warning: no %s archive of %s, archiving as HTML: %v", spec.Format, spec.URL, err)
+		return []byte(page), ".html"
+	}
+	return data, ext
+}
+
+// printPDF renders an archived page to PDF with headless Chrome. The page
+// is printed from a temporary file rather than the live URL so the PDF
+// matches the HTML archive, banner included, and the site is fetched once.
+func printPDF(page, chromePath string) ([]byte, error) {
+	binary, err := chrome.Find(chromePath)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "noteflow-pdf-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "page.html")
+	if err := os.WriteFile(src, []byte(page), 0644); err != nil {
+		return nil, err
+	}
+	out := filepath.Join(dir, "page.pdf")
+
+	ctx, cancel := context.WithTimeout(context.Background(), pdfTimeout)
+	defer cancel()
+	if err := chrome.PrintPDF(ctx, binary, "file://"+filepath.ToSlash(src), out); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(out)
+}
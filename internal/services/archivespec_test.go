@@ -0,0 +1,130 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestArchiveSpecFormats(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr.SetArchiveConfig(models.ArchiveConfig{Format: "pdf", ChromePath: "/opt/chrome"})
+
+	tests := []struct {
+		sigil   string
+		written archiveSpec
+		format  string
+	}{
+		{"+https://a.example/x", archiveSpec{URL: "https://a.example/x"}, "pdf"},
+		{"+html:https://a.example/x", archiveSpec{URL: "https://a.example/x", Format: "html"}, "html"},
+		{"++pdf:https://a.example/x", archiveSpec{URL: "https://a.example/x", Reader: true, Format: "pdf"}, "pdf"},
+		{"+warc:https://a.example/x", archiveSpec{URL: "https://a.example/x", Format: "warc"}, "warc"},
+	}
+	for _, tt := range tests {
+		m := archiveSigilRE.FindStringSubmatch(tt.sigil)
+		if m == nil || m[0] != tt.sigil {
+			t.Errorf("%s: matched %q", tt.sigil, m)
+			continue
+		}
+		written := parseArchiveSigil(m)
+		if written != tt.written || written.sigil() != tt.sigil {
+			t.Errorf("%s: parsed %+v, sigil %q", tt.sigil, written, written.sigil())
+		}
+		if spec := mgr.resolveArchive(written); spec.Format != tt.format || spec.chrome != "/opt/chrome" {
+			t.Errorf("%s: resolved %+v", tt.sigil, spec)
+		}
+		if back := pendingArchives(written.placeholder()); len(back) != 1 || back[0] != written {
+			t.Errorf("%s: placeholder %q read back as %+v", tt.sigil, written.placeholder(), back)
+		}
+	}
+}
+
+func TestPrintPDF(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the fake browser")
+	}
+	// The fake browser "prints" by copying the page it was given.
+	bin := filepath.Join(t.TempDir(), "chrome")
+	script := "#!/bin/sh\nfor a in \"$@\"; do case \"$a\" in --print-to-pdf=*) out=\"${a#--print-to-pdf=}\";; file://*) src=\"${a#file://}\";; esac; done\ncp \"$src\" \"$out\"\n"
+	if err := os.WriteFile(bin, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	pdf, err := printPDF("<html>page</html>", bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(pdf) != "<html>page</html>" {
+		t.Errorf("pdf = %q", pdf)
+	}
+	if _, err := printPDF("<html></html>", filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error without a browser")
+	}
+}
+
+func TestEncodeArchive(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<html>live</html>")
+	}))
+	defer srv.Close()
+	page := "<html>archived</html>"
+	now := time.Now()
+
+	data, ext := encodeArchive(context.Background(), archiveSpec{URL: srv.URL, Format: "mhtml"}, page, "T", now)
+	if ext != ".mhtml" || !bytes.Contains(data, []byte("Snapshot-Content-Location: "+srv.URL)) {
+		t.Errorf("mhtml = %s %q", ext, data)
+	}
+
+	// The test server is on loopback, which archiving refuses by default.
+	if _, ext := encodeArchive(context.Background(), archiveSpec{URL: srv.URL, Format: "warc", limits: newArchiveLimits(models.ArchiveConfig{})}, page, "T", now); ext != ".html" {
+		t.Errorf("warc of a loopback URL = %s, want the HTML fallback", ext)
+	}
+	local := newArchiveLimits(models.ArchiveConfig{AllowPrivate: true})
+	data, ext = encodeArchive(context.Background(), archiveSpec{URL: srv.URL, Format: "warc", limits: local}, page, "T", now)
+	if ext != ".warc.gz" {
+		t.Fatalf("warc ext = %s", ext)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	warc, _ := io.ReadAll(zr)
+	if !strings.Contains(string(warc), "<html>live</html>") {
+		t.Errorf("warc lacks the fetched page:\n%s", warc)
+	}
+
+	// No browser: the PDF request falls back to the HTML archive.
+	data, ext = encodeArchive(context.Background(), archiveSpec{URL: srv.URL, Format: "pdf", chrome: filepath.Join(t.TempDir(), "missing")}, page, "T", now)
+	if ext != ".html" || string(data) != page {
+		t.Errorf("pdf fallback = %s %q", ext, data)
+	}
+}
+
+func TestNewArchiveLimits(t *testing.T) {
+	l := newArchiveLimits(models.ArchiveConfig{})
+	if l.fetch.UserAgent != archiveSoftware || l.fetch.Timeout != 30*time.Second || l.fetch.MaxBytes != 50<<20 ||
+		l.fetch.MaxRedirects != 10 || l.fetch.AllowPrivate || l.pageTimeout != 90*time.Second || l.domainDelay != time.Second {
+		t.Errorf("defaults = %+v", l)
+	}
+	l = newArchiveLimits(models.ArchiveConfig{UserAgent: "bot", RequestTimeout: 5, PageTimeout: 20, MaxPageMB: 2, MaxRedirects: -1, DomainDelay: -1, AllowPrivate: true})
+	if l.fetch.UserAgent != "bot" || l.fetch.Timeout != 5*time.Second || l.fetch.MaxBytes != 2<<20 ||
+		l.fetch.MaxRedirects != 0 || !l.fetch.AllowPrivate || l.pageTimeout != 20*time.Second || l.domainDelay != 0 {
+		t.Errorf("configured = %+v", l)
+	}
+	if l := newArchiveLimits(models.ArchiveConfig{DomainDelay: 2.5}); l.domainDelay != 2500*time.Millisecond {
+		t.Errorf("domain delay = %v", l.domainDelay)
+	}
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// auditSummaryLen caps the before and after summaries of an audit entry,
+// in runes.
+const auditSummaryLen = 500
+
+// DefaultAuditLimit and MaxAuditLimit bound how many entries one audit
+// query returns.
+const (
+	DefaultAuditLimit = 100
+	MaxAuditLimit     = 1000
+)
+
+// NotesSnapshot is a folder's notes at one moment, to tell afterwards
+// which notes changed; see NoteManager.AuditChanges.
+type NotesSnapshot struct {
+	generation uint64
+	notes      []noteState
+}
+
+type noteState struct {
+	note               *models.Note // notes are updated in place, so this follows one
+	id, title, content string
+}
+
+// Snapshot returns the notes as they are now. It copies no note text, so
+// it is cheap enough to take around every change.
+func (nm *NoteManager) Snapshot() NotesSnapshot {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+	s := NotesSnapshot{generation: nm.generation, notes: make([]noteState, len(nm.notes))}
+	for i, note := range nm.notes {
+		s.notes[i] = noteState{note: note, id: note.HistoryKey(), title: note.Title, content: note.Content}
+	}
+	return s
+}
+
+// AuditChanges returns an entry for each note created, changed or deleted
+// since before was taken, with only the action, note and summaries set.
+func (nm *NoteManager) AuditChanges(before NotesSnapshot) []models.AuditEntry {
+	after := nm.Snapshot()
+	if after.generation == before.generation {
+		return nil
+	}
+	return diffSnapshots(before.notes, after.notes)
+}
+
+// diffSnapshots pairs up the notes of before and after: the same note
+// edited in place, or else, e.g. after notes.md was reloaded, the note
+// with the same ID. Notes sharing an ID are paired in order from the
+// oldest, since new notes are added at the top.
+func diffSnapshots(before, after []noteState) []models.AuditEntry {
+	pair := make([]int, len(after)) // index in before, or -1
+	paired := make([]bool, len(before))
+	byNote := make(map[*models.Note]int, len(before))
+	for i, n := range before {
+		byNote[n.note] = i
+	}
+	for i, n := range after {
+		pair[i] = -1
+		if j, ok := byNote[n.note]; ok {
+			pair[i], paired[j] = j, true
+		}
+	}
+	byID := make(map[string][]int)
+	for j := len(before) - 1; j >= 0; j-- {
+		if !paired[j] {
+			byID[before[j].id] = append(byID[before[j].id], j)
+		}
+	}
+	for i := len(after) - 1; i >= 0; i-- {
+		if pair[i] >= 0 {
+			continue
+		}
+		if js := byID[after[i].id]; len(js) > 0 {
+			pair[i], paired[js[0]] = js[0], true
+			byID[after[i].id] = js[1:]
+		}
+	}
+
+	var out []models.AuditEntry
+	for i, n := range after {
+		if pair[i] < 0 {
+			out = append(out, models.AuditEntry{Action: models.AuditCreate, NoteID: n.id, Title: n.title, After: shortenSummary(noteLines(n))})
+			continue
+		}
+		o := before[pair[i]]
+		if o.title == n.title && o.content == n.content {
+			continue
+		}
+		b, a := changedLines(noteLines(o), noteLines(n))
+		action := models.AuditUpdate
+		if isToggle(b, a) {
+			action = models.AuditToggle
+		}
+		out = append(out, models.AuditEntry{Action: action, NoteID: n.id, Title: n.title, Before: shortenSummary(b), After: shortenSummary(a)})
+	}
+	for j, o := range before {
+		if !paired[j] {
+			out = append(out, models.AuditEntry{Action: models.AuditDelete, NoteID: o.id, Title: o.title, Before: shortenSummary(noteLines(o))})
+		}
+	}
+	return out
+}
+
+// noteLines is a note as lines, its title first.
+func noteLines(n noteState) []string {
+	return append([]string{"# " + n.title}, strings.Split(n.content, "\n")...)
+}
+
+// changedLines trims the lines a and b have in common at either end,
+// leaving what was replaced.
+func changedLines(a, b []string) ([]string, []string) {
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	return a, b
+}
+
+// isToggle reports whether before and after differ only in checkbox
+// markers.
+func isToggle(before, after []string) bool {
+	if len(before) == 0 || len(before) != len(after) {
+		return false
+	}
+	for i := range before {
+		if !taskHashCheckboxRE.MatchString(before[i]) || normalizeForHash(before[i]) != normalizeForHash(after[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func shortenSummary(lines []string) string {
+	s := strings.Join(lines, "\n")
+	if r := []rune(s); len(r) > auditSummaryLen {
+		return string(r[:auditSummaryLen]) + "…"
+	}
+	return s
+}
+
+// AuditQuery selects audit entries. Zero fields don't filter.
+type AuditQuery struct {
+	Since, Until time.Time // Until is exclusive
+	Action       string
+	NoteID       string
+	Limit        int // default DefaultAuditLimit, at most MaxAuditLimit
+}
+
+// AuditLog records the changes made through the API to one notes folder,
+// in the task database under the registry's user.
+type AuditLog struct {
+	db     *DatabaseService
+	folder string
+}
+
+// Audit returns the audit log of folder.
+func (trs *TaskRegistryService) Audit(folder string) *AuditLog {
+	return &AuditLog{db: trs.db, folder: folder}
+}
+
+// Record stores entries. The changes have already happened, so a failure
+// to record them is only logged.
+func (l *AuditLog) Record(entries []models.AuditEntry) {
+	if err := l.db.InsertAuditEntries(l.folder, entries); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// Query returns the entries matching q, newest first.
+func (l *AuditLog) Query(q AuditQuery) ([]models.AuditEntry, error) {
+	return l.db.QueryAuditEntries(l.folder, q)
+}
+
+// InsertAuditEntries stores entries in folder's audit log.
+func (ds *DatabaseService) InsertAuditEntries(folder string, entries []models.AuditEntry) error {
+	tx, err := ds.db.Begin()
+	if err != nil {
+		return fmt.Errorf("record audit entries: %w", err)
+	}
+	defer tx.Rollback()
+	for _, e := range entries {
+		if _, err := tx.Exec(`
+			INSERT INTO audit_log (user_id, folder, time, ip, request, action, note_id, title, before, after)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			ds.user, folder, e.Time.UTC(), e.IP, e.Request, e.Action, e.NoteID, e.Title, e.Before, e.After,
+		); err != nil {
+			return fmt.Errorf("record audit entry: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("record audit entries: %w", err)
+	}
+	return nil
+}
+
+// QueryAuditEntries returns folder's audit entries matching q, newest
+// first.
+func (ds *DatabaseService) QueryAuditEntries(folder string, q AuditQuery) ([]models.AuditEntry, error) {
+	query := `SELECT id, time, ip, request, action, note_id, title, before, after
+		FROM audit_log WHERE user_id = ? AND folder = ?`
+	args := []any{ds.user, folder}
+	if !q.Since.IsZero() {
+		query += " AND time >= ?"
+		args = append(args, q.Since.UTC())
+	}
+	if !q.Until.IsZero() {
+		query += " AND time < ?"
+		args = append(args, q.Until.UTC())
+	}
+	if q.Action != "" {
+		query += " AND action = ?"
+		args = append(args, q.Action)
+	}
+	if q.NoteID != "" {
+		query += " AND note_id = ?"
+		args = append(args, q.NoteID)
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultAuditLimit
+	}
+	query += " ORDER BY time DESC, id DESC LIMIT ?"
+	args = append(args, min(limit, MaxAuditLimit))
+
+	rows, err := ds.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query audit log: %w", err)
+	}
+	defer rows.Close()
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(&e.ID, &e.Time, &e.IP, &e.Request, &e.Action, &e.NoteID, &e.Title, &e.Before, &e.After); err != nil {
+			return nil, fmt.Errorf("query audit log: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
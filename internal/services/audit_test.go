@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestNoteManager_AuditChanges(t *testing.T) {
+	nm, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := nm.Snapshot()
+	if got := nm.AuditChanges(before); got != nil {
+		t.Errorf("no change: %+v", got)
+	}
+
+	nm.AddNote("Groceries", "- [ ] milk\n- [ ] eggs")
+	nm.AddNote("Plan", "first draft")
+	got := nm.AuditChanges(before)
+	if len(got) != 2 || got[0].Action != models.AuditCreate || got[0].Title != "Plan" || got[1].Title != "Groceries" {
+		t.Fatalf("created: %+v", got)
+	}
+	if got[1].After != "# Groceries\n- [ ] milk\n- [ ] eggs" || got[1].Before != "" {
+		t.Errorf("created summary: %+v", got[1])
+	}
+
+	before = nm.Snapshot()
+	if err := nm.UpdateTask(0, true); err != nil {
+		t.Fatal(err)
+	}
+	got = nm.AuditChanges(before)
+	if len(got) != 1 || got[0].Action != models.AuditToggle || got[0].Before != "- [ ] milk" || got[0].After != "- [x] milk" {
+		t.Errorf("toggled: %+v", got)
+	}
+
+	before = nm.Snapshot()
+	nm.UpdateNote(0, "Plan v2", "first draft")
+	nm.DeleteNote(1)
+	got = nm.AuditChanges(before)
+	if len(got) != 2 {
+		t.Fatalf("update and delete: %+v", got)
+	}
+	if got[0].Action != models.AuditUpdate || got[0].Before != "# Plan" || got[0].After != "# Plan v2" {
+		t.Errorf("updated: %+v", got[0])
+	}
+	if got[1].Action != models.AuditDelete || got[1].Title != "Groceries" || got[1].After != "" {
+		t.Errorf("deleted: %+v", got[1])
+	}
+}
+
+func TestAuditLog_Query(t *testing.T) {
+	db, _ := newTestDB(t)
+	mine := (&TaskRegistryService{db: db}).Audit("/notes")
+	other := (&TaskRegistryService{db: db}).Audit("/other")
+	day := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	mine.Record([]models.AuditEntry{
+		{Time: day, IP: "10.0.0.1", Request: "POST /api/v1/notes", Action: models.AuditCreate, NoteID: "a"},
+		{Time: day.Add(time.Hour), IP: "10.0.0.1", Request: "PUT /api/v1/notes/0", Action: models.AuditUpdate, NoteID: "a"},
+		{Time: day.AddDate(0, 0, 1), IP: "10.0.0.2", Request: "DELETE /api/v1/notes/0", Action: models.AuditDelete, NoteID: "a"},
+	})
+	other.Record([]models.AuditEntry{{Time: day, Action: models.AuditCreate}})
+
+	all, err := mine.Query(AuditQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].Action != models.AuditDelete || all[2].IP != "10.0.0.1" {
+		t.Errorf("all: %+v", all)
+	}
+	yesterday, _ := mine.Query(AuditQuery{Since: day.Add(-time.Hour), Until: day.Add(23 * time.Hour)})
+	if len(yesterday) != 2 {
+		t.Errorf("one day: %+v", yesterday)
+	}
+	updates, _ := mine.Query(AuditQuery{Action: models.AuditUpdate})
+	if len(updates) != 1 || updates[0].Request != "PUT /api/v1/notes/0" {
+		t.Errorf("updates: %+v", updates)
+	}
+	if limited, _ := mine.Query(AuditQuery{Limit: 1}); len(limited) != 1 {
+		t.Errorf("limit 1: %d entries", len(limited))
+	}
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// Backlink is a note that links to another with [[Title]].
+type Backlink struct {
+	Index     int       `json:"index"`
+	Title     string    `json:"title"`
+	Timestamp time.Time `json:"timestamp"`
+	// Context holds the lines of the linking note that contain the link.
+	Context []string `json:"context"`
+}
+
+// rebuildLinkIndexes refreshes the title and backlink indexes from each
+// note's Title and parsed Links. Part of rebuildIndexes; callers hold the
+// write lock.
+func (nm *NoteManager) rebuildLinkIndexes() {
+	old := nm.titleIndex
+	nm.titleIndex = make(map[string]int)
+	nm.backlinks = make(map[string][]int)
+	for i, note := range nm.notes {
+		key := models.WikiLinkKey(note.Title)
+		if _, dup := nm.titleIndex[key]; key != "" && !dup {
+			// Notes are newest first, so a repeated title links to the
+			// most recent note.
+			nm.titleIndex[key] = i
+		}
+		for _, target := range note.Links {
+			nm.backlinks[target] = append(nm.backlinks[target], i)
+		}
+	}
+	// Rendered notes show whether their wiki links resolve, and where to.
+	if !maps.Equal(old, nm.titleIndex) {
+		nm.renderCache.clear()
+	}
+}
+
+// resolveWikiLink is the renderer's link resolver. It runs while notes are
+// being rendered, so callers already hold the read lock.
+func (nm *NoteManager) resolveWikiLink(target string) (int, bool) {
+	index, ok := nm.titleIndex[models.WikiLinkKey(target)]
+	return index, ok
+}
+
+// Backlinks returns the notes that link to the note at index, newest
+// first. Only links that resolve to this note count: when two notes share
+// a title the older one has no backlinks.
+func (nm *NoteManager) Backlinks(index int) ([]Backlink, error) {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	if index < 0 || index >= len(nm.notes) {
+		return nil, fmt.Errorf("note index %d out of range", index)
+	}
+	key := models.WikiLinkKey(nm.notes[index].Title)
+	result := []Backlink{}
+	if key == "" || nm.titleIndex[key] != index {
+		return result, nil
+	}
+
+	for _, i := range nm.backlinks[key] {
+		if i == index {
+			continue
+		}
+		note := nm.notes[i]
+		var context []string
+		for _, line := range strings.Split(note.Content, "\n") {
+			for _, target := range models.ExtractWikiLinks(line) {
+				if target == key {
+					context = append(context, strings.TrimSpace(line))
+					break
+				}
+			}
+		}
+		result = append(result, Backlink{Index: i, Title: note.Title, Timestamp: note.Timestamp, Context: context})
+	}
+	return result, nil
+}
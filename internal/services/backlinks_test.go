@@ -0,0 +1,58 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBacklinksAndRendering(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Notes are newest first: after these adds Roadmap is 2, Standup 1,
+	// Retro 0.
+	for _, n := range [][2]string{
+		{"Roadmap", "Q4 goals"},
+		{"Standup", "Blocked on [[roadmap]] review\nunrelated line"},
+		{"Retro", "Went well: [[Roadmap|the roadmap]]. Missing: [[Budget]]"},
+	} {
+		if err := mgr.AddNote(n[0], n[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links, err := mgr.Backlinks(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 2 || links[0].Title != "Retro" || links[1].Title != "Standup" {
+		t.Fatalf("backlinks = %+v", links)
+	}
+	if want := []string{"Blocked on [[roadmap]] review"}; !reflect.DeepEqual(links[1].Context, want) {
+		t.Errorf("context = %v, want %v", links[1].Context, want)
+	}
+	if links, _ := mgr.Backlinks(0); len(links) != 0 {
+		t.Errorf("Retro backlinks = %+v", links)
+	}
+
+	html, err := mgr.RenderNotesHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, `href="#note-2" data-note-index="2" onclick="event.stopPropagation();">the roadmap</a>`) {
+		t.Errorf("resolved link missing from:\n%s", html)
+	}
+	if !strings.Contains(html, `class="wiki-link wiki-link-missing"`) {
+		t.Errorf("unresolved [[Budget]] not marked:\n%s", html)
+	}
+
+	// Deleting a linking note drops its backlink.
+	if err := mgr.DeleteNote(0); err != nil {
+		t.Fatal(err)
+	}
+	if links, _ := mgr.Backlinks(1); len(links) != 1 || links[0].Title != "Standup" {
+		t.Errorf("backlinks after delete = %+v", links)
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// ErrBackupNotFound is returned for a name that names no backup.
+var ErrBackupNotFound = errors.New("backup not found")
+
+// SetBackupPolicy sets how many timestamped backups of notes.md are kept
+// and the least time between two of them. keep zero or less turns them
+// off.
+func (nm *NoteManager) SetBackupPolicy(keep int, interval time.Duration) {
+	nm.storage.SetBackupPolicy(keep, interval)
+}
+
+// ListBackups returns the backups of notes.md, newest first.
+func (nm *NoteManager) ListBackups() ([]models.Backup, error) {
+	return nm.storage.ListBackups()
+}
+
+// GetBackup returns the content of the backup with name.
+func (nm *NoteManager) GetBackup(name string) ([]byte, error) {
+	data, err := nm.storage.ReadBackup(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBackupNotFound
+	}
+	return data, err
+}
+
+// RestoreBackup replaces notes.md with the backup with name and reloads
+// the notes. The notes.md it replaces is backed up like any other save,
+// so a restore can be undone.
+func (nm *NoteManager) RestoreBackup(name string) error {
+	data, err := nm.GetBackup(name)
+	if err != nil {
+		return err
+	}
+
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if err := nm.storage.WriteNotesFile(data); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", name, err)
+	}
+	notes, err := nm.storage.LoadNotes()
+	if err != nil {
+		return err
+	}
+	nm.setNotes(notes)
+	nm.needsSave = false
+	nm.synced()
+	nm.events.Publish(Event{Type: EventNotesChanged, Folder: nm.storage.BasePath})
+	return nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestBackupsRotateAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr.SetBackupPolicy(2, 0)
+	if err := mgr.AddNote("First", "one"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Backups are named by the second, so plant older ones instead of
+	// waiting between saves.
+	backupsDir := filepath.Join(dir, filepath.FromSlash(models.BackupsDir))
+	os.MkdirAll(backupsDir, 0755)
+	os.WriteFile(filepath.Join(backupsDir, "notes-20260101-090000.md"), []byte("## 2026-01-01 09:00:00 - Oldest\n\noldest\n"), 0644)
+	os.WriteFile(filepath.Join(backupsDir, "notes-20260102-090000.md"), []byte("## 2026-01-02 09:00:00 - Older\n\nolder\n"), 0644)
+
+	if err := mgr.AddNote("Second", "two"); err != nil {
+		t.Fatal(err)
+	}
+	backups, err := mgr.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 || backups[1].Name != "notes-20260102-090000.md" {
+		t.Fatalf("backups = %+v, want the new one and notes-20260102-090000.md", backups)
+	}
+	data, err := mgr.GetBackup(backups[0].Name)
+	if err != nil || !strings.Contains(string(data), "First") || strings.Contains(string(data), "Second") {
+		t.Fatalf("newest backup = %q, %v; want notes.md before the second note", data, err)
+	}
+
+	if err := mgr.RestoreBackup(backups[1].Name); err != nil {
+		t.Fatal(err)
+	}
+	notes := mgr.GetAllNotes()
+	if len(notes) != 1 || notes[0].Title != "Older" {
+		t.Fatalf("notes after restore = %v", notes)
+	}
+
+	for _, name := range []string{"notes-20990101-000000.md", "../notes.md"} {
+		if err := mgr.RestoreBackup(name); !errors.Is(err, ErrBackupNotFound) {
+			t.Errorf("RestoreBackup(%q) = %v, want ErrBackupNotFound", name, err)
+		}
+	}
+}
+
+func TestBackupsInterval(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr.SetBackupPolicy(10, time.Hour)
+	for _, title := range []string{"A", "B", "C"} {
+		if err := mgr.AddNote(title, title); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if backups, _ := mgr.ListBackups(); len(backups) != 1 {
+		t.Errorf("%d backups within the interval, want 1", len(backups))
+	}
+
+	mgr.SetBackupPolicy(0, 0)
+	os.RemoveAll(filepath.Join(dir, filepath.FromSlash(models.BackupsDir)))
+	if err := mgr.AddNote("D", "d"); err != nil {
+		t.Fatal(err)
+	}
+	if backups, _ := mgr.ListBackups(); len(backups) != 0 {
+		t.Errorf("%d backups with backups off", len(backups))
+	}
+}
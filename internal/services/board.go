@@ -0,0 +1,85 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// BoardCard is one task on the kanban board.
+type BoardCard struct {
+	Index     int        `json:"index"` // task index for /api/tasks/:index
+	Text      string     `json:"text"`  // task text without checkbox and metadata tokens
+	NoteIndex int        `json:"note_index"`
+	NoteTitle string     `json:"note_title"`
+	Priority  int        `json:"priority,omitempty"`
+	Due       *time.Time `json:"due,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	Depth     int        `json:"depth"`
+}
+
+// BoardColumn holds the tasks in one state.
+type BoardColumn struct {
+	State models.TaskState `json:"state"`
+	Tasks []BoardCard      `json:"tasks"`
+}
+
+// Board is the payload behind GET /api/board: one column per task state,
+// in models.TaskStates order, each listing tasks in note order.
+type Board struct {
+	Columns []BoardColumn `json:"columns"`
+}
+
+// Board groups this folder's tasks by state.
+func (nm *NoteManager) Board() *Board {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	board := &Board{}
+	column := make(map[models.TaskState]int, len(models.TaskStates))
+	for i, state := range models.TaskStates {
+		board.Columns = append(board.Columns, BoardColumn{State: state, Tasks: []BoardCard{}})
+		column[state] = i
+	}
+	for noteIndex, note := range nm.notes {
+		for _, task := range note.Tasks {
+			card := BoardCard{
+				Index:     task.Index,
+				Text:      stripTaskCheckbox(models.CleanTaskText(task.Text)),
+				NoteIndex: noteIndex,
+				NoteTitle: note.Title,
+				Priority:  task.Priority,
+				Tags:      task.Tags,
+				Depth:     task.Depth,
+			}
+			if !task.DueDate.IsZero() {
+				due := task.DueDate
+				card.Due = &due
+			}
+			c := &board.Columns[column[task.State]]
+			c.Tasks = append(c.Tasks, card)
+		}
+	}
+	return board
+}
+
+// SetTaskState moves a task to state by rewriting its checkbox in
+// notes.md. Toggle listeners hear about it like a checkbox click.
+func (nm *NoteManager) SetTaskState(taskIndex int, state models.TaskState) error {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.refresh()
+
+	for _, note := range nm.notes {
+		if note.SetTaskState(taskIndex, state) {
+			nm.needsSave = true
+			if err := nm.saveEvent(taskEvent(note, taskIndex)); err != nil {
+				return err
+			}
+			nm.emitTaskToggle(note, taskIndex)
+			return nil
+		}
+	}
+	return fmt.Errorf("task with index %d not found", taskIndex)
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestBoard(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Sprint", "- [ ] Plan #q4\n- [/] Build !p1\n- [x] Ship"); err != nil {
+		t.Fatal(err)
+	}
+
+	board := mgr.Board()
+	if len(board.Columns) != 3 {
+		t.Fatalf("columns = %+v", board.Columns)
+	}
+	for i, want := range []string{"Plan", "Build", "Ship"} {
+		col := board.Columns[i]
+		if len(col.Tasks) != 1 || col.Tasks[0].Text != want || col.Tasks[0].NoteTitle != "Sprint" {
+			t.Errorf("column %s = %+v, want %s", col.State, col.Tasks, want)
+		}
+	}
+
+	plan := board.Columns[0].Tasks[0].Index
+	if err := mgr.SetTaskState(plan, models.TaskDoing); err != nil {
+		t.Fatal(err)
+	}
+	if got := mgr.Board().Columns[1].Tasks; len(got) != 2 || got[0].Text != "Plan" {
+		t.Errorf("doing column = %+v", got)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "notes.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "- [/] Plan #q4") {
+		t.Errorf("notes.md not updated:\n%s", data)
+	}
+	if err := mgr.SetTaskState(99, models.TaskDone); err == nil {
+		t.Error("expected an error for an unknown task")
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// DefaultDashboardWeeks is how many weeks of task activity Dashboard
+// reports when not told; MaxDashboardWeeks caps it at ten years.
+const (
+	DefaultDashboardWeeks = 12
+	MaxDashboardWeeks     = 520
+)
+
+// Dashboard is the statistics dashboard across every registered folder.
+type Dashboard struct {
+	Generated time.Time     `json:"generated"`
+	Totals    FolderStats   `json:"totals"` // all folders summed, without folder fields
+	Folders   []FolderStats `json:"folders"`
+}
+
+// FolderStats is one folder's figures on the dashboard.
+type FolderStats struct {
+	FolderID       int         `json:"folder_id,omitempty"`
+	Path           string      `json:"path,omitempty"`
+	Name           string      `json:"name,omitempty"` // see models.FolderName
+	Group          string      `json:"group,omitempty"`
+	Notes          int         `json:"notes"`
+	Tasks          int         `json:"tasks"`
+	CompletedTasks int         `json:"completed_tasks"`
+	PendingTasks   int         `json:"pending_tasks"`
+	CompletionRate float64     `json:"completion_rate"` // completed / tasks, 0 to 1
+	Archives       int         `json:"archives"`
+	NotesBytes     int64       `json:"notes_bytes"`
+	AssetsBytes    int64       `json:"assets_bytes"`    // uploads and archived sites on disk
+	OffloadedBytes int64       `json:"offloaded_bytes"` // those in the S3 bucket
+	Weeks          []WeekStats `json:"weeks"`           // oldest first, this week last
+	// Error says why the folder's notes couldn't be read; its task
+	// figures come from the task DB and are still there.
+	Error string `json:"error,omitempty"`
+}
+
+// WeekStats is the task activity of one week, Monday to Sunday in local
+// time. As in DailyStats, a task is created when its note was and
+// completed when the task registry last saw it change.
+type WeekStats struct {
+	Week           string `json:"week"` // the Monday, YYYY-MM-DD
+	TasksCreated   int    `json:"tasks_created"`
+	TasksCompleted int    `json:"tasks_completed"`
+}
+
+// Dashboard gathers the statistics of every active registered folder from
+// the task DB and the folders' notes, with weeks of task activity up to
+// now's week (DefaultDashboardWeeks when weeks is 0).
+func (trs *TaskRegistryService) Dashboard(weeks int, now time.Time) (*Dashboard, error) {
+	if weeks <= 0 {
+		weeks = DefaultDashboardWeeks
+	}
+	weeks = min(weeks, MaxDashboardWeeks)
+	global, err := trs.db.GetGlobalTasks()
+	if err != nil {
+		return nil, err
+	}
+	folders, err := trs.db.GetActiveFolders()
+	if err != nil {
+		return nil, err
+	}
+
+	first := weekStart(now).AddDate(0, 0, -7*(weeks-1))
+	newWeeks := func() []WeekStats {
+		out := make([]WeekStats, weeks)
+		for i := range out {
+			out[i].Week = first.AddDate(0, 0, 7*i).Format("2006-01-02")
+		}
+		return out
+	}
+	// week returns the index of t's week, or -1 outside the range.
+	week := func(t time.Time) int {
+		i := int(math.Round(weekStart(t.In(now.Location())).Sub(first).Hours() / (24 * 7)))
+		if i < 0 || i >= weeks {
+			return -1
+		}
+		return i
+	}
+
+	summaries := map[int]models.TaskSummary{}
+	for _, s := range global.Summaries {
+		summaries[s.FolderID] = s
+	}
+	completed := map[int][]time.Time{}
+	for _, t := range global.Tasks {
+		if t.Completed && !t.LastUpdated.IsZero() {
+			completed[t.FolderID] = append(completed[t.FolderID], t.LastUpdated)
+		}
+	}
+
+	d := &Dashboard{Generated: now, Totals: FolderStats{Weeks: newWeeks()}, Folders: []FolderStats{}}
+	for _, folder := range folders {
+		fs := FolderStats{
+			FolderID: folder.ID,
+			Path:     folder.Path,
+			Name:     models.FolderName(folder.Path, folder.Alias),
+			Group:    folder.Group,
+			Weeks:    newWeeks(),
+		}
+		s := summaries[folder.ID]
+		fs.Tasks, fs.CompletedTasks, fs.PendingTasks = s.TotalTasks, s.CompletedTasks, s.PendingTasks
+		for _, t := range completed[folder.ID] {
+			if i := week(t); i >= 0 {
+				fs.Weeks[i].TasksCompleted++
+			}
+		}
+
+		trs.mu.Lock()
+		nm, err := trs.folderNoteManager(&folder)
+		trs.mu.Unlock()
+		if err == nil {
+			err = nm.folderStats(&fs, week)
+		}
+		if err != nil {
+			fs.Error = err.Error()
+		}
+		fs.CompletionRate = completionRate(fs.CompletedTasks, fs.Tasks)
+		d.Folders = append(d.Folders, fs)
+		d.Totals.add(fs)
+	}
+	d.Totals.CompletionRate = completionRate(d.Totals.CompletedTasks, d.Totals.Tasks)
+	return d, nil
+}
+
+// folderStats fills in the figures of fs that come from the folder's notes
+// and files.
+func (nm *NoteManager) folderStats(fs *FolderStats, week func(time.Time) int) error {
+	nm.mu.RLock()
+	fs.Notes = len(nm.notes)
+	for _, note := range nm.notes {
+		if i := week(note.Timestamp); i >= 0 {
+			fs.Weeks[i].TasksCreated += len(note.Tasks)
+		}
+	}
+	nm.mu.RUnlock()
+
+	archives, err := nm.storage.ArchiveTimes()
+	if err != nil {
+		return err
+	}
+	fs.Archives = len(archives)
+	usage, err := nm.storage.AssetUsage()
+	if err != nil {
+		return err
+	}
+	fs.AssetsBytes, fs.OffloadedBytes = usage.LocalBytes, usage.OffloadedBytes
+	fs.NotesBytes, err = nm.storage.NotesSize()
+	return err
+}
+
+// add sums the counts of f into t.
+func (t *FolderStats) add(f FolderStats) {
+	t.Notes += f.Notes
+	t.Tasks += f.Tasks
+	t.CompletedTasks += f.CompletedTasks
+	t.PendingTasks += f.PendingTasks
+	t.Archives += f.Archives
+	t.NotesBytes += f.NotesBytes
+	t.AssetsBytes += f.AssetsBytes
+	t.OffloadedBytes += f.OffloadedBytes
+	for i, w := range f.Weeks {
+		t.Weeks[i].TasksCreated += w.TasksCreated
+		t.Weeks[i].TasksCompleted += w.TasksCompleted
+	}
+}
+
+func completionRate(completed, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(completed) / float64(total)
+}
+
+// weekStart returns midnight of the Monday starting t's week, in t's
+// location.
+func weekStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+	return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+}
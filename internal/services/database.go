@@ -4,12 +4,16 @@ import (
 	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
+	"github.com/Xafloc/NoteFlow-Go/internal/libsql"
 	"github.com/Xafloc/NoteFlow-Go/internal/models"
 	_ "modernc.org/sqlite"
 )
@@ -17,7 +21,7 @@ import (
 // taskHashCheckboxRE matches the checkbox marker inside a task line. We
 // normalize it out before hashing so a task's identity does not depend on
 // its completion state — toggling `[ ]` ↔ `[x]` must not change the hash.
-var taskHashCheckboxRE = regexp.MustCompile(`\[[ xX]\]`)
+var taskHashCheckboxRE = regexp.MustCompile(`\[[ xX/]\]`)
 
 // normalizeForHash returns the canonical form of task text used for hashing:
 // the checkbox marker is replaced with a placeholder so completion state
@@ -30,13 +34,59 @@ func normalizeForHash(text string) string {
 type DatabaseService struct {
 	db   *sql.DB
 	path string
+	// user scopes folders and tasks to one account (users.id); 0 is the
+	// server's owner, whose folders have a NULL user_id. See ForUser.
+	user int
 }
 
-// DefaultDatabasePath returns the conventional location of the cross-project
-// task DB: ~/.config/noteflow/tasks.db. It is exported so subcommands and
-// tests can target the same file the server uses (or, for tests, choose not
-// to).
+// ownerCond restricts a query on folders f to the service's user.
+const ownerCond = "COALESCE(f.user_id, 0) = ?"
+
+// ErrFolderOwned is returned when registering a folder another user has
+// already registered.
+var ErrFolderOwned = errors.New("folder is registered by another user")
+
+// ForUser returns a view of the database that only sees the folders and
+// tasks of the given user. It shares the connection; don't Close it.
+func (ds *DatabaseService) ForUser(userID int) *DatabaseService {
+	scoped := *ds
+	scoped.user = userID
+	return &scoped
+}
+
+// userID is the value stored in folders.user_id for the service's user.
+func (ds *DatabaseService) userID() any {
+	if ds.user == 0 {
+		return nil
+	}
+	return ds.user
+}
+
+// TaskDBEnv names where the task DB is, overriding "task_db" in the user
+// config: a file path, or the URL of a libsql server (libsql://host or
+// https://host, e.g. Turso or sqld) that several machines share for one
+// global task view. TaskDBTokenEnv holds that server's auth token.
+const (
+	TaskDBEnv      = "NOTEFLOW_TASK_DB"
+	TaskDBTokenEnv = "NOTEFLOW_TASK_DB_TOKEN"
+)
+
+// DefaultDatabasePath returns where the cross-project task DB is: $TaskDBEnv,
+// else "task_db" in ~/.config/noteflow/noteflow.json, else the conventional
+// ~/.config/noteflow/tasks.db. It is exported so subcommands and tests can
+// target the same DB the server uses (or, for tests, choose not to).
 func DefaultDatabasePath() (string, error) {
+	if dsn := os.Getenv(TaskDBEnv); dsn != "" {
+		return dsn, nil
+	}
+	if configPath, err := models.DefaultConfigPath(); err == nil {
+		if data, err := os.ReadFile(configPath); err == nil {
+			var config models.Config
+			if json.Unmarshal(data, &config) == nil && config.TaskDB != "" {
+				return config.TaskDB, nil
+			}
+		}
+	}
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
@@ -53,19 +103,46 @@ func NewDatabaseService() (*DatabaseService, error) {
 	return NewDatabaseServiceAt(dbPath)
 }
 
-// NewDatabaseServiceAt opens (or creates) a task DB at the given path. The
-// containing directory is created if missing. Used by tests and by any
-// subcommand that needs to inject a different path.
-func NewDatabaseServiceAt(dbPath string) (*DatabaseService, error) {
-	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+// RemoteTaskDB reports whether dsn is a libsql server's URL rather than a
+// file path.
+func RemoteTaskDB(dsn string) bool {
+	return libsql.IsDSN(dsn)
+}
+
+// OpenTaskDB opens the task DB at dsn, a file path (whose directory is
+// created if missing) or a libsql server's URL, without migrating it.
+func OpenTaskDB(dsn string) (*sql.DB, error) {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return nil, fmt.Errorf("postgres URLs are not supported for the task DB; use a libsql server (libsql:// or https://)")
+	}
+	if RemoteTaskDB(dsn) {
+		connector, err := libsql.NewConnector(dsn, os.Getenv(TaskDBTokenEnv))
+		if err != nil {
+			return nil, err
+		}
+		db := sql.OpenDB(connector)
+		// The server drops streams idle for a few seconds.
+		db.SetConnMaxIdleTime(5 * time.Second)
+		return db, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dsn), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
-
-	// Open database connection
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	return db, nil
+}
+
+// NewDatabaseServiceAt opens (or creates) a task DB at the given path or
+// libsql URL (see OpenTaskDB). Used by tests and by any subcommand that
+// needs to inject a different path.
+func NewDatabaseServiceAt(dbPath string) (*DatabaseService, error) {
+	db, err := OpenTaskDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
 
 	// Enable foreign-key enforcement so the ON DELETE CASCADE in the tasks
 	// table actually fires. SQLite leaves FKs off by default per connection.
@@ -89,74 +166,6 @@ func NewDatabaseServiceAt(dbPath string) (*DatabaseService, error) {
 	return service, nil
 }
 
-// migrate creates the database schema and adds any missing columns.
-//
-// `task_hash` was added 2026-05-12 to give tasks IDs stable across syncs
-// — see docs/20260512_task_db_schema.md §7. New DBs get the column from
-// the CREATE TABLE; pre-existing DBs get it via the addColumnIfMissing
-// pass below.
-func (ds *DatabaseService) migrate() error {
-	// Step 1: create core tables (no-op if they already exist) and the
-	// indexes that don't depend on later-added columns. The task_hash index
-	// is created in step 3 below, AFTER the column is guaranteed to exist.
-	schema := `
-	CREATE TABLE IF NOT EXISTS folders (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		path TEXT UNIQUE NOT NULL,
-		last_scan DATETIME DEFAULT CURRENT_TIMESTAMP,
-		active BOOLEAN DEFAULT 1
-	);
-
-	CREATE TABLE IF NOT EXISTS tasks (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		folder_id INTEGER NOT NULL,
-		file_path TEXT NOT NULL,
-		line_number INTEGER NOT NULL,
-		content TEXT NOT NULL,
-		completed BOOLEAN DEFAULT 0,
-		last_updated DATETIME DEFAULT CURRENT_TIMESTAMP,
-		task_hash TEXT,
-		FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE CASCADE
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_tasks_folder ON tasks(folder_id);
-	CREATE INDEX IF NOT EXISTS idx_tasks_completed ON tasks(completed);
-	CREATE INDEX IF NOT EXISTS idx_tasks_folder_file ON tasks(folder_id, file_path);
-	`
-	if _, err := ds.db.Exec(schema); err != nil {
-		return err
-	}
-
-	// Step 2: ensure the task_hash column exists. New DBs got it from the
-	// CREATE TABLE above (no-op here); older DBs predate the column and need
-	// an ALTER. This must run before any operation references task_hash —
-	// including the index in step 3.
-	if err := ds.addColumnIfMissing("tasks", "task_hash", "TEXT"); err != nil {
-		return err
-	}
-
-	// Step 3: create the task_hash index now that the column is guaranteed
-	// to exist on both fresh and migrated databases.
-	if _, err := ds.db.Exec(`CREATE INDEX IF NOT EXISTS idx_tasks_hash ON tasks(folder_id, task_hash)`); err != nil {
-		return err
-	}
-
-	// Step 4: saved views for the `noteflow tasks` CLI (Goal 2 — "Save common
-	// queries as views"). Independent of the tasks table; no FK because views
-	// reference filter shapes, not specific tasks.
-	if _, err := ds.db.Exec(`
-		CREATE TABLE IF NOT EXISTS task_views (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT UNIQUE NOT NULL,
-			filters TEXT NOT NULL,
-			created DATETIME DEFAULT CURRENT_TIMESTAMP
-		);
-	`); err != nil {
-		return err
-	}
-	return nil
-}
-
 // SaveView upserts a named view storing a JSON-encoded filter blob.
 func (ds *DatabaseService) SaveView(name, filters string) error {
 	_, err := ds.db.Exec(`
@@ -199,41 +208,6 @@ func (ds *DatabaseService) DeleteView(name string) error {
 	return err
 }
 
-// addColumnIfMissing is a tiny one-shot migration helper. SQLite's
-// `ALTER TABLE ADD COLUMN` errors if the column already exists, so we
-// check first via PRAGMA table_info. This is enough for the small set
-// of forward-compatible additions we expect in the near term; if the
-// schema grows more, replace this with a real schema_version table.
-func (ds *DatabaseService) addColumnIfMissing(table, column, sqlType string) error {
-	rows, err := ds.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
-	if err != nil {
-		return fmt.Errorf("check columns on %s: %w", table, err)
-	}
-	defer rows.Close()
-	for rows.Next() {
-		var (
-			cid          int
-			name, ctype  string
-			notnull, pk  int
-			dflt         sql.NullString
-		)
-		if err := rows.Scan(&cid, &name, &ctype, &notnull, &dflt, &pk); err != nil {
-			return fmt.Errorf("scan column info: %w", err)
-		}
-		if name == column {
-			return nil
-		}
-	}
-	if err := rows.Err(); err != nil {
-		return err
-	}
-	_, err = ds.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, sqlType))
-	if err != nil {
-		return fmt.Errorf("add column %s.%s: %w", table, column, err)
-	}
-	return nil
-}
-
 // ComputeTaskHashes returns one hash per task, using a 12-char hex prefix of
 // sha256(text). Duplicate-text tasks within the same folder are disambiguated
 // by an occurrence suffix (#1, #2, ...) so each task gets a unique key.
@@ -258,17 +232,22 @@ func ComputeTaskHashes(tasks []models.Task) []string {
 	return out
 }
 
-// RegisterFolder registers a folder in the database
+// RegisterFolder registers a folder in the database for the service's
+// user. A folder belongs to whoever registered it first.
 func (ds *DatabaseService) RegisterFolder(folderPath string) (*models.FolderRegistry, error) {
 	// Check if folder already exists
 	var folder models.FolderRegistry
+	var owner int
 	err := ds.db.QueryRow(`
-		SELECT id, path, last_scan, active 
+		SELECT id, path, last_scan, active, COALESCE(user_id, 0)
 		FROM folders 
 		WHERE path = ?`, folderPath).Scan(
-		&folder.ID, &folder.Path, &folder.LastScan, &folder.Active)
+		&folder.ID, &folder.Path, &folder.LastScan, &folder.Active, &owner)
 
 	if err == nil {
+		if owner != ds.user {
+			return nil, ErrFolderOwned
+		}
 		// Update as active if it was inactive
 		if !folder.Active {
 			_, err = ds.db.Exec(`UPDATE folders SET active = 1 WHERE id = ?`, folder.ID)
@@ -286,8 +265,8 @@ func (ds *DatabaseService) RegisterFolder(folderPath string) (*models.FolderRegi
 
 	// Insert new folder
 	result, err := ds.db.Exec(`
-		INSERT INTO folders (path, last_scan, active) 
-		VALUES (?, ?, 1)`, folderPath, time.Now())
+		INSERT INTO folders (path, last_scan, active, user_id) 
+		VALUES (?, ?, 1, ?)`, folderPath, time.Now(), ds.userID())
 	if err != nil {
 		return nil, fmt.Errorf("failed to register folder: %w", err)
 	}
@@ -320,39 +299,72 @@ func (ds *DatabaseService) RegisterFolder(folderPath string) (*models.FolderRegi
 // This is the foundation for Goal 2's bidirectional integrity and CLI/UI
 // references to tasks by ID — see docs/20260512_task_db_schema.md §7.
 func (ds *DatabaseService) SyncFolderTasks(folderID int, tasks []models.Task) error {
+	_, err := ds.SyncFolderTasksDiff(folderID, tasks)
+	return err
+}
+
+// TaskChange is one task in a TaskDiff.
+type TaskChange struct {
+	ID      int    `json:"id"`      // tasks.id
+	Content string `json:"content"` // the task line, checkbox included
+}
+
+// TaskDiff is what a sync changed in a folder's tasks. Editing a task's
+// text counts as removing it and adding a new one.
+type TaskDiff struct {
+	Added     []TaskChange `json:"added,omitempty"`
+	Completed []TaskChange `json:"completed,omitempty"`
+	Reopened  []TaskChange `json:"reopened,omitempty"`
+	Removed   int          `json:"removed,omitempty"`
+}
+
+// Empty reports whether the sync changed nothing.
+func (d TaskDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Completed) == 0 && len(d.Reopened) == 0 && d.Removed == 0
+}
+
+// SyncFolderTasksDiff is SyncFolderTasks reporting what changed.
+func (ds *DatabaseService) SyncFolderTasksDiff(folderID int, tasks []models.Task) (TaskDiff, error) {
+	var diff TaskDiff
 	hashes := ComputeTaskHashes(tasks)
 
 	tx, err := ds.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to start transaction: %w", err)
+		return diff, fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer tx.Rollback()
 
 	// Drop any legacy rows for this folder that pre-date the task_hash column.
 	// Once those are gone the upsert path is the only way rows get created.
 	if _, err := tx.Exec(`DELETE FROM tasks WHERE folder_id = ? AND task_hash IS NULL`, folderID); err != nil {
-		return fmt.Errorf("clear legacy rows: %w", err)
+		return diff, fmt.Errorf("clear legacy rows: %w", err)
 	}
 
-	// Pull existing hashes so we know what to delete.
-	rows, err := tx.Query(`SELECT task_hash FROM tasks WHERE folder_id = ?`, folderID)
+	// Pull existing rows so we know what to delete and what changed.
+	type existingTask struct {
+		id        int
+		content   string
+		completed bool
+	}
+	rows, err := tx.Query(`SELECT id, task_hash, content, completed FROM tasks WHERE folder_id = ?`, folderID)
 	if err != nil {
-		return fmt.Errorf("list existing hashes: %w", err)
+		return diff, fmt.Errorf("list existing hashes: %w", err)
 	}
-	existing := make(map[string]bool)
+	existing := make(map[string]existingTask)
 	for rows.Next() {
+		var t existingTask
 		var h sql.NullString
-		if err := rows.Scan(&h); err != nil {
+		if err := rows.Scan(&t.id, &h, &t.content, &t.completed); err != nil {
 			rows.Close()
-			return fmt.Errorf("scan hash: %w", err)
+			return diff, fmt.Errorf("scan hash: %w", err)
 		}
 		if h.Valid {
-			existing[h.String] = true
+			existing[h.String] = t
 		}
 	}
 	if err := rows.Err(); err != nil {
 		rows.Close()
-		return err
+		return diff, err
 	}
 	rows.Close()
 
@@ -363,11 +375,18 @@ func (ds *DatabaseService) SyncFolderTasks(folderID int, tasks []models.Task) er
 	}
 
 	// Delete rows no longer present in the current task list.
-	for h := range existing {
+	for h, old := range existing {
 		if !currentSet[h] {
 			if _, err := tx.Exec(`DELETE FROM tasks WHERE folder_id = ? AND task_hash = ?`, folderID, h); err != nil {
-				return fmt.Errorf("delete stale task: %w", err)
+				return diff, fmt.Errorf("delete stale task: %w", err)
+			}
+			if err := recordTaskEvent(tx, folderID, h, old.content, TaskEventRemoved, old.completed, now); err != nil {
+				return diff, err
 			}
+			if _, err := tx.Exec(`DELETE FROM task_links WHERE folder_id = ? AND task_hash = ?`, folderID, h); err != nil {
+				return diff, fmt.Errorf("unlink stale task: %w", err)
+			}
+			diff.Removed++
 		}
 	}
 
@@ -379,39 +398,70 @@ func (ds *DatabaseService) SyncFolderTasks(folderID int, tasks []models.Task) er
 		SET content = ?2,
 		    completed = ?3,
 		    line_number = ?4,
-		    last_updated = CASE WHEN content != ?2 OR completed != ?3 THEN ?5 ELSE last_updated END
+		    last_updated = CASE WHEN content != ?2 OR completed != ?3 THEN ?5 ELSE last_updated END,
+		    due_date = ?7,
+		    note_id = ?8,
+		    char_offset = ?9
 		WHERE folder_id = ?1 AND task_hash = ?6`)
 	if err != nil {
-		return fmt.Errorf("prepare update: %w", err)
+		return diff, fmt.Errorf("prepare update: %w", err)
 	}
 	defer updateStmt.Close()
 
 	insertStmt, err := tx.Prepare(`
-		INSERT INTO tasks (folder_id, file_path, line_number, content, completed, last_updated, task_hash)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+		INSERT INTO tasks (folder_id, file_path, line_number, content, completed, last_updated, task_hash, due_date, note_id, char_offset)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
-		return fmt.Errorf("prepare insert: %w", err)
+		return diff, fmt.Errorf("prepare insert: %w", err)
 	}
 	defer insertStmt.Close()
 
 	for i, task := range tasks {
 		h := hashes[i]
-		if existing[h] {
-			if _, err := updateStmt.Exec(folderID, task.Text, task.Checked, i, now, h); err != nil {
-				return fmt.Errorf("update task %s: %w", h, err)
+		var due sql.NullString
+		if v := models.FormatDueValue(task.DueDate); v != "" {
+			due = sql.NullString{String: v, Valid: true}
+		}
+		var noteID sql.NullString
+		if task.NoteID != "" {
+			noteID = sql.NullString{String: task.NoteID, Valid: true}
+		}
+		if old, ok := existing[h]; ok {
+			if _, err := updateStmt.Exec(folderID, task.Text, task.Checked, task.Line, now, h, due, noteID, task.Offset); err != nil {
+				return diff, fmt.Errorf("update task %s: %w", h, err)
+			}
+			event := ""
+			switch change := (TaskChange{ID: old.id, Content: task.Text}); {
+			case task.Checked && !old.completed:
+				diff.Completed = append(diff.Completed, change)
+				event = TaskEventCompleted
+			case !task.Checked && old.completed:
+				diff.Reopened = append(diff.Reopened, change)
+				event = TaskEventReopened
+			}
+			if event != "" {
+				if err := recordTaskEvent(tx, folderID, h, task.Text, event, task.Checked, now); err != nil {
+					return diff, err
+				}
 			}
 		} else {
-			if _, err := insertStmt.Exec(folderID, "notes.md", i, task.Text, task.Checked, now, h); err != nil {
-				return fmt.Errorf("insert task %s: %w", h, err)
+			result, err := insertStmt.Exec(folderID, "notes.md", task.Line, task.Text, task.Checked, now, h, due, noteID, task.Offset)
+			if err != nil {
+				return diff, fmt.Errorf("insert task %s: %w", h, err)
+			}
+			id, _ := result.LastInsertId()
+			diff.Added = append(diff.Added, TaskChange{ID: int(id), Content: task.Text})
+			if err := recordTaskEvent(tx, folderID, h, task.Text, TaskEventAdded, task.Checked, now); err != nil {
+				return diff, err
 			}
 		}
 	}
 
 	if _, err := tx.Exec(`UPDATE folders SET last_scan = ? WHERE id = ?`, now, folderID); err != nil {
-		return fmt.Errorf("update folder scan time: %w", err)
+		return diff, fmt.Errorf("update folder scan time: %w", err)
 	}
 
-	return tx.Commit()
+	return diff, tx.Commit()
 }
 
 // TaskHashFromText is exported so callers (CLI, future UI surfaces) can look
@@ -428,64 +478,26 @@ func TaskHashFromText(text string) string {
 
 // GetGlobalTasks retrieves all tasks across all active folders
 func (ds *DatabaseService) GetGlobalTasks() (*models.GlobalTasksResponse, error) {
-	// Get tasks with folder information
-	rows, err := ds.db.Query(`
-		SELECT t.id, t.folder_id, t.file_path, t.line_number, t.content, 
-			   t.completed, t.last_updated, f.path
-		FROM tasks t
-		JOIN folders f ON t.folder_id = f.id
-		WHERE f.active = 1
-		ORDER BY f.path, t.completed, t.last_updated DESC`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query tasks: %w", err)
-	}
-	defer rows.Close()
-
-	var tasks []models.GlobalTask
-	for rows.Next() {
-		var task models.GlobalTask
-		var lastUpdated string
-		err := rows.Scan(
-			&task.ID, &task.FolderID, &task.FilePath, &task.LineNumber,
-			&task.Content, &task.Completed, &lastUpdated, &task.FolderPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan task: %w", err)
-		}
-		// Parse timestamp
-		if t, err := time.Parse("2006-01-02 15:04:05.000000-07:00", lastUpdated); err == nil {
-			task.LastUpdated = t
-		} else if t, err := time.Parse("2006-01-02 15:04:05", lastUpdated); err == nil {
-			task.LastUpdated = t
-		}
-		tasks = append(tasks, task)
-	}
-
-	// Get summaries per folder
-	summaries, err := ds.getTaskSummaries()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get task summaries: %w", err)
-	}
-
-	return &models.GlobalTasksResponse{
-		Tasks:     tasks,
-		Summaries: summaries,
-		Total:     len(tasks),
-	}, nil
+	return ds.QueryTasks(TaskFilter{})
 }
 
+// folderOrder lists folders f by group, ungrouped first, then by alias
+// or, without one, path.
+const folderOrder = "COALESCE(f.group_name, ''), COALESCE(NULLIF(f.alias, ''), f.path), f.path"
+
 // getTaskSummaries generates task summaries grouped by folder
 func (ds *DatabaseService) getTaskSummaries() ([]models.TaskSummary, error) {
 	rows, err := ds.db.Query(`
-		SELECT f.path,
+		SELECT f.id, f.path, COALESCE(f.alias, ''), COALESCE(f.group_name, ''),
 			   COUNT(t.id) as total_tasks,
 			   SUM(CASE WHEN t.completed = 1 THEN 1 ELSE 0 END) as completed_tasks,
 			   SUM(CASE WHEN t.completed = 0 THEN 1 ELSE 0 END) as pending_tasks,
 			   MAX(t.last_updated) as last_updated
 		FROM folders f
 		LEFT JOIN tasks t ON f.id = t.folder_id
-		WHERE f.active = 1
+		WHERE f.active = 1 AND `+ownerCond+`
 		GROUP BY f.id, f.path
-		ORDER BY f.path`)
+		ORDER BY `+folderOrder, ds.user)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query task summaries: %w", err)
 	}
@@ -496,11 +508,12 @@ func (ds *DatabaseService) getTaskSummaries() ([]models.TaskSummary, error) {
 		var summary models.TaskSummary
 		var lastUpdated sql.NullString
 		err := rows.Scan(
-			&summary.FolderPath, &summary.TotalTasks,
+			&summary.FolderID, &summary.FolderPath, &summary.FolderName, &summary.FolderGroup, &summary.TotalTasks,
 			&summary.CompletedTasks, &summary.PendingTasks, &lastUpdated)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan summary: %w", err)
 		}
+		summary.FolderName = models.FolderName(summary.FolderPath, summary.FolderName)
 		if lastUpdated.Valid {
 			// Try to parse the timestamp string
 			if t, err := time.Parse("2006-01-02 15:04:05.000000-07:00", lastUpdated.String); err == nil {
@@ -515,12 +528,51 @@ func (ds *DatabaseService) getTaskSummaries() ([]models.TaskSummary, error) {
 	return summaries, nil
 }
 
+// groupSummaries totals summaries, which are in folderOrder, by group.
+func groupSummaries(summaries []models.TaskSummary) []models.TaskGroupSummary {
+	groups := []models.TaskGroupSummary{}
+	for _, s := range summaries {
+		if len(groups) == 0 || groups[len(groups)-1].Group != s.FolderGroup {
+			groups = append(groups, models.TaskGroupSummary{Group: s.FolderGroup})
+		}
+		g := &groups[len(groups)-1]
+		g.Folders = append(g.Folders, s.FolderName)
+		g.TotalTasks += s.TotalTasks
+		g.CompletedTasks += s.CompletedTasks
+		g.PendingTasks += s.PendingTasks
+	}
+	return groups
+}
+
+// maxFolderLabel caps the length of a folder's alias and group.
+const maxFolderLabel = 100
+
+// SetFolderLabels sets the alias and group of one of the service's user's
+// folders; empty clears them.
+func (ds *DatabaseService) SetFolderLabels(folderID int, alias, group string) (*models.FolderRegistry, error) {
+	alias, group = strings.TrimSpace(alias), strings.TrimSpace(group)
+	if len(alias) > maxFolderLabel || len(group) > maxFolderLabel {
+		return nil, fmt.Errorf("alias and group are limited to %d bytes", maxFolderLabel)
+	}
+	res, err := ds.db.Exec(`UPDATE folders SET alias = NULLIF(?, ''), group_name = NULLIF(?, '')
+		WHERE id = ? AND id IN (SELECT f.id FROM folders f WHERE `+ownerCond+`)`,
+		alias, group, folderID, ds.user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to label folder: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return ds.GetFolderByID(folderID)
+}
+
 // UpdateTaskCompletion updates the completion status of a specific task
 func (ds *DatabaseService) UpdateTaskCompletion(taskID int, completed bool) error {
 	_, err := ds.db.Exec(`
 		UPDATE tasks 
 		SET completed = ?, last_updated = ? 
-		WHERE id = ?`, completed, time.Now(), taskID)
+		WHERE id = ? AND folder_id IN (SELECT f.id FROM folders f WHERE `+ownerCond+`)`,
+		completed, time.Now(), taskID, ds.user)
 	if err != nil {
 		return fmt.Errorf("failed to update task completion: %w", err)
 	}
@@ -529,11 +581,17 @@ func (ds *DatabaseService) UpdateTaskCompletion(taskID int, completed bool) erro
 
 // GetActiveFolders returns all active registered folders
 func (ds *DatabaseService) GetActiveFolders() ([]models.FolderRegistry, error) {
+	return ds.GetFolders(false)
+}
+
+// GetFolders returns the registered folders, active ones first, with the
+// deactivated ones too when includeInactive is set.
+func (ds *DatabaseService) GetFolders(includeInactive bool) ([]models.FolderRegistry, error) {
 	rows, err := ds.db.Query(`
-		SELECT id, path, last_scan, active 
-		FROM folders 
-		WHERE active = 1 
-		ORDER BY path`)
+		SELECT id, path, last_scan, active, COALESCE(alias, ''), COALESCE(group_name, '')
+		FROM folders f
+		WHERE (active = 1 OR ?) AND `+ownerCond+`
+		ORDER BY active DESC, `+folderOrder, includeInactive, ds.user)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query folders: %w", err)
 	}
@@ -542,7 +600,7 @@ func (ds *DatabaseService) GetActiveFolders() ([]models.FolderRegistry, error) {
 	var folders []models.FolderRegistry
 	for rows.Next() {
 		var folder models.FolderRegistry
-		err := rows.Scan(&folder.ID, &folder.Path, &folder.LastScan, &folder.Active)
+		err := rows.Scan(&folder.ID, &folder.Path, &folder.LastScan, &folder.Active, &folder.Alias, &folder.Group)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan folder: %w", err)
 		}
@@ -568,6 +626,14 @@ func (ds *DatabaseService) SoftRemoveFolder(folderID int) error {
 	}
 	defer tx.Rollback()
 
+	// The tasks leave the history too, so reactivating the folder, which
+	// adds them back, doesn't count them twice.
+	if _, err := tx.Exec(`
+		INSERT INTO task_history (folder_id, task_hash, content, event, completed, at)
+		SELECT folder_id, task_hash, content, ?, completed, ? FROM tasks WHERE folder_id = ? AND task_hash IS NOT NULL`,
+		TaskEventRemoved, time.Now(), folderID); err != nil {
+		return fmt.Errorf("record removed tasks for folder %d: %w", folderID, err)
+	}
 	if _, err := tx.Exec("DELETE FROM tasks WHERE folder_id = ?", folderID); err != nil {
 		return fmt.Errorf("clear tasks for folder %d: %w", folderID, err)
 	}
@@ -578,18 +644,19 @@ func (ds *DatabaseService) SoftRemoveFolder(folderID int) error {
 }
 
 // GetFolderByID returns a single folder regardless of active state, or
-// sql.ErrNoRows if none exists. Used by the per-folder sync/forget paths
-// so they can validate the ID before doing work.
+// sql.ErrNoRows if none exists or it belongs to another user. Used by the
+// per-folder sync/forget paths so they can validate the ID before doing
+// work.
 func (ds *DatabaseService) GetFolderByID(folderID int) (*models.FolderRegistry, error) {
-	var f models.FolderRegistry
+	var folder models.FolderRegistry
 	err := ds.db.QueryRow(
-		`SELECT id, path, last_scan, active FROM folders WHERE id = ?`,
-		folderID,
-	).Scan(&f.ID, &f.Path, &f.LastScan, &f.Active)
+		`SELECT id, path, last_scan, active, COALESCE(alias, ''), COALESCE(group_name, '') FROM folders f WHERE id = ? AND `+ownerCond,
+		folderID, ds.user,
+	).Scan(&folder.ID, &folder.Path, &folder.LastScan, &folder.Active, &folder.Alias, &folder.Group)
 	if err != nil {
 		return nil, err
 	}
-	return &f, nil
+	return &folder, nil
 }
 
 // RemoveFolder removes a folder and all its associated tasks from the database
@@ -600,11 +667,17 @@ func (ds *DatabaseService) RemoveFolder(folderID int) error {
 	}
 	defer tx.Rollback()
 
-	// Delete all tasks for this folder
+	// Delete all tasks for this folder, and their history
 	_, err = tx.Exec("DELETE FROM tasks WHERE folder_id = ?", folderID)
 	if err != nil {
 		return fmt.Errorf("failed to delete tasks for folder %d: %w", folderID, err)
 	}
+	if _, err := tx.Exec("DELETE FROM task_history WHERE folder_id = ?", folderID); err != nil {
+		return fmt.Errorf("failed to delete task history for folder %d: %w", folderID, err)
+	}
+	if _, err := tx.Exec("DELETE FROM task_links WHERE folder_id = ?", folderID); err != nil {
+		return fmt.Errorf("failed to delete task links for folder %d: %w", folderID, err)
+	}
 
 	// Delete the folder record
 	_, err = tx.Exec("DELETE FROM folders WHERE id = ?", folderID)
@@ -130,6 +130,48 @@ func TestSyncFolderTasks_EditingOneDoesNotDisturbOthers(t *testing.T) {
 	}
 }
 
+func TestSyncFolderTasksDiff_ReportsChanges(t *testing.T) {
+	svc, folder := newTestDB(t)
+	tasks := []models.Task{
+		{Text: "- [ ] keep"},
+		{Text: "- [ ] finish"},
+		{Text: "- [ ] drop"},
+	}
+	diff, err := svc.SyncFolderTasksDiff(folder.ID, tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Added) != 3 || diff.Added[0].ID == 0 || diff.Added[0].Content != "- [ ] keep" {
+		t.Errorf("first sync: %+v", diff)
+	}
+	if diff, _ := svc.SyncFolderTasksDiff(folder.ID, tasks); !diff.Empty() {
+		t.Errorf("unchanged sync reported %+v", diff)
+	}
+
+	ids := idMap(t, svc, folder.ID)
+	tasks = []models.Task{
+		{Text: "- [ ] keep"},
+		{Text: "- [x] finish", Checked: true},
+		{Text: "- [ ] new"},
+	}
+	diff, err = svc.SyncFolderTasksDiff(folder.ID, tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	finished := TaskChange{ID: ids[TaskHashFromText("- [ ] finish")], Content: "- [x] finish"}
+	if len(diff.Completed) != 1 || diff.Completed[0] != finished {
+		t.Errorf("Completed = %+v, want %+v", diff.Completed, finished)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Content != "- [ ] new" || diff.Removed != 1 || len(diff.Reopened) != 0 {
+		t.Errorf("second sync: %+v", diff)
+	}
+
+	tasks[1] = models.Task{Text: "- [ ] finish"}
+	if diff, _ := svc.SyncFolderTasksDiff(folder.ID, tasks); len(diff.Reopened) != 1 || diff.Reopened[0].ID != finished.ID {
+		t.Errorf("reopen sync: %+v", diff)
+	}
+}
+
 func TestSyncFolderTasks_RemovingOnlyDeletesThatRow(t *testing.T) {
 	svc, folder := newTestDB(t)
 	tasks := []models.Task{
@@ -218,7 +260,12 @@ func TestAddColumnIfMissing_Idempotent(t *testing.T) {
 	// Running migrate twice (simulated by calling addColumnIfMissing again)
 	// must not error.
 	svc, _ := newTestDB(t)
-	if err := svc.addColumnIfMissing("tasks", "task_hash", "TEXT"); err != nil {
+	tx, err := svc.db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if err := addColumnIfMissing(tx, "tasks", "task_hash", "TEXT"); err != nil {
 		t.Errorf("re-adding task_hash errored: %v", err)
 	}
 }
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/mailer"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// digestCheckInterval is how often the background loop asks "is a digest
+// due?". The send hour is coarse, so a few minutes' lag is fine.
+const digestCheckInterval = 5 * time.Minute
+
+// Digest is the open work across all registered folders, bucketed the way
+// the email presents it. A task appears in exactly one bucket.
+type Digest struct {
+	Overdue []models.GlobalTask `json:"overdue"`
+	DueSoon []models.GlobalTask `json:"due_soon"`
+	Open    []models.GlobalTask `json:"open"` // no due date, or due later
+}
+
+// BuildDigest buckets the open tasks: due before today is overdue, due
+// within the next dueSoonDays days (today included) is due soon, and the
+// rest is open.
+func BuildDigest(tasks []models.GlobalTask, now time.Time, dueSoonDays int) *Digest {
+	d := &Digest{Overdue: OverdueTasks(tasks, now)}
+	overdue := make(map[int]bool, len(d.Overdue))
+	for _, t := range d.Overdue {
+		overdue[t.ID] = true
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	horizon := today.AddDate(0, 0, dueSoonDays)
+	for _, t := range tasks {
+		if t.Completed || overdue[t.ID] {
+			continue
+		}
+		_, due, _ := models.ParseTaskMetadata(t.Content)
+		day := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, now.Location())
+		if !due.IsZero() && !day.After(horizon) {
+			d.DueSoon = append(d.DueSoon, t)
+		} else {
+			d.Open = append(d.Open, t)
+		}
+	}
+	return d
+}
+
+// Empty reports whether there is nothing open at all.
+func (d *Digest) Empty() bool {
+	return len(d.Overdue)+len(d.DueSoon)+len(d.Open) == 0
+}
+
+// Subject is the email subject line.
+func (d *Digest) Subject() string {
+	return fmt.Sprintf("NoteFlow digest: %d overdue, %d due soon, %d open",
+		len(d.Overdue), len(d.DueSoon), len(d.Open))
+}
+
+func (d *Digest) sections() []struct {
+	title string
+	tasks []models.GlobalTask
+} {
+	return []struct {
+		title string
+		tasks []models.GlobalTask
+	}{{"Overdue", d.Overdue}, {"Due soon", d.DueSoon}, {"Open", d.Open}}
+}
+
+// digestLine is how one task reads in the digest: its text without the
+// checkbox or tokens, the due date, and which folder it lives in.
+func digestLine(t models.GlobalTask) (text, due, folder string) {
+	text = stripTaskCheckbox(models.CleanTaskText(t.Content))
+	if _, d, _ := models.ParseTaskMetadata(t.Content); !d.IsZero() {
+		due = d.Format("Mon Jan 2")
+	}
+	return text, due, filepath.Base(t.FolderPath)
+}
+
+// Text renders the plain-text body.
+func (d *Digest) Text() string {
+	var b strings.Builder
+	for _, s := range d.sections() {
+		if len(s.tasks) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s (%d)\n", s.title, len(s.tasks))
+		for _, t := range s.tasks {
+			text, due, folder := digestLine(t)
+			if due != "" {
+				fmt.Fprintf(&b, "  - %s (due %s) [%s]\n", text, due, folder)
+			} else {
+				fmt.Fprintf(&b, "  - %s [%s]\n", text, folder)
+			}
+		}
+		b.WriteString("\n")
+	}
+	if b.Len() == 0 {
+		return "Nothing open. Enjoy the day.\n"
+	}
+	return b.String()
+}
+
+// HTML renders the HTML alternative body.
+func (d *Digest) HTML() string {
+	var b strings.Builder
+	b.WriteString(`<div style="font-family: sans-serif; font-size: 14px;">`)
+	for _, s := range d.sections() {
+		if len(s.tasks) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "<h3>%s (%d)</h3><ul>", s.title, len(s.tasks))
+		for _, t := range s.tasks {
+			text, due, folder := digestLine(t)
+			b.WriteString("<li>" + html.EscapeString(text))
+			if due != "" {
+				b.WriteString(" <strong>due " + html.EscapeString(due) + "</strong>")
+			}
+			b.WriteString(` <span style="color: #888;">` + html.EscapeString(folder) + "</span></li>")
+		}
+		b.WriteString("</ul>")
+	}
+	if d.Empty() {
+		b.WriteString("<p>Nothing open. Enjoy the day.</p>")
+	}
+	b.WriteString("</div>")
+	return b.String()
+}
+
+// DigestService emails the Digest on the configured schedule.
+type DigestService struct {
+	cfg       models.DigestConfig
+	tasks     func() ([]models.GlobalTask, error)
+	sender    mailer.Sender
+	statePath string
+	mu        sync.Mutex // serializes sends
+	stop      chan struct{}
+}
+
+// NewDigestService creates the service. The time of the last digest is
+// kept in stateDir (normally ~/.config/noteflow) rather than in memory:
+// every open folder runs its own NoteFlow process, and they must not each
+// send a copy.
+func NewDigestService(registry *TaskRegistryService, cfg models.DigestConfig, stateDir string) *DigestService {
+	return &DigestService{
+		cfg: cfg,
+		tasks: func() ([]models.GlobalTask, error) {
+			global, err := registry.GetGlobalTasks()
+			if err != nil {
+				return nil, err
+			}
+			return global.Tasks, nil
+		},
+		sender:    mailer.NewSMTP(cfg.SMTP),
+		statePath: filepath.Join(stateDir, "digest-last-sent"),
+	}
+}
+
+// Enabled reports whether a recipient and mail server are configured.
+func (s *DigestService) Enabled() bool {
+	return s.cfg.Enabled()
+}
+
+// Start checks for a due digest immediately and then periodically until
+// Stop is called.
+func (s *DigestService) Start() {
+	if !s.Enabled() || s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	go func(stop <-chan struct{}) {
+		ticker := time.NewTicker(digestCheckInterval)
+		defer ticker.Stop()
+		for {
+			if err := s.sendIfDue(time.Now()); err != nil {
+				log.Printf("Warning: task digest failed: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}(s.stop)
+}
+
+// Stop stops the background loop started by Start.
+func (s *DigestService) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// Send builds and emails the digest now, regardless of schedule, and
+// returns what was sent.
+func (s *DigestService) Send(ctx context.Context) (*Digest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, err := s.build(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return d, s.send(ctx, d, time.Now())
+}
+
+// sendIfDue sends the scheduled digest once per period. A digest with
+// nothing in it is skipped (but still counts as sent) so an empty week
+// doesn't produce an empty email.
+func (s *DigestService) sendIfDue(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.due(now, s.lastSent()) {
+		return nil
+	}
+	d, err := s.build(now)
+	if err != nil {
+		return err
+	}
+	if d.Empty() {
+		return s.recordSent(now)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	return s.send(ctx, d, now)
+}
+
+// due reports whether the digest scheduled for now's day is owed: the send
+// hour has passed, it is the right weekday for weekly digests, and nothing
+// has gone out since the scheduled time.
+func (s *DigestService) due(now, lastSent time.Time) bool {
+	if s.cfg.Weekly() && now.Weekday() != s.cfg.SendWeekday() {
+		return false
+	}
+	scheduled := time.Date(now.Year(), now.Month(), now.Day(), s.cfg.SendHour(), 0, 0, 0, now.Location())
+	return !now.Before(scheduled) && lastSent.Before(scheduled)
+}
+
+func (s *DigestService) build(now time.Time) (*Digest, error) {
+	tasks, err := s.tasks()
+	if err != nil {
+		return nil, fmt.Errorf("load tasks: %w", err)
+	}
+	return BuildDigest(tasks, now, s.cfg.DueSoonWindow()), nil
+}
+
+func (s *DigestService) send(ctx context.Context, d *Digest, now time.Time) error {
+	err := s.sender.Send(ctx, mailer.Message{
+		From:    s.cfg.From,
+		To:      s.cfg.To,
+		Subject: d.Subject(),
+		Text:    d.Text(),
+		HTML:    d.HTML(),
+	})
+	if err != nil {
+		return err
+	}
+	return s.recordSent(now)
+}
+
+func (s *DigestService) lastSent() time.Time {
+	data, err := os.ReadFile(s.statePath)
+	if err != nil {
+		return time.Time{}
+	}
+	t, _ := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	return t
+}
+
+func (s *DigestService) recordSent(now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(s.statePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.statePath, []byte(now.Format(time.RFC3339)+"\n"), 0644)
+}
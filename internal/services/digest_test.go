@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/mailer"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+type fakeMailer struct{ sent []mailer.Message }
+
+func (f *fakeMailer) Send(_ context.Context, msg mailer.Message) error {
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+func TestBuildDigest(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	tasks := []models.GlobalTask{
+		{ID: 1, Content: "- [ ] file taxes @2026-10-10", FolderPath: "/home/me/admin"},
+		{ID: 2, Content: "- [ ] book flights @2026-10-18", FolderPath: "/home/me/travel"},
+		{ID: 3, Content: "- [ ] plan offsite @2026-11-30", FolderPath: "/home/me/work"},
+		{ID: 4, Content: "- [ ] read paper", FolderPath: "/home/me/work"},
+		{ID: 5, Content: "- [x] old thing @2026-10-01", Completed: true, FolderPath: "/home/me/work"},
+	}
+	d := BuildDigest(tasks, now, 3)
+	if len(d.Overdue) != 1 || d.Overdue[0].ID != 1 || len(d.DueSoon) != 1 || d.DueSoon[0].ID != 2 || len(d.Open) != 2 {
+		t.Fatalf("digest = %+v", d)
+	}
+	text := d.Text()
+	for _, want := range []string{"Overdue (1)", "  - file taxes (due Sat Oct 10) [admin]", "  - read paper [work]"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text missing %q:\n%s", want, text)
+		}
+	}
+	if d.Subject() != "NoteFlow digest: 1 overdue, 1 due soon, 2 open" {
+		t.Errorf("subject = %q", d.Subject())
+	}
+}
+
+func TestDigestService_SendsOncePerPeriod(t *testing.T) {
+	hour := 7
+	fake := &fakeMailer{}
+	s := &DigestService{
+		cfg: models.DigestConfig{To: []string{"me@example.com"}, Schedule: "weekly", Weekday: "fri", Hour: &hour},
+		tasks: func() ([]models.GlobalTask, error) {
+			return []models.GlobalTask{{ID: 1, Content: "- [ ] call mom"}}, nil
+		},
+		sender:    fake,
+		statePath: filepath.Join(t.TempDir(), "digest-last-sent"),
+	}
+
+	thursday := time.Date(2026, 10, 15, 9, 0, 0, 0, time.Local)
+	friday := thursday.AddDate(0, 0, 1)
+	steps := []struct {
+		now  time.Time
+		sent int
+	}{
+		{thursday, 0},                   // wrong weekday
+		{friday.Add(-3 * time.Hour), 0}, // before the send hour
+		{friday, 1},                     // due
+		{friday.Add(time.Hour), 1},      // already sent this week
+		{friday.AddDate(0, 0, 7), 2},    // next week
+	}
+	for _, st := range steps {
+		if err := s.sendIfDue(st.now); err != nil {
+			t.Fatal(err)
+		}
+		if len(fake.sent) != st.sent {
+			t.Fatalf("at %v: sent %d, want %d", st.now, len(fake.sent), st.sent)
+		}
+	}
+	if msg := fake.sent[0]; msg.To[0] != "me@example.com" || !strings.Contains(msg.Text, "call mom") || msg.HTML == "" {
+		t.Errorf("message = %+v", msg)
+	}
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+)
+
+// DefaultDiscoverIgnore are the directories FindNotesFolders never
+// enters besides hidden ones: dependency trees, build output and caches,
+// which are large and hold no notes of their own.
+var DefaultDiscoverIgnore = []string{"node_modules", "vendor", "target", "dist", "build", "__pycache__", "venv", "Library"}
+
+// DiscoverResult reports what a scan for notes folders found.
+type DiscoverResult struct {
+	Root     string            `json:"root"`
+	DryRun   bool              `json:"dry_run,omitempty"`
+	Found    []string          `json:"found"`            // folders with notes, sorted
+	Added    []string          `json:"added"`            // registered by this scan
+	Existing []string          `json:"existing"`         // registered before
+	Failed   map[string]string `json:"failed,omitempty"` // folder -> why it wasn't registered
+}
+
+// ValidateIgnorePatterns checks every pattern is well-formed, so a typo
+// fails instead of silently ignoring nothing.
+func ValidateIgnorePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// FindNotesFolders walks the tree under root and returns, sorted, every
+// directory holding notes (see storage.HasNotes), root included. Hidden
+// directories, DefaultDiscoverIgnore and directories matching ignore are
+// skipped, as patterns are matched by the export's --exclude: paths
+// relative to root with * and ? wildcards, and names for those without a
+// slash. The assets tree of a folder found is not searched, nor are
+// symlinked directories.
+func FindNotesFolders(root string, ignore []string) ([]string, error) {
+	if err := ValidateIgnorePatterns(ignore); err != nil {
+		return nil, err
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolve root: %w", err)
+	}
+	if info, err := os.Stat(root); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", root)
+	}
+	patterns := append(append([]string{}, DefaultDiscoverIgnore...), ignore...)
+
+	var found []string
+	err = filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			// An unreadable directory is skipped, not the whole scan.
+			if d != nil && d.IsDir() && p != root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if p != root {
+			rel, _ := filepath.Rel(root, p)
+			if strings.HasPrefix(d.Name(), ".") || matchAnyPattern(patterns, filepath.ToSlash(rel)) {
+				return filepath.SkipDir
+			}
+			if d.Name() == "assets" && storage.HasNotes(filepath.Dir(p)) {
+				return filepath.SkipDir
+			}
+		}
+		if storage.HasNotes(p) {
+			found = append(found, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(found)
+	return found, nil
+}
+
+// DiscoverFolders registers every notes folder under root, found by
+// FindNotesFolders, that isn't registered yet, and syncs its tasks. With
+// dryRun it only reports what it would do. A user's registry scans only
+// inside their notes root, and by default all of it.
+func (trs *TaskRegistryService) DiscoverFolders(root string, ignore []string, dryRun bool) (*DiscoverResult, error) {
+	if root == "" {
+		root = trs.root
+	}
+	if root == "" {
+		return nil, fmt.Errorf("root is required")
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolve root: %w", err)
+	}
+	if err := trs.checkInsideRoot(abs); err != nil {
+		return nil, err
+	}
+	found, err := FindNotesFolders(abs, ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	registered := map[string]bool{}
+	folders, err := trs.db.GetActiveFolders()
+	if err != nil {
+		return nil, err
+	}
+	for _, folder := range folders {
+		registered[folder.Path] = true
+	}
+
+	result := &DiscoverResult{Root: abs, DryRun: dryRun, Found: found, Added: []string{}, Existing: []string{}}
+	for _, folder := range found {
+		switch {
+		case registered[folder]:
+			result.Existing = append(result.Existing, folder)
+		case dryRun:
+			result.Added = append(result.Added, folder)
+		default:
+			if _, err := trs.AddFolderByPath(folder); err != nil {
+				if result.Failed == nil {
+					result.Failed = map[string]string{}
+				}
+				result.Failed[folder] = err.Error()
+				continue
+			}
+			result.Added = append(result.Added, folder)
+		}
+	}
+	if !dryRun {
+		log.Printf("Discovered %d notes folder(s) under %s, %d new", len(found), abs, len(result.Added))
+	}
+	return result, nil
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindNotesFolders(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{
+		"",                        // root itself
+		"work/api",                // nested project
+		"work/api/assets/sites/x", // inside a project's assets: skipped
+		"work/old/legacy",         // ignored by pattern
+		"web/node_modules/pkg",    // default ignore
+		".hidden/notes",           // hidden
+		"personal",                // found
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		os.WriteFile(filepath.Join(root, dir, "notes.md"), []byte("## 2026-05-12 09:00:00\n\nhi\n"), 0644)
+	}
+	os.MkdirAll(filepath.Join(root, "empty"), 0755)
+
+	found, err := FindNotesFolders(root, []string{"work/old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{root, filepath.Join(root, "personal"), filepath.Join(root, "work", "api")}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("FindNotesFolders =\n%v\nwant\n%v", found, want)
+	}
+	if _, err := FindNotesFolders(root, []string{"["}); err == nil {
+		t.Error("a malformed pattern was accepted")
+	}
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+)
+
+// Checks a DoctorIssue can come from.
+const (
+	DoctorStructure  = "structure"  // notes.md text the parser drops or misreads
+	DoctorSeparators = "separators" // duplicate, missing or mangled <!-- note --> lines
+	DoctorAssets     = "assets"     // uploads no note links to, or links to missing files
+	DoctorArchives   = "archives"   // archive links whose file is gone
+	DoctorTasks      = "tasks"      // the task DB disagreeing with notes.md
+)
+
+// DoctorIssue is one problem found by Doctor.
+type DoctorIssue struct {
+	Check   string `json:"check"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"` // 1-based line in notes.md, if any
+	Fixable bool   `json:"fixable"`
+	Fixed   bool   `json:"fixed"`
+}
+
+// DoctorReport is the outcome of Doctor for one folder.
+type DoctorReport struct {
+	Path   string        `json:"path"`
+	Notes  int           `json:"notes"`
+	Issues []DoctorIssue `json:"issues"`
+}
+
+// docHeaderRE matches a note header line as NoteFlow writes it.
+var docHeaderRE = regexp.MustCompile(`^## \d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(?: - .*)?$`)
+
+// assetRefRE finds links into the assets tree, with or without the
+// leading slash.
+var assetRefRE = regexp.MustCompile(`assets/((?:images|files|sites)/[^\s()"'<>\[\]]+)`)
+
+const separatorLine = "<!-- note -->"
+
+// Doctor checks the notes folder at basePath: the structure of notes.md,
+// its note separators, the assets tree against the links in notes.md and
+// trash.md, and, when db is not nil, the task DB against the tasks in the
+// file. With fix, separator problems are repaired in notes.md (no text is
+// dropped) and the folder's tasks are re-synced; issues that need a
+// person, such as orphaned files, are only reported.
+func Doctor(basePath string, db *DatabaseService, fix bool) (*DoctorReport, error) {
+	abs, err := filepath.Abs(basePath)
+	if err != nil {
+		return nil, err
+	}
+	report := &DoctorReport{Path: abs, Issues: []DoctorIssue{}}
+
+	if !storage.HasNotes(abs) {
+		report.Issues = append(report.Issues, DoctorIssue{Check: DoctorStructure, Message: "no notes.md in this folder"})
+		return report, nil
+	}
+	// In the SQLite mode this is the notes rendered, which pass the text
+	// checks; the rest apply as they do to notes.md.
+	data, err := storage.NewFileStorage(abs).ReadNotesFile()
+	if err != nil {
+		return nil, err
+	}
+
+	issues, repaired := checkNotesText(string(data))
+	fixable := false
+	for _, issue := range issues {
+		fixable = fixable || issue.Fixable
+	}
+	if fix && fixable {
+		if err := storage.NewFileStorage(abs).WriteNotesFile([]byte(repaired)); err != nil {
+			return nil, fmt.Errorf("repair notes.md: %w", err)
+		}
+		for i := range issues {
+			issues[i].Fixed = issues[i].Fixable
+		}
+		data = []byte(repaired)
+	}
+	report.Issues = append(report.Issues, issues...)
+
+	manager, err := NewNoteManager(abs)
+	if err != nil {
+		return nil, err
+	}
+	report.Notes = len(manager.GetAllNotes())
+
+	trash, _ := os.ReadFile(filepath.Join(abs, models.TrashFile))
+	report.Issues = append(report.Issues, checkAssets(abs, string(data)+"\n"+string(trash))...)
+
+	if db != nil {
+		taskIssues, err := checkTaskRegistry(abs, db, manager, fix)
+		if err != nil {
+			return nil, err
+		}
+		report.Issues = append(report.Issues, taskIssues...)
+	}
+	return report, nil
+}
+
+// checkNotesText finds structural problems in the text of notes.md and
+// returns them with the text repaired: line endings normalized, every
+// separator on a line of its own, no empty notes, and a separator before
+// every note header. Text outside any note is kept where it is.
+func checkNotesText(content string) ([]DoctorIssue, string) {
+	var issues []DoctorIssue
+	if strings.Contains(content, "\r\n") {
+		issues = append(issues, DoctorIssue{Check: DoctorSeparators, Fixable: true,
+			Message: "Windows line endings: separators aren't recognized, so notes run together"})
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+
+	// Split into chunks at every separator line, as the parser would if
+	// each were written exactly as NoteFlow writes them.
+	type chunk struct {
+		line  int // 1-based line of the chunk's first line
+		lines []string
+	}
+	lines := strings.Split(content, "\n")
+	chunks := []chunk{{line: 1}}
+	for i, line := range lines {
+		if strings.TrimSpace(line) != separatorLine {
+			chunks[len(chunks)-1].lines = append(chunks[len(chunks)-1].lines, line)
+			continue
+		}
+		switch {
+		case line != separatorLine:
+			issues = append(issues, DoctorIssue{Check: DoctorSeparators, Line: i + 1, Fixable: true,
+				Message: "separator has extra whitespace and isn't recognized"})
+		case i == 0:
+			issues = append(issues, DoctorIssue{Check: DoctorSeparators, Line: i + 1, Fixable: true,
+				Message: "separator on the first line: the first note is dropped on load"})
+		case i == len(lines)-1:
+			issues = append(issues, DoctorIssue{Check: DoctorSeparators, Line: i + 1, Fixable: true,
+				Message: "separator on the last line without a newline"})
+		}
+		chunks = append(chunks, chunk{line: i + 2})
+	}
+
+	var out []string
+	for n, c := range chunks {
+		text := strings.TrimSpace(strings.Join(c.lines, "\n"))
+		if text == "" {
+			if n > 0 && n < len(chunks)-1 {
+				issues = append(issues, DoctorIssue{Check: DoctorSeparators, Line: c.line - 1, Fixable: true,
+					Message: "duplicate separator (empty note)"})
+			}
+			continue
+		}
+
+		// Split again before every header that lacks a separator,
+		// skipping fenced code. Each part starts at a line of c.
+		starts, fenced := []int{0}, false
+		for i, line := range c.lines {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+				fenced = !fenced
+			}
+			prev := starts[len(starts)-1]
+			if fenced || !docHeaderRE.MatchString(line) || strings.TrimSpace(strings.Join(c.lines[prev:i], "\n")) == "" {
+				continue
+			}
+			issues = append(issues, DoctorIssue{Check: DoctorSeparators, Line: c.line + i, Fixable: true,
+				Message: "missing separator before this note header: two notes are read as one"})
+			starts = append(starts, i)
+		}
+
+		for k, from := range starts {
+			to := len(c.lines)
+			if k+1 < len(starts) {
+				to = starts[k+1]
+			}
+			for from < to && strings.TrimSpace(c.lines[from]) == "" {
+				from++
+			}
+			part := strings.TrimSpace(strings.Join(c.lines[from:to], "\n"))
+			header := c.lines[from]
+			switch {
+			case !strings.HasPrefix(part, "## "):
+				issues = append(issues, DoctorIssue{Check: DoctorStructure, Line: c.line + from,
+					Message: fmt.Sprintf("text without a note header is ignored on load: %q", truncate(strings.TrimSpace(header), 60))})
+			case !docHeaderRE.MatchString(header):
+				issues = append(issues, DoctorIssue{Check: DoctorStructure, Line: c.line + from,
+					Message: fmt.Sprintf("note header has no timestamp, so the note gets a new one on every load: %q", truncate(header, 60))})
+			}
+			out = append(out, part+"\n")
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return issues, strings.Join(out, models.NoteSeparator)
+}
+
+// checkAssets compares the files under assets/ with the links in text:
+// files nothing links to are orphans, links to missing files dangle.
+// Files offloaded to the folder's bucket count as there.
+func checkAssets(basePath, text string) []DoctorIssue {
+	var issues []DoctorIssue
+	offloaded, err := storage.ReadOffloadIndex(basePath)
+	if err != nil {
+		issues = append(issues, DoctorIssue{Check: DoctorAssets, Message: err.Error()})
+	}
+	present := func(rel string) bool {
+		if _, ok := offloaded["assets/"+rel]; ok {
+			return true
+		}
+		_, err := os.Stat(filepath.Join(basePath, "assets", filepath.FromSlash(rel)))
+		return !errors.Is(err, os.ErrNotExist)
+	}
+	linked := make(map[string]bool)
+	var missing []string
+	for _, m := range assetRefRE.FindAllStringSubmatch(text, -1) {
+		rel := m[1]
+		if u, err := url.PathUnescape(rel); err == nil {
+			rel = u
+		}
+		rel = strings.TrimRight(rel, ".,;:")
+		if i := strings.IndexAny(rel, "?#"); i >= 0 {
+			rel = rel[:i]
+		}
+		if linked[rel] {
+			continue
+		}
+		linked[rel] = true
+		if !present(rel) {
+			missing = append(missing, rel)
+		}
+	}
+	for _, rel := range missing {
+		check, what := DoctorAssets, "file"
+		if strings.HasPrefix(rel, "sites/") {
+			check, what = DoctorArchives, "archive"
+		}
+		issues = append(issues, DoctorIssue{Check: check, Message: fmt.Sprintf("link to missing %s assets/%s", what, rel)})
+	}
+
+	// Every asset file by its path under assets/, on disk or offloaded.
+	var files []string
+	for _, dir := range []string{"images", "files", "sites"} {
+		root := filepath.Join(basePath, "assets", dir)
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			rel, _ := filepath.Rel(filepath.Join(basePath, "assets"), path)
+			files = append(files, filepath.ToSlash(rel))
+			return nil
+		})
+	}
+	var remote []string
+	for rel := range offloaded {
+		remote = append(remote, strings.TrimPrefix(rel, "assets/"))
+	}
+	sort.Strings(remote)
+	files = append(files, remote...)
+
+	for _, rel := range files {
+		if linked[rel] {
+			continue
+		}
+		if strings.HasPrefix(rel, "sites/") {
+			// Only archives themselves need a link; metadata, tags and
+			// reader copies belong to their archive.
+			name := path.Base(rel)
+			if path.Dir(rel) == "sites/"+storage.ReaderSitesDir {
+				if !archiveExists(present, strings.TrimSuffix(name, ".html")) {
+					issues = append(issues, DoctorIssue{Check: DoctorArchives, Message: "reader copy without its archive: assets/" + rel})
+				}
+				continue
+			}
+			if path.Dir(rel) != "sites" || storage.ArchiveExt(name) == "" {
+				continue
+			}
+		}
+		issues = append(issues, DoctorIssue{Check: DoctorAssets, Message: "orphaned file, no note links to it: assets/" + rel})
+	}
+	return issues
+}
+
+// archiveExists reports whether there is an archive named base in any
+// format, by present, which reports whether a path under assets/ is there.
+func archiveExists(present func(rel string) bool, base string) bool {
+	for _, ext := range storage.ArchiveExtensions {
+		if present("sites/" + base + ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTaskRegistry compares the task DB's rows for the folder with the
+// tasks in notes.md, and re-syncs them with fix.
+func checkTaskRegistry(basePath string, db *DatabaseService, manager *NoteManager, fix bool) ([]DoctorIssue, error) {
+	tasks := manager.GetAllTasks()
+	folders, err := db.GetActiveFolders()
+	if err != nil {
+		return nil, err
+	}
+	var folder *models.FolderRegistry
+	for i := range folders {
+		if folders[i].Path == basePath {
+			folder = &folders[i]
+		}
+	}
+
+	var issues []DoctorIssue
+	if folder == nil {
+		issue := DoctorIssue{Check: DoctorTasks, Fixable: true,
+			Message: "folder isn't registered in the task DB, so its tasks are missing from the global task list"}
+		if fix {
+			if folder, err = db.RegisterFolder(basePath); err != nil {
+				return nil, err
+			}
+			if err := db.SyncFolderTasks(folder.ID, tasks); err != nil {
+				return nil, err
+			}
+			issue.Fixed = true
+		}
+		return append(issues, issue), nil
+	}
+
+	rows, err := db.QueryTasks(TaskFilter{FolderID: folder.ID})
+	if err != nil {
+		return nil, err
+	}
+	inDB := make(map[string]models.GlobalTask, len(rows.Tasks))
+	for _, t := range rows.Tasks {
+		inDB[t.Hash] = t
+	}
+	var notInDB, changed []string
+	for i, hash := range ComputeTaskHashes(tasks) {
+		row, ok := inDB[hash]
+		switch {
+		case !ok:
+			notInDB = append(notInDB, tasks[i].Text)
+		case row.Completed != tasks[i].Checked:
+			changed = append(changed, tasks[i].Text)
+		}
+		delete(inDB, hash)
+	}
+	var gone []string
+	for _, row := range inDB {
+		gone = append(gone, row.Content)
+	}
+	sort.Strings(gone)
+
+	for _, d := range []struct {
+		texts []string
+		what  string
+	}{
+		{notInDB, "in notes.md but not in the task DB"},
+		{gone, "in the task DB but no longer in notes.md"},
+		{changed, "done in one of notes.md and the task DB but open in the other"},
+	} {
+		if len(d.texts) == 0 {
+			continue
+		}
+		msg := fmt.Sprintf("%d task(s) %s, e.g. %q", len(d.texts), d.what, truncate(strings.TrimSpace(d.texts[0]), 60))
+		issues = append(issues, DoctorIssue{Check: DoctorTasks, Message: msg, Fixable: true})
+	}
+	if fix && len(issues) > 0 {
+		if err := db.SyncFolderTasks(folder.ID, tasks); err != nil {
+			return nil, err
+		}
+		for i := range issues {
+			issues[i].Fixed = true
+		}
+	}
+	return issues, nil
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "…"
+	}
+	return s
+}
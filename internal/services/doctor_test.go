@@ -0,0 +1,94 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckNotesText(t *testing.T) {
+	clean := "## 2026-05-12 09:00:00 - a\n\nfirst\n" + "\n<!-- note -->\n" + "## 2026-05-11 09:00:00\n\nsecond\n"
+	if issues, repaired := checkNotesText(clean); len(issues) != 0 || repaired != clean {
+		t.Errorf("clean file: issues %+v, repaired %q", issues, repaired)
+	}
+
+	broken := "## 2026-05-12 09:00:00 - a\n\nfirst\n" +
+		"\n<!-- note -->\n\n<!-- note -->\n" + // duplicate
+		"## 2026-05-11 09:00:00\n\nsecond\n" +
+		"## 2026-05-10 09:00:00 - c\n\nthird\n" + // missing separator
+		"\n<!-- note --> \n" + // trailing space
+		"stray text\n"
+	issues, repaired := checkNotesText(broken)
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.Check+":"+strings.SplitN(issue.Message, " ", 2)[0])
+	}
+	want := []string{"separators:duplicate", "separators:missing", "separators:separator", "structure:text"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("issues = %v, want %v", got, want)
+	}
+	wantText := "## 2026-05-12 09:00:00 - a\n\nfirst\n" +
+		"\n<!-- note -->\n" + "## 2026-05-11 09:00:00\n\nsecond\n" +
+		"\n<!-- note -->\n" + "## 2026-05-10 09:00:00 - c\n\nthird\n" +
+		"\n<!-- note -->\n" + "stray text\n"
+	if repaired != wantText {
+		t.Errorf("repaired =\n%q\nwant\n%q", repaired, wantText)
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	dir := t.TempDir()
+	notes := "## 2026-05-12 09:00:00 - a\n\n- [ ] one\n![x](/assets/images/used.png) [gone](assets/sites/gone.html)\n" +
+		"## 2026-05-11 09:00:00\n\n- [x] two\n"
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte(notes), 0644)
+	os.MkdirAll(filepath.Join(dir, "assets", "images"), 0755)
+	os.WriteFile(filepath.Join(dir, "assets", "images", "used.png"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "assets", "images", "orphan.png"), nil, 0644)
+
+	db, err := NewDatabaseServiceAt(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatalf("NewDatabaseServiceAt: %v", err)
+	}
+	defer db.Close()
+
+	report, err := Doctor(dir, db, false)
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	messages := func(r *DoctorReport) string {
+		var out []string
+		for _, issue := range r.Issues {
+			out = append(out, issue.Message)
+		}
+		return strings.Join(out, "\n")
+	}
+	all := messages(report)
+	for _, want := range []string{"missing separator", "assets/images/orphan.png", "missing archive assets/sites/gone.html", "isn't registered"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("report lacks %q:\n%s", want, all)
+		}
+	}
+	if strings.Contains(all, "used.png") {
+		t.Errorf("linked file reported:\n%s", all)
+	}
+	if report.Notes != 1 {
+		t.Errorf("notes = %d, want 1 (two run together)", report.Notes)
+	}
+
+	if report, err = Doctor(dir, db, true); err != nil {
+		t.Fatalf("Doctor --fix: %v", err)
+	}
+	for _, issue := range report.Issues {
+		if issue.Fixable != issue.Fixed {
+			t.Errorf("fixable issue not fixed: %+v", issue)
+		}
+	}
+
+	// Fixed: the notes are apart and the tasks registered; only the
+	// orphan and the missing archive need a person.
+	report, _ = Doctor(dir, db, false)
+	if report.Notes != 2 || len(report.Issues) != 2 {
+		t.Errorf("after fix: %d notes, issues:\n%s", report.Notes, messages(report))
+	}
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/nldate"
+)
+
+var (
+	// "@due(next friday)" anywhere in a task line.
+	dueCallRE = regexp.MustCompile(`(^|\s)@due\(([^)]*)\)`)
+	// "^tomorrow 5pm" at the end of a task line.
+	dueCaretRE = regexp.MustCompile(`(^|\s)\^([^\^]+?)\s*$`)
+	// Same checkbox shape Note.parseTasks recognizes.
+	taskCheckboxRE = regexp.MustCompile(`\[[xX /]\]`)
+)
+
+// expandDueDates rewrites natural-language due phrases in task lines into
+// concrete @YYYY-MM-DD (or @YYYY-MM-DDTHH:MM) tokens, resolved against now.
+// Phrases that don't parse are left as typed so the user can see they
+// weren't understood. Lines inside fenced code blocks are never touched.
+func expandDueDates(content string, now time.Time) string {
+	if !strings.Contains(content, "@due(") && !strings.Contains(content, "^") {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !taskCheckboxRE.MatchString(line) {
+			continue
+		}
+		line = dueCallRE.ReplaceAllStringFunc(line, func(m string) string {
+			return replaceDuePhrase(dueCallRE, m, now)
+		})
+		line = dueCaretRE.ReplaceAllStringFunc(line, func(m string) string {
+			return replaceDuePhrase(dueCaretRE, m, now)
+		})
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// replaceDuePhrase resolves one match of re (groups: leading space, phrase).
+func replaceDuePhrase(re *regexp.Regexp, match string, now time.Time) string {
+	sub := re.FindStringSubmatch(match)
+	res, ok := nldate.Parse(sub[2], now)
+	if !ok {
+		return match
+	}
+	return sub[1] + models.FormatDueToken(res.Time, res.HasTime)
+}
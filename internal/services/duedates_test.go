@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandDueDates(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC) // a Friday
+	tests := []struct {
+		name, in, want string
+	}{
+		{"due call", "- [ ] call the bank @due(tomorrow 5pm)", "- [ ] call the bank @2026-10-17T17:00"},
+		{"due call mid-line", "- [ ] @due(next friday) ship #release", "- [ ] @2026-10-23 ship #release"},
+		{"trailing caret", "- [ ] renew passport ^in 2 weeks", "- [ ] renew passport @2026-10-30"},
+		{"unparseable kept", "- [ ] think @due(someday)", "- [ ] think @due(someday)"},
+		{"not a task line", "meet @due(tomorrow) maybe", "meet @due(tomorrow) maybe"},
+		{"caret needs a date", "- [ ] compute 2 ^10", "- [ ] compute 2 ^10"},
+		{"fenced code untouched", "```\n- [ ] x @due(tomorrow)\n```", "```\n- [ ] x @due(tomorrow)\n```"},
+	}
+	for _, tt := range tests {
+		if got := expandDueDates(tt.in, now); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAddNote_ExpandsDueDates(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("", "- [ ] water plants ^tomorrow"); err != nil {
+		t.Fatal(err)
+	}
+	task, err := mgr.GetTask(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	if task.DueDate.Format("2006-01-02") != tomorrow {
+		t.Errorf("task = %+v, want due %s", task, tomorrow)
+	}
+}
+
+func TestAddNote_ExpandsDueDatesInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("Pacific/Kiritimati") // UTC+14, a day ahead of most zones
+	if err != nil {
+		t.Skip(err)
+	}
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr.SetLocation(loc)
+	if err := mgr.AddNote("", "- [ ] water plants @due(tomorrow)"); err != nil {
+		t.Fatal(err)
+	}
+	task, err := mgr.GetTask(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tomorrow := time.Now().In(loc).AddDate(0, 0, 1).Format("2006-01-02")
+	if task.DueDate.Format("2006-01-02") != tomorrow {
+		t.Errorf("task = %+v, want due %s", task, tomorrow)
+	}
+}
@@ -0,0 +1,419 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// DefaultDuplicateThreshold is how alike two tasks' words must be, as
+// the share of words they have in common, to count as near-duplicates.
+const DefaultDuplicateThreshold = 0.8
+
+// ErrInvalidLink is returned for a link or dedupe request that names too
+// few tasks or tasks that can't take part.
+var ErrInvalidLink = errors.New("invalid task link")
+
+// DuplicateGroup is a set of open tasks in two or more folders that say
+// the same thing.
+type DuplicateGroup struct {
+	// Text is the normalized text of the group's first task.
+	Text string `json:"text"`
+	// Exact is set when every task normalizes to the same text.
+	Exact bool `json:"exact"`
+	// Similarity is the lowest word overlap between a task and the first.
+	Similarity float64             `json:"similarity"`
+	Tasks      []models.GlobalTask `json:"tasks"`
+}
+
+// normalizeTaskText reduces a task line to what duplicates are compared
+// on: its text without checkbox, priority, due and tag tokens, lowercased,
+// with punctuation dropped and spaces collapsed.
+func normalizeTaskText(line string) string {
+	text := strings.ToLower(stripTaskCheckbox(models.CleanTaskText(line)))
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// wordOverlap is the Jaccard similarity of two word sets.
+func wordOverlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for w := range a {
+		if b[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// FindDuplicateTasks groups the open tasks of the user's active folders
+// whose normalized texts are equal or share at least threshold of their
+// words, keeping groups that span two or more folders. Tasks already
+// linked together count once. Groups come largest first.
+func (trs *TaskRegistryService) FindDuplicateTasks(threshold float64) ([]DuplicateGroup, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("%w: threshold must be above 0 and at most 1", ErrInvalidTaskQuery)
+	}
+	open := false
+	res, err := trs.db.QueryTasks(TaskFilter{Completed: &open})
+	if err != nil {
+		return nil, err
+	}
+
+	type candidate struct {
+		task  models.GlobalTask
+		text  string
+		words map[string]bool
+	}
+	var tasks []candidate
+	for _, task := range res.Tasks {
+		text := normalizeTaskText(task.Content)
+		if text == "" {
+			continue
+		}
+		words := map[string]bool{}
+		for _, w := range strings.Fields(text) {
+			words[w] = true
+		}
+		tasks = append(tasks, candidate{task: task, text: text, words: words})
+	}
+
+	// Union-find over every pair that matches.
+	parent := make([]int, len(tasks))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range tasks {
+		for j := i + 1; j < len(tasks); j++ {
+			a, b := tasks[i], tasks[j]
+			if a.task.FolderID == b.task.FolderID {
+				continue
+			}
+			// Jaccard can't reach threshold when the sizes differ too much.
+			small, large := min(len(a.words), len(b.words)), max(len(a.words), len(b.words))
+			if float64(small) < threshold*float64(large) {
+				continue
+			}
+			if a.text == b.text || wordOverlap(a.words, b.words) >= threshold {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	members := map[int][]int{}
+	for i := range tasks {
+		root := find(i)
+		members[root] = append(members[root], i)
+	}
+	groups := []DuplicateGroup{}
+	for _, idx := range members {
+		folders := map[int]bool{}
+		links := map[int]bool{}
+		for _, i := range idx {
+			folders[tasks[i].task.FolderID] = true
+			links[tasks[i].task.LinkID] = true
+		}
+		if len(folders) < 2 || (len(links) == 1 && !links[0]) {
+			continue // one folder, or already linked together
+		}
+		first := tasks[idx[0]]
+		group := DuplicateGroup{Text: first.text, Exact: true, Similarity: 1}
+		for _, i := range idx {
+			group.Tasks = append(group.Tasks, tasks[i].task)
+			if tasks[i].text != first.text {
+				group.Exact = false
+				group.Similarity = min(group.Similarity, wordOverlap(first.words, tasks[i].words))
+			}
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if len(groups[i].Tasks) != len(groups[j].Tasks) {
+			return len(groups[i].Tasks) > len(groups[j].Tasks)
+		}
+		return groups[i].Text < groups[j].Text
+	})
+	return groups, nil
+}
+
+// LinkTasks links the user's tasks with taskIDs, at least two, so that
+// completing or reopening one does the same to the rest. Groups some of
+// them were already in are merged. It returns the link's ID.
+func (ds *DatabaseService) LinkTasks(taskIDs []int) (int, error) {
+	var tasks []*models.GlobalTask
+	seen := map[int]bool{}
+	for _, id := range taskIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		task, err := ds.GetTask(id)
+		if err != nil {
+			return 0, fmt.Errorf("task %d: %w", id, err)
+		}
+		if task.Hash == "" {
+			return 0, fmt.Errorf("%w: task %d has no hash yet", ErrInvalidLink, id)
+		}
+		tasks = append(tasks, task)
+	}
+	if len(tasks) < 2 {
+		return 0, fmt.Errorf("%w: link at least two tasks", ErrInvalidLink)
+	}
+
+	tx, err := ds.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+	linkID := 0
+	var merged []any
+	for _, task := range tasks {
+		if task.LinkID != 0 {
+			merged = append(merged, task.LinkID)
+			if linkID == 0 || task.LinkID < linkID {
+				linkID = task.LinkID
+			}
+		}
+	}
+	if linkID == 0 {
+		if err := tx.QueryRow(`SELECT COALESCE(MAX(link_id), 0) + 1 FROM task_links`).Scan(&linkID); err != nil {
+			return 0, fmt.Errorf("failed to allocate link: %w", err)
+		}
+	}
+	for _, old := range merged {
+		if _, err := tx.Exec(`UPDATE task_links SET link_id = ? WHERE link_id = ?`, linkID, old); err != nil {
+			return 0, fmt.Errorf("failed to merge links: %w", err)
+		}
+	}
+	for _, task := range tasks {
+		if _, err := tx.Exec(`
+			INSERT INTO task_links (folder_id, task_hash, link_id) VALUES (?, ?, ?)
+			ON CONFLICT(folder_id, task_hash) DO UPDATE SET link_id = excluded.link_id`,
+			task.FolderID, task.Hash, linkID); err != nil {
+			return 0, fmt.Errorf("failed to link task %d: %w", task.ID, err)
+		}
+	}
+	return linkID, tx.Commit()
+}
+
+// UnlinkTasks dissolves a link of the user's tasks, or returns
+// sql.ErrNoRows when none of theirs has it.
+func (ds *DatabaseService) UnlinkTasks(linkID int) error {
+	res, err := ds.db.Exec(`
+		DELETE FROM task_links
+		WHERE link_id = ? AND folder_id IN (SELECT f.id FROM folders f WHERE `+ownerCond+`)`, linkID, ds.user)
+	if err != nil {
+		return fmt.Errorf("failed to unlink tasks: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// LinkedTasks returns the tasks linked to taskID, not including it.
+func (ds *DatabaseService) LinkedTasks(taskID int) ([]models.GlobalTask, error) {
+	task, err := ds.GetTask(taskID)
+	if err != nil || task.LinkID == 0 {
+		return nil, err
+	}
+	rows, err := ds.db.Query(`
+		SELECT t.id FROM tasks t JOIN task_links l ON l.folder_id = t.folder_id AND l.task_hash = t.task_hash
+		WHERE l.link_id = ? AND t.id != ?`, task.LinkID, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query linked tasks: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var linked []models.GlobalTask
+	for _, id := range ids {
+		// GetTask also keeps out other users' and forgotten folders' tasks.
+		if t, err := ds.GetTask(id); err == nil {
+			linked = append(linked, *t)
+		}
+	}
+	return linked, nil
+}
+
+// LinkTasks links tasks; see DatabaseService.LinkTasks.
+func (trs *TaskRegistryService) LinkTasks(taskIDs []int) (int, error) {
+	linkID, err := trs.db.LinkTasks(taskIDs)
+	if err == nil {
+		trs.events.Publish(Event{Type: EventTasksSynced})
+	}
+	return linkID, err
+}
+
+// UnlinkTasks dissolves a link; see DatabaseService.UnlinkTasks.
+func (trs *TaskRegistryService) UnlinkTasks(linkID int) error {
+	err := trs.db.UnlinkTasks(linkID)
+	if err == nil {
+		trs.events.Publish(Event{Type: EventTasksSynced})
+	}
+	return err
+}
+
+// setLinkedCompletion completes or reopens, in their notes, the tasks
+// linked to taskID that aren't that way yet. Failures are logged: the
+// task itself has changed either way.
+func (trs *TaskRegistryService) setLinkedCompletion(taskID int, completed bool) {
+	linked, err := trs.db.LinkedTasks(taskID)
+	if err != nil {
+		log.Printf("Warning: linked tasks of %d: %v", taskID, err)
+		return
+	}
+	for _, task := range linked {
+		if task.Completed == completed {
+			continue
+		}
+		noteManager, err := trs.FolderNoteManager(task.FolderID)
+		if err == nil {
+			err = trs.db.SetTaskCompletion(&task, completed, noteManager)
+		}
+		if err != nil {
+			log.Printf("Warning: update linked task %d in %s: %v", task.ID, task.FolderPath, err)
+			continue
+		}
+		var diff TaskDiff
+		if change := (TaskChange{ID: task.ID, Content: task.Content}); completed {
+			diff.Completed = append(diff.Completed, change)
+		} else {
+			diff.Reopened = append(diff.Reopened, change)
+		}
+		trs.events.Publish(Event{Type: EventTasksChanged, Folder: task.FolderPath, Changes: &diff})
+	}
+}
+
+// DedupeResult reports what DedupeTasks removed.
+type DedupeResult struct {
+	Kept    models.GlobalTask   `json:"kept"`
+	Removed []models.GlobalTask `json:"removed"`
+}
+
+// DedupeTasks deletes the tasks with ids remove from their notes, the
+// lines nested under them included, keeping the task keep, which must
+// not be among them. Each changed note gets a history revision.
+func (trs *TaskRegistryService) DedupeTasks(keep int, remove []int) (*DedupeResult, error) {
+	kept, err := trs.db.GetTask(keep)
+	if err != nil {
+		return nil, fmt.Errorf("task %d: %w", keep, err)
+	}
+	var tasks []*models.GlobalTask
+	for _, id := range remove {
+		if id == keep {
+			return nil, fmt.Errorf("%w: task %d is both kept and removed", ErrInvalidLink, id)
+		}
+		task, err := trs.db.GetTask(id)
+		if err != nil {
+			return nil, fmt.Errorf("task %d: %w", id, err)
+		}
+		tasks = append(tasks, task)
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("%w: name at least one task to remove", ErrInvalidLink)
+	}
+
+	result := &DedupeResult{Kept: *kept, Removed: []models.GlobalTask{}}
+	folders := map[int]bool{}
+	for _, task := range tasks {
+		noteManager, err := trs.FolderNoteManager(task.FolderID)
+		if err != nil {
+			return result, err
+		}
+		if err := noteManager.DeleteTaskByHash(task.Hash); err != nil {
+			return result, fmt.Errorf("remove task %d from %s: %w", task.ID, task.FolderPath, err)
+		}
+		result.Removed = append(result.Removed, *task)
+		folders[task.FolderID] = true
+	}
+	for folderID := range folders {
+		if err := trs.SyncFolderByID(folderID); err != nil {
+			log.Printf("Warning: sync folder %d after dedupe: %v", folderID, err)
+		}
+	}
+	log.Printf("Removed %d duplicate(s) of task %d", len(result.Removed), keep)
+	return result, nil
+}
+
+// DeleteTaskByHash removes the task with a stable hash (see
+// ComputeTaskHashes) from its note, with the lines indented under it.
+func (nm *NoteManager) DeleteTaskByHash(hash string) error {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.refresh()
+
+	var all []models.Task
+	for _, note := range nm.notes {
+		for _, task := range note.Tasks {
+			all = append(all, *task)
+		}
+	}
+	index := -1
+	for i, h := range ComputeTaskHashes(all) {
+		if h == hash {
+			index = all[i].Index
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("%w: no task %s in the notes", ErrTaskNotInNotes, hash)
+	}
+
+	for _, note := range nm.notes {
+		positions := taskPositions(note)
+		for i, task := range note.Tasks {
+			if task.Index != index {
+				continue
+			}
+			if positions[i] < 0 {
+				return fmt.Errorf("%w: task %s not found in its note", ErrTaskNotInNotes, hash)
+			}
+			lines := strings.Split(note.Content, "\n")
+			start := strings.Count(note.Content[:positions[i]], "\n")
+			indent := leadingSpace(lines[start])
+			end := start + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "" && leadingSpace(lines[end]) > indent {
+				end++
+			}
+			content := strings.Join(append(lines[:start:start], lines[end:]...), "\n")
+			nm.saveRevision(note, note.Title, content)
+			note.Update(note.Title, content)
+			nm.assignTaskIndices()
+			nm.needsSave = true
+			return nm.saveEvent(Event{Type: EventNoteUpdated, NoteID: note.HistoryKey(), Title: note.Title})
+		}
+	}
+	return fmt.Errorf("%w: no task %s in the notes", ErrTaskNotInNotes, hash)
+}
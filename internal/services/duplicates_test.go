@@ -0,0 +1,119 @@
+package services
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestDuplicateTasks(t *testing.T) {
+	db, err := NewDatabaseServiceAt(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	trs := &TaskRegistryService{db: db, noteManagers: map[string]*NoteManager{}, folderIDs: map[string]int{}, stopCh: make(chan struct{})}
+
+	notes := []string{
+		"- [ ] Renew the TLS certificate\n  - staging too\n- [ ] only here",
+		"- [ ] renew the TLS certificate! #ops\n- [ ] Write release notes for v2",
+		"- [ ] Write the release notes for v2\n- [x] renew the tls certificate",
+	}
+	var folders []*models.FolderRegistry
+	for _, content := range notes {
+		folder, err := trs.AddFolderByPath(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		nm, _ := trs.FolderNoteManager(folder.ID)
+		if err := nm.AddNote("", content); err != nil {
+			t.Fatal(err)
+		}
+		if err := trs.SyncFolderByID(folder.ID); err != nil {
+			t.Fatal(err)
+		}
+		folders = append(folders, folder)
+	}
+
+	groups, err := trs.FindDuplicateTasks(DefaultDuplicateThreshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The done task in the third folder doesn't count; "write (the)
+	// release notes" shares 5 of 6 words.
+	if len(groups) != 2 {
+		t.Fatalf("groups = %+v", groups)
+	}
+	tls, notesGroup := groups[0], groups[1]
+	if tls.Text != "renew the tls certificate" || !tls.Exact || len(tls.Tasks) != 2 {
+		t.Errorf("tls group = %+v", tls)
+	}
+	if notesGroup.Exact || len(notesGroup.Tasks) != 2 || notesGroup.Similarity < 0.8 {
+		t.Errorf("release notes group = %+v", notesGroup)
+	}
+	if groups, _ := trs.FindDuplicateTasks(0.9); len(groups) != 1 {
+		t.Errorf("at 0.9: %d groups, want the exact one only", len(groups))
+	}
+	if _, err := trs.FindDuplicateTasks(1.5); err == nil {
+		t.Error("a threshold above 1 was accepted")
+	}
+
+	// Linked tasks complete together, and are no longer reported.
+	a, b := tls.Tasks[0], tls.Tasks[1]
+	if _, err := trs.LinkTasks([]int{a.ID}); err == nil {
+		t.Error("a link of one task was accepted")
+	}
+	linkID, err := trs.LinkTasks([]int{a.ID, b.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if groups, _ := trs.FindDuplicateTasks(DefaultDuplicateThreshold); len(groups) != 1 {
+		t.Errorf("linked tasks still reported: %+v", groups)
+	}
+	if err := trs.UpdateGlobalTaskCompletion(a.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	other, err := db.GetTask(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !other.Completed || other.LinkID != linkID {
+		t.Errorf("linked task = %+v", other)
+	}
+	nm, _ := trs.FolderNoteManager(other.FolderID)
+	if text := nm.GetAllNotes()[0].Content; !strings.Contains(text, "- [x] renew the TLS certificate") {
+		t.Errorf("linked task not ticked in its notes:\n%s", text)
+	}
+	if err := trs.UnlinkTasks(linkID); err != nil {
+		t.Fatal(err)
+	}
+	if err := trs.UnlinkTasks(linkID); err == nil {
+		t.Error("unlinking twice succeeded")
+	}
+
+	// Deduping deletes the task and its nested lines from its note.
+	keep, drop := notesGroup.Tasks[0], notesGroup.Tasks[1]
+	if _, err := trs.DedupeTasks(keep.ID, []int{keep.ID}); err == nil {
+		t.Error("keeping and removing the same task was accepted")
+	}
+	drop, keep = tls.Tasks[0], tls.Tasks[1]
+	if drop.FolderID != folders[0].ID {
+		drop, keep = keep, drop
+	}
+	res, err := trs.DedupeTasks(keep.ID, []int{drop.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Removed) != 1 || res.Removed[0].ID != drop.ID {
+		t.Errorf("removed = %+v", res.Removed)
+	}
+	nm, _ = trs.FolderNoteManager(folders[0].ID)
+	if text := nm.GetAllNotes()[0].Content; text != "- [ ] only here" {
+		t.Errorf("notes after dedupe = %q", text)
+	}
+	if _, err := db.GetTask(drop.ID); err != ErrGlobalTaskNotFound {
+		t.Errorf("removed task still registered: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// Event types published on an EventHub.
+const (
+	EventNoteCreated  = "note.created"
+	EventNoteUpdated  = "note.updated"
+	EventNoteDeleted  = "note.deleted"
+	EventTaskToggled  = "task.toggled"
+	EventNotesChanged = "notes.changed" // any other change to notes.md, e.g. an external edit
+	EventTasksSynced  = "tasks.synced"  // a folder's tasks were re-read into the task DB
+	EventTasksChanged = "tasks.changed" // the task DB gained, completed, reopened or lost tasks
+)
+
+// Event describes one change to a folder's notes or tasks, for pages that
+// want to refresh without polling.
+type Event struct {
+	Type   string `json:"type"`
+	Folder string `json:"folder"`
+	// NoteID identifies the note of note.* events; see
+	// models.Note.HistoryKey.
+	NoteID string `json:"note_id,omitempty"`
+	// Title is the note's title, for note.* events.
+	Title string `json:"title,omitempty"`
+	// Task is the toggled task as saved, for task.toggled from a note.
+	Task *models.Task `json:"task,omitempty"`
+	// Changes lists the tasks of tasks.changed.
+	Changes *TaskDiff `json:"changes,omitempty"`
+}
+
+// eventBuffer is how many events a subscriber may fall behind before
+// further ones are dropped for it.
+const eventBuffer = 32
+
+// EventHub fans events out to subscribers. A nil *EventHub discards
+// everything published to it.
+type EventHub struct {
+	mu     sync.Mutex
+	subs   map[chan Event]struct{}
+	closed bool
+}
+
+// NewEventHub creates an EventHub without subscribers.
+func NewEventHub() *EventHub {
+	return &EventHub{subs: make(map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel receiving every event published from now
+// on, and a function that unsubscribes. The channel is closed on
+// unsubscribing and when the hub is closed. Publish never blocks on a
+// slow subscriber; events it can't keep up with are dropped.
+func (h *EventHub) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, eventBuffer)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	h.subs[ch] = struct{}{}
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[ch]; ok {
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close ends every subscription, so long-lived streams finish and the
+// server can shut down.
+func (h *EventHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+// Publish sends e to every subscriber.
+func (h *EventHub) Publish(e Event) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+)
+
+func TestEventHub_SlowSubscriberDoesNotBlock(t *testing.T) {
+	hub := NewEventHub()
+	events, unsubscribe := hub.Subscribe()
+	for i := 0; i < eventBuffer*2; i++ {
+		hub.Publish(Event{Type: EventNotesChanged})
+	}
+	if len(events) != eventBuffer {
+		t.Errorf("buffered %d events, want %d", len(events), eventBuffer)
+	}
+	unsubscribe()
+	unsubscribe() // idempotent
+	hub.Publish(Event{Type: EventNotesChanged})
+
+	var nilHub *EventHub
+	nilHub.Publish(Event{Type: EventNotesChanged}) // must not panic
+}
+
+func TestEventHub_CloseEndsSubscriptions(t *testing.T) {
+	hub := NewEventHub()
+	events, unsubscribe := hub.Subscribe()
+	hub.Close()
+	unsubscribe() // after Close: no double close
+	if _, ok := <-events; ok {
+		t.Error("subscription still open after Close")
+	}
+	closed, _ := hub.Subscribe()
+	if _, ok := <-closed; ok {
+		t.Error("Subscribe on a closed hub returned an open channel")
+	}
+}
+
+func TestNoteManager_PublishesChanges(t *testing.T) {
+	nm, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := NewEventHub()
+	nm.SetEvents(hub)
+	events, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+
+	next := func() Event {
+		t.Helper()
+		select {
+		case e := <-events:
+			return e
+		default:
+			t.Fatal("no event published")
+			return Event{}
+		}
+	}
+
+	if err := nm.AddNote("plan", "- [ ] ship it"); err != nil {
+		t.Fatal(err)
+	}
+	created := next()
+	if created.Type != EventNoteCreated || created.NoteID == "" || created.Title != "plan" || created.Folder != nm.GetBasePath() {
+		t.Errorf("AddNote published %+v", created)
+	}
+
+	if err := nm.UpdateTask(0, true); err != nil {
+		t.Fatal(err)
+	}
+	if e := next(); e.Type != EventTaskToggled || e.Task == nil || !e.Task.Checked || e.NoteID != created.NoteID {
+		t.Errorf("UpdateTask published %+v", e)
+	}
+
+	if err := nm.UpdateNote(0, "plan", "- [x] ship it\nmore"); err != nil {
+		t.Fatal(err)
+	}
+	if e := next(); e.Type != EventNoteUpdated || e.NoteID != created.NoteID {
+		t.Errorf("UpdateNote published %+v", e)
+	}
+
+	if err := nm.DeleteNote(0); err != nil {
+		t.Fatal(err)
+	}
+	if e := next(); e.Type != EventNoteDeleted || e.NoteID != created.NoteID {
+		t.Errorf("DeleteNote published %+v", e)
+	}
+}
@@ -0,0 +1,514 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+	"github.com/Xafloc/NoteFlow-Go/internal/themes"
+)
+
+// Export formats.
+const (
+	ExportFormatZip  = "zip"  // the project's files as they are on disk
+	ExportFormatHTML = "html" // a static site: index.html and the assets it links
+	ExportFormatJSON = "json" // the parsed notes, and every file base64-encoded
+	ExportFormatPDF  = "pdf"  // the notes, or some of them, as one printed document
+	ExportFormatEPUB = "epub" // the notes, or some of them, as an e-book
+)
+
+// ExportOptions selects what an export holds. Patterns are slash paths
+// relative to the folder, matched with path.Match against a file's path,
+// each of its parent directories and, for patterns without a slash, its
+// base name: "assets/sites", "*.pdf" and "assets/images/2026*" all work.
+type ExportOptions struct {
+	Format  string
+	Include []string // when set, only files matching one of these
+	Exclude []string // files matching one of these are left out
+	// Theme is the color theme of an html export; default dark-orange.
+	Theme string
+}
+
+// exportRoots are the parts of a folder that belong to NoteFlow; the rest
+// of it (usually a code repository) is never exported.
+var exportRoots = []string{
+	"notes.md",
+	storage.NotesDBFile,
+	storage.EncryptedNotesFile,
+	storage.WebDAVCacheFile,
+	models.TrashFile,
+	storage.CompletedArchiveFile,
+	models.FolderConfigFile,
+	storage.NoteTemplatesDir,
+	"assets",
+}
+
+// noteflowRoots returns exportRoots for the folder at basePath, with the
+// notes directory when the folder keeps its notes in one.
+func noteflowRoots(basePath string) []string {
+	if storage.NewFileStorage(basePath).Mode() != models.NotesStorageFiles {
+		return exportRoots
+	}
+	return append(append([]string{}, exportRoots...), storage.NotesDir)
+}
+
+// ExportDump is the document of a JSON export.
+type ExportDump struct {
+	Exported time.Time      `json:"exported"`
+	Folder   string         `json:"folder"`
+	Notes    []*models.Note `json:"notes"` // newest first, as indexed by the API
+	Files    []ExportFile   `json:"files"`
+}
+
+// ExportFile is a file of a JSON export.
+type ExportFile struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Data     []byte    `json:"data"` // base64 in JSON
+}
+
+// ExportFiles returns the NoteFlow files of the folder at basePath that
+// opts selects, as sorted slash paths.
+func ExportFiles(basePath string, opts ExportOptions) ([]string, error) {
+	var files []string
+	for _, root := range noteflowRoots(basePath) {
+		err := filepath.WalkDir(filepath.Join(basePath, root), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(basePath, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if exportSelected(rel, opts) {
+				files = append(files, rel)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// exportSelected applies opts' include and exclude patterns to rel.
+func exportSelected(rel string, opts ExportOptions) bool {
+	if len(opts.Include) > 0 && !matchAnyPattern(opts.Include, rel) {
+		return false
+	}
+	return !matchAnyPattern(opts.Exclude, rel)
+}
+
+func matchAnyPattern(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+				return true
+			}
+		}
+		for p := rel; p != "."; p = path.Dir(p) {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ValidateExportOptions checks the format and that every pattern is
+// well-formed, so a typo fails instead of silently matching nothing.
+func ValidateExportOptions(opts ExportOptions) error {
+	switch opts.Format {
+	case ExportFormatZip, ExportFormatHTML, ExportFormatJSON, ExportFormatPDF, ExportFormatEPUB:
+	default:
+		return fmt.Errorf("unknown format %q (want zip, html, json, pdf or epub)", opts.Format)
+	}
+	for _, pattern := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	if opts.Format == ExportFormatHTML {
+		if _, err := exportTheme(opts.Theme); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportZip writes the selected files to w as a zip archive. Unsaved
+// changes are written to notes.md first.
+func (nm *NoteManager) ExportZip(w io.Writer, opts ExportOptions) (int, error) {
+	if err := nm.flush(); err != nil {
+		return 0, err
+	}
+	files, err := ExportFiles(nm.GetBasePath(), opts)
+	if err != nil {
+		return 0, err
+	}
+	zw := zip.NewWriter(w)
+	for _, rel := range files {
+		if err := addZipFile(zw, nm.GetBasePath(), rel); err != nil {
+			return 0, fmt.Errorf("add %s: %w", rel, err)
+		}
+	}
+	return len(files), zw.Close()
+}
+
+func addZipFile(zw *zip.Writer, basePath, rel string) error {
+	f, err := os.Open(filepath.Join(basePath, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = rel
+	header.Method = zip.Deflate
+	out, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, f)
+	return err
+}
+
+// ExportJSON writes the notes and the selected files to w as an
+// ExportDump. Unsaved changes are written to notes.md first.
+func (nm *NoteManager) ExportJSON(w io.Writer, opts ExportOptions) (int, error) {
+	if err := nm.flush(); err != nil {
+		return 0, err
+	}
+	files, err := ExportFiles(nm.GetBasePath(), opts)
+	if err != nil {
+		return 0, err
+	}
+	dump := ExportDump{
+		Exported: time.Now(),
+		Folder:   nm.GetBasePath(),
+		Notes:    nm.GetAllNotes(),
+		Files:    make([]ExportFile, 0, len(files)),
+	}
+	for _, rel := range files {
+		p := filepath.Join(nm.GetBasePath(), filepath.FromSlash(rel))
+		info, err := os.Stat(p)
+		if err != nil {
+			return 0, err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return 0, err
+		}
+		dump.Files = append(dump.Files, ExportFile{Path: rel, Size: info.Size(), Modified: info.ModTime(), Data: data})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return len(files), enc.Encode(dump)
+}
+
+// ExportHTML writes a static site to dir: index.html with every note
+// rendered in opts' theme, and the selected files under assets/ so its
+// images, uploads and archives open from disk or any web server. dir must
+// not exist or be empty.
+func (nm *NoteManager) ExportHTML(dir string, opts ExportOptions) (int, error) {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return 0, fmt.Errorf("%s is not empty", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	return nm.writeSite(opts, func(rel, from string, data []byte) error {
+		dst := filepath.Join(dir, filepath.FromSlash(rel))
+		if from != "" {
+			return copyFile(from, dst)
+		}
+		return os.WriteFile(dst, data, 0644)
+	})
+}
+
+// ExportSiteZip writes the static site of ExportHTML to w as a zip
+// archive, for downloading over the API.
+func (nm *NoteManager) ExportSiteZip(w io.Writer, opts ExportOptions) (int, error) {
+	zw := zip.NewWriter(w)
+	now := time.Now()
+	n, err := nm.writeSite(opts, func(rel, from string, data []byte) error {
+		if from != "" {
+			return addZipFile(zw, nm.GetBasePath(), rel)
+		}
+		out, err := zw.CreateHeader(&zip.FileHeader{Name: rel, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, zw.Close()
+}
+
+// siteFile puts a file of the static site at the slash path rel: a copy
+// of the file from, or data when from is empty.
+type siteFile func(rel, from string, data []byte) error
+
+// writeSite passes the files of the static site to put and returns how
+// many assets it copied.
+func (nm *NoteManager) writeSite(opts ExportOptions, put siteFile) (int, error) {
+	theme, err := exportTheme(opts.Theme)
+	if err != nil {
+		return 0, err
+	}
+	files, err := ExportFiles(nm.GetBasePath(), opts)
+	if err != nil {
+		return 0, err
+	}
+	exported := make(map[string]bool)
+	copied := 0
+	for _, rel := range files {
+		if !strings.HasPrefix(rel, "assets/") || strings.HasPrefix(rel, historyPrefix) {
+			continue
+		}
+		if err := put(rel, filepath.Join(nm.GetBasePath(), filepath.FromSlash(rel)), nil); err != nil {
+			return 0, fmt.Errorf("copy %s: %w", rel, err)
+		}
+		exported[rel] = true
+		copied++
+	}
+
+	page, err := nm.renderSite(theme, exported)
+	if err != nil {
+		return 0, err
+	}
+	if err := put("index.html", "", []byte(page)); err != nil {
+		return 0, err
+	}
+	// GitHub Pages serves the files as they are rather than through
+	// Jekyll, which would skip some of them.
+	if err := put(".nojekyll", "", nil); err != nil {
+		return 0, err
+	}
+	return copied, nil
+}
+
+// exportTheme returns the theme named name, or the default theme for "".
+func exportTheme(name string) (*models.Theme, error) {
+	if name == "" {
+		name = models.DefaultConfig().Theme
+	}
+	theme, ok := themes.AvailableThemes[name]
+	if !ok {
+		names := make([]string, 0, len(themes.AvailableThemes))
+		for n := range themes.AvailableThemes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown theme %q (want %s)", name, strings.Join(names, ", "))
+	}
+	return theme, nil
+}
+
+// historyPrefix is where note revisions live; a static site has no use
+// for them.
+const historyPrefix = "assets/.history/"
+
+// flush writes unsaved changes to notes.md, including those held by the
+// save delay.
+func (nm *NoteManager) flush() error {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if err := nm.save(); err != nil {
+		return err
+	}
+	return nm.write()
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// siteNote is a note of the static site.
+type siteNote struct {
+	ID    string
+	Title string // timestamp and title, as the UI shows them
+	Body  template.HTML
+}
+
+// renderSite renders the notes as a self-contained page in theme. Links
+// into the assets tree are made relative, so they resolve beside
+// index.html, and links to archives that aren't among the exported files,
+// or that browsers can't show, are rewritten by siteArchiveLink.
+func (nm *NoteManager) renderSite(theme *models.Theme, exported map[string]bool) (string, error) {
+	nm.mu.RLock()
+	notes := make([]siteNote, len(nm.notes))
+	for i, note := range nm.notes {
+		body, err := nm.renderer.render(note.Content, noteIDPrefix(i))
+		if err != nil {
+			nm.mu.RUnlock()
+			return "", fmt.Errorf("failed to render note %d: %w", i, err)
+		}
+		title := note.Timestamp.Format("2006-01-02 15:04:05")
+		if note.Title != "" {
+			title += " - " + note.Title
+		}
+		body = siteAssetLinks.Replace(body)
+		body = siteFilterLinks.ReplaceAllString(body, "$1")
+		body = siteArchiveHrefs.ReplaceAllStringFunc(body, func(href string) string {
+			rel := siteArchiveHrefs.FindStringSubmatch(href)[1]
+			return nm.siteArchiveLink(rel, exported)
+		})
+		notes[i] = siteNote{ID: fmt.Sprintf("note-%d", i), Title: title, Body: template.HTML(body)}
+	}
+	nm.mu.RUnlock()
+
+	var b strings.Builder
+	err := siteTemplate.Execute(&b, map[string]any{
+		"Folder":   filepath.Base(nm.GetBasePath()),
+		"Exported": time.Now().Format("2006-01-02 15:04"),
+		"Theme":    template.CSS(themeVariables(theme)),
+		"Notes":    notes,
+	})
+	return b.String(), err
+}
+
+// themeVariables declares theme's colors as CSS custom properties, named
+// after the color keys with dashes: --text-color, --box-background.
+func themeVariables(theme *models.Theme) string {
+	keys := make([]string, 0, len(theme.Colors))
+	for key := range theme.Colors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(":root {")
+	for _, key := range keys {
+		fmt.Fprintf(&b, " --%s: %s;", strings.ReplaceAll(key, "_", "-"), theme.Colors[key])
+	}
+	b.WriteString(" }")
+	return b.String()
+}
+
+// siteArchiveHrefs finds links to archived sites, made relative.
+var siteArchiveHrefs = regexp.MustCompile(`href="(assets/sites/[^"/]+)"`)
+
+// siteArchiveLink returns the href attribute for a link to the archive
+// rel in the static site. HTML and PDF archives that were exported stay
+// as they are. Others link to the archive's reader copy when that was
+// exported, else to the page it was archived from; with neither the link
+// loses its href.
+func (nm *NoteManager) siteArchiveLink(rel string, exported map[string]bool) string {
+	name := path.Base(rel)
+	if storage.ArchiveExt(name) == "" {
+		return `href="` + rel + `"`
+	}
+	if format := storage.ArchiveFormat(name); exported[rel] && (format == "html" || format == "pdf") {
+		return `href="` + rel + `"`
+	}
+	if reader := "assets/sites/" + storage.ReaderSitesDir + "/" + storage.ReaderCopyName(name); exported[reader] {
+		return `href="` + reader + `"`
+	}
+	if meta, err := nm.storage.LoadArchiveMeta(name); err == nil && meta.URL != "" {
+		return `href="` + template.HTMLEscapeString(meta.URL) + `"`
+	}
+	return ""
+}
+
+// siteAssetLinks turns the UI's root-relative asset links into relative
+// ones.
+var siteAssetLinks = strings.NewReplacer(`href="/assets/`, `href="assets/`, `src="/assets/`, `src="assets/`)
+
+// siteFilterLinks finds the hrefs of tag and mention links, which filter
+// the notes in the UI and lead nowhere in a static page.
+var siteFilterLinks = regexp.MustCompile(`(<a class="(?:tag|mention)-link") href="[^"]*"`)
+
+var siteTemplate = template.Must(template.New("site").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Folder}} - NoteFlow</title>
+<style>
+{{.Theme}}
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.5; max-width: 52rem; margin: 2rem auto; padding: 0 1rem; background: var(--background); color: var(--text-color); }
+a { color: var(--link-color); }
+a:visited { color: var(--visited-link-color); }
+a:hover { color: var(--hover-link-color); }
+header { border-bottom: 1px solid var(--note-border); margin-bottom: 1.5rem; }
+header h1 { color: var(--accent); }
+nav ol { padding-left: 1.5rem; }
+article { background: var(--box-background); border: 1px solid var(--note-border); border-radius: 4px; padding: .5rem 1rem; margin-top: 2rem; }
+article h2 { font-size: 1rem; color: var(--header-text); }
+pre, code { background: var(--code-background); color: #24292e; border-radius: 3px; }
+pre { padding: .75rem; overflow-x: auto; }
+img { max-width: 100%; }
+blockquote { border-left: 3px solid var(--accent); margin-left: 0; padding-left: 1rem; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid var(--table-border); padding: .25rem .5rem; }
+th { background: var(--table-header-bg); color: var(--table-header-text); }
+tr:nth-child(even) td { background: var(--table-row-alt-bg); }
+.tag, .tag-link, .mention-link { color: var(--accent); }
+.wiki-link-missing { color: var(--visited-link-color); }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Folder}}</h1>
+<p>Exported from NoteFlow on {{.Exported}}</p>
+</header>
+<nav><ol>{{range .Notes}}
+<li><a href="#{{.ID}}">{{.Title}}</a></li>{{end}}
+</ol></nav>
+{{range .Notes}}
+<article id="{{.ID}}">
+<h2>{{.Title}}</h2>
+{{.Body}}
+</article>
+{{end}}
+</body>
+</html>
+`))
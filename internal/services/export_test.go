@@ -0,0 +1,188 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/themes"
+)
+
+// exportFolder is a project with notes, an image, an archive and a file
+// that isn't NoteFlow's.
+func exportFolder(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	notes := "## 2026-05-12 09:00:00 - Plan\n\n![chart](/assets/images/chart.png) see [[Ideas]] #work\n" +
+		"\n<!-- note -->\n" + "## 2026-05-11 09:00:00 - Ideas\n\n- [ ] try it\n"
+	files := map[string]string{
+		"notes.md":                notes,
+		"assets/images/chart.png": "png",
+		"assets/sites/page.html":  "<html></html>",
+		"main.go":                 "package main",
+	}
+	for name, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestExportFiles(t *testing.T) {
+	dir := exportFolder(t)
+	tests := []struct {
+		opts ExportOptions
+		want string
+	}{
+		{ExportOptions{}, "assets/images/chart.png,assets/sites/page.html,notes.md"},
+		{ExportOptions{Exclude: []string{"assets/sites"}}, "assets/images/chart.png,notes.md"},
+		{ExportOptions{Exclude: []string{"*.png"}}, "assets/sites/page.html,notes.md"},
+		{ExportOptions{Include: []string{"assets/*"}, Exclude: []string{"page.*"}}, "assets/images/chart.png"},
+	}
+	for _, tt := range tests {
+		got, err := ExportFiles(dir, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("ExportFiles(%+v) = %v, want %s", tt.opts, got, tt.want)
+		}
+	}
+
+	if err := ValidateExportOptions(ExportOptions{Format: "tar"}); err == nil {
+		t.Error("unknown format accepted")
+	}
+	if err := ValidateExportOptions(ExportOptions{Format: ExportFormatZip, Exclude: []string{"["}}); err == nil {
+		t.Error("malformed pattern accepted")
+	}
+}
+
+func TestExportZip(t *testing.T) {
+	nm, err := NewNoteManager(exportFolder(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	n, err := nm.ExportZip(&buf, ExportOptions{Format: ExportFormatZip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	if n != 3 || strings.Join(names, ",") != "assets/images/chart.png,assets/sites/page.html,notes.md" {
+		t.Errorf("zip holds %d: %v", n, names)
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	nm, err := NewNoteManager(exportFolder(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(t.TempDir(), "site")
+	if _, err := nm.ExportHTML(out, ExportOptions{Format: ExportFormatHTML}); err != nil {
+		t.Fatal(err)
+	}
+	page, err := os.ReadFile(filepath.Join(out, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`src="assets/images/chart.png"`, // asset links made relative
+		`href="#note-1"`,                // wiki link to the note's article
+		`<article id="note-1">`,
+		`<a class="tag-link" data-tag="work">`, // no filter link
+		`2026-05-12 09:00:00 - Plan`,
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("index.html lacks %s", want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(out, "assets", "images", "chart.png")); err != nil {
+		t.Errorf("image not copied: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(out, ".nojekyll")); err != nil {
+		t.Errorf("no .nojekyll for GitHub Pages: %v", err)
+	}
+
+	if _, err := nm.ExportHTML(out, ExportOptions{Format: ExportFormatHTML}); err == nil {
+		t.Error("export into a non-empty directory succeeded")
+	}
+	if err := ValidateExportOptions(ExportOptions{Format: ExportFormatHTML, Theme: "neon"}); err == nil {
+		t.Error("unknown theme accepted")
+	}
+}
+
+func TestExportSite_ThemeAndArchives(t *testing.T) {
+	dir := exportFolder(t)
+	for name, data := range map[string]string{
+		"assets/sites/old.mhtml":       "mhtml",
+		"assets/sites/reader/old.html": "<html>reader</html>",
+		"assets/sites/gone.html":       "<html></html>",
+		"assets/sites/gone.json":       `{"url": "https://example.com/gone?a=1&b=2"}`,
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	nm, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nm.AddNote("Reading", "[Page](assets/sites/page.html) [Old](assets/sites/old.mhtml) [Gone](assets/sites/gone.html) [Lost](assets/sites/lost.html)"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	opts := ExportOptions{Format: ExportFormatHTML, Theme: "light-blue", Exclude: []string{"gone.html"}}
+	if _, err := nm.ExportSiteZip(&buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var page string
+	files := map[string]bool{}
+	for _, f := range zr.File {
+		files[f.Name] = true
+		if f.Name == "index.html" {
+			r, _ := f.Open()
+			b := new(bytes.Buffer)
+			b.ReadFrom(r)
+			r.Close()
+			page = b.String()
+		}
+	}
+	if !files[".nojekyll"] || !files["assets/sites/reader/old.html"] || files["assets/sites/gone.html"] || files["notes.md"] {
+		t.Errorf("zip holds %v", files)
+	}
+	for _, want := range []string{
+		"--background: " + themes.AvailableThemes["light-blue"].Colors["background"] + ";",
+		`href="assets/sites/page.html"`,               // exported HTML archive
+		`href="assets/sites/reader/old.html"`,         // MHTML: its reader copy
+		`href="https://example.com/gone?a=1&amp;b=2"`, // excluded: the original page
+		`<a >Lost</a>`, // neither: no link
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("index.html lacks %s", want)
+		}
+	}
+}
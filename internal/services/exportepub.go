@@ -0,0 +1,402 @@
+package services
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// epubImageTypes are the image formats EPUB readers must support, by
+// extension; other images are left out of a book, their alt text kept.
+var epubImageTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".svg":  "image/svg+xml",
+	".webp": "image/webp",
+}
+
+// epubChapter is a note of the book, as an XHTML file in OEBPS/.
+type epubChapter struct {
+	documentNote
+	File string // note-N.xhtml
+}
+
+// epubImage is an asset embedded in the book.
+type epubImage struct {
+	ID        string
+	Href      string // URL relative to OEBPS/: assets/images/x.png
+	MediaType string
+}
+
+// ExportEPUB writes the notes opts selects, oldest first, to w as an EPUB
+// 3 book for e-readers: one chapter per note, a table of contents, and the
+// images the notes show from the folder's assets embedded. Links to other
+// assets, which a reader can't open, keep only their text. It returns how
+// many notes the book holds.
+func (nm *NoteManager) ExportEPUB(w io.Writer, opts DocumentOptions, now time.Time) (int, error) {
+	notes, err := nm.documentNotes(opts, now)
+	if err != nil {
+		return 0, err
+	}
+	title := nm.documentTitle(opts)
+	chapters := make([]epubChapter, len(notes))
+	files := map[string]string{}
+	for i, note := range notes {
+		chapters[i] = epubChapter{documentNote: note, File: note.ID + ".xhtml"}
+		files[note.ID] = chapters[i].File
+	}
+
+	zw := zip.NewWriter(w)
+	// The mimetype comes first and uncompressed, so the file is recognized
+	// by its first bytes.
+	mt, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.WriteString(mt, "application/epub+zip"); err != nil {
+		return 0, err
+	}
+	put := func(name string, data []byte) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+
+	assets := filepath.Join(nm.GetBasePath(), "assets")
+	var images []epubImage
+	embedded := map[string]string{}
+	// embed adds the asset at /assets/rel to the book once and returns its
+	// href, "" if it can't be embedded.
+	embed := func(rel string) (string, error) {
+		if href, ok := embedded[rel]; ok {
+			return href, nil
+		}
+		embedded[rel] = ""
+		mediaType := epubImageTypes[strings.ToLower(path.Ext(rel))]
+		if mediaType == "" {
+			return "", nil
+		}
+		data, err := os.ReadFile(filepath.Join(assets, filepath.FromSlash(rel)))
+		if err != nil {
+			return "", nil // missing or offloaded: the alt text stands in
+		}
+		if err := put("OEBPS/assets/"+rel, data); err != nil {
+			return "", err
+		}
+		href := (&url.URL{Path: "assets/" + rel}).EscapedPath()
+		images = append(images, epubImage{ID: fmt.Sprintf("img%d", len(images)+1), Href: href, MediaType: mediaType})
+		embedded[rel] = href
+		return href, nil
+	}
+
+	for _, ch := range chapters {
+		body, err := epubBody(ch.Body, files, embed)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", ch.File, err)
+		}
+		var b strings.Builder
+		err = epubChapterTemplate.Execute(&b, map[string]any{"Note": ch, "Body": body})
+		if err != nil {
+			return 0, err
+		}
+		if err := put("OEBPS/"+ch.File, []byte(b.String())); err != nil {
+			return 0, err
+		}
+	}
+
+	data := map[string]any{
+		"ID":       epubIdentifier(nm.GetBasePath(), title),
+		"Title":    title,
+		"Modified": now.UTC().Format("2006-01-02T15:04:05Z"),
+		"Chapters": chapters,
+		"Images":   images,
+	}
+	for _, f := range []struct {
+		name string
+		tmpl *template.Template
+	}{
+		{"META-INF/container.xml", epubContainerTemplate},
+		{"OEBPS/content.opf", epubPackageTemplate},
+		{"OEBPS/nav.xhtml", epubNavTemplate},
+		{"OEBPS/toc.ncx", epubNCXTemplate},
+	} {
+		var b strings.Builder
+		if err := f.tmpl.Execute(&b, data); err != nil {
+			return 0, err
+		}
+		if err := put(f.name, []byte(b.String())); err != nil {
+			return 0, err
+		}
+	}
+	if err := put("OEBPS/style.css", []byte(epubCSS)); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	return len(chapters), nil
+}
+
+// epubIdentifier is the book's UUID, derived from the folder and the title
+// so that a reader takes a re-export for a new edition of the same book.
+func epubIdentifier(basePath, title string) string {
+	b := sha1.Sum([]byte(basePath + "\x00" + title))
+	b[6] = b[6]&0x0f | 0x50 // version 5, name-based with SHA-1
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// epubDropped are elements with no place in a book: scripts, embedded
+// pages, forms and media a reader can't play.
+var epubDropped = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Object: true,
+	atom.Embed: true, atom.Button: true, atom.Select: true, atom.Textarea: true,
+	atom.Video: true, atom.Audio: true,
+}
+
+// epubBody turns a note's rendered HTML into well-formed XHTML for a
+// chapter. Links to notes point at their chapter (files maps note-N to
+// note-N.xhtml), /assets/ images go through embed, task checkboxes
+// become ☐ and ☑, and links the book can't follow keep only their text.
+func epubBody(body string, files map[string]string, embed func(rel string) (string, error)) (string, error) {
+	context := &nethtml.Node{Type: nethtml.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := nethtml.ParseFragment(strings.NewReader(body), context)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	var write func(n *nethtml.Node) error
+	children := func(n *nethtml.Node) error {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if err := write(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	write = func(n *nethtml.Node) error {
+		switch n.Type {
+		case nethtml.TextNode:
+			b.WriteString(epubEscaper.Replace(n.Data))
+			return nil
+		case nethtml.ElementNode:
+		default:
+			return nil // comments, doctypes
+		}
+		if epubDropped[n.DataAtom] || n.Namespace != "" {
+			return nil // inline SVG and MathML too, which would need their namespaces
+		}
+		attrs := map[string]string{}
+		for _, a := range n.Attr {
+			attrs[a.Key] = a.Val
+		}
+		switch n.DataAtom {
+		case atom.Input:
+			if attrs["type"] == "checkbox" {
+				if _, checked := attrs["checked"]; checked {
+					b.WriteString("☑")
+				} else {
+					b.WriteString("☐")
+				}
+			}
+			return nil
+		case atom.Img:
+			src := attrs["src"]
+			href := ""
+			if rel, ok := strings.CutPrefix(src, "/assets/"); ok {
+				if rel, err := url.PathUnescape(rel); err == nil && !strings.Contains("/"+rel+"/", "/../") {
+					if href, err = embed(rel); err != nil {
+						return err
+					}
+				}
+			}
+			if href == "" {
+				alt := epubEscaper.Replace(attrs["alt"])
+				if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+					// Readers don't load remote images: link to it instead,
+					// unless the image is a link already.
+					if alt == "" {
+						alt = epubEscaper.Replace(src)
+					}
+					if !insideLink(n) {
+						alt = `<a href="` + epubEscaper.Replace(src) + `">` + alt + `</a>`
+					}
+				}
+				b.WriteString(alt)
+				return nil
+			}
+			attrs["src"] = href
+		case atom.A:
+			href, ok := attrs["href"]
+			if id, local := strings.CutPrefix(href, "#"); local {
+				// Ids are note-N, or note-N-... within note N.
+				if m := epubNoteAnchor.FindStringSubmatch(id); m != nil {
+					file := files[m[1]]
+					switch {
+					case file == "":
+						return children(n) // a note left out of the book
+					case id == m[1]:
+						attrs["href"] = file
+					default:
+						attrs["href"] = file + "#" + id
+					}
+				}
+			} else if ok && !strings.Contains(href, "://") && !strings.HasPrefix(href, "mailto:") {
+				return children(n) // the UI's own pages and assets
+			}
+		}
+
+		b.WriteString("<" + n.Data)
+		for _, a := range n.Attr {
+			if a.Namespace != "" || strings.HasPrefix(a.Key, "on") || strings.ContainsAny(a.Key, `:"'<>/=`) {
+				continue
+			}
+			b.WriteString(" " + a.Key + `="` + epubEscaper.Replace(attrs[a.Key]) + `"`)
+		}
+		if epubVoid[n.DataAtom] {
+			b.WriteString("/>")
+			return nil
+		}
+		b.WriteString(">")
+		if err := children(n); err != nil {
+			return err
+		}
+		b.WriteString("</" + n.Data + ">")
+		return nil
+	}
+	for _, n := range nodes {
+		if err := write(n); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// epubEscaper escapes text and attribute values for XHTML.
+var epubEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&#34;")
+
+// insideLink reports whether n is within an <a>.
+func insideLink(n *nethtml.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.DataAtom == atom.A {
+			return true
+		}
+	}
+	return false
+}
+
+var epubNoteAnchor = regexp.MustCompile(`^(note-\d+)(?:-|$)`)
+
+// epubVoid are the elements XHTML writes self-closed.
+var epubVoid = map[atom.Atom]bool{
+	atom.Br: true, atom.Hr: true, atom.Img: true, atom.Wbr: true, atom.Col: true,
+	atom.Area: true, atom.Source: true, atom.Track: true, atom.Meta: true, atom.Link: true,
+}
+
+// The book's files. Everything interpolated is escaped with xml, since
+// text/template knows nothing of XML; chapter bodies come from epubBody.
+var epubFuncs = template.FuncMap{
+	"xml": epubEscaper.Replace,
+	"inc": func(i int) int { return i + 1 },
+}
+
+var epubContainerTemplate = template.Must(template.New("container").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`))
+
+var epubPackageTemplate = template.Must(template.New("opf").Funcs(epubFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">{{.ID}}</dc:identifier>
+    <dc:title>{{xml .Title}}</dc:title>
+    <dc:language>en</dc:language>
+    <dc:creator>NoteFlow</dc:creator>
+    <meta property="dcterms:modified">{{.Modified}}</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="css" href="style.css" media-type="text/css"/>{{range .Chapters}}
+    <item id="{{.ID}}" href="{{.File}}" media-type="application/xhtml+xml"/>{{end}}{{range .Images}}
+    <item id="{{.ID}}" href="{{xml .Href}}" media-type="{{.MediaType}}"/>{{end}}
+  </manifest>
+  <spine toc="ncx">{{range .Chapters}}
+    <itemref idref="{{.ID}}"/>{{end}}
+  </spine>
+</package>
+`))
+
+var epubNavTemplate = template.Must(template.New("nav").Funcs(epubFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en" xml:lang="en">
+<head><title>{{xml .Title}}</title><link rel="stylesheet" href="style.css"/></head>
+<body>
+<nav epub:type="toc" id="toc">
+<h1>{{xml .Title}}</h1>
+<ol>{{range .Chapters}}
+<li><a href="{{.File}}">{{if .Title}}{{xml .Title}}{{else}}Untitled{{end}}</a></li>{{end}}
+</ol>
+</nav>
+</body>
+</html>
+`))
+
+var epubNCXTemplate = template.Must(template.New("ncx").Funcs(epubFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <head><meta name="dtb:uid" content="{{.ID}}"/></head>
+  <docTitle><text>{{xml .Title}}</text></docTitle>
+  <navMap>{{range $i, $ch := .Chapters}}
+    <navPoint id="nav-{{$ch.ID}}" playOrder="{{inc $i}}">
+      <navLabel><text>{{if $ch.Title}}{{xml $ch.Title}}{{else}}Untitled{{end}}</text></navLabel>
+      <content src="{{$ch.File}}"/>
+    </navPoint>{{end}}
+  </navMap>
+</ncx>
+`))
+
+var epubChapterTemplate = template.Must(template.New("chapter").Funcs(epubFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="en" xml:lang="en">
+<head><title>{{with .Note}}{{if .Title}}{{xml .Title}}{{else}}Untitled{{end}}{{end}}</title><link rel="stylesheet" href="style.css"/></head>
+<body>
+<section id="{{.Note.ID}}">
+<h1>{{with .Note}}{{if .Title}}{{xml .Title}}{{else}}Untitled{{end}}{{end}}</h1>
+<p class="date">{{.Note.Date}}</p>
+{{.Body}}
+</section>
+</body>
+</html>
+`))
+
+const epubCSS = `body { line-height: 1.45; }
+h1 { font-size: 1.5em; margin: 0 0 .2em; }
+.date { color: #777; font-size: .85em; margin: 0 0 1.2em; }
+pre { white-space: pre-wrap; font-size: .85em; }
+img { max-width: 100%; }
+blockquote { border-left: 3px solid #ccc; margin-left: 0; padding-left: 1em; color: #555; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: .2em .45em; }
+.tag, .tag-link, .mention-link { color: #b35c00; text-decoration: none; }
+ul.contains-task-list, .task-list-item { list-style: none; }
+`
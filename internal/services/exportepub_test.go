@@ -0,0 +1,119 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportEPUB(t *testing.T) {
+	dir := exportFolder(t)
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte(
+		"## 2026-10-14 09:00:00 - Chapter two\n\n#essay back to [[Chapter one]], not [[Scratch]]\n\n"+
+			"![chart](/assets/images/chart.png) ![gone](/assets/images/gone.png) ![logo](https://example.com/logo.png)\n\n"+
+			"- [x] drafted<br>\n- [ ] edited & <script>alert(1)</script>\n\nsee [the page](/assets/sites/page.html)\n"+
+			"\n<!-- note -->\n## 2026-10-07 09:00:00 - Scratch\n\nnot in the book\n"+
+			"\n<!-- note -->\n## 2026-10-01 09:00:00 - Chapter one\n\n#essay It starts <here>.\n"), 0644)
+	nm, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	n, err := nm.ExportEPUB(&buf, DocumentOptions{Tag: "essay", Title: "Essays & more"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("%d chapters, want the 2 tagged #essay", n)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := zr.File[0]; f.Name != "mimetype" || f.Method != zip.Store {
+		t.Errorf("first entry %s (method %d), want mimetype stored", f.Name, f.Method)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(r)
+		r.Close()
+		files[f.Name] = string(data)
+		if strings.HasSuffix(f.Name, ".xhtml") || strings.HasSuffix(f.Name, ".opf") || strings.HasSuffix(f.Name, ".ncx") || strings.HasSuffix(f.Name, ".xml") {
+			dec := xml.NewDecoder(strings.NewReader(string(data)))
+			for {
+				if _, err := dec.Token(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Errorf("%s is not well-formed: %v\n%s", f.Name, err, data)
+					break
+				}
+			}
+		}
+	}
+	if files["mimetype"] != "application/epub+zip" {
+		t.Errorf("mimetype = %q", files["mimetype"])
+	}
+	if files["OEBPS/assets/images/chart.png"] != "png" {
+		t.Error("the image is not embedded")
+	}
+
+	// Chapters in spine order, oldest first.
+	opf := files["OEBPS/content.opf"]
+	one, two := strings.Index(opf, `<itemref idref="note-2"/>`), strings.Index(opf, `<itemref idref="note-0"/>`)
+	if one < 0 || two < one {
+		t.Errorf("spine out of order:\n%s", opf)
+	}
+	for _, want := range []string{
+		"<dc:title>Essays &amp; more</dc:title>",
+		`href="assets/images/chart.png" media-type="image/png"`,
+		"<meta property=\"dcterms:modified\">2026-10-17T12:00:00Z</meta>",
+	} {
+		if !strings.Contains(opf, want) {
+			t.Errorf("content.opf lacks %s", want)
+		}
+	}
+	if _, ok := files["OEBPS/note-1.xhtml"]; ok {
+		t.Error("an untagged note is in the book")
+	}
+
+	chapter := files["OEBPS/note-0.xhtml"]
+	for _, want := range []string{
+		"<h1>Chapter two</h1>",
+		`href="note-2.xhtml"`,
+		`src="assets/images/chart.png"`,
+		"gone",
+		`rel="noopener noreferrer">logo</a>`,
+		"☑ drafted<br/>",
+		"☐ edited &amp;",
+		"see the page",
+	} {
+		if !strings.Contains(chapter, want) {
+			t.Errorf("chapter lacks %s:\n%s", want, chapter)
+		}
+	}
+	for _, unwanted := range []string{"<script", "<input", "Scratch</a>", "/assets/"} {
+		if strings.Contains(chapter, unwanted) {
+			t.Errorf("chapter has %s:\n%s", unwanted, chapter)
+		}
+	}
+	if !strings.Contains(files["OEBPS/nav.xhtml"], `<a href="note-2.xhtml">Chapter one</a>`) {
+		t.Errorf("nav = %s", files["OEBPS/nav.xhtml"])
+	}
+
+	if _, err := nm.ExportEPUB(io.Discard, DocumentOptions{Mention: "bob"}, now); !errors.Is(err, ErrNoNotesToExport) {
+		t.Errorf("no match: %v", err)
+	}
+}
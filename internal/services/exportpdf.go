@@ -0,0 +1,223 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// Errors of ExportPDF and ExportEPUB about their options.
+var (
+	ErrInvalidDocument = errors.New("invalid export options")
+	ErrNoNotesToExport = errors.New("no notes match")
+)
+
+// DocumentOptions selects the notes of a PDF or EPUB export and titles
+// it. Empty fields don't filter.
+type DocumentOptions struct {
+	Title   string // default the folder's name
+	Tag     string // notes tagged with this tag or one nested under it
+	Mention string // notes mentioning @name
+	// From and To bound the notes' timestamps, as YYYY-MM-DD days,
+	// inclusive.
+	From string
+	To   string
+}
+
+// documentNote is a note of a PDF or EPUB export.
+type documentNote struct {
+	ID    string // note-N, N the note's index: what wiki links point at
+	Date  string
+	Title string
+	Body  string // rendered, without tag and mention filter links
+}
+
+// documentTitle is opts' title, or the folder's name.
+func (nm *NoteManager) documentTitle(opts DocumentOptions) string {
+	if opts.Title != "" {
+		return opts.Title
+	}
+	return filepath.Base(nm.GetBasePath())
+}
+
+// documentNotes renders the notes opts selects, oldest first as a report
+// or a book reads, or returns ErrNoNotesToExport.
+func (nm *NoteManager) documentNotes(opts DocumentOptions, now time.Time) ([]documentNote, error) {
+	var from, to time.Time
+	for _, d := range []struct {
+		value string
+		dst   *time.Time
+	}{{opts.From, &from}, {opts.To, &to}} {
+		if d.value == "" {
+			continue
+		}
+		t, err := time.ParseInLocation("2006-01-02", d.value, now.Location())
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid date %q (want YYYY-MM-DD)", ErrInvalidDocument, d.value)
+		}
+		*d.dst = t
+	}
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1)
+	}
+
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+	var tagged map[*models.Note]bool
+	if opts.Tag != "" {
+		tagged = nm.notesWithTag(opts.Tag)
+	}
+	var notes []documentNote
+	// nm.notes is newest first.
+	for i := len(nm.notes) - 1; i >= 0; i-- {
+		note := nm.notes[i]
+		switch {
+		case tagged != nil && !tagged[note],
+			opts.Mention != "" && !noteMentions(note, opts.Mention),
+			!from.IsZero() && note.Timestamp.Before(from),
+			!to.IsZero() && !note.Timestamp.Before(to):
+			continue
+		}
+		body, err := nm.renderer.render(note.Content, noteIDPrefix(i))
+		if err != nil {
+			return nil, fmt.Errorf("failed to render note %d: %w", i, err)
+		}
+		notes = append(notes, documentNote{
+			ID:    fmt.Sprintf("note-%d", i),
+			Date:  note.Timestamp.Format("2006-01-02 15:04"),
+			Title: note.Title,
+			Body:  siteFilterLinks.ReplaceAllString(body, "$1"),
+		})
+	}
+	if len(notes) == 0 {
+		return nil, ErrNoNotesToExport
+	}
+	return notes, nil
+}
+
+// pdfNote is a note of the PDF.
+type pdfNote struct {
+	documentNote
+	Body template.HTML
+}
+
+// ExportPDF prints the notes opts selects, oldest first, to one paginated
+// PDF: a cover with a linked table of contents, then each note from a new
+// page. It needs Chrome or Chromium (see chrome.Find; the archive config's
+// chrome_path picks one). It returns the PDF and how many notes it holds.
+func (nm *NoteManager) ExportPDF(opts DocumentOptions, now time.Time) ([]byte, int, error) {
+	page, n, err := nm.renderPDFPage(opts, now)
+	if err != nil {
+		return nil, 0, err
+	}
+	nm.mu.RLock()
+	chromePath := nm.archiveConfig.ChromePath
+	nm.mu.RUnlock()
+	pdf, err := printPDF(page, chromePath)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pdf, n, nil
+}
+
+// renderPDFPage renders the HTML page ExportPDF prints.
+func (nm *NoteManager) renderPDFPage(opts DocumentOptions, now time.Time) (string, int, error) {
+	selected, err := nm.documentNotes(opts, now)
+	if err != nil {
+		return "", 0, err
+	}
+	notes := make([]pdfNote, len(selected))
+	for i, note := range selected {
+		notes[i] = pdfNote{documentNote: note, Body: template.HTML(pdfAssetLinks(note.Body, nm.GetBasePath()))}
+	}
+	var b strings.Builder
+	err = pdfTemplate.Execute(&b, map[string]any{
+		"Title":    nm.documentTitle(opts),
+		"Exported": now.Format("2006-01-02 15:04"),
+		"Filters":  pdfFilters(opts),
+		"Notes":    notes,
+	})
+	return b.String(), len(notes), err
+}
+
+// pdfAssetLinks points the UI's /assets/ links at the files in the folder
+// at basePath, since the page is printed from a temporary directory.
+func pdfAssetLinks(body, basePath string) string {
+	root := "file://" + filepath.ToSlash(filepath.Join(basePath, "assets")) + "/"
+	if !strings.HasPrefix(root, "file:///") {
+		root = "file:///" + strings.TrimPrefix(root, "file://") // Windows drive paths
+	}
+	return strings.NewReplacer(`href="/assets/`, `href="`+root, `src="/assets/`, `src="`+root).Replace(body)
+}
+
+// pdfFilters describes opts' filters for the cover, "" for none.
+func pdfFilters(opts DocumentOptions) string {
+	var parts []string
+	if opts.Tag != "" {
+		parts = append(parts, "#"+opts.Tag)
+	}
+	if opts.Mention != "" {
+		parts = append(parts, "@"+opts.Mention)
+	}
+	switch {
+	case opts.From != "" && opts.To != "":
+		parts = append(parts, opts.From+" to "+opts.To)
+	case opts.From != "":
+		parts = append(parts, "since "+opts.From)
+	case opts.To != "":
+		parts = append(parts, "until "+opts.To)
+	}
+	return strings.Join(parts, ", ")
+}
+
+var pdfTemplate = template.Must(template.New("pdf").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+@page { size: A4; margin: 2cm 1.8cm; @bottom-center { content: counter(page) " / " counter(pages); font-size: 9pt; color: #777; } }
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; font-size: 11pt; line-height: 1.45; color: #222; }
+.cover h1 { font-size: 24pt; margin: 0 0 .25rem; }
+.cover p { color: #555; margin: 0; }
+.toc { margin-top: 1.5rem; padding-left: 1.5rem; }
+.toc li { margin: .15rem 0; }
+.toc a { color: #222; text-decoration: none; }
+.toc .date { color: #777; font-variant-numeric: tabular-nums; margin-right: .5rem; }
+.note { break-before: page; }
+.note > h2 { font-size: 15pt; margin: 0 0 .75rem; border-bottom: 1px solid #ccc; padding-bottom: .25rem; }
+.note > h2 .date { display: block; font-size: 9pt; font-weight: normal; color: #777; }
+a { color: #1a5fb4; }
+pre, code { background: #f5f5f5; border-radius: 3px; }
+pre { padding: .6rem; white-space: pre-wrap; break-inside: avoid; }
+img { max-width: 100%; break-inside: avoid; }
+blockquote { border-left: 3px solid #ccc; margin-left: 0; padding-left: 1rem; color: #555; }
+table { border-collapse: collapse; break-inside: avoid; }
+th, td { border: 1px solid #ccc; padding: .2rem .45rem; }
+h1, h2, h3, h4 { break-after: avoid; }
+.tag, .tag-link, .mention-link { color: #b35c00; text-decoration: none; }
+.wiki-link-missing { color: #999; }
+</style>
+</head>
+<body>
+<section class="cover">
+<h1>{{.Title}}</h1>
+<p>{{len .Notes}} note(s){{if .Filters}} · {{.Filters}}{{end}} · exported from NoteFlow on {{.Exported}}</p>
+<ol class="toc">{{range .Notes}}
+<li><a href="#{{.ID}}"><span class="date">{{.Date}}</span>{{if .Title}}{{.Title}}{{else}}Untitled{{end}}</a></li>{{end}}
+</ol>
+</section>
+{{range .Notes}}
+<section class="note" id="{{.ID}}">
+<h2><span class="date">{{.Date}}</span>{{if .Title}}{{.Title}}{{else}}Untitled{{end}}</h2>
+{{.Body}}
+</section>
+{{end}}
+</body>
+</html>
+`))
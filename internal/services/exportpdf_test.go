@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestExportPDF(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the fake browser")
+	}
+	dir := exportFolder(t)
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte(
+		"## 2026-10-14 09:00:00 - Week 42\n\n#status @ana shipped the importer ![chart](/assets/images/chart.png)\n"+
+			"\n<!-- note -->\n## 2026-10-07 09:00:00 - Week 41\n\n#status started the importer\n"+
+			"\n<!-- note -->\n## 2026-10-01 09:00:00 - Ideas\n\n- [ ] try it\n"), 0644)
+	nm, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+
+	page, n, err := nm.renderPDFPage(DocumentOptions{Tag: "status", Title: "Status report"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("%d notes, want the 2 tagged #status", n)
+	}
+	// Oldest first, in the table of contents and in the body.
+	toc41, toc42 := strings.Index(page, `<a href="#note-1">`), strings.Index(page, `<a href="#note-0">`)
+	body41, body42 := strings.Index(page, `<section class="note" id="note-1">`), strings.Index(page, `<section class="note" id="note-0">`)
+	if toc41 < 0 || toc42 < toc41 || body41 < toc42 || body42 < body41 {
+		t.Errorf("notes out of order:\n%s", page)
+	}
+	for _, want := range []string{
+		"<h1>Status report</h1>",
+		"2 note(s) · #status ·",
+		`src="file://` + filepath.ToSlash(dir) + `/assets/images/chart.png"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page lacks %s", want)
+		}
+	}
+	if strings.Contains(page, "Ideas") {
+		t.Error("an untagged note is in the page")
+	}
+
+	if _, n, _ := nm.renderPDFPage(DocumentOptions{From: "2026-10-07", To: "2026-10-07"}, now); n != 1 {
+		t.Errorf("one day: %d notes", n)
+	}
+	if _, _, err := nm.renderPDFPage(DocumentOptions{Mention: "bob"}, now); !errors.Is(err, ErrNoNotesToExport) {
+		t.Errorf("no match: %v", err)
+	}
+	if _, _, err := nm.renderPDFPage(DocumentOptions{From: "last week"}, now); !errors.Is(err, ErrInvalidDocument) {
+		t.Errorf("bad date: %v", err)
+	}
+
+	// The fake browser "prints" by copying the page it was given.
+	bin := filepath.Join(t.TempDir(), "chrome")
+	script := "#!/bin/sh\nfor a in \"$@\"; do case \"$a\" in --print-to-pdf=*) out=\"${a#--print-to-pdf=}\";; file://*) src=\"${a#file://}\";; esac; done\ncp \"$src\" \"$out\"\n"
+	if err := os.WriteFile(bin, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	nm.SetArchiveConfig(models.ArchiveConfig{ChromePath: bin})
+	pdf, n, err := nm.ExportPDF(DocumentOptions{Mention: "ana"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || !strings.Contains(string(pdf), "shipped the importer") {
+		t.Errorf("printed %d notes: %.200s", n, pdf)
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/github"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// GitHubService links a folder's tasks to GitHub issues: exporting chosen
+// tasks as new issues and keeping issue state in step with the checkbox.
+// Routing (repo, labels) comes from the folder's .noteflow.json; the token
+// comes from the user config or $GITHUB_TOKEN.
+type GitHubService struct {
+	noteManager *NoteManager
+	client      *github.Client
+	folder      *models.GitHubFolderConfig
+	folderName  string
+	stopImport  chan struct{}
+}
+
+// NewGitHubService creates the service. It is always safe to construct;
+// Enabled reports whether the folder is actually configured.
+func NewGitHubService(noteManager *NoteManager, cfg models.GitHubConfig, folderCfg *models.FolderConfig) *GitHubService {
+	var folder *models.GitHubFolderConfig
+	if folderCfg != nil {
+		folder = folderCfg.GitHub
+	}
+	return &GitHubService{
+		noteManager: noteManager,
+		client:      github.NewClient(cfg.API, cfg.ResolvedToken()),
+		folder:      folder,
+		folderName:  filepath.Base(noteManager.GetBasePath()),
+	}
+}
+
+// Enabled reports whether this folder routes tasks to a GitHub repo.
+func (s *GitHubService) Enabled() bool {
+	return s.folder != nil && s.folder.Repo != ""
+}
+
+// ExportedIssue reports the outcome of exporting one task.
+type ExportedIssue struct {
+	TaskIndex int    `json:"task_index"`
+	Number    int    `json:"number"`
+	URL       string `json:"url"`
+	Existing  bool   `json:"existing,omitempty"` // task already linked to an issue
+	Error     string `json:"error,omitempty"`
+}
+
+// ExportTasks creates one issue per task index and appends the issue URL to
+// the task line, so the link lives in notes.md alongside the task. Tasks
+// that already carry an issue URL are reported as Existing and left alone,
+// which makes repeated exports safe.
+func (s *GitHubService) ExportTasks(ctx context.Context, taskIndices []int) ([]ExportedIssue, error) {
+	if !s.Enabled() {
+		return nil, fmt.Errorf("no GitHub repo configured for this folder (set github.repo in %s)", models.FolderConfigFile)
+	}
+
+	results := make([]ExportedIssue, 0, len(taskIndices))
+	for _, idx := range taskIndices {
+		res := ExportedIssue{TaskIndex: idx}
+		task, err := s.noteManager.GetTask(idx)
+		if err != nil {
+			res.Error = err.Error()
+			results = append(results, res)
+			continue
+		}
+		if repo, n, ok := github.ParseIssueURL(task.Text); ok {
+			res.Number, res.Existing = n, true
+			res.URL = fmt.Sprintf("https://github.com/%s/issues/%d", repo, n)
+			results = append(results, res)
+			continue
+		}
+
+		title := stripTaskCheckbox(models.CleanTaskText(task.Text))
+		body := fmt.Sprintf("Exported from NoteFlow (`%s/notes.md`).\n\n```\n%s\n```", s.folderName, task.Text)
+		issue, err := s.client.CreateIssue(ctx, s.folder.Repo, title, body, s.folder.Labels)
+		if err != nil {
+			res.Error = err.Error()
+			results = append(results, res)
+			continue
+		}
+		res.Number, res.URL = issue.Number, issue.HTMLURL
+
+		if err := s.noteManager.UpdateTaskText(idx, strings.TrimRight(task.Text, " ")+" "+issue.HTMLURL); err != nil {
+			res.Error = "issue created but task not annotated: " + err.Error()
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// HandleTaskToggle closes (or reopens) the issue linked from a task when its
+// checkbox changes. Registered as a NoteManager task listener only when the
+// folder opts in via close_on_complete.
+func (s *GitHubService) HandleTaskToggle(task models.Task) {
+	repo, number, ok := github.ParseIssueURL(task.Text)
+	if !ok || !s.linksRepo(repo) {
+		return
+	}
+	state := "open"
+	if task.Checked {
+		state = "closed"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.client.SetIssueState(ctx, repo, number, state); err != nil {
+		log.Printf("Warning: failed to sync GitHub issue state: %v", err)
+	}
+}
+
+// linksRepo reports whether repo is this folder's export repo or one of its
+// import repos — the only repos whose issues checkbox changes may touch.
+func (s *GitHubService) linksRepo(repo string) bool {
+	if strings.EqualFold(repo, s.folder.Repo) {
+		return true
+	}
+	for _, r := range s.folder.Import {
+		if strings.EqualFold(repo, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// CloseOnComplete reports whether checkbox changes should drive issue state.
+func (s *GitHubService) CloseOnComplete() bool {
+	return (s.Enabled() || s.ImportEnabled()) && s.folder.CloseOnComplete
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// fakeGitHub records issue creations and state changes.
+type fakeGitHub struct {
+	mu      sync.Mutex
+	created []string
+	states  []string
+}
+
+func (f *fakeGitHub) server(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		switch r.Method {
+		case http.MethodPost:
+			f.created = append(f.created, body["title"].(string))
+			n := len(f.created)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]any{
+				"number":   n,
+				"html_url": "https://github.com/acme/app/issues/" + string(rune('0'+n)),
+			})
+		case http.MethodPatch:
+			f.states = append(f.states, r.URL.Path+"="+body["state"].(string))
+			w.Write([]byte(`{}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGitHubService_ExportAnnotatesTasks(t *testing.T) {
+	fake := &fakeGitHub{}
+	srv := fake.server(t)
+
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("sprint", "- [ ] !p1 fix login redirect\n- [ ] write docs"); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewGitHubService(mgr, models.GitHubConfig{API: srv.URL, Token: "t"}, &models.FolderConfig{
+		GitHub: &models.GitHubFolderConfig{Repo: "acme/app", Labels: []string{"noteflow"}},
+	})
+
+	results, err := svc.ExportTasks(context.Background(), []int{0})
+	if err != nil {
+		t.Fatalf("ExportTasks: %v", err)
+	}
+	if len(results) != 1 || results[0].Number != 1 || results[0].Error != "" {
+		t.Fatalf("results = %+v", results)
+	}
+	if fake.created[0] != "fix login redirect" {
+		t.Errorf("issue title = %q, want metadata-stripped task text", fake.created[0])
+	}
+
+	task, _ := mgr.GetTask(0)
+	if !strings.HasSuffix(task.Text, "https://github.com/acme/app/issues/1") {
+		t.Errorf("task not annotated with issue URL: %q", task.Text)
+	}
+
+	// Re-exporting a linked task must not open a duplicate issue.
+	results, err = svc.ExportTasks(context.Background(), []int{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Existing || len(fake.created) != 1 {
+		t.Errorf("re-export created a duplicate: results=%+v created=%v", results, fake.created)
+	}
+}
+
+func TestGitHubService_ExportRequiresRepo(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewGitHubService(mgr, models.GitHubConfig{}, &models.FolderConfig{})
+	if svc.Enabled() {
+		t.Error("service without repo should be disabled")
+	}
+	if _, err := svc.ExportTasks(context.Background(), []int{0}); err == nil {
+		t.Error("expected error without a configured repo")
+	}
+}
+
+func TestGitHubService_ToggleClosesIssue(t *testing.T) {
+	fake := &fakeGitHub{}
+	srv := fake.server(t)
+
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewGitHubService(mgr, models.GitHubConfig{API: srv.URL}, &models.FolderConfig{
+		GitHub: &models.GitHubFolderConfig{Repo: "acme/app", CloseOnComplete: true},
+	})
+	svc.HandleTaskToggle(models.Task{Checked: true, Text: "- [x] fix https://github.com/acme/app/issues/4"})
+	svc.HandleTaskToggle(models.Task{Checked: true, Text: "- [x] other https://github.com/else/where/issues/5"})
+
+	if len(fake.states) != 1 || fake.states[0] != "/repos/acme/app/issues/4=closed" {
+		t.Errorf("state changes = %v, want only acme/app#4 closed", fake.states)
+	}
+}
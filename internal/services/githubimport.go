@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/github"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+const defaultGitHubImportInterval = 10 * time.Minute
+
+// ImportEnabled reports whether this folder mirrors assigned issues.
+func (s *GitHubService) ImportEnabled() bool {
+	return s.folder != nil && len(s.folder.Import) > 0
+}
+
+// StartImport runs ImportAssignedIssues immediately and then on a ticker
+// until StopImport is called. GitHub's rate limit makes the 30-second
+// registry tick too aggressive, so imports keep their own interval.
+func (s *GitHubService) StartImport() {
+	if !s.ImportEnabled() || s.stopImport != nil {
+		return
+	}
+	interval := defaultGitHubImportInterval
+	if s.folder.ImportIntervalMinutes > 0 {
+		interval = time.Duration(s.folder.ImportIntervalMinutes) * time.Minute
+	}
+	s.stopImport = make(chan struct{})
+	go func(stop <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.runImport()
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}(s.stopImport)
+}
+
+// StopImport stops the background import loop started by StartImport.
+func (s *GitHubService) StopImport() {
+	if s.stopImport != nil {
+		close(s.stopImport)
+		s.stopImport = nil
+	}
+}
+
+func (s *GitHubService) runImport() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := s.ImportAssignedIssues(ctx); err != nil {
+		log.Printf("Warning: GitHub issue import failed: %v", err)
+	}
+}
+
+// ImportAssignedIssues mirrors open issues assigned to the token owner from
+// every configured repo into the import note as tasks. New issues are
+// appended unchecked; imported issues that are no longer open get checked.
+// Imports never uncheck a task, so a task the user checked locally stays
+// checked even while its issue is still open.
+func (s *GitHubService) ImportAssignedIssues(ctx context.Context) error {
+	if !s.ImportEnabled() {
+		return fmt.Errorf("no import repos configured for this folder (set github.import in %s)", models.FolderConfigFile)
+	}
+	login, err := s.client.CurrentUser(ctx)
+	if err != nil {
+		return err
+	}
+	var open []github.Issue
+	for _, repo := range s.folder.Import {
+		issues, err := s.client.ListAssignedIssues(ctx, repo, login)
+		if err != nil {
+			return err
+		}
+		open = append(open, issues...)
+	}
+
+	title := s.folder.ImportNote
+	if title == "" {
+		title = models.DefaultGitHubImportNote
+	}
+	return s.noteManager.EditNoteByTitle(title, func(content string) string {
+		return mergeIssueTasks(content, open, s.folder.Import)
+	})
+}
+
+// mergeIssueTasks reconciles a note body with the current set of open
+// issues. Only task lines linking to one of repos are touched; anything
+// else the user wrote in the note is preserved as-is.
+func mergeIssueTasks(content string, open []github.Issue, repos []string) string {
+	openByURL := make(map[string]github.Issue, len(open))
+	for _, is := range open {
+		openByURL[issueKey(is.HTMLURL)] = is
+	}
+	tracked := make(map[string]bool, len(repos))
+	for _, r := range repos {
+		tracked[strings.ToLower(r)] = true
+	}
+
+	var lines []string
+	if content != "" {
+		lines = strings.Split(content, "\n")
+	}
+	seen := make(map[string]bool)
+	for i, line := range lines {
+		repo, n, ok := github.ParseIssueURL(line)
+		if !ok || !tracked[strings.ToLower(repo)] || !strings.Contains(line, "[ ]") {
+			if ok {
+				seen[issueKey(fmt.Sprintf("https://github.com/%s/issues/%d", repo, n))] = true
+			}
+			continue
+		}
+		key := issueKey(fmt.Sprintf("https://github.com/%s/issues/%d", repo, n))
+		seen[key] = true
+		if _, stillOpen := openByURL[key]; !stillOpen {
+			lines[i] = strings.Replace(line, "[ ]", "[x]", 1)
+		}
+	}
+
+	for _, is := range open {
+		key := issueKey(is.HTMLURL)
+		if seen[key] {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("- [ ] %s %s", strings.TrimSpace(is.Title), is.HTMLURL))
+		seen[key] = true
+	}
+	return strings.Join(lines, "\n")
+}
+
+// issueKey normalizes an issue URL to "owner/name#N" so github.com and
+// Enterprise hosts compare by repo and number only.
+func issueKey(url string) string {
+	repo, n, ok := github.ParseIssueURL(url)
+	if !ok {
+		return url
+	}
+	return fmt.Sprintf("%s#%d", strings.ToLower(repo), n)
+}
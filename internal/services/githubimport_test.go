@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/github"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestMergeIssueTasks(t *testing.T) {
+	content := strings.Join([]string{
+		"Synced from GitHub.",
+		"- [ ] flaky test https://github.com/acme/app/issues/1",
+		"- [ ] old bug https://github.com/acme/app/issues/2",
+		"- [x] done locally https://github.com/acme/app/issues/3",
+		"- [ ] someone else's repo https://github.com/other/x/issues/9",
+	}, "\n")
+	open := []github.Issue{
+		{Title: "flaky test", HTMLURL: "https://github.com/acme/app/issues/1"},
+		{Title: "done locally", HTMLURL: "https://github.com/acme/app/issues/3"},
+		{Title: "new crash", HTMLURL: "https://github.com/acme/app/issues/5"},
+	}
+
+	got := mergeIssueTasks(content, open, []string{"acme/app"})
+	want := strings.Join([]string{
+		"Synced from GitHub.",
+		"- [ ] flaky test https://github.com/acme/app/issues/1",
+		"- [x] old bug https://github.com/acme/app/issues/2",
+		"- [x] done locally https://github.com/acme/app/issues/3",
+		"- [ ] someone else's repo https://github.com/other/x/issues/9",
+		"- [ ] new crash https://github.com/acme/app/issues/5",
+	}, "\n")
+	if got != want {
+		t.Errorf("merge mismatch\n got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Idempotent: a second merge with the same issues changes nothing.
+	if again := mergeIssueTasks(got, open, []string{"acme/app"}); again != got {
+		t.Errorf("second merge changed content:\n%s", again)
+	}
+}
+
+func TestImportAssignedIssues_CreatesNote(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/user":
+			w.Write([]byte(`{"login":"dev"}`))
+		case r.URL.Path == "/repos/acme/app/issues":
+			if r.URL.Query().Get("assignee") != "dev" {
+				t.Errorf("assignee = %q", r.URL.Query().Get("assignee"))
+			}
+			w.Write([]byte(`[
+				{"number":7,"title":"login loop","html_url":"https://github.com/acme/app/issues/7"},
+				{"number":8,"title":"a PR","html_url":"https://github.com/acme/app/pull/8","pull_request":{}}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewGitHubService(mgr, models.GitHubConfig{API: srv.URL}, &models.FolderConfig{
+		GitHub: &models.GitHubFolderConfig{Import: []string{"acme/app"}},
+	})
+	if err := svc.ImportAssignedIssues(context.Background()); err != nil {
+		t.Fatalf("ImportAssignedIssues: %v", err)
+	}
+
+	notes := mgr.GetAllNotes()
+	if len(notes) != 1 || notes[0].Title != models.DefaultGitHubImportNote {
+		t.Fatalf("notes = %+v", notes)
+	}
+	if len(notes[0].Tasks) != 1 || !strings.Contains(notes[0].Tasks[0].Text, "login loop") {
+		t.Errorf("tasks = %+v, want only the issue (PRs skipped)", notes[0].Tasks)
+	}
+}
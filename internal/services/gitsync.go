@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/git"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// gitCommitDelay is how long auto-commit waits after a change for more
+// before committing them together, so typing a note is one commit.
+const gitCommitDelay = 5 * time.Second
+
+// gitTimeout bounds one git command run in the background.
+const gitTimeout = time.Minute
+
+// gitExclude lists the paths under the NoteFlow files that are never
+// committed: the rotating backups would only bloat the repository.
+var gitExclude = []string{models.BackupsDir}
+
+// ErrGitNotConfigured is returned when the folder config has no git
+// section, or the folder isn't in a git repository.
+var ErrGitNotConfigured = fmt.Errorf("git is not set up for this folder (add a git section to %s in a git repository)", models.FolderConfigFile)
+
+// GitSyncService commits a folder's NoteFlow files (the ones export
+// writes) to the git repository the folder is in, and pushes and pulls
+// them. Only those files are committed; the rest of the work tree is left
+// to its owner.
+type GitSyncService struct {
+	noteManager *NoteManager
+	folder      *models.GitFolderConfig
+	repo        *git.Repo  // nil unless configured and in a repository
+	mu          sync.Mutex // serializes git commands
+
+	pendingMu sync.Mutex
+	pending   []string    // descriptions of the changes not committed yet
+	timer     *time.Timer // commits pending; nil when nothing is
+
+	unsubscribe func()
+	stop        chan struct{}
+}
+
+// NewGitSyncService creates the service. A folder outside a repository, or
+// a missing git binary, is logged and leaves the service disabled.
+func NewGitSyncService(noteManager *NoteManager, folderCfg *models.FolderConfig) *GitSyncService {
+	s := &GitSyncService{noteManager: noteManager}
+	if folderCfg == nil || folderCfg.Git == nil {
+		return s
+	}
+	s.folder = folderCfg.Git
+	repo, err := git.Open(noteManager.GetBasePath())
+	if err != nil {
+		log.Printf("Warning: git sync disabled: %v", err)
+		return s
+	}
+	s.repo = repo
+	return s
+}
+
+// Enabled reports whether the folder's git integration is on.
+func (s *GitSyncService) Enabled() bool {
+	return s.repo != nil
+}
+
+// Watch commits the changes published on events, a little while after
+// the last of a burst, when auto-commit is on.
+func (s *GitSyncService) Watch(events *EventHub) {
+	if !s.Enabled() || !s.folder.AutoCommit || s.unsubscribe != nil {
+		return
+	}
+	ch, unsubscribe := events.Subscribe()
+	s.unsubscribe = unsubscribe
+	go func() {
+		for e := range ch {
+			if e.Folder != s.noteManager.GetBasePath() {
+				continue
+			}
+			if what := describeChange(e); what != "" {
+				s.changed(what)
+			}
+		}
+	}()
+}
+
+// describeChange returns the line a commit message gives e; empty for
+// events that don't change the folder's files.
+func describeChange(e Event) string {
+	quoted := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return fmt.Sprintf(" %q", s)
+	}
+	switch e.Type {
+	case EventNoteCreated:
+		return "Add note" + quoted(e.Title)
+	case EventNoteUpdated:
+		return "Edit note" + quoted(e.Title)
+	case EventNoteDeleted:
+		return "Delete note" + quoted(e.Title)
+	case EventTaskToggled:
+		if e.Task == nil {
+			return "Toggle a task"
+		}
+		if e.Task.Checked {
+			return "Complete task" + quoted(e.Task.Text)
+		}
+		return "Reopen task" + quoted(e.Task.Text)
+	case EventNotesChanged:
+		return "Update notes"
+	}
+	return ""
+}
+
+// changed records a change and (re)starts the commit delay.
+func (s *GitSyncService) changed(what string) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	for _, p := range s.pending {
+		if p == what {
+			what = ""
+			break
+		}
+	}
+	if what != "" {
+		s.pending = append(s.pending, what)
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.timer = time.AfterFunc(gitCommitDelay, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+		defer cancel()
+		if err := s.commitPending(ctx); err != nil {
+			log.Printf("Warning: git auto-commit failed: %v", err)
+		}
+	})
+}
+
+// commitMessage summarises changes: the change itself when there is one,
+// else a count with one line per change in the body.
+func commitMessage(changes []string) string {
+	switch len(changes) {
+	case 0:
+		return "Update notes"
+	case 1:
+		return changes[0]
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Update notes: %d changes\n", len(changes))
+	for _, c := range changes {
+		b.WriteString("\n- " + c)
+	}
+	return b.String()
+}
+
+// commitPending commits the changes recorded since the last commit. On
+// failure they stay pending for the next one.
+func (s *GitSyncService) commitPending(ctx context.Context) error {
+	s.pendingMu.Lock()
+	changes := s.pending
+	s.pending = nil
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.pendingMu.Unlock()
+
+	if _, err := s.Commit(ctx, commitMessage(changes)); err != nil {
+		s.pendingMu.Lock()
+		s.pending = append(changes, s.pending...)
+		s.pendingMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Commit commits the folder's NoteFlow files with message and reports
+// whether anything had changed.
+func (s *GitSyncService) Commit(ctx context.Context, message string) (bool, error) {
+	if !s.Enabled() {
+		return false, ErrGitNotConfigured
+	}
+	// Changes held by the save delay belong in this commit.
+	if err := s.noteManager.flush(); err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.repo.CommitPaths(ctx, message, noteflowRoots(s.noteManager.GetBasePath()), gitExclude)
+}
+
+// Log returns up to limit recent commits touching the folder's NoteFlow
+// files, newest first.
+func (s *GitSyncService) Log(ctx context.Context, limit int) ([]git.Commit, error) {
+	if !s.Enabled() {
+		return nil, ErrGitNotConfigured
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.repo.Log(ctx, limit, noteflowRoots(s.noteManager.GetBasePath()))
+}
+
+// target returns the remote and branch pushes and pulls go to.
+func (s *GitSyncService) target(ctx context.Context) (string, string, error) {
+	remote, branch := s.folder.Remote, s.folder.Branch
+	if remote == "" {
+		remote = models.DefaultGitRemote
+	}
+	if branch == "" {
+		branch = s.repo.CurrentBranch(ctx)
+	}
+	if branch == "" {
+		return "", "", errors.New("HEAD is detached; set git.branch in " + models.FolderConfigFile)
+	}
+	return remote, branch, nil
+}
+
+// Pull commits pending changes, rebases onto the remote branch and
+// reloads notes.md if the pull changed it.
+func (s *GitSyncService) Pull(ctx context.Context) error {
+	if !s.Enabled() {
+		return ErrGitNotConfigured
+	}
+	if err := s.commitPending(ctx); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	remote, branch, err := s.target(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Pull(ctx, remote, branch); err != nil {
+		return err
+	}
+	_, err = s.noteManager.ReloadIfChanged()
+	return err
+}
+
+// Push commits pending changes and pushes them to the remote branch.
+func (s *GitSyncService) Push(ctx context.Context) error {
+	if !s.Enabled() {
+		return ErrGitNotConfigured
+	}
+	if err := s.commitPending(ctx); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	remote, branch, err := s.target(ctx)
+	if err != nil {
+		return err
+	}
+	return s.repo.Push(ctx, remote, branch)
+}
+
+// Start pulls and pushes on a ticker, when the folder sets sync_minutes,
+// until Stop is called.
+func (s *GitSyncService) Start() {
+	if !s.Enabled() || s.folder.SyncMinutes <= 0 || s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	go func(stop <-chan struct{}) {
+		ticker := time.NewTicker(time.Duration(s.folder.SyncMinutes) * time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+			err := s.Pull(ctx)
+			if err == nil {
+				err = s.Push(ctx)
+			}
+			cancel()
+			if err != nil {
+				log.Printf("Warning: git sync failed: %v", err)
+			}
+		}
+	}(s.stop)
+}
+
+// Stop ends the sync loop and the event watch, and commits any change
+// still waiting for the commit delay.
+func (s *GitSyncService) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+	if s.unsubscribe != nil {
+		s.unsubscribe()
+		s.unsubscribe = nil
+	}
+	s.pendingMu.Lock()
+	waiting := s.timer != nil
+	s.pendingMu.Unlock()
+	if waiting {
+		ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+		defer cancel()
+		if err := s.commitPending(ctx); err != nil {
+			log.Printf("Warning: git auto-commit failed: %v", err)
+		}
+	}
+}
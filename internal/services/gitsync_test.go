@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestCommitMessage(t *testing.T) {
+	if got := commitMessage([]string{`Add note "Plan"`}); got != `Add note "Plan"` {
+		t.Errorf("one change: %q", got)
+	}
+	got := commitMessage([]string{`Add note "Plan"`, `Complete task "ship it"`})
+	want := "Update notes: 2 changes\n\n- Add note \"Plan\"\n- Complete task \"ship it\""
+	if got != want {
+		t.Errorf("two changes:\n%s\nwant:\n%s", got, want)
+	}
+	task := &models.Task{Text: "ship it", Checked: true}
+	if got := describeChange(Event{Type: EventTaskToggled, Task: task}); got != `Complete task "ship it"` {
+		t.Errorf("describeChange = %q", got)
+	}
+	if got := describeChange(Event{Type: EventTasksSynced}); got != "" {
+		t.Errorf("tasks.synced described as %q", got)
+	}
+}
+
+func TestGitSyncCommitsPendingChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewGitSyncService(mgr, &models.FolderConfig{Git: &models.GitFolderConfig{AutoCommit: true}})
+	if !s.Enabled() {
+		t.Fatal("git sync not enabled in a repository")
+	}
+	events := NewEventHub()
+	mgr.SetEvents(events)
+
+	if err := mgr.AddNote("Plan", "- [ ] ship it"); err != nil {
+		t.Fatal(err)
+	}
+	s.changed(describeChange(Event{Type: EventNoteCreated, Title: "Plan"}))
+	s.changed(describeChange(Event{Type: EventNoteCreated, Title: "Plan"}))
+	if err := s.commitPending(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	commits, err := s.Log(context.Background(), 10)
+	if err != nil || len(commits) != 1 || commits[0].Subject != `Add note "Plan"` {
+		t.Fatalf("Log = %+v, %v", commits, err)
+	}
+	out, _ := exec.Command("git", "-C", dir, "show", "--name-only", "--format=", "HEAD").Output()
+	if strings.TrimSpace(string(out)) != "notes.md" {
+		t.Errorf("committed files: %s", out)
+	}
+}
+
+func TestGitSyncDisabledOutsideRepo(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewGitSyncService(mgr, &models.FolderConfig{Git: &models.GitFolderConfig{AutoCommit: true}})
+	if s.Enabled() {
+		t.Error("git sync enabled outside a repository")
+	}
+	if _, err := s.Log(context.Background(), 1); err != ErrGitNotConfigured {
+		t.Errorf("Log: err = %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// ErrInvalidTaskQuery is returned for a filter, sort order or page
+// QueryGlobalTasks doesn't understand.
+var ErrInvalidTaskQuery = errors.New("invalid task query")
+
+// GlobalTaskQuery narrows, orders and pages the global task list. Every
+// field is optional.
+type GlobalTaskQuery struct {
+	// Folder is a folder ID or an exact folder path.
+	Folder string
+	// Group keeps the folders given that group with SetFolderLabels.
+	Group     string
+	Completed *bool
+	// Search keeps tasks whose text contains it, ignoring case.
+	Search string
+	// Due is a models.MatchesDueFilter token: today, week, overdue, none
+	// or YYYY-MM-DD.
+	Due string
+	// DueFrom and DueTo are an inclusive YYYY-MM-DD range for the due day.
+	DueFrom string
+	DueTo   string
+	// Sort is due, updated, text or folder, with a leading "-" for
+	// descending. Empty keeps the default folder order.
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+// QueryGlobalTasks is GetGlobalTasks with q pushed down into the task DB.
+// Total counts every matching task, not just the page returned; summaries
+// still cover every task.
+func (trs *TaskRegistryService) QueryGlobalTasks(q GlobalTaskQuery, now time.Time) (*models.GlobalTasksResponse, error) {
+	f, err := q.filter(now)
+	if err != nil {
+		return nil, err
+	}
+	return trs.db.QueryTasks(f)
+}
+
+// filter translates q into a TaskFilter, resolving relative due filters
+// against now.
+func (q GlobalTaskQuery) filter(now time.Time) (TaskFilter, error) {
+	invalid := func(format string, args ...any) (TaskFilter, error) {
+		return TaskFilter{}, fmt.Errorf("%w: %s", ErrInvalidTaskQuery, fmt.Sprintf(format, args...))
+	}
+	f := TaskFilter{Group: q.Group, Completed: q.Completed, Search: q.Search, Limit: q.Limit, Offset: q.Offset}
+	if q.Limit < 0 || q.Offset < 0 {
+		return invalid("limit and offset must not be negative")
+	}
+	if id, err := strconv.Atoi(q.Folder); err == nil {
+		f.FolderID = id
+	} else {
+		f.FolderPath = q.Folder
+	}
+	f.Sort, f.Desc = strings.CutPrefix(q.Sort, "-")
+	if _, ok := taskSortColumns[f.Sort]; f.Sort != "" && !ok {
+		return invalid("unknown sort %q (want due, updated, text or folder)", q.Sort)
+	}
+
+	// Work in whole days: [from, before).
+	var from, before time.Time
+	narrow := func(lo, hi time.Time) {
+		if !lo.IsZero() && (from.IsZero() || lo.After(from)) {
+			from = lo
+		}
+		if !hi.IsZero() && (before.IsZero() || hi.Before(before)) {
+			before = hi
+		}
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch q.Due {
+	case "":
+	case "today":
+		narrow(today, today.AddDate(0, 0, 1))
+	case "week":
+		narrow(today, today.AddDate(0, 0, 7))
+	case "overdue":
+		if q.Completed != nil && *q.Completed {
+			return invalid("overdue tasks are never completed")
+		}
+		open := false
+		f.Completed = &open
+		narrow(time.Time{}, today)
+	case "none":
+		f.DueNone = true
+	default:
+		day, err := time.Parse("2006-01-02", q.Due)
+		if err != nil {
+			return invalid("invalid due filter %q (want today|week|overdue|none|YYYY-MM-DD)", q.Due)
+		}
+		narrow(day, day.AddDate(0, 0, 1))
+	}
+	for _, bound := range []struct {
+		value string
+		lo    bool
+	}{{q.DueFrom, true}, {q.DueTo, false}} {
+		if bound.value == "" {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", bound.value)
+		if err != nil {
+			return invalid("invalid date %q (want YYYY-MM-DD)", bound.value)
+		}
+		if bound.lo {
+			narrow(day, time.Time{})
+		} else {
+			narrow(time.Time{}, day.AddDate(0, 0, 1))
+		}
+	}
+	if !from.IsZero() {
+		f.DueFrom = from.Format("2006-01-02")
+	}
+	if !before.IsZero() {
+		f.DueBefore = before.Format("2006-01-02")
+	}
+	return f, nil
+}
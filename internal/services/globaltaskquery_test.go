@@ -0,0 +1,203 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestGlobalTasks_DueDateColumnAndOverdue(t *testing.T) {
+	svc, folder := newTestDB(t)
+	var tasks []models.Task
+	for _, text := range []string{
+		"- [ ] no date",
+		"- [ ] later 📅 2999-01-02",
+		"- [ ] past @due(2020-03-04)",
+		"- [x] done past @2020-03-04",
+	} {
+		task := models.Task{Text: text, Checked: text[3] == 'x'}
+		_, task.DueDate, _ = models.ParseTaskMetadata(text)
+		tasks = append(tasks, task)
+	}
+	if err := svc.SyncFolderTasks(folder.ID, tasks); err != nil {
+		t.Fatal(err)
+	}
+
+	var stored []string
+	rows, err := svc.db.Query(`SELECT COALESCE(due_date, '') FROM tasks ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d string
+		rows.Scan(&d)
+		stored = append(stored, d)
+	}
+	if want := []string{"", "2999-01-02", "2020-03-04", "2020-03-04"}; !reflect.DeepEqual(stored, want) {
+		t.Errorf("due_date column = %q, want %q", stored, want)
+	}
+
+	res, err := svc.GetGlobalTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	overdue := map[string]bool{}
+	for _, task := range res.Tasks {
+		overdue[task.Content] = task.Overdue
+	}
+	if !overdue["- [ ] past @due(2020-03-04)"] || overdue["- [x] done past @2020-03-04"] || overdue["- [ ] later 📅 2999-01-02"] {
+		t.Errorf("overdue flags = %v", overdue)
+	}
+}
+
+func TestQueryGlobalTasks(t *testing.T) {
+	svc, folder := newTestDB(t)
+	other, err := svc.RegisterFolder("/tmp/other-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := func(text string) models.Task {
+		task := models.Task{Text: text, Checked: strings.HasPrefix(text, "[x]")}
+		_, task.DueDate, _ = models.ParseTaskMetadata(text)
+		return task
+	}
+	if err := svc.SyncFolderTasks(folder.ID, []models.Task{
+		task("[ ] none"),
+		task("[ ] next week @2026-10-20"),
+		task("[ ] yesterday @2026-10-15"),
+		task("[ ] today @2026-10-16T09:30"),
+		task("[x] done yesterday @2026-10-15"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SyncFolderTasks(other.ID, []models.Task{task("[ ] elsewhere 50%_off @2026-10-17")}); err != nil {
+		t.Fatal(err)
+	}
+	trs := &TaskRegistryService{db: svc}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	yes, no := true, false
+
+	contents := func(tasks []models.GlobalTask) []string {
+		var out []string
+		for _, t := range tasks {
+			out = append(out, strings.Fields(t.Content[4:])[0])
+		}
+		return out
+	}
+	tests := []struct {
+		q    GlobalTaskQuery
+		want []string
+	}{
+		{GlobalTaskQuery{Folder: "/tmp/test-project", Sort: "due"}, []string{"yesterday", "done", "today", "next", "none"}},
+		{GlobalTaskQuery{Due: "overdue"}, []string{"yesterday"}},
+		{GlobalTaskQuery{Due: "week", Sort: "due", Folder: strconv.Itoa(folder.ID)}, []string{"today", "next"}},
+		{GlobalTaskQuery{Due: "none"}, []string{"none"}},
+		{GlobalTaskQuery{Due: "2026-10-20"}, []string{"next"}},
+		{GlobalTaskQuery{DueFrom: "2026-10-16", DueTo: "2026-10-17", Sort: "-due"}, []string{"elsewhere", "today"}},
+		{GlobalTaskQuery{Due: "week", DueTo: "2026-10-16"}, []string{"today"}},
+		{GlobalTaskQuery{Completed: &yes}, []string{"done"}},
+		{GlobalTaskQuery{Completed: &no, Search: "YESTER"}, []string{"yesterday"}},
+		{GlobalTaskQuery{Search: "%_"}, []string{"elsewhere"}},
+		// Open tasks sort first: "[ ]" < "[x]".
+		{GlobalTaskQuery{Sort: "text", Limit: 2, Offset: 1}, []string{"next", "none"}},
+	}
+	for _, tt := range tests {
+		got, err := trs.QueryGlobalTasks(tt.q, now)
+		if err != nil {
+			t.Errorf("%+v: %v", tt.q, err)
+			continue
+		}
+		if g := contents(got.Tasks); !reflect.DeepEqual(g, tt.want) {
+			t.Errorf("%+v = %v, want %v", tt.q, g, tt.want)
+		}
+	}
+
+	page, err := trs.QueryGlobalTasks(GlobalTaskQuery{Limit: 2}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Tasks) != 2 || page.Total != 6 {
+		t.Errorf("page: %d tasks, total %d; want 2 of 6", len(page.Tasks), page.Total)
+	}
+
+	for _, q := range []GlobalTaskQuery{
+		{Due: "someday"}, {Sort: "title"}, {DueFrom: "10/16"}, {Limit: -1},
+		{Due: "overdue", Completed: &yes},
+	} {
+		if _, err := trs.QueryGlobalTasks(q, now); !errors.Is(err, ErrInvalidTaskQuery) {
+			t.Errorf("%+v: err = %v", q, err)
+		}
+	}
+}
+
+func TestSetFolderLabels_GroupsGlobalTasks(t *testing.T) {
+	svc, plain := newTestDB(t)
+	work, err := svc.RegisterFolder("/tmp/zz-client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	home, err := svc.RegisterFolder("/tmp/aa-garden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []*models.FolderRegistry{plain, work, home} {
+		if err := svc.SyncFolderTasks(f.ID, []models.Task{{Text: "- [ ] task in " + f.Path}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := svc.SetFolderLabels(work.ID, " Acme ", "Work"); err != nil {
+		t.Fatal(err)
+	}
+	labelled, err := svc.SetFolderLabels(home.ID, "", "Home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labelled.Alias != "" || labelled.Group != "Home" {
+		t.Errorf("labelled folder = %+v", labelled)
+	}
+	if _, err := svc.SetFolderLabels(999, "x", ""); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("unknown folder: err = %v, want sql.ErrNoRows", err)
+	}
+
+	res, err := svc.GetGlobalTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range res.Summaries {
+		names = append(names, s.FolderGroup+"/"+s.FolderName)
+	}
+	if want := []string{"/test-project", "Home/aa-garden", "Work/Acme"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("summaries = %q, want %q", names, want)
+	}
+	if len(res.Tasks) != 3 || res.Tasks[2].FolderName != "Acme" || res.Tasks[2].FolderGroup != "Work" {
+		t.Errorf("tasks not in group order: %+v", res.Tasks)
+	}
+	if len(res.Groups) != 3 || res.Groups[2].Group != "Work" || res.Groups[2].PendingTasks != 1 {
+		t.Errorf("groups = %+v", res.Groups)
+	}
+
+	only, err := svc.QueryTasks(TaskFilter{Group: "Home"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if only.Total != 1 || only.Tasks[0].FolderID != home.ID {
+		t.Errorf("group filter = %+v", only.Tasks)
+	}
+
+	// Clearing the labels puts the folder back under its directory name.
+	if _, err := svc.SetFolderLabels(work.ID, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	folder, err := svc.GetFolderByID(work.ID)
+	if err != nil || folder.Alias != "" || folder.Group != "" {
+		t.Errorf("cleared folder = %+v, %v", folder, err)
+	}
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/gtasks"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+const defaultGoogleTasksInterval = 5 * time.Minute
+
+// GoogleTasksService mirrors a folder's tasks into a Google Tasks list so
+// they show up on Android and in Gmail's sidebar. notes.md stays the source
+// of truth for task text and due dates; only completions flow both ways.
+// Like the Todoist sync, the link to the remote task is appended to the
+// task line and a machine-local snapshot drives the merge (tasksync.go).
+type GoogleTasksService struct {
+	noteManager *NoteManager
+	client      *gtasks.Client
+	folder      *models.GoogleTasksFolderConfig
+	authorized  bool
+	statePath   string
+	mu          sync.Mutex // serializes Sync runs
+	stop        chan struct{}
+}
+
+// NewGoogleTasksService creates the service. Sync state is kept under
+// stateDir (normally ~/.config/noteflow/google-tasks).
+func NewGoogleTasksService(noteManager *NoteManager, cfg models.GoogleConfig, folderCfg *models.FolderConfig, stateDir string) *GoogleTasksService {
+	var folder *models.GoogleTasksFolderConfig
+	if folderCfg != nil {
+		folder = folderCfg.GoogleTasks
+	}
+	tokens := gtasks.NewTokenSource(cfg.ClientID, cfg.ClientSecret, cfg.RefreshToken)
+	return &GoogleTasksService{
+		noteManager: noteManager,
+		client:      gtasks.NewClient(cfg.TasksAPI, tokens),
+		folder:      folder,
+		authorized:  cfg.Authorized(),
+		statePath:   syncStatePath(stateDir, noteManager.GetBasePath()),
+	}
+}
+
+// Enabled reports whether this folder mirrors to Google Tasks and the user
+// has completed `noteflow-go google-auth`.
+func (s *GoogleTasksService) Enabled() bool {
+	return s.folder != nil && s.authorized
+}
+
+func (s *GoogleTasksService) list() string {
+	if s.folder.ListID == "" {
+		return gtasks.DefaultList
+	}
+	return s.folder.ListID
+}
+
+// Start runs Sync immediately and then on a ticker until Stop is called.
+func (s *GoogleTasksService) Start() {
+	if !s.Enabled() || s.stop != nil {
+		return
+	}
+	interval := defaultGoogleTasksInterval
+	if s.folder.IntervalMinutes > 0 {
+		interval = time.Duration(s.folder.IntervalMinutes) * time.Minute
+	}
+	s.stop = make(chan struct{})
+	go func(stop <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			if _, err := s.Sync(ctx); err != nil {
+				log.Printf("Warning: Google Tasks sync failed: %v", err)
+			}
+			cancel()
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}(s.stop)
+}
+
+// Stop stops the background loop started by Start.
+func (s *GoogleTasksService) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// Sync runs one mirror pass:
+//
+//   - unlinked, unchecked tasks (carrying #tag when one is configured) are
+//     added to the list and the task link is appended to their line;
+//   - linked tasks whose text or due date differ in Google are overwritten
+//     from notes.md;
+//   - a completion made on either side is applied to the other; when both
+//     sides flipped since the last sync the most recent change wins.
+//
+// Tasks deleted in Google are left untouched in notes.md.
+func (s *GoogleTasksService) Sync(ctx context.Context) (*TaskSyncResult, error) {
+	if !s.Enabled() {
+		return nil, fmt.Errorf("Google Tasks is not set up for this folder (run 'noteflow-go google-auth' and set google_tasks in %s)", models.FolderConfigFile)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := loadSyncState[syncFields](s.statePath)
+	if err != nil {
+		return nil, err
+	}
+	remoteTasks, err := s.client.ListTasks(ctx, s.list())
+	if err != nil {
+		return nil, err
+	}
+	remoteByLink := make(map[string]gtasks.Task, len(remoteTasks))
+	for _, t := range remoteTasks {
+		if !t.Deleted {
+			remoteByLink[t.Link()] = t
+		}
+	}
+
+	res := &TaskSyncResult{}
+	localModified := s.noteManager.LastModified()
+	for _, task := range s.noteManager.GetAllTasks() {
+		local := taskSyncFields(task, gtasks.StripTaskLink)
+		link, linked := gtasks.ParseTaskLink(task.Text)
+		if !linked {
+			if task.Checked || !hasTag(task, s.folder.Tag) {
+				continue
+			}
+			created, err := s.client.InsertTask(ctx, s.list(), gtasks.Task{Title: local.Content, Due: gtasks.DueValue(local.Due)})
+			if err != nil {
+				return res, err
+			}
+			link = created.Link()
+			if err := s.noteManager.UpdateTaskText(task.Index, strings.TrimRight(task.Text, " ")+" "+link); err != nil {
+				return res, err
+			}
+			state[link] = local
+			res.Created++
+			continue
+		}
+
+		remote, ok := remoteByLink[link]
+		if !ok {
+			delete(state, link)
+			continue
+		}
+
+		patch := map[string]any{}
+		if remote.Title != local.Content {
+			patch["title"] = local.Content
+		}
+		if remote.DueDate() != local.Due {
+			if local.Due == "" {
+				patch["due"] = nil
+			} else {
+				patch["due"] = gtasks.DueValue(local.Due)
+			}
+		}
+		checked := resolveField(local.Checked, remote.Completed(), state[link].Checked, localModified.After(remote.Updated))
+		if checked != remote.Completed() {
+			patch["status"] = gtasks.StatusNeedsAction
+			if checked {
+				patch["status"] = gtasks.StatusCompleted
+			} else {
+				patch["completed"] = nil
+			}
+		}
+		if len(patch) > 0 {
+			if err := s.client.PatchTask(ctx, s.list(), remote.ID, patch); err != nil {
+				return res, err
+			}
+			res.Pushed++
+		}
+		if checked != local.Checked {
+			if err := s.noteManager.UpdateTask(task.Index, checked); err != nil {
+				return res, err
+			}
+			res.Pulled++
+		}
+		local.Checked = checked
+		state[link] = local
+	}
+
+	return res, saveSyncState(s.statePath, state)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/gtasks"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// fakeGoogleTasks is an in-memory task list; it also answers token refreshes.
+type fakeGoogleTasks struct {
+	mu    sync.Mutex
+	tasks map[string]*gtasks.Task
+}
+
+func (f *fakeGoogleTasks) server(t *testing.T) *httptest.Server {
+	f.tasks = make(map[string]*gtasks.Task)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.URL.Path == "/token" {
+			w.Write([]byte(`{"access_token":"at","expires_in":3600}`))
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			var items []gtasks.Task
+			for _, task := range f.tasks {
+				items = append(items, *task)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"items": items})
+		case http.MethodPost:
+			var task gtasks.Task
+			json.NewDecoder(r.Body).Decode(&task)
+			task.ID = fmt.Sprintf("g%d", len(f.tasks)+1)
+			task.Status = gtasks.StatusNeedsAction
+			task.Updated = time.Now()
+			f.tasks[task.ID] = &task
+			json.NewEncoder(w).Encode(task)
+		case http.MethodPatch:
+			var patch map[string]any
+			json.NewDecoder(r.Body).Decode(&patch)
+			task := f.tasks[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]
+			if v, ok := patch["title"].(string); ok {
+				task.Title = v
+			}
+			if v, ok := patch["status"].(string); ok {
+				task.Status = v
+			}
+			task.Updated = time.Now()
+			w.Write([]byte(`{}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	orig := gtasks.TokenEndpoint
+	gtasks.TokenEndpoint = srv.URL + "/token"
+	t.Cleanup(func() { gtasks.TokenEndpoint = orig })
+	return srv
+}
+
+func TestGoogleTasksSync_MirrorsAndPullsCompletions(t *testing.T) {
+	fake := &fakeGoogleTasks{}
+	srv := fake.server(t)
+
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("errands", "- [ ] renew passport @2026-11-02\n- [x] already done"); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewGoogleTasksService(mgr,
+		models.GoogleConfig{ClientID: "id", ClientSecret: "s", RefreshToken: "rt", TasksAPI: srv.URL},
+		&models.FolderConfig{GoogleTasks: &models.GoogleTasksFolderConfig{}}, t.TempDir())
+	ctx := context.Background()
+
+	res, err := svc.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if res.Created != 1 || len(fake.tasks) != 1 {
+		t.Fatalf("created = %d, remote = %d; want only the open task mirrored", res.Created, len(fake.tasks))
+	}
+	if g := fake.tasks["g1"]; g.Title != "renew passport" || g.DueDate() != "2026-11-02" {
+		t.Errorf("remote = %+v", g)
+	}
+	task, _ := mgr.GetTask(0)
+	if !strings.Contains(task.Text, "https://tasks.google.com/task/g1") {
+		t.Fatalf("task not linked: %q", task.Text)
+	}
+
+	// Completed on the phone: reflected back into notes.md.
+	fake.mu.Lock()
+	fake.tasks["g1"].Status = gtasks.StatusCompleted
+	fake.tasks["g1"].Updated = time.Now().Add(time.Hour)
+	fake.mu.Unlock()
+	if _, err := svc.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if task, _ = mgr.GetTask(0); !task.Checked {
+		t.Errorf("task not checked after remote completion: %+v", task)
+	}
+
+	// Text stays owned by notes.md: a rename in Google is overwritten.
+	fake.mu.Lock()
+	fake.tasks["g1"].Title = "renamed on phone"
+	fake.mu.Unlock()
+	if _, err := svc.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if fake.tasks["g1"].Title != "renew passport" {
+		t.Errorf("remote title = %q, want notes.md text", fake.tasks["g1"].Title)
+	}
+}
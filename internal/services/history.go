@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/diff"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// ErrRevisionNotFound is returned for a version spec that names no revision.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// saveRevision records note's current version in its history before it is
+// replaced by title/content. Unchanged saves record nothing. History is a
+// convenience, so a failed write is logged rather than failing the edit.
+// Callers hold nm.mu.
+func (nm *NoteManager) saveRevision(note *models.Note, title, content string) {
+	if note.Title == title && note.Content == content {
+		return
+	}
+	rev := models.Revision{Saved: time.Now(), Title: note.Title, Content: note.Content}
+	if _, err := nm.storage.SaveRevision(note.HistoryKey(), rev); err != nil {
+		log.Printf("Warning: failed to save note history: %v", err)
+	}
+}
+
+// NoteDiff is a word-level comparison of two versions of one note.
+type NoteDiff struct {
+	From string    `json:"from"` // revision ID, or "current"
+	To   string    `json:"to"`
+	Ops  []diff.Op `json:"ops"`
+	HTML string    `json:"html"` // side-by-side rendering of Ops
+}
+
+// DiffNote compares two versions of the note at index. Each version is
+// one of:
+//
+//	"current"     the note as it is now
+//	<revision>    a revision ID from the note's history
+//	YYYY-MM-DD    the note as it stood at the start of that (local) day
+//
+// An empty from means the revision before the latest edit; an empty to
+// means "current".
+func (nm *NoteManager) DiffNote(index int, from, to string) (*NoteDiff, error) {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	if index < 0 || index >= len(nm.notes) {
+		return nil, fmt.Errorf("note index %d out of range", index)
+	}
+	note := nm.notes[index]
+	revs, err := nm.storage.ListRevisions(note.HistoryKey())
+	if err != nil {
+		return nil, err
+	}
+
+	if from == "" {
+		if len(revs) == 0 {
+			return nil, fmt.Errorf("%w: note has no earlier versions", ErrRevisionNotFound)
+		}
+		from = revs[len(revs)-1].ID
+	}
+	if to == "" {
+		to = "current"
+	}
+	fromID, fromText, err := resolveVersion(note, revs, from)
+	if err != nil {
+		return nil, err
+	}
+	toID, toText, err := resolveVersion(note, revs, to)
+	if err != nil {
+		return nil, err
+	}
+
+	ops := diff.Words(fromText, toText)
+	return &NoteDiff{From: fromID, To: toID, Ops: ops, HTML: diff.SideBySideHTML(ops)}, nil
+}
+
+// resolveVersion turns a version spec (see DiffNote) into a revision ID and
+// the note text at that version. revs is oldest first.
+func resolveVersion(note *models.Note, revs []models.Revision, spec string) (string, string, error) {
+	if spec == "current" {
+		return "current", note.Content, nil
+	}
+	if day, err := time.ParseInLocation("2006-01-02", spec, time.Local); err == nil {
+		// A revision holds the text that was live until it was saved, so
+		// the version at a moment is the first revision saved after it.
+		for _, rev := range revs {
+			if rev.Saved.After(day) {
+				return rev.ID, rev.Content, nil
+			}
+		}
+		return "current", note.Content, nil
+	}
+	for _, rev := range revs {
+		if rev.ID == spec {
+			return rev.ID, rev.Content, nil
+		}
+	}
+	return "", "", fmt.Errorf("%w: %s", ErrRevisionNotFound, spec)
+}
+
+// RevisionSummary describes one past version of a note in a history list.
+type RevisionSummary struct {
+	ID    string    `json:"id"`
+	Saved time.Time `json:"saved"` // when this version was replaced
+	Title string    `json:"title"`
+	Size  int       `json:"size"` // content length in bytes
+}
+
+// RevisionDetail is one past version of a note, with a unified diff from
+// it to the note as it is now.
+type RevisionDetail struct {
+	models.Revision
+	Diff string `json:"diff"`
+}
+
+// NoteHistory lists the saved versions of the note at index, newest first.
+func (nm *NoteManager) NoteHistory(index int) ([]RevisionSummary, error) {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	if index < 0 || index >= len(nm.notes) {
+		return nil, fmt.Errorf("note index %d out of range", index)
+	}
+	revs, err := nm.storage.ListRevisions(nm.notes[index].HistoryKey())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]RevisionSummary, 0, len(revs))
+	for i := len(revs) - 1; i >= 0; i-- {
+		r := revs[i]
+		out = append(out, RevisionSummary{ID: r.ID, Saved: r.Saved, Title: r.Title, Size: len(r.Content)})
+	}
+	return out, nil
+}
+
+// NoteRevision returns one saved version of the note at index and the
+// unified diff turning it into the current text.
+func (nm *NoteManager) NoteRevision(index int, id string) (*RevisionDetail, error) {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	note, rev, err := nm.loadRevision(index, id)
+	if err != nil {
+		return nil, err
+	}
+	return &RevisionDetail{
+		Revision: *rev,
+		Diff:     diff.Unified(rev.Content, note.Content, "revision "+rev.ID, "current"),
+	}, nil
+}
+
+// RestoreRevision makes a saved version the note's current title and
+// content. The version being replaced goes into the history first, so a
+// restore can itself be undone. Content is restored verbatim: links and
+// snippets in it were already processed when it was first saved.
+func (nm *NoteManager) RestoreRevision(index int, id string) error {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	note, rev, err := nm.loadRevision(index, id)
+	if err != nil {
+		return err
+	}
+	nm.saveRevision(note, rev.Title, rev.Content)
+	note.Update(rev.Title, rev.Content)
+	nm.assignTaskIndices()
+	nm.needsSave = true
+	return nm.save()
+}
+
+// loadRevision finds the note at index and one of its revisions. Callers
+// hold nm.mu.
+func (nm *NoteManager) loadRevision(index int, id string) (*models.Note, *models.Revision, error) {
+	if index < 0 || index >= len(nm.notes) {
+		return nil, nil, fmt.Errorf("note index %d out of range", index)
+	}
+	note := nm.notes[index]
+	rev, err := nm.storage.LoadRevision(note.HistoryKey(), id)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("%w: %s", ErrRevisionNotFound, id)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return note, rev, nil
+}
+
+// MergeNote merges an edit that started from version of the note at index
+// into the note as it is now, line by line, without saving it. The
+// version is looked up in the note's history; ErrRevisionNotFound means
+// it isn't there and the edit can't be merged. The title is merged as a
+// single line; when both sides changed it, the edit's title is kept and
+// counted as a conflict.
+func (nm *NoteManager) MergeNote(index int, version, title, content string) (*models.NoteMerge, error) {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	if index < 0 || index >= len(nm.notes) {
+		return nil, fmt.Errorf("note index %d out of range", index)
+	}
+	note := nm.notes[index]
+	current := models.NoteMerge{Title: note.Title, Content: note.Content, Version: note.Version()}
+	if version == current.Version {
+		current.Title, current.Content = title, content
+		return &current, nil
+	}
+	revs, err := nm.storage.ListRevisions(note.HistoryKey())
+	if err != nil {
+		return nil, err
+	}
+	for i := len(revs) - 1; i >= 0; i-- {
+		base := revs[i]
+		if models.NoteVersion(base.Title, base.Content) != version {
+			continue
+		}
+		merged := current
+		merged.Content, merged.Conflicts = diff.Merge(base.Content, content, note.Content, "yours", "current")
+		switch {
+		case title == base.Title || title == note.Title:
+		case note.Title == base.Title:
+			merged.Title = title
+		default:
+			merged.Title = title
+			merged.Conflicts++
+		}
+		return &merged, nil
+	}
+	return nil, fmt.Errorf("%w: version %s", ErrRevisionNotFound, version)
+}
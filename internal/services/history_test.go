@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffNote_VersionSpecs(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Plan", "draft one"); err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"draft two", "draft two", "final"} {
+		if err := mgr.UpdateNote(0, "Plan", content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The unchanged save above must not have produced a revision.
+	revs, err := mgr.storage.ListRevisions(mgr.GetAllNotes()[0].HistoryKey())
+	if err != nil || len(revs) != 2 || revs[0].Content != "draft one" || revs[1].Content != "draft two" {
+		t.Fatalf("revisions = %+v, %v", revs, err)
+	}
+
+	d, err := mgr.DiffNote(0, revs[0].ID, "")
+	if err != nil {
+		t.Fatalf("DiffNote: %v", err)
+	}
+	if d.From != revs[0].ID || d.To != "current" || len(d.Ops) != 2 || d.Ops[0].Text != "draft one" || d.Ops[1].Text != "final" {
+		t.Errorf("diff = %+v", d)
+	}
+
+	// A date before any edit resolves to the oldest version; today's
+	// midnight is also before every edit made by this test.
+	today := time.Now().Format("2006-01-02")
+	if d, err := mgr.DiffNote(0, today, "current"); err != nil || d.From != revs[0].ID {
+		t.Errorf("diff from %s = %+v, %v", today, d, err)
+	}
+
+	if _, err := mgr.DiffNote(0, "12345", ""); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("unknown revision: err = %v", err)
+	}
+}
+
+func TestNoteHistoryAndRestore(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Plan", "- [ ] draft\n- [ ] review"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.UpdateNote(0, "Plan v2", "- [ ] draft\n- [ ] review\n- [ ] ship"); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := mgr.NoteHistory(0)
+	if err != nil || len(history) != 1 || history[0].Title != "Plan" {
+		t.Fatalf("history = %+v, %v", history, err)
+	}
+	rev, err := mgr.NoteRevision(0, history[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.Content != "- [ ] draft\n- [ ] review" || !strings.Contains(rev.Diff, "\n+- [ ] ship") {
+		t.Errorf("revision = %+v", rev)
+	}
+
+	if err := mgr.RestoreRevision(0, history[0].ID); err != nil {
+		t.Fatalf("RestoreRevision: %v", err)
+	}
+	note, _ := mgr.GetNote(0)
+	if note.Title != "Plan" || len(note.Tasks) != 2 {
+		t.Errorf("restored note = %+v", note)
+	}
+	// The restore is itself undoable: the replaced version is in history.
+	history, _ = mgr.NoteHistory(0)
+	if len(history) != 2 || history[0].Title != "Plan v2" {
+		t.Errorf("history after restore = %+v", history)
+	}
+
+	if _, err := mgr.NoteRevision(0, "42"); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("unknown revision: err = %v", err)
+	}
+	if err := mgr.RestoreRevision(0, "../../notes"); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("bad revision id: err = %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// IdempotencyTTL is how long the result of a request made with an
+// Idempotency-Key answers retries of it.
+const IdempotencyTTL = 24 * time.Hour
+
+// ErrIdempotencyInFlight is returned by Begin while a request with the
+// same key is still being handled.
+var ErrIdempotencyInFlight = errors.New("a request with this Idempotency-Key is in progress")
+
+// StoredResponse is the result of a request made with an Idempotency-Key.
+type StoredResponse struct {
+	// RequestHash identifies the request the key was first used with, so
+	// the key can't be reused for a different one.
+	RequestHash string
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyStore keeps the results of one notes folder's requests made
+// with an Idempotency-Key, so a client retrying after a dropped connection
+// gets the first result instead of repeating the request. Results live in
+// the task database for IdempotencyTTL.
+type IdempotencyStore struct {
+	db     *DatabaseService
+	folder string
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+}
+
+// Idempotency returns the Idempotency-Key store of folder, kept in the
+// registry's database under its user.
+func (trs *TaskRegistryService) Idempotency(folder string) *IdempotencyStore {
+	return &IdempotencyStore{db: trs.db, folder: folder, inFlight: make(map[string]bool)}
+}
+
+// Begin returns the stored result for key, or nil when there is none and
+// the caller should handle the request and then call End. A key whose
+// request is still being handled is ErrIdempotencyInFlight.
+func (s *IdempotencyStore) Begin(key string, now time.Time) (*StoredResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inFlight[key] {
+		return nil, ErrIdempotencyInFlight
+	}
+	stored, err := s.db.GetIdempotentResponse(s.folder, key, now.Add(-IdempotencyTTL))
+	if err != nil || stored != nil {
+		return stored, err
+	}
+	s.inFlight[key] = true
+	return nil, nil
+}
+
+// End records the result of key's request, or with a nil result only
+// releases the key so the request can be tried again. The request has
+// already happened, so a failure to record it is only logged.
+func (s *IdempotencyStore) End(key string, result *StoredResponse, now time.Time) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.inFlight, key)
+		s.mu.Unlock()
+	}()
+	if result == nil {
+		return
+	}
+	if err := s.db.SaveIdempotentResponse(s.folder, key, *result, now, now.Add(-IdempotencyTTL)); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// GetIdempotentResponse returns the result stored for key in folder since
+// notBefore, or nil.
+func (ds *DatabaseService) GetIdempotentResponse(folder, key string, notBefore time.Time) (*StoredResponse, error) {
+	var r StoredResponse
+	err := ds.db.QueryRow(`
+		SELECT request_hash, status, content_type, body FROM idempotency_keys
+		WHERE user_id = ? AND folder = ? AND key = ? AND created >= ?`,
+		ds.user, folder, key, notBefore.UTC(),
+	).Scan(&r.RequestHash, &r.Status, &r.ContentType, &r.Body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read idempotency key: %w", err)
+	}
+	return &r, nil
+}
+
+// SaveIdempotentResponse stores the result for key in folder, dropping
+// every result stored before expired.
+func (ds *DatabaseService) SaveIdempotentResponse(folder, key string, r StoredResponse, now, expired time.Time) error {
+	if _, err := ds.db.Exec(`DELETE FROM idempotency_keys WHERE created < ?`, expired.UTC()); err != nil {
+		return fmt.Errorf("prune idempotency keys: %w", err)
+	}
+	_, err := ds.db.Exec(`
+		INSERT OR REPLACE INTO idempotency_keys (user_id, folder, key, request_hash, status, content_type, body, created)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		ds.user, folder, key, r.RequestHash, r.Status, r.ContentType, r.Body, now.UTC())
+	if err != nil {
+		return fmt.Errorf("save idempotency key: %w", err)
+	}
+	return nil
+}
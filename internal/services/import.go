@@ -0,0 +1,318 @@
+package services
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+)
+
+// Import modes.
+const (
+	ImportMerge   = "merge"   // add the archive's notes that aren't here yet
+	ImportRestore = "restore" // replace the notes with the archive's
+)
+
+// maxImportFile caps the uncompressed size of one file in an imported
+// archive, so a small zip can't expand without bound. Each file is held in
+// memory while it is imported; this leaves room for a notes.db of a very
+// large collection and for uploads well past the default upload limit.
+const maxImportFile = 128 << 20
+
+// ErrInvalidImport is returned for an archive that can't be imported.
+var ErrInvalidImport = errors.New("invalid import archive")
+
+// ImportResult reports what an import did.
+type ImportResult struct {
+	Mode    string `json:"mode"`
+	Notes   int    `json:"notes"`   // notes in the archive
+	Added   int    `json:"added"`   // notes added; all of them for a restore
+	Skipped int    `json:"skipped"` // notes already here, left alone
+	Files   int    `json:"files"`   // asset files written
+	Reused  int    `json:"reused"`  // asset files already here with the same content
+	// Renamed maps the asset paths the archive used to the ones they were
+	// stored under, for files whose name was taken by other content; the
+	// imported notes' links are rewritten to match.
+	Renamed map[string]string `json:"renamed,omitempty"`
+	// Missing lists the files the imported notes link to or embed that
+	// weren't in what was imported; their links are left as they were.
+	Missing []string `json:"missing,omitempty"`
+	// Tasks counts the to-dos of another app imported as tasks.
+	Tasks int `json:"tasks,omitempty"`
+	// Folders lists the folders an import spanning several wrote to, the
+	// counts above being their totals.
+	Folders []ImportedFolder `json:"folders,omitempty"`
+}
+
+// ImportedFolder is a folder an import wrote notes to.
+type ImportedFolder struct {
+	Name    string `json:"name"` // where the notes came from, e.g. a notebook
+	Path    string `json:"path"`
+	Notes   int    `json:"notes"`
+	Added   int    `json:"added"`
+	Skipped int    `json:"skipped"`
+}
+
+// ImportZip imports an archive made by ExportZip: its notes, in any
+// storage mode but encrypted, and its assets tree. In ImportMerge mode
+// notes that read the same as one already here are skipped and the rest
+// are added in time order; in ImportRestore mode they replace the notes,
+// which go to the backups like any save. Assets are added beside the
+// folder's own (see storage.FileStorage.ImportAsset); hidden directories
+// such as assets/.history are not imported, nor are the other files.
+func (nm *NoteManager) ImportZip(r io.ReaderAt, size int64, mode string) (*ImportResult, error) {
+	if mode == "" {
+		mode = ImportMerge
+	}
+	if mode != ImportMerge && mode != ImportRestore {
+		return nil, fmt.Errorf("unknown import mode %q (want %s or %s)", mode, ImportMerge, ImportRestore)
+	}
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	for _, f := range zr.File {
+		if !filepath.IsLocal(filepath.FromSlash(f.Name)) {
+			return nil, fmt.Errorf("%w: unsafe path %q", ErrInvalidImport, f.Name)
+		}
+		// Checked up front too, so no asset is written before a huge one.
+		if f.UncompressedSize64 > maxImportFile {
+			return nil, fmt.Errorf("%w: %s is too large", ErrInvalidImport, f.Name)
+		}
+	}
+	notes, err := importedNotes(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Mode: mode, Notes: len(notes)}
+	for _, f := range zr.File {
+		if !importedAsset(f) {
+			continue
+		}
+		data, err := readZipFile(f)
+		if err != nil {
+			return nil, err
+		}
+		stored, written, err := nm.storage.ImportAsset(f.Name, data)
+		if err != nil {
+			return nil, err
+		}
+		if stored != f.Name {
+			if result.Renamed == nil {
+				result.Renamed = map[string]string{}
+			}
+			result.Renamed[f.Name] = stored
+		}
+		if written {
+			result.Files++
+		} else {
+			result.Reused++
+		}
+	}
+	relinkAssets(notes, result.Renamed)
+	if err := nm.addImportedNotes(notes, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// addImportedNotes adds notes in result.Mode: in ImportMerge mode the ones
+// that don't read the same as one already here, in time order; in
+// ImportRestore mode all of them, in place of the folder's. It saves when
+// anything changed.
+func (nm *NoteManager) addImportedNotes(notes []*models.Note, result *ImportResult) error {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.refresh()
+	if result.Mode == ImportRestore {
+		nm.notes = notes
+		result.Added = len(notes)
+	} else {
+		have := make(map[string]bool, len(nm.notes))
+		for _, note := range nm.notes {
+			have[note.Render()] = true
+		}
+		for _, note := range notes {
+			if have[note.Render()] {
+				result.Skipped++
+				continue
+			}
+			// Notes are kept newest first.
+			index := sort.Search(len(nm.notes), func(i int) bool {
+				return !nm.notes[i].Timestamp.After(note.Timestamp)
+			})
+			nm.notes = append(nm.notes, nil)
+			copy(nm.notes[index+1:], nm.notes[index:])
+			nm.notes[index] = note
+			result.Added++
+		}
+	}
+	if result.Added == 0 && result.Mode == ImportMerge {
+		return nil
+	}
+	nm.assignTaskIndices()
+	nm.needsSave = true
+	return nm.save()
+}
+
+// importedTagRE matches the runs of another app's tag or label that
+// can't be in a #tag.
+var importedTagRE = regexp.MustCompile(`[^A-Za-z0-9_/-]+`)
+
+// importedTag turns another app's tag or label into a #tag name, "to
+// read" into "to-read", reporting false when nothing of it is left.
+func importedTag(name string) (string, bool) {
+	return models.NormalizeTagName(strings.Trim(importedTagRE.ReplaceAllString(name, "-"), "-/"))
+}
+
+// orderImportedNotes sorts notes newest first, as they are kept, and moves
+// any that shares its timestamp with a newer one a second earlier, so that
+// each keeps its own history.
+func orderImportedNotes(notes []*models.Note) {
+	sort.SliceStable(notes, func(i, j int) bool { return notes[i].Timestamp.After(notes[j].Timestamp) })
+	for i := 1; i < len(notes); i++ {
+		if !notes[i].Timestamp.Before(notes[i-1].Timestamp) {
+			notes[i].Timestamp = notes[i-1].Timestamp.Add(-time.Second)
+		}
+	}
+}
+
+// importedNotes reads the notes of an archive: notes.db, the notes
+// directory or notes.md, whichever the folder it came from kept them in.
+// They are unpacked into a temporary folder and read by the storage
+// layer, so every format reads as it would in place.
+func importedNotes(zr *zip.Reader) ([]*models.Note, error) {
+	tmp, err := os.MkdirTemp("", "noteflow-import-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	found := map[string]bool{}
+	for _, f := range zr.File {
+		var mode string
+		name := f.Name
+		switch {
+		case f.Name == "notes.md":
+			mode = models.NotesStorageMarkdown
+		case f.Name == storage.WebDAVCacheFile:
+			// The local copy of notes kept on a WebDAV server.
+			mode, name = models.NotesStorageMarkdown, "notes.md"
+		case f.Name == storage.NotesDBFile:
+			mode = models.NotesStorageSQLite
+		case f.Name == storage.EncryptedNotesFile:
+			mode = models.NotesStorageEncrypted
+		case path.Dir(f.Name) == storage.NotesDir && strings.EqualFold(path.Ext(f.Name), ".md"):
+			mode = models.NotesStorageFiles
+		default:
+			continue
+		}
+		found[mode] = true
+		if mode == models.NotesStorageEncrypted {
+			continue
+		}
+		data, err := readZipFile(f)
+		if err != nil {
+			return nil, err
+		}
+		p := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(p, data, 0600); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, mode := range []string{models.NotesStorageSQLite, models.NotesStorageFiles, models.NotesStorageMarkdown} {
+		if !found[mode] {
+			continue
+		}
+		fs := storage.NewFileStorageMode(tmp, mode)
+		notes, err := fs.LoadNotes()
+		fs.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		return notes, nil
+	}
+	if found[models.NotesStorageEncrypted] {
+		return nil, fmt.Errorf("%w: the notes are encrypted; run 'noteflow storage markdown' in the folder it came from and export again", ErrInvalidImport)
+	}
+	return nil, fmt.Errorf("%w: no notes.md, %s or %s/ in the archive", ErrInvalidImport, storage.NotesDBFile, storage.NotesDir)
+}
+
+// importedAsset reports whether f is a file of the assets tree to import:
+// not a directory, and not under a hidden directory such as
+// assets/.history, which belongs to the folder it came from.
+func importedAsset(f *zip.File) bool {
+	if !strings.HasPrefix(f.Name, "assets/") || f.FileInfo().IsDir() {
+		return false
+	}
+	for _, part := range strings.Split(f.Name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	return true
+}
+
+// readZipFile reads f, refusing one bigger than maxImportFile.
+func readZipFile(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > maxImportFile {
+		return nil, fmt.Errorf("%w: %s is too large", ErrInvalidImport, f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidImport, f.Name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxImportFile+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidImport, f.Name, err)
+	}
+	if len(data) > maxImportFile {
+		return nil, fmt.Errorf("%w: %s is too large", ErrInvalidImport, f.Name)
+	}
+	return data, nil
+}
+
+// relinkAssets rewrites the links in notes to assets that were stored
+// under another name, as they are written raw or URL-escaped.
+func relinkAssets(notes []*models.Note, renamed map[string]string) {
+	if len(renamed) == 0 {
+		return
+	}
+	// Longer paths first, so one that starts with another wins.
+	froms := make([]string, 0, len(renamed))
+	for from := range renamed {
+		froms = append(froms, from)
+	}
+	sort.Slice(froms, func(i, j int) bool { return len(froms[i]) > len(froms[j]) })
+	var pairs []string
+	for _, from := range froms {
+		to := renamed[from]
+		pairs = append(pairs, "/"+from, "/"+to)
+		if escaped := (&url.URL{Path: "/" + from}).EscapedPath(); escaped != "/"+from {
+			pairs = append(pairs, escaped, (&url.URL{Path: "/" + to}).EscapedPath())
+		}
+	}
+	replacer := strings.NewReplacer(pairs...)
+	for _, note := range notes {
+		if content := replacer.Replace(note.Content); content != note.Content {
+			note.Update(note.Title, content)
+		}
+	}
+}
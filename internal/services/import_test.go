@@ -0,0 +1,135 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// exportedZip returns exportFolder as ExportZip writes it.
+func exportedZip(t *testing.T) []byte {
+	t.Helper()
+	mgr, err := NewNoteManager(exportFolder(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := mgr.ExportZip(&buf, ExportOptions{Format: ExportFormatZip}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImportZip_Merge(t *testing.T) {
+	archive := exportedZip(t)
+	dir := t.TempDir()
+	// One of the archive's notes is already here, and so is another
+	// chart.png.
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte("## 2026-05-11 09:00:00 - Ideas\n\n- [ ] try it\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "assets", "images"), 0755)
+	os.WriteFile(filepath.Join(dir, "assets", "images", "chart.png"), []byte("other png"), 0644)
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := mgr.ImportZip(bytes.NewReader(archive), int64(len(archive)), "")
+	if err != nil {
+		t.Fatalf("ImportZip: %v", err)
+	}
+	if result.Added != 1 || result.Skipped != 1 || result.Files != 2 {
+		t.Errorf("result = %+v", result)
+	}
+	renamed := result.Renamed["assets/images/chart.png"]
+	if !strings.HasPrefix(renamed, "assets/images/chart-") {
+		t.Fatalf("chart.png stored as %q", renamed)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "assets", "images", "chart.png")); string(data) != "other png" {
+		t.Errorf("chart.png overwritten: %q", data)
+	}
+	notes := mgr.GetAllNotes()
+	if len(notes) != 2 || notes[0].Title != "Plan" || !strings.Contains(notes[0].Content, "(/"+renamed+")") {
+		t.Errorf("notes = %+v", notes)
+	}
+
+	// Importing it again changes nothing.
+	result, err = mgr.ImportZip(bytes.NewReader(archive), int64(len(archive)), ImportMerge)
+	if err != nil || result.Added != 0 || result.Files != 0 || result.Reused != 2 {
+		t.Errorf("second import = %+v, %v", result, err)
+	}
+}
+
+func TestImportZip_Restore(t *testing.T) {
+	archive := exportedZip(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte("## 2026-06-01 09:00:00 - Mine\n\ngone after the restore\n"), 0644)
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.ImportZip(bytes.NewReader(archive), int64(len(archive)), ImportRestore); err != nil {
+		t.Fatalf("ImportZip: %v", err)
+	}
+	if notes := mgr.GetAllNotes(); len(notes) != 2 || notes[0].Title != "Plan" {
+		t.Errorf("notes = %+v", notes)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "assets", "sites", "page.html")); err != nil {
+		t.Errorf("page.html: %v", err)
+	}
+}
+
+func TestImportZip_RejectsUnsafeArchives(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, files := range map[string][]string{
+		"escaping path": {"notes.md", "../evil.md"},
+		"no notes":      {"assets/images/a.png"},
+	} {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for _, f := range files {
+			w, _ := zw.Create(f)
+			w.Write([]byte("## 2026-05-11 09:00:00\n\nx\n"))
+		}
+		zw.Close()
+		if _, err := mgr.ImportZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()), ""); !errors.Is(err, ErrInvalidImport) {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(mgr.GetBasePath(), "assets", "images", "a.png")); !os.IsNotExist(err) {
+		t.Errorf("asset of a rejected archive written: %v", err)
+	}
+}
+
+func TestImportZip_RejectsHugeFiles(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("notes.md")
+	w.Write([]byte("## 2026-05-11 09:00:00\n\nx\n"))
+	w, _ = zw.Create("assets/files/small.txt")
+	w.Write([]byte("small"))
+	// The header claims more than maxImportFile; the file isn't read.
+	raw, _ := zw.CreateRaw(&zip.FileHeader{Name: "assets/files/huge.bin", Method: zip.Deflate, UncompressedSize64: maxImportFile + 1})
+	raw.Write([]byte{0x03, 0x00})
+	zw.Close()
+	_, err = mgr.ImportZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()), "")
+	if !errors.Is(err, ErrInvalidImport) || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(mgr.GetBasePath(), "assets", "files", "small.txt")); !os.IsNotExist(err) {
+		t.Errorf("asset before the huge one written: %v", err)
+	}
+	if notes := mgr.GetAllNotes(); len(notes) != 0 {
+		t.Errorf("notes = %d, want none imported", len(notes))
+	}
+}
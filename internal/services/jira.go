@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/jira"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+const defaultJiraSyncInterval = 5 * time.Minute
+
+// jiraSnapshot is what a linked issue looked like at the last sync.
+type jiraSnapshot struct {
+	Done   bool   `json:"done"`
+	Status string `json:"status"`
+}
+
+// JiraService creates Jira issues from a folder's tasks and mirrors the
+// issues' status back onto the task checkboxes. Each task is linked to its
+// issue by a markdown link with the issue key appended to the task line.
+//
+// Status only flows from Jira to notes.md: Jira workflows decide which
+// transitions exist, so checking a task locally does not resolve its issue.
+type JiraService struct {
+	noteManager *NoteManager
+	client      *jira.Client
+	folder      *models.JiraFolderConfig
+	statePath   string
+	mu          sync.Mutex // serializes Sync runs
+	stop        chan struct{}
+}
+
+// NewJiraService creates the service. Like the Todoist sync, the snapshot
+// of last-seen issue status lives under stateDir (normally
+// ~/.config/noteflow/jira), outside the folder.
+func NewJiraService(noteManager *NoteManager, cfg models.JiraConfig, folderCfg *models.FolderConfig, stateDir string) *JiraService {
+	s := &JiraService{
+		noteManager: noteManager,
+		statePath:   syncStatePath(stateDir, noteManager.GetBasePath()),
+	}
+	if folderCfg != nil && folderCfg.Jira != nil {
+		s.folder = folderCfg.Jira
+		creds := cfg.Credentials(s.folder.URL)
+		s.client = jira.NewClient(s.folder.URL, creds.Email, creds.Token)
+	}
+	return s
+}
+
+// Enabled reports whether this folder syncs with a Jira project. A tag or
+// section is required: turning every task in a folder into an issue is
+// almost never what anyone wants.
+func (s *JiraService) Enabled() bool {
+	return s.folder != nil && s.folder.URL != "" && s.folder.Project != "" &&
+		(s.folder.Tag != "" || s.folder.Section != "")
+}
+
+// Start runs Sync immediately and then on a ticker until Stop is called.
+func (s *JiraService) Start() {
+	if !s.Enabled() || s.stop != nil {
+		return
+	}
+	interval := defaultJiraSyncInterval
+	if s.folder.IntervalMinutes > 0 {
+		interval = time.Duration(s.folder.IntervalMinutes) * time.Minute
+	}
+	s.stop = make(chan struct{})
+	go func(stop <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			if _, err := s.Sync(ctx); err != nil {
+				log.Printf("Warning: Jira sync failed: %v", err)
+			}
+			cancel()
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}(s.stop)
+}
+
+// Stop stops the background loop started by Start.
+func (s *JiraService) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// selects reports whether task belongs to the configured tag and section.
+func (s *JiraService) selects(task models.Task) bool {
+	if s.folder.Tag != "" && !hasTag(task, s.folder.Tag) {
+		return false
+	}
+	return s.folder.Section == "" || strings.EqualFold(task.Section, s.folder.Section)
+}
+
+// Sync runs one pass:
+//
+//   - unlinked, unchecked tasks matching the tag/section become issues and
+//     get the issue link appended to their line;
+//   - a linked task is checked or unchecked when its issue has moved into
+//     or out of a "done" status since the last sync. A task whose issue
+//     hasn't moved keeps whatever state it has locally.
+//
+// Issues deleted in Jira leave their tasks untouched.
+func (s *JiraService) Sync(ctx context.Context) (*TaskSyncResult, error) {
+	if !s.Enabled() {
+		return nil, fmt.Errorf("no Jira project configured for this folder (set jira.url, jira.project and jira.tag or jira.section in %s)", models.FolderConfigFile)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := loadSyncState[jiraSnapshot](s.statePath)
+	if err != nil {
+		return nil, err
+	}
+	issueType := s.folder.IssueType
+	if issueType == "" {
+		issueType = "Task"
+	}
+
+	res := &TaskSyncResult{}
+	for _, task := range s.noteManager.GetAllTasks() {
+		key, linked := jira.ParseIssueLink(task.Text)
+		if !linked {
+			if task.Checked || !s.selects(task) {
+				continue
+			}
+			fields := taskSyncFields(task, jira.StripIssueLink)
+			key, err := s.client.CreateIssue(ctx, jira.NewIssue{
+				Project:   s.folder.Project,
+				IssueType: issueType,
+				Summary:   fields.Content,
+				DueDate:   fields.Due,
+				Labels:    s.folder.Labels,
+			})
+			if err != nil {
+				return res, err
+			}
+			if err := s.noteManager.UpdateTaskText(task.Index, strings.TrimRight(task.Text, " ")+" "+jira.IssueLink(s.folder.URL, key)); err != nil {
+				return res, err
+			}
+			state[key] = jiraSnapshot{}
+			res.Created++
+			continue
+		}
+
+		issue, err := s.client.GetIssue(ctx, key)
+		if errors.Is(err, jira.ErrNotFound) {
+			delete(state, key)
+			continue
+		}
+		if err != nil {
+			return res, err
+		}
+		done := issue.Done()
+		// No snapshot means this machine hasn't seen the issue yet; Jira is
+		// the source of truth for status, so take its state.
+		if prev, seen := state[key]; (!seen || prev.Done != done) && task.Checked != done {
+			if err := s.noteManager.UpdateTask(task.Index, done); err != nil {
+				return res, err
+			}
+			res.Pulled++
+		}
+		state[key] = jiraSnapshot{Done: done, Status: issue.Fields.Status.Name}
+	}
+
+	return res, saveSyncState(s.statePath, state)
+}
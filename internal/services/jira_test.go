@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/jira"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// fakeJira is an in-memory Jira project whose issues are either open or
+// done.
+type fakeJira struct {
+	mu       sync.Mutex
+	summary  map[string]string
+	done     map[string]bool
+	next     int
+	labels   []any
+	issueTyp any
+}
+
+func (f *fakeJira) server(t *testing.T) *httptest.Server {
+	f.summary, f.done = make(map[string]string), make(map[string]bool)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.Method == http.MethodPost {
+			var body struct {
+				Fields map[string]any `json:"fields"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			f.next++
+			key := fmt.Sprintf("NF-%d", f.next)
+			f.summary[key] = body.Fields["summary"].(string)
+			f.labels, _ = body.Fields["labels"].([]any)
+			f.issueTyp = body.Fields["issuetype"]
+			json.NewEncoder(w).Encode(map[string]string{"key": key})
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/")
+		summary, ok := f.summary[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		category, name := "indeterminate", "In Progress"
+		if f.done[key] {
+			category, name = "done", "Done"
+		}
+		fmt.Fprintf(w, `{"key":%q,"fields":{"summary":%q,"status":{"name":%q,"statusCategory":{"key":%q}}}}`,
+			key, summary, name, category)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (f *fakeJira) setDone(key string, done bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.done[key] = done
+}
+
+func TestJiraSync_CreatesIssuesAndPullsStatus(t *testing.T) {
+	fake := &fakeJira{}
+	srv := fake.server(t)
+
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "## Sprint 12\n- [ ] fix login @2026-10-20\n- [x] already done\n## Later\n- [ ] rewrite docs\n- [ ] triage bugs #jira"
+	if err := mgr.AddNote("planning", content); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewJiraService(mgr, models.JiraConfig{Sites: map[string]models.JiraCredentials{
+		srv.URL: {Email: "me@example.com", Token: "t"},
+	}}, &models.FolderConfig{
+		Jira: &models.JiraFolderConfig{URL: srv.URL, Project: "NF", Section: "sprint 12", Labels: []string{"noteflow"}},
+	}, t.TempDir())
+	ctx := context.Background()
+
+	// Only the open task under "Sprint 12" becomes an issue.
+	res, err := svc.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if res.Created != 1 || fake.summary["NF-1"] != "fix login" {
+		t.Fatalf("created = %d, issues = %v", res.Created, fake.summary)
+	}
+	if len(fake.labels) != 1 || fake.labels[0] != "noteflow" {
+		t.Errorf("labels = %v", fake.labels)
+	}
+	task, _ := mgr.GetTask(0)
+	if !strings.HasSuffix(task.Text, jira.IssueLink(srv.URL, "NF-1")) {
+		t.Fatalf("task not annotated: %q", task.Text)
+	}
+
+	// Resolving the issue checks the task; reopening it unchecks it.
+	fake.setDone("NF-1", true)
+	if res, err = svc.Sync(ctx); err != nil || res.Pulled != 1 {
+		t.Fatalf("Sync = %+v, %v", res, err)
+	}
+	if task, _ = mgr.GetTask(0); !task.Checked {
+		t.Errorf("task not checked after issue was resolved")
+	}
+	fake.setDone("NF-1", false)
+	svc.Sync(ctx)
+	if task, _ = mgr.GetTask(0); task.Checked {
+		t.Errorf("task still checked after issue was reopened")
+	}
+
+	// A local check sticks while the issue doesn't move.
+	if err := mgr.UpdateTask(0, true); err != nil {
+		t.Fatal(err)
+	}
+	if res, err = svc.Sync(ctx); err != nil || *res != (TaskSyncResult{}) {
+		t.Fatalf("idle Sync = %+v, %v", res, err)
+	}
+	if task, _ = mgr.GetTask(0); !task.Checked {
+		t.Errorf("local check was overwritten")
+	}
+}
+
+func TestJiraEnabledNeedsTagOrSection(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	folder := &models.JiraFolderConfig{URL: "https://acme.atlassian.net", Project: "NF"}
+	svc := NewJiraService(mgr, models.JiraConfig{}, &models.FolderConfig{Jira: folder}, t.TempDir())
+	if svc.Enabled() {
+		t.Error("enabled without a tag or section")
+	}
+	folder.Tag = "jira"
+	if !svc.Enabled() {
+		t.Error("not enabled with a tag")
+	}
+}
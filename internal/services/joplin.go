@@ -0,0 +1,425 @@
+package services
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// Joplin item types: the type_ property of an item of a JEX archive.
+const (
+	joplinNote     = "1"
+	joplinNotebook = "2"
+	joplinResource = "4"
+	joplinTag      = "5"
+	joplinNoteTag  = "6"
+)
+
+var (
+	// joplinLinkRE matches a markdown link or image to a Joplin item:
+	// [label](:/0123456789abcdef0123456789abcdef).
+	joplinLinkRE = regexp.MustCompile(`(!?)\[([^\]\n]*)\]\(:/([0-9a-fA-F]{32})(?:#[^)\s]*)?\)`)
+	// joplinRefRE matches any other reference to a Joplin item, such as
+	// the src of an <img>.
+	joplinRefRE = regexp.MustCompile(`:/([0-9a-fA-F]{32})\b`)
+	// joplinIDRE matches the ID of a Joplin item.
+	joplinIDRE = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+)
+
+// joplinItem is an item of a JEX archive: a note, notebook, resource, tag
+// or note's tag, serialized as its title, body and "key: value" props.
+type joplinItem struct {
+	title string
+	body  string
+	props map[string]string
+}
+
+// parseJoplinItem reads an item the way Joplin writes one: the title
+// line, a blank line and the body, then a blank line and the props, one
+// per line, ending with type_.
+func parseJoplinItem(text string) joplinItem {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	item := joplinItem{props: map[string]string{}}
+	i := len(lines) - 1
+	for ; i >= 0 && lines[i] != ""; i-- {
+		key, value, _ := strings.Cut(lines[i], ":")
+		item.props[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	if i > 0 {
+		item.title = strings.TrimSpace(lines[0])
+		if i > 2 {
+			item.body = strings.Join(lines[2:i], "\n")
+		}
+	}
+	return item
+}
+
+// joplinImport is a JEX archive being imported.
+type joplinImport struct {
+	nm        *NoteManager
+	now       time.Time
+	notebooks map[string]joplinItem
+	dirs      map[string]string // notebook ID → folder, relative to nm's
+	taken     map[string]bool   // lower-cased folders given to notebooks
+	titles    map[string]string // note ID → title
+	resources map[string]joplinItem
+	files     map[string]string // resource ID → its file, unpacked
+	tags      map[string]string // tag ID → #tag name
+	noteTags  map[string][]string
+	stored    map[string]string // folder and resource ID → URL path there
+	missing   map[string]bool
+}
+
+// ImportJEX imports a Joplin export (JEX, a tar of the app's items) in
+// ImportMerge or ImportRestore mode as ImportZip does. Notebooks become
+// folders under this one, nested as they are, and their notes go to
+// them; notes without a notebook come here. A to-do becomes a note
+// opening with its task, ticked if it was completed, with its due date.
+// Resources are stored as uploads of the folders of the notes using
+// them, links between notes become wiki links, and tags #tags. The
+// folders written to are listed in the result's Folders, for the caller
+// to register.
+func (nm *NoteManager) ImportJEX(r io.Reader, mode string, now time.Time) (*ImportResult, error) {
+	if mode == "" {
+		mode = ImportMerge
+	}
+	if mode != ImportMerge && mode != ImportRestore {
+		return nil, fmt.Errorf("unknown import mode %q (want %s or %s)", mode, ImportMerge, ImportRestore)
+	}
+	tmp, err := os.MkdirTemp("", "noteflow-jex-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	j := &joplinImport{
+		nm:        nm,
+		now:       now,
+		notebooks: map[string]joplinItem{},
+		dirs:      map[string]string{},
+		// Notebooks don't take the folder's own directories.
+		taken:     map[string]bool{"assets": true, "templates": true},
+		titles:    map[string]string{},
+		resources: map[string]joplinItem{},
+		files:     map[string]string{},
+		tags:      map[string]string{},
+		noteTags:  map[string][]string{},
+		stored:    map[string]string{},
+		missing:   map[string]bool{},
+	}
+	var notes []joplinItem
+	var noteTags []joplinItem
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: not a JEX archive: %v", ErrInvalidImport, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Size > maxImportFile {
+			return nil, fmt.Errorf("%w: %s is too large", ErrInvalidImport, hdr.Name)
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		dir, base := path.Split(name)
+		id := strings.TrimSuffix(base, path.Ext(base))
+		if !joplinIDRE.MatchString(id) {
+			continue
+		}
+		id = strings.ToLower(id)
+		switch {
+		case dir == "resources/":
+			f, err := os.Create(filepath.Join(tmp, id))
+			if err != nil {
+				return nil, err
+			}
+			_, err = io.Copy(f, tr)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidImport, name, err)
+			}
+			j.files[id] = filepath.Join(tmp, id)
+		case dir == "" && path.Ext(base) == ".md":
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidImport, name, err)
+			}
+			item := parseJoplinItem(string(data))
+			if item.props["encryption_applied"] == "1" {
+				return nil, fmt.Errorf("%w: %s is encrypted; export from Joplin with end-to-end encryption unlocked", ErrInvalidImport, name)
+			}
+			switch item.props["type_"] {
+			case joplinNote:
+				notes = append(notes, item)
+				j.titles[id] = item.title
+			case joplinNotebook:
+				j.notebooks[id] = item
+			case joplinResource:
+				j.resources[id] = item
+			case joplinTag:
+				if tag, ok := importedTag(item.title); ok {
+					j.tags[id] = tag
+				}
+			case joplinNoteTag:
+				noteTags = append(noteTags, item)
+			}
+		}
+	}
+	if len(notes) == 0 {
+		return nil, fmt.Errorf("%w: no notes in the JEX archive", ErrInvalidImport)
+	}
+	for _, item := range noteTags {
+		if tag, ok := j.tags[strings.ToLower(item.props["tag_id"])]; ok {
+			noteID := strings.ToLower(item.props["note_id"])
+			j.noteTags[noteID] = append(j.noteTags[noteID], tag)
+		}
+	}
+
+	// Folders are named in the order of their notebooks' titles, so a
+	// name two notebooks share goes to the same one each time.
+	ids := make([]string, 0, len(j.notebooks))
+	for id := range j.notebooks {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool {
+		ta, tb := j.notebooks[ids[a]].title, j.notebooks[ids[b]].title
+		return ta < tb || ta == tb && ids[a] < ids[b]
+	})
+	for _, id := range ids {
+		j.dir(id, 0)
+	}
+	byDir := map[string][]joplinItem{}
+	for _, item := range notes {
+		dir := j.dirs[strings.ToLower(item.props["parent_id"])]
+		byDir[dir] = append(byDir[dir], item)
+	}
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	result := &ImportResult{Mode: mode}
+	for _, dir := range dirs {
+		folder, err := j.importFolder(dir, byDir[dir], result)
+		if err != nil {
+			return nil, err
+		}
+		result.Folders = append(result.Folders, folder)
+		result.Notes += folder.Notes
+		result.Added += folder.Added
+		result.Skipped += folder.Skipped
+	}
+	for id := range j.missing {
+		result.Missing = append(result.Missing, id)
+	}
+	sort.Strings(result.Missing)
+	return result, nil
+}
+
+// dir returns the folder of notebook id, relative to the importing one,
+// giving it one under its parent's on first use. depth guards against a
+// cycle of parents.
+func (j *joplinImport) dir(id string, depth int) string {
+	if dir, ok := j.dirs[id]; ok {
+		return dir
+	}
+	notebook, ok := j.notebooks[id]
+	if !ok {
+		return ""
+	}
+	parent := ""
+	if depth < 32 {
+		parent = j.dir(strings.ToLower(notebook.props["parent_id"]), depth+1)
+	}
+	name := joplinFolderName(notebook.title)
+	dir := path.Join(parent, name)
+	for n := 2; j.taken[strings.ToLower(dir)]; n++ {
+		dir = path.Join(parent, fmt.Sprintf("%s (%d)", name, n))
+	}
+	j.taken[strings.ToLower(dir)] = true
+	j.dirs[id] = dir
+	return dir
+}
+
+// joplinFolderName turns a notebook's title into a directory name.
+func joplinFolderName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < ' ' || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+		return r
+	}, title)
+	// Not hidden, and not ending in what Windows drops.
+	name = strings.TrimRight(strings.TrimLeft(strings.TrimSpace(name), "."), ". ")
+	if name == "" {
+		return "Untitled"
+	}
+	return name
+}
+
+// importFolder adds the notes of one folder, dir relative to the
+// importing one, through a manager of its own.
+func (j *joplinImport) importFolder(dir string, items []joplinItem, result *ImportResult) (ImportedFolder, error) {
+	nm := j.nm
+	if dir != "" {
+		abs := filepath.Join(j.nm.GetBasePath(), filepath.FromSlash(dir))
+		var err error
+		if nm, err = NewNoteManager(abs); err != nil {
+			return ImportedFolder{}, fmt.Errorf("open notes.md at %s: %w", abs, err)
+		}
+		defer nm.Close()
+	}
+	notes := make([]*models.Note, 0, len(items))
+	for _, item := range items {
+		note, err := j.note(nm, dir, item, result)
+		if err != nil {
+			return ImportedFolder{}, err
+		}
+		notes = append(notes, note)
+	}
+	orderImportedNotes(notes)
+	folderResult := &ImportResult{Mode: result.Mode, Notes: len(notes)}
+	if err := nm.addImportedNotes(notes, folderResult); err != nil {
+		return ImportedFolder{}, err
+	}
+	return ImportedFolder{Name: dir, Path: nm.GetBasePath(), Notes: folderResult.Notes, Added: folderResult.Added, Skipped: folderResult.Skipped}, nil
+}
+
+// note builds the note of a Joplin note or to-do, stored in nm, the
+// manager of folder dir.
+func (j *joplinImport) note(nm *NoteManager, dir string, item joplinItem, result *ImportResult) (*models.Note, error) {
+	var firstErr error
+	attachment := func(id string) (string, joplinItem, bool) {
+		resource, ok := j.resources[id]
+		if !ok {
+			return "", resource, false
+		}
+		u, err := j.attachment(nm, dir, id, result)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return "", resource, false
+		}
+		return u, resource, u != ""
+	}
+
+	content := models.ReplaceOutsideCode(item.body, joplinLinkRE, func(m []string) string {
+		id := strings.ToLower(m[3])
+		if title, ok := j.titles[id]; ok {
+			return wikiLinkTo(title, m[2])
+		}
+		u, resource, ok := attachment(id)
+		if !ok {
+			if _, isResource := j.resources[id]; !isResource {
+				j.missing[":/"+id] = true
+			}
+			return m[0]
+		}
+		label := m[2]
+		if label == "" {
+			label = resource.title
+		}
+		if m[1] == "!" && strings.HasPrefix(resource.props["mime"], "image/") {
+			return "![" + label + "](" + u + ")"
+		}
+		return "[" + label + "](" + u + ")"
+	})
+	content = models.ReplaceOutsideCode(content, joplinRefRE, func(m []string) string {
+		if u, _, ok := attachment(strings.ToLower(m[1])); ok {
+			return u
+		}
+		return m[0]
+	})
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	content = strings.TrimSpace(content)
+
+	if item.props["is_todo"] == "1" {
+		state := models.TaskTodo
+		if done := item.props["todo_completed"]; done != "" && done != "0" {
+			state = models.TaskDone
+		}
+		task := "- " + state.Mark() + " " + item.title
+		if ms, err := strconv.ParseInt(item.props["todo_due"], 10, 64); err == nil && ms > 0 {
+			due := time.UnixMilli(ms).Local()
+			task += " " + models.FormatDueToken(due, due.Hour() != 0 || due.Minute() != 0)
+		}
+		content = strings.TrimSpace(task + "\n\n" + content)
+		result.Tasks++
+	}
+	if tags := j.noteTags[strings.ToLower(item.props["id"])]; len(tags) > 0 {
+		sort.Strings(tags)
+		content = strings.TrimSpace(content + "\n\n#" + strings.Join(tags, " #"))
+	}
+
+	note := models.NewNote(item.title, content)
+	note.Timestamp = wallClock(j.now)
+	for _, key := range []string{"user_created_time", "created_time"} {
+		if t, err := time.Parse(time.RFC3339Nano, item.props[key]); err == nil {
+			note.Timestamp = wallClock(t)
+			break
+		}
+	}
+	return note, nil
+}
+
+// attachment stores resource id as an upload of nm, the manager of folder
+// dir, once, and returns its URL path, or "" when the archive lacks its
+// file.
+func (j *joplinImport) attachment(nm *NoteManager, dir, id string, result *ImportResult) (string, error) {
+	key := dir + "\x00" + id
+	if u, ok := j.stored[key]; ok {
+		return u, nil
+	}
+	resource := j.resources[id]
+	name := resource.title
+	if ext := resource.props["file_extension"]; ext != "" && !strings.EqualFold(path.Ext(name), "."+ext) {
+		name += "." + ext
+	}
+	name = joplinFolderName(name)
+	file, ok := j.files[id]
+	if !ok {
+		j.missing[name] = true
+		return "", nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	contentType := resource.props["mime"]
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(name))
+	}
+	stored, _, err := nm.SaveFile(name, data, contentType)
+	if err != nil {
+		return "", fmt.Errorf("failed to store %s: %w", name, err)
+	}
+	u := (&url.URL{Path: stored}).EscapedPath()
+	j.stored[key] = u
+	result.Files++
+	return u, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// jexArchive builds a JEX archive of files, name → content.
+func jexArchive(t *testing.T, files map[string]string) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func jexID(n int) string {
+	return fmt.Sprintf("%032x", n+1)
+}
+
+func TestImportJEX(t *testing.T) {
+	work, projects, plan, ideas, todo, loose, logo, gone, tag := jexID(0), jexID(1), jexID(2), jexID(3), jexID(4), jexID(5), jexID(6), jexID(7), jexID(8)
+	due := time.Date(2026, 10, 20, 0, 0, 0, 0, time.Local).UnixMilli()
+	archive := jexArchive(t, map[string]string{
+		work + ".md":     "Work\n\nid: " + work + "\nparent_id: \ntype_: 2",
+		projects + ".md": "Projects\n\nid: " + projects + "\nparent_id: " + work + "\ntype_: 2",
+		plan + ".md": "Plan\n\nSee [the ideas](:/" + ideas + ") ![logo](:/" + logo + ") [gone](:/" + gone + ")\n\n<img src=\":/" + logo + "\"/>\n\n" +
+			"id: " + plan + "\nparent_id: " + projects + "\ncreated_time: 2026-10-01T09:30:00.000Z\nuser_created_time: 2026-10-01T09:30:00.000Z\nis_todo: 0\ntype_: 1",
+		ideas + ".md": "Ideas\n\nmore\n\nid: " + ideas + "\nparent_id: " + work + "\nuser_created_time: 2026-10-02T09:30:00.000Z\ntype_: 1",
+		todo + ".md": "Call the bank\n\nabout the loan\n\nid: " + todo + "\nparent_id: " + work + "\nis_todo: 1\ntodo_completed: 1760000000000\ntodo_due: " +
+			strconv.FormatInt(due, 10) + "\nuser_created_time: 2026-10-03T09:30:00.000Z\ntype_: 1",
+		loose + ".md":                "Loose\n\nno notebook\n\nid: " + loose + "\nparent_id: \ntype_: 1",
+		logo + ".md":                 "logo.png\n\nid: " + logo + "\nmime: image/png\nfile_extension: png\ntype_: 4",
+		"resources/" + logo + ".png": "png",
+		tag + ".md":                  "to read\n\nid: " + tag + "\ntype_: 5",
+		jexID(9) + ".md":             "\n\nid: " + jexID(9) + "\nnote_id: " + ideas + "\ntag_id: " + tag + "\ntype_: 6",
+	})
+
+	nm := newNoteManagerWithNote(t, "Existing", "hello")
+	result, err := nm.ImportJEX(archive, "", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Notes != 4 || result.Added != 4 || result.Files != 1 || result.Tasks != 1 || len(result.Folders) != 3 {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Missing) != 1 || result.Missing[0] != ":/"+gone {
+		t.Errorf("missing = %v", result.Missing)
+	}
+	if result.Folders[0].Name != "" || result.Folders[1].Name != "Work" || result.Folders[2].Name != "Work/Projects" ||
+		result.Folders[2].Path != filepath.Join(nm.GetBasePath(), "Work", "Projects") {
+		t.Errorf("folders = %+v", result.Folders)
+	}
+	if notes := nm.GetAllNotes(); len(notes) != 2 || !strings.Contains(notes[0].Title+notes[1].Title, "Loose") {
+		t.Errorf("root notes = %d", len(notes))
+	}
+
+	read := func(dir string) string {
+		data, err := os.ReadFile(filepath.Join(nm.GetBasePath(), dir, "notes.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	planNotes := read(filepath.Join("Work", "Projects"))
+	for _, want := range []string{
+		"## 2026-10-01 09:30:00 - Plan",
+		"See [[Ideas|the ideas]] ![logo](/assets/images/logo.png) [gone](:/" + gone + ")",
+		`<img src="/assets/images/logo.png"/>`,
+	} {
+		if !strings.Contains(planNotes, want) {
+			t.Errorf("Work/Projects lacks %q:\n%s", want, planNotes)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(nm.GetBasePath(), "Work", "Projects", "assets", "images", "logo.png")); err != nil {
+		t.Errorf("logo.png not stored with the note: %v", err)
+	}
+	workNotes := read("Work")
+	for _, want := range []string{"- [x] Call the bank @2026-10-20\n\nabout the loan", "more\n\n#to-read"} {
+		if !strings.Contains(workNotes, want) {
+			t.Errorf("Work lacks %q:\n%s", want, workNotes)
+		}
+	}
+
+	again, err := nm.ImportJEX(jexArchive(t, map[string]string{loose + ".md": "Loose\n\nno notebook\n\nid: " + loose + "\ntype_: 1"}), ImportMerge, time.Now())
+	if err != nil || again.Added != 0 || again.Skipped != 1 {
+		t.Errorf("importing again: %+v, %v", again, err)
+	}
+	if _, err := nm.ImportJEX(strings.NewReader("not a tar"), "", time.Now()); !errors.Is(err, ErrInvalidImport) {
+		t.Errorf("not a tar: %v", err)
+	}
+	encrypted := jexArchive(t, map[string]string{loose + ".md": "\n\nid: " + loose + "\nencryption_applied: 1\ntype_: 1"})
+	if _, err := nm.ImportJEX(encrypted, "", time.Now()); !errors.Is(err, ErrInvalidImport) {
+		t.Errorf("an encrypted note: %v", err)
+	}
+}
+
+func TestParseJoplinItem(t *testing.T) {
+	item := parseJoplinItem("Title\n\nfirst\n\nsecond: line\n\nid: abc\ntitle_diff: \ntype_: 1\n")
+	if item.title != "Title" || item.body != "first\n\nsecond: line" || item.props["id"] != "abc" || item.props["type_"] != "1" {
+		t.Errorf("item = %+v", item)
+	}
+	if item := parseJoplinItem("Empty\n\nid: x\ntype_: 1"); item.title != "Empty" || item.body != "" {
+		t.Errorf("item without a body = %+v", item)
+	}
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// keepTimeLayout is how the HTML of a Keep note gives its date.
+const keepTimeLayout = "Jan 2, 2006, 3:04:05 PM"
+
+// keepNote is a note of Google Keep as Takeout writes it: a JSON file
+// beside an HTML one, or the HTML only in older exports.
+type keepNote struct {
+	Title                   string           `json:"title"`
+	TextContent             *string          `json:"textContent"`
+	ListContent             []keepListItem   `json:"listContent"`
+	Labels                  []keepLabel      `json:"labels"`
+	Attachments             []keepAttachment `json:"attachments"`
+	Annotations             []keepAnnotation `json:"annotations"`
+	IsTrashed               bool             `json:"isTrashed"`
+	CreatedTimestampUsec    int64            `json:"createdTimestampUsec"`
+	UserEditedTimestampUsec int64            `json:"userEditedTimestampUsec"`
+
+	created time.Time // of an HTML note
+}
+
+type keepListItem struct {
+	Text      string `json:"text"`
+	IsChecked bool   `json:"isChecked"`
+}
+
+type keepLabel struct {
+	Name string `json:"name"`
+}
+
+// keepAttachment is a file of a note, FilePath being relative to it.
+type keepAttachment struct {
+	FilePath string `json:"filePath"`
+	Mimetype string `json:"mimetype"`
+}
+
+// keepAnnotation is a link Keep found in a note.
+type keepAnnotation struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// ImportKeepZip imports a Google Takeout zip with ImportKeep.
+func (nm *NoteManager) ImportKeepZip(r io.ReaderAt, size int64, mode string, now time.Time) (*ImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	return nm.ImportKeep(zr, mode, now)
+}
+
+// ImportKeep imports the Google Keep notes of a Takeout export, in
+// ImportMerge or ImportRestore mode as ImportZip does. It reads every
+// note's JSON file wherever it is in takeout, or its HTML file when there
+// is no JSON, as in older exports. A note keeps its title and is dated
+// when it was created; its list items become tasks, ticked as they were,
+// its labels #tags and its links a list after the text. Attachments are
+// stored as uploads, images shown. Notes in Keep's trash are left out.
+func (nm *NoteManager) ImportKeep(takeout fs.FS, mode string, now time.Time) (*ImportResult, error) {
+	if mode == "" {
+		mode = ImportMerge
+	}
+	if mode != ImportMerge && mode != ImportRestore {
+		return nil, fmt.Errorf("unknown import mode %q (want %s or %s)", mode, ImportMerge, ImportRestore)
+	}
+	var jsonFiles, htmlFiles []string
+	err := fs.WalkDir(takeout, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		if p != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		switch ext := strings.ToLower(path.Ext(p)); {
+		case d.IsDir():
+		case ext == ".json":
+			jsonFiles = append(jsonFiles, p)
+		case ext == ".html":
+			htmlFiles = append(htmlFiles, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Mode: mode}
+	missing := map[string]bool{}
+	var notes []*models.Note
+	add := func(p string, kn *keepNote) error {
+		if kn.IsTrashed {
+			return nil
+		}
+		note, err := nm.keepNote(takeout, path.Dir(p), kn, now, result, missing)
+		if err != nil {
+			return err
+		}
+		notes = append(notes, note)
+		return nil
+	}
+	read := map[string]bool{} // note files read from JSON, without extension
+	for _, p := range jsonFiles {
+		data, err := readFSFile(takeout, p)
+		if err != nil {
+			return nil, err
+		}
+		var kn keepNote
+		if json.Unmarshal(data, &kn) != nil || kn.TextContent == nil && kn.ListContent == nil {
+			continue // not a note, as Takeout has other JSON files
+		}
+		read[strings.TrimSuffix(p, path.Ext(p))] = true
+		if err := add(p, &kn); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range htmlFiles {
+		if read[strings.TrimSuffix(p, path.Ext(p))] {
+			continue
+		}
+		data, err := readFSFile(takeout, p)
+		if err != nil {
+			return nil, err
+		}
+		kn, ok := parseKeepHTML(string(data))
+		if !ok {
+			continue
+		}
+		if err := add(p, kn); err != nil {
+			return nil, err
+		}
+	}
+	if len(notes) == 0 {
+		return nil, fmt.Errorf("%w: no Google Keep notes in the export", ErrInvalidImport)
+	}
+	for name := range missing {
+		result.Missing = append(result.Missing, name)
+	}
+	sort.Strings(result.Missing)
+
+	orderImportedNotes(notes)
+	result.Notes = len(notes)
+	if err := nm.addImportedNotes(notes, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// keepNote builds the note of kn, a note read from directory dir of
+// takeout.
+func (nm *NoteManager) keepNote(takeout fs.FS, dir string, kn *keepNote, now time.Time, result *ImportResult, missing map[string]bool) (*models.Note, error) {
+	var parts []string
+	if kn.TextContent != nil {
+		if text := strings.TrimSpace(strings.ReplaceAll(*kn.TextContent, "\r\n", "\n")); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	if len(kn.ListContent) > 0 {
+		items := make([]string, 0, len(kn.ListContent))
+		for _, item := range kn.ListContent {
+			state := models.TaskTodo
+			if item.IsChecked {
+				state = models.TaskDone
+			}
+			items = append(items, "- "+state.Mark()+" "+strings.Join(strings.Fields(item.Text), " "))
+		}
+		parts = append(parts, strings.Join(items, "\n"))
+		result.Tasks += len(items)
+	}
+	var attachments []string
+	for _, a := range kn.Attachments {
+		p, ok := findKeepAttachment(takeout, dir, a.FilePath)
+		if !ok {
+			missing[path.Base(a.FilePath)] = true
+			continue
+		}
+		data, err := readFSFile(takeout, p)
+		if err != nil {
+			return nil, err
+		}
+		contentType := a.Mimetype
+		if contentType == "" {
+			contentType = mime.TypeByExtension(path.Ext(p))
+		}
+		stored, isImage, err := nm.SaveFile(path.Base(p), data, contentType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store %s: %w", p, err)
+		}
+		result.Files++
+		u := (&url.URL{Path: stored}).EscapedPath()
+		name := path.Base(p)
+		if isImage {
+			attachments = append(attachments, "!["+strings.TrimSuffix(name, path.Ext(name))+"]("+u+")")
+		} else {
+			attachments = append(attachments, "["+name+"]("+u+")")
+		}
+	}
+	if len(attachments) > 0 {
+		parts = append(parts, strings.Join(attachments, "\n"))
+	}
+	var links []string
+	for _, a := range kn.Annotations {
+		if a.URL == "" {
+			continue
+		}
+		if title := strings.TrimSpace(a.Title); title != "" {
+			links = append(links, "- ["+title+"]("+a.URL+")")
+		} else {
+			links = append(links, "- <"+a.URL+">")
+		}
+	}
+	if len(links) > 0 {
+		parts = append(parts, strings.Join(links, "\n"))
+	}
+	var tags []string
+	for _, label := range kn.Labels {
+		if tag, ok := importedTag(label.Name); ok {
+			tags = append(tags, "#"+tag)
+		}
+	}
+	if len(tags) > 0 {
+		parts = append(parts, strings.Join(tags, " "))
+	}
+
+	note := models.NewNote(strings.TrimSpace(kn.Title), strings.Join(parts, "\n\n"))
+	switch {
+	case kn.CreatedTimestampUsec > 0:
+		note.Timestamp = wallClock(time.UnixMicro(kn.CreatedTimestampUsec))
+	case kn.UserEditedTimestampUsec > 0:
+		note.Timestamp = wallClock(time.UnixMicro(kn.UserEditedTimestampUsec))
+	case !kn.created.IsZero():
+		note.Timestamp = wallClock(kn.created)
+	default:
+		note.Timestamp = wallClock(now)
+	}
+	return note, nil
+}
+
+// findKeepAttachment finds the file of an attachment of a note in dir. Keep
+// names some attachments with another extension than their file's, as
+// "x.jpeg" for x.jpg, so a file by the same name with another extension
+// stands in.
+func findKeepAttachment(takeout fs.FS, dir, filePath string) (string, bool) {
+	if filePath == "" || strings.Contains("/"+filePath+"/", "/../") {
+		return "", false
+	}
+	p := path.Join(dir, filePath)
+	if info, err := fs.Stat(takeout, p); err == nil && !info.IsDir() {
+		return p, true
+	}
+	stem := strings.TrimSuffix(path.Base(p), path.Ext(p))
+	entries, err := fs.ReadDir(takeout, path.Dir(p))
+	if err != nil {
+		return "", false
+	}
+	for _, e := range entries {
+		if !e.IsDir() && strings.TrimSuffix(e.Name(), path.Ext(e.Name())) == stem {
+			return path.Join(path.Dir(p), e.Name()), true
+		}
+	}
+	return "", false
+}
+
+// parseKeepHTML reads a note from the HTML page Takeout writes for it,
+// reporting false when the page isn't one.
+func parseKeepHTML(page string) (*keepNote, bool) {
+	doc, err := nethtml.Parse(strings.NewReader(page))
+	if err != nil {
+		return nil, false
+	}
+	kn := &keepNote{}
+	found := false
+	var walk func(n *nethtml.Node)
+	walk = func(n *nethtml.Node) {
+		if n.Type == nethtml.ElementNode {
+			has := func(class string) bool { return htmlHasClass(n, class) }
+			switch {
+			case n.DataAtom == atom.Div && has("note"):
+				found = true
+				kn.IsTrashed = has("trashed")
+			case n.DataAtom == atom.Div && has("heading"):
+				text := strings.TrimSpace(htmlText(n, false))
+				// Newer exports put a narrow no-break space before "PM".
+				text = strings.NewReplacer("\u202f", " ", "\u00a0", " ").Replace(text)
+				if t, err := time.ParseInLocation(keepTimeLayout, text, time.Local); err == nil {
+					kn.created = t
+				}
+				return
+			case n.DataAtom == atom.Div && has("title"):
+				kn.Title = strings.TrimSpace(htmlText(n, false))
+				return
+			case n.DataAtom == atom.Li && has("listitem"):
+				text := ""
+				for c := n.FirstChild; c != nil; c = c.NextSibling {
+					if c.Type == nethtml.ElementNode && htmlHasClass(c, "text") {
+						text = htmlText(c, false)
+					}
+				}
+				kn.ListContent = append(kn.ListContent, keepListItem{Text: text, IsChecked: has("checked")})
+				return
+			case n.DataAtom == atom.Div && has("content"):
+				if !htmlHas(n, atom.Ul) {
+					text := htmlText(n, true)
+					kn.TextContent = &text
+					return
+				}
+			case n.DataAtom == atom.Span && has("label-name"):
+				kn.Labels = append(kn.Labels, keepLabel{Name: htmlText(n, false)})
+				return
+			case n.DataAtom == atom.Img:
+				if src := htmlAttr(n, "src"); src != "" && !strings.Contains(src, ":") {
+					if unescaped, err := url.PathUnescape(src); err == nil {
+						src = unescaped
+					}
+					kn.Attachments = append(kn.Attachments, keepAttachment{FilePath: src})
+				}
+			case n.DataAtom == atom.A && n.Parent != nil && htmlHasClass(n.Parent, "annotation"):
+				kn.Annotations = append(kn.Annotations, keepAnnotation{Title: htmlText(n, false), URL: htmlAttr(n, "href")})
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return kn, found
+}
+
+// htmlAttr returns attribute key of n, or "".
+func htmlAttr(n *nethtml.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// htmlHasClass reports whether n is of class class.
+func htmlHasClass(n *nethtml.Node, class string) bool {
+	for _, c := range strings.Fields(htmlAttr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlHas reports whether n has an element a below it.
+func htmlHas(n *nethtml.Node, a atom.Atom) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == nethtml.ElementNode && c.DataAtom == a || htmlHas(c, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlText returns the text of n, with its <br>s as line breaks when
+// lines is set.
+func htmlText(n *nethtml.Node, lines bool) string {
+	var b strings.Builder
+	var walk func(n *nethtml.Node)
+	walk = func(n *nethtml.Node) {
+		switch {
+		case n.Type == nethtml.TextNode:
+			b.WriteString(n.Data)
+		case n.Type == nethtml.ElementNode && n.DataAtom == atom.Br && lines:
+			b.WriteString("\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
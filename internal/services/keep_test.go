@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestImportKeep(t *testing.T) {
+	created := time.Date(2026, 10, 1, 9, 30, 0, 0, time.Local)
+	file := func(content string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(content)} }
+	takeout := fstest.MapFS{
+		"Takeout/archive_browser.html": file("<html><body><h1>Your data</h1></body></html>"),
+		"Takeout/Keep/Groceries.json": file(`{"title": "Groceries", "isTrashed": false,
+			"createdTimestampUsec": ` + strconv.FormatInt(created.UnixMicro(), 10) + `,
+			"listContent": [{"text": "milk", "isChecked": false}, {"text": "eggs\nsix", "isChecked": true}],
+			"labels": [{"name": "Shopping list"}, {"name": "2026"}],
+			"attachments": [{"filePath": "photo.jpeg", "mimetype": "image/jpeg"}, {"filePath": "gone.png", "mimetype": "image/png"}],
+			"annotations": [{"title": "Shop", "url": "https://shop.example"}]}`),
+		"Takeout/Keep/Groceries.html": file(`<div class="note"><div class="title">Groceries (HTML)</div></div>`),
+		"Takeout/Keep/photo.jpg":      {Data: []byte("jpg")},
+		"Takeout/Keep/Old.json":       file(`{"title": "Old", "textContent": "deleted", "isTrashed": true}`),
+		"Takeout/Keep/Labels.json":    file(`{"labels": []}`),
+		"Takeout/Keep/Idea.html": file(`<html><body><div class="note"><div class="heading">Oct 2, 2026, 8:15:00` + " " + `AM</div>` +
+			`<div class="title">Idea</div><div class="content">first line<br>second &amp; last</div>` +
+			`<div class="chips"><span class="chip label"><span class="label-name">work</span></span></div></div></body></html>`),
+	}
+	nm := newNoteManagerWithNote(t, "Existing", "hello")
+	result, err := nm.ImportKeep(takeout, "", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Notes != 2 || result.Added != 2 || result.Files != 1 || result.Tasks != 2 {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Missing) != 1 || result.Missing[0] != "gone.png" {
+		t.Errorf("missing = %v", result.Missing)
+	}
+
+	byTitle := map[string]int{}
+	notes := nm.GetAllNotes()
+	for i, note := range notes {
+		byTitle[note.Title] = i
+	}
+	groceries, idea := notes[byTitle["Groceries"]], notes[byTitle["Idea"]]
+	if want := "- [ ] milk\n- [x] eggs six\n\n![photo](/assets/images/photo.jpg)\n\n- [Shop](https://shop.example)\n\n#Shopping-list"; groceries.Content != want {
+		t.Errorf("groceries = %q, want %q", groceries.Content, want)
+	}
+	if !groceries.Timestamp.Equal(wallClock(created)) || len(groceries.Tasks) != 2 {
+		t.Errorf("groceries at %v with %d task(s)", groceries.Timestamp, len(groceries.Tasks))
+	}
+	if idea.Content != "first line\nsecond & last\n\n#work" {
+		t.Errorf("idea = %q", idea.Content)
+	}
+	if got := idea.Timestamp.Format("2006-01-02 15:04:05"); got != "2026-10-02 08:15:00" {
+		t.Errorf("idea dated %s", got)
+	}
+
+	again, err := nm.ImportKeep(takeout, ImportMerge, time.Now())
+	if err != nil || again.Added != 0 || again.Skipped != 2 {
+		t.Errorf("importing again: %+v, %v", again, err)
+	}
+	if _, err := nm.ImportKeep(fstest.MapFS{"Takeout/Keep/Labels.json": file(`{}`)}, "", time.Now()); !errors.Is(err, ErrInvalidImport) {
+		t.Errorf("an export without notes: %v", err)
+	}
+}
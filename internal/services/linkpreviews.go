@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log"
+	"maps"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/linkpreview"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/safefetch"
+)
+
+// Link preview defaults; see models.LinkPreviewConfig.
+const (
+	defaultLinkPreviewMaxAge = 30 * 24 * time.Hour
+	linkPreviewRetry         = 24 * time.Hour // after a failed fetch
+	linkPreviewWorkers       = 2
+)
+
+// linkPreviews fetches the preview cards of plain URLs in the background
+// and keeps them in models.LinkPreviewsFile. It has its own lock: lookups
+// happen while notes render under the NoteManager read lock.
+type linkPreviews struct {
+	mu      sync.Mutex
+	cache   map[string]models.LinkPreview
+	pending map[string]bool
+	maxAge  time.Duration
+	slots   chan struct{}
+
+	fetch   func(url string) (*models.LinkPreview, error)
+	save    func(map[string]models.LinkPreview) error
+	updated func() // a preview arrived; rendered notes are stale
+}
+
+// SetLinkPreviewConfig turns preview cards for plain URLs on or off.
+// Previews are fetched from the pages themselves, through the archiver's
+// fetch limits, so links to private addresses get no card unless archiving
+// allows them.
+func (nm *NoteManager) SetLinkPreviewConfig(cfg models.LinkPreviewConfig) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	defer nm.renderCache.clear()
+
+	if !cfg.Enabled {
+		nm.previews = nil
+		nm.renderer.SetPreviewResolver(nil)
+		return
+	}
+	cache, err := nm.storage.LoadLinkPreviews()
+	if err != nil {
+		log.Printf("Warning: %v; link previews will be fetched again", err)
+		cache = make(map[string]models.LinkPreview)
+	}
+	p := &linkPreviews{
+		cache:   cache,
+		pending: make(map[string]bool),
+		maxAge:  time.Duration(cfg.MaxAgeDays) * 24 * time.Hour,
+		slots:   make(chan struct{}, linkPreviewWorkers),
+		fetch:   nm.fetchLinkPreview,
+		save:    nm.storage.SaveLinkPreviews,
+		updated: nm.renderCache.clear,
+	}
+	if p.maxAge <= 0 {
+		p.maxAge = defaultLinkPreviewMaxAge
+	}
+	nm.previews = p
+	nm.renderer.SetPreviewResolver(p.lookup)
+}
+
+// fetchLinkPreview reads a page's metadata within the archive limits.
+func (nm *NoteManager) fetchLinkPreview(pageURL string) (*models.LinkPreview, error) {
+	nm.mu.RLock()
+	limits := newArchiveLimits(nm.archiveConfig)
+	nm.mu.RUnlock()
+
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), limits.fetch.Timeout)
+	defer cancel()
+	if err := nm.archiveHosts.Wait(ctx, u.Hostname(), limits.domainDelay); err != nil {
+		return nil, err
+	}
+	return linkpreview.Fetch(ctx, safefetch.NewClient(limits.fetch), pageURL)
+}
+
+// lookup returns the cached preview of pageURL, and false when there is
+// none yet. A missing or outdated preview is fetched in the background.
+func (p *linkPreviews) lookup(pageURL string) (*models.LinkPreview, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	preview, ok := p.cache[pageURL]
+	maxAge := p.maxAge
+	if preview.Error != "" {
+		maxAge = linkPreviewRetry
+	}
+	if (!ok || time.Since(preview.FetchedAt) > maxAge) && !p.pending[pageURL] {
+		p.pending[pageURL] = true
+		go p.refresh(pageURL)
+	}
+	if !ok {
+		return nil, false
+	}
+	return &preview, true
+}
+
+// refresh fetches pageURL's preview and stores it, or the error.
+func (p *linkPreviews) refresh(pageURL string) {
+	p.slots <- struct{}{}
+	preview, err := p.fetch(pageURL)
+	<-p.slots
+	if err != nil {
+		preview = &models.LinkPreview{URL: pageURL, Error: err.Error()}
+	}
+	preview.FetchedAt = time.Now()
+
+	p.mu.Lock()
+	p.cache[pageURL] = *preview
+	delete(p.pending, pageURL)
+	snapshot := maps.Clone(p.cache)
+	p.mu.Unlock()
+
+	if err := p.save(snapshot); err != nil {
+		log.Printf("Warning: failed to save link previews: %v", err)
+	}
+	p.updated()
+}
+
+// previewURLRE matches a bare URL standing on its own: at the start of a
+// line or after a space, so not inside a markdown link, an autolink, an
+// HTML attribute or a +URL sigil.
+var previewURLRE = regexp.MustCompile(`(?m)(?:^|[ \t])(https?://[^\s<>()\[\]"'` + "`" + `]+)`)
+
+// noPreviewLineRE matches the lines that get no cards: headings, whose
+// text becomes anchors, and tasks, which render as bare checkbox lines.
+var noPreviewLineRE = regexp.MustCompile(`^\s*(?:#|[-*+]\s*\[[ xX/]\])`)
+
+// insertLinkPreviews adds a preview card after the first appearance of
+// each bare URL in content, or a pending marker while its preview is being
+// fetched.
+func (r *MarkdownRenderer) insertLinkPreviews(content string) string {
+	code := models.CodeRanges(content)
+	seen := make(map[string]bool)
+	var b strings.Builder
+	last := 0
+	for _, m := range previewURLRE.FindAllStringSubmatchIndex(content, -1) {
+		start := m[2]
+		if inRanges(start, code) {
+			continue
+		}
+		lineStart := strings.LastIndexByte(content[:start], '\n') + 1
+		if noPreviewLineRE.MatchString(content[lineStart:start]) {
+			continue
+		}
+		u := strings.TrimRight(content[start:m[3]], ".,;:!?")
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+		card := ` <span class="link-preview-pending"></span>`
+		if preview, ok := r.preview(u); ok {
+			if preview.Error != "" || preview.Title == "" {
+				continue
+			}
+			card = renderLinkPreview(preview)
+		}
+		end := start + len(u)
+		b.WriteString(content[last:end])
+		b.WriteString(card)
+		last = end
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// cardTextEscaper hides what markdown, the tag and mention passes, math
+// and autolinking would otherwise act on in card text.
+var cardTextEscaper = strings.NewReplacer(
+	"*", "&#42;", "_", "&#95;", "[", "&#91;", "]", "&#93;", "~", "&#126;",
+	"`", "&#96;", "\\", "&#92;", "|", "&#124;", "$", "&#36;", "#", "&#35;",
+	"@", "&#64;", ":", "&#58;", "www.", "www&#46;",
+)
+
+func cardText(s string) string {
+	return cardTextEscaper.Replace(template.HTMLEscapeString(s))
+}
+
+// renderLinkPreview renders a preview card, on one line so it stays
+// inline HTML within the link's paragraph. The image is a CSS background
+// so the lightbox pass leaves it alone.
+func renderLinkPreview(p *models.LinkPreview) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ` <a class="link-preview" href="%s" target="_blank" rel="noopener noreferrer" onclick="event.stopPropagation();">`,
+		template.HTMLEscapeString(p.URL))
+	if p.Image != "" && !strings.ContainsAny(p.Image, "'\"()\\<> \t\n") {
+		fmt.Fprintf(&b, `<span class="link-preview-image" style="background-image: url('%s')"></span>`, p.Image)
+	}
+	b.WriteString(`<span class="link-preview-body">`)
+	fmt.Fprintf(&b, `<span class="link-preview-title">%s</span>`, cardText(p.Title))
+	if p.Description != "" {
+		fmt.Fprintf(&b, `<span class="link-preview-desc">%s</span>`, cardText(p.Description))
+	}
+	if p.SiteName != "" {
+		fmt.Fprintf(&b, `<span class="link-preview-site">%s</span>`, cardText(p.SiteName))
+	}
+	b.WriteString(`</span></a>`)
+	return b.String()
+}
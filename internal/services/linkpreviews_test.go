@@ -0,0 +1,85 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestLinkPreviews(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "Read https://example.com/post, then [again](https://example.com/post)\n" +
+		"- [ ] task https://example.com/task\n`https://example.com/code`\nhttps://down.example/"
+	if err := mgr.AddNote("Reading", content); err != nil {
+		t.Fatal(err)
+	}
+	mgr.SetLinkPreviewConfig(models.LinkPreviewConfig{Enabled: true})
+	fetched := make(chan string, 10)
+	mgr.previews.fetch = func(u string) (*models.LinkPreview, error) {
+		fetched <- u
+		if strings.Contains(u, "down") {
+			return nil, errors.New("offline")
+		}
+		return &models.LinkPreview{URL: u, Title: "A *post* about #go", Description: "see www.example.org", Image: "https://example.com/i.png"}, nil
+	}
+
+	html, err := mgr.RenderNotesHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(html, `class="link-preview-pending"`); n != 2 {
+		t.Errorf("%d pending markers, want 2:\n%s", n, html)
+	}
+	got := map[string]bool{}
+	for range 2 {
+		select {
+		case u := <-fetched:
+			got[u] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("previews not fetched")
+		}
+	}
+	if !got["https://example.com/post"] || !got["https://down.example/"] {
+		t.Errorf("fetched %v", got)
+	}
+
+	// The render cache is cleared once the previews are stored.
+	deadline := time.Now().Add(5 * time.Second)
+	for strings.Contains(html, "link-preview-pending") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		html, _ = mgr.RenderNotesHTML()
+	}
+	if n := strings.Count(html, `<a class="link-preview"`); n != 1 {
+		t.Fatalf("%d cards, want 1:\n%s", n, html)
+	}
+	for _, want := range []string{
+		`<span class="link-preview-title">A *post* about #go</span>`,
+		`<span class="link-preview-desc">see www.example.org</span>`,
+		`background-image: url('https://example.com/i.png')`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("card lacks %s:\n%s", want, html)
+		}
+	}
+
+	// A new manager reads the cache instead of fetching again.
+	again, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again.SetLinkPreviewConfig(models.LinkPreviewConfig{Enabled: true})
+	again.previews.fetch = func(u string) (*models.LinkPreview, error) {
+		t.Errorf("refetched %s", u)
+		return nil, errors.New("unexpected")
+	}
+	if html, _ := again.RenderNotesHTML(); !strings.Contains(html, `<a class="link-preview"`) {
+		t.Errorf("cached card missing:\n%s", html)
+	}
+}
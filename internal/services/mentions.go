@@ -0,0 +1,55 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// MentionCount is a person @mentioned in this folder and how many notes
+// mention them.
+type MentionCount struct {
+	Name  string `json:"name"` // as first written
+	Notes int    `json:"notes"`
+}
+
+// noteMentions reports whether note mentions name, ignoring case.
+func noteMentions(note *models.Note, name string) bool {
+	key := models.MentionKey(name)
+	for _, m := range note.Mentions {
+		if models.MentionKey(m) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Mentions lists the people @mentioned in any note, most mentioned first
+// and then by name.
+func (nm *NoteManager) Mentions() []MentionCount {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	counts := make(map[string]*MentionCount)
+	// Oldest first, so Name is how the person was first written.
+	for i := len(nm.notes) - 1; i >= 0; i-- {
+		for _, m := range nm.notes[i].Mentions {
+			key := models.MentionKey(m)
+			if counts[key] == nil {
+				counts[key] = &MentionCount{Name: m}
+			}
+			counts[key].Notes++
+		}
+	}
+	result := make([]MentionCount, 0, len(counts))
+	for _, c := range counts {
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Notes != result[j].Notes {
+			return result[i].Notes > result[j].Notes
+		}
+		return models.MentionKey(result[i].Name) < models.MentionKey(result[j].Name)
+	})
+	return result
+}
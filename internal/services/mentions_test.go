@@ -0,0 +1,43 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMentionsAndFilter(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range [][2]string{
+		{"Kickoff", "With @Ana and @bo. See [[Notes @ana]]"},
+		{"Review", "@ana signed off"},
+		{"Solo", "no one else; mail me at x@example.com"},
+	} {
+		if err := mgr.AddNote(n[0], n[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []MentionCount{{Name: "Ana", Notes: 2}, {Name: "bo", Notes: 1}}
+	if got := mgr.Mentions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Mentions = %+v, want %+v", got, want)
+	}
+
+	html, err := mgr.RenderNotesHTMLFiltered("", "ANA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, "Review") || !strings.Contains(html, "Kickoff") || strings.Contains(html, "Solo") {
+		t.Errorf("mention filter kept the wrong notes:\n%s", html)
+	}
+	if !strings.Contains(html, `<a class="mention-link" href="?mention=Ana" data-mention="Ana">@Ana</a>`) {
+		t.Errorf("mention not linked:\n%s", html)
+	}
+	// The wiki link's "@ana" stays inside the link.
+	if !strings.Contains(html, `>Notes @ana</span>`) {
+		t.Errorf("wiki link label rewritten:\n%s", html)
+	}
+}
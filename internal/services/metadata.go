@@ -0,0 +1,57 @@
+package services
+
+import (
+	"strings"
+	"time"
+)
+
+// NoteMetadata is one note's frontmatter in a metadata query result.
+type NoteMetadata struct {
+	Index     int               `json:"index"`
+	Title     string            `json:"title"`
+	Timestamp time.Time         `json:"timestamp"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+// metadataMatches reports whether value satisfies want: an empty want only
+// needs the key to be present, otherwise the value, or one item of a list
+// value, must equal want ignoring case.
+func metadataMatches(value, want string) bool {
+	if want == "" || strings.EqualFold(value, want) {
+		return true
+	}
+	for _, item := range strings.Split(value, ",") {
+		if strings.EqualFold(strings.TrimSpace(item), want) {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryMetadata returns the notes with frontmatter matching every filter,
+// newest first. Filters map a key to the wanted value; see metadataMatches.
+// No filters lists every note that has frontmatter.
+func (nm *NoteManager) QueryMetadata(filters map[string]string) []NoteMetadata {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	result := []NoteMetadata{}
+notes:
+	for i, note := range nm.notes {
+		if len(note.Metadata) == 0 {
+			continue
+		}
+		for key, want := range filters {
+			value, ok := note.Metadata[key]
+			if !ok || !metadataMatches(value, want) {
+				continue notes
+			}
+		}
+		meta := make(map[string]string, len(note.Metadata))
+		for k, v := range note.Metadata {
+			meta[k] = v
+		}
+		result = append(result, NoteMetadata{Index: i, Title: note.Title, Timestamp: note.Timestamp, Metadata: meta})
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQueryMetadataAndRender(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range [][2]string{
+		{"Plain", "no frontmatter"},
+		{"Draft", "---\nstatus: draft\ntags: [q4, planning]\n---\nbody"},
+		{"Done", "---\nstatus: Done\n---\nbody"},
+	} {
+		if err := mgr.AddNote(n[0], n[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	titles := func(filters map[string]string) string {
+		var out []string
+		for _, m := range mgr.QueryMetadata(filters) {
+			out = append(out, m.Title)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := titles(nil); got != "Done,Draft" {
+		t.Errorf("all = %s", got)
+	}
+	if got := titles(map[string]string{"status": "done"}); got != "Done" {
+		t.Errorf("status=done = %s", got)
+	}
+	if got := titles(map[string]string{"tags": "Q4", "status": "draft"}); got != "Draft" {
+		t.Errorf("tags=q4&status=draft = %s", got)
+	}
+	if got := titles(map[string]string{"tags": ""}); got != "Draft" {
+		t.Errorf("tags present = %s", got)
+	}
+
+	html, err := mgr.RenderNotesHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, `<dl class="note-metadata"><dt>status</dt><dd>draft</dd><dt>tags</dt><dd>q4, planning</dd></dl>`) {
+		t.Errorf("metadata block missing:\n%s", html)
+	}
+	if strings.Contains(html, "<hr") {
+		t.Errorf("frontmatter fences rendered as rules:\n%s", html)
+	}
+}
@@ -0,0 +1,437 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// schemaMigration is one step of the task DB's schema. up moves a DB at
+// version-1 to version and down moves it back; each runs in a
+// transaction that also records the step in schema_version.
+type schemaMigration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+	down    func(tx *sql.Tx) error
+}
+
+// schemaMigrations are the task DB's schema steps, oldest first; a
+// step's version is its position, from 1. Append new steps, never edit or
+// reorder shipped ones: a DB records the steps it has by version and
+// name.
+//
+// Steps 1 to 8 predate schema_version and had been applied with
+// CREATE IF NOT EXISTS and addColumnIfMissing, so they stay idempotent:
+// a DB from before this table runs them all and only records them.
+var schemaMigrations = []schemaMigration{
+	{1, "folders and tasks", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS folders (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				path TEXT UNIQUE NOT NULL,
+				last_scan DATETIME DEFAULT CURRENT_TIMESTAMP,
+				active BOOLEAN DEFAULT 1
+			);
+			CREATE TABLE IF NOT EXISTS tasks (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				folder_id INTEGER NOT NULL,
+				file_path TEXT NOT NULL,
+				line_number INTEGER NOT NULL,
+				content TEXT NOT NULL,
+				completed BOOLEAN DEFAULT 0,
+				last_updated DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE CASCADE
+			);
+			CREATE INDEX IF NOT EXISTS idx_tasks_folder ON tasks(folder_id);
+			CREATE INDEX IF NOT EXISTS idx_tasks_completed ON tasks(completed);
+			CREATE INDEX IF NOT EXISTS idx_tasks_folder_file ON tasks(folder_id, file_path);
+		`)
+		return err
+	}, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DROP TABLE tasks; DROP TABLE folders;`)
+		return err
+	}},
+
+	// task_hash (2026-05-12) gives tasks IDs stable across syncs; see
+	// docs/20260512_task_db_schema.md §4. The index comes after the
+	// column, which a DB from before it doesn't have.
+	{2, "task hashes", func(tx *sql.Tx) error {
+		if err := addColumnIfMissing(tx, "tasks", "task_hash", "TEXT"); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_tasks_hash ON tasks(folder_id, task_hash)`)
+		return err
+	}, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DROP INDEX idx_tasks_hash; ALTER TABLE tasks DROP COLUMN task_hash;`)
+		return err
+	}},
+
+	// due_date holds the parsed due token as "YYYY-MM-DD" or
+	// "YYYY-MM-DDTHH:MM" (models.FormatDueValue), NULL when the task has
+	// none.
+	{3, "due dates", func(tx *sql.Tx) error {
+		if err := addColumnIfMissing(tx, "tasks", "due_date", "TEXT"); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_tasks_due ON tasks(due_date)`)
+		return err
+	}, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DROP INDEX idx_tasks_due; ALTER TABLE tasks DROP COLUMN due_date;`)
+		return err
+	}},
+
+	// note_id and char_offset locate a task in its folder's notes.md
+	// together with line_number; see models.Task.
+	{4, "task positions", func(tx *sql.Tx) error {
+		if err := addColumnIfMissing(tx, "tasks", "note_id", "TEXT"); err != nil {
+			return err
+		}
+		return addColumnIfMissing(tx, "tasks", "char_offset", "INTEGER NOT NULL DEFAULT 0")
+	}, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`ALTER TABLE tasks DROP COLUMN char_offset; ALTER TABLE tasks DROP COLUMN note_id;`)
+		return err
+	}},
+
+	// Saved views for the `noteflow tasks` CLI. No FK: views reference
+	// filter shapes, not specific tasks.
+	{5, "saved views", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS task_views (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT UNIQUE NOT NULL,
+				filters TEXT NOT NULL,
+				created DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+		`)
+		return err
+	}, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DROP TABLE task_views`)
+		return err
+	}},
+
+	// Accounts for multi-user mode. A folder's user_id is the account that
+	// registered it; NULL means the server's owner, so every folder of a
+	// single-user install stays the owner's.
+	{6, "users", func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS users (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT UNIQUE NOT NULL COLLATE NOCASE,
+				password_hash TEXT NOT NULL,
+				notes_root TEXT NOT NULL,
+				created DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+		`); err != nil {
+			return err
+		}
+		return addColumnIfMissing(tx, "folders", "user_id", "INTEGER REFERENCES users(id) ON DELETE CASCADE")
+	}, func(tx *sql.Tx) error {
+		// SQLite can't drop a column with a foreign key, so folders is
+		// rebuilt without it; MigrateTo turns foreign keys off around a
+		// down so dropping the old table doesn't cascade into tasks.
+		_, err := tx.Exec(`
+			CREATE TABLE folders_old (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				path TEXT UNIQUE NOT NULL,
+				last_scan DATETIME DEFAULT CURRENT_TIMESTAMP,
+				active BOOLEAN DEFAULT 1
+			);
+			INSERT INTO folders_old (id, path, last_scan, active)
+				SELECT id, path, last_scan, active FROM folders;
+			DROP TABLE folders;
+			ALTER TABLE folders_old RENAME TO folders;
+			DROP TABLE users;
+		`)
+		return err
+	}},
+
+	// Results of requests made with an Idempotency-Key, per user and
+	// notes folder; see IdempotencyStore.
+	{7, "idempotency keys", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS idempotency_keys (
+				user_id INTEGER NOT NULL,
+				folder TEXT NOT NULL,
+				key TEXT NOT NULL,
+				request_hash TEXT NOT NULL,
+				status INTEGER NOT NULL,
+				content_type TEXT NOT NULL,
+				body BLOB NOT NULL,
+				created DATETIME NOT NULL,
+				PRIMARY KEY (user_id, folder, key)
+			);
+			CREATE INDEX IF NOT EXISTS idx_idempotency_created ON idempotency_keys(created);
+		`)
+		return err
+	}, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DROP TABLE idempotency_keys`)
+		return err
+	}},
+
+	// The audit log of changes made through the API, per user and notes
+	// folder; see AuditLog.
+	{8, "audit log", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS audit_log (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL,
+				folder TEXT NOT NULL,
+				time DATETIME NOT NULL,
+				ip TEXT NOT NULL,
+				request TEXT NOT NULL,
+				action TEXT NOT NULL,
+				note_id TEXT NOT NULL,
+				title TEXT NOT NULL,
+				before TEXT NOT NULL,
+				after TEXT NOT NULL
+			);
+			CREATE INDEX IF NOT EXISTS idx_audit_folder_time ON audit_log(user_id, folder, time);
+		`)
+		return err
+	}, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DROP TABLE audit_log`)
+		return err
+	}},
+
+	// A display alias and a group for each folder, which the global tasks
+	// page and summaries show instead of the path; see SetFolderLabels.
+	{9, "folder labels", func(tx *sql.Tx) error {
+		if err := addColumnIfMissing(tx, "folders", "alias", "TEXT"); err != nil {
+			return err
+		}
+		return addColumnIfMissing(tx, "folders", "group_name", "TEXT")
+	}, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`ALTER TABLE folders DROP COLUMN group_name; ALTER TABLE folders DROP COLUMN alias;`)
+		return err
+	}},
+
+	// Tasks added, completed, reopened and removed, for streaks and
+	// burndown charts; see TaskHistory. Existing tasks are recorded as
+	// added, and the done ones as completed, when they last changed.
+	{10, "task history", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE task_history (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				folder_id INTEGER NOT NULL,
+				task_hash TEXT NOT NULL,
+				content TEXT NOT NULL,
+				event TEXT NOT NULL,
+				completed BOOLEAN NOT NULL DEFAULT 0,
+				at DATETIME NOT NULL,
+				FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE CASCADE
+			);
+			CREATE INDEX idx_task_history_folder_at ON task_history(folder_id, at);
+			INSERT INTO task_history (folder_id, task_hash, content, event, completed, at)
+				SELECT folder_id, task_hash, content, 'added', 0, last_updated FROM tasks WHERE task_hash IS NOT NULL;
+			INSERT INTO task_history (folder_id, task_hash, content, event, completed, at)
+				SELECT folder_id, task_hash, content, 'completed', 1, last_updated FROM tasks WHERE task_hash IS NOT NULL AND completed = 1;
+		`)
+		return err
+	}, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DROP TABLE task_history`)
+		return err
+	}},
+
+	// Copies of a task in several folders, linked so completing one
+	// completes the others; see LinkTasks.
+	{11, "task links", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE task_links (
+				folder_id INTEGER NOT NULL,
+				task_hash TEXT NOT NULL,
+				link_id INTEGER NOT NULL,
+				PRIMARY KEY (folder_id, task_hash),
+				FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE CASCADE
+			);
+			CREATE INDEX idx_task_links_link ON task_links(link_id);
+		`)
+		return err
+	}, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DROP TABLE task_links`)
+		return err
+	}},
+}
+
+// ErrSchemaTooNew is returned for a task DB migrated by a newer NoteFlow
+// than this one.
+var ErrSchemaTooNew = errors.New("task DB schema is newer than this NoteFlow")
+
+// LatestSchemaVersion is the version of the task DB schema this build
+// migrates to.
+func LatestSchemaVersion() int {
+	return len(schemaMigrations)
+}
+
+// SchemaStep is an applied step of the task DB schema.
+type SchemaStep struct {
+	Version int       `json:"version"`
+	Name    string    `json:"name"`
+	Applied time.Time `json:"applied"`
+}
+
+// migrate brings the DB to the latest schema version, after checking the
+// steps it records are the ones this build knows.
+func (ds *DatabaseService) migrate() error {
+	if _, err := ds.db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+	`); err != nil {
+		return err
+	}
+	if err := ds.validateSchema(); err != nil {
+		return err
+	}
+	return ds.MigrateTo(LatestSchemaVersion())
+}
+
+// validateSchema checks schema_version: its steps must be 1..n with the
+// names this build gives them, and n no later than the latest.
+func (ds *DatabaseService) validateSchema() error {
+	steps, err := ds.SchemaSteps()
+	if err != nil {
+		return err
+	}
+	for i, step := range steps {
+		if step.Version > LatestSchemaVersion() {
+			return fmt.Errorf("%w: it is at version %d, this build knows %d; upgrade NoteFlow, or run 'noteflow db migrate %d' with the newer one",
+				ErrSchemaTooNew, steps[len(steps)-1].Version, LatestSchemaVersion(), LatestSchemaVersion())
+		}
+		if step.Version != i+1 {
+			return fmt.Errorf("task DB schema_version is missing version %d", i+1)
+		}
+		if want := schemaMigrations[i].name; step.Name != want {
+			return fmt.Errorf("task DB schema version %d is %q, this build expects %q", step.Version, step.Name, want)
+		}
+	}
+	return nil
+}
+
+// SchemaSteps returns the schema steps applied to the DB, oldest first.
+func (ds *DatabaseService) SchemaSteps() ([]SchemaStep, error) {
+	rows, err := ds.db.Query(`SELECT version, name, applied FROM schema_version ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var steps []SchemaStep
+	for rows.Next() {
+		var step SchemaStep
+		if err := rows.Scan(&step.Version, &step.Name, &step.Applied); err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, rows.Err()
+}
+
+// SchemaVersion returns the DB's schema version: its latest step, 0 for
+// none.
+func (ds *DatabaseService) SchemaVersion() (int, error) {
+	var version int
+	err := ds.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	return version, err
+}
+
+// MigrateTo runs the up steps after the DB's version through version, or
+// the down steps back to it, each in its own transaction. Down steps drop
+// what their up added, data included; 0 leaves an empty DB.
+func (ds *DatabaseService) MigrateTo(version int) error {
+	if version < 0 || version > LatestSchemaVersion() {
+		return fmt.Errorf("no schema version %d (latest is %d)", version, LatestSchemaVersion())
+	}
+	current, err := ds.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if current == version {
+		return nil
+	}
+
+	// One connection throughout: the foreign_keys pragma is per connection
+	// and can't change inside a transaction.
+	ctx := context.Background()
+	conn, err := ds.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if version < current {
+		if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+			return err
+		}
+		defer conn.ExecContext(ctx, `PRAGMA foreign_keys = ON`)
+	}
+
+	for current != version {
+		var (
+			step   schemaMigration
+			run    func(tx *sql.Tx) error
+			record string
+			next   int
+		)
+		if version > current {
+			step, next = schemaMigrations[current], current+1
+			run, record = step.up, `INSERT INTO schema_version (version, name) VALUES (?, ?)`
+		} else {
+			step, next = schemaMigrations[current-1], current-1
+			run, record = step.down, `DELETE FROM schema_version WHERE version = ? AND name = ?`
+		}
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if err := run(tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("schema version %d (%s): %w", step.version, step.name, err)
+		}
+		if _, err := tx.Exec(record, step.version, step.name); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		current = next
+	}
+	return nil
+}
+
+// addColumnIfMissing is the migration helper for a column added to an
+// existing table. SQLite's `ALTER TABLE ADD COLUMN` errors if the column
+// already exists, as it does on a DB whose steps predate schema_version,
+// so we check first via PRAGMA table_info.
+func addColumnIfMissing(tx *sql.Tx, table, column, sqlType string) error {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("check columns on %s: %w", table, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			cid         int
+			name, ctype string
+			notnull, pk int
+			dflt        sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notnull, &dflt, &pk); err != nil {
+			return fmt.Errorf("scan column info: %w", err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+	if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, sqlType)); err != nil {
+		return fmt.Errorf("add column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
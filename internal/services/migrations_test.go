@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestMigrateTo_DownAndUp(t *testing.T) {
+	svc, folder := newTestDB(t)
+	if err := svc.SyncFolderTasks(folder.ID, []models.Task{{Text: "- [ ] keep me @due(2026-05-12)"}}); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := svc.SchemaVersion(); err != nil || v != LatestSchemaVersion() {
+		t.Fatalf("SchemaVersion = %d, %v; want %d", v, err, LatestSchemaVersion())
+	}
+
+	// Back to before users: the folder and its task survive the rebuild
+	// of folders, the task_hash column stays.
+	if err := svc.MigrateTo(5); err != nil {
+		t.Fatalf("MigrateTo(5): %v", err)
+	}
+	var n int
+	if err := svc.db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE folder_id = ? AND task_hash IS NOT NULL`, folder.ID).Scan(&n); err != nil || n != 1 {
+		t.Errorf("tasks after down = %d, %v", n, err)
+	}
+	for _, table := range []string{"users", "audit_log", "idempotency_keys"} {
+		if err := svc.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = ?`, table).Scan(&n); err != nil || n != 0 {
+			t.Errorf("%s still there after down: %d, %v", table, n, err)
+		}
+	}
+	if err := svc.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('folders') WHERE name = 'user_id'`).Scan(&n); err != nil || n != 0 {
+		t.Errorf("folders.user_id still there after down: %d, %v", n, err)
+	}
+
+	// And up again.
+	if err := svc.MigrateTo(LatestSchemaVersion()); err != nil {
+		t.Fatalf("MigrateTo(latest): %v", err)
+	}
+	if _, err := svc.RegisterFolder("/tmp/another"); err != nil {
+		t.Errorf("RegisterFolder after up: %v", err)
+	}
+	steps, err := svc.SchemaSteps()
+	if err != nil || len(steps) != LatestSchemaVersion() || steps[5].Name != "users" {
+		t.Errorf("SchemaSteps = %+v, %v", steps, err)
+	}
+	if err := svc.MigrateTo(LatestSchemaVersion() + 1); err == nil {
+		t.Error("MigrateTo past the latest version succeeded")
+	}
+}
+
+func TestMigrate_RefusesNewerSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tasks.db")
+	svc, err := NewDatabaseServiceAt(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.db.Exec(`INSERT INTO schema_version (version, name) VALUES (?, 'from the future')`, LatestSchemaVersion()+1); err != nil {
+		t.Fatal(err)
+	}
+	svc.Close()
+
+	if _, err := NewDatabaseServiceAt(dbPath); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("opening a newer DB: err = %v, want ErrSchemaTooNew", err)
+	}
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"html"
 	"log"
@@ -12,8 +14,11 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf16"
 
 	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/notify"
+	"github.com/Xafloc/NoteFlow-Go/internal/safefetch"
 	"github.com/Xafloc/NoteFlow-Go/internal/storage"
 	"github.com/go-shiori/obelisk"
 )
@@ -26,6 +31,35 @@ type NoteManager struct {
 	renderer      *MarkdownRenderer
 	mu            sync.RWMutex
 	needsSave     bool
+	notifier      notify.Notifier // optional; alerts on archive failures
+	events        *EventHub       // optional; see SetEvents
+	taskListeners []func(models.Task)
+	tagIndex      map[string][]*models.Note // exact tag -> notes using it, newest first; see rebuildIndexes
+	generation    uint64                    // bumped whenever notes change; see Generation
+	trashDays     int                       // auto-purge age for trash.md; see SetTrashRetention
+	titleIndex    map[string]int            // WikiLinkKey(title) -> newest note with it; see rebuildLinkIndexes
+	backlinks     map[string][]int          // link target -> indices of notes linking to it
+	diskStamp     fileStamp                 // notes.md as last loaded or saved; see ReloadIfChanged
+	base          string                    // the notes as last loaded or saved, rendered; see mergeFromDisk
+	saveDelay     time.Duration             // see SetSaveDelay
+	location      *time.Location            // due-date phrases resolve here; see SetLocation
+	saveTimer     *time.Timer               // writes changes held by saveDelay; nil when none are
+	renderCache   *renderCache              // rendered note HTML; see RenderNotesHTMLFiltered
+	stopPolling   chan struct{}             // see StartRemotePolling
+
+	// archive fetches a +URL page; it is archiveWebsite outside tests.
+	archive       func(archiveSpec) (*ArchiveInfo, error)
+	archiveConfig models.ArchiveConfig // see SetArchiveConfig
+	// archives is nil while +URLs are archived during save; see
+	// StartArchiveQueue.
+	archives *archiveQueue
+	// bulkArchiving is held while BulkArchive runs.
+	bulkArchiving sync.Mutex
+	// archiveHosts spaces out archives of pages on one host.
+	archiveHosts *safefetch.HostLimiter
+	// previews is nil unless link preview cards are on; see
+	// SetLinkPreviewConfig.
+	previews *linkPreviews
 }
 
 // NewNoteManager creates a new note manager for the given base path
@@ -43,8 +77,14 @@ func NewNoteManager(basePath string) (*NoteManager, error) {
 		checkboxIndex: 0,
 		storage:       storage,
 		renderer:      renderer,
+		trashDays:     models.DefaultTrashRetentionDays,
+		archiveHosts:  safefetch.NewHostLimiter(),
+		renderCache:   newRenderCache(),
 	}
 
+	renderer.SetLinkResolver(manager.resolveWikiLink)
+	manager.archive = manager.archiveWebsite
+
 	// Load existing notes
 	if err := manager.loadNotes(); err != nil {
 		return nil, fmt.Errorf("failed to load notes: %w", err)
@@ -53,6 +93,45 @@ func NewNoteManager(basePath string) (*NoteManager, error) {
 	return manager, nil
 }
 
+// SetNotifier attaches a push channel used to report archive failures.
+// Passing nil disables alerts.
+func (nm *NoteManager) SetNotifier(n notify.Notifier) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.notifier = n
+}
+
+// SetEvents attaches a hub that hears about every saved change and
+// every reload of notes.md. Passing nil disables events.
+func (nm *NoteManager) SetEvents(h *EventHub) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.events = h
+}
+
+// SetLocation sets the zone natural-language due dates ("tomorrow 5pm")
+// resolve in. Nil, the default, is the system zone.
+func (nm *NoteManager) SetLocation(loc *time.Location) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.location = loc
+}
+
+// now returns the current time in the zone set by SetLocation.
+func (nm *NoteManager) now() time.Time {
+	if nm.location == nil {
+		return time.Now()
+	}
+	return time.Now().In(nm.location)
+}
+
+// SetArchiveConfig sets the folder-wide +URL archive options.
+func (nm *NoteManager) SetArchiveConfig(cfg models.ArchiveConfig) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.archiveConfig = cfg
+}
+
 // loadNotes loads all notes from storage
 func (nm *NoteManager) loadNotes() error {
 	notes, err := nm.storage.LoadNotes()
@@ -63,8 +142,8 @@ func (nm *NoteManager) loadNotes() error {
 	nm.mu.Lock()
 	defer nm.mu.Unlock()
 
-	nm.notes = notes
-	nm.assignTaskIndices()
+	nm.setNotes(notes)
+	nm.synced()
 
 	return nil
 }
@@ -73,10 +152,7 @@ func (nm *NoteManager) loadNotes() error {
 func (nm *NoteManager) assignTaskIndices() {
 	index := 0
 	for _, note := range nm.notes {
-		for _, task := range note.Tasks {
-			task.Index = index
-			index++
-		}
+		index = note.AssignTaskIndices(index)
 	}
 	nm.checkboxIndex = index
 }
@@ -85,50 +161,75 @@ func (nm *NoteManager) assignTaskIndices() {
 func (nm *NoteManager) AddNote(title, content string) error {
 	nm.mu.Lock()
 	defer nm.mu.Unlock()
+	nm.refresh()
 
-	// Process any +http links and +file: snippets in content.
+	// Process any +http links, +file: snippets and natural-language due
+	// dates in content.
 	processedContent, err := nm.processArchiveLinks(content)
 	if err != nil {
 		// Log error but continue with original content
 		processedContent = content
 	}
 	processedContent = nm.processCodeSnippets(processedContent)
+	processedContent = expandDueDates(processedContent, nm.now())
 
 	note := models.NewNote(title, processedContent)
 	
 	// Assign task indices
-	for _, task := range note.Tasks {
-		task.Index = nm.checkboxIndex
-		nm.checkboxIndex++
-	}
+	nm.checkboxIndex = note.AssignTaskIndices(nm.checkboxIndex)
 
 	// Insert at the beginning (newest first)
 	nm.notes = append([]*models.Note{note}, nm.notes...)
 	nm.needsSave = true
 
-	return nm.save()
+	return nm.saveEvent(Event{Type: EventNoteCreated, NoteID: note.HistoryKey(), Title: note.Title})
 }
 
 // UpdateNote updates an existing note
 func (nm *NoteManager) UpdateNote(index int, title, content string) error {
+	return nm.UpdateNoteAt(index, "", title, content)
+}
+
+// NoteConflictError is returned by UpdateNoteAt when the note is no longer
+// at the version the edit started from.
+type NoteConflictError struct {
+	Current models.Note // a copy of the note as it is now
+}
+
+func (e *NoteConflictError) Error() string {
+	return "note was changed since the edit started (now at version " + e.Current.Version() + ")"
+}
+
+// UpdateNoteAt is UpdateNote for an edit that started from version of the
+// note. If the note has changed since, whether edited elsewhere or
+// because the index now holds another note, nothing is saved and the
+// error is a *NoteConflictError. An empty version always overwrites.
+func (nm *NoteManager) UpdateNoteAt(index int, version, title, content string) error {
 	nm.mu.Lock()
 	defer nm.mu.Unlock()
+	nm.refresh()
 
 	if index < 0 || index >= len(nm.notes) {
 		return fmt.Errorf("note index %d out of range", index)
 	}
+	if version != "" && nm.notes[index].Version() != version {
+		return &NoteConflictError{Current: *nm.notes[index]}
+	}
 
-	// Process any +http links and +file: snippets in content.
+	// Process any +http links, +file: snippets and natural-language due
+	// dates in content.
 	processedContent, err := nm.processArchiveLinks(content)
 	if err != nil {
 		// Log error but continue with original content
 		processedContent = content
 	}
 	processedContent = nm.processCodeSnippets(processedContent)
+	processedContent = expandDueDates(processedContent, nm.now())
 
 	note := nm.notes[index]
 	oldTaskCount := len(note.Tasks)
 
+	nm.saveRevision(note, title, processedContent)
 	note.Update(title, processedContent)
 
 	// Update task indices if task count changed
@@ -137,26 +238,33 @@ func (nm *NoteManager) UpdateNote(index int, title, content string) error {
 	}
 
 	nm.needsSave = true
-	return nm.save()
+	return nm.saveEvent(Event{Type: EventNoteUpdated, NoteID: note.HistoryKey(), Title: note.Title})
 }
 
-// DeleteNote removes a note from the collection
+// DeleteNote moves a note to the trash (see RestoreTrashedNote)
 func (nm *NoteManager) DeleteNote(index int) error {
 	nm.mu.Lock()
 	defer nm.mu.Unlock()
+	nm.refresh()
 
 	if index < 0 || index >= len(nm.notes) {
 		return fmt.Errorf("note index %d out of range", index)
 	}
 
+	// Keep a copy in trash.md before the note leaves notes.md
+	if err := nm.moveToTrash(nm.notes[index]); err != nil {
+		return err
+	}
+
 	// Remove note from slice
+	deleted := nm.notes[index]
 	nm.notes = append(nm.notes[:index], nm.notes[index+1:]...)
 	
 	// Reassign all task indices since we removed a note
 	nm.assignTaskIndices()
 	
 	nm.needsSave = true
-	return nm.save()
+	return nm.saveEvent(Event{Type: EventNoteDeleted, NoteID: deleted.HistoryKey(), Title: deleted.Title})
 }
 
 // GetNote returns a note by index
@@ -171,6 +279,17 @@ func (nm *NoteManager) GetNote(index int) (*models.Note, error) {
 	return nm.notes[index], nil
 }
 
+// RawNotes returns notes.md exactly as it is on disk, once changes held
+// by the save delay are written.
+func (nm *NoteManager) RawNotes() ([]byte, error) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if err := nm.write(); err != nil {
+		return nil, err
+	}
+	return nm.storage.ReadNotesFile()
+}
+
 // GetAllNotes returns all notes
 func (nm *NoteManager) GetAllNotes() []*models.Note {
 	nm.mu.RLock()
@@ -198,57 +317,313 @@ func (nm *NoteManager) GetActiveTasks() []*models.TaskInfo {
 func (nm *NoteManager) UpdateTask(taskIndex int, checked bool) error {
 	nm.mu.Lock()
 	defer nm.mu.Unlock()
+	nm.refresh()
 
 	// Find the task across all notes
 	for _, note := range nm.notes {
 		if note.UpdateTask(taskIndex, checked) {
 			nm.needsSave = true
-			return nm.save()
+			if err := nm.saveEvent(taskEvent(note, taskIndex)); err != nil {
+				return err
+			}
+			nm.emitTaskToggle(note, taskIndex)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("task with index %d not found", taskIndex)
+}
+
+// UpdateTaskCascade checks or unchecks a task together with all of its
+// subtasks in one save. Subtasks already in the requested state are left
+// alone and don't notify toggle listeners.
+func (nm *NoteManager) UpdateTaskCascade(taskIndex int, checked bool) error {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.refresh()
+
+	for _, note := range nm.notes {
+		if !note.UpdateTask(taskIndex, checked) {
+			continue
 		}
+		changed := []int{taskIndex}
+		for _, sub := range note.Subtasks(taskIndex) {
+			if sub.Checked != checked && note.UpdateTask(sub.Index, checked) {
+				changed = append(changed, sub.Index)
+			}
+		}
+		nm.needsSave = true
+		if err := nm.saveEvent(taskEvent(note, taskIndex)); err != nil {
+			return err
+		}
+		for _, index := range changed {
+			nm.emitTaskToggle(note, index)
+		}
+		return nil
 	}
 
 	return fmt.Errorf("task with index %d not found", taskIndex)
 }
 
+// UpdateTaskText rewrites a task's full line (checkbox included). Used by
+// integrations that annotate tasks, e.g. appending a linked issue URL.
+func (nm *NoteManager) UpdateTaskText(taskIndex int, text string) error {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.refresh()
+
+	for _, note := range nm.notes {
+		if note.SetTaskText(taskIndex, text) {
+			nm.needsSave = true
+			return nm.save()
+		}
+	}
+	return fmt.Errorf("task with index %d not found", taskIndex)
+}
+
+// EditNoteByTitle applies edit to the content of the newest note titled
+// title, creating that note first when none exists. The read-modify-write
+// runs under the manager lock so it can't race a save from the UI. When
+// edit returns the content unchanged nothing is written.
+func (nm *NoteManager) EditNoteByTitle(title string, edit func(content string) string) error {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.refresh()
+
+	var target *models.Note
+	for _, note := range nm.notes {
+		if note.Title == title {
+			target = note
+			break
+		}
+	}
+	current := ""
+	if target != nil {
+		current = target.Content
+	}
+	updated := edit(current)
+	if updated == current {
+		return nil
+	}
+
+	if target == nil {
+		nm.notes = append([]*models.Note{models.NewNote(title, updated)}, nm.notes...)
+	} else {
+		target.Update(title, updated)
+	}
+	nm.assignTaskIndices()
+	nm.needsSave = true
+	return nm.save()
+}
+
+// GetTask returns a copy of the task with the given global index.
+func (nm *NoteManager) GetTask(taskIndex int) (models.Task, error) {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	for _, note := range nm.notes {
+		for _, task := range note.Tasks {
+			if task.Index == taskIndex {
+				return *task, nil
+			}
+		}
+	}
+	return models.Task{}, fmt.Errorf("task with index %d not found", taskIndex)
+}
+
+// LastModified returns the modification time of notes.md, i.e. when any
+// note in this folder last changed. Zero when the file can't be read.
+func (nm *NoteManager) LastModified() time.Time {
+	mod, _, err := nm.storage.NotesStamp()
+	if err != nil {
+		return time.Time{}
+	}
+	return mod
+}
+
+// OnTaskToggle registers fn to run after a task's checked state is saved.
+// Listeners run on their own goroutine so slow integrations (issue trackers,
+// webhooks) never hold up the save path.
+func (nm *NoteManager) OnTaskToggle(fn func(models.Task)) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.taskListeners = append(nm.taskListeners, fn)
+}
+
+// emitTaskToggle notifies listeners about the task at taskIndex in note.
+// Callers hold nm.mu.
+func (nm *NoteManager) emitTaskToggle(note *models.Note, taskIndex int) {
+	if len(nm.taskListeners) == 0 {
+		return
+	}
+	for _, task := range note.Tasks {
+		if task.Index != taskIndex {
+			continue
+		}
+		snapshot := *task
+		listeners := append([]func(models.Task){}, nm.taskListeners...)
+		go func() {
+			for _, fn := range listeners {
+				fn(snapshot)
+			}
+		}()
+		return
+	}
+}
+
 // RenderNotesHTML returns HTML representation of all notes
 func (nm *NoteManager) RenderNotesHTML() (string, error) {
+	return nm.RenderNotesHTMLFiltered("", "")
+}
+
+// RenderNotesHTMLFiltered renders only the notes carrying tag or a tag
+// nested under it and mentioning @mention; empty filters match every note.
+// Note indices in the output stay the global ones so edit/delete keep
+// working while filtered.
+func (nm *NoteManager) RenderNotesHTMLFiltered(tag, mention string) (string, error) {
 	nm.mu.RLock()
 	defer nm.mu.RUnlock()
 
 	var htmlParts []string
+	var tagged map[*models.Note]bool
+	if tag != "" {
+		tagged = nm.notesWithTag(tag)
+	}
+	used := make(map[[sha256.Size]byte]bool, len(nm.notes))
 
 	for i, note := range nm.notes {
+		if tagged != nil && !tagged[note] {
+			continue
+		}
+		if mention != "" && !noteMentions(note, mention) {
+			continue
+		}
 		timestamp := note.Timestamp.Format("2006-01-02 15:04:05")
 		titleDisplay := timestamp
 		if note.Title != "" {
 			titleDisplay += " - " + note.Title
 		}
 
-		noteHTML, err := nm.renderer.RenderNoteHTML(note.Content, titleDisplay, note.Title, i)
-		if err != nil {
-			return "", fmt.Errorf("failed to render note %d: %w", i, err)
+		key := renderCacheKey(i, titleDisplay, note.Content)
+		used[key] = true
+		noteHTML, ok := nm.renderCache.get(key)
+		if !ok {
+			var err error
+			noteHTML, err = nm.renderer.RenderNoteHTML(note.Content, titleDisplay, note.Title, i)
+			if err != nil {
+				return "", fmt.Errorf("failed to render note %d: %w", i, err)
+			}
+			nm.renderCache.put(key, noteHTML)
 		}
 
 		htmlParts = append(htmlParts, noteHTML)
 	}
+	if tag == "" && mention == "" {
+		nm.renderCache.retain(used)
+	}
 
 	return strings.Join(htmlParts, ""), nil
 }
 
+// Close stops the archive queue and writes any change not yet saved,
+// e.g. one whose save failed or an archive job that just finished. Call it
+// once nothing else is changing notes, as the server shuts down.
+func (nm *NoteManager) Close() error {
+	nm.StopArchiveQueue()
+	nm.StopRemotePolling()
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if err := nm.save(); err != nil {
+		return err
+	}
+	if err := nm.write(); err != nil {
+		return err
+	}
+	return nm.storage.Close()
+}
+
 // save persists notes to storage if needed
 func (nm *NoteManager) save() error {
+	return nm.saveEvent(Event{Type: EventNotesChanged})
+}
+
+// saveEvent is save that publishes e once the notes are written. With a
+// save delay the changes are written later, by writeLater, and e is
+// published at once.
+func (nm *NoteManager) saveEvent(e Event) error {
 	if !nm.needsSave {
 		return nil
 	}
+	nm.rebuildIndexes()
+	if nm.saveDelay > 0 {
+		nm.writeLater()
+	} else if err := nm.write(); err != nil {
+		return err
+	}
+	e.Folder = nm.storage.BasePath
+	nm.events.Publish(e)
+	return nil
+}
 
-	if err := nm.storage.SaveNotes(nm.notes); err != nil {
+// write writes unsaved changes to disk now. Callers hold nm.mu.
+func (nm *NoteManager) write() error {
+	if nm.saveTimer != nil {
+		nm.saveTimer.Stop()
+		nm.saveTimer = nil
+	}
+	if !nm.needsSave {
+		return nil
+	}
+	if nm.changedOnDisk() {
+		// Someone else's edit is on disk and ours was made without it:
+		// merge the two before writing.
+		if err := nm.mergeOrSetAside(); err != nil {
+			return err
+		}
+	}
+	err := nm.storage.SaveNotes(nm.notes)
+	if errors.Is(err, storage.ErrRemoteChanged) {
+		// The server caught an edit the stamp didn't: as above.
+		if err := nm.mergeOrSetAside(); err != nil {
+			return err
+		}
+		err = nm.storage.SaveNotes(nm.notes)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to save notes: %w", err)
 	}
-
+	nm.synced()
 	nm.needsSave = false
 	return nil
 }
 
+// mergeOrSetAside is mergeFromDisk for write: the error is
+// ErrNotesChangedOnDisk, naming the copy, when the changes were set
+// aside. Callers hold nm.mu.
+func (nm *NoteManager) mergeOrSetAside() error {
+	conflictCopy, err := nm.mergeFromDisk()
+	if err != nil {
+		return fmt.Errorf("failed to reload notes: %w", err)
+	}
+	if conflictCopy != "" {
+		return fmt.Errorf("%w; the changes it conflicted with are in %s", ErrNotesChangedOnDisk, conflictCopy)
+	}
+	return nil
+}
+
+// taskEvent returns the task.toggled event for the task at taskIndex in
+// note.
+func taskEvent(note *models.Note, taskIndex int) Event {
+	e := Event{Type: EventTaskToggled, NoteID: note.HistoryKey()}
+	for _, task := range note.Tasks {
+		if task.Index == taskIndex {
+			snapshot := *task
+			e.Task = &snapshot
+		}
+	}
+	return e
+}
+
 // reassignTaskIndicesFromNote reassigns task indices starting from a specific note
 func (nm *NoteManager) reassignTaskIndicesFromNote(startNoteIndex int) {
 	index := nm.checkboxIndex
@@ -262,10 +637,7 @@ func (nm *NoteManager) reassignTaskIndicesFromNote(startNoteIndex int) {
 
 	// Reassign from start note onwards
 	for i := startNoteIndex; i < len(nm.notes); i++ {
-		for _, task := range nm.notes[i].Tasks {
-			task.Index = index
-			index++
-		}
+		index = nm.notes[i].AssignTaskIndices(index)
 	}
 
 	// Update the global counter
@@ -459,11 +831,8 @@ func extractSnippetLines(content string, startLine, endLine int) (string, string
 
 // processArchiveLinks processes +http links in content and archives the websites
 func (nm *NoteManager) processArchiveLinks(content string) (string, error) {
-	// Regular expression to match +http(s)://... links
-	re := regexp.MustCompile(`\+https?://[^\s\)]+`)
-	
-	// Find all matches
-	matches := re.FindAllString(content, -1)
+	// Find all +http(s)://... and ++http(s)://... links
+	matches := archiveSigilRE.FindAllStringSubmatch(content, -1)
 	if len(matches) == 0 {
 		return content, nil
 	}
@@ -471,33 +840,60 @@ func (nm *NoteManager) processArchiveLinks(content string) (string, error) {
 	processedContent := content
 	
 	for _, match := range matches {
-		// Remove the + prefix to get the actual URL
-		url := strings.TrimPrefix(match, "+")
+		written := parseArchiveSigil(match)
+		spec := nm.resolveArchive(written)
+
+		// With a queue the page is fetched in the background
+		if nm.archives != nil {
+			nm.archives.enqueue(written, spec)
+			processedContent = strings.Replace(processedContent, match[0], written.placeholder(), 1)
+			continue
+		}
 		
 		// Archive the website
-		archiveInfo, err := nm.archiveWebsite(url)
+		archiveInfo, err := nm.archive(spec)
 		if err != nil {
-			log.Printf("Warning: failed to archive %s: %v", url, err)
+			log.Printf("Warning: failed to archive %s: %v", spec.URL, err)
+			nm.alertArchiveFailure(spec.URL, err)
 			continue
 		}
 		
-		// Replace +URL with archived link reference
-		archiveLink := fmt.Sprintf("[%s](%s) (archived %s)", 
-			archiveInfo.Title, 
-			archiveInfo.FilePath, 
-			archiveInfo.Timestamp.Format("2006-01-02 15:04"))
-		
-		processedContent = strings.Replace(processedContent, match, archiveLink, 1)
+		// Replace the sigil with archived link reference
+		processedContent = strings.Replace(processedContent, match[0], archiveLink(archiveInfo), 1)
 	}
 	
 	return processedContent, nil
 }
 
+// alertArchiveFailure pushes a failed-archive alert without blocking the
+// save path. Callers hold nm.mu, so the notifier is read here and the send
+// happens on its own goroutine.
+func (nm *NoteManager) alertArchiveFailure(websiteURL string, archiveErr error) {
+	n := nm.notifier
+	if n == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		msg := notify.Message{
+			Title: "NoteFlow: archive failed",
+			Body:  fmt.Sprintf("%s\n%v", websiteURL, archiveErr),
+			Tags:  []string{"warning"},
+			URL:   websiteURL,
+		}
+		if err := n.Notify(ctx, msg); err != nil {
+			log.Printf("Warning: archive-failure notification failed: %v", err)
+		}
+	}()
+}
+
 // ArchiveInfo contains information about an archived website
 type ArchiveInfo struct {
-	Title     string
-	FilePath  string
-	Timestamp time.Time
+	Title      string
+	FilePath   string
+	ReaderPath string // reader-mode copy; empty if none was asked for or extraction failed
+	Timestamp  time.Time
 }
 
 // archiveWebsite downloads a webpage and produces a single self-contained HTML
@@ -507,7 +903,8 @@ type ArchiveInfo struct {
 // port of monolith. We previously rolled this by hand with regexes, which
 // worked but mis-handled inline JavaScript template literals, sponsor-badge
 // sprite maps, and refetched duplicate resources dozens of times per save.
-func (nm *NoteManager) archiveWebsite(websiteURL string) (*ArchiveInfo, error) {
+func (nm *NoteManager) archiveWebsite(spec archiveSpec) (*ArchiveInfo, error) {
+	websiteURL := spec.URL
 	parsedURL, err := url.Parse(websiteURL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid URL: %w", err)
@@ -524,13 +921,17 @@ func (nm *NoteManager) archiveWebsite(websiteURL string) (*ArchiveInfo, error) {
 	//     strips real images. (Verified against process-html.go upstream.)
 	//   - MaxRetries=0: a failed resource stays failed. Retries doubled
 	//     wall-clock on flaky CDN endpoints with no quality gain.
-	//   - RequestTimeout=30s: generous enough for slow CDNs (we saw a
-	//     lobste.rs body read trip a 15s ceiling) but still bounded.
+	//   - RequestTimeout (default 30s): generous enough for slow CDNs (we
+	//     saw a lobste.rs body read trip a 15s ceiling) but still bounded.
 	//   - MaxConcurrentDownload=16: obelisk's default is 10. Pages with
 	//     many small image references benefit from more parallelism.
+	//   - Transport: safefetch caps response size and redirects and
+	//     refuses private addresses, for the page and every resource.
+	limits := spec.limits
 	arc := &obelisk.Archiver{
-		UserAgent:             "NoteFlow-Go archive",
-		RequestTimeout:        30 * time.Second,
+		UserAgent:             limits.fetch.UserAgent,
+		Transport:             safefetch.NewTransport(limits.fetch),
+		RequestTimeout:        limits.fetch.Timeout,
 		MaxConcurrentDownload: 16,
 		MaxRetries:            0,
 		SkipResourceURLError:  true,
@@ -541,11 +942,16 @@ func (nm *NoteManager) archiveWebsite(websiteURL string) (*ArchiveInfo, error) {
 	arc.Validate()
 
 	// Overall archive deadline. Without this, a single hung resource
-	// retry can wedge the save handler for minutes. 90s is enough for
-	// real news/forum pages even with their long resource lists.
-	archiveCtx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
+	// retry can wedge the save handler for minutes. The 90s default is
+	// enough for real news/forum pages even with their long resource lists.
+	archiveCtx, cancel := context.WithTimeout(context.Background(), limits.pageTimeout)
 	defer cancel()
 
+	// Space out pages from one site, e.g. during a bulk archive
+	if err := nm.archiveHosts.Wait(archiveCtx, parsedURL.Hostname(), limits.domainDelay); err != nil {
+		return nil, fmt.Errorf("failed to archive: %w", err)
+	}
+
 	body, _, err := arc.Archive(archiveCtx, obelisk.Request{URL: websiteURL})
 	if err != nil {
 		return nil, fmt.Errorf("failed to archive: %w", err)
@@ -554,7 +960,7 @@ func (nm *NoteManager) archiveWebsite(websiteURL string) (*ArchiveInfo, error) {
 	title := nm.extractTitle(string(body), parsedURL.Host)
 
 	timestamp := time.Now()
-	filename := fmt.Sprintf("%s_%s-%s.html",
+	filename := fmt.Sprintf("%s_%s-%s",
 		timestamp.Format("2006_01_02_150405"),
 		nm.sanitizeFilename(title),
 		nm.sanitizeFilename(parsedURL.Host))
@@ -569,16 +975,58 @@ func (nm *NoteManager) archiveWebsite(websiteURL string) (*ArchiveInfo, error) {
 	// this an archived page is visually indistinguishable from the live one.
 	withBanner := injectArchiveBanner(string(body), websiteURL, timestamp)
 
+	data, ext := encodeArchive(archiveCtx, spec, withBanner, title, timestamp)
+	filename += ext
+
 	filePath := filepath.Join(sitesDir, filename)
-	if err := os.WriteFile(filePath, []byte(withBanner), 0644); err != nil {
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
 		return nil, fmt.Errorf("failed to save archived file: %w", err)
 	}
 
-	return &ArchiveInfo{
+	info := &ArchiveInfo{
 		Title:     title,
 		FilePath:  filepath.Join("assets", "sites", filename),
 		Timestamp: timestamp,
-	}, nil
+	}
+
+	// Remember where the page came from so it can be captured again
+	meta := models.ArchiveMeta{URL: websiteURL, Title: title, ArchivedAt: timestamp, Format: storage.ArchiveFormat(filename)}
+	if err := nm.storage.SaveArchiveMeta(filename, meta); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	// The reader copy is a convenience: without it the full archive still
+	// stands, so a page with no recognisable article only logs a warning.
+	if spec.Reader {
+		readerPath, err := nm.writeReaderCopy(string(body), websiteURL, filename, timestamp)
+		if err != nil {
+			log.Printf("Warning: no reader copy of %s: %v", websiteURL, err)
+		}
+		info.ReaderPath = readerPath
+	}
+
+	// With an S3 bucket configured the archive goes there; the metadata
+	// stays here. Left on disk when the upload fails, it is offloaded by
+	// the next 'noteflow assets offload'.
+	if offload, err := nm.storage.AssetsOffloaded(); err != nil {
+		log.Printf("Warning: %v", err)
+	} else if offload {
+		for _, p := range []string{info.FilePath, info.ReaderPath} {
+			if p == "" {
+				continue
+			}
+			if err := nm.storage.OffloadAsset(filepath.ToSlash(p)); err != nil {
+				log.Printf("Warning: %s stays on disk: %v", p, err)
+			}
+		}
+	}
+	return info, nil
+}
+
+// AssetURL returns a signed URL of the asset at rel, such as
+// "assets/images/x.png", when it is kept in the folder's S3 bucket.
+func (nm *NoteManager) AssetURL(rel string) (string, bool) {
+	return nm.storage.AssetURL(rel)
 }
 
 // injectArchiveBanner prepends our archive-attribution box immediately after
@@ -693,10 +1141,33 @@ func (nm *NoteManager) GetAllTasks() []models.Task {
 	defer nm.mu.RUnlock()
 	
 	var allTasks []models.Task
+	line := 1 // first line of the current note in notes.md
 	for _, note := range nm.notes {
-		for _, task := range note.Tasks {
-			allTasks = append(allTasks, *task)
+		positions := taskPositions(note)
+		for i, task := range note.Tasks {
+			t := *task
+			t.NoteID = note.HistoryKey()
+			if pos := positions[i]; pos >= 0 {
+				// The header line and a blank line precede the body.
+				t.Line = line + 2 + strings.Count(note.Content[:pos], "\n")
+				t.Offset = len(utf16.Encode([]rune(note.Content[:pos])))
+			}
+			allTasks = append(allTasks, t)
 		}
+		line += strings.Count(note.Render(), "\n") + strings.Count(models.NoteSeparator, "\n")
 	}
 	return allTasks
+}
+
+// NoteIndexByID returns the current index of the note whose HistoryKey is
+// id.
+func (nm *NoteManager) NoteIndexByID(id string) (int, bool) {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+	for i, note := range nm.notes {
+		if note.HistoryKey() == id {
+			return i, true
+		}
+	}
+	return 0, false
 }
\ No newline at end of file
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// NotesJSONFormat and NotesJSONVersion identify a notes JSON document, the
+// scripting format of GET /api/notes/export.json and POST
+// /api/notes/import.json. docs/20261017_notes_json_schema.md describes it;
+// a change that breaks readers of version 1 must bump the version.
+const (
+	NotesJSONFormat  = "noteflow-notes"
+	NotesJSONVersion = 1
+)
+
+// NotesJSONTime is the layout of note timestamps in a notes JSON document:
+// local time without a zone, as notes.md keeps it.
+const NotesJSONTime = "2006-01-02T15:04:05"
+
+// NotesJSON is a notes JSON document.
+type NotesJSON struct {
+	Format   string     `json:"format"`
+	Version  int        `json:"version"`
+	Exported string     `json:"exported,omitempty"` // RFC 3339
+	Folder   string     `json:"folder,omitempty"`
+	Notes    []NoteJSON `json:"notes"` // newest first, as indexed by the API
+}
+
+// NoteJSON is a note of a notes JSON document. Title, Timestamp, Content
+// and Tasks are read on import; the rest is derived from Content.
+type NoteJSON struct {
+	ID        string            `json:"id,omitempty"` // the note's creation time, YYYYMMDD-HHMMSS
+	Index     int               `json:"index"`
+	Title     string            `json:"title"`
+	Timestamp string            `json:"timestamp"` // NotesJSONTime
+	Content   string            `json:"content"`   // markdown, without the ## header
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+	Mentions  []string          `json:"mentions,omitempty"`
+	Links     []string          `json:"links,omitempty"`
+	Tasks     []NoteJSONTask    `json:"tasks,omitempty"`
+}
+
+// NoteJSONTask is a task of a NoteJSON. Text and State are read on import.
+type NoteJSONTask struct {
+	Text     string           `json:"text"` // the line after the checkbox, metadata tokens included
+	State    models.TaskState `json:"state"`
+	Priority int              `json:"priority,omitempty"`
+	Due      string           `json:"due,omitempty"` // YYYY-MM-DD or YYYY-MM-DDTHH:MM
+	Tags     []string         `json:"tags,omitempty"`
+	Section  string           `json:"section,omitempty"`
+	Depth    int              `json:"depth,omitempty"`
+	Hash     string           `json:"hash"` // the task's ID in the task registry
+}
+
+// ExportNotesJSON returns the folder's notes as a notes JSON document.
+func (nm *NoteManager) ExportNotesJSON(now time.Time) NotesJSON {
+	doc := NotesJSON{
+		Format:   NotesJSONFormat,
+		Version:  NotesJSONVersion,
+		Exported: now.Format(time.RFC3339),
+		Folder:   nm.GetBasePath(),
+		Notes:    []NoteJSON{},
+	}
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+	// Hashes are computed over the folder's tasks in order, as the
+	// registry does.
+	var tasks []models.Task
+	for _, note := range nm.notes {
+		for _, task := range note.Tasks {
+			tasks = append(tasks, *task)
+		}
+	}
+	hashes := ComputeTaskHashes(tasks)
+	next := 0
+	for i, note := range nm.notes {
+		out := NoteJSON{
+			ID:        note.HistoryKey(),
+			Index:     i,
+			Title:     note.Title,
+			Timestamp: note.Timestamp.Format(NotesJSONTime),
+			Content:   note.Content,
+			Metadata:  note.Metadata,
+			Tags:      note.Tags,
+			Mentions:  note.Mentions,
+			Links:     note.Links,
+		}
+		for _, task := range note.Tasks {
+			jt := NoteJSONTask{
+				Text:     taskLineText(task.Text),
+				State:    task.State,
+				Priority: task.Priority,
+				Tags:     task.Tags,
+				Section:  task.Section,
+				Depth:    task.Depth,
+				Hash:     hashes[next],
+			}
+			if !task.DueDate.IsZero() {
+				hasTime := task.DueDate.Hour() != 0 || task.DueDate.Minute() != 0
+				jt.Due = strings.TrimPrefix(models.FormatDueToken(task.DueDate, hasTime), "@")
+			}
+			out.Tasks = append(out.Tasks, jt)
+			next++
+		}
+		doc.Notes = append(doc.Notes, out)
+	}
+	return doc
+}
+
+// taskLineText is a task's text without its checkbox.
+func taskLineText(text string) string {
+	return strings.TrimSpace(text[min(3, len(text)):])
+}
+
+// ImportNotesJSON adds the notes of doc to the folder, in ImportMerge or
+// ImportRestore mode as ImportZip does. Each note is built from its title,
+// timestamp (default now) and content. Its metadata becomes a frontmatter
+// block if the content has none, and its tasks are reconciled with the
+// content: one whose text is already a task there takes the given state,
+// others are appended as checkbox lines. So a script can write notes and
+// tasks without writing markdown.
+func (nm *NoteManager) ImportNotesJSON(doc *NotesJSON, mode string, now time.Time) (*ImportResult, error) {
+	if mode == "" {
+		mode = ImportMerge
+	}
+	if mode != ImportMerge && mode != ImportRestore {
+		return nil, fmt.Errorf("unknown import mode %q (want %s or %s)", mode, ImportMerge, ImportRestore)
+	}
+	if doc.Format != "" && doc.Format != NotesJSONFormat {
+		return nil, fmt.Errorf("%w: format %q is not %s", ErrInvalidImport, doc.Format, NotesJSONFormat)
+	}
+	if doc.Version > NotesJSONVersion {
+		return nil, fmt.Errorf("%w: version %d is newer than this NoteFlow reads (%d)", ErrInvalidImport, doc.Version, NotesJSONVersion)
+	}
+	if len(doc.Notes) == 0 {
+		return nil, fmt.Errorf("%w: no notes", ErrInvalidImport)
+	}
+	notes := make([]*models.Note, 0, len(doc.Notes))
+	for i, in := range doc.Notes {
+		// Notes without a timestamp are dated now, a second apart so that
+		// each keeps its own history.
+		note, err := importedJSONNote(in, now.Add(-time.Duration(i)*time.Second))
+		if err != nil {
+			return nil, fmt.Errorf("%w: note %d: %v", ErrInvalidImport, i, err)
+		}
+		notes = append(notes, note)
+	}
+	// Notes are kept newest first, whatever order the document has.
+	sort.SliceStable(notes, func(i, j int) bool { return notes[i].Timestamp.After(notes[j].Timestamp) })
+
+	result := &ImportResult{Mode: mode, Notes: len(notes)}
+	if err := nm.addImportedNotes(notes, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// importedJSONNote builds the note in of a notes JSON document.
+func importedJSONNote(in NoteJSON, now time.Time) (*models.Note, error) {
+	timestamp := now
+	if in.Timestamp != "" {
+		var err error
+		if timestamp, err = parseNotesJSONTime(in.Timestamp); err != nil {
+			return nil, err
+		}
+	}
+	if strings.Contains(in.Title, "\n") {
+		return nil, fmt.Errorf("title spans lines")
+	}
+	content := strings.TrimSpace(in.Content)
+	if _, _, ok := models.ParseFrontmatter(content); !ok && len(in.Metadata) > 0 {
+		block, err := models.RenderFrontmatter(in.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		content = block + content
+	}
+
+	note := models.NewNote(in.Title, content)
+	var added []string
+	for _, task := range in.Tasks {
+		text := strings.TrimSpace(task.Text)
+		if text == "" || strings.ContainsAny(text, "\r\n") {
+			return nil, fmt.Errorf("task text %q is not one line", task.Text)
+		}
+		state := models.TaskTodo
+		if task.State != "" {
+			var ok bool
+			if state, ok = models.ParseTaskState(string(task.State)); !ok {
+				return nil, fmt.Errorf("unknown task state %q", task.State)
+			}
+		}
+		found := false
+		for _, existing := range note.Tasks {
+			if taskLineText(existing.Text) == text {
+				if existing.State != state {
+					note.SetTaskState(existing.Index, state)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			added = append(added, "- "+state.Mark()+" "+text)
+		}
+	}
+	if len(added) > 0 {
+		body := note.Content
+		if body != "" {
+			body += "\n\n"
+		}
+		note.Update(note.Title, body+strings.Join(added, "\n"))
+	}
+	note.Timestamp = timestamp
+	return note, nil
+}
+
+// parseNotesJSONTime reads a note timestamp: NotesJSONTime, the notes.md
+// form, or RFC 3339, which is converted to local time.
+func parseNotesJSONTime(s string) (time.Time, error) {
+	for _, layout := range []string{NotesJSONTime, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q (want %s)", s, NotesJSONTime)
+	}
+	return wallClock(t), nil
+}
+
+// wallClock returns t as notes.md keeps times: the local wall-clock time,
+// which reads back as UTC, to the second.
+func wallClock(t time.Time) time.Time {
+	local := t.In(time.Local)
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), 0, time.UTC)
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestNotesJSON_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte(
+		"## 2026-10-14 09:00:00 - Plan\n\n---\nstatus: draft\n---\n#work with @ana, see [[Ideas]]\n\n- [ ] ship it !p1 @2026-10-20\n  - [x] write docs\n"+
+			"\n<!-- note -->\n## 2026-10-13 09:00:00 - Ideas\n\n- [/] try it\n"), 0644)
+	nm, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := nm.ExportNotesJSON(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	if doc.Format != NotesJSONFormat || doc.Version != NotesJSONVersion || len(doc.Notes) != 2 {
+		t.Fatalf("doc = %+v", doc)
+	}
+	plan := doc.Notes[0]
+	if plan.Title != "Plan" || plan.Timestamp != "2026-10-14T09:00:00" || plan.Metadata["status"] != "draft" ||
+		len(plan.Tags) != 1 || len(plan.Mentions) != 1 || len(plan.Links) != 1 {
+		t.Errorf("plan = %+v", plan)
+	}
+	if len(plan.Tasks) != 2 {
+		t.Fatalf("tasks = %+v", plan.Tasks)
+	}
+	ship := plan.Tasks[0]
+	if ship.Text != "ship it !p1 @2026-10-20" || ship.State != models.TaskTodo || ship.Priority != 1 || ship.Due != "2026-10-20" {
+		t.Errorf("task = %+v", ship)
+	}
+	hashes := ComputeTaskHashes(nm.GetAllTasks())
+	if ship.Hash != hashes[0] || plan.Tasks[1].Depth != 1 || doc.Notes[1].Tasks[0].State != models.TaskDoing {
+		t.Errorf("tasks = %+v, hashes %v", doc.Notes, hashes)
+	}
+
+	// Through JSON and into another folder, the notes read the same.
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back NotesJSON
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	other := newNoteManagerWithNote(t, "old", "gone after the restore")
+	result, err := other.ImportNotesJSON(&back, ImportRestore, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 2 {
+		t.Errorf("result = %+v", result)
+	}
+	for i, note := range other.GetAllNotes() {
+		if want := nm.GetAllNotes()[i].Render(); note.Render() != want {
+			t.Errorf("note %d = %q, want %q", i, note.Render(), want)
+		}
+	}
+	if result, _ := other.ImportNotesJSON(&back, ImportMerge, time.Now()); result.Added != 0 || result.Skipped != 2 {
+		t.Errorf("merging the same notes again: %+v", result)
+	}
+}
+
+func TestImportNotesJSON_TasksAndMetadata(t *testing.T) {
+	nm := newNoteManagerWithNote(t, "Existing", "hello")
+	now := time.Now().Add(time.Hour).Truncate(time.Second)
+	doc := &NotesJSON{Notes: []NoteJSON{
+		{
+			Title:    "Groceries",
+			Content:  "For the weekend.\n\n- [ ] milk",
+			Metadata: map[string]string{"store": "corner shop"},
+			Tasks:    []NoteJSONTask{{Text: "milk", State: models.TaskDone}, {Text: "bread"}},
+		},
+		{Title: "Older", Timestamp: "2020-10-01T08:30:00", Content: "from a script"},
+	}}
+	result, err := nm.ImportNotesJSON(doc, "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Mode != ImportMerge || result.Added != 2 {
+		t.Errorf("result = %+v", result)
+	}
+	notes := nm.GetAllNotes()
+	if len(notes) != 3 {
+		t.Fatalf("%d notes", len(notes))
+	}
+	groceries := notes[0]
+	want := "---\nstore: \"corner shop\"\n---\nFor the weekend.\n\n- [x] milk\n\n- [ ] bread"
+	if groceries.Title != "Groceries" || groceries.Content != want || groceries.Metadata["store"] != "corner shop" {
+		t.Errorf("groceries = %q %q", groceries.Title, groceries.Content)
+	}
+	if !groceries.Timestamp.Equal(now) {
+		t.Errorf("timestamp = %v, want the import time", groceries.Timestamp)
+	}
+	if older := notes[2]; older.Title != "Older" || older.Timestamp.Format("2006-01-02 15:04:05") != "2020-10-01 08:30:00" {
+		t.Errorf("older = %+v", older)
+	}
+
+	for name, bad := range map[string]*NotesJSON{
+		"no notes":    {},
+		"format":      {Format: "other", Notes: []NoteJSON{{Content: "x"}}},
+		"version":     {Version: NotesJSONVersion + 1, Notes: []NoteJSON{{Content: "x"}}},
+		"timestamp":   {Notes: []NoteJSON{{Timestamp: "yesterday"}}},
+		"task state":  {Notes: []NoteJSON{{Tasks: []NoteJSONTask{{Text: "x", State: "blocked"}}}}},
+		"task line":   {Notes: []NoteJSON{{Tasks: []NoteJSONTask{{Text: "a\nb"}}}}},
+		"metadata":    {Notes: []NoteJSON{{Metadata: map[string]string{"a key": "x"}}}},
+		"title lines": {Notes: []NoteJSON{{Title: "a\nb"}}},
+	} {
+		if _, err := nm.ImportNotesJSON(bad, ImportMerge, now); !errors.Is(err, ErrInvalidImport) {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if len(nm.GetAllNotes()) != 3 {
+		t.Error("a rejected document changed the notes")
+	}
+	if !strings.Contains(nm.GetAllNotes()[1].Content, "hello") {
+		t.Error("the existing note moved")
+	}
+}
+
+// newNoteManagerWithNote returns a manager of a new folder holding one
+// note.
+func newNoteManagerWithNote(t *testing.T, title, content string) *NoteManager {
+	t.Helper()
+	nm, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nm.AddNote(title, content); err != nil {
+		t.Fatal(err)
+	}
+	return nm
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+)
+
+// ErrTemplateNotFound is returned for a template name with no file.
+var ErrTemplateNotFound = errors.New("template not found")
+
+// NoteTemplate is a note skeleton from the folder's templates/ directory.
+type NoteTemplate struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// ExpandedTemplate is a template with its placeholders filled in.
+type ExpandedTemplate struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	// Cursor is where {{cursor}} stood, as a UTF-16 offset into Content
+	// (a JavaScript string index), or -1 when the template has none.
+	Cursor int `json:"cursor"`
+}
+
+// placeholderRE matches {{name}} and {{name:layout}}. date, time and
+// datetime accept a Go time layout after the colon, e.g.
+// {{date:Monday, Jan 2}}.
+var placeholderRE = regexp.MustCompile(`\{\{\s*([a-z]+)(?::([^{}]*))?\s*\}\}`)
+
+// cursorMarker stands in for {{cursor}} while the other placeholders are
+// expanded; it can't occur in a template.
+const cursorMarker = "\x00cursor\x00"
+
+// NoteTemplateService manages note templates and expands them into new
+// notes. Templates are markdown files under templates/ in the project
+// folder, so they can be shared through the repo like notes.md.
+type NoteTemplateService struct {
+	storage *storage.FileStorage
+	folder  string
+	now     func() time.Time
+}
+
+// NewNoteTemplateService creates the service for the folder at basePath.
+func NewNoteTemplateService(basePath string) *NoteTemplateService {
+	return &NoteTemplateService{
+		storage: storage.NewFileStorage(basePath),
+		folder:  filepath.Base(basePath),
+		now:     time.Now,
+	}
+}
+
+// templateError maps a missing file to ErrTemplateNotFound.
+func templateError(err error) error {
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrTemplateNotFound
+	}
+	return err
+}
+
+// List returns every template, sorted by name.
+func (s *NoteTemplateService) List() ([]NoteTemplate, error) {
+	names, err := s.storage.ListNoteTemplates()
+	if err != nil {
+		return nil, err
+	}
+	templates := make([]NoteTemplate, 0, len(names))
+	for _, name := range names {
+		content, err := s.storage.LoadNoteTemplate(name)
+		if err != nil {
+			return nil, templateError(err)
+		}
+		templates = append(templates, NoteTemplate{Name: name, Content: content})
+	}
+	return templates, nil
+}
+
+// Get returns one template.
+func (s *NoteTemplateService) Get(name string) (*NoteTemplate, error) {
+	content, err := s.storage.LoadNoteTemplate(name)
+	if err != nil {
+		return nil, templateError(err)
+	}
+	return &NoteTemplate{Name: name, Content: content}, nil
+}
+
+// Save creates or replaces a template.
+func (s *NoteTemplateService) Save(name, content string) error {
+	return s.storage.SaveNoteTemplate(name, content)
+}
+
+// Delete removes a template.
+func (s *NoteTemplateService) Delete(name string) error {
+	return templateError(s.storage.DeleteNoteTemplate(name))
+}
+
+// Expand fills in the named template for a new note titled title. The
+// title may use placeholders too. Supported placeholders:
+//
+//	{{date}}      2026-10-16        {{time}}    14:05
+//	{{datetime}}  2026-10-16 14:05  {{weekday}} Friday
+//	{{folder}}    the project folder's name
+//	{{title}}     the expanded note title (content only)
+//	{{cursor}}    removed; its position is returned as Cursor
+//
+// Unknown placeholders are left as they are. text, when not empty, is
+// inserted at the cursor, or appended when the template has no cursor.
+func (s *NoteTemplateService) Expand(name, title, text string) (*ExpandedTemplate, error) {
+	tmpl, err := s.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	title = strings.ReplaceAll(s.expand(title, now, ""), cursorMarker, "")
+	content := s.expand(tmpl.Content, now, title)
+
+	before, after, hasCursor := strings.Cut(content, cursorMarker)
+	after = strings.ReplaceAll(after, cursorMarker, "")
+	if !hasCursor && text != "" {
+		before = strings.TrimRight(before, "\n") + "\n\n"
+	}
+	before += text
+
+	res := &ExpandedTemplate{Title: title, Content: before + after, Cursor: -1}
+	if hasCursor {
+		res.Cursor = len(utf16.Encode([]rune(before)))
+	}
+	return res, nil
+}
+
+// expand replaces the placeholders in text, leaving {{cursor}} as
+// cursorMarker for Expand to find.
+func (s *NoteTemplateService) expand(text string, now time.Time, title string) string {
+	return placeholderRE.ReplaceAllStringFunc(text, func(m string) string {
+		sub := placeholderRE.FindStringSubmatch(m)
+		name, layout := sub[1], sub[2]
+		withLayout := func(def string) string {
+			if layout != "" {
+				return now.Format(layout)
+			}
+			return now.Format(def)
+		}
+		switch name {
+		case "date":
+			return withLayout("2006-01-02")
+		case "time":
+			return withLayout("15:04")
+		case "datetime":
+			return withLayout("2006-01-02 15:04")
+		case "weekday":
+			return now.Format("Monday")
+		case "folder":
+			return s.folder
+		case "title":
+			return title
+		case "cursor":
+			return cursorMarker
+		}
+		return m
+	})
+}
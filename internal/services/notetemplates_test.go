@@ -0,0 +1,50 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNoteTemplateExpand(t *testing.T) {
+	dir := t.TempDir()
+	s := NewNoteTemplateService(dir)
+	s.now = func() time.Time { return time.Date(2026, 10, 16, 9, 5, 0, 0, time.Local) }
+	s.folder = "acme"
+
+	if err := s.Save("meeting", "# {{title}} ({{folder}})\n{{weekday}} {{date}} {{time}}, {{date:Jan 2}}\n\n“{{cursor}}”\n{{unknown}}"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Expand("meeting", "Sync {{date}}", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantContent := "# Sync 2026-10-16 (acme)\nFriday 2026-10-16 09:05, Oct 16\n\n“”\n{{unknown}}"
+	if got.Title != "Sync 2026-10-16" || got.Content != wantContent {
+		t.Errorf("Expand = %q / %q", got.Title, got.Content)
+	}
+	// The cursor offset counts UTF-16 code units, so the curly quote is one.
+	if want := len("# Sync 2026-10-16 (acme)\nFriday 2026-10-16 09:05, Oct 16\n\n") + 1; got.Cursor != want {
+		t.Errorf("Cursor = %d, want %d", got.Cursor, want)
+	}
+
+	// Without a cursor, text is appended and Cursor is -1.
+	if err := s.Save("plain", "Agenda\n"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = s.Expand("plain", "", "first item")
+	if got.Content != "Agenda\n\nfirst item" || got.Cursor != -1 {
+		t.Errorf("Expand plain = %+v", got)
+	}
+
+	list, err := s.List()
+	if err != nil || len(list) != 2 || list[0].Name != "meeting" || list[1].Name != "plain" {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+	if err := s.Delete("plain"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Expand("plain", "", ""); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("Expand deleted: err = %v", err)
+	}
+}
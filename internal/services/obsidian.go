@@ -0,0 +1,378 @@
+package services
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+var (
+	// obsidianLinkRE matches Obsidian's [[links]] and ![[embeds]]:
+	// [[Note]], [[folder/Note#Heading|label]], ![[image.png|300]].
+	obsidianLinkRE = regexp.MustCompile(`(!?)\[\[([^\[\]\n]+)\]\]`)
+	// obsidianMarkdownLinkRE matches markdown links and images, which
+	// Obsidian also uses for notes and attachments with a relative path.
+	obsidianMarkdownLinkRE = regexp.MustCompile(`(!?)\[([^\]\n]*)\]\(<?([^()<>\n]+?)>?\)`)
+	// obsidianSizeRE matches the size an embed's label can give instead of
+	// a caption: ![[image.png|300]], ![[image.png|300x200]].
+	obsidianSizeRE = regexp.MustCompile(`^\d+(?:x\d+)?$`)
+)
+
+// obsidianTimeKeys are the frontmatter keys a note's creation time is
+// read from, in order; the file's modification time stands in otherwise.
+var obsidianTimeKeys = []string{"created", "date"}
+
+// obsidianVault is a vault being imported, indexed the way Obsidian
+// resolves links: by path from the vault's root or the linking note's
+// folder, else by file name.
+type obsidianVault struct {
+	fsys    fs.FS
+	paths   map[string]string   // lower-cased path → path
+	names   map[string][]string // lower-cased file name → paths, shortest first
+	titles  map[string]string   // path of a note → its title
+	aliases map[string]string   // WikiLinkKey of a frontmatter alias → title
+	stored  map[string]string   // path of an attachment → its escaped URL here
+}
+
+// obsidianNote is a note file of a vault, read but not yet translated.
+type obsidianNote struct {
+	path    string
+	content string
+	meta    map[string]string
+	modTime time.Time
+}
+
+// ImportObsidianZip imports a zip of an Obsidian vault with
+// ImportObsidian.
+func (nm *NoteManager) ImportObsidianZip(r io.ReaderAt, size int64, mode string, now time.Time) (*ImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	return nm.ImportObsidian(zr, mode, now)
+}
+
+// ImportObsidian imports the Obsidian vault in vault, in ImportMerge or
+// ImportRestore mode as ImportZip does. Each markdown file becomes a note
+// titled with its name and dated by its "created" or "date" frontmatter,
+// else by when it was last modified. Frontmatter is kept as the note's
+// metadata, without what NoteFlow can't read (see
+// models.LooseFrontmatter). Links are translated: [[folder/Note#Heading|
+// label]] becomes a [[Note|label]] wiki link, aliases resolving to their
+// note, and embedded or linked attachments are stored as uploads and
+// linked from there, images shown. Hidden directories such as .obsidian
+// and .trash are not imported.
+func (nm *NoteManager) ImportObsidian(vault fs.FS, mode string, now time.Time) (*ImportResult, error) {
+	if mode == "" {
+		mode = ImportMerge
+	}
+	if mode != ImportMerge && mode != ImportRestore {
+		return nil, fmt.Errorf("unknown import mode %q (want %s or %s)", mode, ImportMerge, ImportRestore)
+	}
+	v := &obsidianVault{
+		fsys:    vault,
+		paths:   map[string]string{},
+		names:   map[string][]string{},
+		titles:  map[string]string{},
+		aliases: map[string]string{},
+		stored:  map[string]string{},
+	}
+	var files []obsidianNote
+	err := fs.WalkDir(vault, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		if p != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		v.paths[strings.ToLower(p)] = p
+		name := strings.ToLower(path.Base(p))
+		v.names[name] = append(v.names[name], p)
+		if !strings.EqualFold(path.Ext(p), ".md") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		data, err := readFSFile(vault, p)
+		if err != nil {
+			return err
+		}
+		content := strings.TrimPrefix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\ufeff")
+		note := obsidianNote{path: p, content: content, modTime: info.ModTime()}
+		if meta, end, ok := models.LooseFrontmatter(content); ok {
+			note.meta = meta
+			if _, _, strict := models.ParseFrontmatter(content); !strict {
+				// Keep what NoteFlow reads of the block, as it writes blocks.
+				block := ""
+				if len(meta) > 0 {
+					if block, err = models.RenderFrontmatter(meta); err != nil {
+						return fmt.Errorf("%w: %s: %v", ErrInvalidImport, p, err)
+					}
+				}
+				note.content = block + strings.TrimLeft(content[end:], "\n")
+			}
+		}
+		files = append(files, note)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no markdown notes in the vault", ErrInvalidImport)
+	}
+	for _, paths := range v.names {
+		sort.SliceStable(paths, func(i, j int) bool { return len(paths[i]) < len(paths[j]) })
+	}
+	for _, file := range files {
+		title := strings.TrimSuffix(path.Base(file.path), path.Ext(file.path))
+		v.titles[file.path] = title
+		for _, alias := range strings.Split(file.meta["aliases"]+", "+file.meta["alias"], ", ") {
+			if key := models.WikiLinkKey(alias); key != "" {
+				v.aliases[key] = title
+			}
+		}
+	}
+
+	result := &ImportResult{Mode: mode, Notes: len(files)}
+	missing := map[string]bool{}
+	notes := make([]*models.Note, 0, len(files))
+	for _, file := range files {
+		content, err := v.translate(nm, file, result, missing)
+		if err != nil {
+			return nil, err
+		}
+		note := models.NewNote(v.titles[file.path], strings.TrimSpace(content))
+		note.Timestamp = obsidianTimestamp(file, now)
+		notes = append(notes, note)
+	}
+	for p := range missing {
+		result.Missing = append(result.Missing, p)
+	}
+	sort.Strings(result.Missing)
+
+	// Files checked out together share a modification time.
+	orderImportedNotes(notes)
+	if err := nm.addImportedNotes(notes, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// obsidianTimestamp is when file was created, from its frontmatter, else
+// its modification time, else now.
+func obsidianTimestamp(file obsidianNote, now time.Time) time.Time {
+	for _, key := range obsidianTimeKeys {
+		value := file.meta[key]
+		if value == "" {
+			continue
+		}
+		if t, err := parseNotesJSONTime(value); err == nil {
+			return t
+		}
+		for _, layout := range []string{"2006-01-02", "2006-01-02 15:04", "2006-01-02T15:04"} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t
+			}
+		}
+	}
+	if !file.modTime.IsZero() {
+		return wallClock(file.modTime)
+	}
+	return wallClock(now)
+}
+
+// translate returns the content of file with its links in NoteFlow's
+// form, storing the attachments they point to. Links in code are left
+// alone.
+func (v *obsidianVault) translate(nm *NoteManager, file obsidianNote, result *ImportResult, missing map[string]bool) (string, error) {
+	dir := path.Dir(file.path)
+	var firstErr error
+	attach := func(p, label string, embed bool) string {
+		u, err := v.attachment(nm, p, result)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return ""
+		}
+		name := path.Base(p)
+		if embed && strings.HasPrefix(mime.TypeByExtension(path.Ext(p)), "image/") {
+			if label == "" || obsidianSizeRE.MatchString(label) {
+				label = strings.TrimSuffix(name, path.Ext(name))
+			}
+			return "![" + label + "](" + u + ")"
+		}
+		if label == "" {
+			label = name
+		}
+		return "[" + label + "](" + u + ")"
+	}
+
+	content := models.ReplaceOutsideCode(file.content, obsidianLinkRE, func(m []string) string {
+		embed := m[1] == "!"
+		target, label, _ := strings.Cut(m[2], "|")
+		// Inside a table the pipe is escaped: [[Note\|label]].
+		target = strings.TrimSpace(strings.TrimSuffix(target, `\`))
+		label = strings.TrimSpace(label)
+		name, fragment, _ := strings.Cut(target, "#")
+		name, fragment = strings.TrimSpace(name), strings.TrimSpace(fragment)
+		if name == "" {
+			// A heading or block of the same note.
+			if label != "" {
+				return label
+			}
+			return strings.TrimPrefix(fragment, "^")
+		}
+		if title, ok := v.note(name, dir); ok {
+			if label == "" && fragment != "" && !strings.HasPrefix(fragment, "^") {
+				label = title + " > " + fragment
+			}
+			if label == "" {
+				// Show an alias as written; a name or path reads as the title.
+				label = strings.TrimSuffix(path.Base(name), ".md")
+			}
+			return wikiLinkTo(title, label)
+		}
+		if p, ok := v.find(name, dir); ok {
+			return attach(p, label, embed)
+		}
+		if isAttachmentName(name) {
+			missing[name] = true
+			return m[0]
+		}
+		// A link to a note not written yet, as Obsidian allows.
+		return wikiLinkTo(name, label)
+	})
+
+	content = models.ReplaceOutsideCode(content, obsidianMarkdownLinkRE, func(m []string) string {
+		dest := m[3]
+		if strings.Contains(dest, ":") || strings.HasPrefix(dest, "#") || strings.HasPrefix(dest, "/") {
+			return m[0] // a URL, an anchor or a path of this server
+		}
+		unescaped, err := url.PathUnescape(dest)
+		if err != nil {
+			return m[0]
+		}
+		name, _, _ := strings.Cut(unescaped, "#")
+		if title, ok := v.note(name, dir); ok {
+			return wikiLinkTo(title, m[2])
+		}
+		if p, ok := v.find(name, dir); ok {
+			return attach(p, m[2], m[1] == "!")
+		}
+		if isAttachmentName(name) {
+			missing[name] = true
+		}
+		return m[0]
+	})
+	return content, firstErr
+}
+
+// note resolves a link target to the title of a note of the vault, by
+// path, name or alias.
+func (v *obsidianVault) note(target, dir string) (string, bool) {
+	if !strings.EqualFold(path.Ext(target), ".md") {
+		if title, ok := v.aliases[models.WikiLinkKey(target)]; ok {
+			if p, found := v.find(target+".md", dir); found {
+				return v.titles[p], true // a note's name wins over an alias
+			}
+			return title, true
+		}
+		target += ".md"
+	}
+	p, ok := v.find(target, dir)
+	if !ok {
+		return "", false
+	}
+	title, isNote := v.titles[p]
+	return title, isNote
+}
+
+// find resolves a link target to the path of a file of the vault: from
+// the linking note's folder dir, from the vault's root, or by name, the
+// shortest path first, as Obsidian does.
+func (v *obsidianVault) find(target, dir string) (string, bool) {
+	target = strings.TrimPrefix(target, "./")
+	for _, p := range []string{path.Join(dir, target), path.Clean(target)} {
+		if found, ok := v.paths[strings.ToLower(p)]; ok {
+			return found, true
+		}
+	}
+	if paths := v.names[strings.ToLower(path.Base(target))]; len(paths) > 0 {
+		return paths[0], true
+	}
+	return "", false
+}
+
+// attachment stores the vault's file p as an upload, once, and returns its
+// URL path.
+func (v *obsidianVault) attachment(nm *NoteManager, p string, result *ImportResult) (string, error) {
+	if u, ok := v.stored[p]; ok {
+		return u, nil
+	}
+	data, err := readFSFile(v.fsys, p)
+	if err != nil {
+		return "", err
+	}
+	stored, _, err := nm.SaveFile(path.Base(p), data, mime.TypeByExtension(path.Ext(p)))
+	if err != nil {
+		return "", fmt.Errorf("failed to store %s: %w", p, err)
+	}
+	u := (&url.URL{Path: stored}).EscapedPath()
+	v.stored[p] = u
+	result.Files++
+	return u, nil
+}
+
+// wikiLinkTo writes a wiki link to the note titled title, with label if
+// it reads differently.
+func wikiLinkTo(title, label string) string {
+	if label == "" || models.WikiLinkKey(label) == models.WikiLinkKey(title) {
+		return "[[" + title + "]]"
+	}
+	return "[[" + title + "|" + label + "]]"
+}
+
+// isAttachmentName reports whether a link target names a file other than
+// a note, by an extension with a known type.
+func isAttachmentName(name string) bool {
+	ext := path.Ext(name)
+	return ext != "" && !strings.EqualFold(ext, ".md") && mime.TypeByExtension(ext) != ""
+}
+
+// readFSFile reads the file name of fsys, refusing one bigger than
+// maxImportFile.
+func readFSFile(fsys fs.FS, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidImport, name, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxImportFile+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidImport, name, err)
+	}
+	if len(data) > maxImportFile {
+		return nil, fmt.Errorf("%w: %s is too large", ErrInvalidImport, name)
+	}
+	return data, nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestImportObsidian(t *testing.T) {
+	modified := time.Date(2026, 10, 10, 8, 0, 0, 0, time.Local)
+	file := func(content string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(content), ModTime: modified}
+	}
+	vault := fstest.MapFS{
+		"Projects/Plan.md": file("---\ncreated: 2026-10-01 09:30\naliases: [roadmap]\nlinks:\n  repo: x\n---\n" +
+			"See [[Ideas]], [[Ideas#Later|the later ones]], [[roadmap]] and [[Not written yet]].\n\n" +
+			"![[chart.png|300]] ![[report.pdf]] ![[gone.png]]\n\n![photo](../attachments/my%20photo.jpg) [the ideas](Ideas.md)\n\n" +
+			"`[[in code]]`\n\n- [ ] ship it"),
+		"Ideas.md":                   file("Back to [[Projects/Plan|the plan]] and [[#Later]].\n\n## Later\n\nmore"),
+		"Daily.md":                   file("nothing linked"),
+		"attachments/chart.png":      {Data: []byte("png")},
+		"attachments/report.pdf":     {Data: []byte("pdf")},
+		"attachments/my photo.jpg":   {Data: []byte("jpg")},
+		".obsidian/workspace.json":   {Data: []byte("{}")},
+		".trash/Deleted.md":          file("deleted"),
+		"Projects/.hidden/Secret.md": file("secret"),
+	}
+	nm := newNoteManagerWithNote(t, "Existing", "hello")
+	result, err := nm.ImportObsidian(vault, "", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Notes != 3 || result.Added != 3 || result.Files != 3 {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Missing) != 1 || result.Missing[0] != "gone.png" {
+		t.Errorf("missing = %v", result.Missing)
+	}
+
+	notes := nm.GetAllNotes()
+	byTitle := map[string]int{}
+	for i, note := range notes {
+		byTitle[note.Title] = i
+	}
+	plan, ideas := notes[byTitle["Plan"]], notes[byTitle["Ideas"]]
+	if got := plan.Timestamp.Format("2006-01-02 15:04:05"); got != "2026-10-01 09:30:00" {
+		t.Errorf("plan dated %s, want its created key", got)
+	}
+	if plan.Metadata["aliases"] != "roadmap" || plan.Metadata["links"] != "" || !strings.HasPrefix(plan.Content, "---\naliases: \"roadmap\"\n") {
+		t.Errorf("plan metadata = %v, content %q", plan.Metadata, plan.Content)
+	}
+	for _, want := range []string{
+		"See [[Ideas]], [[Ideas|the later ones]], [[Plan|roadmap]] and [[Not written yet]].",
+		"![chart](/assets/images/chart.png) [report.pdf](/assets/files/report.pdf) ![[gone.png]]",
+		"![photo](/assets/images/my%20photo.jpg) [[Ideas|the ideas]]",
+		"`[[in code]]`",
+	} {
+		if !strings.Contains(plan.Content, want) {
+			t.Errorf("plan lacks %q:\n%s", want, plan.Content)
+		}
+	}
+	if len(plan.Tasks) != 1 {
+		t.Errorf("plan tasks = %d", len(plan.Tasks))
+	}
+	if !strings.HasPrefix(ideas.Content, "Back to [[Plan|the plan]] and Later.") {
+		t.Errorf("ideas = %q", ideas.Content)
+	}
+	if data, err := os.ReadFile(filepath.Join(nm.GetBasePath(), "assets", "images", "chart.png")); err != nil || string(data) != "png" {
+		t.Errorf("chart.png not stored: %v", err)
+	}
+
+	// Files sharing a modification time get a second each, newest first.
+	daily := notes[byTitle["Daily"]]
+	if !daily.Timestamp.Equal(wallClock(modified)) || !ideas.Timestamp.Equal(wallClock(modified).Add(-time.Second)) {
+		t.Errorf("daily at %v, ideas at %v", daily.Timestamp, ideas.Timestamp)
+	}
+
+	again, err := nm.ImportObsidian(vault, ImportMerge, time.Now())
+	if err != nil || again.Added != 0 || again.Skipped != 3 {
+		t.Errorf("importing again: %+v, %v", again, err)
+	}
+	if _, err := nm.ImportObsidian(fstest.MapFS{"a.txt": {}}, "", time.Now()); !errors.Is(err, ErrInvalidImport) {
+		t.Errorf("a vault without notes: %v", err)
+	}
+}
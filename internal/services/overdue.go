@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/notify"
+)
+
+// SetNotifier attaches a push channel for registry-level alerts. Passing nil
+// disables alerts. Safe to call before or after the background sync starts.
+func (trs *TaskRegistryService) SetNotifier(n notify.Notifier) {
+	trs.mu.Lock()
+	defer trs.mu.Unlock()
+	trs.notifier = n
+}
+
+// OverdueTasks returns the open tasks whose due date is strictly before
+// today. Due dates are read as models.ParseTaskMetadata reads them:
+// @YYYY-MM-DD, @due(YYYY-MM-DD) or 📅 YYYY-MM-DD, each optionally with a
+// THH:MM time, which is ignored here. Same grammar as the CLI's --due
+// overdue filter.
+func OverdueTasks(tasks []models.GlobalTask, today time.Time) []models.GlobalTask {
+	day := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	var out []models.GlobalTask
+	for _, t := range tasks {
+		if t.Completed {
+			continue
+		}
+		_, due, _ := models.ParseTaskMetadata(t.Content)
+		if due.IsZero() {
+			continue
+		}
+		d := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, day.Location())
+		if d.Before(day) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// checkOverdue sends at most one overdue-task alert per calendar day. It runs
+// on the background sync tick, so the first tick after midnight (or after
+// startup) is when the day's alert goes out. A day only counts as checked
+// once the tasks were read and any alert sent, so a failed query or send is
+// retried on the next tick.
+func (trs *TaskRegistryService) checkOverdue(now time.Time) {
+	trs.mu.Lock()
+	n := trs.notifier
+	today := now.Format("2006-01-02")
+	checked := trs.lastOverdueAlert == today
+	trs.mu.Unlock()
+	if n == nil || checked {
+		return
+	}
+
+	global, err := trs.db.GetGlobalTasks()
+	if err != nil {
+		log.Printf("Warning: overdue check failed: %v", err)
+		return
+	}
+	if overdue := OverdueTasks(global.Tasks, now); len(overdue) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := notify.Send(ctx, n, overdueMessage(overdue)); err != nil {
+			log.Printf("Warning: overdue notification failed: %v", err)
+			return
+		}
+	}
+
+	trs.mu.Lock()
+	trs.lastOverdueAlert = today
+	trs.mu.Unlock()
+}
+
+// overdueMessage summarizes overdue tasks into a single alert. The body lists
+// up to five tasks so the push stays readable on a lock screen.
+func overdueMessage(tasks []models.GlobalTask) notify.Message {
+	const maxListed = 5
+	var b strings.Builder
+	for i, t := range tasks {
+		if i == maxListed {
+			fmt.Fprintf(&b, "…and %d more\n", len(tasks)-maxListed)
+			break
+		}
+		fmt.Fprintf(&b, "• %s (%s)\n", stripTaskCheckbox(models.CleanTaskText(t.Content)), filepath.Base(t.FolderPath))
+	}
+	return notify.Message{
+		Title:    fmt.Sprintf("NoteFlow: %d overdue task(s)", len(tasks)),
+		Body:     strings.TrimRight(b.String(), "\n"),
+		Priority: notify.PriorityHigh,
+		Tags:     []string{"warning"},
+	}
+}
+
+// stripTaskCheckbox drops a leading "- [ ] " from a task line for display.
+func stripTaskCheckbox(line string) string {
+	t := strings.TrimPrefix(strings.TrimSpace(line), "- ")
+	for _, mark := range []string{"[ ]", "[/]", "[x]", "[X]"} {
+		if rest, ok := strings.CutPrefix(t, mark); ok {
+			return strings.TrimSpace(rest)
+		}
+	}
+	return t
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/notify"
+)
+
+type captureNotifier struct {
+	msgs  []notify.Message
+	fails int // how many sends fail before one succeeds
+}
+
+func (c *captureNotifier) Notify(_ context.Context, m notify.Message) error {
+	if c.fails > 0 {
+		c.fails--
+		return errors.New("unreachable")
+	}
+	c.msgs = append(c.msgs, m)
+	return nil
+}
+
+func TestOverdueTasks(t *testing.T) {
+	today := time.Date(2026, 5, 20, 15, 0, 0, 0, time.Local)
+	tasks := []models.GlobalTask{
+		{Content: "- [ ] @2026-05-19 late"},
+		{Content: "- [ ] @2026-05-20 due today"},
+		{Content: "- [x] @2026-05-01 done already", Completed: true},
+		{Content: "- [ ] no due date"},
+		{Content: "- [ ] @2026-04-01 very late"},
+	}
+	got := OverdueTasks(tasks, today)
+	if len(got) != 2 {
+		t.Fatalf("got %d overdue, want 2: %+v", len(got), got)
+	}
+	if !strings.Contains(got[0].Content, "late") || !strings.Contains(got[1].Content, "very late") {
+		t.Errorf("unexpected overdue set: %+v", got)
+	}
+}
+
+func TestCheckOverdue_OncePerDay(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	trs, err := NewTaskRegistryService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trs.Close()
+
+	folder, err := trs.db.RegisterFolder("/tmp/overdue-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := trs.db.SyncFolderTasks(folder.ID, []models.Task{
+		{Text: "- [ ] @2020-01-01 renew the certificate"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &captureNotifier{}
+	trs.SetNotifier(rec)
+
+	now := time.Now()
+	trs.checkOverdue(now)
+	trs.checkOverdue(now.Add(time.Minute))
+	if len(rec.msgs) != 1 {
+		t.Fatalf("sent %d alerts on the same day, want 1", len(rec.msgs))
+	}
+	if !strings.Contains(rec.msgs[0].Body, "renew the certificate") {
+		t.Errorf("alert body missing task text: %q", rec.msgs[0].Body)
+	}
+
+	trs.checkOverdue(now.Add(24 * time.Hour))
+	if len(rec.msgs) != 2 {
+		t.Errorf("expected a fresh alert the next day, got %d total", len(rec.msgs))
+	}
+}
+
+func TestCheckOverdue_RetriesAfterFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	trs, err := NewTaskRegistryService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trs.Close()
+
+	folder, err := trs.db.RegisterFolder("/tmp/overdue-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := trs.db.SyncFolderTasks(folder.ID, []models.Task{
+		{Text: "- [ ] @due(2020-01-01) renew the certificate"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &captureNotifier{fails: 1}
+	trs.SetNotifier(rec)
+
+	now := time.Now()
+	trs.checkOverdue(now)
+	if len(rec.msgs) != 0 {
+		t.Fatalf("sent %d alerts through a failing channel", len(rec.msgs))
+	}
+	trs.checkOverdue(now.Add(time.Minute))
+	trs.checkOverdue(now.Add(2 * time.Minute))
+	if len(rec.msgs) != 1 {
+		t.Errorf("sent %d alerts after the failure, want 1", len(rec.msgs))
+	}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// ErrProjectNotFound is returned for an alias no active folder has.
+var ErrProjectNotFound = errors.New("project not found")
+
+// Projects lists the active registered folders with their aliases, the
+// URL-safe names they are served under. An alias is the folder's
+// lowercased base name; when two folders share one, both get their
+// folder ID appended ("notes-3", "notes-7") so neither depends on which was
+// registered first.
+func (trs *TaskRegistryService) Projects() ([]models.Project, error) {
+	folders, err := trs.db.GetActiveFolders()
+	if err != nil {
+		return nil, err
+	}
+	projects := make([]models.Project, len(folders))
+	count := make(map[string]int)
+	for i, f := range folders {
+		projects[i] = models.Project{Alias: folderAlias(f.Path), FolderID: f.ID, Path: f.Path}
+		count[projects[i].Alias]++
+	}
+	for i := range projects {
+		if count[projects[i].Alias] > 1 {
+			projects[i].Alias += "-" + strconv.Itoa(projects[i].FolderID)
+		}
+	}
+	return projects, nil
+}
+
+// Project returns the active folder served under alias.
+func (trs *TaskRegistryService) Project(alias string) (*models.Project, error) {
+	projects, err := trs.Projects()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projects {
+		if p.Alias == alias {
+			return &p, nil
+		}
+	}
+	return nil, ErrProjectNotFound
+}
+
+// folderAlias turns a folder's base name into an alias: lowercase ASCII
+// letters, digits, "." and "_", with runs of anything else made one "-".
+func folderAlias(path string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(filepath.Base(path)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '_':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		default:
+			dash = true
+		}
+	}
+	alias := strings.TrimLeft(b.String(), ".")
+	if alias == "" {
+		return "folder"
+	}
+	return alias
+}
+
+// FolderNoteManager returns the NoteManager of a registered folder,
+// opening it on first use. Folders registered by an earlier session, or
+// added on the global tasks page, have none until then.
+func (trs *TaskRegistryService) FolderNoteManager(folderID int) (*NoteManager, error) {
+	folder, err := trs.db.GetFolderByID(folderID)
+	if err != nil {
+		return nil, fmt.Errorf("folder %d not found: %w", folderID, err)
+	}
+	trs.mu.Lock()
+	defer trs.mu.Unlock()
+	return trs.folderNoteManager(folder)
+}
+
+// folderNoteManager is FolderNoteManager for a folder already looked up.
+// trs.mu must be held.
+func (trs *TaskRegistryService) folderNoteManager(folder *models.FolderRegistry) (*NoteManager, error) {
+	if nm, ok := trs.noteManagers[folder.Path]; ok {
+		return nm, nil
+	}
+	nm, err := NewNoteManager(folder.Path)
+	if err != nil {
+		return nil, fmt.Errorf("open notes.md at %s: %w", folder.Path, err)
+	}
+	trs.noteManagers[folder.Path] = nm
+	trs.watchFolder(folder.ID, folder.Path)
+	return nm, nil
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestFolderAlias(t *testing.T) {
+	for path, want := range map[string]string{
+		"/home/me/NoteFlow-Go": "noteflow-go",
+		"/work/My Project!":    "my-project",
+		"/tmp/.dotfiles":       "dotfiles",
+		"/tmp/v1.2_notes":      "v1.2_notes",
+		"/tmp/日本語":             "folder",
+	} {
+		if got := folderAlias(path); got != want {
+			t.Errorf("folderAlias(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestProjects_DisambiguatesSharedNames(t *testing.T) {
+	db, home := newTestDB(t) // /tmp/test-project
+	trs := &TaskRegistryService{db: db}
+	a, _ := db.RegisterFolder("/work/a/notes")
+	b, _ := db.RegisterFolder("/work/b/notes")
+
+	projects, err := trs.Projects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliases := map[string]int{}
+	for _, p := range projects {
+		aliases[p.Alias] = p.FolderID
+	}
+	want := map[string]int{"test-project": home.ID, "notes-" + strconv.Itoa(a.ID): a.ID, "notes-" + strconv.Itoa(b.ID): b.ID}
+	if len(aliases) != len(want) {
+		t.Fatalf("aliases = %v, want %v", aliases, want)
+	}
+	for alias, id := range want {
+		if aliases[alias] != id {
+			t.Errorf("aliases = %v, want %v", aliases, want)
+		}
+	}
+
+	if p, err := trs.Project("test-project"); err != nil || p.Path != "/tmp/test-project" {
+		t.Errorf("Project = %+v, %v", p, err)
+	}
+	if _, err := trs.Project("notes"); !errors.Is(err, ErrProjectNotFound) {
+		t.Errorf("ambiguous alias: err = %v", err)
+	}
+}
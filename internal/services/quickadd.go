@@ -0,0 +1,156 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultCaptureNote is where quick-added tasks land when the text names
+// no >target.
+const DefaultCaptureNote = "Inbox"
+
+// QuickAdd is a parsed one-line capture.
+type QuickAdd struct {
+	Line    string `json:"line"`              // the task line as written to notes.md
+	Note    string `json:"note"`              // target note title
+	Section string `json:"section,omitempty"` // heading within the note, if any
+}
+
+var (
+	// "!2" → priority 2. The long form "!p2" passes through untouched.
+	quickPriorityRE = regexp.MustCompile(`(^|\s)!([0-3])(\s|$)`)
+	// ">ProjectX" or ">ProjectX/Design" → target note and section.
+	quickTargetRE = regexp.MustCompile(`(^|\s)>([^\s/]+)(?:/(\S+))?`)
+)
+
+// ParseQuickAdd turns compact capture syntax into a task line and target:
+//
+//	Buy cake #errands !2 @due(sat) >ProjectX/Shopping
+//
+// becomes "- [ ] Buy cake #errands !p2 @2026-10-17" filed under the
+// "Shopping" heading of the note whose title matches "ProjectX". Tags and
+// @due/^ phrases use the ordinary inline-metadata syntax; "!N" is
+// shorthand for "!pN"; ">Note[/Section]" picks the destination, with
+// underscores standing in for spaces.
+func ParseQuickAdd(text string, now time.Time) (QuickAdd, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	text = strings.TrimPrefix(strings.TrimPrefix(text, "- "), "[ ] ")
+
+	q := QuickAdd{Note: DefaultCaptureNote}
+	if m := quickTargetRE.FindStringSubmatch(text); m != nil {
+		q.Note = strings.ReplaceAll(m[2], "_", " ")
+		q.Section = strings.ReplaceAll(m[3], "_", " ")
+		text = strings.Replace(text, m[0], m[1], 1)
+	}
+	text = quickPriorityRE.ReplaceAllString(text, "$1!p$2$3")
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return QuickAdd{}, fmt.Errorf("nothing to capture")
+	}
+	q.Line = expandDueDates("- [ ] "+text, now)
+	return q, nil
+}
+
+// QuickAddTask parses text with ParseQuickAdd and files the task: into an
+// existing note whose title loosely matches the target (case, spaces and
+// punctuation ignored), otherwise into a new note with that title.
+func (nm *NoteManager) QuickAddTask(text string) (QuickAdd, error) {
+	q, err := ParseQuickAdd(text, nm.now())
+	if err != nil {
+		return QuickAdd{}, err
+	}
+	q.Note = nm.matchNoteTitle(q.Note)
+	err = nm.EditNoteByTitle(q.Note, func(content string) string {
+		return insertIntoSection(content, q.Section, q.Line)
+	})
+	return q, err
+}
+
+// CaptureNote adds text, a message from a chat app, as a note titled with
+// its first line when it has several, and returns the title.
+func (nm *NoteManager) CaptureNote(text string) (string, error) {
+	title, content := "", strings.TrimSpace(text)
+	if first, rest, ok := strings.Cut(content, "\n"); ok && strings.TrimSpace(rest) != "" {
+		title, content = strings.TrimSpace(first), strings.TrimSpace(rest)
+	}
+	return title, nm.AddNote(title, content)
+}
+
+// matchNoteTitle returns the title of the newest note matching name
+// loosely, or name itself when none does.
+func (nm *NoteManager) matchNoteTitle(name string) string {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+	want := looseKey(name)
+	for _, note := range nm.notes {
+		if looseKey(note.Title) == want {
+			return note.Title
+		}
+	}
+	return name
+}
+
+// looseKey lowercases s and drops everything but letters and digits, so
+// ">projectx" finds "Project X" and "project-x".
+func looseKey(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') || r > 127 {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+var headingRE = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+
+// insertIntoSection appends line to the end of the named heading's section
+// in content — just before the next heading of the same or higher level —
+// adding a "### section" heading at the end when none matches. With no
+// section the line is simply appended.
+func insertIntoSection(content, section, line string) string {
+	content = strings.TrimRight(content, "\n")
+	if section == "" {
+		if content == "" {
+			return line
+		}
+		return content + "\n" + line
+	}
+
+	lines := strings.Split(content, "\n")
+	start, level := -1, 0
+	for i, l := range lines {
+		m := headingRE.FindStringSubmatch(l)
+		if m == nil {
+			continue
+		}
+		if start >= 0 && len(m[1]) <= level {
+			return joinInsert(lines, start, i, line)
+		}
+		if start < 0 && looseKey(m[2]) == looseKey(section) {
+			start, level = i, len(m[1])
+		}
+	}
+	if start >= 0 {
+		return joinInsert(lines, start, len(lines), line)
+	}
+	if content == "" {
+		return "### " + section + "\n" + line
+	}
+	return content + "\n\n### " + section + "\n" + line
+}
+
+// joinInsert places line after the last non-blank line of lines[start:end].
+func joinInsert(lines []string, start, end int, line string) string {
+	at := end
+	for at > start+1 && strings.TrimSpace(lines[at-1]) == "" {
+		at--
+	}
+	out := make([]string, 0, len(lines)+1)
+	out = append(out, lines[:at]...)
+	out = append(out, line)
+	out = append(out, lines[at:]...)
+	return strings.Join(out, "\n")
+}
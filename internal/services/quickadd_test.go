@@ -0,0 +1,82 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC) // a Friday
+	tests := []struct {
+		in   string
+		want QuickAdd
+	}{
+		{"Buy cake #errands !2 @due(sat) >ProjectX",
+			QuickAdd{Line: "- [ ] Buy cake #errands !p2 @2026-10-17", Note: "ProjectX"}},
+		{">Home/Garden_Shed fix door !1",
+			QuickAdd{Line: "- [ ] fix door !p1", Note: "Home", Section: "Garden Shed"}},
+		{"call mom ^tomorrow",
+			QuickAdd{Line: "- [ ] call mom @2026-10-17", Note: DefaultCaptureNote}},
+		{"- [ ] already a task !p3",
+			QuickAdd{Line: "- [ ] already a task !p3", Note: DefaultCaptureNote}},
+		{"compare a > b and 5 !9",
+			QuickAdd{Line: "- [ ] compare a > b and 5 !9", Note: DefaultCaptureNote}},
+	}
+	for _, tt := range tests {
+		got, err := ParseQuickAdd(tt.in, now)
+		if err != nil {
+			t.Errorf("ParseQuickAdd(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseQuickAdd(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+	if _, err := ParseQuickAdd("  >Inbox  ", now); err == nil {
+		t.Error("expected error for a capture with no text")
+	}
+}
+
+func TestInsertIntoSection(t *testing.T) {
+	content := "intro\n\n## Shopping\n- [ ] milk\n\n## Chores\n- [ ] dishes"
+	got := insertIntoSection(content, "shopping", "- [ ] cake")
+	want := "intro\n\n## Shopping\n- [ ] milk\n- [ ] cake\n\n## Chores\n- [ ] dishes"
+	if got != want {
+		t.Errorf("existing section:\n%s\nwant:\n%s", got, want)
+	}
+
+	got = insertIntoSection(content, "Chores", "- [ ] laundry")
+	if !strings.HasSuffix(got, "- [ ] dishes\n- [ ] laundry") {
+		t.Errorf("last section:\n%s", got)
+	}
+
+	got = insertIntoSection("intro", "Later", "- [ ] x")
+	if got != "intro\n\n### Later\n- [ ] x" {
+		t.Errorf("new section:\n%s", got)
+	}
+}
+
+func TestQuickAddTask_MatchesExistingNote(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Project X", "## Design\n- [ ] mockups"); err != nil {
+		t.Fatal(err)
+	}
+	added, err := mgr.QuickAddTask("review colors #ui >projectx/design")
+	if err != nil {
+		t.Fatalf("QuickAddTask: %v", err)
+	}
+	if added.Note != "Project X" {
+		t.Errorf("note = %q, want existing title", added.Note)
+	}
+	notes := mgr.GetAllNotes()
+	if len(notes) != 1 || !strings.Contains(notes[0].Content, "- [ ] mockups\n- [ ] review colors #ui") {
+		t.Fatalf("notes = %d, content = %q", len(notes), notes[0].Content)
+	}
+	if len(notes[0].Tasks) != 2 {
+		t.Errorf("tasks = %d, want 2", len(notes[0].Tasks))
+	}
+}
@@ -0,0 +1,421 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+)
+
+// RegistryExportVersion is the format version ExportRegistry writes and
+// the newest ImportRegistry reads.
+const RegistryExportVersion = 1
+
+// RegistryExport is a task registry as JSON, for moving it to another
+// machine: the folders with their labels, tasks and task history, and
+// the saved views.
+// IDs are not kept; folders are matched by path and tasks by hash.
+type RegistryExport struct {
+	Version  int               `json:"version"`
+	Exported time.Time         `json:"exported"`
+	Folders  []RegistryFolder  `json:"folders"`
+	Views    map[string]string `json:"views,omitempty"` // name -> filters JSON
+}
+
+// RegistryFolder is a registered folder in a RegistryExport.
+type RegistryFolder struct {
+	Path     string         `json:"path"`
+	Alias    string         `json:"alias,omitempty"`
+	Group    string         `json:"group,omitempty"`
+	Active   bool           `json:"active"`
+	LastScan time.Time      `json:"last_scan"`
+	Tasks    []RegistryTask `json:"tasks"`
+	History  []TaskEvent    `json:"history,omitempty"`
+}
+
+// RegistryTask is a task in a RegistryExport. Updated is when its text or
+// completion last changed, so for a completed task when it was completed.
+type RegistryTask struct {
+	Hash       string    `json:"hash"`
+	Content    string    `json:"content"`
+	Completed  bool      `json:"completed"`
+	Updated    time.Time `json:"updated"`
+	FilePath   string    `json:"file_path"`
+	Line       int       `json:"line"`
+	DueDate    string    `json:"due_date,omitempty"` // see models.FormatDueValue
+	NoteID     string    `json:"note_id,omitempty"`
+	CharOffset int       `json:"char_offset,omitempty"`
+}
+
+// RegistryImportResult reports what ImportRegistry did.
+type RegistryImportResult struct {
+	Added   []string          `json:"added"`             // folders registered by the import
+	Updated []string          `json:"updated"`           // folders registered before, merged
+	Missing []string          `json:"missing,omitempty"` // no notes here: imported as forgotten
+	Skipped map[string]string `json:"skipped,omitempty"` // folder -> why it wasn't imported
+	Tasks   int               `json:"tasks"`             // tasks written
+	Views   int               `json:"views"`             // views written
+}
+
+// PathRewrite maps folders under From to the same place under To, for
+// importing a registry exported where the notes lived elsewhere.
+type PathRewrite struct {
+	From string
+	To   string
+}
+
+// ParsePathRewrite reads a rewrite given as OLD=NEW.
+func ParsePathRewrite(s string) (PathRewrite, error) {
+	from, to, ok := strings.Cut(s, "=")
+	if !ok || from == "" || to == "" {
+		return PathRewrite{}, fmt.Errorf("rewrite %q is not OLD=NEW", s)
+	}
+	return PathRewrite{From: from, To: to}, nil
+}
+
+// RewritePaths moves every folder under a rewrite's From to its To; the
+// first rewrite a folder is under applies.
+func (e *RegistryExport) RewritePaths(rewrites []PathRewrite) {
+	for i := range e.Folders {
+		for _, r := range rewrites {
+			from := strings.TrimRight(r.From, `/\`)
+			path := e.Folders[i].Path
+			if path == from || strings.HasPrefix(path, from+"/") || strings.HasPrefix(path, from+`\`) {
+				e.Folders[i].Path = filepath.Clean(strings.TrimRight(r.To, `/\`) + filepath.FromSlash(path[len(from):]))
+				break
+			}
+		}
+	}
+}
+
+// ExportRegistry returns the service's user's folders, forgotten ones
+// included, with their tasks, and for the owner the saved views.
+func (ds *DatabaseService) ExportRegistry(now time.Time) (*RegistryExport, error) {
+	folders, err := ds.GetFolders(true)
+	if err != nil {
+		return nil, err
+	}
+	export := &RegistryExport{Version: RegistryExportVersion, Exported: now.UTC(), Folders: []RegistryFolder{}}
+	for _, f := range folders {
+		folder := RegistryFolder{Path: f.Path, Alias: f.Alias, Group: f.Group, Active: f.Active, LastScan: f.LastScan}
+		if folder.Tasks, err = ds.exportTasks(f.ID); err != nil {
+			return nil, err
+		}
+		if folder.History, err = ds.folderHistory(f.ID); err != nil {
+			return nil, err
+		}
+		export.Folders = append(export.Folders, folder)
+	}
+	if ds.user == 0 {
+		names, err := ds.ListViews()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			filters, err := ds.GetView(name)
+			if err != nil {
+				return nil, err
+			}
+			if export.Views == nil {
+				export.Views = map[string]string{}
+			}
+			export.Views[name] = filters
+		}
+	}
+	return export, nil
+}
+
+func (ds *DatabaseService) exportTasks(folderID int) ([]RegistryTask, error) {
+	rows, err := ds.db.Query(`
+		SELECT task_hash, content, completed, last_updated, file_path, line_number,
+		       COALESCE(due_date, ''), COALESCE(note_id, ''), char_offset
+		FROM tasks WHERE folder_id = ? AND task_hash IS NOT NULL
+		ORDER BY line_number, id`, folderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+	tasks := []RegistryTask{}
+	for rows.Next() {
+		var t RegistryTask
+		if err := rows.Scan(&t.Hash, &t.Content, &t.Completed, &t.Updated, &t.FilePath, &t.Line, &t.DueDate, &t.NoteID, &t.CharOffset); err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// folderHistory returns a folder's task history, oldest first.
+func (ds *DatabaseService) folderHistory(folderID int) ([]TaskEvent, error) {
+	rows, err := ds.db.Query(`
+		SELECT task_hash, content, event, completed, at FROM task_history
+		WHERE folder_id = ? ORDER BY at, id`, folderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task history: %w", err)
+	}
+	defer rows.Close()
+	var events []TaskEvent
+	for rows.Next() {
+		var e TaskEvent
+		if err := rows.Scan(&e.Hash, &e.Content, &e.Event, &e.Completed, &e.At); err != nil {
+			return nil, fmt.Errorf("failed to scan task event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// ImportRegistry merges an exported registry into the service's user's.
+// Folders not registered yet are added; registered ones take the file's
+// alias and group where it has them, and stay active if either side is.
+// A task replaces the one with the same hash only if it changed later,
+// and tasks missing from the file are kept; history events are added
+// unless already recorded, so importing twice changes nothing. Folders
+// without notes on this machine are imported forgotten, tasks and all,
+// for ReactivateFolder once the notes are in place; those under another
+// user's name or not absolute are skipped.
+func (ds *DatabaseService) ImportRegistry(export *RegistryExport) (*RegistryImportResult, error) {
+	if export.Version < 1 || export.Version > RegistryExportVersion {
+		return nil, fmt.Errorf("unsupported registry export version %d (want 1 to %d)", export.Version, RegistryExportVersion)
+	}
+	result := &RegistryImportResult{Added: []string{}, Updated: []string{}}
+	skip := func(path, why string) {
+		if result.Skipped == nil {
+			result.Skipped = map[string]string{}
+		}
+		result.Skipped[path] = why
+	}
+
+	tx, err := ds.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, folder := range export.Folders {
+		if !filepath.IsAbs(folder.Path) {
+			skip(folder.Path, "not an absolute path")
+			continue
+		}
+		folder.Path = filepath.Clean(folder.Path)
+		active := folder.Active
+		if active && !storage.HasNotes(folder.Path) {
+			active = false
+			result.Missing = append(result.Missing, folder.Path)
+		}
+
+		var id, owner int
+		err := tx.QueryRow(`SELECT id, COALESCE(user_id, 0) FROM folders WHERE path = ?`, folder.Path).Scan(&id, &owner)
+		switch {
+		case err == sql.ErrNoRows:
+			res, err := tx.Exec(`
+				INSERT INTO folders (path, last_scan, active, user_id, alias, group_name)
+				VALUES (?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''))`,
+				folder.Path, folder.LastScan, active, ds.userID(), folder.Alias, folder.Group)
+			if err != nil {
+				return nil, fmt.Errorf("failed to register folder %s: %w", folder.Path, err)
+			}
+			id64, _ := res.LastInsertId()
+			id = int(id64)
+			result.Added = append(result.Added, folder.Path)
+		case err != nil:
+			return nil, fmt.Errorf("failed to check existing folder: %w", err)
+		case owner != ds.user:
+			skip(folder.Path, ErrFolderOwned.Error())
+			continue
+		default:
+			if _, err := tx.Exec(`
+				UPDATE folders SET alias = COALESCE(NULLIF(?, ''), alias), group_name = COALESCE(NULLIF(?, ''), group_name),
+					active = (active OR ?)
+				WHERE id = ?`, folder.Alias, folder.Group, active, id); err != nil {
+				return nil, fmt.Errorf("failed to update folder %s: %w", folder.Path, err)
+			}
+			result.Updated = append(result.Updated, folder.Path)
+		}
+
+		n, err := importTasks(tx, id, folder.Tasks)
+		if err != nil {
+			return nil, fmt.Errorf("import tasks of %s: %w", folder.Path, err)
+		}
+		result.Tasks += n
+		if err := importHistory(tx, id, folder.History); err != nil {
+			return nil, fmt.Errorf("import task history of %s: %w", folder.Path, err)
+		}
+	}
+
+	if ds.user == 0 {
+		names := make([]string, 0, len(export.Views))
+		for name := range export.Views {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, err := tx.Exec(`
+				INSERT INTO task_views (name, filters) VALUES (?, ?)
+				ON CONFLICT(name) DO UPDATE SET filters = excluded.filters`, name, export.Views[name]); err != nil {
+				return nil, fmt.Errorf("failed to save view %s: %w", name, err)
+			}
+			result.Views++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// importTasks writes tasks into folderID, each replacing the task with
+// its hash unless that one changed since, and returns how many it wrote.
+func importTasks(tx *sql.Tx, folderID int, tasks []RegistryTask) (int, error) {
+	existing := map[string]time.Time{}
+	rows, err := tx.Query(`SELECT task_hash, last_updated FROM tasks WHERE folder_id = ? AND task_hash IS NOT NULL`, folderID)
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var hash string
+		var updated time.Time
+		if err := rows.Scan(&hash, &updated); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		existing[hash] = updated
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for _, t := range tasks {
+		if t.Hash == "" || t.Content == "" {
+			continue
+		}
+		if t.FilePath == "" {
+			t.FilePath = "notes.md"
+		}
+		updated, ok := existing[t.Hash]
+		if ok && !t.Updated.After(updated) {
+			continue
+		}
+		if ok {
+			_, err = tx.Exec(`
+				UPDATE tasks SET content = ?, completed = ?, last_updated = ?, file_path = ?, line_number = ?,
+					due_date = NULLIF(?, ''), note_id = NULLIF(?, ''), char_offset = ?
+				WHERE folder_id = ? AND task_hash = ?`,
+				t.Content, t.Completed, t.Updated, t.FilePath, t.Line, t.DueDate, t.NoteID, t.CharOffset, folderID, t.Hash)
+		} else {
+			_, err = tx.Exec(`
+				INSERT INTO tasks (folder_id, file_path, line_number, content, completed, last_updated, task_hash, due_date, note_id, char_offset)
+				VALUES (?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?)`,
+				folderID, t.FilePath, t.Line, t.Content, t.Completed, t.Updated, t.Hash, t.DueDate, t.NoteID, t.CharOffset)
+		}
+		if err != nil {
+			return written, fmt.Errorf("task %s: %w", t.Hash, err)
+		}
+		written++
+	}
+	return written, nil
+}
+
+// importHistory adds the events folderID doesn't have yet.
+func importHistory(tx *sql.Tx, folderID int, events []TaskEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	key := func(hash, event string, at time.Time) string {
+		return fmt.Sprintf("%s %s %d", hash, event, at.UnixNano())
+	}
+	have := map[string]bool{}
+	rows, err := tx.Query(`SELECT task_hash, event, at FROM task_history WHERE folder_id = ?`, folderID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var hash, event string
+		var at time.Time
+		if err := rows.Scan(&hash, &event, &at); err != nil {
+			rows.Close()
+			return err
+		}
+		have[key(hash, event, at)] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, e := range events {
+		switch e.Event {
+		case TaskEventAdded, TaskEventCompleted, TaskEventReopened, TaskEventRemoved:
+		default:
+			continue
+		}
+		if e.Hash == "" || have[key(e.Hash, e.Event, e.At)] {
+			continue
+		}
+		if err := recordTaskEvent(tx, folderID, e.Hash, e.Content, e.Event, e.Completed, e.At); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportRegistry exports the registry; see DatabaseService.ExportRegistry.
+func (trs *TaskRegistryService) ExportRegistry(now time.Time) (*RegistryExport, error) {
+	return trs.db.ExportRegistry(now)
+}
+
+// ImportRegistry rewrites the export's paths, imports it (see
+// DatabaseService.ImportRegistry) and starts tracking the active folders
+// it brought in, which re-reads their tasks from their notes. A user's
+// registry skips folders outside their notes root.
+func (trs *TaskRegistryService) ImportRegistry(export *RegistryExport, rewrites []PathRewrite) (*RegistryImportResult, error) {
+	export.RewritePaths(rewrites)
+	outside := map[string]string{}
+	kept := export.Folders[:0:0]
+	for _, folder := range export.Folders {
+		if err := trs.checkInsideRoot(folder.Path); err != nil {
+			outside[folder.Path] = err.Error()
+			continue
+		}
+		kept = append(kept, folder)
+	}
+	export.Folders = kept
+
+	result, err := trs.db.ImportRegistry(export)
+	if err != nil {
+		return nil, err
+	}
+	for path, why := range outside {
+		if result.Skipped == nil {
+			result.Skipped = map[string]string{}
+		}
+		result.Skipped[path] = why
+	}
+
+	folders, err := trs.db.GetActiveFolders()
+	if err != nil {
+		return result, err
+	}
+	imported := map[string]bool{}
+	for _, path := range append(result.Added, result.Updated...) {
+		imported[path] = true
+	}
+	for _, folder := range folders {
+		if imported[folder.Path] {
+			if err := trs.SyncFolderByID(folder.ID); err != nil {
+				log.Printf("Warning: sync imported folder %s: %v", folder.Path, err)
+			}
+		}
+	}
+	trs.events.Publish(Event{Type: EventTasksSynced})
+	log.Printf("Imported %d folder(s) into the task registry, %d new, %d task(s)",
+		len(result.Added)+len(result.Updated), len(result.Added), result.Tasks)
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestImportRegistry_Merges(t *testing.T) {
+	svc, folder := newTestDB(t)
+	if err := svc.SyncFolderTasks(folder.ID, []models.Task{{Text: "- [ ] ship it"}, {Text: "- [ ] write docs"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SaveView("open", `{"done":false}`); err != nil {
+		t.Fatal(err)
+	}
+	export, err := svc.ExportRegistry(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(export.Folders) != 1 || len(export.Folders[0].Tasks) != 2 || export.Views["open"] != `{"done":false}` {
+		t.Fatalf("export = %+v", export)
+	}
+
+	// Importing what is already there writes nothing.
+	result, err := svc.ImportRegistry(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Tasks != 0 || len(result.Updated) != 1 || len(result.Added) != 0 {
+		t.Errorf("re-import = %+v", result)
+	}
+
+	// A task completed later elsewhere wins; one changed earlier doesn't.
+	ship, docs := &export.Folders[0].Tasks[0], &export.Folders[0].Tasks[1]
+	ship.Content, ship.Completed, ship.Updated = "- [x] ship it", true, ship.Updated.Add(time.Hour)
+	docs.Content, docs.Completed, docs.Updated = "- [x] write docs", true, docs.Updated.Add(-time.Hour)
+	export.Folders[0].Alias = "Project"
+	export.Folders = append(export.Folders, RegistryFolder{Path: "relative/path"})
+	if result, err = svc.ImportRegistry(export); err != nil {
+		t.Fatal(err)
+	}
+	if result.Tasks != 1 || result.Skipped["relative/path"] == "" {
+		t.Errorf("import = %+v", result)
+	}
+	global, _ := svc.GetGlobalTasks()
+	done := map[string]bool{}
+	for _, task := range global.Tasks {
+		done[task.Content] = task.Completed
+		if task.FolderName != "Project" {
+			t.Errorf("folder name = %q", task.FolderName)
+		}
+	}
+	if !done["- [x] ship it"] || done["- [ ] write docs"] {
+		t.Errorf("tasks after import = %v", done)
+	}
+
+	// Another user can't take over the owner's folder.
+	if result, err = svc.ForUser(2).ImportRegistry(export); err != nil {
+		t.Fatal(err)
+	}
+	if result.Skipped[folder.Path] == "" || result.Views != 0 {
+		t.Errorf("other user's import = %+v", result)
+	}
+
+	export.Version = RegistryExportVersion + 1
+	if _, err := svc.ImportRegistry(export); err == nil {
+		t.Error("a newer export version was accepted")
+	}
+}
+
+func TestRewritePaths(t *testing.T) {
+	export := &RegistryExport{Folders: []RegistryFolder{{Path: "/Users/ana/code/app"}, {Path: "/Users/anabel/notes"}, {Path: "/Users/ana"}}}
+	export.RewritePaths([]PathRewrite{{From: "/Users/ana/", To: "/home/ana"}})
+	want := []string{"/home/ana/code/app", "/Users/anabel/notes", "/home/ana"}
+	for i, folder := range export.Folders {
+		if folder.Path != want[i] {
+			t.Errorf("folder %d = %q, want %q", i, folder.Path, want[i])
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/diff"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+)
+
+// fileStamp identifies one version of notes.md on disk.
+type fileStamp struct {
+	mod  time.Time
+	size int64
+}
+
+// statNotesFile returns the current stamp of notes.md.
+func (nm *NoteManager) statNotesFile() (fileStamp, error) {
+	mod, size, err := nm.storage.NotesStamp()
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{mod: mod, size: size}, nil
+}
+
+// ErrNotesChangedOnDisk is returned by a change that was not saved because
+// another program changed notes.md after the manager last read it, in the
+// same lines. Writing would have thrown that edit away; instead the
+// manager reloads the file and sets the unsaved changes aside in a
+// conflict copy (see mergeFromDisk), so the change can be made again over
+// what is there now.
+var ErrNotesChangedOnDisk = errors.New("notes.md was changed by another program; it has been reloaded, make the change again")
+
+// ReloadIfChanged re-reads notes.md when another program (an editor, git
+// pull) has changed it since this manager last loaded or saved it, and
+// reports whether it did. The manager's own saves are recognised by the
+// file's size and modification time and don't cause a reload. Changes not
+// yet saved are merged into the file rather than dropped.
+func (nm *NoteManager) ReloadIfChanged() (bool, error) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	return nm.reload()
+}
+
+// reload is ReloadIfChanged for callers holding nm.mu.
+func (nm *NoteManager) reload() (bool, error) {
+	// Stat before reading: a write racing the read leaves a stamp that
+	// doesn't match the file, so the next check reloads again.
+	stamp, err := nm.statNotesFile()
+	if err != nil {
+		return false, err
+	}
+	if stamp == nm.diskStamp {
+		return false, nil
+	}
+	if nm.needsSave {
+		conflictCopy, err := nm.mergeFromDisk()
+		if err != nil {
+			return false, err
+		}
+		if conflictCopy != "" {
+			log.Printf("Warning: %s: notes.md changed on disk in the same lines as changes not yet saved; those are in %s", nm.storage.BasePath, conflictCopy)
+		}
+		return true, nil
+	}
+	notes, err := nm.storage.LoadNotes()
+	if err != nil {
+		return false, err
+	}
+	nm.setNotes(notes)
+	nm.diskStamp = stamp
+	nm.base = storage.RenderNotes(notes)
+	nm.events.Publish(Event{Type: EventNotesChanged, Folder: nm.storage.BasePath})
+	return true, nil
+}
+
+// mergeFromDisk loads notes.md as another program left it and merges the
+// changes not yet saved into it, line by line against the notes as last
+// loaded or saved, so that neither side's edits are lost. The merged notes
+// still need saving. Where both sides changed the same lines, notes.md is
+// kept as it is and the unsaved notes are written to a conflict copy
+// beside it, whose name is returned. Callers hold nm.mu and have changes
+// to save; on error nothing has changed.
+func (nm *NoteManager) mergeFromDisk() (conflictCopy string, err error) {
+	stamp, err := nm.statNotesFile()
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	theirs, err := nm.storage.LoadNotes()
+	if err != nil {
+		return "", err
+	}
+	ours := storage.RenderNotes(nm.notes)
+	disk := storage.RenderNotes(theirs)
+	merged, conflicts := diff.Merge(nm.base, ours, disk, "unsaved", "notes.md")
+	if conflicts > 0 {
+		if conflictCopy, err = nm.storage.SaveConflictCopy([]byte(ours), time.Now()); err != nil {
+			return "", err
+		}
+		nm.setNotes(theirs)
+		nm.needsSave = false
+	} else {
+		notes, err := storage.ParseNotes(merged)
+		if err != nil {
+			return "", err
+		}
+		nm.setNotes(notes)
+	}
+	nm.diskStamp = stamp
+	nm.base = disk
+	nm.events.Publish(Event{Type: EventNotesChanged, Folder: nm.storage.BasePath})
+	return conflictCopy, nil
+}
+
+// setNotes replaces the notes with ones read from storage. Callers hold
+// nm.mu.
+func (nm *NoteManager) setNotes(notes []*models.Note) {
+	nm.notes = notes
+	nm.assignTaskIndices()
+	nm.rebuildIndexes()
+}
+
+// synced records the notes as just loaded or saved: notes.md's stamp, and
+// the base later changes are merged against. Callers hold nm.mu.
+func (nm *NoteManager) synced() {
+	nm.diskStamp, _ = nm.statNotesFile()
+	nm.base = storage.RenderNotes(nm.notes)
+}
+
+// refresh picks up changes another program made to notes.md, so an edit
+// about to be made applies to the file as it is rather than overwriting
+// it. The watcher usually has already; this covers the moments before it
+// fires and folders it doesn't watch. Callers hold nm.mu. A failed reload
+// is logged and left to saveEvent's check.
+func (nm *NoteManager) refresh() {
+	if nm.needsSave {
+		return
+	}
+	if _, err := nm.reload(); err != nil && !os.IsNotExist(err) {
+		log.Printf("Warning: failed to reload %s: %v", nm.storage.BasePath, err)
+	}
+}
+
+// changedOnDisk reports whether notes.md is no longer as this manager last
+// loaded or saved it. A file that is gone doesn't count: saving recreates
+// it.
+func (nm *NoteManager) changedOnDisk() bool {
+	stamp, err := nm.statNotesFile()
+	return err == nil && nm.diskStamp != (fileStamp{}) && stamp != nm.diskStamp
+}
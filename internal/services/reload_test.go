@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// editOutside rewrites notes.md the way another program would.
+func editOutside(t *testing.T, dir string, edit func(string) string) {
+	t.Helper()
+	path := filepath.Join(dir, "notes.md")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(edit(string(data))), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEditAfterExternalChangeKeepsIt(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Plan", "- [ ] Write tests"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing watches this folder: the edit is picked up when the next
+	// change is made.
+	editOutside(t, dir, func(s string) string { return strings.Replace(s, "Write tests", "Write more tests", 1) })
+	if err := mgr.UpdateTask(0, true); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "notes.md"))
+	if !strings.Contains(string(data), "- [x] Write more tests") {
+		t.Errorf("notes.md = %q", data)
+	}
+}
+
+func TestSaveRefusesToClobberExternalChange(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Plan", "mine"); err != nil {
+		t.Fatal(err)
+	}
+
+	// An unsaved change made before another program edits the file.
+	mgr.mu.Lock()
+	mgr.notes[0].Update("Plan", "mine, edited")
+	mgr.needsSave = true
+	editOutside(t, dir, func(s string) string { return strings.Replace(s, "mine", "theirs", 1) })
+	err = mgr.save()
+	mgr.mu.Unlock()
+
+	if !errors.Is(err, ErrNotesChangedOnDisk) {
+		t.Fatalf("save: err = %v, want ErrNotesChangedOnDisk", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "notes.md"))
+	if !strings.Contains(string(data), "theirs") {
+		t.Errorf("notes.md = %q; the outside edit was overwritten", data)
+	}
+	if notes := mgr.GetAllNotes(); notes[0].Content != "theirs" {
+		t.Errorf("manager not reloaded: %q", notes[0].Content)
+	}
+	copies, _ := filepath.Glob(filepath.Join(dir, "notes.conflict-*.md"))
+	if len(copies) != 1 {
+		t.Fatalf("conflict copies = %v", copies)
+	}
+	if data, _ := os.ReadFile(copies[0]); !strings.Contains(string(data), "mine, edited") {
+		t.Errorf("conflict copy = %q", data)
+	}
+}
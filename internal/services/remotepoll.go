@@ -0,0 +1,48 @@
+package services
+
+import (
+	"log"
+	"time"
+)
+
+// StartRemotePolling checks for edits made elsewhere every interval, for
+// notes kept on a server, where no file watcher sees them: another
+// client's changes are loaded and pushed to the browser like an editor's
+// changes to notes.md. StopRemotePolling, or Close, ends it.
+func (nm *NoteManager) StartRemotePolling(interval time.Duration) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if nm.stopPolling != nil || interval <= 0 {
+		return
+	}
+	nm.stopPolling = make(chan struct{})
+	go func(stop <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+			nm.mu.Lock()
+			// Changes waiting for the save delay are checked when written.
+			if !nm.needsSave {
+				if _, err := nm.reload(); err != nil {
+					log.Printf("Warning: failed to check %s for remote changes: %v", nm.storage.BasePath, err)
+				}
+			}
+			nm.mu.Unlock()
+		}
+	}(nm.stopPolling)
+}
+
+// StopRemotePolling stops the loop started by StartRemotePolling.
+func (nm *NoteManager) StopRemotePolling() {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if nm.stopPolling != nil {
+		close(nm.stopPolling)
+		nm.stopPolling = nil
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RenderCacheStats describes the rendered-note cache, for
+// GET /api/debug/render-cache.
+type RenderCacheStats struct {
+	Entries int    `json:"entries"`
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+}
+
+// renderCache holds the HTML of rendered notes keyed by a hash of
+// everything that goes into it, so an edited note simply misses. Rendering
+// also depends on which titles wiki links resolve to; rebuildLinkIndexes
+// clears the cache when that changes.
+type renderCache struct {
+	mu           sync.Mutex
+	entries      map[[sha256.Size]byte]string
+	hits, misses uint64
+	// epoch changes with every clear; see NotesETag. It starts from the
+	// clock so that tags handed out by an earlier run don't match.
+	epoch uint64
+}
+
+func newRenderCache() *renderCache {
+	return &renderCache{entries: make(map[[sha256.Size]byte]string), epoch: uint64(time.Now().UnixNano())}
+}
+
+// renderCacheKey identifies the rendering of a note's content at index
+// under the header text display.
+func renderCacheKey(index int, display, content string) [sha256.Size]byte {
+	return sha256.Sum256(fmt.Appendf(nil, "%d\x00%s\x00%s", index, display, content))
+}
+
+func (c *renderCache) get(key [sha256.Size]byte) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	html, ok := c.entries[key]
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	return html, ok
+}
+
+func (c *renderCache) put(key [sha256.Size]byte, html string) {
+	c.mu.Lock()
+	c.entries[key] = html
+	c.mu.Unlock()
+}
+
+// retain drops every entry not in keep: after a render of all notes,
+// whatever it didn't use belongs to old versions of notes.
+func (c *renderCache) retain(keep map[[sha256.Size]byte]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if !keep[key] {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *renderCache) clear() {
+	c.mu.Lock()
+	clear(c.entries)
+	c.epoch++
+	c.mu.Unlock()
+}
+
+func (c *renderCache) currentEpoch() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.epoch
+}
+
+func (c *renderCache) stats() RenderCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return RenderCacheStats{Entries: len(c.entries), Hits: c.hits, Misses: c.misses}
+}
+
+// RenderCacheStats reports the size and hit rate of the rendered-note
+// cache.
+func (nm *NoteManager) RenderCacheStats() RenderCacheStats {
+	return nm.renderCache.stats()
+}
+
+// NotesETag is an entity tag for what RenderNotesHTMLFiltered(tag, mention)
+// returns now, worked out without rendering: it changes whenever the notes
+// do and whenever the cache is cleared because rendering itself changed
+// (wiki link targets, link previews). Take it before rendering, so a
+// change in between leaves the client with a stale tag, never a stale
+// page.
+func (nm *NoteManager) NotesETag(tag, mention string) string {
+	generation := nm.Generation()
+	sum := sha256.Sum256(fmt.Appendf(nil, "%d\x00%d\x00%s\x00%s", generation, nm.renderCache.currentEpoch(), tag, mention))
+	return `"` + hex.EncodeToString(sum[:12]) + `"`
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderCache(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range [][2]string{{"Roadmap", "Q4 goals"}, {"Standup", "See [[Budget]]"}} {
+		if err := mgr.AddNote(n[0], n[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	render := func() string {
+		t.Helper()
+		html, err := mgr.RenderNotesHTML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return html
+	}
+	first := render()
+	if s := mgr.RenderCacheStats(); s.Entries != 2 || s.Misses != 2 || s.Hits != 0 {
+		t.Fatalf("after first render: %+v", s)
+	}
+	if render() != first {
+		t.Error("cached render differs")
+	}
+	if s := mgr.RenderCacheStats(); s.Hits != 2 {
+		t.Errorf("after second render: %+v", s)
+	}
+
+	// An edit misses, and the old version's entry goes.
+	if err := mgr.UpdateNote(1, "Roadmap", "Q1 goals"); err != nil {
+		t.Fatal(err)
+	}
+	if html := render(); !strings.Contains(html, "Q1 goals") {
+		t.Errorf("edited note not re-rendered:\n%s", html)
+	}
+	if s := mgr.RenderCacheStats(); s.Entries != 2 || s.Misses != 3 {
+		t.Errorf("after edit: %+v", s)
+	}
+
+	// Retitling one note changes where the other's unchanged wiki link
+	// points.
+	if err := mgr.UpdateNote(1, "Budget", "Q1 goals"); err != nil {
+		t.Fatal(err)
+	}
+	if html := render(); strings.Contains(html, "wiki-link-missing") {
+		t.Errorf("link to retitled note still unresolved:\n%s", html)
+	}
+}
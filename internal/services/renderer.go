@@ -3,31 +3,51 @@ package services
 import (
 	"bytes"
 	"fmt"
+	"html/template"
 	"regexp"
+	"net/url"
+	"sort"
 	"strings"
 
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
 	"github.com/yuin/goldmark"
+	gast "github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
 )
 
 // MarkdownRenderer handles markdown to HTML conversion
 type MarkdownRenderer struct {
 	md goldmark.Markdown
+	// resolveLink maps a [[wiki link]] target to a note index; see
+	// SetLinkResolver. Nil renders every wiki link as unresolved.
+	resolveLink func(target string) (int, bool)
+	// preview returns the preview card of a bare URL, false while it is
+	// being fetched; see SetPreviewResolver. Nil renders no cards.
+	preview func(url string) (*models.LinkPreview, bool)
 }
 
 // NewMarkdownRenderer creates a new markdown renderer with extensions
 func NewMarkdownRenderer() *MarkdownRenderer {
 	md := goldmark.New(
 		goldmark.WithExtensions(
-			extension.GFM,        // GitHub Flavored Markdown
-			extension.Table,      // Tables
-			extension.Strikethrough, // Strikethrough text
-			extension.TaskList,   // Task lists (checkboxes)
+			// GitHub Flavored Markdown, with table alignment as inline
+			// styles so the stylesheet's th/td rules don't override it
+			extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignStyle)),
+			extension.Strikethrough,
+			extension.Linkify, // Bare URLs and www. links
+			extension.TaskList,
+			extension.DefinitionList, // Term / ": definition" lists
+			extension.NewFootnote(    // [^1] references and definitions
+				extension.WithFootnoteIDPrefixFunction(footnoteIDPrefix),
+			),
 		),
 		goldmark.WithParserOptions(
-			parser.WithAutoHeadingID(), // Auto-generate heading IDs
+			// Heading IDs from the visible heading text
+			parser.WithASTTransformers(util.Prioritized(headingIDs{}, 100)),
 		),
 		goldmark.WithRendererOptions(
 			html.WithHardWraps(),   // Convert line breaks to <br>
@@ -39,13 +59,31 @@ func NewMarkdownRenderer() *MarkdownRenderer {
 	return &MarkdownRenderer{md: md}
 }
 
+// SetLinkResolver sets how [[Note Title]] links find their note. The
+// resolver runs during rendering, so it must not take locks the caller of
+// RenderToHTML already holds.
+func (r *MarkdownRenderer) SetLinkResolver(resolve func(target string) (int, bool)) {
+	r.resolveLink = resolve
+}
+
+// SetPreviewResolver sets where preview cards for bare URLs come from;
+// nil turns them off. Like the link resolver it runs during rendering.
+func (r *MarkdownRenderer) SetPreviewResolver(preview func(url string) (*models.LinkPreview, bool)) {
+	r.preview = preview
+}
+
 // RenderToHTML converts markdown content to HTML
 func (r *MarkdownRenderer) RenderToHTML(content string) (string, error) {
-	// Pre-process content for custom features
-	content = r.preprocessContent(content)
-	
+	return r.render(content, "")
+}
+
+// render converts markdown content to HTML, prefixing the ids of its
+// headings and footnotes with idPrefix.
+func (r *MarkdownRenderer) render(content, idPrefix string) (string, error) {
+	metaHTML, source := r.prepare(content)
+	doc := r.parse(source, idPrefix)
 	var buf bytes.Buffer
-	if err := r.md.Convert([]byte(content), &buf); err != nil {
+	if err := r.md.Renderer().Render(&buf, source, doc); err != nil {
 		return "", fmt.Errorf("failed to render markdown: %w", err)
 	}
 
@@ -54,11 +92,56 @@ func (r *MarkdownRenderer) RenderToHTML(content string) (string, error) {
 	// Post-process HTML for custom features
 	html = r.postprocessHTML(html)
 	
-	return html, nil
+	return metaHTML + html, nil
+}
+
+// prepare splits off content's frontmatter, rendered as a property list
+// (as markdown it would turn into a rule and a heading), and applies the
+// custom markdown features to the rest.
+func (r *MarkdownRenderer) prepare(content string) (metaHTML string, source []byte) {
+	if meta, end, ok := models.ParseFrontmatter(content); ok {
+		metaHTML = renderFrontmatter(meta)
+		content = content[end:]
+	}
+	return metaHTML, []byte(r.preprocessContent(content))
+}
+
+// parse parses prepared source, giving headings and footnotes ids that
+// start with idPrefix.
+func (r *MarkdownRenderer) parse(source []byte, idPrefix string) gast.Node {
+	pc := parser.NewContext()
+	pc.Set(idPrefixKey, idPrefix)
+	return r.md.Parser().Parse(text.NewReader(source), parser.WithContext(pc))
+}
+
+// renderFrontmatter renders note metadata as a definition list, keys
+// sorted.
+func renderFrontmatter(meta map[string]string) string {
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(`<dl class="note-metadata">`)
+	for _, k := range keys {
+		fmt.Fprintf(&b, `<dt>%s</dt><dd>%s</dd>`, template.HTMLEscapeString(k), template.HTMLEscapeString(meta[k]))
+	}
+	b.WriteString(`</dl>`)
+	return b.String()
 }
 
 // preprocessContent handles custom markdown features before goldmark processing
 func (r *MarkdownRenderer) preprocessContent(content string) string {
+	// Turn #tags and @mentions into filter links before anything else rewrites
+	// the text, while code spans are still where findCodeRanges expects them.
+	content = models.ReplaceWikiLinks(content, r.renderWikiLink)
+	content = models.ReplaceTagTokens(content, renderTag)
+	content = models.ReplaceMentionTokens(content, renderMention)
+	if r.preview != nil {
+		content = r.insertLinkPreviews(content)
+	}
+
 	// Handle math expressions (MathJax format)
 	// Protect inline math $...$ from being processed as markdown
 	content = r.protectMathExpressions(content)
@@ -69,6 +152,56 @@ func (r *MarkdownRenderer) preprocessContent(content string) string {
 	return content
 }
 
+// renderTag renders a tag as one link per path segment, so in
+// #project/clientA each of "project" and "project/clientA" can be clicked
+// to filter the notes. The hrefs are query-only, so they stay on the page
+// they are shown on, under any base path or project prefix.
+func renderTag(tag string) string {
+	var b strings.Builder
+	b.WriteString(`<span class="tag">`)
+	segments := strings.Split(tag, "/")
+	for i, seg := range segments {
+		label := seg
+		if i == 0 {
+			label = "#" + seg
+		} else {
+			b.WriteString(`<span class="tag-sep">/</span>`)
+		}
+		fmt.Fprintf(&b, `<a class="tag-link" href="?tag=%s" data-tag="%s">%s</a>`,
+			url.QueryEscape(strings.Join(segments[:i+1], "/")), strings.Join(segments[:i+1], "/"), label)
+	}
+	b.WriteString(`</span>`)
+	return b.String()
+}
+
+// renderMention renders @name as a link to the notes mentioning them,
+// query-only like renderTag's.
+func renderMention(name string) string {
+	return fmt.Sprintf(`<a class="mention-link" href="?mention=%s" data-mention="%s">@%s</a>`,
+		url.QueryEscape(name), name, name)
+}
+
+// renderWikiLink renders [[target|label]] as an anchor to the note's
+// element on the page, or as a marked-up span when no note has that
+// title. "#" and "@" are escaped so the tag and mention passes that follow
+// leave labels alone.
+func (r *MarkdownRenderer) renderWikiLink(target, label string) string {
+	label = escapeTokens(template.HTMLEscapeString(label))
+	if r.resolveLink != nil {
+		if index, ok := r.resolveLink(target); ok {
+			return fmt.Sprintf(`<a class="wiki-link" href="#note-%d" data-note-index="%d" onclick="event.stopPropagation();">%s</a>`,
+				index, index, label)
+		}
+	}
+	return fmt.Sprintf(`<span class="wiki-link wiki-link-missing" title="No note titled &quot;%s&quot;">%s</span>`,
+		escapeTokens(template.HTMLEscapeString(target)), label)
+}
+
+// escapeTokens hides "#" and "@" from the tag and mention passes.
+func escapeTokens(s string) string {
+	return strings.NewReplacer("#", "&#35;", "@", "&#64;").Replace(s)
+}
+
 // protectMathExpressions protects math expressions from markdown processing
 func (r *MarkdownRenderer) protectMathExpressions(content string) string {
 	// Protect display math blocks $$...$$ 
@@ -95,7 +228,7 @@ func (r *MarkdownRenderer) preprocessCheckboxes(content string) string {
 	
 	for i, line := range lines {
 		// Match checkbox patterns
-		checkboxPattern := regexp.MustCompile(`^(\s*-\s*)\[([xX ])\](.*)`)
+		checkboxPattern := regexp.MustCompile(`^(\s*-\s*)\[([xX /])\](.*)`)
 		if matches := checkboxPattern.FindStringSubmatch(line); len(matches) == 4 {
 			prefix := matches[1]
 			status := matches[2]
@@ -105,6 +238,9 @@ func (r *MarkdownRenderer) preprocessCheckboxes(content string) string {
 			checkedAttr := ""
 			if checked {
 				checkedAttr = " checked"
+			} else if status == "/" {
+				// In progress; the page shows it as indeterminate.
+				checkedAttr = ` data-task-state="doing"`
 			}
 			
 			// Replace with custom HTML that goldmark will pass through
@@ -178,7 +314,7 @@ func (r *MarkdownRenderer) fixCheckboxes(html string) string {
 
 // RenderNoteHTML renders a complete note with proper styling and structure
 func (r *MarkdownRenderer) RenderNoteHTML(content, timestamp, title string, noteIndex int) (string, error) {
-	renderedContent, err := r.RenderToHTML(content)
+	renderedContent, err := r.render(content, noteIDPrefix(noteIndex))
 	if err != nil {
 		return "", err
 	}
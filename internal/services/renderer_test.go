@@ -1,10 +1,15 @@
 package services
 
 import (
+	"flag"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
 
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/render")
+
 // These benchmarks check the Goal 3 reliability target:
 //   "render a note <100ms"  — see docs/TODO.md → "Long-term Direction" goal 3.
 //
@@ -129,3 +134,72 @@ func TestRender_TypicalUnderBudget(t *testing.T) {
 	}
 	t.Logf("typical-note render = %d ns/op (~%.2f ms)", nsPerOp, float64(nsPerOp)/1e6)
 }
+
+func TestRender_NestedTagLinks(t *testing.T) {
+	html, err := NewMarkdownRenderer().RenderToHTML("Kickoff #client/acme\n`#not-a-tag`")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<a class="tag-link" href="?tag=client" data-tag="client">#client</a>`,
+		`<a class="tag-link" href="?tag=client%2Facme" data-tag="client/acme">acme</a>`,
+		`<code>#not-a-tag</code>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing %s in:\n%s", want, html)
+		}
+	}
+}
+
+// TestRender_Golden renders each testdata/render/*.md and compares it with
+// the .html file beside it. Run with -update after an intended change.
+func TestRender_Golden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "render", "*.md"))
+	if err != nil || len(inputs) == 0 {
+		t.Fatalf("no golden inputs: %v", err)
+	}
+	r := NewMarkdownRenderer()
+	for _, in := range inputs {
+		name := strings.TrimSuffix(filepath.Base(in), ".md")
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.RenderToHTML(string(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden := strings.TrimSuffix(in, ".md") + ".html"
+			if *updateGolden {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("%s differs from %s:\n%s", in, golden, got)
+			}
+		})
+	}
+}
+
+func TestRender_FootnoteIDsPerNote(t *testing.T) {
+	r := NewMarkdownRenderer()
+	const note = "Claim[^1].\n\n[^1]: Source."
+	first, err := r.RenderNoteHTML(note, "t", "A", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := r.RenderNoteHTML(note, "t", "B", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(first, `id="note-0-fn:1"`) || !strings.Contains(second, `href="#note-1-fn:1"`) {
+		t.Errorf("footnote ids not per note:\n%s\n%s", first, second)
+	}
+}
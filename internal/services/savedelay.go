@@ -0,0 +1,46 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// SetSaveDelay makes changes wait in memory until d has passed without
+// another one before they are written, so a burst of edits to a large
+// notes.md is one write. Zero, the default, writes every change at once.
+// Changes still waiting are written by Close, exports and RawNotes; when
+// notes.md changes on disk meanwhile, they are merged into it (see
+// mergeFromDisk).
+func (nm *NoteManager) SetSaveDelay(d time.Duration) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.saveDelay = d
+	if d <= 0 {
+		if err := nm.write(); err != nil {
+			log.Printf("Warning: failed to save %s: %v", nm.storage.BasePath, err)
+		}
+	}
+}
+
+// writeLater (re)starts the timer that writes the changes once the save
+// delay has passed. Callers hold nm.mu.
+func (nm *NoteManager) writeLater() {
+	if nm.saveTimer != nil {
+		nm.saveTimer.Reset(nm.saveDelay)
+		return
+	}
+	nm.saveTimer = time.AfterFunc(nm.saveDelay, func() {
+		nm.mu.Lock()
+		defer nm.mu.Unlock()
+		nm.saveTimer = nil
+		err := nm.write()
+		switch {
+		case errors.Is(err, ErrNotesChangedOnDisk):
+			log.Printf("Warning: %s: %v", nm.storage.BasePath, err)
+		case err != nil:
+			// needsSave stays set: the next change, or Close, tries again.
+			log.Printf("Warning: failed to save %s: %v", nm.storage.BasePath, err)
+		}
+	})
+}
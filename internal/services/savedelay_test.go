@@ -0,0 +1,132 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveDelay_BatchesWrites(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Plan", "- [ ] one\n- [ ] two"); err != nil {
+		t.Fatal(err)
+	}
+	notesPath := filepath.Join(dir, "notes.md")
+	before, _ := os.ReadFile(notesPath)
+
+	mgr.SetSaveDelay(time.Hour)
+	for _, index := range []int{0, 1} {
+		if err := mgr.UpdateTask(index, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if after, _ := os.ReadFile(notesPath); string(after) != string(before) {
+		t.Errorf("notes.md written during the save delay: %q", after)
+	}
+	if tasks := mgr.GetAllTasks(); !tasks[0].Checked || !tasks[1].Checked {
+		t.Errorf("tasks = %+v", tasks)
+	}
+
+	// Reading the file writes what is waiting.
+	raw, err := mgr.RawNotes()
+	if err != nil || strings.Count(string(raw), "- [x]") != 2 {
+		t.Errorf("RawNotes = %q, %v", raw, err)
+	}
+}
+
+func TestSaveDelay_WritesWhenQuiet(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr.SetSaveDelay(20 * time.Millisecond)
+	if err := mgr.AddNote("Plan", "- [ ] one"); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(filepath.Join(dir, "notes.md"))
+		if strings.Contains(string(data), "- [ ] one") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("delayed save never written")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if mgr.HasChanges() {
+		t.Error("HasChanges after the delayed write")
+	}
+}
+
+func TestSaveDelay_MergesChangesWhenFileChanges(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Plan", "- [ ] one"); err != nil {
+		t.Fatal(err)
+	}
+	mgr.SetSaveDelay(time.Hour)
+	if err := mgr.AddNote("Pending", "not written yet"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another program appends to notes.md and the watcher reloads it
+	// before the delayed save.
+	editOutside(t, dir, func(s string) string { return s + "\n- [ ] two, from outside\n" })
+	if reloaded, err := mgr.ReloadIfChanged(); err != nil || !reloaded {
+		t.Fatalf("ReloadIfChanged = %v, %v", reloaded, err)
+	}
+	raw, err := mgr.RawNotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{" - Pending\n", "not written yet", "two, from outside"} {
+		if !strings.Contains(string(raw), want) {
+			t.Errorf("notes.md lacks %q: %q", want, raw)
+		}
+	}
+}
+
+func TestSaveDelay_SetsAsideConflictingChanges(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Plan", "- [ ] one"); err != nil {
+		t.Fatal(err)
+	}
+	mgr.SetSaveDelay(time.Hour)
+	if err := mgr.UpdateTask(0, true); err != nil {
+		t.Fatal(err)
+	}
+
+	editOutside(t, dir, func(s string) string { return strings.Replace(s, "- [ ] one", "- [ ] one, reworded", 1) })
+	if _, err := mgr.ReloadIfChanged(); err != nil {
+		t.Fatal(err)
+	}
+	if mgr.HasChanges() {
+		t.Error("HasChanges after the changes were set aside")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "notes.md")); !strings.Contains(string(data), "- [ ] one, reworded") {
+		t.Errorf("notes.md = %q", data)
+	}
+	copies, _ := filepath.Glob(filepath.Join(dir, "notes.conflict-*.md"))
+	if len(copies) != 1 {
+		t.Fatalf("conflict copies = %v", copies)
+	}
+	if data, _ := os.ReadFile(copies[0]); !strings.Contains(string(data), "- [x] one") {
+		t.Errorf("conflict copy = %q", data)
+	}
+}
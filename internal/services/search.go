@@ -0,0 +1,255 @@
+package services
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Field weights: a hit in the title says more about a note than a hit in
+// its body. Task lines are part of the body too, so a task hit scores
+// contentWeight+taskWeight.
+const (
+	titleWeight   = 3
+	taskWeight    = 1
+	contentWeight = 1
+)
+
+// snippetRadius is how much context, in bytes, a snippet shows on either
+// side of the first hit.
+const snippetRadius = 80
+
+var searchTokenRE = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+
+// searchDoc is the searchable copy of one note. The strings are copied out
+// under the NoteManager lock so the index never reads live notes.
+type searchDoc struct {
+	index     int
+	title     string
+	content   string
+	timestamp time.Time
+}
+
+// posting records how often a token occurs in one note, weighted by field.
+type posting struct {
+	doc   int // position in SearchService.docs
+	score int
+}
+
+// SearchResult is one matching note.
+type SearchResult struct {
+	Index     int       `json:"index"` // note index for /api/notes/:index
+	Title     string    `json:"title"`
+	Timestamp time.Time `json:"timestamp"`
+	// Snippet is HTML: escaped text around the first hit with every
+	// matching word wrapped in <mark>.
+	Snippet string `json:"snippet"`
+	Score   int    `json:"score"`
+}
+
+// SearchResults is the payload behind GET /api/search.
+type SearchResults struct {
+	Query   string         `json:"query"`
+	Total   int            `json:"total"`
+	Results []SearchResult `json:"results"`
+}
+
+// SearchService answers full-text queries over this folder's notes from an
+// inverted index of note titles, bodies and task lines. The index is
+// rebuilt lazily, on the first query after the notes change.
+type SearchService struct {
+	noteManager *NoteManager
+	mu          sync.Mutex
+	generation  uint64
+	built       bool
+	docs        []searchDoc
+	postings    map[string][]posting
+	tokens      []string // sorted keys of postings, for prefix lookups
+}
+
+// NewSearchService creates a search service over noteManager's notes.
+func NewSearchService(noteManager *NoteManager) *SearchService {
+	return &SearchService{noteManager: noteManager}
+}
+
+// searchDocs snapshots the notes for indexing.
+func (nm *NoteManager) searchDocs() ([]searchDoc, uint64) {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+	docs := make([]searchDoc, len(nm.notes))
+	for i, note := range nm.notes {
+		docs[i] = searchDoc{index: i, title: note.Title, content: note.Content, timestamp: note.Timestamp}
+	}
+	return docs, nm.generation
+}
+
+// taskLines returns the task lines of content, for the task weight.
+func taskLines(content string) []string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if t := strings.TrimSpace(line); strings.HasPrefix(t, "- [") || strings.HasPrefix(t, "* [") {
+			lines = append(lines, t)
+		}
+	}
+	return lines
+}
+
+// refresh rebuilds the index if the notes changed since it was built.
+// Callers hold s.mu.
+func (s *SearchService) refresh() {
+	if s.built && s.noteManager.Generation() == s.generation {
+		return
+	}
+	docs, generation := s.noteManager.searchDocs()
+	postings := make(map[string][]posting)
+	for d, doc := range docs {
+		counts := make(map[string]int)
+		add := func(text string, weight int) {
+			for _, tok := range searchTokenRE.FindAllString(strings.ToLower(text), -1) {
+				counts[tok] += weight
+			}
+		}
+		add(doc.title, titleWeight)
+		add(doc.content, contentWeight)
+		for _, line := range taskLines(doc.content) {
+			add(line, taskWeight)
+		}
+		for tok, score := range counts {
+			postings[tok] = append(postings[tok], posting{doc: d, score: score})
+		}
+	}
+	tokens := make([]string, 0, len(postings))
+	for tok := range postings {
+		tokens = append(tokens, tok)
+	}
+	sort.Strings(tokens)
+
+	s.docs, s.postings, s.tokens = docs, postings, tokens
+	s.generation, s.built = generation, true
+}
+
+// Search returns notes containing every word of query, best match first,
+// up to limit results (limit <= 0 means no limit). Each query word also
+// matches longer words it is a prefix of, so "deploy" finds "deployment"
+// and results appear while the user is still typing.
+func (s *SearchService) Search(query string, limit int) *SearchResults {
+	terms := searchTokenRE.FindAllString(strings.ToLower(query), -1)
+	res := &SearchResults{Query: query, Results: []SearchResult{}}
+	if len(terms) == 0 {
+		return res
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh()
+
+	var scores map[int]int
+	for _, term := range terms {
+		termScores := make(map[int]int)
+		for i := sort.SearchStrings(s.tokens, term); i < len(s.tokens) && strings.HasPrefix(s.tokens[i], term); i++ {
+			for _, p := range s.postings[s.tokens[i]] {
+				termScores[p.doc] += p.score
+			}
+		}
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for d := range scores {
+			if ts, ok := termScores[d]; ok {
+				scores[d] += ts
+			} else {
+				delete(scores, d)
+			}
+		}
+	}
+
+	for d, score := range scores {
+		doc := s.docs[d]
+		res.Results = append(res.Results, SearchResult{
+			Index:     doc.index,
+			Title:     doc.title,
+			Timestamp: doc.timestamp,
+			Snippet:   highlightSnippet(doc, terms),
+			Score:     score,
+		})
+	}
+	sort.Slice(res.Results, func(i, j int) bool {
+		a, b := res.Results[i], res.Results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Timestamp.After(b.Timestamp)
+	})
+	res.Total = len(res.Results)
+	if limit > 0 && len(res.Results) > limit {
+		res.Results = res.Results[:limit]
+	}
+	return res
+}
+
+// matchesTerm reports whether the lowercased word starts with any term.
+func matchesTerm(word string, terms []string) bool {
+	for _, t := range terms {
+		if strings.HasPrefix(word, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// highlightSnippet cuts a window of the note body around its first hit (or
+// the start of the body when only the title matched) and marks every hit
+// in it.
+func highlightSnippet(doc searchDoc, terms []string) string {
+	content := doc.content
+	words := searchTokenRE.FindAllStringIndex(content, -1)
+	first := -1
+	for _, w := range words {
+		if matchesTerm(strings.ToLower(content[w[0]:w[1]]), terms) {
+			first = w[0]
+			break
+		}
+	}
+
+	start, end := 0, len(content)
+	if first >= 0 {
+		start = max(first-snippetRadius, 0)
+		end = min(first+snippetRadius, len(content))
+	} else {
+		end = min(2*snippetRadius, len(content))
+	}
+	// Keep the window on rune boundaries.
+	for start > 0 && !utf8.RuneStart(content[start]) {
+		start--
+	}
+	for end < len(content) && !utf8.RuneStart(content[end]) {
+		end++
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	last := start
+	for _, w := range words {
+		if w[0] < start || w[1] > end {
+			continue
+		}
+		if !matchesTerm(strings.ToLower(content[w[0]:w[1]]), terms) {
+			continue
+		}
+		b.WriteString(html.EscapeString(content[last:w[0]]))
+		b.WriteString("<mark>" + html.EscapeString(content[w[0]:w[1]]) + "</mark>")
+		last = w[1]
+	}
+	b.WriteString(html.EscapeString(content[last:end]))
+	if end < len(content) {
+		b.WriteString("…")
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchService(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range [][2]string{
+		{"Deploy checklist", "Run the migrations first.\n- [ ] deploy to staging"},
+		{"Standup", "Talked about the deployment window and <lunch> plans."},
+		{"Groceries", "- [ ] milk\n- [ ] bread"},
+	} {
+		if err := mgr.AddNote(n[0], n[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := NewSearchService(mgr)
+
+	res := s.Search("deploy", 0)
+	if res.Total != 2 {
+		t.Fatalf("deploy: %d results, want 2: %+v", res.Total, res.Results)
+	}
+	// The title and task hits outrank a passing mention.
+	if res.Results[0].Title != "Deploy checklist" {
+		t.Errorf("top result = %q", res.Results[0].Title)
+	}
+	if got := res.Results[1].Snippet; !strings.Contains(got, "the <mark>deployment</mark> window") || !strings.Contains(got, "&lt;lunch&gt;") {
+		t.Errorf("snippet = %q", got)
+	}
+	if idx := res.Results[1].Index; idx != 1 {
+		t.Errorf("standup index = %d, want 1", idx)
+	}
+
+	// Every word must match.
+	if res := s.Search("deploy window", 0); res.Total != 1 || res.Results[0].Title != "Standup" {
+		t.Errorf("deploy window = %+v", res.Results)
+	}
+	if res := s.Search("deploy milk", 0); res.Total != 0 {
+		t.Errorf("deploy milk = %+v", res.Results)
+	}
+
+	// The index follows edits.
+	if err := mgr.UpdateNote(0, "Groceries", "- [ ] oat milk\n- [ ] deploy the shopping list"); err != nil {
+		t.Fatal(err)
+	}
+	if res := s.Search("deploy", 1); res.Total != 3 || len(res.Results) != 1 {
+		t.Errorf("after edit: total %d, %d results", res.Total, len(res.Results))
+	}
+}
+
+func TestTagIndexFollowsEdits(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("a", "alpha #work/site"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("b", "beta #home"); err != nil {
+		t.Fatal(err)
+	}
+	count := func(tag string) int {
+		mgr.mu.RLock()
+		defer mgr.mu.RUnlock()
+		return len(mgr.notesWithTag(tag))
+	}
+	if count("work") != 1 || count("home") != 1 {
+		t.Fatalf("work=%d home=%d", count("work"), count("home"))
+	}
+	note, _ := mgr.GetNote(0)
+	if len(note.Tags) != 1 || note.Tags[0] != "home" {
+		t.Errorf("note tags = %v", note.Tags)
+	}
+
+	if err := mgr.UpdateNote(0, "b", "beta #work"); err != nil {
+		t.Fatal(err)
+	}
+	if count("work") != 2 || count("home") != 0 {
+		t.Errorf("after edit: work=%d home=%d", count("work"), count("home"))
+	}
+	if _, err := mgr.RenameTag("work", "job", false); err != nil {
+		t.Fatal(err)
+	}
+	if count("work") != 0 || count("job/site") != 1 {
+		t.Errorf("after rename: work=%d job/site=%d", count("work"), count("job/site"))
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// slackMaxSkew is how far a request's timestamp may be from now, so that
+// a recorded request can't be replayed later.
+const slackMaxSkew = 5 * time.Minute
+
+// ErrSlackSignature is returned by Verify for a request Slack didn't sign.
+var ErrSlackSignature = errors.New("invalid Slack request signature")
+
+// slackHelp answers help and commands given nothing to do.
+const slackHelp = "`/note TEXT` adds a note; when it has several lines, the first is the title.\n" +
+	"`/task TEXT` adds a task, e.g. `/task Buy cake #errands !2 @due(sat) >Shopping`\n" +
+	"`/tasks` lists the open tasks of every folder.\n" +
+	"A command of another name takes `note`, `task` or `tasks` as its first word: `/noteflow tasks`."
+
+var (
+	// slackUnescaper undoes the escaping of the text Slack sends.
+	slackUnescaper = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+	// slackEscaper escapes the characters Slack's mrkdwn reads as markup.
+	slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
+// SlackService answers the slash commands of a Slack app: /note adds a
+// note to the folder, /task a quick-added task, and /tasks lists the
+// open tasks of every registered folder.
+type SlackService struct {
+	noteManager *NoteManager
+	tasks       func() ([]models.GlobalTask, error)
+	secret      string
+}
+
+// NewSlackService creates the service for the folder of noteManager.
+func NewSlackService(noteManager *NoteManager, registry *TaskRegistryService, cfg models.SlackConfig) *SlackService {
+	return &SlackService{
+		noteManager: noteManager,
+		tasks: func() ([]models.GlobalTask, error) {
+			global, err := registry.GetGlobalTasks()
+			if err != nil {
+				return nil, err
+			}
+			return global.Tasks, nil
+		},
+		secret: cfg.ResolvedSigningSecret(),
+	}
+}
+
+// Enabled reports whether a signing secret is configured; without one no
+// command is answered.
+func (s *SlackService) Enabled() bool {
+	return s.secret != ""
+}
+
+// Verify checks the signature Slack sends with a request, in its
+// X-Slack-Signature header: "v0=" and the hex HMAC-SHA256, keyed with the
+// signing secret, of "v0:" + X-Slack-Request-Timestamp + ":" + body.
+func (s *SlackService) Verify(timestamp, signature string, body []byte, now time.Time) error {
+	if !s.Enabled() {
+		return ErrSlackSignature
+	}
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrSlackSignature
+	}
+	if skew := now.Sub(time.Unix(sec, 0)); skew > slackMaxSkew || skew < -slackMaxSkew {
+		return fmt.Errorf("%w: timestamp too far from now", ErrSlackSignature)
+	}
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(signature), []byte(want)) {
+		return ErrSlackSignature
+	}
+	return nil
+}
+
+// Command answers command, such as "/note", given text after it, and
+// returns the reply in Slack's mrkdwn.
+func (s *SlackService) Command(command, text string, now time.Time) string {
+	name := strings.ToLower(strings.TrimPrefix(command, "/"))
+	text = strings.TrimSpace(slackUnescaper.Replace(text))
+	switch name {
+	case "note", "task", "tasks":
+	default:
+		// A command named otherwise, like /noteflow, takes the action as
+		// its first word, defaulting to a note.
+		first, rest := text, ""
+		if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+			first, rest = text[:i], strings.TrimSpace(text[i:])
+		}
+		switch strings.ToLower(first) {
+		case "note", "task", "tasks", "help":
+			name, text = strings.ToLower(first), rest
+		default:
+			name = "note"
+		}
+	}
+
+	switch name {
+	case "tasks":
+		tasks, err := s.tasks()
+		if err != nil {
+			return "Couldn't read the tasks: " + slackEscaper.Replace(err.Error())
+		}
+		return slackEscaper.Replace(BuildDigest(tasks, now, models.DigestConfig{}.DueSoonWindow()).Text())
+	case "task":
+		if text == "" {
+			return slackHelp
+		}
+		added, err := s.noteManager.QuickAddTask(text)
+		if err != nil {
+			return "Couldn't add the task: " + slackEscaper.Replace(err.Error())
+		}
+		return slackEscaper.Replace(fmt.Sprintf("Added to %s: %s", added.Note, strings.TrimPrefix(added.Line, "- ")))
+	case "note":
+		if text == "" {
+			return slackHelp
+		}
+		title, err := s.noteManager.CaptureNote(text)
+		if err != nil {
+			return "Couldn't add the note: " + slackEscaper.Replace(err.Error())
+		}
+		if title == "" {
+			return "Note added."
+		}
+		return "Note added: " + slackEscaper.Replace(title)
+	}
+	return slackHelp
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestSlackVerify(t *testing.T) {
+	s := &SlackService{secret: "8f742231b10e8888abcd99yyyzzz85a5"}
+	now := time.Unix(1531420618, 0)
+	body := []byte("token=xyzz0WbapA4vBCDEFasx0q6G&command=%2Fnote&text=hello")
+	sign := func(ts string) string {
+		mac := hmac.New(sha256.New, []byte(s.secret))
+		mac.Write([]byte("v0:" + ts + ":" + string(body)))
+		return "v0=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	if err := s.Verify(ts, sign(ts), body, now); err != nil {
+		t.Errorf("signed request: %v", err)
+	}
+	if err := s.Verify(ts, sign(ts), append(body, '!'), now); !errors.Is(err, ErrSlackSignature) {
+		t.Errorf("altered body: %v", err)
+	}
+	if err := s.Verify(ts, sign(ts), body, now.Add(10*time.Minute)); !errors.Is(err, ErrSlackSignature) {
+		t.Errorf("replayed request: %v", err)
+	}
+	if err := (&SlackService{}).Verify(ts, sign(ts), body, now); !errors.Is(err, ErrSlackSignature) {
+		t.Errorf("no secret: %v", err)
+	}
+}
+
+func TestSlackCommand(t *testing.T) {
+	nm := newNoteManagerWithNote(t, "Shopping", "## List")
+	s := &SlackService{
+		noteManager: nm,
+		secret:      "x",
+		tasks: func() ([]models.GlobalTask, error) {
+			return []models.GlobalTask{{ID: 1, Content: "- [ ] file taxes @2026-10-01", FolderPath: "/home/me/admin"}}, nil
+		},
+	}
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local)
+
+	if reply := s.Command("/note", "Trip ideas\nLisbon &amp; Porto", now); reply != "Note added: Trip ideas" {
+		t.Errorf("/note reply = %q", reply)
+	}
+	if reply := s.Command("/task", "Buy cake !2 &gt;Shopping/List", now); reply != "Added to Shopping: [ ] Buy cake !p2" {
+		t.Errorf("/task reply = %q", reply)
+	}
+	if reply := s.Command("/tasks", "", now); !strings.HasPrefix(reply, "Overdue (1)\n  - file taxes (due Thu Oct 1) [admin]") {
+		t.Errorf("/tasks reply = %q", reply)
+	}
+	if reply := s.Command("/noteflow", "tasks", now); !strings.HasPrefix(reply, "Overdue (1)") {
+		t.Errorf("/noteflow tasks reply = %q", reply)
+	}
+	if reply := s.Command("/noteflow", "call the bank", now); reply != "Note added." {
+		t.Errorf("/noteflow note reply = %q", reply)
+	}
+	if reply := s.Command("/note", " ", now); reply != slackHelp {
+		t.Errorf("empty /note reply = %q", reply)
+	}
+
+	byTitle := map[string]*models.Note{}
+	for _, note := range nm.GetAllNotes() {
+		byTitle[note.Title] = note
+	}
+	if n := byTitle["Trip ideas"]; n == nil || n.Content != "Lisbon & Porto" {
+		t.Errorf("trip note = %+v", n)
+	}
+	if n := byTitle[""]; n == nil || n.Content != "call the bank" {
+		t.Errorf("untitled note = %+v", n)
+	}
+	if n := byTitle["Shopping"]; n == nil || !strings.Contains(n.Content, "## List\n- [ ] Buy cake !p2") {
+		t.Errorf("shopping note = %+v", n)
+	}
+}
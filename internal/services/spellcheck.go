@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/spell"
+)
+
+// SpellcheckService checks text with the folder's dictionary and custom
+// word list. The word list lives in .noteflow.json so it travels with the
+// project like the rest of the folder settings.
+type SpellcheckService struct {
+	basePath string
+	mu       sync.RWMutex
+	language string
+	words    []string
+	checker  *spell.Checker
+}
+
+// ErrInvalidWord is returned by AddWord for empty or multi-word input.
+var ErrInvalidWord = errors.New("a word must be non-empty and contain no spaces")
+
+// SpellcheckResult is the response to a check.
+type SpellcheckResult struct {
+	Language     string              `json:"language"`
+	Misspellings []spell.Misspelling `json:"misspellings"`
+}
+
+// NewSpellcheckService creates the service for basePath. An unknown
+// language is logged and replaced with the default rather than failing
+// startup.
+func NewSpellcheckService(basePath string, folderCfg *models.FolderConfig) *SpellcheckService {
+	s := &SpellcheckService{basePath: basePath, language: spell.DefaultLanguage}
+	if cfg := folderCfg.Spellcheck; cfg != nil {
+		if cfg.Language != "" {
+			s.language = cfg.Language
+		}
+		s.words = append(s.words, cfg.Words...)
+	}
+	checker, err := spell.New(s.language, s.words)
+	if err != nil {
+		log.Printf("Warning: spellcheck: %v; using %q", err, spell.DefaultLanguage)
+		s.language = spell.DefaultLanguage
+		checker, _ = spell.New(s.language, s.words)
+	}
+	s.checker = checker
+	return s
+}
+
+// Check returns the misspelled words in text.
+func (s *SpellcheckService) Check(text string) *SpellcheckResult {
+	s.mu.RLock()
+	checker, language := s.checker, s.language
+	s.mu.RUnlock()
+
+	misspellings := checker.Check(text)
+	if misspellings == nil {
+		misspellings = []spell.Misspelling{}
+	}
+	return &SpellcheckResult{Language: language, Misspellings: misspellings}
+}
+
+// Words returns the folder's custom word list.
+func (s *SpellcheckService) Words() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string{}, s.words...)
+}
+
+// AddWord adds word to the folder's custom list and saves it to
+// .noteflow.json. Adding a word that is already known is a no-op. The file
+// is re-read first so settings edited by hand since startup are kept.
+func (s *SpellcheckService) AddWord(word string) error {
+	word = strings.TrimSpace(word)
+	if word == "" || strings.IndexFunc(word, unicode.IsSpace) >= 0 {
+		return ErrInvalidWord
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range s.words {
+		if strings.EqualFold(w, word) {
+			return nil
+		}
+	}
+
+	cfg, err := models.LoadFolderConfig(s.basePath)
+	if err != nil {
+		return err
+	}
+	if cfg.Spellcheck == nil {
+		cfg.Spellcheck = &models.SpellcheckFolderConfig{}
+	}
+	cfg.Spellcheck.Words = append(cfg.Spellcheck.Words, word)
+	if err := models.SaveFolderConfig(s.basePath, cfg); err != nil {
+		return fmt.Errorf("save %s: %w", models.FolderConfigFile, err)
+	}
+
+	words := append(append([]string{}, s.words...), word)
+	checker, err := spell.New(s.language, words)
+	if err != nil {
+		return err
+	}
+	s.words, s.checker = words, checker
+	return nil
+}
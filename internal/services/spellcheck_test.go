@@ -0,0 +1,55 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestSpellcheckAddWordPersists(t *testing.T) {
+	dir := t.TempDir()
+	if err := models.SaveFolderConfig(dir, &models.FolderConfig{
+		Todoist: &models.TodoistFolderConfig{ProjectID: "42"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ := models.LoadFolderConfig(dir)
+	s := NewSpellcheckService(dir, cfg)
+
+	if got := s.Check("ask Grafana about kubectl"); len(got.Misspellings) != 1 || got.Misspellings[0].Word != "kubectl" {
+		t.Fatalf("Check = %+v, want only kubectl flagged", got.Misspellings)
+	}
+	if err := s.AddWord("kubectl"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddWord("Kubectl"); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Check("ask Grafana about kubectl"); len(got.Misspellings) != 0 {
+		t.Errorf("Check after AddWord = %+v", got.Misspellings)
+	}
+
+	saved, err := models.LoadFolderConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Spellcheck == nil || !reflect.DeepEqual(saved.Spellcheck.Words, []string{"kubectl"}) {
+		t.Errorf("saved spellcheck config = %+v", saved.Spellcheck)
+	}
+	if saved.Todoist == nil || saved.Todoist.ProjectID != "42" {
+		t.Errorf("other folder settings lost: %+v", saved)
+	}
+	if err := s.AddWord("two words"); err != ErrInvalidWord {
+		t.Errorf("AddWord(two words) = %v, want ErrInvalidWord", err)
+	}
+}
+
+func TestSpellcheckUnknownLanguageFallsBack(t *testing.T) {
+	s := NewSpellcheckService(t.TempDir(), &models.FolderConfig{
+		Spellcheck: &models.SpellcheckFolderConfig{Language: "xx"},
+	})
+	if got := s.Check("hello"); got.Language != "en" {
+		t.Errorf("language = %q, want en", got.Language)
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// DayStats is one row of the per-day activity export.
+type DayStats struct {
+	Date             string `json:"date"` // YYYY-MM-DD, local time
+	NotesCreated     int    `json:"notes_created"`
+	TasksCreated     int    `json:"tasks_created"`
+	TasksCompleted   int    `json:"tasks_completed"`
+	WordsWritten     int    `json:"words_written"`
+	ArchivesCaptured int    `json:"archives_captured"`
+}
+
+// statsCSVHeader is the column order of WriteStatsCSV.
+var statsCSVHeader = []string{"date", "notes_created", "tasks_created", "tasks_completed", "words_written", "archives_captured"}
+
+// DailyStats reports activity per local calendar day, one row per day from
+// the first recorded activity through today, zero rows included so the
+// series can be plotted directly.
+//
+// notes.md keeps no per-task history, so some columns are approximations:
+// a task is "created" on the day its note was, and "completed" on the day
+// the task registry last saw it change (completions comes from there).
+// Words written counts words in new notes plus words added by each edit
+// in the note history; deletions don't subtract.
+func (nm *NoteManager) DailyStats(completions []time.Time, now time.Time) ([]DayStats, error) {
+	archives, err := nm.storage.ArchiveTimes()
+	if err != nil {
+		return nil, err
+	}
+
+	nm.mu.RLock()
+	byDay := make(map[string]*DayStats)
+	first := ""
+	day := func(t time.Time) *DayStats {
+		key := t.In(now.Location()).Format("2006-01-02")
+		if first == "" || key < first {
+			first = key
+		}
+		if byDay[key] == nil {
+			byDay[key] = &DayStats{Date: key}
+		}
+		return byDay[key]
+	}
+	for _, note := range nm.notes {
+		d := day(note.Timestamp)
+		d.NotesCreated++
+		d.TasksCreated += len(note.Tasks)
+
+		revs, err := nm.storage.ListRevisions(note.HistoryKey())
+		if err != nil {
+			nm.mu.RUnlock()
+			return nil, err
+		}
+		// Versions in order: the original, each replaced text, then now.
+		// revs[i] was replaced by the next version at revs[i].Saved.
+		versions := make([]string, 0, len(revs)+1)
+		for _, r := range revs {
+			versions = append(versions, r.Content)
+		}
+		versions = append(versions, note.Content)
+		d.WordsWritten += countWords(versions[0])
+		for i, r := range revs {
+			if added := countWords(versions[i+1]) - countWords(versions[i]); added > 0 {
+				day(r.Saved).WordsWritten += added
+			}
+		}
+	}
+	nm.mu.RUnlock()
+
+	for _, t := range completions {
+		day(t).TasksCompleted++
+	}
+	for _, t := range archives {
+		day(t).ArchivesCaptured++
+	}
+
+	if first == "" {
+		return []DayStats{}, nil
+	}
+	start, _ := time.ParseInLocation("2006-01-02", first, now.Location())
+	end := now.Format("2006-01-02")
+	var out []DayStats
+	for d := start; d.Format("2006-01-02") <= end; d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		if s := byDay[key]; s != nil {
+			out = append(out, *s)
+		} else {
+			out = append(out, DayStats{Date: key})
+		}
+	}
+	return out, nil
+}
+
+// FolderCompletions returns the last-change times of completed registry
+// tasks that belong to folderPath, i.e. approximate completion times.
+func FolderCompletions(tasks []models.GlobalTask, folderPath string) []time.Time {
+	var out []time.Time
+	for _, t := range tasks {
+		if t.Completed && t.FolderPath == folderPath && !t.LastUpdated.IsZero() {
+			out = append(out, t.LastUpdated)
+		}
+	}
+	return out
+}
+
+// WriteStatsCSV writes days as CSV with a header row.
+func WriteStatsCSV(w io.Writer, days []DayStats) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(statsCSVHeader); err != nil {
+		return err
+	}
+	for _, d := range days {
+		row := []string{d.Date}
+		for _, n := range []int{d.NotesCreated, d.TasksCreated, d.TasksCompleted, d.WordsWritten, d.ArchivesCaptured} {
+			row = append(row, strconv.Itoa(n))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func countWords(s string) int {
+	return len(strings.Fields(s))
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDailyStats(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Log", "one two three\n- [ ] task"); err != nil {
+		t.Fatal(err)
+	}
+	// The edit adds two words.
+	if err := mgr.UpdateNote(0, "Log", "one two three four five\n- [ ] task"); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	sites := filepath.Join(dir, "assets", "sites")
+	os.MkdirAll(sites, 0755)
+	os.WriteFile(filepath.Join(sites, yesterday.Format("2006_01_02_150405")+"_Example-example.com.html"), nil, 0644)
+
+	days, err := mgr.DailyStats([]time.Time{now}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 2 {
+		t.Fatalf("days = %+v, want yesterday and today", days)
+	}
+	if days[0] != (DayStats{Date: yesterday.Format("2006-01-02"), ArchivesCaptured: 1}) {
+		t.Errorf("yesterday = %+v", days[0])
+	}
+	// The note starts as 7 whitespace-separated words ("[" and "]"
+	// included), then the edit adds 2.
+	want := DayStats{Date: now.Format("2006-01-02"), NotesCreated: 1, TasksCreated: 1, TasksCompleted: 1, WordsWritten: 9}
+	if days[1] != want {
+		t.Errorf("today = %+v, want %+v", days[1], want)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStatsCSV(&buf, days[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "date,notes_created,tasks_created,tasks_completed,words_written,archives_captured\n"+now.Format("2006-01-02")+",1,1,1,9,0\n" {
+		t.Errorf("csv = %q", got)
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	db, err := NewDatabaseServiceAt(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	trs := &TaskRegistryService{db: db, noteManagers: map[string]*NoteManager{}, folderIDs: map[string]int{}, stopCh: make(chan struct{})}
+
+	work, home := t.TempDir(), t.TempDir()
+	for dir, notes := range map[string][]string{
+		work: {"- [ ] a\n- [x] b", "- [x] c"},
+		home: {"no tasks"},
+	} {
+		folder, err := trs.AddFolderByPath(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nm, _ := trs.FolderNoteManager(folder.ID)
+		for _, content := range notes {
+			if err := nm.AddNote("", content); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := trs.SyncFolderByID(folder.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.MkdirAll(filepath.Join(work, "assets", "sites"), 0755)
+	os.WriteFile(filepath.Join(work, "assets", "sites", "2026_01_02_150405_Example-example.com.html"), []byte("<html>"), 0644)
+
+	now := time.Now()
+	d, err := trs.Dashboard(4, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Folders) != 2 {
+		t.Fatalf("folders = %+v", d.Folders)
+	}
+	tot := d.Totals
+	if tot.Notes != 3 || tot.Tasks != 3 || tot.CompletedTasks != 2 || tot.PendingTasks != 1 || tot.Archives != 1 {
+		t.Errorf("totals = %+v", tot)
+	}
+	if tot.CompletionRate < 0.66 || tot.CompletionRate > 0.67 {
+		t.Errorf("completion rate = %v, want 2/3", tot.CompletionRate)
+	}
+	if tot.NotesBytes == 0 || tot.AssetsBytes != int64(len("<html>")) {
+		t.Errorf("storage = %d notes, %d assets", tot.NotesBytes, tot.AssetsBytes)
+	}
+	if len(tot.Weeks) != 4 {
+		t.Fatalf("weeks = %+v", tot.Weeks)
+	}
+	this := tot.Weeks[3]
+	if this.Week != weekStart(now).Format("2006-01-02") || this.TasksCreated != 3 || this.TasksCompleted != 2 {
+		t.Errorf("this week = %+v", this)
+	}
+	if tot.Weeks[0].Week != weekStart(now).AddDate(0, 0, -21).Format("2006-01-02") {
+		t.Errorf("first week = %s", tot.Weeks[0].Week)
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	for day, want := range map[string]string{
+		"2026-10-12": "2026-10-12", // Monday
+		"2026-10-17": "2026-10-12", // Saturday
+		"2026-10-18": "2026-10-12", // Sunday
+		"2026-11-02": "2026-11-02",
+	} {
+		d, _ := time.Parse("2006-01-02", day)
+		if got := weekStart(d.Add(15 * time.Hour)).Format("2006-01-02"); got != want {
+			t.Errorf("weekStart(%s) = %s, want %s", day, got, want)
+		}
+	}
+}
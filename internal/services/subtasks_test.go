@@ -0,0 +1,50 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestUpdateTaskCascade(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Older", "- [ ] Other"); err != nil {
+		t.Fatal(err)
+	}
+	content := "- [ ] Release\n  - [ ] Changelog\n  - [x] Tag\n    - [ ] Push\n- [ ] Next"
+	if err := mgr.AddNote("Plan", content); err != nil {
+		t.Fatal(err)
+	}
+
+	note, _ := mgr.GetNote(0)
+	release := note.Tasks[0].Index
+	toggled := make(chan int, 8)
+	mgr.OnTaskToggle(func(task models.Task) { toggled <- task.Index })
+
+	if err := mgr.UpdateTaskCascade(release, true); err != nil {
+		t.Fatal(err)
+	}
+	want := "- [x] Release\n  - [x] Changelog\n  - [x] Tag\n    - [x] Push\n- [ ] Next"
+	if !strings.HasPrefix(note.Content, want) {
+		t.Errorf("content = %q, want %q", note.Content, want)
+	}
+	// Tag was already checked, so only three tasks changed.
+	seen := map[int]bool{}
+	for range 3 {
+		seen[<-toggled] = true
+	}
+	if !seen[release] || !seen[note.Tasks[1].Index] || !seen[note.Tasks[3].Index] {
+		t.Errorf("toggled = %v, want Release, Changelog and Push", seen)
+	}
+
+	if err := mgr.UpdateTask(release, false); err != nil {
+		t.Fatal(err)
+	}
+	if !note.Tasks[1].Checked {
+		t.Error("plain UpdateTask cascaded to a subtask")
+	}
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// ErrTagNotFound is returned when a tag to rename or merge appears in no note.
+var ErrTagNotFound = errors.New("tag not found")
+
+// ErrTagExists is returned by RenameTag when the new name is already in use;
+// combining two tags is what MergeTags is for.
+var ErrTagExists = errors.New("tag already exists")
+
+// TagRewrite describes a rename or merge, applied or previewed.
+type TagRewrite struct {
+	From        []string             `json:"from"`
+	To          string               `json:"to"`
+	DryRun      bool                 `json:"dry_run"`
+	Occurrences int                  `json:"occurrences"`
+	Notes       []TagRewriteNoteInfo `json:"notes"`
+}
+
+// TagRewriteNoteInfo lists the lines a rewrite changes in one note.
+type TagRewriteNoteInfo struct {
+	Index   int                    `json:"index"`
+	Title   string                 `json:"title"`
+	Changes []models.TagLineChange `json:"changes"`
+}
+
+// RenameTag rewrites #from to #to across every note, carrying nested tags
+// along (#from/x becomes #to/x). It refuses to rename onto a tag that is
+// already in use.
+func (nm *NoteManager) RenameTag(from, to string, dryRun bool) (*TagRewrite, error) {
+	return nm.rewriteTags([]string{from}, to, dryRun, false)
+}
+
+// MergeTags rewrites each of sources to into across every note; into may
+// already exist. Lines carrying several of the merged tags end up with a
+// single #into.
+func (nm *NoteManager) MergeTags(sources []string, into string, dryRun bool) (*TagRewrite, error) {
+	return nm.rewriteTags(sources, into, dryRun, true)
+}
+
+// rewriteTags applies the rewrite to all notes under the manager lock and
+// saves once, so a failure leaves notes.md either fully old or fully new.
+// With dryRun the notes are left untouched and only the preview is returned.
+func (nm *NoteManager) rewriteTags(sources []string, to string, dryRun, allowExisting bool) (*TagRewrite, error) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.refresh()
+
+	mapping := make(map[string]string, len(sources))
+	for _, s := range sources {
+		if s != to {
+			mapping[s] = to
+		}
+	}
+	if len(mapping) == 0 {
+		return nil, fmt.Errorf("nothing to rewrite: source and target are the same")
+	}
+
+	// A tag counts as in use when it or anything nested under it is.
+	inUse := func(name string) bool {
+		for tag := range nm.tagIndex {
+			if models.TagMatches(tag, name) {
+				return true
+			}
+		}
+		return false
+	}
+	for s := range mapping {
+		if !inUse(s) {
+			return nil, fmt.Errorf("%w: #%s", ErrTagNotFound, s)
+		}
+		if models.TagMatches(to, s) {
+			return nil, fmt.Errorf("cannot move #%s under itself", s)
+		}
+	}
+	if !allowExisting && inUse(to) {
+		return nil, fmt.Errorf("%w: #%s (merge instead)", ErrTagExists, to)
+	}
+
+	result := &TagRewrite{From: sources, To: to, DryRun: dryRun, Notes: []TagRewriteNoteInfo{}}
+	updated := make(map[*models.Note]string)
+	for i, note := range nm.notes {
+		content, count, changes := models.RewriteTags(note.Content, mapping)
+		if count == 0 {
+			continue
+		}
+		result.Occurrences += count
+		result.Notes = append(result.Notes, TagRewriteNoteInfo{Index: i, Title: note.Title, Changes: changes})
+		updated[note] = content
+	}
+	if dryRun || len(updated) == 0 {
+		return result, nil
+	}
+
+	for note, content := range updated {
+		note.Update(note.Title, content)
+	}
+	nm.assignTaskIndices()
+	nm.needsSave = true
+	return result, nm.save()
+}
+
+// TagTree returns every tag used in the notes as a hierarchy, splitting
+// nested tags like #project/clientA/website on "/".
+func (nm *NoteManager) TagTree() []*models.TagNode {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	noteTags := make([][]string, 0, len(nm.notes))
+	for _, note := range nm.notes {
+		noteTags = append(noteTags, note.Tags)
+	}
+	return models.BuildTagTree(noteTags)
+}
+
+// rebuildIndexes refreshes the tag and link indexes from each note's
+// parsed Tags and Links and bumps the generation. Called with the write
+// lock held whenever notes change (from loadNotes and save), so lookups
+// never rescan note bodies.
+func (nm *NoteManager) rebuildIndexes() {
+	nm.tagIndex = make(map[string][]*models.Note)
+	for _, note := range nm.notes {
+		for _, tag := range note.Tags {
+			nm.tagIndex[tag] = append(nm.tagIndex[tag], note)
+		}
+	}
+	nm.rebuildLinkIndexes()
+	nm.generation++
+}
+
+// notesWithTag returns the set of notes carrying tag or a tag nested under
+// it, from the index. Callers hold at least the read lock.
+func (nm *NoteManager) notesWithTag(tag string) map[*models.Note]bool {
+	set := make(map[*models.Note]bool)
+	for t, notes := range nm.tagIndex {
+		if !models.TagMatches(t, tag) {
+			continue
+		}
+		for _, note := range notes {
+			set[note] = true
+		}
+	}
+	return set
+}
+
+// Generation changes every time the notes do. Derived indexes outside the
+// manager (the search index) compare it to know when to rebuild.
+func (nm *NoteManager) Generation() uint64 {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+	return nm.generation
+}
+
+// TagStat summarizes how one tag is used.
+type TagStat struct {
+	Tag       string    `json:"tag"`
+	Notes     int       `json:"notes"`      // notes mentioning the tag
+	OpenTasks int       `json:"open_tasks"` // unchecked tasks carrying it
+	LastUsed  time.Time `json:"last_used"`  // newest note mentioning it
+}
+
+// TagPair counts the notes two tags appear in together.
+type TagPair struct {
+	A     string `json:"a"`
+	B     string `json:"b"`
+	Notes int    `json:"notes"`
+}
+
+// TagStats is the payload behind GET /api/tags/stats.
+type TagStats struct {
+	Tags         []TagStat `json:"tags"`
+	CoOccurrence []TagPair `json:"co_occurrence"`
+}
+
+// TagStats counts, per tag, the notes and open tasks using it and when it
+// was last used, plus how often each pair of tags shares a note. Tags are
+// ordered by note count, most used first; pairs likewise. Counts are by
+// exact tag — a note tagged #project/site does not count towards #project.
+func (nm *NoteManager) TagStats() *TagStats {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	byTag := make(map[string]*TagStat)
+	pairs := make(map[[2]string]int)
+	for _, note := range nm.notes {
+		tags := note.Tags
+		for _, tag := range tags {
+			st, ok := byTag[tag]
+			if !ok {
+				st = &TagStat{Tag: tag}
+				byTag[tag] = st
+			}
+			st.Notes++
+			if note.Timestamp.After(st.LastUsed) {
+				st.LastUsed = note.Timestamp
+			}
+		}
+		for _, task := range note.Tasks {
+			if task.Checked {
+				continue
+			}
+			for _, tag := range task.Tags {
+				if st, ok := byTag[tag]; ok {
+					st.OpenTasks++
+				}
+			}
+		}
+		sorted := append([]string(nil), tags...)
+		sort.Strings(sorted)
+		for i := range sorted {
+			for j := i + 1; j < len(sorted); j++ {
+				pairs[[2]string{sorted[i], sorted[j]}]++
+			}
+		}
+	}
+
+	stats := &TagStats{Tags: []TagStat{}, CoOccurrence: []TagPair{}}
+	for _, st := range byTag {
+		stats.Tags = append(stats.Tags, *st)
+	}
+	sort.Slice(stats.Tags, func(i, j int) bool {
+		a, b := stats.Tags[i], stats.Tags[j]
+		if a.Notes != b.Notes {
+			return a.Notes > b.Notes
+		}
+		return a.Tag < b.Tag
+	})
+	for p, n := range pairs {
+		stats.CoOccurrence = append(stats.CoOccurrence, TagPair{A: p[0], B: p[1], Notes: n})
+	}
+	sort.Slice(stats.CoOccurrence, func(i, j int) bool {
+		a, b := stats.CoOccurrence[i], stats.CoOccurrence[j]
+		if a.Notes != b.Notes {
+			return a.Notes > b.Notes
+		}
+		if a.A != b.A {
+			return a.A < b.A
+		}
+		return a.B < b.B
+	})
+	return stats
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRenameAndMergeTags(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("A", "- [ ] draft spec #wrk #projx"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("B", "Kickoff for #proj-x at #wrk"); err != nil {
+		t.Fatal(err)
+	}
+
+	preview, err := mgr.RenameTag("wrk", "work", true)
+	if err != nil {
+		t.Fatalf("RenameTag dry run: %v", err)
+	}
+	if preview.Occurrences != 2 || len(preview.Notes) != 2 {
+		t.Errorf("preview = %+v", preview)
+	}
+	if got := mgr.GetAllNotes()[1].Content; got != "- [ ] draft spec #wrk #projx" {
+		t.Fatalf("dry run changed content: %q", got)
+	}
+
+	if _, err := mgr.RenameTag("wrk", "work", false); err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+	if _, err := mgr.RenameTag("projx", "work", false); !errors.Is(err, ErrTagExists) {
+		t.Errorf("rename onto existing tag: err = %v", err)
+	}
+	if _, err := mgr.RenameTag("nope", "other", false); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("rename of unknown tag: err = %v", err)
+	}
+
+	if _, err := mgr.MergeTags([]string{"projx", "proj-x"}, "projectx", false); err != nil {
+		t.Fatalf("MergeTags: %v", err)
+	}
+	notes := mgr.GetAllNotes()
+	if notes[0].Content != "Kickoff for #projectx at #work" || notes[1].Content != "- [ ] draft spec #work #projectx" {
+		t.Errorf("contents = %q, %q", notes[0].Content, notes[1].Content)
+	}
+	if tags := notes[1].Tasks[0].Tags; len(tags) != 2 || tags[0] != "work" || tags[1] != "projectx" {
+		t.Errorf("task tags = %v", tags)
+	}
+
+	// The rewrite is on disk, not just in memory.
+	reloaded, err := NewNoteManager(mgr.GetBasePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.GetAllNotes()[0].Content; got != "Kickoff for #projectx at #work" {
+		t.Errorf("reloaded content = %q", got)
+	}
+}
+
+func TestRenderNotesHTMLFiltered_TagIncludesDescendants(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{"site launch #client/acme/site", "invoice #client/beta", "groceries #home"} {
+		if err := mgr.AddNote("", body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	html, err := mgr.RenderNotesHTMLFiltered("client", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, "site launch") || !strings.Contains(html, "invoice") || strings.Contains(html, "groceries") {
+		t.Errorf("filtered html:\n%s", html)
+	}
+	// Indices stay global so edit/delete target the right note.
+	if !strings.Contains(html, `id="note-1"`) || strings.Contains(html, `id="note-0"`) {
+		t.Error("filtered notes should keep their global indices")
+	}
+}
+
+func TestTagStats(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{
+		"- [ ] ship #release #web\n- [x] tag build #release",
+		"retro #release #web",
+		"ideas #someday",
+	} {
+		if err := mgr.AddNote("", body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := mgr.TagStats()
+	if len(stats.Tags) != 3 {
+		t.Fatalf("tags = %+v", stats.Tags)
+	}
+	release := stats.Tags[0]
+	if release.Tag != "release" || release.Notes != 2 || release.OpenTasks != 1 {
+		t.Errorf("release = %+v", release)
+	}
+	// Notes are newest first, so the newest mention is the retro note.
+	if !release.LastUsed.Equal(mgr.GetAllNotes()[1].Timestamp) {
+		t.Errorf("release last used %v", release.LastUsed)
+	}
+	if len(stats.CoOccurrence) != 1 || stats.CoOccurrence[0] != (TagPair{A: "release", B: "web", Notes: 2}) {
+		t.Errorf("co-occurrence = %+v", stats.CoOccurrence)
+	}
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// taskExportHeader is the column order of WriteTaskRows.
+var taskExportHeader = []string{"folder", "folder_path", "note", "text", "state", "priority", "due", "tags", "created", "updated", "completed", "id", "hash"}
+
+// TaskExportRow is a task as a row of the CSV or TSV task export.
+type TaskExportRow struct {
+	Folder     string // alias, else base name
+	FolderPath string
+	Note       string // title of the note holding the task, else its ID
+	Text       string // without checkbox and metadata tokens
+	State      models.TaskState
+	Priority   int
+	Due        *time.Time
+	Tags       []string
+	// Created is when a sync first saw the task, zero if that was before
+	// the task history; Completed when it was last ticked, for a done task.
+	Created   time.Time
+	Updated   time.Time
+	Completed time.Time
+	ID        int
+	Hash      string
+}
+
+// TaskTimes is when the task history first saw a task and last saw it
+// completed.
+type TaskTimes struct {
+	Added     time.Time
+	Completed time.Time
+}
+
+// TaskTimes returns the times of the tasks of the service's user's active
+// folders matching f's folder fields, by folder ID and task hash.
+func (ds *DatabaseService) TaskTimes(f TaskFilter) (map[int]map[string]TaskTimes, error) {
+	where, args := TaskFilter{FolderID: f.FolderID, FolderPath: f.FolderPath, Group: f.Group}.where()
+	rows, err := ds.db.Query(`
+		SELECT t.folder_id, t.task_hash, t.event, t.completed, t.at
+		FROM task_history t JOIN folders f ON t.folder_id = f.id
+		WHERE `+ownerCond+` AND t.event IN (?, ?) AND `+where+`
+		ORDER BY t.at, t.id`, append([]any{ds.user, TaskEventAdded, TaskEventCompleted}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task history: %w", err)
+	}
+	defer rows.Close()
+	times := map[int]map[string]TaskTimes{}
+	for rows.Next() {
+		var folderID int
+		var hash, event string
+		var completed bool
+		var at time.Time
+		if err := rows.Scan(&folderID, &hash, &event, &completed, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan task event: %w", err)
+		}
+		if times[folderID] == nil {
+			times[folderID] = map[string]TaskTimes{}
+		}
+		t := times[folderID][hash]
+		if event == TaskEventAdded && t.Added.IsZero() {
+			t.Added = at
+			// A task first seen done has no completed event of its own.
+			if completed {
+				t.Completed = at
+			}
+		} else if event == TaskEventCompleted {
+			t.Completed = at
+		}
+		times[folderID][hash] = t
+	}
+	return times, rows.Err()
+}
+
+// TaskExportRows turns registry tasks into export rows, in order, with
+// their times from the task history. noteTitles returns a folder's note
+// titles by note ID (models.Note.HistoryKey); it is called once per
+// folder, and without it, or a title, the note column holds the ID.
+func (ds *DatabaseService) TaskExportRows(tasks []models.GlobalTask, noteTitles func(folderID int, folderPath string) map[string]string) ([]TaskExportRow, error) {
+	times, err := ds.TaskTimes(TaskFilter{})
+	if err != nil {
+		return nil, err
+	}
+	titles := map[int]map[string]string{}
+	rows := make([]TaskExportRow, 0, len(tasks))
+	for _, task := range tasks {
+		if _, ok := titles[task.FolderID]; !ok && noteTitles != nil {
+			titles[task.FolderID] = noteTitles(task.FolderID, task.FolderPath)
+		}
+		note := task.NoteID
+		if title := titles[task.FolderID][task.NoteID]; title != "" {
+			note = title
+		}
+		priority, _, tags := models.ParseTaskMetadata(task.Content)
+		row := TaskExportRow{
+			Folder:     task.FolderName,
+			FolderPath: task.FolderPath,
+			Note:       note,
+			Text:       strings.TrimSpace(models.CleanTaskText(taskLineText(task.Content))),
+			State:      models.TaskStateOf(task.Content),
+			Priority:   priority,
+			Due:        task.DueDate,
+			Tags:       tags,
+			Created:    times[task.FolderID][task.Hash].Added,
+			Updated:    task.LastUpdated,
+			ID:         task.ID,
+			Hash:       task.Hash,
+		}
+		if task.Completed {
+			row.State = models.TaskDone
+			row.Completed = times[task.FolderID][task.Hash].Completed
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ExportTasks returns the tasks q selects (its limit and offset aside) as
+// export rows, with the titles of their notes.
+func (trs *TaskRegistryService) ExportTasks(q GlobalTaskQuery, now time.Time) ([]TaskExportRow, error) {
+	q.Limit, q.Offset = 0, 0
+	res, err := trs.QueryGlobalTasks(q, now)
+	if err != nil {
+		return nil, err
+	}
+	return trs.db.TaskExportRows(res.Tasks, func(folderID int, _ string) map[string]string {
+		nm, err := trs.FolderNoteManager(folderID)
+		if err != nil {
+			return nil
+		}
+		return nm.NoteTitles()
+	})
+}
+
+// NoteTitles maps the ID of each note (models.Note.HistoryKey) to its
+// title.
+func (nm *NoteManager) NoteTitles() map[string]string {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+	titles := make(map[string]string, len(nm.notes))
+	for _, note := range nm.notes {
+		titles[note.HistoryKey()] = note.Title
+	}
+	return titles
+}
+
+// WriteTaskRows writes rows with a header row, as CSV, or as TSV when
+// comma is '\t'. Times are local, YYYY-MM-DD HH:MM:SS; empty when unknown.
+func WriteTaskRows(w io.Writer, rows []TaskExportRow, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(taskExportHeader); err != nil {
+		return err
+	}
+	stamp := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Local().Format("2006-01-02 15:04:05")
+	}
+	for _, row := range rows {
+		priority, due := "", ""
+		if row.Priority > 0 {
+			priority = "p" + strconv.Itoa(row.Priority)
+		}
+		if row.Due != nil {
+			due = row.Due.Format("2006-01-02")
+			if row.Due.Hour() != 0 || row.Due.Minute() != 0 {
+				due = row.Due.Format("2006-01-02 15:04")
+			}
+		}
+		record := []string{
+			row.Folder, row.FolderPath, row.Note, row.Text, string(row.State), priority, due,
+			strings.Join(row.Tags, " "), stamp(row.Created), stamp(row.Updated), stamp(row.Completed),
+			strconv.Itoa(row.ID), row.Hash,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestExportTasks(t *testing.T) {
+	db, err := NewDatabaseServiceAt(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	trs := &TaskRegistryService{db: db, noteManagers: map[string]*NoteManager{}, folderIDs: map[string]int{}, stopCh: make(chan struct{})}
+
+	folder, err := trs.AddFolderByPath(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	nm, _ := trs.FolderNoteManager(folder.ID)
+	if err := nm.AddNote("Release", "- [ ] !p1 @2026-10-20 #ops ship it, \"now\"\n- [/] write docs\n- [x] tag v2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := trs.SyncFolderByID(folder.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := trs.ExportTasks(GlobalTaskQuery{Limit: 1}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("%d rows, want every task whatever the limit", len(rows))
+	}
+	ship := rows[0]
+	if ship.Note != "Release" || ship.Text != `ship it, "now"` || ship.State != models.TaskTodo || ship.Priority != 1 ||
+		ship.Due == nil || len(ship.Tags) != 1 || ship.Created.IsZero() || !ship.Completed.IsZero() {
+		t.Errorf("ship = %+v", ship)
+	}
+	if rows[1].State != models.TaskDoing || rows[2].State != models.TaskDone || rows[2].Completed.IsZero() {
+		t.Errorf("rows = %+v", rows)
+	}
+	open := false
+	if rows, _ := trs.ExportTasks(GlobalTaskQuery{Completed: &open}, time.Now()); len(rows) != 2 {
+		t.Errorf("%d open rows, want 2", len(rows))
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTaskRows(&buf, rows, ','); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 || strings.Join(records[0], ",") != strings.Join(taskExportHeader, ",") {
+		t.Fatalf("records = %q", records)
+	}
+	if got := records[1]; got[2] != "Release" || got[3] != `ship it, "now"` || got[4] != "todo" || got[5] != "p1" || got[6] != "2026-10-20" || got[7] != "ops" {
+		t.Errorf("first record = %q", got)
+	}
+
+	buf.Reset()
+	WriteTaskRows(&buf, rows[2:], '\t')
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "folder\tfolder_path\tnote\t") || !strings.Contains(lines[1], "\ttag v2\tdone\t") {
+		t.Errorf("TSV = %q", buf.String())
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// TaskFilter narrows and orders a task DB query. The zero value selects
+// every task of every active folder in folder order.
+type TaskFilter struct {
+	TaskID     int    // 0 = any task
+	Hash       string // stable task hash (see ComputeTaskHashes); "" = any
+	FolderID   int    // 0 = any folder
+	FolderPath string // exact folder path; "" = any
+	Group      string // folder group (see SetFolderLabels); "" = any
+	Completed  *bool  // nil = open and done
+	Search     string // case-insensitive substring of the task text
+	// DueFrom and DueBefore bound the due day as "YYYY-MM-DD": on or after
+	// DueFrom and strictly before DueBefore. Either set drops undated tasks.
+	DueFrom   string
+	DueBefore string
+	DueNone   bool // only tasks without a due date
+	// Sort is one of taskSortColumns' keys; "" keeps folder order.
+	// Undated tasks sort last by due either way.
+	Sort  string
+	Desc  bool
+	Limit int // 0 = no limit
+	// Offset skips that many matching tasks; used with Limit to page.
+	Offset int
+}
+
+// taskSortColumns maps the sort keys TaskFilter accepts to SQL.
+var taskSortColumns = map[string]string{
+	"due":     "t.due_date",
+	"updated": "t.last_updated",
+	"text":    "t.content COLLATE NOCASE",
+	"folder":  "COALESCE(NULLIF(f.alias, ''), f.path)",
+}
+
+// likeEscaper escapes LIKE wildcards in search text; queries use ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// where builds the WHERE clause and its arguments.
+func (f TaskFilter) where() (string, []any) {
+	conds := []string{"f.active = 1"}
+	var args []any
+	add := func(cond string, arg ...any) {
+		conds = append(conds, cond)
+		args = append(args, arg...)
+	}
+	if f.TaskID != 0 {
+		add("t.id = ?", f.TaskID)
+	}
+	if f.Hash != "" {
+		add("t.task_hash = ?", f.Hash)
+	}
+	if f.FolderID != 0 {
+		add("t.folder_id = ?", f.FolderID)
+	}
+	if f.FolderPath != "" {
+		add("f.path = ?", f.FolderPath)
+	}
+	if f.Group != "" {
+		add("f.group_name = ?", f.Group)
+	}
+	if f.Completed != nil {
+		add("t.completed = ?", *f.Completed)
+	}
+	if f.Search != "" {
+		add(`t.content LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(f.Search)+"%")
+	}
+	// Stored due values are "YYYY-MM-DD" or "YYYY-MM-DDTHH:MM"; both compare
+	// correctly against a bare day as text.
+	if f.DueFrom != "" {
+		add("t.due_date >= ?", f.DueFrom)
+	}
+	if f.DueBefore != "" {
+		add("t.due_date < ?", f.DueBefore)
+	}
+	if f.DueNone {
+		add("t.due_date IS NULL")
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// QueryTasks returns the tasks matching f, one page of them when f.Limit
+// is set. Total counts every match; summaries always cover every task.
+func (ds *DatabaseService) QueryTasks(f TaskFilter) (*models.GlobalTasksResponse, error) {
+	order := folderOrder + ", t.completed, t.last_updated DESC"
+	if f.Sort != "" {
+		col, ok := taskSortColumns[f.Sort]
+		if !ok {
+			return nil, fmt.Errorf("unknown task sort %q", f.Sort)
+		}
+		dir := "ASC"
+		if f.Desc {
+			dir = "DESC"
+		}
+		order = fmt.Sprintf("%s %s, t.id", col, dir)
+		if f.Sort == "due" {
+			order = "t.due_date IS NULL, " + order
+		}
+	}
+	where, args := f.where()
+	where = ownerCond + " AND " + where
+	args = append([]any{ds.user}, args...)
+
+	var total int
+	if err := ds.db.QueryRow(`SELECT COUNT(*) FROM tasks t JOIN folders f ON t.folder_id = f.id WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	query := `
+		SELECT t.id, t.folder_id, t.file_path, t.line_number, t.content,
+			   t.completed, t.last_updated, f.path, t.due_date, t.note_id, t.char_offset,
+			   t.task_hash, COALESCE(f.alias, ''), COALESCE(f.group_name, ''), COALESCE(l.link_id, 0)
+		FROM tasks t
+		JOIN folders f ON t.folder_id = f.id
+		LEFT JOIN task_links l ON l.folder_id = t.folder_id AND l.task_hash = t.task_hash
+		WHERE ` + where + `
+		ORDER BY ` + order
+	if f.Limit > 0 || f.Offset > 0 {
+		limit := f.Limit
+		if limit <= 0 {
+			limit = -1 // SQLite: no limit
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, f.Offset)
+	}
+	rows, err := ds.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	var tasks []models.GlobalTask
+	for rows.Next() {
+		var task models.GlobalTask
+		var lastUpdated string
+		var due, noteID, hash sql.NullString
+		err := rows.Scan(
+			&task.ID, &task.FolderID, &task.FilePath, &task.LineNumber,
+			&task.Content, &task.Completed, &lastUpdated, &task.FolderPath, &due,
+			&noteID, &task.CharOffset, &hash, &task.FolderName, &task.FolderGroup, &task.LinkID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		// The driver hands DATETIME columns back as RFC 3339.
+		if t, err := time.Parse(time.RFC3339Nano, lastUpdated); err == nil {
+			task.LastUpdated = t
+		} else if t, err := time.Parse("2006-01-02 15:04:05.000000-07:00", lastUpdated); err == nil {
+			task.LastUpdated = t
+		} else if t, err := time.Parse("2006-01-02 15:04:05", lastUpdated); err == nil {
+			task.LastUpdated = t
+		}
+		task.FolderName = models.FolderName(task.FolderPath, task.FolderName)
+		task.NoteID = noteID.String
+		task.Hash = hash.String
+		if d := models.ParseDueValue(due.String); due.Valid && !d.IsZero() {
+			task.DueDate = &d
+			task.Overdue = models.IsOverdue(d, task.Completed, now)
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	summaries, err := ds.getTaskSummaries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task summaries: %w", err)
+	}
+	return &models.GlobalTasksResponse{
+		Tasks:     tasks,
+		Summaries: summaries,
+		Groups:    groupSummaries(summaries),
+		Total:     total,
+	}, nil
+}
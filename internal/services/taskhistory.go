@@ -0,0 +1,300 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Task history events, recorded in task_history by every sync that sees
+// them and by toggles from the global tasks page.
+const (
+	TaskEventAdded     = "added"     // a task appeared, done or not
+	TaskEventCompleted = "completed" // an open task was ticked
+	TaskEventReopened  = "reopened"  // a done task was unticked
+	TaskEventRemoved   = "removed"   // a task left the notes, or its folder was forgotten
+)
+
+// MaxHistoryBuckets caps how many days or weeks TaskHistory reports.
+const MaxHistoryBuckets = 731
+
+// TaskEvent is a row of task_history. Completed is the task's state after
+// the event; for a removed task, whether it was done when it went.
+type TaskEvent struct {
+	Hash      string    `json:"hash"`
+	Content   string    `json:"content"`
+	Event     string    `json:"event"`
+	Completed bool      `json:"completed"`
+	At        time.Time `json:"at"`
+}
+
+// openDelta is how an event changes the number of open tasks.
+func (e TaskEvent) openDelta() int {
+	switch {
+	case e.Event == TaskEventAdded && !e.Completed, e.Event == TaskEventReopened:
+		return 1
+	case e.Event == TaskEventCompleted, e.Event == TaskEventRemoved && !e.Completed:
+		return -1
+	}
+	return 0
+}
+
+// execer is what recordTaskEvent needs of a *sql.DB or *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// recordTaskEvent adds an event to task_history.
+func recordTaskEvent(db execer, folderID int, hash, content, event string, completed bool, at time.Time) error {
+	_, err := db.Exec(`
+		INSERT INTO task_history (folder_id, task_hash, content, event, completed, at)
+		VALUES (?, ?, ?, ?, ?, ?)`, folderID, hash, content, event, completed, at)
+	if err != nil {
+		return fmt.Errorf("record %s event: %w", event, err)
+	}
+	return nil
+}
+
+// TaskHistoryQuery selects the folders and the days TaskHistory covers.
+type TaskHistoryQuery struct {
+	// Folder is a folder ID or an exact folder path; Group a folder group.
+	Folder string
+	Group  string
+	// By is "day" (default) or "week", weeks starting on Monday.
+	By string
+	// From and To are the first and last day as YYYY-MM-DD, inclusive. To
+	// defaults to today, From to 30 days or 12 weeks before it.
+	From string
+	To   string
+}
+
+// TaskHistory is task activity bucketed by day or week: burndown data.
+type TaskHistory struct {
+	By      string          `json:"by"`
+	From    string          `json:"from"`
+	To      string          `json:"to"`
+	Open    int             `json:"open"` // open tasks now
+	Buckets []HistoryBucket `json:"buckets"`
+	// Streak is how many days in a row, up to To, had a completion; a To
+	// of today without one yet counts up to yesterday. LongestStreak is
+	// the longest run up to To.
+	Streak        int `json:"streak"`
+	LongestStreak int `json:"longest_streak"`
+}
+
+// HistoryBucket is a day or week of task activity.
+type HistoryBucket struct {
+	Start     string `json:"start"` // first day, YYYY-MM-DD
+	Added     int    `json:"added"`
+	Completed int    `json:"completed"`
+	Reopened  int    `json:"reopened"`
+	Removed   int    `json:"removed"`
+	Open      int    `json:"open"` // open tasks at the end of the bucket
+}
+
+// TaskHistory buckets the task history of the user's active folders
+// matching q, in now's time zone.
+func (trs *TaskRegistryService) TaskHistory(q TaskHistoryQuery, now time.Time) (*TaskHistory, error) {
+	invalid := func(format string, args ...any) (*TaskHistory, error) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTaskQuery, fmt.Sprintf(format, args...))
+	}
+	f := TaskFilter{Group: q.Group}
+	if id, err := strconv.Atoi(q.Folder); err == nil {
+		f.FolderID = id
+	} else {
+		f.FolderPath = q.Folder
+	}
+
+	loc := now.Location()
+	day := func(s string, def time.Time) (time.Time, error) {
+		if s == "" {
+			return def, nil
+		}
+		return time.ParseInLocation("2006-01-02", s, loc)
+	}
+	bucketStart := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+	step := func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	switch q.By {
+	case "", "day":
+		q.By = "day"
+	case "week":
+		bucketStart = func(t time.Time) time.Time { return weekStart(t.In(loc)) }
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	default:
+		return invalid("unknown bucket %q (want day or week)", q.By)
+	}
+
+	to, err := day(q.To, now)
+	if err != nil {
+		return invalid("invalid to %q (want YYYY-MM-DD)", q.To)
+	}
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, loc)
+	defFrom := to.AddDate(0, 0, -29)
+	if q.By == "week" {
+		defFrom = to.AddDate(0, 0, -7*11)
+	}
+	from, err := day(q.From, defFrom)
+	if err != nil {
+		return invalid("invalid from %q (want YYYY-MM-DD)", q.From)
+	}
+	if from.After(to) {
+		return invalid("from %s is after to %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+
+	history := &TaskHistory{By: q.By, From: from.Format("2006-01-02"), To: to.Format("2006-01-02"), Buckets: []HistoryBucket{}}
+	var starts []time.Time
+	for s := bucketStart(from); !s.After(to); s = step(s) {
+		if len(starts) == MaxHistoryBuckets {
+			return invalid("more than %d %ss from %s to %s", MaxHistoryBuckets, q.By, history.From, history.To)
+		}
+		starts = append(starts, s)
+	}
+
+	open := false
+	current, err := trs.db.QueryTasks(TaskFilter{FolderID: f.FolderID, FolderPath: f.FolderPath, Group: f.Group, Completed: &open, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	history.Open = current.Total
+	// Stored times keep their UTC offset and compare as text, so ask for
+	// a day more and drop the excess by time.
+	events, err := trs.db.TaskEvents(f, starts[0].AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk back from now: a bucket ends with the open tasks of now, less
+	// what every later event changed.
+	history.Buckets = make([]HistoryBucket, len(starts))
+	for i, s := range starts {
+		history.Buckets[i].Start = s.Format("2006-01-02")
+	}
+	openAfter := make([]int, len(starts)) // open delta of events after bucket i
+	end := step(starts[len(starts)-1])
+	for _, e := range events {
+		if e.At.Before(starts[0]) {
+			continue
+		}
+		if !e.At.Before(end) {
+			for j := range openAfter {
+				openAfter[j] += e.openDelta()
+			}
+			continue
+		}
+		i := len(starts) - 1
+		for e.At.Before(starts[i]) {
+			i--
+		}
+		b := &history.Buckets[i]
+		switch e.Event {
+		case TaskEventAdded:
+			b.Added++
+		case TaskEventCompleted:
+			b.Completed++
+		case TaskEventReopened:
+			b.Reopened++
+		case TaskEventRemoved:
+			b.Removed++
+		}
+		for j := 0; j < i; j++ {
+			openAfter[j] += e.openDelta()
+		}
+	}
+	for i := range history.Buckets {
+		history.Buckets[i].Open = max(history.Open-openAfter[i], 0)
+	}
+
+	days, err := trs.db.CompletionDays(f, loc)
+	if err != nil {
+		return nil, err
+	}
+	history.Streak, history.LongestStreak = streaks(days, to, now)
+	return history, nil
+}
+
+// streaks returns the run of days with a completion ending at to (or the
+// day before, when to is today) and the longest run up to to. days holds
+// YYYY-MM-DD keys.
+func streaks(days map[string]bool, to, now time.Time) (current, longest int) {
+	d := to
+	if !days[d.Format("2006-01-02")] && d.Format("2006-01-02") == now.Format("2006-01-02") {
+		d = d.AddDate(0, 0, -1)
+	}
+	for days[d.Format("2006-01-02")] {
+		current++
+		d = d.AddDate(0, 0, -1)
+	}
+
+	last := to.Format("2006-01-02")
+	var keys []string
+	for key := range days {
+		if key <= last {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	run := 0
+	var prev time.Time
+	for _, key := range keys {
+		t, _ := time.ParseInLocation("2006-01-02", key, to.Location())
+		if !prev.IsZero() && prev.AddDate(0, 0, 1).Equal(t) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+		prev = t
+	}
+	return current, longest
+}
+
+// TaskEvents returns the history of the service's user's active folders
+// matching f's folder fields since a time, oldest first.
+func (ds *DatabaseService) TaskEvents(f TaskFilter, since time.Time) ([]TaskEvent, error) {
+	where, args := TaskFilter{FolderID: f.FolderID, FolderPath: f.FolderPath, Group: f.Group}.where()
+	rows, err := ds.db.Query(`
+		SELECT t.task_hash, t.content, t.event, t.completed, t.at
+		FROM task_history t JOIN folders f ON t.folder_id = f.id
+		WHERE `+ownerCond+` AND t.at >= ? AND `+where+`
+		ORDER BY t.at, t.id`, append([]any{ds.user, since}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task history: %w", err)
+	}
+	defer rows.Close()
+	var events []TaskEvent
+	for rows.Next() {
+		var e TaskEvent
+		if err := rows.Scan(&e.Hash, &e.Content, &e.Event, &e.Completed, &e.At); err != nil {
+			return nil, fmt.Errorf("failed to scan task event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// CompletionDays returns the days, as YYYY-MM-DD in loc, on which a task
+// of the active folders matching f's folder fields was completed.
+func (ds *DatabaseService) CompletionDays(f TaskFilter, loc *time.Location) (map[string]bool, error) {
+	where, args := TaskFilter{FolderID: f.FolderID, FolderPath: f.FolderPath, Group: f.Group}.where()
+	rows, err := ds.db.Query(`
+		SELECT t.at FROM task_history t JOIN folders f ON t.folder_id = f.id
+		WHERE `+ownerCond+` AND t.event = ? AND `+where,
+		append([]any{ds.user, TaskEventCompleted}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query completions: %w", err)
+	}
+	defer rows.Close()
+	days := map[string]bool{}
+	for rows.Next() {
+		var at time.Time
+		if err := rows.Scan(&at); err != nil {
+			return nil, fmt.Errorf("failed to scan completion: %w", err)
+		}
+		days[at.In(loc).Format("2006-01-02")] = true
+	}
+	return days, rows.Err()
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestTaskHistory(t *testing.T) {
+	db, folder := newTestDB(t)
+	trs := &TaskRegistryService{db: db, noteManagers: map[string]*NoteManager{}, folderIDs: map[string]int{}, stopCh: make(chan struct{})}
+
+	if err := db.SyncFolderTasks(folder.ID, []models.Task{{Text: "- [ ] a"}, {Text: "- [ ] b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SyncFolderTasks(folder.ID, []models.Task{{Text: "- [x] a", Checked: true}, {Text: "- [ ] b"}}); err != nil {
+		t.Fatal(err)
+	}
+	// Two tasks done earlier, since gone from the notes.
+	now := time.Now()
+	ago := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+	for _, e := range []struct {
+		hash, event string
+		done        bool
+		at          time.Time
+	}{
+		{"old1", TaskEventAdded, false, ago(5)}, {"old2", TaskEventAdded, false, ago(5)},
+		{"old1", TaskEventCompleted, true, ago(3)}, {"old2", TaskEventCompleted, true, ago(2)},
+	} {
+		if err := recordTaskEvent(db.db, folder.ID, e.hash, "- [x] "+e.hash, e.event, e.done, e.at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := trs.TaskHistory(TaskHistoryQuery{From: ago(6).Format("2006-01-02")}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Buckets) != 7 || history.Open != 1 {
+		t.Fatalf("history = %+v", history)
+	}
+	wantOpen := []int{0, 2, 2, 1, 0, 0, 1}
+	for i, b := range history.Buckets {
+		if b.Open != wantOpen[i] {
+			t.Errorf("bucket %s open = %d, want %d", b.Start, b.Open, wantOpen[i])
+		}
+	}
+	if today := history.Buckets[6]; today.Added != 2 || today.Completed != 1 {
+		t.Errorf("today = %+v", today)
+	}
+	if history.Streak != 1 || history.LongestStreak != 2 {
+		t.Errorf("streak = %d, longest %d", history.Streak, history.LongestStreak)
+	}
+
+	weekly, err := trs.TaskHistory(TaskHistoryQuery{By: "week", From: ago(6).Format("2006-01-02")}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	completed := 0
+	for _, b := range weekly.Buckets {
+		completed += b.Completed
+	}
+	if completed != 3 || weekly.Buckets[len(weekly.Buckets)-1].Open != 1 {
+		t.Errorf("weekly = %+v", weekly.Buckets)
+	}
+
+	// Forgetting the folder takes its tasks out of the count.
+	if err := db.SoftRemoveFolder(folder.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.RegisterFolder(folder.Path); err != nil {
+		t.Fatal(err)
+	}
+	if history, err = trs.TaskHistory(TaskHistoryQuery{From: ago(6).Format("2006-01-02")}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if history.Open != 0 || history.Buckets[6].Removed != 2 || history.Buckets[5].Open != 0 || history.Buckets[2].Open != 2 {
+		t.Errorf("after forgetting = %+v", history.Buckets)
+	}
+
+	if _, err := trs.TaskHistory(TaskHistoryQuery{By: "month"}, now); !errors.Is(err, ErrInvalidTaskQuery) {
+		t.Errorf("by=month: %v", err)
+	}
+}
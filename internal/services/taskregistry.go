@@ -5,10 +5,13 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/notify"
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
 )
 
 // TaskRegistryService manages cross-folder task synchronization
@@ -18,6 +21,20 @@ type TaskRegistryService struct {
 	mu           sync.RWMutex
 	syncTicker   *time.Ticker
 	stopCh       chan struct{}
+	closeOnce    sync.Once
+
+	notifier         notify.Notifier // optional; nil disables alerts
+	events           *EventHub       // optional; see SetEvents
+	lastOverdueAlert string          // YYYY-MM-DD of the last overdue alert sent
+
+	watcher   *folderWatcher  // nil when watching isn't available; see startBackgroundSync
+	folderIDs map[string]int  // folderPath -> folders.id for watched folders
+
+	// root, when set, is the only tree AddFolderByPath accepts; a user's
+	// registry is confined to their notes root. sharedDB means db belongs
+	// to another registry and Close leaves it open.
+	root     string
+	sharedDB bool
 }
 
 // NewTaskRegistryService creates a new task registry service
@@ -31,14 +48,44 @@ func NewTaskRegistryService() (*TaskRegistryService, error) {
 		db:           db,
 		noteManagers: make(map[string]*NoteManager),
 		stopCh:       make(chan struct{}),
+		folderIDs:    make(map[string]int),
 	}
 
-	// Start background sync every 30 seconds
+	// Watch registered folders for changes, or poll when that fails
 	service.startBackgroundSync()
 
 	return service, nil
 }
 
+// ForUser returns the registry of one multi-user account: it sees only
+// the folders that user registered, and only accepts folders inside their
+// notes root. It shares this registry's database connection.
+func (trs *TaskRegistryService) ForUser(user *models.User) *TaskRegistryService {
+	service := &TaskRegistryService{
+		db:           trs.db.ForUser(user.ID),
+		noteManagers: make(map[string]*NoteManager),
+		stopCh:       make(chan struct{}),
+		folderIDs:    make(map[string]int),
+		root:         user.NotesRoot,
+		sharedDB:     true,
+	}
+	service.startBackgroundSync()
+	return service
+}
+
+// Users returns the database holding the multi-user accounts.
+func (trs *TaskRegistryService) Users() *DatabaseService {
+	return trs.db
+}
+
+// SetEvents attaches a hub that hears about task toggles from the global
+// tasks page and about every folder sync. Passing nil disables events.
+func (trs *TaskRegistryService) SetEvents(h *EventHub) {
+	trs.mu.Lock()
+	defer trs.mu.Unlock()
+	trs.events = h
+}
+
 // RegisterFolder registers a folder for cross-folder task management
 func (trs *TaskRegistryService) RegisterFolder(folderPath string, noteManager *NoteManager) error {
 	trs.mu.Lock()
@@ -52,6 +99,7 @@ func (trs *TaskRegistryService) RegisterFolder(folderPath string, noteManager *N
 
 	// Store note manager for this folder
 	trs.noteManagers[folderPath] = noteManager
+	trs.watchFolder(folder.ID, folderPath)
 
 	// Initial sync of tasks for this folder
 	if err := trs.syncFolderTasks(folder.ID, folderPath, noteManager); err != nil {
@@ -68,7 +116,27 @@ func (trs *TaskRegistryService) syncFolderTasks(folderID int, folderPath string,
 	tasks := noteManager.GetAllTasks()
 	
 	// Sync with database
-	return trs.db.SyncFolderTasks(folderID, tasks)
+	diff, err := trs.db.SyncFolderTasksDiff(folderID, tasks)
+	if err != nil {
+		return err
+	}
+	trs.events.Publish(Event{Type: EventTasksSynced, Folder: folderPath})
+	if !diff.Empty() {
+		trs.events.Publish(Event{Type: EventTasksChanged, Folder: folderPath, Changes: &diff})
+	}
+	// Ticked in the file: follow up on linked tasks. Callers hold trs.mu,
+	// which FolderNoteManager takes, so this can't run inline.
+	if len(diff.Completed)+len(diff.Reopened) > 0 {
+		go func() {
+			for _, c := range diff.Completed {
+				trs.setLinkedCompletion(c.ID, true)
+			}
+			for _, c := range diff.Reopened {
+				trs.setLinkedCompletion(c.ID, false)
+			}
+		}()
+	}
+	return nil
 }
 
 // GetGlobalTasks returns all tasks across all registered folders
@@ -76,54 +144,56 @@ func (trs *TaskRegistryService) GetGlobalTasks() (*models.GlobalTasksResponse, e
 	return trs.db.GetGlobalTasks()
 }
 
-// UpdateGlobalTaskCompletion updates task completion and syncs back to the note file
+// UpdateGlobalTaskCompletion updates task completion in the task's note
+// file and the task DB; see DatabaseService.SetTaskCompletion.
 func (trs *TaskRegistryService) UpdateGlobalTaskCompletion(taskID int, completed bool) error {
-	// First, get the task details to know which folder it belongs to
-	globalTasks, err := trs.db.GetGlobalTasks()
+	task, err := trs.db.GetTask(taskID)
 	if err != nil {
-		return fmt.Errorf("failed to get global tasks: %w", err)
+		return fmt.Errorf("task with ID %d: %w", taskID, err)
 	}
-
-	var targetTask *models.GlobalTask
-	for _, task := range globalTasks.Tasks {
-		if task.ID == taskID {
-			targetTask = &task
-			break
-		}
+	noteManager, err := trs.FolderNoteManager(task.FolderID)
+	if err != nil {
+		return err
 	}
-
-	if targetTask == nil {
-		return fmt.Errorf("task with ID %d not found", taskID)
+	if err := trs.db.SetTaskCompletion(task, completed, noteManager); err != nil {
+		return err
 	}
 
-	// Update in database
-	if err := trs.db.UpdateTaskCompletion(taskID, completed); err != nil {
-		return fmt.Errorf("failed to update task in database: %w", err)
+	var diff TaskDiff
+	if change := (TaskChange{ID: taskID, Content: task.Content}); completed {
+		diff.Completed = append(diff.Completed, change)
+	} else {
+		diff.Reopened = append(diff.Reopened, change)
 	}
+	trs.events.Publish(Event{Type: EventTasksChanged, Folder: task.FolderPath, Changes: &diff})
+	trs.setLinkedCompletion(taskID, completed)
+	return nil
+}
 
-	// Update in the corresponding note file
-	trs.mu.RLock()
-	noteManager, exists := trs.noteManagers[targetTask.FolderPath]
-	trs.mu.RUnlock()
-
-	if exists {
-		// Find and update the task in the note manager
-		tasks := noteManager.GetAllTasks()
-		for _, task := range tasks {
-			if task.Text == targetTask.Content {
-				if err := noteManager.UpdateTask(task.Index, completed); err != nil {
-					log.Printf("Warning: failed to update task in note file: %v", err)
+// startBackgroundSync keeps the DB in step with registered folders. Each
+// folder's notes.md is watched, so external edits (vim, git pull) are
+// synced within milliseconds; a ticker only runs the overdue check. When
+// the platform can't watch files it falls back to polling every 30
+// seconds.
+func (trs *TaskRegistryService) startBackgroundSync() {
+	watcher, err := newFolderWatcher(trs.folderChanged)
+	if err == nil {
+		trs.watcher = watcher
+		trs.syncTicker = time.NewTicker(overdueCheckInterval)
+		go func() {
+			for {
+				select {
+				case <-trs.syncTicker.C:
+					trs.checkOverdue(time.Now())
+				case <-trs.stopCh:
+					return
 				}
-				break
 			}
-		}
+		}()
+		return
 	}
+	log.Printf("Warning: file watching unavailable, polling folders instead: %v", err)
 
-	return nil
-}
-
-// startBackgroundSync starts a background goroutine to periodically sync all folders
-func (trs *TaskRegistryService) startBackgroundSync() {
 	trs.syncTicker = time.NewTicker(30 * time.Second)
 	
 	go func() {
@@ -131,6 +201,7 @@ func (trs *TaskRegistryService) startBackgroundSync() {
 			select {
 			case <-trs.syncTicker.C:
 				trs.performBackgroundSync()
+				trs.checkOverdue(time.Now())
 			case <-trs.stopCh:
 				return
 			}
@@ -227,10 +298,17 @@ func (trs *TaskRegistryService) GetActiveFolders() ([]models.FolderRegistry, err
 	return trs.db.GetActiveFolders()
 }
 
+// GetFolders returns the registered folders, including deactivated ones
+// when includeInactive is set.
+func (trs *TaskRegistryService) GetFolders(includeInactive bool) ([]models.FolderRegistry, error) {
+	return trs.db.GetFolders(includeInactive)
+}
+
 // AddFolderByPath registers a user-supplied path with the global task graph.
 // Accepts any absolute or absolute-able path the user can type — no admin
 // or sandbox restrictions, matching the existing implicit auto-register
-// behavior. Validates that the path exists and is a directory; creates a
+// behavior — except that a user's registry (ForUser) stays inside their
+// notes root. Validates that the path exists and is a directory; creates a
 // fresh NoteManager for it (which also creates an empty notes.md if one
 // doesn't exist) and runs an initial sync.
 //
@@ -252,6 +330,9 @@ func (trs *TaskRegistryService) AddFolderByPath(folderPath string) (*models.Fold
 	if !info.IsDir() {
 		return nil, fmt.Errorf("not a directory: %s", abs)
 	}
+	if err := trs.checkInsideRoot(abs); err != nil {
+		return nil, err
+	}
 
 	noteManager, err := NewNoteManager(abs)
 	if err != nil {
@@ -266,6 +347,7 @@ func (trs *TaskRegistryService) AddFolderByPath(folderPath string) (*models.Fold
 		return nil, fmt.Errorf("register folder in db: %w", err)
 	}
 	trs.noteManagers[abs] = noteManager
+	trs.watchFolder(folder.ID, abs)
 
 	if err := trs.syncFolderTasks(folder.ID, abs, noteManager); err != nil {
 		log.Printf("Warning: initial sync for added folder %s: %v", abs, err)
@@ -275,10 +357,22 @@ func (trs *TaskRegistryService) AddFolderByPath(folderPath string) (*models.Fold
 	return folder, nil
 }
 
+// checkInsideRoot refuses abs, an absolute path, when the registry is
+// confined to a notes root it is outside of.
+func (trs *TaskRegistryService) checkInsideRoot(abs string) error {
+	if trs.root == "" {
+		return nil
+	}
+	if rel, err := filepath.Rel(trs.root, abs); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s is outside your notes folder %s", abs, trs.root)
+	}
+	return nil
+}
+
 // SyncFolderByID re-syncs a single folder's tasks. Used by the per-folder
 // "Sync" button in the global tasks UI — useful when the user has edited
 // notes.md externally and wants the central view to catch up immediately
-// instead of waiting for the 30s background tick.
+// instead of waiting for the watcher (or, without one, the 30s poll).
 func (trs *TaskRegistryService) SyncFolderByID(folderID int) error {
 	folder, err := trs.db.GetFolderByID(folderID)
 	if err != nil {
@@ -288,22 +382,15 @@ func (trs *TaskRegistryService) SyncFolderByID(folderID int) error {
 		return fmt.Errorf("folder %d is forgotten — re-add it first", folderID)
 	}
 
+	// Folders this process didn't open itself (e.g. registered by an
+	// earlier session, or auto-discovered after a path move) get a
+	// NoteManager now.
 	trs.mu.Lock()
-	noteManager, exists := trs.noteManagers[folder.Path]
-	if !exists {
-		// Folder was registered but we don't have a live NoteManager — that
-		// happens for folders the current process didn't open itself (e.g.
-		// folders registered by an earlier session, or auto-discovered after
-		// a path move). Lazily create one now.
-		nm, err := NewNoteManager(folder.Path)
-		if err != nil {
-			trs.mu.Unlock()
-			return fmt.Errorf("open notes.md at %s: %w", folder.Path, err)
-		}
-		trs.noteManagers[folder.Path] = nm
-		noteManager = nm
-	}
+	noteManager, err := trs.folderNoteManager(folder)
 	trs.mu.Unlock()
+	if err != nil {
+		return err
+	}
 
 	return trs.syncFolderTasks(folder.ID, folder.Path, noteManager)
 }
@@ -322,38 +409,96 @@ func (trs *TaskRegistryService) ForgetFolder(folderID int) error {
 	}
 	trs.mu.Lock()
 	delete(trs.noteManagers, folder.Path)
+	trs.unwatchFolder(folder.Path)
 	trs.mu.Unlock()
+	trs.events.Publish(Event{Type: EventTasksSynced, Folder: folder.Path})
 	log.Printf("User forgot folder %s (id=%d) — kept as inactive audit row", folder.Path, folderID)
 	return nil
 }
 
-// validateFolder checks if a folder still exists and has notes.md
-func (trs *TaskRegistryService) validateFolder(folderPath string) bool {
-	// Check if folder exists
-	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
-		return false
+// SetFolderLabels sets the display alias and group of a registered
+// folder; empty values clear them.
+func (trs *TaskRegistryService) SetFolderLabels(folderID int, alias, group string) (*models.FolderRegistry, error) {
+	folder, err := trs.db.SetFolderLabels(folderID, alias, group)
+	if err != nil {
+		return nil, err
 	}
+	trs.events.Publish(Event{Type: EventTasksSynced, Folder: folder.Path})
+	return folder, nil
+}
 
-	// Check if notes.md exists in the folder
-	notesPath := filepath.Join(folderPath, "notes.md")
-	if _, err := os.Stat(notesPath); os.IsNotExist(err) {
-		return false
+// ReactivateFolder puts a forgotten folder back into the global task view
+// under its old id and re-reads its tasks. Its notes must still be there.
+func (trs *TaskRegistryService) ReactivateFolder(folderID int) (*models.FolderRegistry, error) {
+	folder, err := trs.db.GetFolderByID(folderID)
+	if err != nil {
+		return nil, fmt.Errorf("folder %d not found: %w", folderID, err)
 	}
-
-	return true
+	if folder.Active {
+		return folder, nil
+	}
+	if !trs.validateFolder(folder.Path) {
+		return nil, fmt.Errorf("no notes in %s any more", folder.Path)
+	}
+	if _, err := trs.AddFolderByPath(folder.Path); err != nil {
+		return nil, err
+	}
+	return trs.db.GetFolderByID(folderID)
 }
 
-// Close stops the background sync and closes the database connection
-func (trs *TaskRegistryService) Close() error {
-	if trs.syncTicker != nil {
-		trs.syncTicker.Stop()
+// DeleteFolder removes a folder and its tasks from the registry for good,
+// as stale-folder cleanup does; unlike ForgetFolder no row is kept, so
+// adding the path again starts with a new id. The folder's notes on disk
+// are left alone.
+func (trs *TaskRegistryService) DeleteFolder(folderID int) error {
+	folder, err := trs.db.GetFolderByID(folderID)
+	if err != nil {
+		return fmt.Errorf("folder %d not found: %w", folderID, err)
 	}
-	
-	close(trs.stopCh)
-	
-	if trs.db != nil {
-		return trs.db.Close()
+	if err := trs.db.RemoveFolder(folderID); err != nil {
+		return err
 	}
-	
+	trs.mu.Lock()
+	delete(trs.noteManagers, folder.Path)
+	trs.unwatchFolder(folder.Path)
+	trs.mu.Unlock()
+	trs.events.Publish(Event{Type: EventTasksSynced, Folder: folder.Path})
+	log.Printf("User deleted folder %s (id=%d) from the task registry", folder.Path, folderID)
 	return nil
-}
\ No newline at end of file
+}
+
+// validateFolder checks if a folder still exists and has notes, in
+// notes.md or notes.db
+func (trs *TaskRegistryService) validateFolder(folderPath string) bool {
+	return storage.HasNotes(folderPath)
+}
+
+// Close stops the background sync, saves the registered folders' unsaved
+// notes and closes the database connection. Calls after the first do
+// nothing.
+func (trs *TaskRegistryService) Close() error {
+	var err error
+	trs.closeOnce.Do(func() {
+		if trs.syncTicker != nil {
+			trs.syncTicker.Stop()
+		}
+
+		close(trs.stopCh)
+		if trs.watcher != nil {
+			trs.watcher.close()
+		}
+
+		trs.mu.RLock()
+		for path, nm := range trs.noteManagers {
+			if cerr := nm.Close(); cerr != nil {
+				log.Printf("Warning: failed to save notes in %s: %v", path, cerr)
+			}
+		}
+		trs.mu.RUnlock()
+
+		if trs.db != nil && !trs.sharedDB {
+			err = trs.db.Close()
+		}
+	})
+	return err
+}
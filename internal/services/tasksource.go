@@ -0,0 +1,50 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+)
+
+// ErrGlobalTaskNotFound is returned for a global task ID with no row.
+var ErrGlobalTaskNotFound = errors.New("global task not found")
+
+// TaskSource is where a global task lives, for jumping from the global task
+// list to the note that holds it.
+type TaskSource struct {
+	TaskID     int    `json:"task_id"`
+	FolderPath string `json:"folder_path"`
+	FilePath   string `json:"file_path"` // absolute path of the notes file
+	Line       int    `json:"line"`      // 1-based line of the checkbox
+	NoteID     string `json:"note_id"`
+	Offset     int    `json:"offset"` // UTF-16 offset of the checkbox in the note body
+	// NoteIndex is the note's current index, known only while the folder is
+	// loaded in this process; it can be used as /#note-N on that folder's
+	// server.
+	NoteIndex *int `json:"note_index,omitempty"`
+}
+
+// TaskSource resolves a global task ID to its note and position. The
+// location is as of the folder's last sync.
+func (trs *TaskRegistryService) TaskSource(taskID int) (*TaskSource, error) {
+	task, err := trs.db.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	src := &TaskSource{
+		TaskID:     task.ID,
+		FolderPath: task.FolderPath,
+		FilePath:   filepath.Join(task.FolderPath, task.FilePath),
+		Line:       task.LineNumber,
+		NoteID:     task.NoteID,
+		Offset:     task.CharOffset,
+	}
+	trs.mu.RLock()
+	noteManager, ok := trs.noteManagers[task.FolderPath]
+	trs.mu.RUnlock()
+	if ok && task.NoteID != "" {
+		if index, found := noteManager.NoteIndexByID(task.NoteID); found {
+			src.NoteIndex = &index
+		}
+	}
+	return src, nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskSourceLocatesTaskInNotesFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	trs, err := NewTaskRegistryService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trs.Close()
+
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Older", "intro\n- [ ] Buy “milk”\n- [ ] Call Bob"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Newer", "- [ ] Ship it"); err != nil {
+		t.Fatal(err)
+	}
+	// Note IDs have one-second resolution; keep the two notes apart.
+	mgr.notes[1].Timestamp = mgr.notes[1].Timestamp.Add(-time.Hour)
+	mgr.needsSave = true
+	if err := mgr.save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := trs.RegisterFolder(dir, mgr); err != nil {
+		t.Fatal(err)
+	}
+
+	global, err := trs.GetGlobalTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "notes.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\n")
+
+	for _, task := range global.Tasks {
+		src, err := trs.TaskSource(task.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if src.Line < 1 || src.Line > len(lines) || !strings.HasSuffix(lines[src.Line-1], task.Content) {
+			t.Errorf("%q: line %d doesn't hold the task", task.Content, src.Line)
+		}
+		if src.NoteIndex == nil {
+			t.Fatalf("%q: no note index", task.Content)
+		}
+		want := 1
+		if strings.Contains(task.Content, "Ship") {
+			want = 0
+		}
+		if *src.NoteIndex != want {
+			t.Errorf("%q: note index %d, want %d", task.Content, *src.NoteIndex, want)
+		}
+		if strings.Contains(task.Content, "Call Bob") && src.Offset != 25 { // the curly quotes are one UTF-16 unit each
+			t.Errorf("Call Bob offset = %d", src.Offset)
+		}
+	}
+
+	if _, err := trs.TaskSource(-1); !errors.Is(err, ErrGlobalTaskNotFound) {
+		t.Errorf("unknown id: err = %v", err)
+	}
+}
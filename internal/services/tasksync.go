@@ -0,0 +1,136 @@
+package services
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// Shared plumbing for the external task-app syncs (Todoist, Google Tasks).
+// Each links a task line to its remote twin by a URL appended to the line
+// and keeps a machine-local snapshot of what was last synced, so edits on
+// either side can be told apart.
+
+// TaskSyncResult summarizes one sync run.
+type TaskSyncResult struct {
+	Created int `json:"created"` // local tasks created in the remote app
+	Pushed  int `json:"pushed"`  // remote updates made for linked tasks
+	Pulled  int `json:"pulled"`  // linked tasks updated in notes.md
+}
+
+// syncFields is the part of a task both sides can edit.
+type syncFields struct {
+	Content string `json:"content"`
+	Due     string `json:"due,omitempty"` // YYYY-MM-DD
+	Checked bool   `json:"checked,omitempty"`
+}
+
+// taskSyncFields extracts the syncable fields from a task line. The content
+// is the description without checkbox, metadata tokens or the remote link,
+// which stripLink removes.
+func taskSyncFields(task models.Task, stripLink func(string) string) syncFields {
+	f := syncFields{
+		Content: stripLink(stripTaskCheckbox(models.CleanTaskText(task.Text))),
+		Checked: task.Checked,
+	}
+	if !task.DueDate.IsZero() {
+		f.Due = task.DueDate.Format("2006-01-02")
+	}
+	return f
+}
+
+// rewriteSyncedTask rebuilds a task line around new content and due date,
+// keeping the local checkbox, priority and tags plus the remote link.
+func rewriteSyncedTask(task models.Task, f syncFields, link string) string {
+	mark := task.State.Mark()
+	if task.Checked {
+		mark = "[x]"
+	}
+	parts := []string{mark, f.Content}
+	if task.Priority > 0 {
+		parts = append(parts, fmt.Sprintf("!p%d", task.Priority))
+	}
+	switch {
+	case f.Due != "" && f.Due == task.DueDate.Format("2006-01-02"):
+		// Same day: keep the local token, which may carry a time of day.
+		hasTime := task.DueDate.Hour() != 0 || task.DueDate.Minute() != 0
+		parts = append(parts, models.FormatDueToken(task.DueDate, hasTime))
+	case f.Due != "":
+		parts = append(parts, "@"+f.Due)
+	}
+	for _, tag := range task.Tags {
+		parts = append(parts, "#"+tag)
+	}
+	parts = append(parts, link)
+	return strings.Join(parts, " ")
+}
+
+// resolveField three-way merges one field: a side that still matches base
+// hasn't changed, so the other side's value wins; when both changed, the
+// more recent side wins.
+func resolveField[T comparable](local, remote, base T, localWins bool) T {
+	switch {
+	case local == remote, remote == base:
+		return local
+	case local == base:
+		return remote
+	case localWins:
+		return local
+	default:
+		return remote
+	}
+}
+
+// hasTag reports whether task carries #tag. An empty tag matches every task.
+func hasTag(task models.Task, tag string) bool {
+	if tag == "" {
+		return true
+	}
+	want := strings.TrimPrefix(tag, "#")
+	for _, t := range task.Tags {
+		if strings.EqualFold(t, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncStatePath names the snapshot file for basePath under stateDir. The
+// folder name keeps it recognizable; the hash keeps same-named folders apart.
+func syncStatePath(stateDir, basePath string) string {
+	sum := sha1.Sum([]byte(basePath))
+	return filepath.Join(stateDir, filepath.Base(basePath)+"-"+hex.EncodeToString(sum[:])[:12]+".json")
+}
+
+// loadSyncState reads a snapshot file; a missing file is an empty snapshot.
+func loadSyncState[T any](path string) (map[string]T, error) {
+	state := make(map[string]T)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parse sync state %s: %w", path, err)
+	}
+	return state, nil
+}
+
+func saveSyncState[T any](path string, state map[string]T) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,22 @@
+package services
+
+import "testing"
+
+func TestResolveField(t *testing.T) {
+	cases := []struct {
+		local, remote, base string
+		localWins           bool
+		want                string
+	}{
+		{"a", "a", "x", false, "a"},         // both agree
+		{"new", "old", "old", false, "new"}, // only local changed
+		{"old", "new", "old", true, "new"},  // only remote changed
+		{"l", "r", "old", true, "l"},        // conflict, local newer
+		{"l", "r", "old", false, "r"},       // conflict, remote newer
+	}
+	for _, c := range cases {
+		if got := resolveField(c.local, c.remote, c.base, c.localWins); got != c.want {
+			t.Errorf("resolveField(%q, %q, %q, %v) = %q, want %q", c.local, c.remote, c.base, c.localWins, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// ErrTaskNotInNotes is returned by SetTaskCompletion when the task is in
+// the DB but no longer in its folder's notes.md: the file changed since
+// the last sync, and nothing is written.
+var ErrTaskNotInNotes = errors.New("task is no longer in notes.md")
+
+// GetTask returns the global task with the given ID, or
+// ErrGlobalTaskNotFound.
+func (ds *DatabaseService) GetTask(taskID int) (*models.GlobalTask, error) {
+	return ds.firstTask(TaskFilter{TaskID: taskID})
+}
+
+// GetTaskByHash returns the global task with the given stable hash, or
+// ErrGlobalTaskNotFound.
+func (ds *DatabaseService) GetTaskByHash(hash string) (*models.GlobalTask, error) {
+	return ds.firstTask(TaskFilter{Hash: hash})
+}
+
+func (ds *DatabaseService) firstTask(f TaskFilter) (*models.GlobalTask, error) {
+	f.Limit = 1
+	res, err := ds.QueryTasks(f)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Tasks) == 0 {
+		return nil, ErrGlobalTaskNotFound
+	}
+	return &res.Tasks[0], nil
+}
+
+// SetTaskCompletion marks task done or open in its folder's notes.md,
+// through noteManager, and then in the task DB. The task is found in the
+// file by its hash, which ignores the checkbox, so a task already toggled
+// there still matches. Both the global tasks page and `noteflow tasks
+// --toggle` go through here.
+func (ds *DatabaseService) SetTaskCompletion(task *models.GlobalTask, completed bool, noteManager *NoteManager) error {
+	hash := task.Hash
+	if hash == "" {
+		hash = TaskHashFromText(task.Content)
+	}
+	tasks := noteManager.GetAllTasks()
+	found := false
+	for i, h := range ComputeTaskHashes(tasks) {
+		if h != hash {
+			continue
+		}
+		t := tasks[i]
+		if err := noteManager.UpdateTask(t.Index, completed); err != nil {
+			return fmt.Errorf("update notes.md: %w", err)
+		}
+		found = true
+		break
+	}
+	if !found {
+		return fmt.Errorf("%w: %s changed since the last sync", ErrTaskNotInNotes, filepath.Join(task.FolderPath, "notes.md"))
+	}
+
+	// Keep the row's checkbox in step with the file, so the next sync
+	// doesn't see a content change.
+	content := withCheckbox(task.Content, completed)
+	now := time.Now()
+	res, err := ds.db.Exec(`
+		UPDATE tasks
+		SET content = ?, completed = ?, last_updated = ?
+		WHERE id = ? AND folder_id IN (SELECT f.id FROM folders f WHERE `+ownerCond+`)`,
+		content, completed, now, task.ID, ds.user)
+	if err != nil {
+		return fmt.Errorf("failed to update task completion: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 && task.Completed != completed {
+		event := TaskEventCompleted
+		if !completed {
+			event = TaskEventReopened
+		}
+		if err := recordTaskEvent(ds.db, task.FolderID, hash, content, event, completed, now); err != nil {
+			return err
+		}
+	}
+	task.Content, task.Completed = content, completed
+	return nil
+}
+
+// withCheckbox returns a task line with its first checkbox set to done or
+// open.
+func withCheckbox(content string, done bool) string {
+	if done {
+		content = strings.Replace(content, "[ ]", "[x]", 1)
+		return strings.Replace(content, "[/]", "[x]", 1)
+	}
+	content = strings.Replace(content, "[x]", "[ ]", 1)
+	return strings.Replace(content, "[X]", "[ ]", 1)
+}
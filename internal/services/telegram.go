@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/telegram"
+)
+
+// telegramPollTimeout is how long one long poll waits for a message.
+const telegramPollTimeout = 50 * time.Second
+
+// telegramRetryDelay is how long the bot waits after a failed poll, so
+// that an outage or a bad token doesn't spin.
+const telegramRetryDelay = 30 * time.Second
+
+// telegramMaxReply leaves room in a reply for the ellipsis that marks
+// it cut.
+const telegramMaxReply = telegram.MaxMessageLength - 16
+
+// telegramHelp answers /start and /help, and commands the bot doesn't
+// know.
+const telegramHelp = `Send me text and I'll add it as a note; when it has several lines, the first is the title.
+
+/task TEXT adds a task, e.g. /task Buy cake #errands !2 @due(sat) >Shopping
+/note TEXT adds a note, for text starting with "/"
+/tasks lists the open tasks of every folder`
+
+// TelegramService runs the Telegram capture bot: it long-polls the Bot
+// API for messages from the allowed chats and files them in the folder,
+// as notes or, with /task, as quick-added tasks; /tasks replies with the
+// open tasks of every registered folder.
+type TelegramService struct {
+	noteManager *NoteManager
+	tasks       func() ([]models.GlobalTask, error)
+	cfg         models.TelegramConfig
+	client      *telegram.Client
+	offset      int64
+	stop        chan struct{}
+}
+
+// NewTelegramService creates the bot for the folder of noteManager.
+func NewTelegramService(noteManager *NoteManager, registry *TaskRegistryService, cfg models.TelegramConfig) *TelegramService {
+	return &TelegramService{
+		noteManager: noteManager,
+		tasks: func() ([]models.GlobalTask, error) {
+			global, err := registry.GetGlobalTasks()
+			if err != nil {
+				return nil, err
+			}
+			return global.Tasks, nil
+		},
+		cfg:    cfg,
+		client: telegram.NewClient(cfg.API, cfg.ResolvedToken()),
+	}
+}
+
+// Enabled reports whether a bot token is configured and this folder is
+// the one the bot files into.
+func (s *TelegramService) Enabled() bool {
+	return s.cfg.ResolvedToken() != "" && s.cfg.RunsIn(s.noteManager.GetBasePath())
+}
+
+// Start polls for messages until Stop is called.
+func (s *TelegramService) Start() {
+	if !s.Enabled() || s.stop != nil {
+		return
+	}
+	if len(s.cfg.Chats) == 0 {
+		log.Printf("Telegram bot: no chats allowed yet; message the bot to learn your chat ID for telegram.chats")
+	}
+	s.stop = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func(stop <-chan struct{}) {
+		<-stop
+		cancel()
+	}(s.stop)
+	go func(stop <-chan struct{}) {
+		for {
+			if err := s.poll(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Warning: Telegram bot: %v", err)
+				select {
+				case <-time.After(telegramRetryDelay):
+				case <-stop:
+				}
+			}
+			select {
+			case <-stop:
+				return
+			default:
+			}
+		}
+	}(s.stop)
+}
+
+// Stop stops the polling started by Start, ending the poll in progress.
+func (s *TelegramService) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// poll waits for the next messages and answers each.
+func (s *TelegramService) poll(ctx context.Context) error {
+	updates, err := s.client.GetUpdates(ctx, s.offset, telegramPollTimeout)
+	if err != nil {
+		return err
+	}
+	for _, u := range updates {
+		s.offset = u.UpdateID + 1
+		if u.Message == nil {
+			continue
+		}
+		reply := s.Handle(u.Message.Chat.ID, u.Message.Text+u.Message.Caption, time.Now())
+		if err := s.client.SendMessage(ctx, u.Message.Chat.ID, reply); err != nil {
+			log.Printf("Warning: Telegram bot: %v", err)
+		}
+	}
+	return nil
+}
+
+// Handle files text, a message from chat, and returns the reply.
+func (s *TelegramService) Handle(chat int64, text string, now time.Time) string {
+	if !s.cfg.Allowed(chat) {
+		return fmt.Sprintf("This chat isn't allowed to use NoteFlow. To allow it, add its ID, %d, to telegram.chats in noteflow.json.", chat)
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "Send me text; photos and files need a caption to become a note."
+	}
+	command, arg := "", text
+	if strings.HasPrefix(text, "/") {
+		i := strings.IndexFunc(text, unicode.IsSpace)
+		if i < 0 {
+			i = len(text)
+		}
+		command, arg = text[:i], strings.TrimSpace(text[i:])
+		// In groups, commands name the bot: /tasks@NoteFlowBot.
+		command, _, _ = strings.Cut(strings.ToLower(command), "@")
+	}
+
+	switch command {
+	case "":
+		return s.addNote(text)
+	case "/note":
+		if arg == "" {
+			return "Usage: /note TEXT"
+		}
+		return s.addNote(arg)
+	case "/task":
+		if arg == "" {
+			return "Usage: /task TEXT"
+		}
+		added, err := s.noteManager.QuickAddTask(arg)
+		if err != nil {
+			return "Couldn't add the task: " + err.Error()
+		}
+		return fmt.Sprintf("Added to %s: %s", added.Note, strings.TrimPrefix(added.Line, "- "))
+	case "/tasks":
+		tasks, err := s.tasks()
+		if err != nil {
+			return "Couldn't read the tasks: " + err.Error()
+		}
+		return telegramTruncate(BuildDigest(tasks, now, models.DigestConfig{}.DueSoonWindow()).Text())
+	}
+	return telegramHelp
+}
+
+// addNote adds text as a note and says so.
+func (s *TelegramService) addNote(text string) string {
+	title, err := s.noteManager.CaptureNote(text)
+	if err != nil {
+		return "Couldn't add the note: " + err.Error()
+	}
+	if title == "" {
+		return "Note added."
+	}
+	return "Note added: " + title
+}
+
+// telegramTruncate cuts text to a message's length, at a line.
+func telegramTruncate(text string) string {
+	if len([]rune(text)) <= telegramMaxReply {
+		return text
+	}
+	runes := []rune(text)[:telegramMaxReply]
+	cut := string(runes)
+	if i := strings.LastIndex(cut, "\n"); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "\n…"
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/telegram"
+)
+
+func TestTelegramHandle(t *testing.T) {
+	nm := newNoteManagerWithNote(t, "Shopping", "## List")
+	s := &TelegramService{
+		noteManager: nm,
+		cfg:         models.TelegramConfig{Token: "x", Chats: []int64{42}},
+		tasks: func() ([]models.GlobalTask, error) {
+			return []models.GlobalTask{{ID: 1, Content: "- [ ] file taxes @2026-10-01", FolderPath: "/home/me/admin"}}, nil
+		},
+	}
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local)
+
+	if reply := s.Handle(7, "hello", now); !strings.Contains(reply, "7, to telegram.chats") {
+		t.Errorf("a stranger's message: %q", reply)
+	}
+	if reply := s.Handle(42, "Trip ideas\nLisbon\nPorto", now); reply != "Note added: Trip ideas" {
+		t.Errorf("note reply = %q", reply)
+	}
+	if reply := s.Handle(42, "/note@NoteFlowBot /etc is full", now); reply != "Note added." {
+		t.Errorf("/note reply = %q", reply)
+	}
+	if reply := s.Handle(42, "/task Buy cake !2 >Shopping/List", now); reply != "Added to Shopping: [ ] Buy cake !p2" {
+		t.Errorf("/task reply = %q", reply)
+	}
+	if reply := s.Handle(42, "/tasks", now); !strings.HasPrefix(reply, "Overdue (1)\n  - file taxes (due Thu Oct 1) [admin]") {
+		t.Errorf("/tasks reply = %q", reply)
+	}
+	if reply := s.Handle(42, "/frobnicate", now); reply != telegramHelp {
+		t.Errorf("unknown command reply = %q", reply)
+	}
+
+	byTitle := map[string]*models.Note{}
+	for _, note := range nm.GetAllNotes() {
+		byTitle[note.Title] = note
+	}
+	if n := byTitle["Trip ideas"]; n == nil || n.Content != "Lisbon\nPorto" {
+		t.Errorf("trip note = %+v", n)
+	}
+	if n := byTitle[""]; n == nil || n.Content != "/etc is full" {
+		t.Errorf("untitled note = %+v", n)
+	}
+	if n := byTitle["Shopping"]; n == nil || !strings.Contains(n.Content, "## List\n- [ ] Buy cake !p2") {
+		t.Errorf("shopping note = %+v", n)
+	}
+}
+
+func TestTelegramPoll(t *testing.T) {
+	var offsets []string
+	var replies []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/botx/getUpdates":
+			offsets = append(offsets, r.URL.Query().Get("offset"))
+			w.Write([]byte(`{"ok":true,"result":[{"update_id":10,"message":{"chat":{"id":42},"text":"remember the milk"}},{"update_id":11}]}`))
+		case "/botx/sendMessage":
+			var m map[string]any
+			json.NewDecoder(r.Body).Decode(&m)
+			replies = append(replies, m)
+			w.Write([]byte(`{"ok":true,"result":{}}`))
+		}
+	}))
+	defer srv.Close()
+
+	cfg := models.TelegramConfig{Token: "x", Chats: []int64{42}, API: srv.URL}
+	s := &TelegramService{noteManager: newNoteManagerWithNote(t, "Existing", "hello"), cfg: cfg, client: telegram.NewClient(srv.URL, "x")}
+	for i := 0; i < 2; i++ {
+		if err := s.poll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if strings.Join(offsets, ",") != "0,12" || len(replies) != 2 || replies[0]["text"] != "Note added." {
+		t.Errorf("offsets %v, replies %v", offsets, replies)
+	}
+}
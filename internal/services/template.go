@@ -16,6 +16,8 @@ import (
 type TemplateService struct {
 	templates map[string]*template.Template
 	assets    *embed.FS
+	urlPrefix string // base path the pages are served under, "" for the root
+	authOn    bool   // a login is required, so the UI offers to log out
 }
 
 // commitView is the shape recent commits take when handed to the template.
@@ -40,6 +42,25 @@ func NewTemplateService(assets *embed.FS) (*TemplateService, error) {
 	return service, nil
 }
 
+// SetURLPrefix sets the base path, such as "/notes", that the pages'
+// root-relative URLs resolve under.
+func (ts *TemplateService) SetURLPrefix(prefix string) {
+	ts.urlPrefix = prefix
+}
+
+// WithURLPrefix returns a TemplateService rendering the same templates
+// for pages served under prefix instead.
+func (ts *TemplateService) WithURLPrefix(prefix string) *TemplateService {
+	clone := *ts
+	clone.urlPrefix = prefix
+	return &clone
+}
+
+// SetAuthEnabled tells the pages whether a login is required.
+func (ts *TemplateService) SetAuthEnabled(on bool) {
+	ts.authOn = on
+}
+
 // loadTemplates loads all templates from embedded filesystem
 func (ts *TemplateService) loadTemplates() error {
 	var indexHTML []byte
@@ -62,7 +83,7 @@ func (ts *TemplateService) loadTemplates() error {
 	})
 
 	// Parse the template
-	tmpl, err = tmpl.Parse(string(indexHTML))
+	tmpl, err = ts.parsePage(tmpl, indexHTML)
 	if err != nil {
 		return err
 	}
@@ -71,6 +92,28 @@ func (ts *TemplateService) loadTemplates() error {
 	return nil
 }
 
+// partialsFile holds the templates the pages share, such as the
+// "url-prefix" script.
+const partialsFile = "web/templates/partials.html"
+
+// parsePage parses page into tmpl along with the shared partials.
+func (ts *TemplateService) parsePage(tmpl *template.Template, page []byte) (*template.Template, error) {
+	var partials []byte
+	var err error
+	if ts.assets != nil {
+		partials, err = ts.assets.ReadFile(partialsFile)
+	} else {
+		partials, err = os.ReadFile(partialsFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tmpl, err = tmpl.Parse(string(page)); err != nil {
+		return nil, err
+	}
+	return tmpl.Parse(string(partials))
+}
+
 // RenderIndex renders the main index page with theme and context
 func (ts *TemplateService) RenderIndex(config *models.Config, basePath string) (string, error) {
 	// Get current theme
@@ -116,6 +159,8 @@ func (ts *TemplateService) RenderIndex(config *models.Config, basePath string) (
 		FolderPath    string
 		GitDisplay    string
 		RecentCommits []commitView
+		URLPrefix     string
+		AuthEnabled   bool
 	}{
 		FontFaces:     template.CSS(fontCSS),
 		ThemedStyles:  template.CSS(themedCSS),
@@ -123,6 +168,8 @@ func (ts *TemplateService) RenderIndex(config *models.Config, basePath string) (
 		FolderPath:    basePath,
 		GitDisplay:    gitDisplay,
 		RecentCommits: recentCommits,
+		URLPrefix:     ts.urlPrefix,
+		AuthEnabled:   ts.authOn,
 	}
 
 	// Execute template
@@ -148,7 +195,9 @@ func (ts *TemplateService) getFontCSS() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return string(fontCSS), nil
+	// fonts.css points at the font files relative to itself; inlined into
+	// a page they need the full path.
+	return strings.ReplaceAll(string(fontCSS), "url('../fonts/", "url('"+ts.urlPrefix+"/static/fonts/"), nil
 }
 
 // getThemedCSS returns the CSS with theme colors applied
@@ -209,6 +258,7 @@ func (ts *TemplateService) RenderGlobalTasks(config *models.Config, basePath str
 	data := map[string]interface{}{
 		"CSS":        template.CSS(themedCSS),
 		"WorkingDir": basePath,
+		"URLPrefix":  ts.urlPrefix,
 	}
 
 	// Add theme colors to template data
@@ -217,7 +267,7 @@ func (ts *TemplateService) RenderGlobalTasks(config *models.Config, basePath str
 	}
 
 	// Parse and execute template
-	tmpl, err := template.New("globaltasks").Parse(string(templateHTML))
+	tmpl, err := ts.parsePage(template.New("globaltasks"), templateHTML)
 	if err != nil {
 		return "", err
 	}
@@ -228,4 +278,134 @@ func (ts *TemplateService) RenderGlobalTasks(config *models.Config, basePath str
 	}
 
 	return buf.String(), nil
-}
\ No newline at end of file
+}
+
+// RenderBoard renders the kanban board page with theme styling
+func (ts *TemplateService) RenderBoard(config *models.Config, basePath string) (string, error) {
+	theme := themes.AvailableThemes[config.Theme]
+	if theme == nil {
+		theme = themes.AvailableThemes["dark-orange"]
+	}
+
+	var templateHTML []byte
+	var err error
+	if ts.assets != nil {
+		templateHTML, err = ts.assets.ReadFile("web/templates/board.html")
+	} else {
+		templateHTML, err = os.ReadFile("web/templates/board.html")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	themedCSS, err := ts.getThemedCSS(theme.Colors)
+	if err != nil {
+		return "", err
+	}
+	data := map[string]interface{}{
+		"CSS":        template.CSS(themedCSS),
+		"WorkingDir": basePath,
+		"URLPrefix":  ts.urlPrefix,
+	}
+	for key, value := range theme.Colors {
+		data[key] = value
+	}
+
+	tmpl, err := ts.parsePage(template.New("board"), templateHTML)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderStats renders the statistics dashboard page with theme styling
+func (ts *TemplateService) RenderStats(config *models.Config, basePath string) (string, error) {
+	theme := themes.AvailableThemes[config.Theme]
+	if theme == nil {
+		theme = themes.AvailableThemes["dark-orange"]
+	}
+
+	var templateHTML []byte
+	var err error
+	if ts.assets != nil {
+		templateHTML, err = ts.assets.ReadFile("web/templates/stats.html")
+	} else {
+		templateHTML, err = os.ReadFile("web/templates/stats.html")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	themedCSS, err := ts.getThemedCSS(theme.Colors)
+	if err != nil {
+		return "", err
+	}
+	data := map[string]interface{}{
+		"CSS":        template.CSS(themedCSS),
+		"WorkingDir": basePath,
+		"URLPrefix":  ts.urlPrefix,
+	}
+	for key, value := range theme.Colors {
+		data[key] = value
+	}
+
+	tmpl, err := ts.parsePage(template.New("stats"), templateHTML)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderLogin renders the login page. next is where to go after logging
+// in; errMsg, when set, says why the last attempt failed. askName adds a
+// user name field for multi-user mode.
+func (ts *TemplateService) RenderLogin(config *models.Config, next, errMsg string, askName bool) (string, error) {
+	theme := themes.AvailableThemes[config.Theme]
+	if theme == nil {
+		theme = themes.AvailableThemes["dark-orange"]
+	}
+
+	var templateHTML []byte
+	var err error
+	if ts.assets != nil {
+		templateHTML, err = ts.assets.ReadFile("web/templates/login.html")
+	} else {
+		templateHTML, err = os.ReadFile("web/templates/login.html")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	themedCSS, err := ts.getThemedCSS(theme.Colors)
+	if err != nil {
+		return "", err
+	}
+	data := map[string]interface{}{
+		"CSS":       template.CSS(themedCSS),
+		"URLPrefix": ts.urlPrefix,
+		"Next":      next,
+		"Error":     errMsg,
+		"AskName":   askName,
+	}
+	for key, value := range theme.Colors {
+		data[key] = value
+	}
+
+	tmpl, err := template.New("login").Parse(string(templateHTML))
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	gast "github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// TOCEntry is one heading of a note. ID is the heading's anchor on the
+// notes page.
+type TOCEntry struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id"`
+}
+
+// NoteTOC is the table of contents of one note.
+type NoteTOC struct {
+	Index   int        `json:"index"`
+	Title   string     `json:"title"`
+	Entries []TOCEntry `json:"entries"`
+}
+
+// idPrefixKey holds, in the parser context, the prefix for the ids of
+// headings and footnotes; notes on one page each get their own so their
+// anchors don't collide.
+var idPrefixKey = parser.NewContextKey()
+
+// idPrefixAttr carries the id prefix on the parsed document, where the
+// footnote extension can read it.
+const idPrefixAttr = "id-prefix"
+
+// headingIDs gives every heading an id made from its visible text, so a
+// heading holding a #tag or [[link]] gets a slug of what the reader sees
+// rather than of the markup that replaced it.
+type headingIDs struct{}
+
+func (headingIDs) Transform(doc *gast.Document, reader text.Reader, pc parser.Context) {
+	prefix, _ := pc.Get(idPrefixKey).(string)
+	doc.SetAttributeString(idPrefixAttr, []byte(prefix))
+	ids := parser.NewContext().IDs()
+	source := reader.Source()
+	gast.Walk(doc, func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if h, ok := n.(*gast.Heading); ok && entering {
+			id := ids.Generate([]byte(headingText(h, source)), gast.KindHeading)
+			h.SetAttributeString("id", []byte(prefix+string(id)))
+			return gast.WalkSkipChildren, nil
+		}
+		return gast.WalkContinue, nil
+	})
+}
+
+// footnoteIDPrefix reads the id prefix set by headingIDs.
+func footnoteIDPrefix(n gast.Node) []byte {
+	if v, ok := n.OwnerDocument().AttributeString(idPrefixAttr); ok {
+		return v.([]byte)
+	}
+	return nil
+}
+
+// headingText is the text of a heading without its inline HTML.
+func headingText(h *gast.Heading, source []byte) string {
+	var b strings.Builder
+	gast.Walk(h, func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if !entering {
+			return gast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *gast.RawHTML:
+			return gast.WalkSkipChildren, nil
+		case *gast.Text:
+			b.Write(n.Segment.Value(source))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *gast.String:
+			b.Write(n.Value)
+		}
+		return gast.WalkContinue, nil
+	})
+	return strings.TrimSpace(b.String())
+}
+
+// TableOfContents lists the headings of content with the ids they get when
+// it is rendered with idPrefix.
+func (r *MarkdownRenderer) TableOfContents(content, idPrefix string) []TOCEntry {
+	_, source := r.prepare(content)
+	doc := r.parse(source, idPrefix)
+	entries := []TOCEntry{}
+	gast.Walk(doc, func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if h, ok := n.(*gast.Heading); ok && entering {
+			id, _ := h.AttributeString("id")
+			entries = append(entries, TOCEntry{Level: h.Level, Text: headingText(h, source), ID: string(id.([]byte))})
+			return gast.WalkSkipChildren, nil
+		}
+		return gast.WalkContinue, nil
+	})
+	return entries
+}
+
+// noteIDPrefix is the prefix of the heading and footnote ids of the note
+// at index on the notes page.
+func noteIDPrefix(index int) string {
+	return fmt.Sprintf("note-%d-", index)
+}
+
+// NoteTOC returns the table of contents of the note at index.
+func (nm *NoteManager) NoteTOC(index int) (NoteTOC, error) {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	if index < 0 || index >= len(nm.notes) {
+		return NoteTOC{}, fmt.Errorf("note index %d out of range", index)
+	}
+	note := nm.notes[index]
+	return NoteTOC{Index: index, Title: note.Title, Entries: nm.renderer.TableOfContents(note.Content, noteIDPrefix(index))}, nil
+}
+
+// TOC returns the tables of contents of the notes that have headings,
+// newest first.
+func (nm *NoteManager) TOC() []NoteTOC {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
+	result := []NoteTOC{}
+	for i, note := range nm.notes {
+		if entries := nm.renderer.TableOfContents(note.Content, noteIDPrefix(i)); len(entries) > 0 {
+			result = append(result, NoteTOC{Index: i, Title: note.Title, Entries: entries})
+		}
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNoteTOC(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Plain", "no headings here"); err != nil {
+		t.Fatal(err)
+	}
+	content := "# Plan #release\n\nintro\n\n## Open `questions`\n\n```\n# not a heading\n```\n\n## Open questions\n"
+	if err := mgr.AddNote("Plan", content); err != nil {
+		t.Fatal(err)
+	}
+
+	toc, err := mgr.NoteTOC(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TOCEntry{
+		{Level: 1, Text: "Plan #release", ID: "note-0-plan-release"},
+		{Level: 2, Text: "Open questions", ID: "note-0-open-questions"},
+		{Level: 2, Text: "Open questions", ID: "note-0-open-questions-1"},
+	}
+	if !reflect.DeepEqual(toc.Entries, want) {
+		t.Errorf("entries = %+v", toc.Entries)
+	}
+
+	// The anchors must be the ids the page actually renders.
+	html, err := mgr.RenderNotesHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range want {
+		if !strings.Contains(html, `id="`+e.ID+`"`) {
+			t.Errorf("rendered notes lack id %s", e.ID)
+		}
+	}
+
+	if all := mgr.TOC(); len(all) != 1 || all[0].Index != 0 || all[0].Title != "Plan" {
+		t.Errorf("TOC = %+v", all)
+	}
+	if _, err := mgr.NoteTOC(5); err == nil {
+		t.Error("out-of-range index accepted")
+	}
+}
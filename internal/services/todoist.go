@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/todoist"
+)
+
+const defaultTodoistSyncInterval = 5 * time.Minute
+
+// TodoistService keeps a folder's tasks in step with a Todoist project.
+// The link between a task and its Todoist twin is the task URL appended to
+// the task line, the same convention the GitHub export uses; see
+// tasksync.go for the snapshot that drives the three-way merge.
+type TodoistService struct {
+	noteManager *NoteManager
+	client      *todoist.Client
+	folder      *models.TodoistFolderConfig
+	statePath   string
+	mu          sync.Mutex // serializes Sync runs
+	stop        chan struct{}
+}
+
+// NewTodoistService creates the service. Sync state is kept under stateDir
+// (normally ~/.config/noteflow/todoist) rather than in the folder, because
+// it is machine-specific and must not be committed.
+func NewTodoistService(noteManager *NoteManager, cfg models.TodoistConfig, folderCfg *models.FolderConfig, stateDir string) *TodoistService {
+	var folder *models.TodoistFolderConfig
+	if folderCfg != nil {
+		folder = folderCfg.Todoist
+	}
+	return &TodoistService{
+		noteManager: noteManager,
+		client:      todoist.NewClient(cfg.API, cfg.ResolvedToken()),
+		folder:      folder,
+		statePath:   syncStatePath(stateDir, noteManager.GetBasePath()),
+	}
+}
+
+// Enabled reports whether this folder syncs with a Todoist project.
+func (s *TodoistService) Enabled() bool {
+	return s.folder != nil && s.folder.ProjectID != ""
+}
+
+// Start runs Sync immediately and then on a ticker until Stop is called.
+func (s *TodoistService) Start() {
+	if !s.Enabled() || s.stop != nil {
+		return
+	}
+	interval := defaultTodoistSyncInterval
+	if s.folder.IntervalMinutes > 0 {
+		interval = time.Duration(s.folder.IntervalMinutes) * time.Minute
+	}
+	s.stop = make(chan struct{})
+	go func(stop <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			if _, err := s.Sync(ctx); err != nil {
+				log.Printf("Warning: Todoist sync failed: %v", err)
+			}
+			cancel()
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}(s.stop)
+}
+
+// Stop stops the background loop started by Start.
+func (s *TodoistService) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// Sync runs one two-way pass:
+//
+//   - unlinked, unchecked tasks (carrying #tag when one is configured) are
+//     created in the project and the task URL is appended to their line;
+//   - for linked tasks, a field edited on one side since the last sync is
+//     copied to the other; a field edited on both sides goes to whichever
+//     side changed most recently (notes.md mtime vs Todoist's updated_at);
+//   - completions travel both ways, including tasks completed in Todoist,
+//     which drop out of the open-task list.
+//
+// Tasks deleted in Todoist are left untouched in notes.md.
+func (s *TodoistService) Sync(ctx context.Context) (*TaskSyncResult, error) {
+	if !s.Enabled() {
+		return nil, fmt.Errorf("no Todoist project configured for this folder (set todoist.project_id in %s)", models.FolderConfigFile)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := loadSyncState[syncFields](s.statePath)
+	if err != nil {
+		return nil, err
+	}
+	open, err := s.client.ListTasks(ctx, s.folder.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	remoteByID := make(map[string]todoist.Task, len(open))
+	for _, t := range open {
+		remoteByID[t.ID] = t
+	}
+
+	res := &TaskSyncResult{}
+	localModified := s.noteManager.LastModified()
+	for _, task := range s.noteManager.GetAllTasks() {
+		id, linked := todoist.ParseTaskURL(task.Text)
+		if !linked {
+			if task.Checked || !hasTag(task, s.folder.Tag) {
+				continue
+			}
+			local := taskSyncFields(task, todoist.StripTaskURL)
+			created, err := s.client.CreateTask(ctx, s.folder.ProjectID, local.Content, local.Due)
+			if err != nil {
+				return res, err
+			}
+			if err := s.noteManager.UpdateTaskText(task.Index, strings.TrimRight(task.Text, " ")+" "+todoist.TaskURL(created.ID)); err != nil {
+				return res, err
+			}
+			state[created.ID] = local
+			res.Created++
+			continue
+		}
+
+		remote, ok := remoteByID[id]
+		if !ok {
+			// Not open any more: completed, or deleted.
+			t, err := s.client.GetTask(ctx, id)
+			if errors.Is(err, todoist.ErrNotFound) {
+				delete(state, id)
+				continue
+			}
+			if err != nil {
+				return res, err
+			}
+			remote = *t
+		}
+
+		local := taskSyncFields(task, todoist.StripTaskURL)
+		theirs := syncFields{Content: remote.Content, Due: remote.DueDate(), Checked: remote.Checked}
+		// With no snapshot (first sync on this machine) base is zero, so
+		// any difference is treated as a conflict and goes to the newer side.
+		base := state[id]
+		localWins := localModified.After(remote.UpdatedAt)
+		merged := syncFields{
+			Content: resolveField(local.Content, theirs.Content, base.Content, localWins),
+			Due:     resolveField(local.Due, theirs.Due, base.Due, localWins),
+			Checked: resolveField(local.Checked, theirs.Checked, base.Checked, localWins),
+		}
+
+		if merged.Content != theirs.Content || merged.Due != theirs.Due {
+			if err := s.client.UpdateTask(ctx, id, merged.Content, merged.Due); err != nil {
+				return res, err
+			}
+			res.Pushed++
+		}
+		if merged.Checked != theirs.Checked {
+			if err := s.client.SetChecked(ctx, id, merged.Checked); err != nil {
+				return res, err
+			}
+			res.Pushed++
+		}
+		if merged != local {
+			if merged.Content != local.Content || merged.Due != local.Due {
+				if err := s.noteManager.UpdateTaskText(task.Index, rewriteSyncedTask(task, merged, todoist.TaskURL(id))); err != nil {
+					return res, err
+				}
+			}
+			if merged.Checked != local.Checked {
+				if err := s.noteManager.UpdateTask(task.Index, merged.Checked); err != nil {
+					return res, err
+				}
+			}
+			res.Pulled++
+		}
+		state[id] = merged
+	}
+
+	return res, saveSyncState(s.statePath, state)
+}
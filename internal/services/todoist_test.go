@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/todoist"
+)
+
+// fakeTodoist is an in-memory Todoist project.
+type fakeTodoist struct {
+	mu    sync.Mutex
+	tasks map[string]*todoist.Task
+	next  int
+}
+
+func (f *fakeTodoist) server(t *testing.T) *httptest.Server {
+	f.tasks = make(map[string]*todoist.Task)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/") // tasks[/id[/action]]
+		switch {
+		case r.Method == http.MethodGet && len(parts) == 1:
+			var open []todoist.Task
+			for _, task := range f.tasks {
+				if !task.Checked {
+					open = append(open, *task)
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"results": open})
+		case r.Method == http.MethodGet:
+			task, ok := f.tasks[parts[1]]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(task)
+		case len(parts) == 1:
+			f.next++
+			task := &todoist.Task{ID: fmt.Sprintf("t%d", f.next), Content: body["content"], UpdatedAt: time.Now()}
+			if body["due_date"] != "" {
+				task.Due = &todoist.Due{Date: body["due_date"]}
+			}
+			f.tasks[task.ID] = task
+			json.NewEncoder(w).Encode(task)
+		case len(parts) == 2:
+			task := f.tasks[parts[1]]
+			task.Content = body["content"]
+			task.Due = nil
+			if body["due_date"] != "" {
+				task.Due = &todoist.Due{Date: body["due_date"]}
+			}
+			task.UpdatedAt = time.Now()
+			w.Write([]byte(`{}`))
+		default:
+			f.tasks[parts[1]].Checked = parts[2] == "close"
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// remoteEdit changes a task as if edited in the Todoist app at updatedAt.
+func (f *fakeTodoist) remoteEdit(id string, edit func(*todoist.Task), updatedAt time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	edit(f.tasks[id])
+	f.tasks[id].UpdatedAt = updatedAt
+}
+
+func TestTodoistSync_TwoWay(t *testing.T) {
+	fake := &fakeTodoist{}
+	srv := fake.server(t)
+
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("errands", "- [ ] buy milk @2026-10-20 #home\n- [ ] file taxes"); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewTodoistService(mgr, models.TodoistConfig{API: srv.URL, Token: "t"}, &models.FolderConfig{
+		Todoist: &models.TodoistFolderConfig{ProjectID: "p1", Tag: "home"},
+	}, t.TempDir())
+	ctx := context.Background()
+
+	// Push: only the #home task is created, and the line gets its link.
+	res, err := svc.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if res.Created != 1 || len(fake.tasks) != 1 {
+		t.Fatalf("created = %d, remote tasks = %d, want 1", res.Created, len(fake.tasks))
+	}
+	remote := fake.tasks["t1"]
+	if remote.Content != "buy milk" || remote.DueDate() != "2026-10-20" {
+		t.Errorf("remote = %+v", remote)
+	}
+	task, _ := mgr.GetTask(0)
+	if id, ok := todoist.ParseTaskURL(task.Text); !ok || id != "t1" {
+		t.Fatalf("task not linked: %q", task.Text)
+	}
+
+	// Pull: a rename and completion in Todoist land in notes.md, keeping
+	// local tags.
+	fake.remoteEdit("t1", func(task *todoist.Task) {
+		task.Content = "buy oat milk"
+		task.Checked = true
+	}, time.Now().Add(time.Hour))
+	if _, err := svc.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	task, _ = mgr.GetTask(0)
+	if !task.Checked || !strings.Contains(task.Text, "buy oat milk") || !strings.Contains(task.Text, "#home") {
+		t.Errorf("pulled task = %+v", task)
+	}
+
+	// Conflict: both sides renamed; the local edit is newer and wins.
+	if err := mgr.UpdateTaskText(0, strings.Replace(task.Text, "buy oat milk", "buy soy milk", 1)); err != nil {
+		t.Fatal(err)
+	}
+	fake.remoteEdit("t1", func(task *todoist.Task) { task.Content = "buy rice milk" }, time.Now().Add(-time.Hour))
+	if _, err := svc.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if fake.tasks["t1"].Content != "buy soy milk" {
+		t.Errorf("remote content = %q, want local edit to win", fake.tasks["t1"].Content)
+	}
+
+	// A further sync with nothing changed is a no-op.
+	res, err = svc.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if *res != (TaskSyncResult{}) {
+		t.Errorf("idle sync = %+v, want no changes", res)
+	}
+}
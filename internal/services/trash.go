@@ -0,0 +1,193 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// ErrTrashNotFound is returned for a trash ID that names no deleted note.
+var ErrTrashNotFound = errors.New("note not found in trash")
+
+// TrashEntry describes a deleted note in the trash listing.
+type TrashEntry struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"` // when the note was created
+	Deleted   time.Time `json:"deleted"`
+	// Expires is when auto-purge removes the note; absent when the
+	// folder keeps its trash forever.
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// SetTrashRetention sets how many days deleted notes stay in the trash.
+// Zero or less keeps them until they are purged by hand.
+func (nm *NoteManager) SetTrashRetention(days int) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.trashDays = days
+}
+
+// moveToTrash appends note to trash.md. Callers hold nm.mu.
+func (nm *NoteManager) moveToTrash(note *models.Note) error {
+	trash, err := nm.storage.LoadTrash()
+	if err != nil {
+		return err
+	}
+	trash = append(trash, models.TrashedNote{Note: note, Deleted: time.Now()})
+	if err := nm.storage.SaveTrash(trash); err != nil {
+		return fmt.Errorf("failed to save %s: %w", models.TrashFile, err)
+	}
+	return nil
+}
+
+// expired reports whether t is past the retention period at now.
+// Callers hold nm.mu.
+func (nm *NoteManager) expired(t models.TrashedNote, now time.Time) bool {
+	return nm.trashDays > 0 && now.Sub(t.Deleted) >= time.Duration(nm.trashDays)*24*time.Hour
+}
+
+// purge permanently removes the entries for which drop returns true,
+// along with their history, and returns how many went. Callers hold nm.mu.
+func (nm *NoteManager) purge(drop func(models.TrashedNote) bool) (int, error) {
+	trash, err := nm.storage.LoadTrash()
+	if err != nil {
+		return 0, err
+	}
+	kept := trash[:0]
+	var purged []models.TrashedNote
+	for _, t := range trash {
+		if drop(t) {
+			purged = append(purged, t)
+		} else {
+			kept = append(kept, t)
+		}
+	}
+	if len(purged) == 0 {
+		return 0, nil
+	}
+	if err := nm.storage.SaveTrash(kept); err != nil {
+		return 0, fmt.Errorf("failed to save %s: %w", models.TrashFile, err)
+	}
+	for _, t := range purged {
+		if err := nm.storage.DeleteHistory(t.ID()); err != nil {
+			log.Printf("Warning: failed to delete history of purged note: %v", err)
+		}
+	}
+	return len(purged), nil
+}
+
+// PurgeExpiredTrash permanently deletes notes that have been in the trash
+// longer than the retention period.
+func (nm *NoteManager) PurgeExpiredTrash() (int, error) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	now := time.Now()
+	return nm.purge(func(t models.TrashedNote) bool { return nm.expired(t, now) })
+}
+
+// ListTrash returns the deleted notes, most recently deleted first. Expired
+// notes are purged first so the listing never shows them.
+func (nm *NoteManager) ListTrash() ([]TrashEntry, error) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	now := time.Now()
+	if _, err := nm.purge(func(t models.TrashedNote) bool { return nm.expired(t, now) }); err != nil {
+		return nil, err
+	}
+	trash, err := nm.storage.LoadTrash()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]TrashEntry, 0, len(trash))
+	for _, t := range trash {
+		e := TrashEntry{
+			ID:        t.ID(),
+			Title:     t.Note.Title,
+			Content:   t.Note.Content,
+			Timestamp: t.Note.Timestamp,
+			Deleted:   t.Deleted,
+		}
+		if nm.trashDays > 0 {
+			expires := t.Deleted.AddDate(0, 0, nm.trashDays)
+			e.Expires = &expires
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Deleted.After(entries[j].Deleted) })
+	return entries, nil
+}
+
+// RestoreTrashedNote moves the note with id out of the trash and back into
+// notes.md at the place its creation time puts it, and returns its new
+// index.
+func (nm *NoteManager) RestoreTrashedNote(id string) (int, error) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.refresh()
+
+	trash, err := nm.storage.LoadTrash()
+	if err != nil {
+		return 0, err
+	}
+	pos := -1
+	for i, t := range trash {
+		if t.ID() == id {
+			pos = i
+			break
+		}
+	}
+	if pos < 0 {
+		return 0, ErrTrashNotFound
+	}
+	note := trash[pos].Note
+
+	// Notes are kept newest first.
+	index := sort.Search(len(nm.notes), func(i int) bool {
+		return !nm.notes[i].Timestamp.After(note.Timestamp)
+	})
+	nm.notes = append(nm.notes, nil)
+	copy(nm.notes[index+1:], nm.notes[index:])
+	nm.notes[index] = note
+	nm.assignTaskIndices()
+
+	nm.needsSave = true
+	if err := nm.save(); err != nil {
+		return 0, err
+	}
+	// The note is safely back in notes.md; a stale trash entry is only
+	// a duplicate, so log rather than fail.
+	if err := nm.storage.SaveTrash(append(trash[:pos], trash[pos+1:]...)); err != nil {
+		log.Printf("Warning: restored note is still in %s: %v", models.TrashFile, err)
+	}
+	return index, nil
+}
+
+// PurgeTrashedNote permanently deletes the note with id from the trash.
+func (nm *NoteManager) PurgeTrashedNote(id string) error {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	n, err := nm.purge(func(t models.TrashedNote) bool { return t.ID() == id })
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrTrashNotFound
+	}
+	return nil
+}
+
+// EmptyTrash permanently deletes every note in the trash and returns how
+// many there were.
+func (nm *NoteManager) EmptyTrash() (int, error) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	return nm.purge(func(models.TrashedNote) bool { return true })
+}
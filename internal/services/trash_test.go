@@ -0,0 +1,118 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestTrashDeleteRestorePurge(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"Old", "Middle", "New"} {
+		if err := mgr.AddNote(title, "- [ ] "+title); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Give the notes distinct creation times; AddNote stamps them all
+	// within the same second.
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	for i, note := range mgr.GetAllNotes() {
+		note.Timestamp = base.Add(time.Duration(-i) * time.Hour)
+	}
+
+	if err := mgr.DeleteNote(1); err != nil { // "Middle"
+		t.Fatal(err)
+	}
+	if n := len(mgr.GetAllNotes()); n != 2 {
+		t.Fatalf("%d notes after delete, want 2", n)
+	}
+	if _, err := os.Stat(filepath.Join(dir, models.TrashFile)); err != nil {
+		t.Fatalf("trash.md not written: %v", err)
+	}
+
+	// Reload from disk so the trash round-trips through trash.md.
+	mgr, err = NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trash, err := mgr.ListTrash()
+	if err != nil || len(trash) != 1 || trash[0].Title != "Middle" || trash[0].Expires == nil {
+		t.Fatalf("trash = %+v, %v", trash, err)
+	}
+
+	index, err := mgr.RestoreTrashedNote(trash[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes := mgr.GetAllNotes()
+	if index != 1 || len(notes) != 3 || notes[1].Title != "Middle" {
+		t.Fatalf("restored at %d, notes = %v", index, notes)
+	}
+	if tasks := mgr.GetAllTasks(); !strings.HasSuffix(tasks[1].Text, "Middle") || tasks[1].Index != 1 {
+		t.Errorf("task indices not reassigned: %+v", tasks)
+	}
+	if trash, _ := mgr.ListTrash(); len(trash) != 0 {
+		t.Errorf("trash after restore = %+v", trash)
+	}
+	if _, err := os.Stat(filepath.Join(dir, models.TrashFile)); !os.IsNotExist(err) {
+		t.Errorf("empty trash left trash.md behind: %v", err)
+	}
+
+	if err := mgr.DeleteNote(0); err != nil {
+		t.Fatal(err)
+	}
+	trash, _ = mgr.ListTrash()
+	if err := mgr.PurgeTrashedNote(trash[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.PurgeTrashedNote(trash[0].ID); !errors.Is(err, ErrTrashNotFound) {
+		t.Errorf("second purge: err = %v", err)
+	}
+	if _, err := mgr.RestoreTrashedNote("20200101-000000"); !errors.Is(err, ErrTrashNotFound) {
+		t.Errorf("restore unknown: err = %v", err)
+	}
+}
+
+func TestTrashAutoPurge(t *testing.T) {
+	mgr, err := NewNoteManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := models.NewNote("Old", "gone")
+	old.Timestamp = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := models.NewNote("Recent", "kept")
+	err = mgr.storage.SaveTrash([]models.TrashedNote{
+		{Note: old, Deleted: time.Now().AddDate(0, 0, -10)},
+		{Note: recent, Deleted: time.Now().AddDate(0, 0, -2)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mgr.SetTrashRetention(0)
+	if n, err := mgr.PurgeExpiredTrash(); err != nil || n != 0 {
+		t.Errorf("retention off: purged %d, %v", n, err)
+	}
+
+	mgr.SetTrashRetention(7)
+	if n, err := mgr.PurgeExpiredTrash(); err != nil || n != 1 {
+		t.Errorf("purged %d, %v; want 1", n, err)
+	}
+	trash, _ := mgr.ListTrash()
+	if len(trash) != 1 || trash[0].Title != "Recent" {
+		t.Errorf("trash = %+v", trash)
+	}
+
+	if n, err := mgr.EmptyTrash(); err != nil || n != 1 {
+		t.Errorf("EmptyTrash = %d, %v", n, err)
+	}
+}
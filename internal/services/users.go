@@ -0,0 +1,111 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// ErrUserNotFound is returned for an unknown user name or ID.
+var ErrUserNotFound = errors.New("no such user")
+
+// CreateUser adds an account. passwordHash comes from auth.HashPassword;
+// notesRoot should be an absolute path.
+func (ds *DatabaseService) CreateUser(name, passwordHash, notesRoot string) (*models.User, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("user name is empty")
+	}
+	result, err := ds.db.Exec(`INSERT INTO users (name, password_hash, notes_root) VALUES (?, ?, ?)`,
+		name, passwordHash, notesRoot)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return nil, fmt.Errorf("user %q already exists", name)
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return ds.GetUser(int(id))
+}
+
+// GetUser returns the user with the given ID.
+func (ds *DatabaseService) GetUser(id int) (*models.User, error) {
+	return ds.scanUser(ds.db.QueryRow(`SELECT id, name, notes_root, created FROM users WHERE id = ?`, id))
+}
+
+// GetUserByName returns the user with the given name, matched
+// case-insensitively.
+func (ds *DatabaseService) GetUserByName(name string) (*models.User, error) {
+	return ds.scanUser(ds.db.QueryRow(`SELECT id, name, notes_root, created FROM users WHERE name = ?`, name))
+}
+
+func (ds *DatabaseService) scanUser(row *sql.Row) (*models.User, error) {
+	var u models.User
+	if err := row.Scan(&u.ID, &u.Name, &u.NotesRoot, &u.Created); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+// UserPasswordHash returns the ID and stored password hash of the named
+// user, for auth.Authenticator.
+func (ds *DatabaseService) UserPasswordHash(name string) (int, string, error) {
+	var id int
+	var hash string
+	err := ds.db.QueryRow(`SELECT id, password_hash FROM users WHERE name = ?`, name).Scan(&id, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", ErrUserNotFound
+	}
+	return id, hash, err
+}
+
+// ListUsers returns every account in name order.
+func (ds *DatabaseService) ListUsers() ([]models.User, error) {
+	rows, err := ds.db.Query(`SELECT id, name, notes_root, created FROM users ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Name, &u.NotesRoot, &u.Created); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// SetUserPassword replaces the named user's password hash.
+func (ds *DatabaseService) SetUserPassword(name, passwordHash string) error {
+	return ds.execUser(`UPDATE users SET password_hash = ? WHERE name = ?`, passwordHash, name)
+}
+
+// DeleteUser removes the named user together with their registered
+// folders and synced tasks. Their notes on disk are left alone.
+func (ds *DatabaseService) DeleteUser(name string) error {
+	return ds.execUser(`DELETE FROM users WHERE name = ?`, name)
+}
+
+// execUser runs a statement on one user, reporting ErrUserNotFound when
+// it matched nothing.
+func (ds *DatabaseService) execUser(query string, args ...any) error {
+	result, err := ds.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrUserNotFound
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestUsers_CreateLookupDelete(t *testing.T) {
+	svc, _ := newTestDB(t)
+	u, err := svc.CreateUser("alice", "hash-a", "/srv/alice")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if u.ID == 0 || u.Name != "alice" || u.NotesRoot != "/srv/alice" || u.Created.IsZero() {
+		t.Fatalf("CreateUser returned %+v", u)
+	}
+	if _, err := svc.CreateUser("Alice", "x", "/srv/other"); err == nil {
+		t.Error("duplicate name differing only in case was accepted")
+	}
+
+	id, hash, err := svc.UserPasswordHash("ALICE")
+	if err != nil || id != u.ID || hash != "hash-a" {
+		t.Errorf("UserPasswordHash = %d, %q, %v", id, hash, err)
+	}
+	if err := svc.SetUserPassword("alice", "hash-b"); err != nil {
+		t.Fatalf("SetUserPassword: %v", err)
+	}
+	if _, hash, _ := svc.UserPasswordHash("alice"); hash != "hash-b" {
+		t.Errorf("password hash after change = %q", hash)
+	}
+
+	if err := svc.DeleteUser("alice"); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := svc.GetUser(u.ID); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("GetUser after delete: %v", err)
+	}
+	if err := svc.DeleteUser("alice"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("second DeleteUser: %v", err)
+	}
+}
+
+func TestForUser_ScopesFoldersAndTasks(t *testing.T) {
+	owner, ownerFolder := newTestDB(t)
+	alice, err := owner.CreateUser("alice", "h", "/srv/alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := owner.CreateUser("bob", "h", "/srv/bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	adb, bdb := owner.ForUser(alice.ID), owner.ForUser(bob.ID)
+
+	aliceFolder, err := adb.RegisterFolder("/srv/alice")
+	if err != nil {
+		t.Fatalf("RegisterFolder: %v", err)
+	}
+	if err := adb.SyncFolderTasks(aliceFolder.ID, []models.Task{{Text: "- [ ] alice's task"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := owner.SyncFolderTasks(ownerFolder.ID, []models.Task{{Text: "- [ ] owner's task"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		db   *DatabaseService
+		want string
+	}{
+		{"owner", owner, "- [ ] owner's task"},
+		{"alice", adb, "- [ ] alice's task"},
+	} {
+		resp, err := tc.db.QueryTasks(TaskFilter{})
+		if err != nil {
+			t.Fatalf("%s QueryTasks: %v", tc.name, err)
+		}
+		if len(resp.Tasks) != 1 || resp.Tasks[0].Content != tc.want {
+			t.Errorf("%s sees %+v, want only %q", tc.name, resp.Tasks, tc.want)
+		}
+	}
+
+	if folders, _ := bdb.GetActiveFolders(); len(folders) != 0 {
+		t.Errorf("bob sees folders %+v", folders)
+	}
+	if _, err := bdb.GetFolderByID(aliceFolder.ID); err == nil {
+		t.Error("bob can look up alice's folder")
+	}
+	if _, err := bdb.RegisterFolder("/srv/alice"); !errors.Is(err, ErrFolderOwned) {
+		t.Errorf("bob registering alice's folder: %v", err)
+	}
+	if _, err := owner.RegisterFolder("/srv/alice"); !errors.Is(err, ErrFolderOwned) {
+		t.Errorf("owner registering alice's folder: %v", err)
+	}
+
+	// Deleting a user takes their folders and tasks with them.
+	if err := owner.DeleteUser("alice"); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	owner.db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE folder_id = ?`, aliceFolder.ID).Scan(&n)
+	if n != 0 {
+		t.Errorf("%d tasks left after deleting their user", n)
+	}
+}
+
+func TestTaskRegistry_ForUserStaysInRoot(t *testing.T) {
+	svc, _ := newTestDB(t)
+	root := t.TempDir()
+	inside := filepath.Join(root, "project")
+	if err := os.Mkdir(inside, 0755); err != nil {
+		t.Fatal(err)
+	}
+	u, err := svc.CreateUser("alice", "h", root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trs := &TaskRegistryService{db: svc, noteManagers: map[string]*NoteManager{}, folderIDs: map[string]int{}, stopCh: make(chan struct{})}
+	user := trs.ForUser(u)
+	t.Cleanup(func() { user.Close() })
+
+	if _, err := user.AddFolderByPath(inside); err != nil {
+		t.Errorf("AddFolderByPath inside the root: %v", err)
+	}
+	if _, err := user.AddFolderByPath(t.TempDir()); err == nil {
+		t.Error("AddFolderByPath accepted a folder outside the root")
+	}
+	sibling := root + "-sibling"
+	if err := os.Mkdir(sibling, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(sibling) })
+	if _, err := user.AddFolderByPath(sibling); err == nil {
+		t.Error("AddFolderByPath accepted a sibling sharing the root's prefix")
+	}
+}
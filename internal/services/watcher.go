@@ -0,0 +1,195 @@
+package services
+
+import (
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/storage"
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce collapses the burst of events one save produces (editors
+// often truncate, write and rename) into a single sync.
+const watchDebounce = 50 * time.Millisecond
+
+// overdueCheckInterval is how often the overdue alert runs once the
+// watcher has taken over syncing; the alert itself fires once a day.
+const overdueCheckInterval = time.Minute
+
+// folderWatcher reports changes to the notes.md (or notes.db,
+// notes.md.enc or the notes directory's files) of watched folders. It
+// watches the folder rather than the file so saves that replace the file
+// (vim, git checkout) keep being seen.
+type folderWatcher struct {
+	fs       *fsnotify.Watcher
+	onChange func(folderPath string)
+
+	mu      sync.Mutex
+	folders map[string]bool
+	pending map[string]*time.Timer
+}
+
+func newFolderWatcher(onChange func(folderPath string)) (*folderWatcher, error) {
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &folderWatcher{
+		fs:       fs,
+		onChange: onChange,
+		folders:  make(map[string]bool),
+		pending:  make(map[string]*time.Timer),
+	}
+	go w.run()
+	return w, nil
+}
+
+// add starts watching folderPath; watching it twice is a no-op.
+func (w *folderWatcher) add(folderPath string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.folders[folderPath] {
+		return nil
+	}
+	if err := w.fs.Add(folderPath); err != nil {
+		return err
+	}
+	if storage.NewFileStorage(folderPath).Mode() == models.NotesStorageFiles {
+		// The notes are in a directory of their own. Failing to watch it
+		// leaves changes made there unnoticed until a restart, which is
+		// not worth refusing the folder over.
+		if err := w.fs.Add(filepath.Join(folderPath, storage.NotesDir)); err != nil {
+			log.Printf("Warning: file watcher: %v", err)
+		}
+	}
+	w.folders[folderPath] = true
+	return nil
+}
+
+// remove stops watching folderPath.
+func (w *folderWatcher) remove(folderPath string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.folders[folderPath] {
+		return
+	}
+	delete(w.folders, folderPath)
+	if t := w.pending[folderPath]; t != nil {
+		t.Stop()
+		delete(w.pending, folderPath)
+	}
+	w.fs.Remove(folderPath)
+	w.fs.Remove(filepath.Join(folderPath, storage.NotesDir))
+}
+
+func (w *folderWatcher) close() error {
+	return w.fs.Close()
+}
+
+func (w *folderWatcher) run() {
+	for {
+		select {
+		case ev, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			// An event on the folder itself means it was removed or moved.
+			folder := ev.Name
+			name, dir := filepath.Base(ev.Name), filepath.Dir(ev.Name)
+			switch {
+			case name == "notes.md" || name == storage.NotesDBFile ||
+				name == storage.EncryptedNotesFile:
+				folder = dir
+			case filepath.Base(dir) == storage.NotesDir &&
+				strings.EqualFold(filepath.Ext(name), ".md"):
+				folder = filepath.Dir(dir)
+			case ev.Op&(fsnotify.Remove|fsnotify.Rename) == 0:
+				continue
+			}
+			w.schedule(folder)
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Warning: file watcher: %v", err)
+		}
+	}
+}
+
+// schedule runs onChange for folder once events for it stop arriving.
+func (w *folderWatcher) schedule(folder string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.folders[folder] {
+		return
+	}
+	if t := w.pending[folder]; t != nil {
+		t.Reset(watchDebounce)
+		return
+	}
+	w.pending[folder] = time.AfterFunc(watchDebounce, func() {
+		w.mu.Lock()
+		delete(w.pending, folder)
+		w.mu.Unlock()
+		w.onChange(folder)
+	})
+}
+
+// folderChanged brings one folder's notes and DB rows up to date after its
+// notes.md changed on disk. A folder whose notes.md is gone is dropped
+// from the registry, as the old polling sync did.
+func (trs *TaskRegistryService) folderChanged(folderPath string) {
+	trs.mu.RLock()
+	noteManager, exists := trs.noteManagers[folderPath]
+	folderID, known := trs.folderIDs[folderPath]
+	trs.mu.RUnlock()
+	if !exists || !known {
+		return
+	}
+
+	if !trs.validateFolder(folderPath) {
+		log.Printf("Removing folder whose notes.md disappeared: %s", folderPath)
+		trs.watcher.remove(folderPath)
+		trs.mu.Lock()
+		delete(trs.noteManagers, folderPath)
+		delete(trs.folderIDs, folderPath)
+		trs.mu.Unlock()
+		if err := trs.db.RemoveFolder(folderID); err != nil {
+			log.Printf("Warning: failed to remove stale folder %d: %v", folderID, err)
+		}
+		return
+	}
+
+	if _, err := noteManager.ReloadIfChanged(); err != nil {
+		log.Printf("Warning: failed to reload %s: %v", folderPath, err)
+		return
+	}
+	// Sync even when the change was this process's own save: it is what
+	// gets UI edits into the DB.
+	if err := trs.syncFolderTasks(folderID, folderPath, noteManager); err != nil {
+		log.Printf("Warning: failed to sync folder %s: %v", folderPath, err)
+	}
+}
+
+// watchFolder starts watching a registered folder. Callers hold trs.mu.
+func (trs *TaskRegistryService) watchFolder(folderID int, folderPath string) {
+	trs.folderIDs[folderPath] = folderID
+	if trs.watcher == nil {
+		return
+	}
+	if err := trs.watcher.add(folderPath); err != nil {
+		log.Printf("Warning: cannot watch %s for changes: %v", folderPath, err)
+	}
+}
+
+// unwatchFolder stops watching a folder. Callers hold trs.mu.
+func (trs *TaskRegistryService) unwatchFolder(folderPath string) {
+	delete(trs.folderIDs, folderPath)
+	if trs.watcher != nil {
+		trs.watcher.remove(folderPath)
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExternalEditIsSyncedByWatcher(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	trs, err := NewTaskRegistryService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trs.Close()
+	if trs.watcher == nil {
+		t.Skip("file watching unavailable")
+	}
+
+	dir := t.TempDir()
+	mgr, err := NewNoteManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddNote("Plan", "- [ ] Write tests"); err != nil {
+		t.Fatal(err)
+	}
+	if err := trs.RegisterFolder(dir, mgr); err != nil {
+		t.Fatal(err)
+	}
+
+	// Edit the file behind the manager's back, the way an editor would:
+	// write a new file and rename it over notes.md.
+	notesPath := filepath.Join(dir, "notes.md")
+	data, err := os.ReadFile(notesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(data), "- [ ] Write tests", "- [x] Write tests\n- [ ] Ship", 1)
+	tmp := notesPath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, notesPath); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		global, err := trs.GetGlobalTasks()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(global.Tasks) == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("DB not updated after external edit: %+v", global.Tasks)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if tasks := mgr.GetAllTasks(); len(tasks) != 2 || !tasks[0].Checked {
+		t.Errorf("note manager not reloaded: %+v", tasks)
+	}
+
+	// A save by the manager itself must not be mistaken for an outside edit.
+	if err := mgr.AddNote("Later", "- [ ] Another"); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := mgr.ReloadIfChanged(); err != nil || changed {
+		t.Errorf("ReloadIfChanged after own save = %v, %v", changed, err)
+	}
+}
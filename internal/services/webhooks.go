@@ -0,0 +1,278 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	"github.com/Xafloc/NoteFlow-Go/internal/notify"
+)
+
+// EventTaskCompleted is sent to webhooks once for each task the task
+// registry sees completed, whether ticked in a note, on the global tasks
+// page or in an editor.
+const EventTaskCompleted = "task.completed"
+
+// webhookEvents are the event types webhooks can receive.
+var webhookEvents = map[string]bool{
+	EventNoteCreated:   true,
+	EventNoteUpdated:   true,
+	EventNoteDeleted:   true,
+	EventTaskCompleted: true,
+}
+
+// Webhook request headers.
+const (
+	WebhookEventHeader     = "X-NoteFlow-Event"
+	WebhookDeliveryHeader  = "X-NoteFlow-Delivery"
+	WebhookSignatureHeader = "X-NoteFlow-Signature"
+)
+
+// webhookQueue is how many payloads a hook may have waiting before new
+// ones are dropped, e.g. while its endpoint is down and being retried.
+const webhookQueue = 100
+
+// webhookRetryDelays are the waits before each retry of a failed delivery.
+var webhookRetryDelays = []time.Duration{time.Second, 10 * time.Second, time.Minute, 5 * time.Minute}
+
+// WebhookPayload is the JSON body POSTed to webhooks.
+type WebhookPayload struct {
+	Event string `json:"event"`
+	// Delivery identifies the payload; retries send the same one.
+	Delivery  string    `json:"delivery"`
+	Timestamp time.Time `json:"timestamp"`
+	Folder    string    `json:"folder"`
+	// NoteID and Title describe the note of note.* events.
+	NoteID string `json:"note_id,omitempty"`
+	Title  string `json:"title,omitempty"`
+	// Task is the task of task.completed: its task DB id and line.
+	Task *TaskChange `json:"task,omitempty"`
+}
+
+// WebhookService POSTs note and task events to the configured webhooks,
+// retrying failed deliveries with backoff. Each hook gets its payloads in
+// order.
+type WebhookService struct {
+	hooks       []*webhook
+	client      *http.Client
+	retryDelays []time.Duration
+	notifier    notify.Notifier // optional; alerts when a delivery is given up
+	done        chan struct{}   // closed when the watched hub closes
+}
+
+type webhook struct {
+	cfg    models.WebhookConfig
+	name   string          // webhooks[i] (host), shown instead of the URL and its tokens
+	events map[string]bool // nil: every event
+	queue  chan WebhookPayload
+}
+
+// NewWebhookService checks the webhook config. It returns (nil, nil) when
+// no webhooks are configured.
+func NewWebhookService(cfgs []models.WebhookConfig) (*WebhookService, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+	s := &WebhookService{
+		client:      &http.Client{Timeout: 10 * time.Second},
+		retryDelays: webhookRetryDelays,
+		done:        make(chan struct{}),
+	}
+	for i, cfg := range cfgs {
+		u, err := url.Parse(cfg.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("webhooks[%d]: url must be an http(s) URL", i)
+		}
+		h := &webhook{
+			cfg:   cfg,
+			name:  fmt.Sprintf("webhooks[%d] (%s)", i, u.Host),
+			queue: make(chan WebhookPayload, webhookQueue),
+		}
+		for _, e := range cfg.Events {
+			if !webhookEvents[e] {
+				return nil, fmt.Errorf("webhooks[%d]: unknown event %q", i, e)
+			}
+			if h.events == nil {
+				h.events = make(map[string]bool)
+			}
+			h.events[e] = true
+		}
+		s.hooks = append(s.hooks, h)
+	}
+	return s, nil
+}
+
+// SetNotifier enables an alert for each delivery given up after its
+// retries.
+func (s *WebhookService) SetNotifier(n notify.Notifier) {
+	s.notifier = n
+}
+
+// Watch starts delivering the events published on hub. Deliveries stop
+// when the hub is closed.
+func (s *WebhookService) Watch(hub *EventHub) {
+	events, _ := hub.Subscribe()
+	for _, h := range s.hooks {
+		go s.deliverAll(h)
+	}
+	go func() {
+		defer close(s.done)
+		for e := range events {
+			for _, p := range webhookPayloads(e, time.Now()) {
+				s.enqueue(p)
+			}
+		}
+	}()
+}
+
+// webhookPayloads turns a hub event into the payloads webhooks receive:
+// note.* events as they are, and one task.completed for each task a
+// tasks.changed completed.
+func webhookPayloads(e Event, now time.Time) []WebhookPayload {
+	switch e.Type {
+	case EventNoteCreated, EventNoteUpdated, EventNoteDeleted:
+		return []WebhookPayload{{Event: e.Type, Timestamp: now, Folder: e.Folder, NoteID: e.NoteID, Title: e.Title}}
+	case EventTasksChanged:
+		if e.Changes == nil {
+			return nil
+		}
+		var out []WebhookPayload
+		for _, t := range e.Changes.Completed {
+			out = append(out, WebhookPayload{Event: EventTaskCompleted, Timestamp: now, Folder: e.Folder, Task: &t})
+		}
+		return out
+	}
+	return nil
+}
+
+func (s *WebhookService) enqueue(p WebhookPayload) {
+	p.Delivery = newDeliveryID()
+	for _, h := range s.hooks {
+		if h.events != nil && !h.events[p.Event] {
+			continue
+		}
+		select {
+		case h.queue <- p:
+		default:
+			log.Printf("Warning: %s is %d deliveries behind; dropped %s", h.name, webhookQueue, p.Event)
+		}
+	}
+}
+
+// deliverAll sends h's payloads one at a time until the hub closes.
+func (s *WebhookService) deliverAll(h *webhook) {
+	for {
+		select {
+		case p := <-h.queue:
+			s.deliver(h, p)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// deliver POSTs p to h, retrying after network errors, 429s and 5xx
+// responses. Other responses are final.
+func (s *WebhookService) deliver(h *webhook, p WebhookPayload) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		log.Printf("Warning: webhook payload: %v", err)
+		return
+	}
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(h, p, body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt == len(s.retryDelays) {
+			log.Printf("Warning: %s: gave up on %s %s: %v", h.name, p.Event, p.Delivery, err)
+			s.alertFailure(h, p, err)
+			return
+		}
+		select {
+		case <-time.After(s.retryDelays[attempt]):
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// post makes one delivery attempt and reports whether a failure is worth
+// retrying.
+func (s *WebhookService) post(h *webhook, p WebhookPayload, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, h.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "NoteFlow-Webhook")
+	req.Header.Set(WebhookEventHeader, p.Event)
+	req.Header.Set(WebhookDeliveryHeader, p.Delivery)
+	if h.cfg.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, WebhookSignature(h.cfg.Secret, body))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// A *url.Error quotes the URL; keep only the cause.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return true, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %s", resp.Status)
+	default:
+		return false, fmt.Errorf("status %s", resp.Status)
+	}
+}
+
+// alertFailure pushes a failed-webhook alert on its own goroutine.
+func (s *WebhookService) alertFailure(h *webhook, p WebhookPayload, deliveryErr error) {
+	n := s.notifier
+	if n == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		msg := notify.Message{
+			Title: "NoteFlow: webhook failed",
+			Body:  fmt.Sprintf("%s\n%s: %v", h.name, p.Event, deliveryErr),
+			Tags:  []string{"warning"},
+		}
+		if err := n.Notify(ctx, msg); err != nil {
+			log.Printf("Warning: webhook-failure notification failed: %v", err)
+		}
+	}()
+}
+
+// WebhookSignature is the X-NoteFlow-Signature of body under secret:
+// "sha256=" and the hex HMAC-SHA256. Receivers recompute it over the raw
+// body and compare in constant time.
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// hookServer records the requests it gets, answering each with the next
+// of statuses (200 once they run out).
+type hookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	got      []*http.Request
+	bodies   [][]byte
+	arrived  chan struct{}
+}
+
+func newHookServer(t *testing.T, statuses ...int) *hookServer {
+	s := &hookServer{statuses: statuses, arrived: make(chan struct{}, 100)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.got = append(s.got, r)
+		s.bodies = append(s.bodies, body)
+		status := http.StatusOK
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		s.mu.Unlock()
+		w.WriteHeader(status)
+		s.arrived <- struct{}{}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// wait blocks until n requests have arrived.
+func (s *hookServer) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.arrived:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d of %d webhook requests", i, n)
+		}
+	}
+}
+
+func TestNewWebhookService_ChecksConfig(t *testing.T) {
+	if s, err := NewWebhookService(nil); s != nil || err != nil {
+		t.Errorf("no webhooks: got %v, %v", s, err)
+	}
+	for _, cfg := range []models.WebhookConfig{
+		{URL: "ftp://example.com/hook"},
+		{URL: "/relative"},
+		{URL: "https://example.com/hook", Events: []string{"note.exploded"}},
+	} {
+		if _, err := NewWebhookService([]models.WebhookConfig{cfg}); err == nil {
+			t.Errorf("%+v accepted", cfg)
+		}
+	}
+}
+
+func TestWebhookService_HidesHookURLs(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	s, err := NewWebhookService([]models.WebhookConfig{{URL: down.URL + "/hooks/s3cret-token"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := s.hooks[0]
+	if strings.Contains(h.name, "s3cret") || !strings.HasPrefix(h.name, "webhooks[0] (127.0.0.1:") {
+		t.Errorf("name = %q", h.name)
+	}
+	_, err = s.post(h, WebhookPayload{Event: EventNoteCreated}, []byte("{}"))
+	if err == nil || strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("post error = %v, want one without the URL", err)
+	}
+}
+
+func TestWebhookService_DeliversSignedPayloads(t *testing.T) {
+	all := newHookServer(t)
+	tasks := newHookServer(t)
+	s, err := NewWebhookService([]models.WebhookConfig{
+		{URL: all.URL},
+		{URL: tasks.URL, Secret: "s3cret", Events: []string{EventTaskCompleted}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := NewEventHub()
+	defer hub.Close()
+	s.Watch(hub)
+
+	hub.Publish(Event{Type: EventNoteCreated, Folder: "/notes", NoteID: "n1", Title: "Plan"})
+	hub.Publish(Event{Type: EventTasksSynced, Folder: "/notes"}) // not a webhook event
+	hub.Publish(Event{Type: EventTasksChanged, Folder: "/notes", Changes: &TaskDiff{
+		Completed: []TaskChange{{ID: 1, Content: "- [x] a"}, {ID: 2, Content: "- [x] b"}},
+		Reopened:  []TaskChange{{ID: 3, Content: "- [ ] c"}},
+	}})
+
+	all.wait(t, 3)
+	tasks.wait(t, 2)
+
+	var first WebhookPayload
+	if err := json.Unmarshal(all.bodies[0], &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Event != EventNoteCreated || first.NoteID != "n1" || first.Title != "Plan" || first.Delivery == "" {
+		t.Errorf("note payload %+v", first)
+	}
+	if all.got[0].Header.Get(WebhookSignatureHeader) != "" {
+		t.Error("signed without a secret")
+	}
+
+	for i, r := range tasks.got {
+		if r.Header.Get(WebhookEventHeader) != EventTaskCompleted {
+			t.Errorf("event header %q", r.Header.Get(WebhookEventHeader))
+		}
+		if got, want := r.Header.Get(WebhookSignatureHeader), WebhookSignature("s3cret", tasks.bodies[i]); got != want {
+			t.Errorf("signature %q, want %q", got, want)
+		}
+		var p WebhookPayload
+		if err := json.Unmarshal(tasks.bodies[i], &p); err != nil {
+			t.Fatal(err)
+		}
+		if p.Task == nil || p.Task.ID != i+1 {
+			t.Errorf("task payload %+v", p)
+		}
+	}
+}
+
+func TestWebhookService_RetriesServerErrors(t *testing.T) {
+	flaky := newHookServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	rejecting := newHookServer(t, http.StatusBadRequest)
+	s, err := NewWebhookService([]models.WebhookConfig{{URL: flaky.URL}, {URL: rejecting.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.retryDelays = []time.Duration{time.Millisecond, time.Millisecond}
+	hub := NewEventHub()
+	defer hub.Close()
+	s.Watch(hub)
+
+	hub.Publish(Event{Type: EventNoteDeleted, NoteID: "n1"})
+	flaky.wait(t, 3)
+	rejecting.wait(t, 1)
+	if d := flaky.got[0].Header.Get(WebhookDeliveryHeader); d == "" || flaky.got[2].Header.Get(WebhookDeliveryHeader) != d {
+		t.Error("retries changed the delivery ID")
+	}
+
+	// A 4xx is final: a second event is the next request.
+	hub.Publish(Event{Type: EventNoteDeleted, NoteID: "n2"})
+	rejecting.wait(t, 1)
+	var p WebhookPayload
+	json.Unmarshal(rejecting.bodies[1], &p)
+	if p.NoteID != "n2" {
+		t.Errorf("second request was %+v, want the next event", p)
+	}
+}
@@ -0,0 +1,482 @@
+// Package spell checks note text against a bundled word list so the editor
+// can flag mistakes without downloading a dictionary. It is deliberately
+// small: a list of base words plus suffix and prefix rules, not a full
+// Hunspell implementation. The rules accept some non-words ("runned"), which
+// is the right trade-off for underlining likely typos.
+package spell
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+//go:embed dict/*.txt
+var dictFS embed.FS
+
+// DefaultLanguage is used when a folder doesn't pick one.
+const DefaultLanguage = "en"
+
+// maxSuggested bounds how many misspellings get suggestions in one Check.
+// Suggestions are the expensive part; past this the editor still gets the
+// ranges, just with empty suggestion lists.
+const maxSuggested = 100
+
+// maxSuggestions is the length of each suggestion list.
+const maxSuggestions = 5
+
+var (
+	dictMu sync.Mutex
+	dicts  = map[string]map[string]bool{}
+)
+
+// Languages returns the bundled dictionary names.
+func Languages() []string {
+	entries, _ := dictFS.ReadDir("dict")
+	var langs []string
+	for _, e := range entries {
+		langs = append(langs, strings.TrimSuffix(e.Name(), ".txt"))
+	}
+	return langs
+}
+
+// loadDict parses a bundled dictionary once and caches it.
+func loadDict(lang string) (map[string]bool, error) {
+	dictMu.Lock()
+	defer dictMu.Unlock()
+	if d, ok := dicts[lang]; ok {
+		return d, nil
+	}
+	f, err := dictFS.Open("dict/" + lang + ".txt")
+	if err != nil {
+		return nil, fmt.Errorf("no dictionary for language %q (have %s)", lang, strings.Join(Languages(), ", "))
+	}
+	defer f.Close()
+	d := make(map[string]bool)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		w := strings.TrimSpace(sc.Text())
+		if w != "" && !strings.HasPrefix(w, "#") {
+			d[w] = true
+		}
+	}
+	dicts[lang] = d
+	return d, sc.Err()
+}
+
+// Checker checks text against one language's dictionary plus a list of
+// custom words. It is safe for concurrent use.
+type Checker struct {
+	dict   map[string]bool
+	custom map[string]bool
+}
+
+// New returns a checker for lang ("" means DefaultLanguage) that also
+// accepts custom words. Custom words match case-insensitively.
+func New(lang string, custom []string) (*Checker, error) {
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+	d, err := loadDict(lang)
+	if err != nil {
+		return nil, err
+	}
+	c := &Checker{dict: d, custom: make(map[string]bool, len(custom))}
+	for _, w := range custom {
+		c.custom[normalize(w)] = true
+	}
+	return c, nil
+}
+
+// Misspelling is one unknown word. Start and End are offsets into the
+// checked text in UTF-16 code units, i.e. JavaScript string indices, so the
+// editor can use them on its textarea value directly.
+type Misspelling struct {
+	Word        string   `json:"word"`
+	Start       int      `json:"start"`
+	End         int      `json:"end"`
+	Suggestions []string `json:"suggestions"`
+}
+
+var (
+	wordRE = regexp.MustCompile(`\p{L}+(?:['’-]\p{L}+)*`)
+
+	// skipRE matches markup whose letters are not prose: URLs, e-mail
+	// addresses, link targets, HTML tags, wiki links and NoteFlow's task
+	// tokens (#tag, @date, !pN).
+	skipRE = regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://\S+|www\.\S+|[\w.+-]+@[\w-]+\.[\w.-]+|\]\([^)]*\)|<[^>\n]+>|\[\[[^\]\n]*\]\]|(?:^|\s)[#@!]\S+`)
+
+	// lineStartRE is what may precede the first word of a sentence on a
+	// line: list bullets, numbers, headings, quotes and checkboxes.
+	lineStartRE = regexp.MustCompile(`^[\s>*+#-]*(?:\d+[.)]\s*)?(?:\[[ xX]\]\s*)?[*_"'(“‘]*$`)
+)
+
+// Check returns the misspelled words in text, in order. Code blocks, inline
+// code and the markup matched by skipRE are ignored, as are words that look
+// like identifiers or acronyms (digits, underscores, inner capitals, all
+// caps) and capitalized words in mid-sentence, which are usually names.
+func (c *Checker) Check(text string) []Misspelling {
+	skip := models.CodeRanges(text)
+	for _, m := range skipRE.FindAllStringIndex(text, -1) {
+		skip = append(skip, [2]int{m[0], m[1]})
+	}
+
+	var out []Misspelling
+	pos := newUTF16Counter(text)
+	for _, m := range wordRE.FindAllStringIndex(text, -1) {
+		start, end := m[0], m[1]
+		if inRanges(start, skip) || partOfIdentifier(text, start, end) {
+			continue
+		}
+		word := text[start:end]
+		if c.accept(word, sentenceStart(text, start)) {
+			continue
+		}
+		var suggestions []string
+		if len(out) < maxSuggested {
+			suggestions = c.Suggest(word)
+		}
+		out = append(out, Misspelling{
+			Word:        word,
+			Start:       pos.at(start),
+			End:         pos.at(end),
+			Suggestions: suggestions,
+		})
+	}
+	return out
+}
+
+// accept reports whether word should go unflagged.
+func (c *Checker) accept(word string, atSentenceStart bool) bool {
+	if utf8.RuneCountInString(word) < 2 || c.custom[normalize(word)] {
+		return true
+	}
+	switch caseOf(word) {
+	case upperCase, mixedCase:
+		return true
+	case titleCase:
+		if c.Known(word) {
+			return true
+		}
+		return !atSentenceStart
+	}
+	return c.Known(word)
+}
+
+// Known reports whether word is in the dictionary, the custom list, or
+// derives from one of them by the inflection rules. Hyphenated words are
+// known when every part is (a leading part may also be a prefix like "re").
+func (c *Checker) Known(word string) bool {
+	w := normalize(word)
+	if c.custom[w] {
+		return true
+	}
+	if strings.Contains(w, "-") {
+		parts := strings.Split(w, "-")
+		for i, p := range parts {
+			if !c.known(p, 2) && !(i < len(parts)-1 && isPrefix(p)) {
+				return false
+			}
+		}
+		return true
+	}
+	return c.known(w, 2)
+}
+
+func (c *Checker) has(w string) bool {
+	return c.dict[w] || c.custom[w]
+}
+
+// suffixRules maps an ending to the endings its stem may have had. "" means
+// the ending was simply appended; a doubled final consonant ("stopped") is
+// handled separately in known.
+var suffixRules = []struct {
+	suffix string
+	stems  []string
+}{
+	{"'s", []string{""}},
+	{"s'", []string{"s"}},
+	{"ies", []string{"y"}},
+	{"es", []string{""}},
+	{"s", []string{""}},
+	{"ied", []string{"y"}},
+	{"ed", []string{"", "e"}},
+	{"ying", []string{"ie", "y"}},
+	{"ing", []string{"", "e"}},
+	{"ier", []string{"y"}},
+	{"er", []string{"", "e"}},
+	{"iest", []string{"y"}},
+	{"est", []string{"", "e"}},
+	{"ily", []string{"y"}},
+	{"ly", []string{"", "le"}},
+	{"iness", []string{"y"}},
+	{"ness", []string{""}},
+	{"ment", []string{""}},
+	{"ful", []string{""}},
+	{"less", []string{""}},
+	{"able", []string{"", "e"}},
+	{"ation", []string{"e", ""}},
+	{"ization", []string{"ize"}},
+	{"ity", []string{"", "e"}},
+	{"ize", []string{"", "e"}},
+}
+
+var prefixes = []string{"anti", "auto", "co", "de", "dis", "inter", "mis", "multi", "non", "out", "over", "pre", "re", "self", "sub", "un", "under"}
+
+func isPrefix(p string) bool {
+	for _, x := range prefixes {
+		if p == x {
+			return true
+		}
+	}
+	return false
+}
+
+// known applies up to depth rounds of suffix and prefix stripping, which
+// covers "carefully" (care+ful+ly) and "unfinished" (un+finish+ed).
+func (c *Checker) known(w string, depth int) bool {
+	if c.has(w) {
+		return true
+	}
+	if depth == 0 {
+		return false
+	}
+	for _, r := range suffixRules {
+		stem, ok := strings.CutSuffix(w, r.suffix)
+		if !ok || len(stem) < 2 {
+			continue
+		}
+		for _, end := range r.stems {
+			if c.known(stem+end, depth-1) {
+				return true
+			}
+		}
+		// stopped, running, bigger: the stem doubled its last consonant.
+		if n := len(stem); n >= 3 && stem[n-1] == stem[n-2] && !strings.ContainsRune("aeiou", rune(stem[n-1])) &&
+			c.known(stem[:n-1], depth-1) {
+			return true
+		}
+	}
+	for _, p := range prefixes {
+		if rest, ok := strings.CutPrefix(w, p); ok && len(rest) >= 3 && c.known(rest, depth-1) {
+			return true
+		}
+	}
+	return false
+}
+
+const letters = "abcdefghijklmnopqrstuvwxyz'"
+
+// Suggest returns up to five likely intended spellings of word: known
+// words one edit away, or else two edits away, closest first. The result
+// follows word's capitalization.
+func (c *Checker) Suggest(word string) []string {
+	w := normalize(word)
+	found := map[string]int{}
+	ones := edits(w)
+	for _, e := range ones {
+		if e != w && c.Known(e) {
+			found[e] = 1
+		}
+	}
+	if len(found) == 0 && utf8.RuneCountInString(w) <= 12 {
+		for _, e1 := range ones {
+			for _, e2 := range edits(e1) {
+				if _, ok := found[e2]; !ok && e2 != w && c.has(e2) {
+					found[e2] = 2
+				}
+			}
+		}
+	}
+
+	candidates := make([]string, 0, len(found))
+	for s := range found {
+		candidates = append(candidates, s)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if found[a] != found[b] {
+			return found[a] < found[b]
+		}
+		// Typos rarely change the first letter or the length.
+		if sa, sb := a[0] == w[0], b[0] == w[0]; sa != sb {
+			return sa
+		}
+		if da, db := abs(len(a)-len(w)), abs(len(b)-len(w)); da != db {
+			return da < db
+		}
+		return a < b
+	})
+	if len(candidates) > maxSuggestions {
+		candidates = candidates[:maxSuggestions]
+	}
+
+	suggestions := make([]string, 0, len(candidates))
+	title := caseOf(word) == titleCase
+	for _, s := range candidates {
+		if title {
+			r, size := utf8.DecodeRuneInString(s)
+			s = string(unicode.ToUpper(r)) + s[size:]
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions
+}
+
+// edits returns every string one deletion, transposition, substitution or
+// insertion away from w (Norvig's edits1). Only ASCII letters are tried,
+// matching the bundled dictionaries.
+func edits(w string) []string {
+	var out []string
+	for i := 0; i <= len(w); i++ {
+		l, r := w[:i], w[i:]
+		if r != "" {
+			out = append(out, l+r[1:])
+		}
+		if len(r) > 1 {
+			out = append(out, l+string(r[1])+string(r[0])+r[2:])
+		}
+		for j := 0; j < len(letters); j++ {
+			ch := string(letters[j])
+			if r != "" {
+				out = append(out, l+ch+r[1:])
+			}
+			out = append(out, l+ch+r)
+		}
+	}
+	return out
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// normalize lowercases w and straightens typographic apostrophes.
+func normalize(w string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(w), "’", "'"))
+}
+
+type wordCase int
+
+const (
+	lowerCase wordCase = iota
+	titleCase          // "Berlin"
+	upperCase          // "HTTP"
+	mixedCase          // "iPhone", "NoteFlow"
+)
+
+func caseOf(word string) wordCase {
+	upper, lower, first := 0, 0, true
+	firstUpper := false
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if unicode.IsUpper(r) {
+			upper++
+			if first {
+				firstUpper = true
+			}
+		} else {
+			lower++
+		}
+		first = false
+	}
+	switch {
+	case upper == 0:
+		return lowerCase
+	case lower == 0:
+		return upperCase
+	case upper == 1 && firstUpper:
+		return titleCase
+	}
+	return mixedCase
+}
+
+// partOfIdentifier reports whether the word at text[start:end] is glued to
+// digits, underscores, slashes or dots, as in "v2", "file.go" or "src/app".
+func partOfIdentifier(text string, start, end int) bool {
+	glue := func(r rune) bool {
+		return unicode.IsDigit(r) || strings.ContainsRune(`_/\=`, r)
+	}
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(text[:start])
+		if glue(r) {
+			return true
+		}
+		if r == '.' && start > 1 {
+			p, _ := utf8.DecodeLastRuneInString(text[:start-1])
+			if unicode.IsLetter(p) || unicode.IsDigit(p) {
+				return true
+			}
+		}
+	}
+	if end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if glue(r) {
+			return true
+		}
+		if r == '.' && end+size < len(text) {
+			n, _ := utf8.DecodeRuneInString(text[end+size:])
+			if unicode.IsLetter(n) || unicode.IsDigit(n) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sentenceStart reports whether the word at start begins a sentence: it is
+// the first word on its line (after any list or heading marker) or follows
+// ".", "!", "?" or ":".
+func sentenceStart(text string, start int) bool {
+	lineStart := strings.LastIndexByte(text[:start], '\n') + 1
+	before := text[lineStart:start]
+	if lineStartRE.MatchString(before) {
+		return true
+	}
+	before = strings.TrimRight(before, " \t*_\"'(“‘")
+	return strings.HasSuffix(before, ".") || strings.HasSuffix(before, "!") ||
+		strings.HasSuffix(before, "?") || strings.HasSuffix(before, ":")
+}
+
+func inRanges(pos int, ranges [][2]int) bool {
+	for _, r := range ranges {
+		if pos >= r[0] && pos < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// utf16Counter converts increasing byte offsets in s to UTF-16 offsets
+// without rescanning from the start each time.
+type utf16Counter struct {
+	s            string
+	byteOff, u16 int
+}
+
+func newUTF16Counter(s string) *utf16Counter {
+	return &utf16Counter{s: s}
+}
+
+func (c *utf16Counter) at(byteOff int) int {
+	for c.byteOff < byteOff {
+		r, size := utf8.DecodeRuneInString(c.s[c.byteOff:])
+		c.u16 += utf16.RuneLen(r)
+		c.byteOff += size
+	}
+	return c.u16
+}
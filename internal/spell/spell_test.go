@@ -0,0 +1,102 @@
+package spell
+
+import (
+	"reflect"
+	"testing"
+)
+
+func words(ms []Misspelling) []string {
+	var out []string
+	for _, m := range ms {
+		out = append(out, m.Word)
+	}
+	return out
+}
+
+func TestCheckFlagsTypos(t *testing.T) {
+	c, err := New("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := words(c.Check("We recieve the reports every mornign and review them carefully."))
+	want := []string{"recieve", "mornign"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check = %v, want %v", got, want)
+	}
+}
+
+func TestCheckAcceptsInflections(t *testing.T) {
+	c, _ := New("", nil)
+	text := "The children stopped running; the bigger boxes were unfinished, the cities happily rebuilt and the team's reviewers re-enabled it."
+	if got := c.Check(text); len(got) != 0 {
+		t.Errorf("unexpected misspellings: %v", words(got))
+	}
+}
+
+func TestCheckSkipsMarkup(t *testing.T) {
+	c, _ := New("", nil)
+	text := "- [ ] Deploy the fix #projx/wbsite @2026-10-20 !p1\n" +
+		"See https://exmaple.com/foo and `fmt.Sprntf` and [[Meeting Nots]].\n" +
+		"```\nfunc mian() {}\n```\n" +
+		"Ask Zanzibar about the HTTPX v2 config_file in src/cmd and NoteFlow's main.go."
+	if got := c.Check(text); len(got) != 0 {
+		t.Errorf("unexpected misspellings: %v", words(got))
+	}
+}
+
+func TestCheckFlagsCapitalizedAtSentenceStart(t *testing.T) {
+	c, _ := New("", nil)
+	got := words(c.Check("Teh plan works. Recieve it.\n- Wrok on it"))
+	want := []string{"Teh", "Recieve", "Wrok"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check = %v, want %v", got, want)
+	}
+}
+
+func TestCustomWords(t *testing.T) {
+	c, _ := New("en", []string{"Kubectl", "grafana"})
+	if got := c.Check("run kubectl and open grafana"); len(got) != 0 {
+		t.Errorf("custom words flagged: %v", words(got))
+	}
+}
+
+func TestOffsetsAreUTF16(t *testing.T) {
+	c, _ := New("", nil)
+	text := "“Hi” 😀 speling"
+	got := c.Check(text)
+	if len(got) != 1 {
+		t.Fatalf("Check = %v", words(got))
+	}
+	// "“Hi” " is 5 code units, the emoji 2, the space 1.
+	if got[0].Start != 8 || got[0].End != 15 {
+		t.Errorf("offsets = %d..%d, want 8..15", got[0].Start, got[0].End)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	c, _ := New("", nil)
+	tests := map[string]string{
+		"recieve": "receive",
+		"speling": "spelling",
+		"Teh":     "The",
+		"meetign": "meeting",
+	}
+	for word, want := range tests {
+		got := c.Suggest(word)
+		found := false
+		for _, s := range got {
+			if s == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Suggest(%q) = %v, want it to include %q", word, got, want)
+		}
+	}
+}
+
+func TestUnknownLanguage(t *testing.T) {
+	if _, err := New("xx", nil); err == nil {
+		t.Error("expected an error for a language without a dictionary")
+	}
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// archiveBannerRE matches the comment the archiver puts above the banner
+// of every HTML archive; archives saved before metadata files existed
+// still name their URL there.
+var archiveBannerRE = regexp.MustCompile(`<!-- ARCHIVED PAGE - Original URL: (\S+) - Archived: ([0-9-]+ [0-9:]+) -->`)
+
+// archiveHeadBytes is how much of an archive is scanned for its banner.
+const archiveHeadBytes = 64 << 10
+
+// ArchiveFormat names the format of the archive filename: "html", "pdf",
+// "mhtml" or "warc".
+func ArchiveFormat(filename string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(ArchiveExt(filename), "."), ".gz")
+}
+
+// archiveMetaPath is the metadata file of the archive filename.
+func (fs *FileStorage) archiveMetaPath(filename string) string {
+	return filepath.Join(fs.BasePath, "assets", "sites", strings.TrimSuffix(filename, ArchiveExt(filename))+".json")
+}
+
+// archiveTagsPath is the tags file of the archive filename.
+func (fs *FileStorage) archiveTagsPath(filename string) string {
+	return filepath.Join(fs.BasePath, "assets", "sites", strings.TrimSuffix(filename, ArchiveExt(filename))+".tags")
+}
+
+// SaveArchiveMeta writes the metadata of the archive filename, and its
+// tags file when it has tags.
+func (fs *FileStorage) SaveArchiveMeta(filename string, meta models.ArchiveMeta) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.saveArchiveMeta(filename, meta)
+}
+
+func (fs *FileStorage) saveArchiveMeta(filename string, meta models.ArchiveMeta) error {
+	tags := meta.Tags
+	meta.Tags = nil
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(fs.archiveMetaPath(filename), data, 0644); err != nil {
+		return fmt.Errorf("failed to save archive metadata: %w", err)
+	}
+
+	tagsPath := fs.archiveTagsPath(filename)
+	if len(tags) == 0 {
+		if err := os.Remove(tagsPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to save archive tags: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(tagsPath, []byte(strings.Join(tags, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to save archive tags: %w", err)
+	}
+	return nil
+}
+
+// UpdateArchiveMeta applies update to the metadata of the archive filename
+// and saves the result, all under the storage lock.
+func (fs *FileStorage) UpdateArchiveMeta(filename string, update func(*models.ArchiveMeta)) (*models.ArchiveMeta, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	meta, err := fs.loadArchiveMeta(filename)
+	if err != nil {
+		return nil, err
+	}
+	update(meta)
+	if err := fs.saveArchiveMeta(filename, *meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// LoadArchiveMeta reads the metadata of the archive filename. Archives
+// from before metadata files fall back to what the file itself records:
+// the banner comment of an HTML archive or the MHTML snapshot header.
+// os.ErrNotExist is returned when the archive doesn't exist.
+func (fs *FileStorage) LoadArchiveMeta(filename string) (*models.ArchiveMeta, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.loadArchiveMeta(filename)
+}
+
+func (fs *FileStorage) loadArchiveMeta(filename string) (*models.ArchiveMeta, error) {
+	if filename == "" || filename != filepath.Base(filename) || ArchiveExt(filename) == "" {
+		return nil, fmt.Errorf("invalid archive name %q: %w", filename, os.ErrNotExist)
+	}
+	path := filepath.Join(fs.BasePath, "assets", "sites", filename)
+	if !fs.assetExists("assets/sites/" + filename) {
+		return nil, &os.PathError{Op: "stat", Path: path, Err: os.ErrNotExist}
+	}
+
+	meta := &models.ArchiveMeta{Format: ArchiveFormat(filename)}
+	if data, err := os.ReadFile(fs.archiveTagsPath(filename)); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if tag := strings.TrimSpace(line); tag != "" {
+				meta.Tags = append(meta.Tags, tag)
+			}
+		}
+	}
+	tags := meta.Tags
+	if data, err := os.ReadFile(fs.archiveMetaPath(filename)); err == nil {
+		if err := json.Unmarshal(data, meta); err != nil {
+			return nil, fmt.Errorf("failed to read archive metadata: %w", err)
+		}
+		meta.Tags = tags
+		return meta, nil
+	}
+
+	if len(filename) >= len("2006_01_02_150405") {
+		meta.ArchivedAt, _ = time.ParseInLocation("2006_01_02_150405", filename[:len("2006_01_02_150405")], time.Local)
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		// Offloaded to the bucket without metadata: the URL isn't known.
+		return meta, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	switch meta.Format {
+	case "html":
+		head, _ := io.ReadAll(io.LimitReader(f, archiveHeadBytes))
+		if m := archiveBannerRE.FindSubmatch(head); m != nil {
+			meta.URL = string(m[1])
+		}
+	case "mhtml":
+		if header, err := textproto.NewReader(bufio.NewReader(f)).ReadMIMEHeader(); err == nil {
+			meta.URL = header.Get("Snapshot-Content-Location")
+		}
+	}
+	return meta, nil
+}
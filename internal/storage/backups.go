@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// backupNameRE matches the names of the files in models.BackupsDir.
+var backupNameRE = regexp.MustCompile(`^notes-(\d{8}-\d{6})\.md$`)
+
+const backupTimeLayout = "20060102-150405"
+
+// SetBackupPolicy sets how many backups of notes.md to keep and the least
+// time between two of them. keep zero or less turns backups off.
+func (fs *FileStorage) SetBackupPolicy(keep int, interval time.Duration) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.backupKeep = keep
+	fs.backupInterval = interval
+}
+
+func (fs *FileStorage) backupsPath() string {
+	return filepath.Join(fs.BasePath, filepath.FromSlash(models.BackupsDir))
+}
+
+// backupNotes copies old, the notes.md a save is about to replace, into
+// the backups directory, unless the newest backup is more recent than the
+// interval or, as names go by the second, from the same second. It then
+// deletes the oldest backups beyond the number kept. An empty notes.md
+// isn't worth a backup. Callers hold fs.mu.
+func (fs *FileStorage) backupNotes(old []byte, now time.Time) error {
+	if fs.backupKeep <= 0 || len(old) == 0 {
+		return nil
+	}
+	backups, err := fs.listBackups()
+	if err != nil {
+		return err
+	}
+	if len(backups) > 0 && now.Sub(backups[0].Taken) < max(fs.backupInterval, time.Second) {
+		return nil
+	}
+	if err := os.MkdirAll(fs.backupsPath(), 0755); err != nil {
+		return fmt.Errorf("failed to create backups directory: %w", err)
+	}
+	name := "notes-" + now.Format(backupTimeLayout) + ".md"
+	if err := writeFileAtomic(filepath.Join(fs.backupsPath(), name), old, 0644); err != nil {
+		return err
+	}
+	backups = append([]models.Backup{{Name: name}}, backups...)
+	for _, b := range backups[min(fs.backupKeep, len(backups)):] {
+		if err := os.Remove(filepath.Join(fs.backupsPath(), b.Name)); err != nil && !os.IsNotExist(err) {
+			log.Printf("Warning: failed to delete old backup %s: %v", b.Name, err)
+		}
+	}
+	return nil
+}
+
+// ListBackups returns the backups of notes.md, newest first.
+func (fs *FileStorage) ListBackups() ([]models.Backup, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.listBackups()
+}
+
+func (fs *FileStorage) listBackups() ([]models.Backup, error) {
+	entries, err := os.ReadDir(fs.backupsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var backups []models.Backup
+	for _, e := range entries {
+		m := backupNameRE.FindStringSubmatch(e.Name())
+		if m == nil || !e.Type().IsRegular() {
+			continue
+		}
+		taken, err := time.ParseInLocation(backupTimeLayout, m[1], time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, models.Backup{Name: e.Name(), Taken: taken, Size: info.Size()})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Taken.After(backups[j].Taken) })
+	return backups, nil
+}
+
+// ReadBackup returns the content of the backup with name. The error wraps
+// os.ErrNotExist when there is no such backup.
+func (fs *FileStorage) ReadBackup(name string) ([]byte, error) {
+	if !backupNameRE.MatchString(name) {
+		return nil, fmt.Errorf("invalid backup name %q: %w", name, os.ErrNotExist)
+	}
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return os.ReadFile(filepath.Join(fs.backupsPath(), name))
+}
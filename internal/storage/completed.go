@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CompletedArchiveFile collects tasks archived out of notes.md, newest
+// entries last.
+const CompletedArchiveFile = "archive.md"
+
+// AppendCompletedArchive appends entry to archive.md, creating it if
+// needed.
+func (fs *FileStorage) AppendCompletedArchive(entry string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(fs.BasePath, CompletedArchiveFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", CompletedArchiveFile, err)
+	}
+	if _, err := f.WriteString(entry); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", CompletedArchiveFile, err)
+	}
+	return f.Close()
+}
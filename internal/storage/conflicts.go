@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SaveConflictCopy writes data, notes that could not be merged into
+// notes.md, to a notes.conflict-YYYYMMDD-HHMMSS.md file beside it, so
+// that they can be merged by hand. It is encrypted in the encrypted mode,
+// like the trash. It returns the file's name.
+func (fs *FileStorage) SaveConflictCopy(data []byte, now time.Time) (string, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	sealed, err := fs.sealPrivate(data)
+	if err != nil {
+		return "", err
+	}
+	stem := "notes.conflict-" + now.Format(backupTimeLayout)
+	name := stem + ".md"
+	for n := 2; ; n++ {
+		if _, err := os.Lstat(filepath.Join(fs.BasePath, name)); os.IsNotExist(err) {
+			break
+		}
+		name = fmt.Sprintf("%s-%d.md", stem, n)
+	}
+	if err := writeFileAtomic(filepath.Join(fs.BasePath, name), sealed, 0644); err != nil {
+		return "", fmt.Errorf("failed to save conflicting notes: %w", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,294 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// EncryptedNotesFile holds a folder's notes in the encrypted storage mode:
+// notes.md, sealed with AES-256-GCM under a key derived from a passphrase.
+const EncryptedNotesFile = "notes.md.enc"
+
+// PassphraseEnv is the environment variable a passphrase is taken from
+// when an encrypted folder is opened without being unlocked first; it is
+// how the CLI subcommands and a daemonized server get one.
+const PassphraseEnv = "NOTEFLOW_PASSPHRASE"
+
+// sealedMagic starts every file this package encrypts, so sealed and
+// plain files can be told apart; the digit is the format version.
+var sealedMagic = []byte("NOTEFLOW-SEALED-1\n")
+
+// keyIterations is the PBKDF2-HMAC-SHA256 work factor, as OWASP
+// recommends. Unlocking takes a fraction of a second, once per process.
+const keyIterations = 600_000
+
+var (
+	// ErrLocked is returned for an encrypted folder no passphrase was
+	// given for.
+	ErrLocked = errors.New("notes are encrypted: give the passphrase at startup or in " + PassphraseEnv)
+	// ErrWrongPassphrase is returned when the passphrase doesn't open the
+	// folder's notes.
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+)
+
+// folderKeys holds the key of each unlocked folder, by absolute path, for
+// the life of the process.
+var folderKeys sync.Map
+
+func folderKeyID(basePath string) string {
+	if abs, err := filepath.Abs(basePath); err == nil {
+		return abs
+	}
+	return filepath.Clean(basePath)
+}
+
+// NewEncryptionSalt returns a random salt for a folder's
+// models.EncryptionFolderConfig.
+func NewEncryptionSalt() (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(salt), nil
+}
+
+// DeriveKey returns the AES-256 key for passphrase and a folder's salt.
+func DeriveKey(passphrase, salt string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil || len(raw) < 8 {
+		return nil, fmt.Errorf("invalid encryption salt in %s", models.FolderConfigFile)
+	}
+	return pbkdf2.Key(sha256.New, passphrase, raw, keyIterations, 32)
+}
+
+// SetFolderKey makes key the encryption key of the folder at basePath.
+func SetFolderKey(basePath string, key []byte) {
+	folderKeys.Store(folderKeyID(basePath), key)
+}
+
+// Unlock derives the key of the encrypted folder at basePath from
+// passphrase and, after checking it opens the notes, keeps it for every
+// storage of the folder this process opens.
+func Unlock(basePath, passphrase string) error {
+	cfg, err := models.LoadFolderConfig(basePath)
+	if err != nil {
+		return err
+	}
+	if cfg.Encryption == nil {
+		return fmt.Errorf("%s has no encryption salt", models.FolderConfigFile)
+	}
+	key, err := DeriveKey(passphrase, cfg.Encryption.Salt)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filepath.Join(basePath, EncryptedNotesFile))
+	if err == nil {
+		if _, err := openSealed(key, data); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	SetFolderKey(basePath, key)
+	return nil
+}
+
+// IsUnlocked reports whether the folder at basePath has a key.
+func IsUnlocked(basePath string) bool {
+	_, ok := folderKeys.Load(folderKeyID(basePath))
+	return ok
+}
+
+// folderKey returns the key of the folder at basePath, unlocking it with
+// PassphraseEnv when it hasn't been.
+func folderKey(basePath string) ([]byte, error) {
+	if key, ok := folderKeys.Load(folderKeyID(basePath)); ok {
+		return key.([]byte), nil
+	}
+	passphrase := os.Getenv(PassphraseEnv)
+	if passphrase == "" {
+		return nil, ErrLocked
+	}
+	if err := Unlock(basePath, passphrase); err != nil {
+		return nil, err
+	}
+	key, _ := folderKeys.Load(folderKeyID(basePath))
+	return key.([]byte), nil
+}
+
+// seal encrypts data under key: the magic, a random nonce, then the
+// AES-GCM ciphertext, which authenticates the magic too.
+func seal(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(sealedMagic)+gcm.NonceSize(), len(sealedMagic)+gcm.NonceSize()+len(data)+gcm.Overhead())
+	copy(out, sealedMagic)
+	nonce := out[len(sealedMagic):]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(out, nonce, data, sealedMagic), nil
+}
+
+// openSealed decrypts what seal wrote. Anything else, or a wrong key, is
+// ErrWrongPassphrase: GCM can't tell a bad key from tampering.
+func openSealed(key, data []byte) ([]byte, error) {
+	if !isSealed(data) {
+		return nil, fmt.Errorf("not an encrypted NoteFlow file")
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	rest := data[len(sealedMagic):]
+	if len(rest) < gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plain, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], sealedMagic)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
+
+func isSealed(data []byte) bool {
+	return bytes.HasPrefix(data, sealedMagic)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptedNotes is the notes.md.enc of the encrypted mode.
+type encryptedNotes struct {
+	path string
+	key  []byte
+}
+
+// load decrypts and parses the notes; no file yet is no notes.
+func (e encryptedNotes) load() ([]*models.Note, error) {
+	data, err := os.ReadFile(e.path)
+	if os.IsNotExist(err) {
+		return []*models.Note{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", EncryptedNotesFile, err)
+	}
+	plain, err := openSealed(e.key, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", EncryptedNotesFile, err)
+	}
+	notes, err := ParseNotes(string(plain))
+	if notes == nil && err == nil {
+		notes = []*models.Note{}
+	}
+	return notes, err
+}
+
+// save renders the notes as notes.md and writes them sealed, atomically.
+func (e encryptedNotes) save(notes []*models.Note) error {
+	sealed, err := seal(e.key, []byte(RenderNotes(notes)))
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(e.path, sealed, 0600)
+}
+
+// sealPrivate returns data as it is to be written to one of the files
+// holding note text besides the notes themselves (trash.md, history):
+// encrypted in the encrypted mode, as is otherwise.
+func (fs *FileStorage) sealPrivate(data []byte) ([]byte, error) {
+	if fs.mode != models.NotesStorageEncrypted {
+		return data, nil
+	}
+	key, err := folderKey(fs.BasePath)
+	if err != nil {
+		return nil, err
+	}
+	return seal(key, data)
+}
+
+// openPrivate undoes sealPrivate. Plain files are returned as they are, in
+// any mode, so those written before the folder was encrypted still read.
+func (fs *FileStorage) openPrivate(data []byte) ([]byte, error) {
+	if !isSealed(data) {
+		return data, nil
+	}
+	key, err := folderKey(fs.BasePath)
+	if err != nil {
+		return nil, err
+	}
+	return openSealed(key, data)
+}
+
+// ResealPrivateFiles rewrites trash.md and the note history in the
+// storage's mode: encrypted when converting to the encrypted mode, plain
+// when converting from it. It returns how many files it rewrote.
+func (fs *FileStorage) ResealPrivateFiles() (int, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	paths := []string{filepath.Join(fs.BasePath, models.TrashFile)}
+	history := filepath.Join(fs.BasePath, filepath.FromSlash(historyDir))
+	err := filepath.WalkDir(history, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() && strings.HasSuffix(p, ".json") {
+			paths = append(paths, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		if isSealed(data) == (fs.mode == models.NotesStorageEncrypted) {
+			continue
+		}
+		plain, err := fs.openPrivate(data)
+		if err != nil {
+			return n, fmt.Errorf("%s: %w", path, err)
+		}
+		out, err := fs.sealPrivate(plain)
+		if err != nil {
+			return n, err
+		}
+		if err := writeFileAtomic(path, out, 0600); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -1,11 +1,13 @@
 package storage
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Xafloc/NoteFlow-Go/internal/models"
 )
@@ -14,13 +16,108 @@ import (
 type FileStorage struct {
 	BasePath string
 	mu       sync.RWMutex // Protects concurrent file access
+
+	// mode is the folder's notes storage mode, a models.NotesStorage*.
+	// In the SQLite mode notesDB is opened on first use, in the WebDAV
+	// mode remote is set up then.
+	mode      string
+	notesDB   *sqliteNotes
+	remote    *webdavNotes
+	notesDBMu sync.Mutex
+	// assetBucket is the folder's S3 bucket, nil without one; see bucket.
+	assetBucket   *s3Bucket
+	bucketChecked bool
+	// See SetBackupPolicy; backups are off until it is called.
+	backupKeep     int
+	backupInterval time.Duration
 }
 
-// NewFileStorage creates a new file storage instance
+// NewFileStorage creates a new file storage instance, in the notes
+// storage mode the folder's .noteflow.json sets.
 func NewFileStorage(basePath string) *FileStorage {
+	mode := models.NotesStorageMarkdown
+	if cfg, err := models.LoadFolderConfig(basePath); err == nil {
+		mode = cfg.NotesStorage()
+	}
+	return NewFileStorageMode(basePath, mode)
+}
+
+// NewFileStorageMode creates a file storage instance keeping notes in
+// mode, whatever the folder config says; for converting between modes.
+func NewFileStorageMode(basePath, mode string) *FileStorage {
 	return &FileStorage{
 		BasePath: basePath,
+		mode:     mode,
+	}
+}
+
+// Mode returns the notes storage mode, a models.NotesStorage*.
+func (fs *FileStorage) Mode() string {
+	return fs.mode
+}
+
+// notesStore keeps a folder's notes somewhere other than notes.md.
+type notesStore interface {
+	load() ([]*models.Note, error)
+	save(notes []*models.Note) error
+}
+
+// store returns where the notes are kept in the modes other than
+// markdown, opening the database on first use, and an error in an unknown
+// mode or a locked encrypted folder.
+func (fs *FileStorage) store() (notesStore, error) {
+	switch fs.mode {
+	case models.NotesStorageFiles:
+		return noteFiles{dir: filepath.Join(fs.BasePath, NotesDir)}, nil
+	case models.NotesStorageEncrypted:
+		key, err := folderKey(fs.BasePath)
+		if err != nil {
+			return nil, err
+		}
+		return encryptedNotes{path: fs.GetNotesFilePath(), key: key}, nil
+	case models.NotesStorageWebDAV:
+		return fs.webdav()
+	case models.NotesStorageSQLite:
+	default:
+		return nil, fmt.Errorf("unknown notes storage mode %q (want %s, %s, %s, %s or %s)", fs.mode, models.NotesStorageMarkdown, models.NotesStorageSQLite, models.NotesStorageFiles, models.NotesStorageEncrypted, models.NotesStorageWebDAV)
+	}
+	fs.notesDBMu.Lock()
+	defer fs.notesDBMu.Unlock()
+	if fs.notesDB == nil {
+		db, err := openSQLiteNotes(filepath.Join(fs.BasePath, NotesDBFile))
+		if err != nil {
+			return nil, err
+		}
+		fs.notesDB = db
+	}
+	return fs.notesDB, nil
+}
+
+// Close closes the notes database of the SQLite mode, if it was opened.
+func (fs *FileStorage) Close() error {
+	fs.notesDBMu.Lock()
+	defer fs.notesDBMu.Unlock()
+	if fs.notesDB == nil {
+		return nil
+	}
+	err := fs.notesDB.close()
+	fs.notesDB = nil
+	return err
+}
+
+// HasNotes reports whether basePath holds notes: a notes.md or notes.db,
+// or the notes directory when its folder config sets the files mode.
+func HasNotes(basePath string) bool {
+	for _, path := range []string{
+		filepath.Join(basePath, "notes.md"),
+		filepath.Join(basePath, NotesDBFile),
+		NewFileStorage(basePath).GetNotesFilePath(),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
 	}
+	return false
 }
 
 // EnsureDirectories creates necessary directories
@@ -41,16 +138,80 @@ func (fs *FileStorage) EnsureDirectories() error {
 	return nil
 }
 
-// GetNotesFilePath returns the path to the notes.md file
+// GetNotesFilePath returns the path to the file holding the notes:
+// notes.md, notes.db in the SQLite mode, the notes directory in the files
+// mode, notes.md.enc in the encrypted mode or the local copy in the
+// WebDAV mode.
 func (fs *FileStorage) GetNotesFilePath() string {
+	switch fs.mode {
+	case models.NotesStorageWebDAV:
+		return filepath.Join(fs.BasePath, WebDAVCacheFile)
+	case models.NotesStorageSQLite:
+		return filepath.Join(fs.BasePath, NotesDBFile)
+	case models.NotesStorageFiles:
+		return filepath.Join(fs.BasePath, NotesDir)
+	case models.NotesStorageEncrypted:
+		return filepath.Join(fs.BasePath, EncryptedNotesFile)
+	}
 	return filepath.Join(fs.BasePath, "notes.md")
 }
 
+// NotesSize returns how many bytes the notes take on disk in the folder's
+// storage mode, a whole directory of them in the files mode.
+func (fs *FileStorage) NotesSize() (int64, error) {
+	var size int64
+	err := filepath.WalkDir(fs.GetNotesFilePath(), func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	return size, err
+}
+
+// NotesStamp returns when the notes last changed on disk and their size,
+// which together tell one version of them from another.
+func (fs *FileStorage) NotesStamp() (time.Time, int64, error) {
+	switch fs.mode {
+	case models.NotesStorageFiles:
+		return noteFiles{dir: fs.GetNotesFilePath()}.stamp()
+	case models.NotesStorageWebDAV:
+		remote, err := fs.webdav()
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+		return remote.stamp()
+	}
+	info, err := os.Stat(fs.GetNotesFilePath())
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return info.ModTime(), info.Size(), nil
+}
+
 // LoadNotes loads all notes from the notes.md file
 func (fs *FileStorage) LoadNotes() ([]*models.Note, error) {
 	fs.mu.RLock()
 	defer fs.mu.RUnlock()
 
+	if fs.mode != models.NotesStorageMarkdown {
+		store, err := fs.store()
+		if err != nil {
+			return nil, err
+		}
+		return store.load()
+	}
+
 	notesPath := fs.GetNotesFilePath()
 	
 	// Create notes.md if it doesn't exist
@@ -72,11 +233,88 @@ func (fs *FileStorage) LoadNotes() ([]*models.Note, error) {
 		return []*models.Note{}, nil
 	}
 
-	return fs.parseNotes(content)
+	return ParseNotes(content)
+}
+
+// ReadNotesFile returns notes.md as it is on disk; empty when it doesn't
+// exist yet. In the other modes it is rendered from the notes.
+func (fs *FileStorage) ReadNotesFile() ([]byte, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	if fs.mode != models.NotesStorageMarkdown {
+		store, err := fs.store()
+		if err != nil {
+			return nil, err
+		}
+		notes, err := store.load()
+		if err != nil {
+			return nil, err
+		}
+		return []byte(RenderNotes(notes)), nil
+	}
+
+	data, err := os.ReadFile(fs.GetNotesFilePath())
+	if os.IsNotExist(err) {
+		return []byte{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes.md: %w", err)
+	}
+	return data, nil
 }
 
-// parseNotes parses the raw content into Note objects
-func (fs *FileStorage) parseNotes(content string) ([]*models.Note, error) {
+// WriteNotesFile replaces notes.md with data as is, atomically. It is for
+// repairs that must keep text the parser would drop; notes are saved
+// with SaveNotes. The other modes have no place for such text: data is
+// parsed and saved as notes.
+func (fs *FileStorage) WriteNotesFile(data []byte) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.mode != models.NotesStorageMarkdown {
+		notes, err := ParseNotes(string(data))
+		if err != nil {
+			return err
+		}
+		return fs.saveNotes(notes)
+	}
+	return fs.writeNotes(data)
+}
+
+// NotesBackupFile holds notes.md as it was before the last save that
+// changed it, so a bad save can be undone by hand.
+const NotesBackupFile = ".notes.md.bak"
+
+// writeNotes replaces notes.md with data atomically unless it already
+// holds data, first copying the current file to NotesBackupFile with the
+// same mode and, as the backup policy allows, into the backups directory.
+// Callers hold fs.mu.
+func (fs *FileStorage) writeNotes(data []byte) error {
+	notesPath := fs.GetNotesFilePath()
+	old, err := os.ReadFile(notesPath)
+	switch {
+	case err == nil && bytes.Equal(old, data):
+		// Nothing changed; don't wear the disk rewriting it.
+		return nil
+	case err == nil:
+		perm := os.FileMode(0644)
+		if info, err := os.Stat(notesPath); err == nil {
+			perm = info.Mode().Perm()
+		}
+		if err := writeFileAtomic(filepath.Join(fs.BasePath, NotesBackupFile), old, perm); err != nil {
+			return fmt.Errorf("failed to back up notes.md: %w", err)
+		}
+		if err := fs.backupNotes(old, time.Now()); err != nil {
+			return fmt.Errorf("failed to back up notes.md: %w", err)
+		}
+	case err != nil && !os.IsNotExist(err):
+		return fmt.Errorf("failed to read notes.md: %w", err)
+	}
+	return writeFileAtomic(notesPath, data, 0644)
+}
+
+// ParseNotes parses content in the notes.md format into notes
+func ParseNotes(content string) ([]*models.Note, error) {
 	var notes []*models.Note
 	
 	// Split by note separator
@@ -102,23 +340,39 @@ func (fs *FileStorage) parseNotes(content string) ([]*models.Note, error) {
 	return notes, nil
 }
 
-// SaveNotes saves all notes to the notes.md file
+// SaveNotes saves all notes to the notes.md file, or wherever the storage
+// mode keeps them
 func (fs *FileStorage) SaveNotes(notes []*models.Note) error {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
+	return fs.saveNotes(notes)
+}
 
-	var rendered []string
-	for _, note := range notes {
-		rendered = append(rendered, note.Render())
+// saveNotes is SaveNotes for callers holding fs.mu.
+func (fs *FileStorage) saveNotes(notes []*models.Note) error {
+	if fs.mode != models.NotesStorageMarkdown {
+		store, err := fs.store()
+		if err != nil {
+			return err
+		}
+		return store.save(notes)
 	}
-	
-	content := strings.Join(rendered, models.NoteSeparator)
-	notesPath := fs.GetNotesFilePath()
-	
-	return os.WriteFile(notesPath, []byte(content), 0644)
+	return fs.writeNotes([]byte(RenderNotes(notes)))
 }
 
-// SaveFile saves an uploaded file to the appropriate directory
+// RenderNotes renders notes in the notes.md format.
+func RenderNotes(notes []*models.Note) string {
+	rendered := make([]string, len(notes))
+	for i, note := range notes {
+		rendered[i] = note.Render()
+	}
+	return strings.Join(rendered, models.NoteSeparator)
+}
+
+// SaveFile saves an uploaded file to the appropriate directory. A file
+// with the same content already there is reused rather than stored twice,
+// and one with the same name but other content is kept: the upload gets
+// its content hash added to its name.
 func (fs *FileStorage) SaveFile(filename string, data []byte, isImage bool) (string, error) {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
@@ -135,6 +389,39 @@ func (fs *FileStorage) SaveFile(filename string, data []byte, isImage bool) (str
 		return "", fmt.Errorf("failed to create assets directory: %w", err)
 	}
 
+	existing, err := findUpload(assetsDir, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to read assets directory: %w", err)
+	}
+	if existing != "" {
+		return fmt.Sprintf("/assets/%s/%s", subDir, existing), nil
+	}
+	bucket, err := fs.bucket()
+	if err != nil {
+		return "", err
+	}
+	if bucket != nil {
+		index, err := fs.offloaded()
+		if err != nil {
+			return "", err
+		}
+		if existing := findOffloaded(index, "assets/"+subDir, data); existing != "" {
+			return fmt.Sprintf("/assets/%s/%s", subDir, existing), nil
+		}
+		if _, taken := index["assets/"+subDir+"/"+filename]; taken {
+			filename = hashedUploadName(filename, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(assetsDir, filename)); err == nil {
+		filename = hashedUploadName(filename, data)
+	}
+	if bucket != nil {
+		if err := fs.putAsset(bucket, "assets/"+subDir+"/"+filename, data); err != nil {
+			return "", fmt.Errorf("failed to save file: %w", err)
+		}
+		return fmt.Sprintf("/assets/%s/%s", subDir, filename), nil
+	}
+
 	filePath := filepath.Join(assetsDir, filename)
 	if err := os.WriteFile(filePath, data, 0644); err != nil {
 		return "", fmt.Errorf("failed to save file: %w", err)
@@ -173,7 +460,35 @@ func (fs *FileStorage) DeleteFile(relativePath string) error {
 		return fmt.Errorf("file path outside assets directory: %s", relativePath)
 	}
 
-	return os.Remove(absFilePath)
+	rel := strings.TrimPrefix(relativePath, "/")
+	if !fs.assetExists(rel) {
+		return os.Remove(absFilePath)
+	}
+	return fs.removeAsset(rel)
+}
+
+// ReaderSitesDir is the subdirectory of assets/sites holding reader-mode
+// copies of archived pages; see ReaderCopyName.
+const ReaderSitesDir = "reader"
+
+// ArchiveExtensions are the file types an archived site is saved as.
+var ArchiveExtensions = []string{".html", ".pdf", ".mhtml", ".warc.gz"}
+
+// ArchiveExt returns name's archive extension, or "" if name is not an
+// archive.
+func ArchiveExt(name string) string {
+	for _, ext := range ArchiveExtensions {
+		if strings.HasSuffix(name, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// ReaderCopyName is the filename, under ReaderSitesDir, of the reader-mode
+// copy of the archive filename: the same name as an HTML page.
+func ReaderCopyName(filename string) string {
+	return strings.TrimSuffix(filename, ArchiveExt(filename)) + ".html"
 }
 
 // ListArchivedSites returns a list of archived website files
@@ -181,22 +496,18 @@ func (fs *FileStorage) ListArchivedSites() (map[string]interface{}, error) {
 	fs.mu.RLock()
 	defer fs.mu.RUnlock()
 
-	sitesPath := filepath.Join(fs.BasePath, "assets", "sites")
-	entries, err := os.ReadDir(sitesPath)
+	names, err := fs.archiveNames()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return make(map[string]interface{}), nil
-		}
-		return nil, fmt.Errorf("failed to read sites directory: %w", err)
+		return nil, err
 	}
 
 	linkGroups := make(map[string]interface{})
 	
 	// Filter for HTML files and group by domain
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".html") {
+	for _, name := range names {
+		if ext := ArchiveExt(name); ext != "" {
 			// Parse filename: YYYY_MM_DD_HHMMSS_title-domain.html
-			parts := strings.Split(strings.TrimSuffix(entry.Name(), ".html"), "_")
+			parts := strings.Split(strings.TrimSuffix(name, ext), "_")
 			if len(parts) >= 4 {
 				// Extract domain from the last part after the dash
 				lastPart := parts[len(parts)-1]
@@ -213,10 +524,29 @@ func (fs *FileStorage) ListArchivedSites() (map[string]interface{}, error) {
 					// Add archive info
 					domainData := linkGroups[domain].(map[string]interface{})
 					archives := domainData["archives"].([]map[string]string)
-					archives = append(archives, map[string]string{
+					archive := map[string]string{
 						"timestamp": strings.Join(parts[:3], "_"),
-						"filename":  entry.Name(),
-					})
+						"filename":  name,
+						"format":    ArchiveFormat(name),
+					}
+					if meta, err := fs.loadArchiveMeta(name); err == nil {
+						if meta.URL != "" {
+							archive["url"] = meta.URL
+						}
+						if meta.Title != "" {
+							archive["title"] = meta.Title
+						}
+						if meta.Notes != "" {
+							archive["notes"] = meta.Notes
+						}
+						if len(meta.Tags) > 0 {
+							archive["tags"] = strings.Join(meta.Tags, ",")
+						}
+					}
+					if fs.assetExists("assets/sites/" + ReaderSitesDir + "/" + ReaderCopyName(name)) {
+						archive["reader"] = ReaderSitesDir + "/" + ReaderCopyName(name)
+					}
+					archives = append(archives, archive)
 					domainData["archives"] = archives
 				}
 			}
@@ -226,22 +556,51 @@ func (fs *FileStorage) ListArchivedSites() (map[string]interface{}, error) {
 	return linkGroups, nil
 }
 
+// ArchiveTimes returns when each archived site was captured, read from the
+// YYYY_MM_DD_HHMMSS prefix of its filename (local time). Files that don't
+// follow the naming scheme are skipped.
+func (fs *FileStorage) ArchiveTimes() ([]time.Time, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	names, err := fs.archiveNames()
+	if err != nil {
+		return nil, err
+	}
+	var times []time.Time
+	for _, name := range names {
+		if ArchiveExt(name) == "" || len(name) < len("2006_01_02_150405") {
+			continue
+		}
+		if t, err := time.ParseInLocation("2006_01_02_150405", name[:len("2006_01_02_150405")], time.Local); err == nil {
+			times = append(times, t)
+		}
+	}
+	return times, nil
+}
+
 // DeleteArchivedSite deletes an archived website file and its metadata
 func (fs *FileStorage) DeleteArchivedSite(filename string) error {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
-	sitesPath := filepath.Join(fs.BasePath, "assets", "sites")
-	
-	// Delete HTML file
-	htmlPath := filepath.Join(sitesPath, filename)
-	if err := os.Remove(htmlPath); err != nil && !os.IsNotExist(err) {
+	// Delete HTML file, here or in the bucket
+	if err := fs.removeAsset("assets/sites/" + filename); err != nil {
 		return fmt.Errorf("failed to delete HTML file: %w", err)
 	}
 
+	// Delete the reader-mode copy if there is one
+	if err := fs.removeAsset("assets/sites/" + ReaderSitesDir + "/" + ReaderCopyName(filename)); err != nil {
+		return fmt.Errorf("failed to delete reader copy: %w", err)
+	}
+
+	// Delete the metadata file if it exists
+	if err := os.Remove(fs.archiveMetaPath(filename)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete archive metadata: %w", err)
+	}
+
 	// Delete tags file if it exists
-	tagsPath := strings.TrimSuffix(htmlPath, ".html") + ".tags"
-	if err := os.Remove(tagsPath); err != nil && !os.IsNotExist(err) {
+	if err := os.Remove(fs.archiveTagsPath(filename)); err != nil && !os.IsNotExist(err) {
 		// Non-critical error, log but don't fail
 	}
 
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// historyDir holds one directory per note (named by Note.HistoryKey) with
+// one JSON file per revision. It lives under assets so it travels with the
+// folder, and starts with a dot so it stays out of the file listings.
+const historyDir = "assets/.history"
+
+var historyNameRE = regexp.MustCompile(`^[0-9-]+$`)
+
+func (fs *FileStorage) historyPath(noteKey string) (string, error) {
+	if !historyNameRE.MatchString(noteKey) {
+		return "", fmt.Errorf("invalid note key %q", noteKey)
+	}
+	return filepath.Join(fs.BasePath, filepath.FromSlash(historyDir), noteKey), nil
+}
+
+// SaveRevision stores rev in the history of the note with noteKey. The ID
+// is assigned from rev.Saved.
+func (fs *FileStorage) SaveRevision(noteKey string, rev models.Revision) (models.Revision, error) {
+	dir, err := fs.historyPath(noteKey)
+	if err != nil {
+		return rev, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return rev, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	rev.ID = strconv.FormatInt(rev.Saved.UnixNano(), 10)
+	data, err := json.Marshal(rev)
+	if err != nil {
+		return rev, err
+	}
+	if data, err = fs.sealPrivate(data); err != nil {
+		return rev, err
+	}
+	return rev, os.WriteFile(filepath.Join(dir, rev.ID+".json"), data, 0644)
+}
+
+// ListRevisions returns the note's revisions, oldest first. A note that
+// was never edited has none.
+func (fs *FileStorage) ListRevisions(noteKey string) ([]models.Revision, error) {
+	dir, err := fs.historyPath(noteKey)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var revs []models.Revision
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok {
+			continue
+		}
+		rev, err := fs.LoadRevision(noteKey, id)
+		if err != nil {
+			return nil, err
+		}
+		revs = append(revs, *rev)
+	}
+	sort.Slice(revs, func(i, j int) bool { return revs[i].Saved.Before(revs[j].Saved) })
+	return revs, nil
+}
+
+// LoadRevision reads one revision of a note. The error wraps os.ErrNotExist
+// when there is no such revision.
+func (fs *FileStorage) LoadRevision(noteKey, id string) (*models.Revision, error) {
+	dir, err := fs.historyPath(noteKey)
+	if err != nil {
+		return nil, err
+	}
+	if !historyNameRE.MatchString(id) {
+		return nil, fmt.Errorf("invalid revision id %q: %w", id, os.ErrNotExist)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, id+".json"))
+	if err != nil {
+		return nil, err
+	}
+	if data, err = fs.openPrivate(data); err != nil {
+		return nil, fmt.Errorf("revision %s/%s: %w", noteKey, id, err)
+	}
+	var rev models.Revision
+	if err := json.Unmarshal(data, &rev); err != nil {
+		return nil, fmt.Errorf("corrupt revision %s/%s: %w", noteKey, id, err)
+	}
+	return &rev, nil
+}
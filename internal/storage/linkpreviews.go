@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// LoadLinkPreviews reads the link preview cache, keyed by URL. A missing
+// file is an empty cache.
+func (fs *FileStorage) LoadLinkPreviews() (map[string]models.LinkPreview, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	previews := make(map[string]models.LinkPreview)
+	data, err := os.ReadFile(filepath.Join(fs.BasePath, models.LinkPreviewsFile))
+	if os.IsNotExist(err) {
+		return previews, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", models.LinkPreviewsFile, err)
+	}
+	if err := json.Unmarshal(data, &previews); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", models.LinkPreviewsFile, err)
+	}
+	return previews, nil
+}
+
+// SaveLinkPreviews writes the link preview cache.
+func (fs *FileStorage) SaveLinkPreviews(previews map[string]models.LinkPreview) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	data, err := json.MarshalIndent(previews, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(fs.BasePath, models.LinkPreviewsFile), data, 0644)
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// NotesDir holds a folder's notes in the files storage mode, one markdown
+// file per note, so the folder opens as an Obsidian or Logseq vault.
+const NotesDir = "notes"
+
+// noteFileTimeLayout starts a note's filename; colons are not allowed in
+// filenames everywhere.
+const noteFileTimeLayout = "2006-01-02 150405"
+
+// maxNoteFileTitle caps the title part of a filename, in runes.
+const maxNoteFileTitle = 80
+
+// noteFileUnsafe are the characters a filename can't hold on some system,
+// or that break Obsidian's [[links]].
+var noteFileUnsafe = strings.NewReplacer(
+	"/", " ", "\\", " ", ":", " ", "*", " ", "?", " ", "\"", " ", "<", " ", ">", " ",
+	"|", " ", "#", " ", "^", " ", "[", " ", "]", " ", "\n", " ", "\r", " ", "\t", " ",
+)
+
+// noteHeaderRE matches the header line a note file written by NoteFlow
+// starts with.
+var noteHeaderRE = regexp.MustCompile(`^## \d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(?: - .*)?$`)
+
+// noteFiles is the notes directory of the files mode. Each file holds one
+// note as notes.md would, header line included, so moving between layouts
+// loses nothing.
+type noteFiles struct {
+	dir string
+}
+
+// NoteFileName returns the name of note's file: its timestamp and
+// title, made safe for a filename.
+func NoteFileName(note *models.Note) string {
+	name := note.Timestamp.Format(noteFileTimeLayout)
+	title := strings.Join(strings.Fields(noteFileUnsafe.Replace(note.Title)), " ")
+	title = strings.TrimLeft(title, ".")
+	if r := []rune(title); len(r) > maxNoteFileTitle {
+		title = strings.TrimSpace(string(r[:maxNoteFileTitle]))
+	}
+	if title != "" {
+		name += " " + title
+	}
+	return name + ".md"
+}
+
+// noteFileNames names each of notes' files, numbering the names of notes
+// that would share one.
+func noteFileNames(notes []*models.Note) []string {
+	names := make([]string, len(notes))
+	used := make(map[string]bool, len(notes))
+	for i, note := range notes {
+		name := NoteFileName(note)
+		base := strings.TrimSuffix(name, ".md")
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			name = fmt.Sprintf("%s (%d).md", base, n)
+		}
+		used[strings.ToLower(name)] = true
+		names[i] = name
+	}
+	return names
+}
+
+// files returns the note files in the directory: its .md files, leaving out hidden ones such as an atomic write's temporary file.
+func (d noteFiles) files() ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(d.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", NotesDir, err)
+	}
+	var files []os.DirEntry
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.EqualFold(filepath.Ext(name), ".md") && !strings.HasPrefix(name, ".") {
+			files = append(files, entry)
+		}
+	}
+	return files, nil
+}
+
+// load returns every note, newest first. A file without a NoteFlow header,
+// one made in another editor say, is a note titled after the file and
+// dated by its modification time; the next save renames it to match.
+func (d noteFiles) load() ([]*models.Note, error) {
+	entries, err := d.files()
+	if err != nil {
+		return nil, err
+	}
+	type loaded struct {
+		name string
+		note *models.Note
+	}
+	notes := make([]loaded, 0, len(entries))
+	for _, entry := range entries {
+		path := filepath.Join(d.dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		text := strings.TrimSpace(strings.ReplaceAll(string(data), "\r\n", "\n"))
+		note, err := models.NewNoteFromText(text)
+		if err != nil || !noteHeaderRE.MatchString(strings.SplitN(text, "\n", 2)[0]) {
+			info, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			plain := &models.Note{
+				Timestamp: info.ModTime().Truncate(time.Second),
+				Title:     strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())),
+				Content:   text,
+			}
+			if note, err = models.NewNoteFromText(plain.Render()); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", entry.Name(), err)
+			}
+		}
+		// Without the extension, "X" sorts before "X (2)", keeping notes
+		// that share a name in the order they were saved.
+		notes = append(notes, loaded{strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())), note})
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		if ti, tj := notes[i].note.Timestamp, notes[j].note.Timestamp; !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return notes[i].name < notes[j].name
+	})
+	result := make([]*models.Note, len(notes))
+	for i, n := range notes {
+		result[i] = n.note
+	}
+	return result, nil
+}
+
+// save makes the directory hold exactly notes: files whose content changed
+// are rewritten atomically and files of notes that are gone are removed.
+func (d noteFiles) save(notes []*models.Note) error {
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", NotesDir, err)
+	}
+	want := make(map[string]string, len(notes))
+	for i, name := range noteFileNames(notes) {
+		want[name] = notes[i].Render()
+	}
+	entries, err := d.files()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if _, ok := want[entry.Name()]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(d.dir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", entry.Name(), err)
+		}
+	}
+	for name, content := range want {
+		path := filepath.Join(d.dir, name)
+		if old, err := os.ReadFile(path); err == nil && string(old) == content {
+			continue
+		}
+		if err := writeFileAtomic(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// stamp returns the newest modification time of the directory and its
+// note files, and their total size, which change whenever a note does.
+func (d noteFiles) stamp() (time.Time, int64, error) {
+	info, err := os.Stat(d.dir)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	mod, size := info.ModTime(), int64(0)
+	entries, err := d.files()
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(mod) {
+			mod = info.ModTime()
+		}
+		size += info.Size()
+	}
+	return mod, size, nil
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// NoteTemplatesDir holds note templates as plain markdown files, one per
+// template, named <name>.md. Unlike assets it sits at the top of the
+// folder so templates are easy to find and edit by hand.
+const NoteTemplatesDir = "templates"
+
+// ErrInvalidTemplateName is returned for names that aren't a plain file name.
+var ErrInvalidTemplateName = errors.New("template names may only contain letters, digits, spaces, '.', '_' and '-'")
+
+var templateNameRE = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N} ._-]*$`)
+
+func (fs *FileStorage) noteTemplatePath(name string) (string, error) {
+	if !templateNameRE.MatchString(name) || strings.Contains(name, "..") {
+		return "", ErrInvalidTemplateName
+	}
+	return filepath.Join(fs.BasePath, NoteTemplatesDir, name+".md"), nil
+}
+
+// ListNoteTemplates returns the names of the folder's note templates,
+// sorted. A folder without a templates directory has none.
+func (fs *FileStorage) ListNoteTemplates() ([]string, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	entries, err := os.ReadDir(filepath.Join(fs.BasePath, NoteTemplatesDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".md")
+		if !ok || e.IsDir() || !templateNameRE.MatchString(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoadNoteTemplate reads a template. The error wraps os.ErrNotExist when
+// there is no template with that name.
+func (fs *FileStorage) LoadNoteTemplate(name string) (string, error) {
+	path, err := fs.noteTemplatePath(name)
+	if err != nil {
+		return "", err
+	}
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// SaveNoteTemplate creates or replaces a template, creating the templates
+// directory on first use.
+func (fs *FileStorage) SaveNoteTemplate(name, content string) error {
+	path, err := fs.noteTemplatePath(name)
+	if err != nil {
+		return err
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", NoteTemplatesDir, err)
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// DeleteNoteTemplate removes a template. The error wraps os.ErrNotExist
+// when there is no template with that name.
+func (fs *FileStorage) DeleteNoteTemplate(name string) error {
+	path, err := fs.noteTemplatePath(name)
+	if err != nil {
+		return err
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return os.Remove(path)
+}
@@ -0,0 +1,367 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// OffloadIndexFile lists the assets kept in the folder's S3 bucket rather
+// than on disk, by their path in the assets tree. The notes keep linking
+// to /assets/...; those links are answered with a signed bucket URL.
+const OffloadIndexFile = "assets/.offloaded.json"
+
+// OffloadDirs are the parts of the assets tree that go to the bucket:
+// uploads and archived sites. Archive metadata and note history stay on
+// disk, being small and read all the time.
+var OffloadDirs = []string{"assets/images", "assets/files", "assets/sites"}
+
+// OffloadedAsset is an entry of OffloadIndexFile.
+type OffloadedAsset struct {
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+	Offloaded time.Time `json:"offloaded"`
+}
+
+// bucket returns the folder's S3 bucket, set up from its config on first
+// use, or nil when the folder has none.
+func (fs *FileStorage) bucket() (*s3Bucket, error) {
+	fs.notesDBMu.Lock()
+	defer fs.notesDBMu.Unlock()
+	if !fs.bucketChecked {
+		cfg, err := models.LoadFolderConfig(fs.BasePath)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.S3 != nil {
+			if fs.assetBucket, err = newS3Bucket(cfg.S3); err != nil {
+				return nil, err
+			}
+		}
+		fs.bucketChecked = true
+	}
+	return fs.assetBucket, nil
+}
+
+// AssetsOffloaded reports whether new uploads and archives go to an S3
+// bucket. A bucket that is configured but can't be used is an error.
+func (fs *FileStorage) AssetsOffloaded() (bool, error) {
+	b, err := fs.bucket()
+	return b != nil, err
+}
+
+// OffloadedAssets returns the entries of OffloadIndexFile.
+func (fs *FileStorage) OffloadedAssets() (map[string]OffloadedAsset, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.offloaded()
+}
+
+func (fs *FileStorage) offloaded() (map[string]OffloadedAsset, error) {
+	return ReadOffloadIndex(fs.BasePath)
+}
+
+// ReadOffloadIndex reads the OffloadIndexFile of the folder at basePath;
+// a folder without one has nothing offloaded.
+func ReadOffloadIndex(basePath string) (map[string]OffloadedAsset, error) {
+	index := map[string]OffloadedAsset{}
+	data, err := os.ReadFile(filepath.Join(basePath, filepath.FromSlash(OffloadIndexFile)))
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", OffloadIndexFile, err)
+	}
+	return index, nil
+}
+
+func (fs *FileStorage) saveOffloaded(index map[string]OffloadedAsset) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	p := filepath.Join(fs.BasePath, filepath.FromSlash(OffloadIndexFile))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(p, append(data, '\n'), 0644)
+}
+
+// bucketKey is the key of the asset at rel, relative to the bucket prefix.
+func bucketKey(rel string) string {
+	return strings.TrimPrefix(rel, "assets/")
+}
+
+// putAsset uploads data as the asset at rel and records it in the index.
+// Callers hold fs.mu.
+func (fs *FileStorage) putAsset(b *s3Bucket, rel string, data []byte) error {
+	if err := b.put(bucketKey(rel), data, mime.TypeByExtension(path.Ext(rel))); err != nil {
+		return err
+	}
+	index, err := fs.offloaded()
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	index[rel] = OffloadedAsset{Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:]), Offloaded: time.Now().UTC()}
+	return fs.saveOffloaded(index)
+}
+
+// findOffloaded returns the name of an asset in the bucket under dir, a
+// slash path, whose content is data, or "".
+func findOffloaded(index map[string]OffloadedAsset, dir string, data []byte) string {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	names := make([]string, 0, len(index))
+	for rel := range index {
+		names = append(names, rel)
+	}
+	sort.Strings(names)
+	for _, rel := range names {
+		if path.Dir(rel) == dir && index[rel].Size == int64(len(data)) && index[rel].SHA256 == hash {
+			return path.Base(rel)
+		}
+	}
+	return ""
+}
+
+// OffloadAsset moves the asset at rel, a slash path such as
+// "assets/sites/x.html", from disk to the bucket.
+func (fs *FileStorage) OffloadAsset(rel string) error {
+	b, err := fs.bucket()
+	if err != nil || b == nil {
+		return err
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.offloadAsset(b, rel)
+}
+
+func (fs *FileStorage) offloadAsset(b *s3Bucket, rel string) error {
+	p := filepath.Join(fs.BasePath, filepath.FromSlash(rel))
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	if err := fs.putAsset(b, rel, data); err != nil {
+		return err
+	}
+	return os.Remove(p)
+}
+
+// OffloadAssets moves every file under OffloadDirs to the bucket, except
+// hidden files and archive metadata, and returns how many it moved and
+// their total size.
+func (fs *FileStorage) OffloadAssets() (int, int64, error) {
+	b, err := fs.bucket()
+	if err != nil {
+		return 0, 0, err
+	}
+	if b == nil {
+		return 0, 0, fmt.Errorf("no \"s3\" bucket in %s", models.FolderConfigFile)
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	local, err := fs.offloadable()
+	if err != nil {
+		return 0, 0, err
+	}
+	var size int64
+	for i, rel := range local {
+		info, err := os.Stat(filepath.Join(fs.BasePath, filepath.FromSlash(rel)))
+		if err != nil {
+			return i, size, err
+		}
+		if err := fs.offloadAsset(b, rel); err != nil {
+			return i, size, fmt.Errorf("%s: %w", rel, err)
+		}
+		size += info.Size()
+	}
+	return len(local), size, nil
+}
+
+// offloadable returns the files on disk OffloadAssets would move, as
+// slash paths.
+func (fs *FileStorage) offloadable() ([]string, error) {
+	var files []string
+	for _, dir := range OffloadDirs {
+		err := filepath.WalkDir(filepath.Join(fs.BasePath, filepath.FromSlash(dir)), func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || offloadKeepsLocal(d.Name()) {
+				return nil
+			}
+			rel, err := filepath.Rel(fs.BasePath, p)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// AssetUsage counts the uploads and archived sites on disk and in the
+// bucket.
+type AssetUsage struct {
+	Local          int   `json:"local"`
+	LocalBytes     int64 `json:"local_bytes"`
+	Offloaded      int   `json:"offloaded"`
+	OffloadedBytes int64 `json:"offloaded_bytes"`
+}
+
+// AssetUsage returns where the folder's uploads and archived sites are.
+func (fs *FileStorage) AssetUsage() (*AssetUsage, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	usage := &AssetUsage{}
+	local, err := fs.offloadable()
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range local {
+		if info, err := os.Stat(filepath.Join(fs.BasePath, filepath.FromSlash(rel))); err == nil {
+			usage.Local++
+			usage.LocalBytes += info.Size()
+		}
+	}
+	index, err := fs.offloaded()
+	if err != nil {
+		return nil, err
+	}
+	for _, asset := range index {
+		usage.Offloaded++
+		usage.OffloadedBytes += asset.Size
+	}
+	return usage, nil
+}
+
+// offloadKeepsLocal reports whether a file in the assets tree stays on
+// disk: an archive's .json metadata and .tags.
+func offloadKeepsLocal(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".json" || ext == ".tags"
+}
+
+// AssetURL returns a signed URL of the asset at rel when it is kept in
+// the bucket.
+func (fs *FileStorage) AssetURL(rel string) (string, bool) {
+	fs.mu.RLock()
+	index, err := fs.offloaded()
+	fs.mu.RUnlock()
+	if err != nil {
+		return "", false
+	}
+	if _, ok := index[rel]; !ok {
+		return "", false
+	}
+	b, err := fs.bucket()
+	if err != nil || b == nil {
+		return "", false
+	}
+	return b.presign(bucketKey(rel)), true
+}
+
+// AssetExists reports whether the asset at rel is on disk or in the bucket.
+func (fs *FileStorage) AssetExists(rel string) bool {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return fs.assetExists(rel)
+}
+
+func (fs *FileStorage) assetExists(rel string) bool {
+	if _, err := os.Stat(filepath.Join(fs.BasePath, filepath.FromSlash(rel))); err == nil {
+		return true
+	}
+	index, err := fs.offloaded()
+	if err != nil {
+		return false
+	}
+	_, ok := index[rel]
+	return ok
+}
+
+// archiveNames returns the names of the files directly in assets/sites,
+// on disk or in the bucket, sorted; nil when there are none.
+func (fs *FileStorage) archiveNames() ([]string, error) {
+	seen := map[string]bool{}
+	entries, err := os.ReadDir(filepath.Join(fs.BasePath, "assets", "sites"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read sites directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			seen[entry.Name()] = true
+		}
+	}
+	index, err := fs.offloaded()
+	if err != nil {
+		return nil, err
+	}
+	for rel := range index {
+		if path.Dir(rel) == "assets/sites" {
+			seen[path.Base(rel)] = true
+		}
+	}
+	var names []string
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// removeAsset deletes the asset at rel from disk and the bucket; one that
+// is in neither is no error. Callers hold fs.mu.
+func (fs *FileStorage) removeAsset(rel string) error {
+	if err := os.Remove(filepath.Join(fs.BasePath, filepath.FromSlash(rel))); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	index, err := fs.offloaded()
+	if err != nil {
+		return err
+	}
+	if _, ok := index[rel]; !ok {
+		return nil
+	}
+	b, err := fs.bucket()
+	if err != nil {
+		return err
+	}
+	if b != nil {
+		if err := b.delete(bucketKey(rel)); err != nil {
+			return err
+		}
+	}
+	delete(index, rel)
+	return fs.saveOffloaded(index)
+}
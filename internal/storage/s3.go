@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// s3Timeout bounds each request to the bucket; uploads of archived sites
+// can be tens of megabytes.
+const s3Timeout = 5 * time.Minute
+
+// s3Bucket is an S3-compatible bucket (AWS, MinIO, Backblaze B2, Cloudflare
+// R2, Wasabi...) spoken to with Signature Version 4, the one request
+// signing scheme they all accept.
+type s3Bucket struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	prefix    string // prepended to every key; "" or ending in "/"
+	pathStyle bool   // bucket in the path instead of the host name
+	urlExpiry time.Duration
+
+	accessKey    string
+	secretKey    string
+	sessionToken string
+
+	client *http.Client
+	now    func() time.Time
+}
+
+// newS3Bucket sets up the bucket of cfg, with the credentials in the
+// standard AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+// environment variables: .noteflow.json travels with the folder.
+func newS3Bucket(cfg *models.S3FolderConfig) (*s3Bucket, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("\"s3\" in %s needs a bucket", models.FolderConfigFile)
+	}
+	b := &s3Bucket{
+		region:       cfg.S3Region(),
+		bucket:       cfg.Bucket,
+		pathStyle:    cfg.PathStyle,
+		urlExpiry:    cfg.URLExpiry(),
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		client:       &http.Client{Timeout: s3Timeout},
+		now:          time.Now,
+	}
+	if b.accessKey == "" || b.secretKey == "" {
+		return nil, fmt.Errorf("the s3 bucket needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	if prefix := strings.Trim(cfg.Prefix, "/"); prefix != "" {
+		b.prefix = prefix + "/"
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + b.region + ".amazonaws.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	b.endpoint = u
+	return b, nil
+}
+
+// objectURL returns the URL of the object at key.
+func (b *s3Bucket) objectURL(key string) *url.URL {
+	u := *b.endpoint
+	key = b.prefix + key
+	if b.pathStyle {
+		u.Path = strings.TrimRight(u.Path, "/") + "/" + b.bucket + "/" + key
+	} else {
+		u.Host = b.bucket + "." + u.Host
+		u.Path = strings.TrimRight(u.Path, "/") + "/" + key
+	}
+	u.RawPath = s3Escape(u.Path, false)
+	return &u
+}
+
+func (b *s3Bucket) do(method, key string, body []byte, contentType string) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, b.objectURL(key).String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	sum := sha256.Sum256(body)
+	b.sign(req, hex.EncodeToString(sum[:]))
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s %s: %w", method, key, err)
+	}
+	return resp, nil
+}
+
+// put stores data at key.
+func (b *s3Bucket) put(key string, data []byte, contentType string) error {
+	resp, err := b.do(http.MethodPut, key, data, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("s3 PUT %s: %s %s", key, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// delete removes the object at key; one that isn't there is no error.
+func (b *s3Bucket) delete(key string) error {
+	resp, err := b.do(http.MethodDelete, key, nil, "")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("s3 DELETE %s: %s", key, resp.Status)
+	}
+	return nil
+}
+
+// presign returns a URL that GETs the object at key without credentials
+// until urlExpiry has passed.
+func (b *s3Bucket) presign(key string) string {
+	now := b.now().UTC()
+	u := b.objectURL(key)
+	q := url.Values{}
+	q.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	q.Set("X-Amz-Credential", b.accessKey+"/"+b.scope(now))
+	q.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	q.Set("X-Amz-Expires", strconv.Itoa(int(b.urlExpiry/time.Second)))
+	q.Set("X-Amz-SignedHeaders", "host")
+	if b.sessionToken != "" {
+		q.Set("X-Amz-Security-Token", b.sessionToken)
+	}
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		s3Query(q),
+		"host:" + u.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	q.Set("X-Amz-Signature", b.signature(now, canonical))
+	u.RawQuery = s3Query(q)
+	return u.String()
+}
+
+// sign adds the Authorization header of SigV4 to req, whose body hashes
+// to payloadHash.
+func (b *s3Bucket) sign(req *http.Request, payloadHash string) {
+	now := b.now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if b.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", b.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3Query(req.URL.Query()),
+		canonicalHeaders.String(),
+		signed,
+		payloadHash,
+	}, "\n")
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.accessKey, b.scope(now), signed, b.signature(now, canonical)))
+}
+
+// scope is the credential scope of a request made at now.
+func (b *s3Bucket) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + b.region + "/s3/aws4_request"
+}
+
+// signature signs canonical, a canonical request made at now.
+func (b *s3Bucket) signature(now time.Time, canonical string) string {
+	sum := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + now.Format("20060102T150405Z") + "\n" + b.scope(now) + "\n" + hex.EncodeToString(sum[:])
+	key := []byte("AWS4" + b.secretKey)
+	for _, part := range []string{now.Format("20060102"), b.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Query is the canonical query string: sorted, every value escaped.
+func s3Query(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range q[k] {
+			parts = append(parts, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape percent-encodes s as SigV4 wants: everything but the
+// unreserved characters, and slashes too when escapeSlash is set.
+func s3Escape(s string, escapeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !escapeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+	_ "modernc.org/sqlite"
+)
+
+// NotesDBFile holds a folder's notes in the SQLite storage mode, one row
+// per note, in place of notes.md.
+const NotesDBFile = "notes.db"
+
+// notesSchema keeps each note's header fields in columns of their own, so
+// they can be queried and indexed, and its body as notes.md has it. The
+// position is the note's place in notes.md order, newest first.
+const notesSchema = `
+CREATE TABLE IF NOT EXISTS notes (
+	position  INTEGER PRIMARY KEY,
+	timestamp TEXT NOT NULL,
+	title     TEXT NOT NULL DEFAULT '',
+	content   TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_notes_timestamp ON notes(timestamp);
+CREATE INDEX IF NOT EXISTS idx_notes_title ON notes(title);
+`
+
+// noteTimeLayout is the note header's timestamp format.
+const noteTimeLayout = "2006-01-02 15:04:05"
+
+// sqliteNotes is the notes table of a notes.db.
+type sqliteNotes struct {
+	db *sql.DB
+}
+
+// openSQLiteNotes opens the notes database at path, creating it when
+// needed. Another process writing it (the CLI, say) makes a writer wait
+// for its transaction instead of failing.
+func openSQLiteNotes(path string) (*sqliteNotes, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", NotesDBFile, err)
+	}
+	if _, err := db.Exec(notesSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to set up %s: %w", NotesDBFile, err)
+	}
+	return &sqliteNotes{db: db}, nil
+}
+
+// load returns every note, newest first.
+func (s *sqliteNotes) load() ([]*models.Note, error) {
+	rows, err := s.db.Query(`SELECT timestamp, title, content FROM notes ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	notes := []*models.Note{}
+	for rows.Next() {
+		var stamp, title, content string
+		if err := rows.Scan(&stamp, &title, &content); err != nil {
+			return nil, err
+		}
+		ts, err := time.ParseInLocation(noteTimeLayout, stamp, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("note with bad timestamp %q in %s", stamp, NotesDBFile)
+		}
+		// Parsing the rendered note derives tasks, tags and metadata
+		// exactly as loading notes.md would.
+		note, err := models.NewNoteFromText((&models.Note{Timestamp: ts, Title: title, Content: content}).Render())
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+	return notes, rows.Err()
+}
+
+// save replaces the stored notes with notes in one transaction. Only rows
+// that changed are written.
+func (s *sqliteNotes) save(notes []*models.Note) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO notes (position, timestamp, title, content) VALUES (?, ?, ?, ?)
+		ON CONFLICT(position) DO UPDATE SET
+			timestamp = excluded.timestamp, title = excluded.title, content = excluded.content
+		WHERE timestamp != excluded.timestamp OR title != excluded.title OR content != excluded.content`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for i, note := range notes {
+		if _, err := stmt.Exec(i, note.Timestamp.Format(noteTimeLayout), note.Title, note.Content); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM notes WHERE position >= ?`, len(notes)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteNotes) close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// trashMarkerRE matches the line written above each note in trash.md.
+var trashMarkerRE = regexp.MustCompile(`^<!-- deleted (\S+) -->\n`)
+
+// LoadTrash reads trash.md. A missing file is an empty trash.
+func (fs *FileStorage) LoadTrash() ([]models.TrashedNote, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	data, err := os.ReadFile(filepath.Join(fs.BasePath, models.TrashFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", models.TrashFile, err)
+	}
+	if data, err = fs.openPrivate(data); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", models.TrashFile, err)
+	}
+
+	var trash []models.TrashedNote
+	for _, raw := range strings.Split(string(data), models.NoteSeparator) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		// An entry without a readable marker (say, pasted in by hand) is
+		// kept and treated as deleted now, so it ages out normally.
+		deleted := time.Now()
+		if m := trashMarkerRE.FindStringSubmatch(raw + "\n"); m != nil {
+			if t, err := time.Parse(time.RFC3339, m[1]); err == nil {
+				deleted = t
+			}
+			raw = strings.TrimSpace(raw[min(len(m[0]), len(raw)):])
+		}
+		note, err := models.NewNoteFromText(raw)
+		if err != nil {
+			continue
+		}
+		trash = append(trash, models.TrashedNote{Note: note, Deleted: deleted})
+	}
+	return trash, nil
+}
+
+// SaveTrash replaces trash.md with trash. An empty trash removes the file.
+func (fs *FileStorage) SaveTrash(trash []models.TrashedNote) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	path := filepath.Join(fs.BasePath, models.TrashFile)
+	if len(trash) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	rendered := make([]string, len(trash))
+	for i, t := range trash {
+		rendered[i] = "<!-- deleted " + t.Deleted.Format(time.RFC3339) + " -->\n" + t.Note.Render()
+	}
+	data, err := fs.sealPrivate([]byte(strings.Join(rendered, models.NoteSeparator)))
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// DeleteHistory removes every saved revision of the note with noteKey.
+func (fs *FileStorage) DeleteHistory(noteKey string) error {
+	dir, err := fs.historyPath(noteKey)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
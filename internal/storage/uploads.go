@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// findUpload returns the name of a file in dir whose content is data, or
+// "" if there is none. Only files of the same size are read, so an upload
+// costs a directory listing, not a read of every asset.
+func findUpload(dir string, data []byte) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Size() != int64(len(data)) {
+			continue
+		}
+		existing, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err == nil && bytes.Equal(existing, data) {
+			return entry.Name(), nil
+		}
+	}
+	return "", nil
+}
+
+// hashedUploadName returns filename with a prefix of data's SHA-256 before
+// its extension, for an upload whose name is taken by other content:
+// "diagram.png" becomes "diagram-3f2a9c1e.png".
+func hashedUploadName(filename string, data []byte) string {
+	sum := sha256.Sum256(data)
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "-" + hex.EncodeToString(sum[:4]) + ext
+}
+
+// ImportAsset stores data at rel, a slash path under assets/ taken from an
+// imported archive, and returns the path it is kept at. A file with the
+// same content already in that directory is reused; other content under a
+// taken name is stored as SaveFile would, with its hash added to the name.
+// written is false when nothing had to be written.
+func (fs *FileStorage) ImportAsset(rel string, data []byte) (stored string, written bool, err error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if !strings.HasPrefix(rel, "assets/") || !filepath.IsLocal(filepath.FromSlash(rel)) {
+		return "", false, fmt.Errorf("invalid asset path: %s", rel)
+	}
+	dir, name := path.Split(rel)
+	diskDir := filepath.Join(fs.BasePath, filepath.FromSlash(dir))
+	if err := os.MkdirAll(diskDir, 0755); err != nil {
+		return "", false, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if existing, err := os.ReadFile(filepath.Join(diskDir, name)); err == nil && bytes.Equal(existing, data) {
+		return rel, false, nil
+	}
+	existing, err := findUpload(diskDir, data)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	if existing != "" {
+		return dir + existing, false, nil
+	}
+	if _, err := os.Stat(filepath.Join(diskDir, name)); err == nil {
+		name = hashedUploadName(name, data)
+	}
+	if err := os.WriteFile(filepath.Join(diskDir, name), data, 0644); err != nil {
+		return "", false, fmt.Errorf("failed to write %s: %w", dir+name, err)
+	}
+	return dir + name, true, nil
+}
@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// WebDAVCacheFile is the local copy of the notes in the WebDAV storage
+// mode: the remote notes.md as last read or written, so the notes can
+// still be read while the server is unreachable.
+const WebDAVCacheFile = ".notes.webdav.md"
+
+// webdavMetaFile records which version of the remote file the cache is.
+const webdavMetaFile = ".notes.webdav.json"
+
+// WebDAVPasswordEnv is the environment variable the WebDAV password is
+// taken from; .noteflow.json travels with the folder, so it holds only
+// the URL and user name.
+const WebDAVPasswordEnv = "NOTEFLOW_WEBDAV_PASSWORD"
+
+// webdavTimeout bounds each request to the server.
+const webdavTimeout = 30 * time.Second
+
+// ErrRemoteChanged is returned by a save refused because the remote
+// notes.md changed since it was last read; the notes are to be reloaded.
+var ErrRemoteChanged = errors.New("notes.md was changed on the WebDAV server")
+
+// webdavNotes keeps notes.md on a WebDAV server (Nextcloud, ownCloud, a
+// NAS, Apache mod_dav). Writes are conditional on the ETag of the version
+// last read, so an edit made elsewhere in the meantime is never
+// overwritten.
+type webdavNotes struct {
+	url      string
+	username string
+	password string
+	cache    string // WebDAVCacheFile
+	metaPath string // webdavMetaFile
+	client   *http.Client
+}
+
+// webdavMeta is the content of webdavMetaFile.
+type webdavMeta struct {
+	URL  string `json:"url"`
+	ETag string `json:"etag"`
+}
+
+func newWebDAVNotes(basePath string, cfg *models.WebDAVFolderConfig) (*webdavNotes, error) {
+	if cfg == nil || cfg.URL == "" {
+		return nil, fmt.Errorf("the webdav storage mode needs \"webdav\": {\"url\": ...} in %s", models.FolderConfigFile)
+	}
+	return &webdavNotes{
+		url:      cfg.URL,
+		username: cfg.Username,
+		password: os.Getenv(WebDAVPasswordEnv),
+		cache:    filepath.Join(basePath, WebDAVCacheFile),
+		metaPath: filepath.Join(basePath, webdavMetaFile),
+		client:   &http.Client{Timeout: webdavTimeout},
+	}, nil
+}
+
+func (w *webdavNotes) request(method string, body []byte, header map[string]string) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, w.url, reader)
+	if err != nil {
+		return nil, err
+	}
+	if w.username != "" || w.password != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("webdav %s: %w", method, err)
+	}
+	return resp, nil
+}
+
+// etag returns the ETag of the cached version, and whether there is one
+// of this URL: a server that sends no ETags leaves it empty.
+func (w *webdavNotes) etag() (string, bool) {
+	data, err := os.ReadFile(w.metaPath)
+	if err != nil {
+		return "", false
+	}
+	var meta webdavMeta
+	if json.Unmarshal(data, &meta) != nil || meta.URL != w.url {
+		return "", false
+	}
+	return meta.ETag, true
+}
+
+// remember makes data, at etag on the server, the cached version.
+func (w *webdavNotes) remember(data []byte, etag string) error {
+	if err := writeFileAtomic(w.cache, data, 0644); err != nil {
+		return err
+	}
+	meta, _ := json.Marshal(webdavMeta{URL: w.url, ETag: etag})
+	return writeFileAtomic(w.metaPath, meta, 0644)
+}
+
+// fetch returns the remote notes.md, from the cache when the server says
+// it hasn't changed or can't be reached.
+func (w *webdavNotes) fetch() ([]byte, error) {
+	header := map[string]string{}
+	cached, cacheErr := os.ReadFile(w.cache)
+	if etag, _ := w.etag(); cacheErr == nil && etag != "" {
+		header["If-None-Match"] = etag
+	}
+	resp, err := w.request(http.MethodGet, nil, header)
+	if err != nil {
+		if cacheErr == nil {
+			log.Printf("Warning: %v; showing the notes as last read", err)
+			return cached, nil
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cacheErr == nil:
+		return cached, nil
+	case resp.StatusCode == http.StatusNotFound:
+		// Nothing there yet, or deleted: the next save creates it.
+		os.Remove(w.metaPath)
+		return nil, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("webdav GET %s: %s", w.url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("webdav GET %s: %w", w.url, err)
+	}
+	if err := w.remember(data, resp.Header.Get("ETag")); err != nil {
+		log.Printf("Warning: failed to cache the WebDAV notes: %v", err)
+	}
+	return data, nil
+}
+
+func (w *webdavNotes) load() ([]*models.Note, error) {
+	data, err := w.fetch()
+	if err != nil {
+		return nil, err
+	}
+	notes, err := ParseNotes(string(data))
+	if notes == nil && err == nil {
+		notes = []*models.Note{}
+	}
+	return notes, err
+}
+
+// save writes the notes to the server, only over the version last read:
+// ErrRemoteChanged when another client has written since.
+func (w *webdavNotes) save(notes []*models.Note) error {
+	data := []byte(RenderNotes(notes))
+	header := map[string]string{"Content-Type": "text/markdown; charset=utf-8"}
+	switch etag, known := w.etag(); {
+	case etag != "":
+		header["If-Match"] = etag
+	case !known:
+		// Never read: only create the file, never replace one.
+		header["If-None-Match"] = "*"
+	}
+	resp, err := w.request(http.MethodPut, data, header)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		return ErrRemoteChanged
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("webdav PUT %s: %s", w.url, resp.Status)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		// Not every server returns the new ETag; ask for it.
+		if head, err := w.request(http.MethodHead, nil, nil); err == nil {
+			head.Body.Close()
+			etag = head.Header.Get("ETag")
+		}
+	}
+	return w.remember(data, etag)
+}
+
+// stamp returns the remote notes.md's modification time and size, which
+// change whenever another client writes it.
+func (w *webdavNotes) stamp() (time.Time, int64, error) {
+	resp, err := w.request(http.MethodHead, nil, nil)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return time.Time{}, 0, os.ErrNotExist
+	}
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, 0, fmt.Errorf("webdav HEAD %s: %s", w.url, resp.Status)
+	}
+	mod, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return mod, resp.ContentLength, nil
+}
+
+// webdav returns the WebDAV store of the folder, set up from its config
+// on first use.
+func (fs *FileStorage) webdav() (*webdavNotes, error) {
+	fs.notesDBMu.Lock()
+	defer fs.notesDBMu.Unlock()
+	if fs.remote == nil {
+		cfg, err := models.LoadFolderConfig(fs.BasePath)
+		if err != nil {
+			return nil, err
+		}
+		remote, err := newWebDAVNotes(fs.BasePath, cfg.WebDAV)
+		if err != nil {
+			return nil, err
+		}
+		fs.remote = remote
+	}
+	return fs.remote, nil
+}
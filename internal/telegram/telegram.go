@@ -0,0 +1,133 @@
+// Package telegram is a minimal client for the Telegram Bot API covering
+// the calls NoteFlow's capture bot needs: long-polling for messages and
+// replying to them. Like internal/todoist it uses net/http directly and
+// only models the fields NoteFlow reads.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAPI is the public Bot API endpoint.
+const DefaultAPI = "https://api.telegram.org"
+
+// MaxMessageLength is the most characters a message may hold.
+const MaxMessageLength = 4096
+
+// Client performs Bot API calls for one bot.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a client for baseURL (DefaultAPI when empty) using
+// the token @BotFather gave the bot.
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPI
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		// Long enough for a long poll; see GetUpdates.
+		http: &http.Client{Timeout: 90 * time.Second},
+	}
+}
+
+// Chat is the chat a message was sent in.
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+// Message is the subset of a message NoteFlow reads.
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	Chat      Chat   `json:"chat"`
+	Date      int64  `json:"date"` // Unix time
+	Text      string `json:"text"`
+	Caption   string `json:"caption"` // the text of a photo or file
+}
+
+// Update is one event for the bot. Updates other than new messages have
+// no Message.
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message"`
+}
+
+// GetUpdates waits up to timeout for updates after offset, the last seen
+// update ID plus one, which also confirms the earlier ones to Telegram.
+func (c *Client) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	q := url.Values{
+		"offset":          {strconv.FormatInt(offset, 10)},
+		"timeout":         {strconv.Itoa(int(timeout.Seconds()))},
+		"allowed_updates": {`["message"]`},
+	}
+	var updates []Update
+	if err := c.do(ctx, "getUpdates?"+q.Encode(), nil, &updates); err != nil {
+		return nil, fmt.Errorf("get updates: %w", err)
+	}
+	return updates, nil
+}
+
+// SendMessage sends text, as plain text, to chatID.
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string) error {
+	payload := map[string]any{"chat_id": chatID, "text": text, "disable_web_page_preview": true}
+	if err := c.do(ctx, "sendMessage", payload, nil); err != nil {
+		return fmt.Errorf("send message to %d: %w", chatID, err)
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method string, body, out any) error {
+	httpMethod, reader := http.MethodGet, io.Reader(nil)
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		httpMethod, reader = http.MethodPost, bytes.NewReader(buf)
+	}
+	req, err := http.NewRequestWithContext(ctx, httpMethod, c.baseURL+"/bot"+c.token+"/"+method, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// The URL holds the token; keep it out of logs.
+		if uerr, ok := err.(*url.Error); ok {
+			return uerr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	var envelope struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 16<<20)).Decode(&envelope); err != nil {
+		return fmt.Errorf("%s: %w", resp.Status, err)
+	}
+	if !envelope.OK {
+		return fmt.Errorf("%s: %s", resp.Status, envelope.Description)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(envelope.Result, out)
+}
@@ -0,0 +1,51 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetUpdatesAndSendMessage(t *testing.T) {
+	var sent map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/botsecret/getUpdates":
+			if r.URL.Query().Get("offset") != "7" || r.URL.Query().Get("timeout") != "30" {
+				t.Errorf("query = %v", r.URL.Query())
+			}
+			w.Write([]byte(`{"ok":true,"result":[{"update_id":7,"message":{"message_id":1,"chat":{"id":42},"date":1760000000,"text":"hi"}},{"update_id":8}]}`))
+		case "/botsecret/sendMessage":
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.Write([]byte(`{"ok":true,"result":{"message_id":2}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"ok":false,"description":"Not Found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "secret")
+	updates, err := c.GetUpdates(context.Background(), 7, 30*time.Second)
+	if err != nil {
+		t.Fatalf("GetUpdates: %v", err)
+	}
+	if len(updates) != 2 || updates[0].Message == nil || updates[0].Message.Chat.ID != 42 || updates[0].Message.Text != "hi" || updates[1].Message != nil {
+		t.Fatalf("updates = %+v", updates)
+	}
+	if err := c.SendMessage(context.Background(), 42, "hello"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if sent["chat_id"] != float64(42) || sent["text"] != "hello" {
+		t.Errorf("sent = %v", sent)
+	}
+
+	err = NewClient(srv.URL, "wrong").SendMessage(context.Background(), 42, "x")
+	if err == nil || !strings.Contains(err.Error(), "Not Found") || strings.Contains(err.Error(), "wrong") {
+		t.Errorf("bad token: %v", err)
+	}
+}
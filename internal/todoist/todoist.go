@@ -0,0 +1,208 @@
+// Package todoist is a minimal client for the Todoist API (v1) covering the
+// calls NoteFlow's two-way task sync needs. Like internal/github it uses
+// net/http directly and only models the fields NoteFlow reads.
+package todoist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultAPI is the public Todoist API endpoint.
+const DefaultAPI = "https://api.todoist.com/api/v1"
+
+// ErrNotFound is returned by GetTask when the task was deleted.
+var ErrNotFound = errors.New("todoist: task not found")
+
+// maxTaskPages bounds ListTasks pagination; at 200 per page that is far
+// more open tasks than one project should hold.
+const maxTaskPages = 10
+
+// Client performs authenticated Todoist API calls.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a client for baseURL (DefaultAPI when empty) using a
+// personal API token.
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPI
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 20 * time.Second},
+	}
+}
+
+// Due is a task's due date. NoteFlow only uses the calendar date.
+type Due struct {
+	Date string `json:"date"` // "YYYY-MM-DD", or a full datetime for timed tasks
+}
+
+// Task is the subset of a Todoist task NoteFlow cares about.
+type Task struct {
+	ID        string    `json:"id"`
+	ProjectID string    `json:"project_id"`
+	Content   string    `json:"content"`
+	Checked   bool      `json:"checked"`
+	Due       *Due      `json:"due"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DueDate returns the task's due date as YYYY-MM-DD, or "" when unset.
+func (t Task) DueDate() string {
+	if t.Due == nil || len(t.Due.Date) < 10 {
+		return ""
+	}
+	return t.Due.Date[:10]
+}
+
+// ListTasks returns the open tasks in projectID.
+func (c *Client) ListTasks(ctx context.Context, projectID string) ([]Task, error) {
+	var all []Task
+	cursor := ""
+	for page := 0; page < maxTaskPages; page++ {
+		q := url.Values{"project_id": {projectID}, "limit": {"200"}}
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+		var resp struct {
+			Results    []Task  `json:"results"`
+			NextCursor *string `json:"next_cursor"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/tasks?"+q.Encode(), nil, &resp); err != nil {
+			return nil, fmt.Errorf("list tasks in project %s: %w", projectID, err)
+		}
+		all = append(all, resp.Results...)
+		if resp.NextCursor == nil || *resp.NextCursor == "" {
+			break
+		}
+		cursor = *resp.NextCursor
+	}
+	return all, nil
+}
+
+// GetTask fetches one task, including completed ones. It returns
+// ErrNotFound when the task has been deleted.
+func (c *Client) GetTask(ctx context.Context, id string) (*Task, error) {
+	var task Task
+	if err := c.do(ctx, http.MethodGet, "/tasks/"+url.PathEscape(id), nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CreateTask adds a task to projectID. due is YYYY-MM-DD or "".
+func (c *Client) CreateTask(ctx context.Context, projectID, content, due string) (*Task, error) {
+	payload := map[string]string{"project_id": projectID, "content": content}
+	if due != "" {
+		payload["due_date"] = due
+	}
+	var task Task
+	if err := c.do(ctx, http.MethodPost, "/tasks", payload, &task); err != nil {
+		return nil, fmt.Errorf("create task: %w", err)
+	}
+	return &task, nil
+}
+
+// UpdateTask rewrites a task's content and due date. An empty due clears it.
+func (c *Client) UpdateTask(ctx context.Context, id, content, due string) error {
+	payload := map[string]string{"content": content}
+	if due != "" {
+		payload["due_date"] = due
+	} else {
+		payload["due_string"] = "no date"
+	}
+	if err := c.do(ctx, http.MethodPost, "/tasks/"+url.PathEscape(id), payload, nil); err != nil {
+		return fmt.Errorf("update task %s: %w", id, err)
+	}
+	return nil
+}
+
+// SetChecked completes or reopens a task.
+func (c *Client) SetChecked(ctx context.Context, id string, checked bool) error {
+	action := "reopen"
+	if checked {
+		action = "close"
+	}
+	if err := c.do(ctx, http.MethodPost, "/tasks/"+url.PathEscape(id)+"/"+action, nil, nil); err != nil {
+		return fmt.Errorf("%s task %s: %w", action, id, err)
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(buf)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// taskURLRE matches a Todoist task link as written back into task text.
+// The web app also emits slugged links ("task/buy-milk-<id>"), so the ID is
+// the trailing alphanumeric run.
+var taskURLRE = regexp.MustCompile(`https://app\.todoist\.com/app/task/(?:[\w-]*-)?([A-Za-z0-9]+)`)
+
+// TaskURL returns the web link for a task ID.
+func TaskURL(id string) string {
+	return "https://app.todoist.com/app/task/" + id
+}
+
+// ParseTaskURL finds the first Todoist task link in text and returns the
+// task ID. ok is false when text has no link.
+func ParseTaskURL(text string) (id string, ok bool) {
+	m := taskURLRE.FindStringSubmatch(text)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// StripTaskURL removes any Todoist task link from text.
+func StripTaskURL(text string) string {
+	return strings.Join(strings.Fields(taskURLRE.ReplaceAllString(text, "")), " ")
+}
@@ -0,0 +1,66 @@
+package todoist
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListTasks_FollowsCursor(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("auth = %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Query().Get("project_id") != "p1" {
+			t.Errorf("project_id = %q", r.URL.Query().Get("project_id"))
+		}
+		if r.URL.Query().Get("cursor") == "" {
+			w.Write([]byte(`{"results":[{"id":"a","content":"one","due":{"date":"2026-10-20T09:00:00"}}],"next_cursor":"c2"}`))
+			return
+		}
+		w.Write([]byte(`{"results":[{"id":"b","content":"two"}],"next_cursor":null}`))
+	}))
+	defer srv.Close()
+
+	tasks, err := NewClient(srv.URL, "secret").ListTasks(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("ListTasks: %v", err)
+	}
+	if calls != 2 || len(tasks) != 2 {
+		t.Fatalf("calls = %d, tasks = %+v", calls, tasks)
+	}
+	if tasks[0].DueDate() != "2026-10-20" || tasks[1].DueDate() != "" {
+		t.Errorf("due dates = %q, %q", tasks[0].DueDate(), tasks[1].DueDate())
+	}
+}
+
+func TestGetTask_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, "").GetTask(context.Background(), "gone"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestParseTaskURL(t *testing.T) {
+	cases := map[string]string{
+		"[ ] call mom https://app.todoist.com/app/task/6X7rM8997g3RQmvh": "6X7rM8997g3RQmvh",
+		"[ ] https://app.todoist.com/app/task/call-mom-6X7rM8997g3RQmvh": "6X7rM8997g3RQmvh",
+	}
+	for text, want := range cases {
+		if id, ok := ParseTaskURL(text); !ok || id != want {
+			t.Errorf("ParseTaskURL(%q) = %q, %v; want %q", text, id, ok, want)
+		}
+	}
+	if _, ok := ParseTaskURL("[ ] no link here"); ok {
+		t.Error("expected no match")
+	}
+	if got := StripTaskURL("call mom " + TaskURL("abc")); got != "call mom" {
+		t.Errorf("StripTaskURL = %q", got)
+	}
+}
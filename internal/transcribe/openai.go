@@ -0,0 +1,93 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultOpenAIURL is the OpenAI API base; Groq and other compatible
+// providers work by pointing api_url at their base instead.
+const DefaultOpenAIURL = "https://api.openai.com/v1"
+
+// OpenAI calls an OpenAI-compatible /audio/transcriptions endpoint.
+type OpenAI struct {
+	baseURL  string
+	apiKey   string
+	model    string
+	language string
+	http     *http.Client
+}
+
+// NewOpenAI creates a transcriber for baseURL (DefaultOpenAIURL when empty)
+// using model (default "whisper-1").
+func NewOpenAI(baseURL, apiKey, model, language string) *OpenAI {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIURL
+	}
+	if model == "" {
+		model = "whisper-1"
+	}
+	return &OpenAI{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		apiKey:   apiKey,
+		model:    model,
+		language: language,
+		http:     &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// Transcribe implements Transcriber.
+func (o *OpenAI) Transcribe(ctx context.Context, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return "", err
+	}
+	mw.WriteField("model", o.model)
+	mw.WriteField("response_format", "text")
+	if o.language != "" {
+		mw.WriteField("language", o.language)
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/audio/transcriptions", &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+o.apiKey)
+
+	resp, err := o.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	text, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("transcription API: %s: %s", resp.Status, strings.TrimSpace(string(text)))
+	}
+	return cleanTranscript(string(text)), nil
+}
@@ -0,0 +1,56 @@
+// Package transcribe turns audio attachments into text so voice memos end
+// up in the note body, where search already looks. Two backends: a local
+// whisper.cpp binary (shelled out to, no cgo binding) and any
+// OpenAI-compatible transcription API.
+package transcribe
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// Transcriber converts the audio file at path to text.
+type Transcriber interface {
+	Transcribe(ctx context.Context, path string) (string, error)
+}
+
+// audioExts are the upload extensions treated as audio.
+var audioExts = map[string]bool{
+	".mp3": true, ".m4a": true, ".wav": true, ".ogg": true,
+	".oga": true, ".opus": true, ".webm": true, ".flac": true,
+}
+
+// IsAudio reports whether filename looks like an audio file.
+func IsAudio(filename string) bool {
+	return audioExts[strings.ToLower(filepath.Ext(filename))]
+}
+
+// New builds the configured Transcriber. It returns nil, nil when
+// transcription is off.
+func New(cfg models.TranscriptionConfig) (Transcriber, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case "whisper-cpp":
+		if cfg.WhisperModel == "" {
+			return nil, fmt.Errorf("transcription: whisper_model is required for whisper-cpp")
+		}
+		return NewWhisperCPP(cfg.WhisperBinary, cfg.WhisperModel, cfg.Language), nil
+	case "openai":
+		key := cfg.APIKey
+		if key == "" {
+			key = os.Getenv("OPENAI_API_KEY")
+		}
+		if key == "" {
+			return nil, fmt.Errorf("transcription: api_key (or $OPENAI_API_KEY) is required for openai")
+		}
+		return NewOpenAI(cfg.APIURL, key, cfg.Model, cfg.Language), nil
+	default:
+		return nil, fmt.Errorf("transcription: unknown provider %q", cfg.Provider)
+	}
+}
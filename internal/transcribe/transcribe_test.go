@@ -0,0 +1,94 @@
+package transcribe
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestNew(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	if tr, err := New(models.TranscriptionConfig{}); tr != nil || err != nil {
+		t.Errorf("empty config = %v, %v; want nil, nil", tr, err)
+	}
+	if _, err := New(models.TranscriptionConfig{Provider: "whisper-cpp"}); err == nil {
+		t.Error("whisper-cpp without a model should fail")
+	}
+	if _, err := New(models.TranscriptionConfig{Provider: "openai"}); err == nil {
+		t.Error("openai without a key should fail")
+	}
+	if _, err := New(models.TranscriptionConfig{Provider: "carrier-pigeon"}); err == nil {
+		t.Error("unknown provider should fail")
+	}
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	if tr, err := New(models.TranscriptionConfig{Provider: "openai"}); err != nil || tr == nil {
+		t.Errorf("openai via env = %v, %v", tr, err)
+	}
+}
+
+func TestOpenAI_Transcribe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/audio/transcriptions" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("request = %s %s", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		file, hdr, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("FormFile: %v", err)
+		}
+		data, _ := io.ReadAll(file)
+		if hdr.Filename != "memo.m4a" || string(data) != "fake audio" {
+			t.Errorf("upload = %s %q", hdr.Filename, data)
+		}
+		if r.FormValue("model") != "whisper-1" || r.FormValue("language") != "en" {
+			t.Errorf("form = %v", r.Form)
+		}
+		w.Write([]byte("remember to buy\nmilk\n"))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "memo.m4a")
+	os.WriteFile(path, []byte("fake audio"), 0644)
+	text, err := NewOpenAI(srv.URL, "sk-test", "", "en").Transcribe(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if text != "remember to buy milk" {
+		t.Errorf("text = %q", text)
+	}
+}
+
+func TestWhisperCPP_Transcribe(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the fake binary")
+	}
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "whisper-cli")
+	// Echo the arguments so the test can see what whisper.cpp was given.
+	os.WriteFile(bin, []byte("#!/bin/sh\necho ' hello'\necho \" $@\"\n"), 0755)
+	audio := filepath.Join(dir, "memo.wav")
+	os.WriteFile(audio, []byte("RIFF"), 0644)
+
+	text, err := NewWhisperCPP(bin, "/models/base.bin", "").Transcribe(context.Background(), audio)
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	want := "hello -m /models/base.bin -f " + audio + " --no-timestamps --no-prints"
+	if text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+}
+
+func TestIsAudio(t *testing.T) {
+	for name, want := range map[string]bool{"memo.M4A": true, "rec.webm": true, "a.wav": true, "doc.pdf": false, "noext": false} {
+		if IsAudio(name) != want {
+			t.Errorf("IsAudio(%q) = %v", name, !want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// whisperNativeExts are the formats whisper.cpp decodes itself; anything
+// else is converted with ffmpeg first.
+var whisperNativeExts = map[string]bool{".wav": true, ".mp3": true, ".flac": true, ".ogg": true}
+
+// WhisperCPP runs a local whisper.cpp CLI.
+type WhisperCPP struct {
+	binary   string
+	model    string
+	language string
+}
+
+// NewWhisperCPP creates a transcriber for the whisper.cpp binary (default
+// "whisper-cli" on $PATH) and ggml model file.
+func NewWhisperCPP(binary, model, language string) *WhisperCPP {
+	if binary == "" {
+		binary = "whisper-cli"
+	}
+	return &WhisperCPP{binary: binary, model: model, language: language}
+}
+
+// Transcribe implements Transcriber.
+func (w *WhisperCPP) Transcribe(ctx context.Context, path string) (string, error) {
+	input := path
+	if !whisperNativeExts[strings.ToLower(filepath.Ext(path))] {
+		wav, cleanup, err := toWAV(ctx, path)
+		if err != nil {
+			return "", err
+		}
+		defer cleanup()
+		input = wav
+	}
+
+	args := []string{"-m", w.model, "-f", input, "--no-timestamps", "--no-prints"}
+	if w.language != "" {
+		args = append(args, "-l", w.language)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, w.binary, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("whisper.cpp: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return cleanTranscript(stdout.String()), nil
+}
+
+// toWAV converts path to 16 kHz mono WAV, the format whisper.cpp expects,
+// using ffmpeg. Browser recordings (webm/opus) and phone memos (m4a) need it.
+func toWAV(ctx context.Context, path string) (string, func(), error) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return "", nil, fmt.Errorf("whisper.cpp can't read %s files directly and ffmpeg is not installed", filepath.Ext(path))
+	}
+	tmp, err := os.CreateTemp("", "noteflow-*.wav")
+	if err != nil {
+		return "", nil, err
+	}
+	tmp.Close()
+	cleanup := func() { os.Remove(tmp.Name()) }
+	out, err := exec.CommandContext(ctx, "ffmpeg", "-y", "-loglevel", "error",
+		"-i", path, "-ar", "16000", "-ac", "1", tmp.Name()).CombinedOutput()
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return tmp.Name(), cleanup, nil
+}
+
+// cleanTranscript joins whisper's per-segment lines into one paragraph.
+func cleanTranscript(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,156 @@
+// Package vision generates alt text for uploaded images with a vision
+// model, so image-heavy notes stay accessible and searchable by what the
+// pictures show. It speaks the OpenAI-compatible chat-completions API over
+// net/http, which covers hosted providers and a local Ollama alike.
+package vision
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+// DefaultPrompt asks for one short, literal sentence suitable as alt text.
+const DefaultPrompt = "Write alt text for this image: one concise sentence describing what it shows, " +
+	"including any legible text. No preamble."
+
+// Provider defaults.
+const (
+	OpenAIURL   = "https://api.openai.com/v1"
+	OllamaURL   = "http://localhost:11434/v1"
+	OpenAIModel = "gpt-4o-mini"
+	OllamaModel = "llava"
+)
+
+// Describer produces alt text for an image.
+type Describer interface {
+	Describe(ctx context.Context, image []byte, mimeType string) (string, error)
+}
+
+// New builds the configured Describer. It returns nil, nil when alt text
+// generation is off.
+func New(cfg models.AltTextConfig) (Describer, error) {
+	var url, key, model string
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case "openai":
+		url, model = OpenAIURL, OpenAIModel
+		key = cfg.APIKey
+		if key == "" {
+			key = os.Getenv("OPENAI_API_KEY")
+		}
+		if key == "" {
+			return nil, fmt.Errorf("alt text: api_key (or $OPENAI_API_KEY) is required for openai")
+		}
+	case "ollama":
+		url, model, key = OllamaURL, OllamaModel, cfg.APIKey
+	default:
+		return nil, fmt.Errorf("alt text: unknown provider %q", cfg.Provider)
+	}
+	if cfg.APIURL != "" {
+		url = cfg.APIURL
+	}
+	if cfg.Model != "" {
+		model = cfg.Model
+	}
+	prompt := cfg.Prompt
+	if prompt == "" {
+		prompt = DefaultPrompt
+	}
+	return NewChatDescriber(url, key, model, prompt), nil
+}
+
+// ChatDescriber sends the image to a chat-completions endpoint.
+type ChatDescriber struct {
+	baseURL string
+	apiKey  string
+	model   string
+	prompt  string
+	http    *http.Client
+}
+
+// NewChatDescriber creates a Describer for an OpenAI-compatible API.
+func NewChatDescriber(baseURL, apiKey, model, prompt string) *ChatDescriber {
+	return &ChatDescriber{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		prompt:  prompt,
+		http:    &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// Describe implements Describer.
+func (d *ChatDescriber) Describe(ctx context.Context, image []byte, mimeType string) (string, error) {
+	dataURL := "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(image)
+	payload := map[string]any{
+		"model":      d.model,
+		"max_tokens": 120,
+		"messages": []any{map[string]any{
+			"role": "user",
+			"content": []any{
+				map[string]any{"type": "text", "text": d.prompt},
+				map[string]any{"type": "image_url", "image_url": map[string]string{"url": dataURL}},
+			},
+		}},
+	}
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.baseURL+"/chat/completions", bytes.NewReader(buf))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+d.apiKey)
+	}
+
+	resp, err := d.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("vision API: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var out struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	if len(out.Choices) == 0 {
+		return "", fmt.Errorf("vision API returned no choices")
+	}
+	return CleanAltText(out.Choices[0].Message.Content), nil
+}
+
+// CleanAltText flattens model output into something safe inside the
+// brackets of a markdown image: one line, no brackets, bounded length.
+func CleanAltText(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.NewReplacer("[", "(", "]", ")").Replace(s)
+	s = strings.Trim(s, `"'`)
+	const max = 250
+	if r := []rune(s); len(r) > max {
+		s = strings.TrimSpace(string(r[:max-1])) + "…"
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package vision
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
+)
+
+func TestChatDescriber_SendsImageAndCleansReply(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		var req struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Content []struct {
+					Type     string            `json:"type"`
+					ImageURL map[string]string `json:"image_url"`
+				} `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "llava" || len(req.Messages) != 1 || len(req.Messages[0].Content) != 2 {
+			t.Fatalf("request = %+v", req)
+		}
+		if url := req.Messages[0].Content[1].ImageURL["url"]; !strings.HasPrefix(url, "data:image/png;base64,") {
+			t.Errorf("image url = %q", url)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"\"A cat\nasleep on a [keyboard].\""}}]}`))
+	}))
+	defer srv.Close()
+
+	d, err := New(models.AltTextConfig{Provider: "ollama", APIURL: srv.URL})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	alt, err := d.Describe(context.Background(), []byte("png"), "image/png")
+	if err != nil {
+		t.Fatalf("Describe: %v", err)
+	}
+	if alt != "A cat asleep on a (keyboard)." {
+		t.Errorf("alt = %q", alt)
+	}
+}
+
+func TestNew_Config(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	if d, err := New(models.AltTextConfig{}); d != nil || err != nil {
+		t.Errorf("off = %v, %v", d, err)
+	}
+	if _, err := New(models.AltTextConfig{Provider: "openai"}); err == nil {
+		t.Error("openai without key should fail")
+	}
+	if _, err := New(models.AltTextConfig{Provider: "mystery"}); err == nil {
+		t.Error("unknown provider should fail")
+	}
+}
+
+func TestCleanAltText_Truncates(t *testing.T) {
+	got := CleanAltText(strings.Repeat("word ", 100))
+	if n := len([]rune(got)); n > 250 || !strings.HasSuffix(got, "…") {
+		t.Errorf("len = %d, got %q", n, got)
+	}
+}
@@ -0,0 +1,204 @@
+// Package webarchive writes archived web pages in the standard formats
+// other tools read: MHTML (RFC 2557, opened by Chrome and Edge) and WARC
+// 1.1 (ISO 28500, replayed by pywb, ReplayWeb.page and the Wayback
+// Machine).
+package webarchive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/http/httputil"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// maxBody caps the page body Fetch records.
+const maxBody = 50 << 20
+
+// WriteMHTML writes page, a self-contained HTML document, as a
+// single-part MHTML file. Resources stay inlined as data URIs, which every
+// MHTML reader accepts.
+func WriteMHTML(w io.Writer, page, pageURL, title string, savedAt time.Time) error {
+	mw := multipart.NewWriter(w)
+	header := []string{
+		"From: <Saved by NoteFlow>",
+		"Snapshot-Content-Location: " + pageURL,
+		"Subject: " + mime.QEncoding.Encode("utf-8", title),
+		"Date: " + savedAt.Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/related; type="text/html"; boundary="` + mw.Boundary() + `"`,
+	}
+	if _, err := io.WriteString(w, strings.Join(header, "\r\n")+"\r\n\r\n"); err != nil {
+		return err
+	}
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {`text/html; charset="utf-8"`},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+		"Content-Location":          {pageURL},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := io.WriteString(qp, page); err != nil {
+		return err
+	}
+	if err := qp.Close(); err != nil {
+		return err
+	}
+	return mw.Close()
+}
+
+// Exchange is one HTTP request and its response as sent over the wire,
+// the unit a WARC file records.
+type Exchange struct {
+	URL      string
+	Request  []byte // request line, headers and body
+	Response []byte // status line, headers and body
+	Date     time.Time
+	IP       string // server address, when known
+}
+
+// Fetch GETs pageURL and records the exchange. Compressed responses are
+// stored decoded, with the Content-Encoding header dropped, so the record
+// stays consistent with its payload.
+func Fetch(ctx context.Context, client *http.Client, pageURL, userAgent string) (*Exchange, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	date := time.Now().UTC()
+	reqDump, err := httputil.DumpRequestOut(req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// The trace is attached after the dump, which does a fake round trip.
+	var ip string
+	req = req.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if host, _, err := net.SplitHostPort(info.Conn.RemoteAddr().String()); err == nil {
+				ip = host
+			}
+		},
+	}))
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", pageURL, err)
+	}
+
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Transfer-Encoding")
+	resp.Header.Set("Content-Length", fmt.Sprint(len(body)))
+	resp.TransferEncoding = nil
+	resp.ContentLength = int64(len(body))
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	respDump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return nil, err
+	}
+	// A redirect means the page was recorded under its final URL.
+	return &Exchange{URL: resp.Request.URL.String(), Request: reqDump, Response: respDump, Date: date, IP: ip}, nil
+}
+
+// WriteWARC writes a gzipped WARC 1.1 file holding a warcinfo record and
+// the exchange's request and response. Each record is its own gzip member,
+// as .warc.gz readers expect, so tools can seek to any record.
+func WriteWARC(w io.Writer, ex *Exchange, software string) error {
+	date := ex.Date.UTC().Format("2006-01-02T15:04:05Z")
+	info := "software: " + software + "\r\nformat: WARC File Format 1.1\r\n"
+	infoID := recordID()
+	respID := recordID()
+	records := []struct {
+		headers [][2]string
+		block   []byte
+	}{
+		{[][2]string{
+			{"WARC-Type", "warcinfo"},
+			{"WARC-Record-ID", infoID},
+			{"WARC-Date", date},
+			{"Content-Type", "application/warc-fields"},
+		}, []byte(info)},
+		{[][2]string{
+			{"WARC-Type", "response"},
+			{"WARC-Record-ID", respID},
+			{"WARC-Date", date},
+			{"WARC-Target-URI", ex.URL},
+			{"WARC-Warcinfo-ID", infoID},
+			{"WARC-IP-Address", ex.IP},
+			{"WARC-Payload-Digest", payloadDigest(ex.Response)},
+			{"Content-Type", "application/http; msgtype=response"},
+		}, ex.Response},
+		{[][2]string{
+			{"WARC-Type", "request"},
+			{"WARC-Record-ID", recordID()},
+			{"WARC-Date", date},
+			{"WARC-Target-URI", ex.URL},
+			{"WARC-Warcinfo-ID", infoID},
+			{"WARC-Concurrent-To", respID},
+			{"Content-Type", "application/http; msgtype=request"},
+		}, ex.Request},
+	}
+
+	for _, r := range records {
+		gz := gzip.NewWriter(w)
+		bw := bufio.NewWriter(gz)
+		bw.WriteString("WARC/1.1\r\n")
+		for _, h := range r.headers {
+			if h[1] != "" {
+				bw.WriteString(h[0] + ": " + h[1] + "\r\n")
+			}
+		}
+		fmt.Fprintf(bw, "Content-Length: %d\r\n\r\n", len(r.block))
+		bw.Write(r.block)
+		bw.WriteString("\r\n\r\n")
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordID returns a fresh WARC-Record-ID, a random UUID URN.
+func recordID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// payloadDigest is the SHA-1 of the HTTP body, base32 as WARC tools
+// expect.
+func payloadDigest(response []byte) string {
+	body := response
+	if i := bytes.Index(response, []byte("\r\n\r\n")); i >= 0 {
+		body = response[i+4:]
+	}
+	sum := sha1.Sum(body)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
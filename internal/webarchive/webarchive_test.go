@@ -0,0 +1,93 @@
+package webarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMHTML(t *testing.T) {
+	var buf bytes.Buffer
+	page := "<html><body>Café = " + strings.Repeat("long line ", 20) + "</body></html>"
+	if err := WriteMHTML(&buf, page, "https://example.com/a", "Café notes", time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(&buf)
+	if err != nil {
+		t.Fatalf("not a MIME message: %v", err)
+	}
+	if got := msg.Header.Get("Snapshot-Content-Location"); got != "https://example.com/a" {
+		t.Errorf("Snapshot-Content-Location = %q", got)
+	}
+	if subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); subject != "Café notes" {
+		t.Errorf("Subject = %q", subject)
+	}
+	mediaType, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if mediaType != "multipart/related" {
+		t.Fatalf("Content-Type = %q", mediaType)
+	}
+	part, err := multipart.NewReader(msg.Body, params["boundary"]).NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// multipart.Reader decodes quoted-printable parts itself.
+	body, _ := io.ReadAll(part)
+	if string(body) != page {
+		t.Errorf("part body = %q", body)
+	}
+}
+
+func TestFetchAndWriteWARC(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "<html>hello</html>")
+	}))
+	defer srv.Close()
+
+	ex, err := Fetch(context.Background(), srv.Client(), srv.URL+"/page", "test-agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(ex.Response, []byte("HTTP/1.1 200 OK")) || !bytes.HasSuffix(ex.Response, []byte("<html>hello</html>")) {
+		t.Errorf("response = %q", ex.Response)
+	}
+	if ex.IP != "127.0.0.1" {
+		t.Errorf("IP = %q", ex.IP)
+	}
+	if !bytes.HasPrefix(ex.Request, []byte("GET /page HTTP/1.1")) || !bytes.Contains(ex.Request, []byte("User-Agent: test-agent")) {
+		t.Errorf("request = %q", ex.Request)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteWARC(&buf, ex, "NoteFlow test"); err != nil {
+		t.Fatal(err)
+	}
+	// Concatenated gzip members read back as one stream.
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(zr)
+	records := strings.Split(string(data), "WARC/1.1\r\n")[1:]
+	if len(records) != 3 {
+		t.Fatalf("got %d records:\n%s", len(records), data)
+	}
+	for i, want := range []string{"WARC-Type: warcinfo", "WARC-Type: response", "WARC-Type: request"} {
+		if !strings.HasPrefix(records[i], want) {
+			t.Errorf("record %d starts %q, want %q", i, records[i][:30], want)
+		}
+	}
+	if !strings.Contains(records[1], "WARC-Target-URI: "+srv.URL+"/page\r\n") || !strings.Contains(records[1], "WARC-Payload-Digest: sha1:") {
+		t.Errorf("response record:\n%s", records[1])
+	}
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/Xafloc/NoteFlow-Go/internal/app"
 	"github.com/Xafloc/NoteFlow-Go/internal/cli"
+	"github.com/Xafloc/NoteFlow-Go/internal/models"
 	"github.com/Xafloc/NoteFlow-Go/internal/services"
 )
 
@@ -20,19 +24,51 @@ USAGE:
     noteflow-go <subcommand> [args]   Run a subcommand
 
 FLAGS (when starting the server):
+    --port N         Listen on port N (default 8000, or the next free port)
+    --host ADDR      Bind to ADDR only, e.g. 127.0.0.1 (default: all interfaces)
+    --base-path P    Serve under the URL prefix P, e.g. /notes, behind a proxy
+    --tls-cert FILE  Serve HTTPS with this PEM certificate (needs --tls-key)
+    --tls-key FILE   Private key for --tls-cert
+    --self-signed    Serve HTTPS with a generated self-signed certificate
+    --dir DIR        Serve the notes in DIR instead of the current folder
+    --log-file FILE  Append the log to FILE as well as stderr
+    --log-requests   Log every HTTP request
     --no-browser     Don't auto-open the default browser on startup
+                     (also "no_browser" in the config's "server" section)
     --version, -v    Print version and exit
     --help, -h       Show this help and exit
 
 SUBCOMMANDS:
-    append           Append a note to notes.md (for AI agents / scripts / shell)
+    append, add      Append a note to notes.md (for AI agents / scripts / shell)
+    archive-links    Archive the plain http(s) links already in notes.md
+    assets           Show or move uploads and archives to an S3 bucket
+    db               Show or change the task DB's schema version
+    discover         Register every notes folder under a directory tree
+    doctor           Check notes.md, assets and the task DB; --fix repairs
+    export           Export the project as a zip, HTML site, JSON, PDF or EPUB
+    google-auth      Authorize the Google Tasks mirror
+    grep             Print the lines of notes.md matching a pattern
+    import           Import notes: NoteFlow zip or JSON, Obsidian, Joplin or Keep
+    init             Set up a folder as a NoteFlow project
+    list             List the notes in notes.md
+    registry         Export or import the task registry as JSON
+    start            Start the server; --daemon runs it in the background
+    status           Show whether this folder's background server is running
+    storage          Keep the notes in notes.md or a SQLite database
+    stop             Stop this folder's background server
     tasks            Query and manage tasks across every NoteFlow project
+    users            Manage the accounts of multi-user mode
 
 Run 'noteflow-go <subcommand> --help' for subcommand-specific options.
+Settings come from ~/.config/noteflow/noteflow.json, overridden by NOTEFLOW_*
+environment variables (NOTEFLOW_PORT, NOTEFLOW_HOST, NOTEFLOW_BASE_PATH,
+NOTEFLOW_NO_BROWSER, NOTEFLOW_DIR, NOTEFLOW_LOG_FILE, ...; see the README),
+overridden by the flags. GET /api/config shows the result.
 Docs: https://github.com/Xafloc/NoteFlow-Go
 `
 
 func main() {
+	serverArgs := os.Args[1:]
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
 		case "--version", "-v":
@@ -41,13 +77,202 @@ func main() {
 		case "--help", "-h":
 			fmt.Printf(topHelp, Version)
 			return
-		case "append":
+		case "append", "add":
 			workingDir, err := os.Getwd()
 			if err != nil {
 				log.Fatal("Failed to get working directory:", err)
 			}
 			if err := cli.RunAppend(workingDir, os.Args[2:], os.Stdin, os.Stdout); err != nil {
-				fmt.Fprintln(os.Stderr, "noteflow append:", err)
+				fmt.Fprintln(os.Stderr, "noteflow "+os.Args[1]+":", err)
+				os.Exit(1)
+			}
+			return
+		case "archive-links":
+			workingDir, err := os.Getwd()
+			if err != nil {
+				log.Fatal("Failed to get working directory:", err)
+			}
+			if err := cli.RunArchiveLinks(workingDir, os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "noteflow archive-links:", err)
+				os.Exit(1)
+			}
+			return
+		case "assets":
+			workingDir, err := os.Getwd()
+			if err != nil {
+				log.Fatal("Failed to get working directory:", err)
+			}
+			if err := cli.RunAssets(workingDir, os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "noteflow assets:", err)
+				os.Exit(1)
+			}
+			return
+		case "db":
+			dbPath, err := services.DefaultDatabasePath()
+			if err != nil {
+				log.Fatal("Failed to resolve task DB path:", err)
+			}
+			if err := cli.RunDB(dbPath, os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "noteflow db:", err)
+				os.Exit(1)
+			}
+			return
+		case "discover":
+			workingDir, err := os.Getwd()
+			if err != nil {
+				log.Fatal("Failed to get working directory:", err)
+			}
+			dbPath, err := services.DefaultDatabasePath()
+			if err != nil {
+				log.Fatal("Failed to resolve task DB path:", err)
+			}
+			if err := cli.RunDiscover(workingDir, dbPath, os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "noteflow discover:", err)
+				os.Exit(1)
+			}
+			return
+		case "doctor":
+			workingDir, err := os.Getwd()
+			if err != nil {
+				log.Fatal("Failed to get working directory:", err)
+			}
+			dbPath, err := services.DefaultDatabasePath()
+			if err != nil {
+				log.Fatal("Failed to resolve task DB path:", err)
+			}
+			if err := cli.RunDoctor(workingDir, dbPath, os.Args[2:], os.Stdout); err != nil {
+				if !errors.Is(err, cli.ErrDoctorIssues) {
+					fmt.Fprintln(os.Stderr, "noteflow doctor:", err)
+				}
+				os.Exit(1)
+			}
+			return
+		case "export":
+			workingDir, err := os.Getwd()
+			if err != nil {
+				log.Fatal("Failed to get working directory:", err)
+			}
+			if err := cli.RunExport(workingDir, os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "noteflow export:", err)
+				os.Exit(1)
+			}
+			return
+		case "import":
+			workingDir, err := os.Getwd()
+			if err != nil {
+				log.Fatal("Failed to get working directory:", err)
+			}
+			dbPath, err := services.DefaultDatabasePath()
+			if err != nil {
+				log.Fatal("Failed to resolve task DB path:", err)
+			}
+			if err := cli.RunImport(workingDir, dbPath, os.Args[2:], os.Stdin, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "noteflow import:", err)
+				os.Exit(1)
+			}
+			return
+		case "registry":
+			dbPath, err := services.DefaultDatabasePath()
+			if err != nil {
+				log.Fatal("Failed to resolve task DB path:", err)
+			}
+			if err := cli.RunRegistry(dbPath, os.Args[2:], os.Stdin, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "noteflow registry:", err)
+				os.Exit(1)
+			}
+			return
+		case "storage":
+			workingDir, err := os.Getwd()
+			if err != nil {
+				log.Fatal("Failed to get working directory:", err)
+			}
+			if err := cli.RunStorage(workingDir, os.Args[2:], os.Stdin, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "noteflow storage:", err)
+				os.Exit(1)
+			}
+			return
+		case "init":
+			workingDir, err := os.Getwd()
+			if err != nil {
+				log.Fatal("Failed to get working directory:", err)
+			}
+			dbPath, err := services.DefaultDatabasePath()
+			if err != nil {
+				log.Fatal("Failed to resolve task DB path:", err)
+			}
+			if err := cli.RunInit(workingDir, dbPath, os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "noteflow init:", err)
+				os.Exit(1)
+			}
+			return
+		case "list":
+			workingDir, err := os.Getwd()
+			if err != nil {
+				log.Fatal("Failed to get working directory:", err)
+			}
+			if err := cli.RunList(workingDir, os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "noteflow list:", err)
+				os.Exit(1)
+			}
+			return
+		case "grep":
+			workingDir, err := os.Getwd()
+			if err != nil {
+				log.Fatal("Failed to get working directory:", err)
+			}
+			if err := cli.RunGrep(workingDir, os.Args[2:], os.Stdout); err != nil {
+				if errors.Is(err, cli.ErrNoMatch) {
+					os.Exit(1)
+				}
+				fmt.Fprintln(os.Stderr, "noteflow grep:", err)
+				os.Exit(2)
+			}
+			return
+		case "google-auth":
+			configPath, err := models.DefaultConfigPath()
+			if err != nil {
+				log.Fatal("Failed to resolve config path:", err)
+			}
+			if err := cli.RunGoogleAuth(configPath, os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "noteflow google-auth:", err)
+				os.Exit(1)
+			}
+			return
+		case "start":
+			serverArgs = os.Args[2:]
+			if !daemonRequested(serverArgs) {
+				break // run the server in the foreground, as with no subcommand
+			}
+			workingDir, err := os.Getwd()
+			if err != nil {
+				log.Fatal("Failed to get working directory:", err)
+			}
+			runDir, err := cli.DefaultRunDir()
+			if err != nil {
+				log.Fatal("Failed to resolve run directory:", err)
+			}
+			if err := cli.RunStart(workingDir, runDir, serverArgs, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "noteflow start:", err)
+				os.Exit(1)
+			}
+			return
+		case "stop", "status":
+			workingDir, err := os.Getwd()
+			if err != nil {
+				log.Fatal("Failed to get working directory:", err)
+			}
+			runDir, err := cli.DefaultRunDir()
+			if err != nil {
+				log.Fatal("Failed to resolve run directory:", err)
+			}
+			run := cli.RunStop
+			if os.Args[1] == "status" {
+				run = cli.RunStatus
+			}
+			if err := run(workingDir, runDir, os.Args[2:], os.Stdout); err != nil {
+				if !errors.Is(err, cli.ErrNotRunning) {
+					fmt.Fprintln(os.Stderr, "noteflow "+os.Args[1]+":", err)
+				}
 				os.Exit(1)
 			}
 			return
@@ -61,6 +286,16 @@ func main() {
 				os.Exit(1)
 			}
 			return
+		case "users":
+			dbPath, err := services.DefaultDatabasePath()
+			if err != nil {
+				log.Fatal("Failed to resolve task DB path:", err)
+			}
+			if err := cli.RunUsers(dbPath, os.Args[2:], os.Stdin, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "noteflow users:", err)
+				os.Exit(1)
+			}
+			return
 		}
 	}
 
@@ -70,26 +305,118 @@ func main() {
 		log.Fatal("Failed to get working directory:", err)
 	}
 
-	// Create assets directory if it doesn't exist
-	assetsDir := filepath.Join(workingDir, "assets")
-	if err := os.MkdirAll(assetsDir, 0755); err != nil {
-		log.Fatal("Failed to create assets directory:", err)
+	flags, err := parseServerFlags(serverArgs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "noteflow:", err)
+		os.Exit(2)
 	}
 
-	// Initialize and start the application
-	application, err := app.NewApp(workingDir, &WebAssets)
+	// Initialize and start the application; it creates the folder's
+	// notes.md and assets directories if needed
+	application, err := app.NewApp(workingDir, &WebAssets, flags)
 	if err != nil {
 		log.Fatal("Failed to initialize application:", err)
 	}
 
-	// --no-browser disables auto-opening the user's default browser to the
-	// server URL once it's listening. Useful for headless / SSH sessions.
-	for _, arg := range os.Args[1:] {
-		if arg == "--no-browser" {
-			application.SetNoBrowser(true)
-			break
+	// A server started by 'start --daemon' writes its PID file once it is
+	// listening, and removes it when it stops.
+	statePath := os.Getenv(cli.DaemonStateEnv)
+	if statePath != "" {
+		application.SetOnListen(func(url string) {
+			if err := cli.WriteDaemonState(statePath, application.Folder(), url); err != nil {
+				log.Printf("Failed to write PID file: %v", err)
+			}
+		})
+	}
+
+	// Ctrl-C and SIGTERM shut down gracefully, so notes.md is never left
+	// half-written; a second signal exits at once.
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	started := make(chan error, 1)
+	go func() { started <- application.Start() }()
+
+	select {
+	case sig := <-signals:
+		log.Printf("Received %v", sig)
+		go func() {
+			<-signals
+			log.Println("Exiting without saving")
+			os.Exit(1)
+		}()
+		application.Shutdown()
+		err = <-started
+	case err = <-started:
+		// Stopped by POST /api/shutdown, or failed to listen
+		application.Shutdown()
+	}
+	if statePath != "" {
+		os.Remove(statePath)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// daemonRequested reports whether args, those of 'start', are for
+// cli.RunStart: --daemon, or a request for start's help.
+func daemonRequested(args []string) bool {
+	for _, a := range args {
+		if a == "--daemon" || a == "--help" || a == "-h" {
+			return true
 		}
 	}
+	return false
+}
 
-	log.Fatal(application.Start())
+// parseServerFlags reads the flags that start the server: --port, --host,
+// --base-path, --tls-cert, --tls-key, --dir and --log-file (each as
+// "--flag value" or "--flag=value"), --self-signed, --no-browser and
+// --log-requests, into the settings they override (see Config.Override).
+// Other arguments are ignored.
+func parseServerFlags(args []string) (flags models.Config, err error) {
+	server := &flags.Server
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--no-browser":
+			server.NoBrowser = true
+			continue
+		case "--self-signed":
+			server.SelfSigned = true
+			continue
+		case "--log-requests":
+			flags.Log.Requests = true
+			continue
+		case "--port", "--host", "--base-path", "--tls-cert", "--tls-key", "--dir", "--log-file":
+		default:
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return flags, fmt.Errorf("%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case "--port":
+			if server.Port, err = models.ParsePort(value); err != nil {
+				return flags, fmt.Errorf("--port: %w", err)
+			}
+		case "--host":
+			server.Host = value
+		case "--base-path":
+			server.BasePath = value
+		case "--tls-cert":
+			server.TLSCert = value
+		case "--tls-key":
+			server.TLSKey = value
+		case "--dir":
+			flags.DataDir = value
+		case "--log-file":
+			flags.Log.File = value
+		}
+	}
+	return flags, nil
 }
\ No newline at end of file